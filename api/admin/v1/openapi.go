@@ -0,0 +1,252 @@
+package v1
+
+import (
+	"encoding/json"
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// OpenAPIPath is the path the admin API serves its OpenAPI document at.
+const OpenAPIPath = "/openapi.json"
+
+// Operation describes one route of the admin API.
+type Operation struct {
+	Method      string
+	Path        string // Path template, e.g. "/rules/{name}/enabled".
+	ID          string // operationId, naming generated client methods.
+	Summary     string
+	Request     any            // Zero value of the JSON request body type, if any.
+	RequestYAML bool           // The request body is a kubeclean configuration document.
+	Response    any            // Zero value of the JSON response body type.
+	Errors      map[int]string // Error responses by status code.
+}
+
+// Operations lists the routes of the admin API.
+var Operations = []Operation{
+	{
+		Method:      http.MethodPost,
+		Path:        "/simulate",
+		ID:          "simulate",
+		Summary:     "Compare the matches of a candidate config with those of the active config",
+		RequestYAML: true,
+		Response:    SimulationResult{},
+		Errors:      map[int]string{http.StatusBadRequest: "The candidate config is invalid"},
+	},
+	{
+		Method:   http.MethodGet,
+		Path:     "/rules/status",
+		ID:       "getRuleStatuses",
+		Summary:  "Get the latest status of every rule, sorted by kind and name",
+		Response: []RuleStatus{},
+	},
+	{
+		Method:   http.MethodGet,
+		Path:     "/retries",
+		ID:       "getRetryQueue",
+		Summary:  "Get the pods awaiting a deletion retry and those given up on",
+		Response: RetryQueue{},
+	},
+	{
+		Method:   http.MethodGet,
+		Path:     "/config/pending",
+		ID:       "getPendingConfig",
+		Summary:  "Get the reloaded config held back by reloadSafety",
+		Response: PendingConfig{},
+		Errors:   map[int]string{http.StatusNotFound: "No config is pending approval"},
+	},
+	{
+		Method:   http.MethodPost,
+		Path:     "/config/pending/approve",
+		ID:       "approvePendingConfig",
+		Summary:  "Replace the active config with the held config",
+		Response: PendingConfig{},
+		Errors:   map[int]string{http.StatusNotFound: "No config is pending approval"},
+	},
+	{
+		Method:   http.MethodDelete,
+		Path:     "/config/pending",
+		ID:       "rejectPendingConfig",
+		Summary:  "Discard the held config and keep the active config",
+		Response: PendingConfig{},
+		Errors:   map[int]string{http.StatusNotFound: "No config is pending approval"},
+	},
+	{
+		Method:   http.MethodPatch,
+		Path:     "/rules/{name}/enabled",
+		ID:       "setRuleEnabled",
+		Summary:  "Enable or disable a rule at runtime without changing the config",
+		Request:  RuleEnabled{},
+		Response: RuleEnabled{},
+		Errors: map[int]string{
+			http.StatusBadRequest:          "The body does not set enabled",
+			http.StatusNotFound:            "No rule of the kind query parameter has this name",
+			http.StatusConflict:            "Rules of several kinds have this name and the kind query parameter is not set",
+			http.StatusUnprocessableEntity: "The rule is invalid and cannot be enabled",
+		},
+	},
+	{
+		Method:   http.MethodDelete,
+		Path:     "/rules/{name}/enabled",
+		ID:       "clearRuleEnabled",
+		Summary:  "Remove a runtime override so the rule follows the config again",
+		Response: RuleEnabled{},
+		Errors: map[int]string{
+			http.StatusNotFound: "No rule of the kind query parameter has this name",
+			http.StatusConflict: "Rules of several kinds have this name and the kind query parameter is not set",
+		},
+	},
+}
+
+// OpenAPI returns the OpenAPI 3.0 document describing Operations. Schemas are derived from the
+// JSON encoding of the request and response types, so the document follows them as they change.
+func OpenAPI() ([]byte, error) {
+	schemas := schemaSet{}
+	paths := map[string]map[string]any{}
+
+	for _, op := range Operations {
+		operation := map[string]any{
+			"operationId": op.ID,
+			"summary":     op.Summary,
+			"responses": map[string]any{
+				"200": map[string]any{
+					"description": "OK",
+					"content":     jsonContent(schemas.of(reflect.TypeOf(op.Response))),
+				},
+			},
+		}
+
+		var parameters []any
+		for _, segment := range strings.Split(op.Path, "/") {
+			if name, ok := strings.CutPrefix(segment, "{"); ok {
+				parameters = append(parameters, map[string]any{
+					"name": strings.TrimSuffix(name, "}"), "in": "path", "required": true,
+					"schema": map[string]any{"type": "string"},
+				})
+			}
+		}
+		if parameters != nil {
+			operation["parameters"] = parameters
+		}
+
+		switch {
+		case op.RequestYAML:
+			operation["requestBody"] = map[string]any{
+				"required":    true,
+				"description": "kubeclean configuration document, as in the config file",
+				"content":     map[string]any{"application/yaml": map[string]any{"schema": map[string]any{"type": "string"}}},
+			}
+		case op.Request != nil:
+			operation["requestBody"] = map[string]any{
+				"required": true,
+				"content":  jsonContent(schemas.of(reflect.TypeOf(op.Request))),
+			}
+		}
+
+		responses := operation["responses"].(map[string]any)
+		errorSchema := schemas.of(reflect.TypeOf(Error{}))
+		for status, description := range op.Errors {
+			responses[strconv.Itoa(status)] = map[string]any{"description": description, "content": jsonContent(errorSchema)}
+		}
+		responses["401"] = textResponse("Authentication is enabled and the request carries no valid bearer token")
+		responses["403"] = textResponse("The caller may not make this request")
+
+		if paths[op.Path] == nil {
+			paths[op.Path] = map[string]any{}
+		}
+		paths[op.Path][strings.ToLower(op.Method)] = operation
+	}
+
+	return json.MarshalIndent(map[string]any{
+		"openapi": "3.0.3",
+		"info": map[string]any{
+			"title":   "kubeclean admin API",
+			"version": "v1",
+		},
+		"paths": paths,
+		"components": map[string]any{
+			"schemas": schemas,
+			"securitySchemes": map[string]any{
+				"bearer": map[string]any{"type": "http", "scheme": "bearer"},
+			},
+		},
+		// Bearer tokens are only required when API authentication is enabled.
+		"security": []any{map[string]any{"bearer": []string{}}, map[string]any{}},
+	}, "", "  ")
+}
+
+func jsonContent(schema map[string]any) map[string]any {
+	return map[string]any{"application/json": map[string]any{"schema": schema}}
+}
+
+func textResponse(description string) map[string]any {
+	return map[string]any{
+		"description": description,
+		"content":     map[string]any{"text/plain": map[string]any{"schema": map[string]any{"type": "string"}}},
+	}
+}
+
+// schemaSet collects the component schemas of named struct types, keyed by type name.
+type schemaSet map[string]any
+
+var timeType = reflect.TypeOf(time.Time{})
+
+// of returns the schema of t, referencing named struct types from the component schemas.
+func (s schemaSet) of(t reflect.Type) map[string]any {
+	switch {
+	case t == timeType:
+		return map[string]any{"type": "string", "format": "date-time"}
+	case t.Kind() == reflect.Pointer:
+		return s.of(t.Elem())
+	case t.Kind() == reflect.Struct:
+		if _, ok := s[t.Name()]; !ok {
+			s[t.Name()] = map[string]any{} // Reserved while recursing, in case the type refers to itself.
+			s[t.Name()] = s.object(t)
+		}
+		return map[string]any{"$ref": "#/components/schemas/" + t.Name()}
+	case t.Kind() == reflect.Map:
+		return map[string]any{"type": "object", "additionalProperties": s.of(t.Elem())}
+	case t.Kind() == reflect.Slice:
+		return map[string]any{"type": "array", "items": s.of(t.Elem())}
+	case t.Kind() == reflect.Bool:
+		return map[string]any{"type": "boolean"}
+	case t.Kind() >= reflect.Int && t.Kind() <= reflect.Uint64:
+		return map[string]any{"type": "integer"}
+	case t.Kind() == reflect.Float32 || t.Kind() == reflect.Float64:
+		return map[string]any{"type": "number"}
+	default:
+		return map[string]any{"type": "string"}
+	}
+}
+
+// object returns the schema of struct type t following its JSON field names. Fields without
+// omitempty are required.
+func (s schemaSet) object(t reflect.Type) map[string]any {
+	properties := map[string]any{}
+	var required []string
+
+	for i := range t.NumField() {
+		field := t.Field(i)
+		tag := field.Tag.Get("json")
+		name, options, _ := strings.Cut(tag, ",")
+		if name == "-" || !field.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+
+		properties[name] = s.of(field.Type)
+		if !strings.Contains(options, "omitempty") {
+			required = append(required, name)
+		}
+	}
+
+	schema := map[string]any{"type": "object", "properties": properties}
+	if required != nil {
+		schema["required"] = required
+	}
+	return schema
+}
@@ -0,0 +1,47 @@
+package v1
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestOpenAPI(t *testing.T) {
+	data, err := OpenAPI()
+	require.NoError(t, err)
+
+	var document struct {
+		OpenAPI    string                               `json:"openapi"`
+		Paths      map[string]map[string]map[string]any `json:"paths"`
+		Components struct {
+			Schemas map[string]map[string]any `json:"schemas"`
+		} `json:"components"`
+	}
+	require.NoError(t, json.Unmarshal(data, &document))
+
+	require.Equal(t, "3.0.3", document.OpenAPI)
+	require.Len(t, document.Paths, 6)
+	require.Equal(t, "setRuleEnabled", document.Paths["/rules/{name}/enabled"]["patch"]["operationId"])
+	require.Contains(t, document.Paths["/simulate"]["post"]["requestBody"], "content")
+	require.Equal(t, "rejectPendingConfig", document.Paths["/config/pending"]["delete"]["operationId"])
+
+	require.ElementsMatch(t, []string{"SimulationResult", "PodRef", "RuleStatus", "RuleEnabled", "RetryQueue", "RetryEntry", "PendingConfig", "Error"},
+		keys(document.Components.Schemas))
+
+	podRef := document.Components.Schemas["PodRef"]
+	require.ElementsMatch(t, []any{"rule", "namespace", "name"}, podRef["required"])
+	require.Contains(t, podRef["properties"], "owner")
+
+	status := document.Components.Schemas["RuleStatus"]["properties"].(map[string]any)
+	require.Equal(t, map[string]any{"type": "string", "format": "date-time"}, status["lastRunTime"])
+	require.Equal(t, map[string]any{"type": "integer"}, status["lastMatched"])
+}
+
+func keys(m map[string]map[string]any) []string {
+	out := make([]string, 0, len(m))
+	for key := range m {
+		out = append(out, key)
+	}
+	return out
+}
@@ -0,0 +1,82 @@
+// Package v1 defines the request and response bodies of kubeclean's admin HTTP API and the
+// OpenAPI document describing it, so clients can be generated from or built on them.
+package v1
+
+import "time"
+
+// PodRef identifies a pod matched by a rule.
+type PodRef struct {
+	Rule      string `json:"rule"`
+	Namespace string `json:"namespace"`
+	Name      string `json:"name"`
+	Owner     string `json:"owner,omitempty"` // Top-level owner, e.g. "CronJob default/nightly".
+
+	// ReasonCode is why the rule matched the pod, e.g. "KC1001" when its TTL expired.
+	ReasonCode string `json:"reasonCode,omitempty"`
+
+	// Warnings name finalizers and validating webhooks that would make deleting the pod hang or fail.
+	Warnings []string `json:"warnings,omitempty"`
+}
+
+// SimulationResult is the response of POST /simulate. It compares the pods the posted candidate
+// config would act on with those of the active config.
+type SimulationResult struct {
+	Active    map[string]int `json:"active"`    // Matched pods per rule under the active config.
+	Candidate map[string]int `json:"candidate"` // Matched pods per rule under the candidate config.
+	Added     []PodRef       `json:"added"`     // Pods only the candidate config matches.
+	Removed   []PodRef       `json:"removed"`   // Pods only the active config matches.
+}
+
+// RuleStatus describes a rule's health as of its most recent evaluation. GET /rules/status
+// returns one per rule evaluated since startup, sorted by kind and name.
+type RuleStatus struct {
+	Kind                string    `json:"kind"` // Config section of the rule, such as pod or orphan; names are unique within it.
+	Name                string    `json:"name"`
+	LastRunTime         time.Time `json:"lastRunTime"`
+	LastMatched         int       `json:"lastMatched"`            // Resources the rule matched.
+	LastDeleted         int       `json:"lastDeleted"`            // Resources deleted; zero on dry-runs.
+	LastFailed          int       `json:"lastFailed"`             // Resources whose deletion failed.
+	LastError           string    `json:"lastError,omitempty"`    // Error of the last run, if it failed.
+	ConsecutiveFailures int       `json:"consecutiveFailures"`    // Runs in a row that ended with an error.
+	CooldownRuns        int       `json:"cooldownRuns,omitempty"` // Runs the rule is still skipped for after most of its actions failed.
+	Unavailable         string    `json:"unavailable,omitempty"`  // Why the rule was skipped, such as its kind not being served; empty when it ran.
+}
+
+// RuleEnabled is the request body of PATCH /rules/{name}/enabled and the response of both rule
+// enablement routes.
+type RuleEnabled struct {
+	Rule    string `json:"rule,omitempty"`
+	Kind    string `json:"kind,omitempty"` // Config section of the rule, such as pod or orphan.
+	Enabled *bool  `json:"enabled"`
+}
+
+// RetryEntry is a pod whose deletion failed with a transient error.
+type RetryEntry struct {
+	Rule        string    `json:"rule"`
+	Namespace   string    `json:"namespace"`
+	Name        string    `json:"name"`
+	Attempts    int       `json:"attempts"`   // Failed deletions so far.
+	Reason      string    `json:"reason"`     // Reason of the last failure, e.g. "Timeout".
+	ReasonCode  string    `json:"reasonCode"` // Reason code of the last failure, e.g. "KC3002".
+	Error       string    `json:"error"`      // Message of the last failure.
+	FirstFailed time.Time `json:"firstFailed"`
+	LastFailed  time.Time `json:"lastFailed"`
+}
+
+// RetryQueue is the response of GET /retries.
+type RetryQueue struct {
+	Pending     []RetryEntry `json:"pending"`     // Pods awaiting another attempt, oldest failure first.
+	DeadLetters []RetryEntry `json:"deadLetters"` // Pods given up on, newest first.
+}
+
+// PendingConfig is a reloaded config held back by reloadSafety until it is approved. It is the
+// response of GET /config/pending and of approving or rejecting it.
+type PendingConfig struct {
+	Since      time.Time        `json:"since"`      // When the config was held back.
+	Simulation SimulationResult `json:"simulation"` // Matches of the active config compared with the held config.
+}
+
+// Error is the body of every error response.
+type Error struct {
+	Error string `json:"error"`
+}
@@ -0,0 +1,83 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// CleanupRuleSpec defines a pod cleanup rule scoped to the namespace of its CleanupRule.
+type CleanupRuleSpec struct {
+	// Phase of the pods to clean up, e.g. Succeeded or Failed.
+	// +optional
+	Phase string `json:"phase,omitempty"`
+
+	// TTL after which matching pods are eligible for cleanup.
+	TTL metav1.Duration `json:"ttl"`
+
+	// Selector restricts the rule to pods with these labels.
+	// +optional
+	Selector *metav1.LabelSelector `json:"selector,omitempty"`
+
+	// ExcludeSelector excludes pods with these labels.
+	// +optional
+	ExcludeSelector *metav1.LabelSelector `json:"excludeSelector,omitempty"`
+
+	// DeleteOwnerWhenEmpty deletes the owning Job once all its pods match.
+	// Requires the deleteOwner action to be allowed by the kubeclean administrator.
+	// +optional
+	DeleteOwnerWhenEmpty bool `json:"deleteOwnerWhenEmpty,omitempty"`
+}
+
+// CleanupRuleStatus reports whether kubeclean accepted the rule.
+type CleanupRuleStatus struct {
+	// ObservedGeneration is the generation last evaluated by kubeclean.
+	// +optional
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+
+	// Conditions hold the Accepted condition.
+	// +optional
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:subresource:status
+// +kubebuilder:printcolumn:name="Phase",type=string,JSONPath=`.spec.phase`
+// +kubebuilder:printcolumn:name="TTL",type=string,JSONPath=`.spec.ttl`
+// +kubebuilder:printcolumn:name="Accepted",type=string,JSONPath=`.status.conditions[?(@.type=="Accepted")].status`
+
+// CleanupRule is a tenant-provided pod cleanup rule that only applies to its own namespace.
+type CleanupRule struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   CleanupRuleSpec   `json:"spec,omitempty"`
+	Status CleanupRuleStatus `json:"status,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// CleanupRuleList contains a list of CleanupRule.
+type CleanupRuleList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []CleanupRule `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&CleanupRule{}, &CleanupRuleList{})
+}
@@ -0,0 +1,110 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"syscall"
+	"text/tabwriter"
+	"time"
+
+	"github.com/infrautils/kubeclean/internal/bench"
+	"github.com/infrautils/kubeclean/internal/offline"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log/zap"
+)
+
+// benchReport is the output of `kubeclean bench`.
+type benchReport struct {
+	Rounds     []bench.Result `json:"rounds"`
+	HeapGrowth float64        `json:"heapGrowth"` // Live heap growth from the first to the last round.
+}
+
+// runBench implements `kubeclean bench`. It creates synthetic pods in the cluster of the current
+// kubeconfig, such as a kind or envtest cluster, or in memory with -in-memory, and reports how fast
+// they are matched and deleted. With -soak it repeats rounds for the given duration and reports the
+// heap growth, failing when it exceeds -max-heap-growth.
+func runBench(args []string) int {
+	fs := flag.NewFlagSet("bench", flag.ContinueOnError)
+	pods := fs.Int("pods", 1000, "Synthetic pods created per round")
+	namespace := fs.String("namespace", "kubeclean-bench", "Namespace the synthetic pods are created in")
+	batchSize := fs.Int("batch-size", 100, "Pods deleted per batch")
+	inMemory := fs.Bool("in-memory", false, "Benchmark against an in-memory client instead of a cluster")
+	soak := fs.Duration("soak", 0, "Repeat rounds for this long to detect memory leaks; 0 runs a single round")
+	maxHeapGrowth := fs.Float64("max-heap-growth", 0, "Fail a soak run when the live heap grows by more than "+
+		"this fraction, e.g. 0.5 for 50%; 0 disables the check")
+	output := outputFlag(fs, outputTable)
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+
+	if err := checkOutputFormat(*output); err != nil {
+		fmt.Fprintf(os.Stderr, "bench: %v\n", err)
+		return 2
+	}
+	if *pods <= 0 || *batchSize <= 0 {
+		fmt.Fprintln(os.Stderr, "bench: -pods and -batch-size must be positive")
+		return 2
+	}
+
+	// Per-pod deletion logs would dominate the measured time.
+	ctrl.SetLogger(zap.New(zap.WriteTo(io.Discard)))
+
+	var k8sClient client.Client
+	if *inMemory {
+		k8sClient = offline.NewClient(scheme, nil)
+	} else {
+		var err error
+		if k8sClient, err = client.New(ctrl.GetConfigOrDie(), client.Options{Scheme: scheme}); err != nil {
+			fmt.Fprintf(os.Stderr, "bench: unable to create client: %v\n", err)
+			return 1
+		}
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	opts := bench.Options{Pods: *pods, Namespace: *namespace, BatchSize: *batchSize}
+	deadline := time.Now().Add(*soak)
+	var report benchReport
+	for round := 1; round == 1 || time.Now().Before(deadline); round++ {
+		result, err := bench.Run(ctx, k8sClient, opts, round)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "bench: round %d: %v\n", round, err)
+			return 1
+		}
+		report.Rounds = append(report.Rounds, result)
+		if *soak > 0 {
+			fmt.Fprintf(os.Stderr, "bench: round %d: %d pods, heap in use %d bytes\n", round, result.Deleted, result.HeapInUseBytes)
+		}
+	}
+	report.HeapGrowth = bench.HeapGrowth(report.Rounds)
+
+	if err := writeOutput(os.Stdout, *output, report, func(w *tabwriter.Writer) { benchTable(w, report) }); err != nil {
+		fmt.Fprintf(os.Stderr, "bench: %v\n", err)
+		return 1
+	}
+
+	if *maxHeapGrowth > 0 && report.HeapGrowth > *maxHeapGrowth {
+		fmt.Fprintf(os.Stderr, "bench: heap grew by %.0f%%, more than the allowed %.0f%%\n", report.HeapGrowth*100, *maxHeapGrowth*100)
+		return 1
+	}
+	return 0
+}
+
+// benchTable renders one line per round followed by the heap growth of a soak run.
+func benchTable(w *tabwriter.Writer, report benchReport) {
+	fmt.Fprintln(w, "ROUND\tPODS\tMATCHED\tDELETED\tCREATE\tMATCH\tMATCH/S\tDELETE\tDELETE/S\tHEAP")
+	for _, r := range report.Rounds {
+		fmt.Fprintf(w, "%d\t%d\t%d\t%d\t%s\t%s\t%.0f\t%s\t%.0f\t%.1fMiB\n", r.Round, r.Pods, r.Matched, r.Deleted,
+			r.Create.Round(time.Millisecond), r.Match.Round(time.Millisecond), r.MatchPerSecond,
+			r.Delete.Round(time.Millisecond), r.DeletePerSecond, float64(r.HeapInUseBytes)/(1<<20))
+	}
+	if len(report.Rounds) > 1 {
+		fmt.Fprintf(w, "\nHeap growth over %d rounds: %.1f%%\n", len(report.Rounds), report.HeapGrowth*100)
+	}
+}
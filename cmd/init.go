@@ -0,0 +1,57 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/infrautils/kubeclean/internal/wizard"
+)
+
+// runInit implements `kubeclean init`. It surveys the namespaces, workload labels and terminated
+// pods of the cluster and writes a commented starter config tuned to them.
+func runInit(args []string) int {
+	fs := flag.NewFlagSet("init", flag.ContinueOnError)
+	outputPath := fs.String("f", "-", "File to write the config to; - writes to stdout")
+	snapshotPath := fs.String("snapshot", "", "Snapshot file from kubeclean snapshot export to survey instead of the cluster")
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+
+	k8sClient, now, err := newClient(*snapshotPath)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "init: unable to create client: %v\n", err)
+		return 1
+	}
+
+	surveyedAt := time.Now()
+	if now != nil {
+		surveyedAt = now()
+	}
+	obs, err := wizard.Survey(context.Background(), k8sClient, surveyedAt)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "init: %v\n", err)
+		return 1
+	}
+
+	var w io.Writer = os.Stdout
+	if *outputPath != "-" {
+		file, err := os.Create(*outputPath)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "init: %v\n", err)
+			return 1
+		}
+		defer file.Close()
+		w = file
+	}
+
+	if err := wizard.Render(w, obs); err != nil {
+		fmt.Fprintf(os.Stderr, "init: %v\n", err)
+		return 1
+	}
+
+	return 0
+}
@@ -0,0 +1,124 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"slices"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	cleanupconfig "github.com/infrautils/kubeclean/internal/cleanup_config"
+	"github.com/infrautils/kubeclean/internal/ledger"
+)
+
+// ledgerVerification is the output of `kubeclean ledger verify`.
+type ledgerVerification struct {
+	Path    string `json:"path"`
+	Valid   bool   `json:"valid"`
+	Records int    `json:"records"`
+	First   int    `json:"first,omitempty"` // Sequence number of the first record; above 1 once rotated files were removed.
+	Head    string `json:"head,omitempty"`  // Hash of the last record.
+	Error   string `json:"error,omitempty"`
+}
+
+// runLedger implements `kubeclean ledger verify|export -f ledger.jsonl`. verify checks the hash
+// chain of a run ledger; export writes its records once they are verified.
+func runLedger(args []string) int {
+	if len(args) == 0 || (args[0] != "verify" && args[0] != "export") {
+		fmt.Fprintln(os.Stderr, "ledger: usage: kubeclean ledger verify|export [-f file]")
+		return 2
+	}
+	command := args[0]
+
+	fs := flag.NewFlagSet("ledger "+command, flag.ContinueOnError)
+	ledgerPath := fs.String("f", cleanupconfig.DefaultLedgerPath, "Ledger file to read; - reads from stdin")
+	anchor := fs.String("anchor", "", "Hash of a record recorded outside the ledger, e.g. in kubeclean's logs, "+
+		"that the ledger must still contain")
+	defaultOutput := outputTable
+	if command == "export" {
+		defaultOutput = outputJSON
+	}
+	output := outputFlag(fs, defaultOutput)
+	if err := fs.Parse(args[1:]); err != nil {
+		return 2
+	}
+
+	if err := checkOutputFormat(*output); err != nil {
+		fmt.Fprintf(os.Stderr, "ledger: %v\n", err)
+		return 2
+	}
+
+	records, err := readLedger(*ledgerPath)
+	if err == nil && *anchor != "" && !slices.ContainsFunc(records, func(r ledger.Record) bool { return r.Hash == *anchor }) {
+		err = fmt.Errorf("no record has the anchor hash %s; the ledger was rewritten or truncated", *anchor)
+	}
+
+	if command == "export" {
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "ledger: %v\n", err)
+			return 1
+		}
+		if err := writeOutput(os.Stdout, *output, records, func(w *tabwriter.Writer) {
+			fmt.Fprintln(w, "SEQ\tTIME\tRUN\tDRY RUN\tMATCHED\tDELETED\tFAILURES\tHASH")
+			for _, record := range records {
+				fmt.Fprintf(w, "%d\t%s\t%s\t%t\t%d\t%s\t%d\t%s\n", record.Seq, record.Time.Format(time.RFC3339),
+					record.RunID, record.DryRun, record.Matched, formatDeleted(record.Deleted), record.DeleteFailures, record.Hash)
+			}
+		}); err != nil {
+			fmt.Fprintf(os.Stderr, "ledger: %v\n", err)
+			return 1
+		}
+		return 0
+	}
+
+	result := ledgerVerification{Path: *ledgerPath, Valid: err == nil, Records: len(records)}
+	if err != nil {
+		result.Error = err.Error()
+	} else if len(records) > 0 {
+		result.First = records[0].Seq
+		result.Head = records[len(records)-1].Hash
+	}
+
+	if err := writeOutput(os.Stdout, *output, result, func(w *tabwriter.Writer) {
+		if result.Valid && result.First > 1 {
+			fmt.Fprintf(w, "%s: valid, %d record(s) from record %d, head %s\n", result.Path, result.Records, result.First, result.Head)
+		} else if result.Valid {
+			fmt.Fprintf(w, "%s: valid, %d record(s), head %s\n", result.Path, result.Records, result.Head)
+		} else {
+			fmt.Fprintf(w, "%s: invalid\n%s\n", result.Path, result.Error)
+		}
+	}); err != nil {
+		fmt.Fprintf(os.Stderr, "ledger: %v\n", err)
+		return 1
+	}
+
+	if !result.Valid {
+		return 1
+	}
+	return 0
+}
+
+// readLedger reads and verifies the ledger at path, together with the files it was rotated to,
+// or stdin for -.
+func readLedger(path string) ([]ledger.Record, error) {
+	if path == "-" {
+		return ledger.Read(os.Stdin)
+	}
+	if _, err := os.Stat(path); err != nil {
+		return nil, err
+	}
+	return ledger.ReadFiles(path)
+}
+
+// formatDeleted formats pods deleted per rule as "rule=count,...", sorted by rule.
+func formatDeleted(deleted map[string]int) string {
+	rules := make([]string, 0, len(deleted))
+	for rule, count := range deleted {
+		rules = append(rules, fmt.Sprintf("%s=%d", rule, count))
+	}
+	sort.Strings(rules)
+	return strings.Join(rules, ",")
+}
@@ -17,23 +17,38 @@ limitations under the License.
 package main
 
 import (
+	"context"
+	"crypto/ed25519"
 	"crypto/tls"
+	"crypto/x509"
+	"errors"
 	"flag"
 	"os"
 	"path/filepath"
 	"time"
+	// Embed the IANA time zone database so configured time zones resolve the same regardless of
+	// the zoneinfo of the image or host.
+	_ "time/tzdata"
 
 	// Import all Kubernetes client auth plugins (e.g. Azure, GCP, OIDC, etc.)
 	// to ensure that exec-entrypoint and run can make use of them.
+	kubecleanv1alpha1 "github.com/infrautils/kubeclean/api/v1alpha1"
+	"github.com/infrautils/kubeclean/internal/admin"
+	"github.com/infrautils/kubeclean/internal/auth"
 	cleanupconfig "github.com/infrautils/kubeclean/internal/cleanup_config"
 	"github.com/infrautils/kubeclean/internal/controller"
+	"github.com/infrautils/kubeclean/internal/grpcapi"
+	annotationwebhook "github.com/infrautils/kubeclean/internal/webhook"
 	_ "k8s.io/client-go/plugin/pkg/client/auth"
 
+	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	"k8s.io/client-go/kubernetes"
 	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/certwatcher"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/healthz"
 	"sigs.k8s.io/controller-runtime/pkg/log/zap"
 	"sigs.k8s.io/controller-runtime/pkg/metrics/filters"
@@ -45,16 +60,51 @@ import (
 var (
 	scheme   = runtime.NewScheme()
 	setupLog = ctrl.Log.WithName("setup")
+
+	// version is set at build time with -ldflags "-X main.version=...".
+	version = "dev"
+)
+
+// Client rate limits applied with --low-priority-traffic, well below controller-runtime's defaults.
+const (
+	lowPriorityQPS   = 5
+	lowPriorityBurst = 10
 )
 
 func init() {
 	utilruntime.Must(clientgoscheme.AddToScheme(scheme))
+	utilruntime.Must(kubecleanv1alpha1.AddToScheme(scheme))
 
 	// +kubebuilder:scaffold:scheme
 }
 
 // nolint:gocyclo
 func main() {
+	if len(os.Args) > 1 {
+		switch os.Args[1] {
+		case "simulate":
+			os.Exit(runSimulate(os.Args[2:]))
+		case "run":
+			os.Exit(runOnce(os.Args[2:]))
+		case "preview":
+			os.Exit(runPreview(os.Args[2:]))
+		case "validate":
+			os.Exit(runValidate(os.Args[2:]))
+		case "tui":
+			os.Exit(runTUI(os.Args[2:]))
+		case "test":
+			os.Exit(runTest(os.Args[2:]))
+		case "snapshot":
+			os.Exit(runSnapshot(os.Args[2:]))
+		case "bench":
+			os.Exit(runBench(os.Args[2:]))
+		case "init":
+			os.Exit(runInit(os.Args[2:]))
+		case "ledger":
+			os.Exit(runLedger(os.Args[2:]))
+		}
+	}
+
 	var metricsAddr string
 	var metricsCertPath, metricsCertName, metricsCertKey string
 	var webhookCertPath, webhookCertName, webhookCertKey string
@@ -64,7 +114,18 @@ func main() {
 	var enableHTTP2 bool
 	var tlsOpts []func(*tls.Config)
 	var configPath string
+	var shadowConfigPath string
+	var configURL, configPublicKeyPath string
+	var configPollInterval time.Duration
 	var batchCleanupInterval time.Duration
+	var adminAddr string
+	var grpcAddr string
+	var apiCertPath, apiClientCAFile, apiTokenFile string
+	var apiDelegatedAuth bool
+	var userAgent, fieldManager string
+	var lowPriorityTraffic bool
+	var enableAnnotationWebhook bool
+	var estimateRuleImpact bool
 	flag.StringVar(&metricsAddr, "metrics-bind-address", "0", "The address the metrics endpoint binds to. "+
 		"Use :8443 for HTTPS or :8080 for HTTP, or leave as 0 to disable the metrics service.")
 	flag.StringVar(&probeAddr, "health-probe-bind-address", ":8081", "The address the probe endpoint binds to.")
@@ -83,7 +144,37 @@ func main() {
 	flag.BoolVar(&enableHTTP2, "enable-http2", false,
 		"If set, HTTP/2 will be enabled for the metrics and webhook servers")
 	flag.StringVar(&configPath, "config", "/etc/config/config.yaml", "Path to configuration file")
+	flag.StringVar(&configURL, "config-url", "", "HTTPS URL to fetch the configuration from instead of --config, "+
+		"polled every --config-poll-interval.")
+	flag.DurationVar(&configPollInterval, "config-poll-interval", time.Minute, "Interval for polling --config-url for changes")
+	flag.StringVar(&configPublicKeyPath, "config-public-key", "", "File of an ed25519 public key. If set, the config "+
+		"fetched from --config-url must be signed by its private key, with the base64 signature served at <url>.sig.")
+	flag.StringVar(&shadowConfigPath, "shadow-config", "",
+		"Path to a shadow configuration file whose pod rules are compared with the active config on every run, without acting.")
 	flag.DurationVar(&batchCleanupInterval, "batch-cleanup-interval", time.Minute, "Interval for batch cleanup runs")
+	flag.StringVar(&adminAddr, "admin-bind-address", "0", "The address the admin API binds to. "+
+		"Leave as 0 to disable the admin API.")
+	flag.StringVar(&grpcAddr, "grpc-bind-address", "0", "The address the gRPC API binds to. "+
+		"Leave as 0 to disable the gRPC API.")
+	flag.StringVar(&apiCertPath, "api-cert-path", "", "The directory that contains the tls.crt and tls.key "+
+		"serving the admin and gRPC APIs over TLS. Leave empty to serve them in plaintext.")
+	flag.StringVar(&apiClientCAFile, "api-client-ca-file", "", "CA bundle verifying client certificates. "+
+		"If set, the admin and gRPC APIs require mTLS. Requires --api-cert-path.")
+	flag.StringVar(&apiTokenFile, "api-token-file", "", "File of bearer tokens, one per line, "+
+		"granting full access to the admin and gRPC APIs.")
+	flag.BoolVar(&apiDelegatedAuth, "api-delegated-auth", false, "If set, the admin and gRPC APIs authenticate "+
+		"other bearer tokens with TokenReview and authorize them with SubjectAccessReview.")
+	flag.StringVar(&userAgent, "user-agent", "kubeclean/"+version, "User-Agent sent on API calls. "+
+		"Calls made on behalf of a rule append \" rule=<name>\".")
+	flag.StringVar(&fieldManager, "field-manager", controller.DefaultFieldManager,
+		"Field manager recorded on objects kubeclean writes.")
+	flag.BoolVar(&lowPriorityTraffic, "low-priority-traffic", false,
+		"If set, API calls are rate limited conservatively so cleanups yield to production controllers. "+
+			"Pair with the chart's FlowSchema to also deprioritize them in API priority and fairness.")
+	flag.BoolVar(&enableAnnotationWebhook, "enable-annotation-webhook", false,
+		"If set, serve a validating webhook that rejects objects with malformed kubeclean annotations.")
+	flag.BoolVar(&estimateRuleImpact, "estimate-rule-impact", false,
+		"If set, log how many objects each rule currently matches when the config is loaded at startup.")
 
 	opts := zap.Options{
 		Development: true,
@@ -93,19 +184,36 @@ func main() {
 
 	ctrl.SetLogger(zap.New(zap.UseFlagOptions(&opts)))
 
-	cleanupConfig, err := cleanupconfig.LoadConfigFromFile(configPath)
-
-	if err != nil {
-		setupLog.Error(err, "unable to load config file", "path", configPath)
-		os.Exit(1)
+	var cleanupConfig *cleanupconfig.CleanupConfig
+	var configSource *cleanupconfig.URLSource
+	var err error
+	if configURL != "" {
+		var publicKey ed25519.PublicKey
+		if configPublicKeyPath != "" {
+			if publicKey, err = cleanupconfig.LoadPublicKey(configPublicKeyPath); err != nil {
+				setupLog.Error(err, "unable to load config public key")
+				os.Exit(1)
+			}
+		}
+		if configSource, err = cleanupconfig.NewURLSource(configURL, publicKey); err != nil {
+			setupLog.Error(err, "invalid config URL")
+			os.Exit(1)
+		}
+		if cleanupConfig, err = cleanupconfig.LoadConfigFromURL(context.Background(), configSource); err != nil {
+			setupLog.Error(err, "unable to load config", "url", configURL)
+			os.Exit(1)
+		}
+		setupLog.Info("Loaded config", "url", configURL, "signed", publicKey != nil)
+	} else {
+		if cleanupConfig, err = cleanupconfig.LoadConfigFromFile(configPath); err != nil {
+			setupLog.Error(err, "unable to load config file", "path", configPath)
+			os.Exit(1)
+		}
+		setupLog.Info("Loaded config file", "path", configPath)
 	}
 
-	setupLog.Info("Loaded config file", "path", configPath)
-
 	ctx := ctrl.SetupSignalHandler()
 
-	go cleanupconfig.WatchConfig(ctx, configPath, cleanupConfig, time.NewTicker(30*time.Second))
-
 	// if the enable-http2 flag is false (the default), http/2 should be disabled
 	// due to its vulnerabilities. More specifically, disabling http/2 will
 	// prevent from being vulnerable to the HTTP/2 Stream Cancellation and
@@ -195,13 +303,29 @@ func main() {
 		})
 	}
 
-	mgr, err := ctrl.NewManager(ctrl.GetConfigOrDie(), ctrl.Options{
+	restConfig := ctrl.GetConfigOrDie()
+	restConfig.UserAgent = userAgent
+	restConfig.Wrap(controller.RuleUserAgent(userAgent))
+	apiHealth := controller.NewAPIHealth()
+	restConfig.Wrap(apiHealth.Wrap())
+	if lowPriorityTraffic {
+		restConfig.QPS = lowPriorityQPS
+		restConfig.Burst = lowPriorityBurst
+		setupLog.Info("Low-priority traffic mode enabled", "qps", restConfig.QPS, "burst", restConfig.Burst)
+	}
+
+	mgr, err := ctrl.NewManager(restConfig, ctrl.Options{
 		Scheme:                 scheme,
 		Metrics:                metricsServerOptions,
 		WebhookServer:          webhookServer,
 		HealthProbeBindAddress: probeAddr,
 		LeaderElection:         enableLeaderElection,
 		LeaderElectionID:       "e5c72248.infrautils.github.io",
+		// ConfigMaps (receipts, namespace ownership) are read one at a time; caching every
+		// ConfigMap of the cluster for them would cost far more memory than the reads. Secrets
+		// are only listed by image pull secret rules, once per run, and a cluster-wide Secret
+		// informer would keep every Secret's data in memory.
+		Client: client.Options{Cache: &client.CacheOptions{DisableFor: []client.Object{&corev1.ConfigMap{}, &corev1.Secret{}}}},
 		// LeaderElectionReleaseOnCancel defines if the leader should step down voluntarily
 		// when the Manager ends. This requires the binary to immediately end when the
 		// Manager is stopped, otherwise, this setting is unsafe. Setting this significantly
@@ -219,14 +343,149 @@ func main() {
 		os.Exit(1)
 	}
 
+	if err := controller.ValidateGenericRuleScopes(mgr.GetRESTMapper(), cleanupConfig); err != nil {
+		setupLog.Error(err, "invalid config for this cluster", "path", configPath)
+		os.Exit(1)
+	}
+
 	batchCleanupReconciler := controller.NewPodCleanController(
-		mgr.GetClient(),
+		client.WithFieldOwner(mgr.GetClient(), fieldManager),
 		mgr.GetScheme(),
 		cleanupConfig,
 	)
 
+	clientset, err := kubernetes.NewForConfig(mgr.GetConfig())
+	if err != nil {
+		setupLog.Error(err, "unable to create clientset")
+		os.Exit(1)
+	}
+	batchCleanupReconciler.Logs = clientset.CoreV1()
+	batchCleanupReconciler.APIReader = mgr.GetAPIReader()
+	batchCleanupReconciler.Discovery = clientset.Discovery()
+	batchCleanupReconciler.APIHealth = apiHealth
+
+	// The controller holds back reloads that reloadSafety deems too big a change.
+	if configSource != nil {
+		go cleanupconfig.WatchConfigURL(ctx, configSource, cleanupConfig, time.NewTicker(configPollInterval), batchCleanupReconciler)
+	} else {
+		go cleanupconfig.WatchConfig(ctx, configPath, cleanupConfig, time.NewTicker(30*time.Second), batchCleanupReconciler)
+	}
+
+	if shadowConfigPath != "" {
+		shadowConfig, err := cleanupconfig.LoadConfigFromFile(shadowConfigPath)
+		if err != nil {
+			setupLog.Error(err, "unable to load shadow config file", "path", shadowConfigPath)
+			os.Exit(1)
+		}
+		setupLog.Info("Loaded shadow config file", "path", shadowConfigPath)
+
+		batchCleanupReconciler.ShadowConfig = shadowConfig
+		go cleanupconfig.WatchShadowConfig(ctx, shadowConfigPath, shadowConfig, time.NewTicker(30*time.Second))
+	}
+
+	if estimateRuleImpact {
+		// The manager's client reads from its cache, which only starts with the manager.
+		directClient, err := client.New(mgr.GetConfig(), client.Options{Scheme: mgr.GetScheme(), Mapper: mgr.GetRESTMapper()})
+		if err != nil {
+			setupLog.Error(err, "unable to create client for estimating rule impact")
+			os.Exit(1)
+		}
+		estimator := controller.NewPodCleanController(directClient, mgr.GetScheme(), cleanupConfig)
+		for _, impact := range estimator.EstimateImpact(ctx) {
+			setupLog.Info("Estimated rule impact", "rule", impact.Rule, "kind", impact.Kind,
+				"enabled", impact.Enabled, "matched", impact.Matched, "error", impact.Error)
+		}
+	}
+
 	go controller.RunPodCleanJob(ctx, batchCleanupReconciler, batchCleanupInterval)
 
+	if cleanupConfig.PodCleanupConfig.QuotaPressure.TriggerOnEvents {
+		if err := mgr.Add(controller.NewQuotaEventTrigger(batchCleanupReconciler, clientset.CoreV1())); err != nil {
+			setupLog.Error(err, "unable to add quota event trigger to manager")
+			os.Exit(1)
+		}
+	}
+
+	if cleanupConfig.PodCleanupConfig.NodePressure.Enabled {
+		if err := mgr.Add(controller.NewNodePressureTrigger(batchCleanupReconciler, clientset.CoreV1())); err != nil {
+			setupLog.Error(err, "unable to add node pressure trigger to manager")
+			os.Exit(1)
+		}
+	}
+
+	var apiTLSConfig *tls.Config
+	var apiCertWatcher *certwatcher.CertWatcher
+	if len(apiCertPath) > 0 {
+		setupLog.Info("Initializing API certificate watcher using provided certificates", "api-cert-path", apiCertPath)
+
+		apiCertWatcher, err = certwatcher.New(
+			filepath.Join(apiCertPath, "tls.crt"),
+			filepath.Join(apiCertPath, "tls.key"),
+		)
+		if err != nil {
+			setupLog.Error(err, "unable to initialize API certificate watcher")
+			os.Exit(1)
+		}
+		if err := mgr.Add(apiCertWatcher); err != nil {
+			setupLog.Error(err, "unable to add API certificate watcher to manager")
+			os.Exit(1)
+		}
+
+		apiTLSConfig = &tls.Config{MinVersion: tls.VersionTLS12, GetCertificate: apiCertWatcher.GetCertificate}
+		if len(apiClientCAFile) > 0 {
+			caBundle, err := os.ReadFile(apiClientCAFile)
+			if err != nil {
+				setupLog.Error(err, "unable to read API client CA file")
+				os.Exit(1)
+			}
+			apiTLSConfig.ClientCAs = x509.NewCertPool()
+			if !apiTLSConfig.ClientCAs.AppendCertsFromPEM(caBundle) {
+				setupLog.Error(errors.New("no certificate found"), "invalid API client CA file", "path", apiClientCAFile)
+				os.Exit(1)
+			}
+			apiTLSConfig.ClientAuth = tls.RequireAndVerifyClientCert
+		}
+	} else if len(apiClientCAFile) > 0 {
+		setupLog.Error(errors.New("--api-client-ca-file requires --api-cert-path"), "invalid API TLS flags")
+		os.Exit(1)
+	}
+
+	apiAuthorizer := &auth.Authorizer{}
+	if len(apiTokenFile) > 0 {
+		if apiAuthorizer.Tokens, err = auth.LoadTokens(apiTokenFile); err != nil {
+			setupLog.Error(err, "unable to load API tokens")
+			os.Exit(1)
+		}
+	}
+	if apiDelegatedAuth {
+		apiAuthorizer.Client = mgr.GetClient()
+	}
+
+	if adminAddr != "0" {
+		adminServer := admin.NewServer(adminAddr, batchCleanupReconciler)
+		adminServer.TLSConfig = apiTLSConfig
+		adminServer.Authorizer = apiAuthorizer
+		if err := mgr.Add(adminServer); err != nil {
+			setupLog.Error(err, "unable to add admin API server to manager")
+			os.Exit(1)
+		}
+	}
+
+	if grpcAddr != "0" {
+		grpcServer := grpcapi.NewServer(grpcAddr, batchCleanupReconciler)
+		grpcServer.TLSConfig = apiTLSConfig
+		grpcServer.Authorizer = apiAuthorizer
+		if err := mgr.Add(grpcServer); err != nil {
+			setupLog.Error(err, "unable to add gRPC API server to manager")
+			os.Exit(1)
+		}
+	}
+
+	if enableAnnotationWebhook {
+		mgr.GetWebhookServer().Register(annotationwebhook.AnnotationValidatorPath,
+			&webhook.Admission{Handler: &annotationwebhook.AnnotationValidator{}})
+	}
+
 	// +kubebuilder:scaffold:builder
 
 	if metricsCertWatcher != nil {
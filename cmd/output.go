@@ -0,0 +1,57 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"text/tabwriter"
+
+	"sigs.k8s.io/yaml"
+)
+
+// Output formats accepted by the -o flag of every subcommand. JSON and YAML share the field
+// names of the JSON encoding, which are kept stable for scripts.
+const (
+	outputJSON  = "json"
+	outputYAML  = "yaml"
+	outputTable = "table"
+)
+
+// outputFlag registers -o on fs with the given default format.
+func outputFlag(fs *flag.FlagSet, defaultFormat string) *string {
+	return fs.String("o", defaultFormat, "Output format: json, yaml or table")
+}
+
+// checkOutputFormat rejects formats other than json, yaml and table.
+func checkOutputFormat(format string) error {
+	switch format {
+	case outputJSON, outputYAML, outputTable:
+		return nil
+	default:
+		return fmt.Errorf("unknown output format %q; expected json, yaml or table", format)
+	}
+}
+
+// writeOutput writes v to w in format; table renders the table format.
+func writeOutput(w io.Writer, format string, v interface{}, table func(w *tabwriter.Writer)) error {
+	switch format {
+	case outputJSON:
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(v)
+	case outputYAML:
+		data, err := yaml.Marshal(v)
+		if err != nil {
+			return err
+		}
+		_, err = w.Write(data)
+		return err
+	case outputTable:
+		tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+		table(tw)
+		return tw.Flush()
+	default:
+		return checkOutputFormat(format)
+	}
+}
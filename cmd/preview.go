@@ -0,0 +1,66 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	cleanupconfig "github.com/infrautils/kubeclean/internal/cleanup_config"
+	"github.com/infrautils/kubeclean/internal/controller"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/log/zap"
+)
+
+// runPreview implements `kubeclean preview`. It lists the pods the config's rules currently
+// match without deleting anything.
+func runPreview(args []string) int {
+	fs := flag.NewFlagSet("preview", flag.ContinueOnError)
+	configPath := fs.String("config", "/etc/config/config.yaml", "Path to the configuration file")
+	snapshotPath := fs.String("snapshot", "", "Snapshot file from kubeclean snapshot export to evaluate instead of the cluster")
+	output := outputFlag(fs, outputTable)
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+
+	if err := checkOutputFormat(*output); err != nil {
+		fmt.Fprintf(os.Stderr, "preview: %v\n", err)
+		return 2
+	}
+
+	ctrl.SetLogger(zap.New())
+
+	cfg, err := cleanupconfig.LoadConfigFromFile(*configPath)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "preview: %v\n", err)
+		return 1
+	}
+
+	k8sClient, now, err := newClient(*snapshotPath)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "preview: unable to create client: %v\n", err)
+		return 1
+	}
+
+	podCleanController := controller.NewPodCleanController(k8sClient, scheme, cfg)
+	podCleanController.Now = now
+	refs := podCleanController.Preview(context.Background())
+	if refs == nil {
+		refs = []controller.PodRef{}
+	}
+
+	if err := writeOutput(os.Stdout, *output, refs, func(w *tabwriter.Writer) {
+		fmt.Fprintln(w, "RULE\tNAMESPACE\tPOD\tREASON\tOWNER\tWARNINGS")
+		for _, ref := range refs {
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s %s\t%s\t%s\n", ref.Rule, ref.Namespace, ref.Name, ref.ReasonCode, ref.ReasonCode.Name(),
+				ref.Owner, strings.Join(ref.Warnings, "; "))
+		}
+	}); err != nil {
+		fmt.Fprintf(os.Stderr, "preview: %v\n", err)
+		return 1
+	}
+
+	return 0
+}
@@ -0,0 +1,106 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"sort"
+	"text/tabwriter"
+
+	cleanupconfig "github.com/infrautils/kubeclean/internal/cleanup_config"
+	"github.com/infrautils/kubeclean/internal/controller"
+	"k8s.io/client-go/kubernetes"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log/zap"
+)
+
+// runOnce implements `kubeclean run`. It performs a single cleanup pass, prints its summary and
+// exits with the status the config's exitStatus policy assigns to the outcome, for use in CronJobs.
+func runOnce(args []string) int {
+	fs := flag.NewFlagSet("run", flag.ContinueOnError)
+	configPath := fs.String("config", "/etc/config/config.yaml", "Path to the configuration file")
+	output := outputFlag(fs, outputTable)
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+
+	if err := checkOutputFormat(*output); err != nil {
+		fmt.Fprintf(os.Stderr, "run: %v\n", err)
+		return 2
+	}
+
+	ctrl.SetLogger(zap.New(zap.WriteTo(os.Stderr)))
+
+	cfg, err := cleanupconfig.LoadConfigFromFile(*configPath)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "run: %v\n", err)
+		return 1
+	}
+	cfg.SetDefaults()
+	if err := controller.ValidateRunOnce(cfg); err != nil {
+		fmt.Fprintf(os.Stderr, "run: invalid config: %v\n", err)
+		return 1
+	}
+	for _, warning := range controller.RunOnceWarnings(cfg) {
+		fmt.Fprintf(os.Stderr, "run: warning: %s\n", warning)
+	}
+
+	restConfig := ctrl.GetConfigOrDie()
+	apiHealth := controller.NewAPIHealth()
+	restConfig.Wrap(apiHealth.Wrap())
+	k8sClient, err := client.New(restConfig, client.Options{Scheme: scheme})
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "run: unable to create client: %v\n", err)
+		return 1
+	}
+	clientset, err := kubernetes.NewForConfig(restConfig)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "run: unable to create client: %v\n", err)
+		return 1
+	}
+	if err := controller.ValidateGenericRuleScopes(k8sClient.RESTMapper(), cfg); err != nil {
+		fmt.Fprintf(os.Stderr, "run: invalid config: %v\n", err)
+		return 1
+	}
+
+	podCleanController := controller.NewPodCleanController(k8sClient, scheme, cfg)
+	podCleanController.Logs = clientset.CoreV1()
+	podCleanController.Discovery = clientset.Discovery()
+	podCleanController.APIHealth = apiHealth
+	summary := podCleanController.RunCleanUp(context.Background())
+
+	if err := writeOutput(os.Stdout, *output, summary, func(w *tabwriter.Writer) { summaryTable(w, summary) }); err != nil {
+		fmt.Fprintf(os.Stderr, "run: %v\n", err)
+		return 1
+	}
+
+	return summary.ExitStatus(cfg.ExitStatus)
+}
+
+// summaryTable renders a run summary as per-rule match counts followed by the run's totals.
+func summaryTable(w *tabwriter.Writer, summary controller.RunSummary) {
+	rules := make([]string, 0, len(summary.MatchedByRule))
+	for rule := range summary.MatchedByRule {
+		rules = append(rules, rule)
+	}
+	sort.Strings(rules)
+
+	fmt.Fprintln(w, "RULE\tMATCHED\tNEW")
+	for _, rule := range rules {
+		fmt.Fprintf(w, "%s\t%d\t%d\n", rule, summary.MatchedByRule[rule], summary.NewByRule[rule])
+	}
+
+	fmt.Fprintf(w, "\nRun %s: %d matched, %d deferred, %d failed deletion(s)", summary.RunID, summary.Matched, summary.Deferred, summary.DeleteFailures)
+	if reasons := summary.TopDeleteErrors(3); reasons != "" {
+		fmt.Fprintf(w, " (%s)", reasons)
+	}
+	if summary.Retried > 0 || summary.DeadLettered > 0 {
+		fmt.Fprintf(w, ", %d retried, %d given up", summary.Retried, summary.DeadLettered)
+	}
+	if forbidden := summary.ListErrors[controller.ErrorReasonForbidden]; forbidden > 0 {
+		fmt.Fprintf(w, ", %d forbidden list call(s)", forbidden)
+	}
+	fmt.Fprintln(w)
+}
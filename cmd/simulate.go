@@ -0,0 +1,98 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"sort"
+	"text/tabwriter"
+
+	cleanupconfig "github.com/infrautils/kubeclean/internal/cleanup_config"
+	"github.com/infrautils/kubeclean/internal/controller"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/log/zap"
+)
+
+// runSimulate implements `kubeclean simulate -f candidate.yaml`. It evaluates the candidate config
+// against the live cluster without deleting anything and prints the delta to the active config.
+func runSimulate(args []string) int {
+	fs := flag.NewFlagSet("simulate", flag.ContinueOnError)
+	candidatePath := fs.String("f", "", "Path to the candidate configuration file")
+	activePath := fs.String("config", "/etc/config/config.yaml", "Path to the active configuration file")
+	snapshotPath := fs.String("snapshot", "", "Snapshot file from kubeclean snapshot export to evaluate instead of the cluster")
+	output := outputFlag(fs, outputJSON)
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+
+	if err := checkOutputFormat(*output); err != nil {
+		fmt.Fprintf(os.Stderr, "simulate: %v\n", err)
+		return 2
+	}
+
+	if *candidatePath == "" {
+		fmt.Fprintln(os.Stderr, "simulate: -f is required")
+		return 2
+	}
+
+	ctrl.SetLogger(zap.New())
+
+	active, err := cleanupconfig.LoadConfigFromFile(*activePath)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "simulate: %v\n", err)
+		return 1
+	}
+
+	candidate, err := cleanupconfig.LoadConfigFromFile(*candidatePath)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "simulate: %v\n", err)
+		return 1
+	}
+
+	k8sClient, now, err := newClient(*snapshotPath)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "simulate: unable to create client: %v\n", err)
+		return 1
+	}
+
+	podCleanController := controller.NewPodCleanController(k8sClient, scheme, active)
+	podCleanController.Now = now
+	result := podCleanController.Simulate(context.Background(), candidate)
+
+	if err := writeOutput(os.Stdout, *output, result, func(w *tabwriter.Writer) { simulationTable(w, result) }); err != nil {
+		fmt.Fprintf(os.Stderr, "simulate: %v\n", err)
+		return 1
+	}
+
+	return 0
+}
+
+// simulationTable renders a simulation result as per-rule counts followed by the changed pods.
+func simulationTable(w *tabwriter.Writer, result controller.SimulationResult) {
+	rules := map[string]struct{}{}
+	for rule := range result.Active {
+		rules[rule] = struct{}{}
+	}
+	for rule := range result.Candidate {
+		rules[rule] = struct{}{}
+	}
+	names := make([]string, 0, len(rules))
+	for rule := range rules {
+		names = append(names, rule)
+	}
+	sort.Strings(names)
+
+	fmt.Fprintln(w, "RULE\tACTIVE\tCANDIDATE")
+	for _, rule := range names {
+		fmt.Fprintf(w, "%s\t%d\t%d\n", rule, result.Active[rule], result.Candidate[rule])
+	}
+
+	fmt.Fprintln(w, "\nCHANGE\tRULE\tNAMESPACE\tPOD\tOWNER")
+	for _, ref := range result.Added {
+		fmt.Fprintf(w, "added\t%s\t%s\t%s\t%s\n", ref.Rule, ref.Namespace, ref.Name, ref.Owner)
+	}
+	for _, ref := range result.Removed {
+		fmt.Fprintf(w, "removed\t%s\t%s\t%s\t%s\n", ref.Rule, ref.Namespace, ref.Name, ref.Owner)
+	}
+}
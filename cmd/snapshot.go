@@ -0,0 +1,83 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/infrautils/kubeclean/internal/offline"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// runSnapshot implements `kubeclean snapshot export -f snapshot.yaml`. It dumps the objects pod
+// rules are evaluated against, so that match behavior can be reproduced offline with --snapshot.
+func runSnapshot(args []string) int {
+	if len(args) == 0 || args[0] != "export" {
+		fmt.Fprintln(os.Stderr, "snapshot: usage: kubeclean snapshot export [-f file]")
+		return 2
+	}
+
+	fs := flag.NewFlagSet("snapshot export", flag.ContinueOnError)
+	outputPath := fs.String("f", "-", "File to write the snapshot to; - writes to stdout")
+	if err := fs.Parse(args[1:]); err != nil {
+		return 2
+	}
+
+	k8sClient, err := client.New(ctrl.GetConfigOrDie(), client.Options{Scheme: scheme})
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "snapshot: unable to create client: %v\n", err)
+		return 1
+	}
+
+	var w io.Writer = os.Stdout
+	if *outputPath != "-" {
+		file, err := os.Create(*outputPath)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "snapshot: %v\n", err)
+			return 1
+		}
+		defer file.Close()
+		w = file
+	}
+
+	if err := offline.Export(context.Background(), k8sClient, scheme, w); err != nil {
+		fmt.Fprintf(os.Stderr, "snapshot: %v\n", err)
+		return 1
+	}
+
+	return 0
+}
+
+// newClient returns a client for the cluster, or one serving the objects of snapshotPath when set.
+// The returned clock is when the snapshot was exported, which rules are evaluated against; it is
+// nil for the cluster and for snapshots without an export time, meaning the current time.
+func newClient(snapshotPath string) (client.Client, func() time.Time, error) {
+	if snapshotPath == "" {
+		k8sClient, err := client.New(ctrl.GetConfigOrDie(), client.Options{Scheme: scheme})
+		return k8sClient, nil, err
+	}
+
+	objects, err := offline.LoadObjects(snapshotPath, scheme)
+	if err != nil {
+		return nil, nil, err
+	}
+	now, err := snapshotClock(snapshotPath)
+	if err != nil {
+		return nil, nil, err
+	}
+	return offline.NewClient(scheme, objects), now, nil
+}
+
+// snapshotClock returns a clock stopped at the export time of the snapshot at path, or nil when
+// path holds no export time, such as a fixture directory.
+func snapshotClock(path string) (func() time.Time, error) {
+	exportedAt, err := offline.ExportedAt(path)
+	if err != nil || exportedAt.IsZero() {
+		return nil, err
+	}
+	return func() time.Time { return exportedAt }, nil
+}
@@ -0,0 +1,111 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	cleanupconfig "github.com/infrautils/kubeclean/internal/cleanup_config"
+	"github.com/infrautils/kubeclean/internal/controller"
+	"github.com/infrautils/kubeclean/internal/offline"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/log/zap"
+)
+
+// runTest implements `kubeclean test -config config.yaml -fixtures dir [-now time]`. It evaluates the rules
+// against the objects in the fixture directory instead of a cluster and exits non-zero when a
+// rule's matches differ from the directory's expectations.yaml.
+func runTest(args []string) int {
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	configPath := fs.String("config", "/etc/config/config.yaml", "Path to the configuration file")
+	fixturesDir := fs.String("fixtures", "", "Directory of YAML fixtures, such as pods, jobs and namespaces, or a snapshot file")
+	expectPath := fs.String("expect", "", "Path to the expected matches; defaults to expectations.yaml in the fixture directory")
+	nowFlag := fs.String("now", "", "RFC 3339 time TTLs are evaluated against, such as 2026-01-02T15:04:05Z; defaults to a snapshot's export time, or the current time")
+	output := outputFlag(fs, outputTable)
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+
+	if err := checkOutputFormat(*output); err != nil {
+		fmt.Fprintf(os.Stderr, "test: %v\n", err)
+		return 2
+	}
+
+	if *fixturesDir == "" {
+		fmt.Fprintln(os.Stderr, "test: -fixtures is required")
+		return 2
+	}
+	if *expectPath == "" {
+		*expectPath = filepath.Join(*fixturesDir, offline.ExpectationsFile)
+	}
+
+	// Fixtures are recorded once, so their ages only stay meaningful against a fixed time.
+	var now func() time.Time
+	if *nowFlag != "" {
+		at, err := time.Parse(time.RFC3339, *nowFlag)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "test: invalid -now: %v\n", err)
+			return 2
+		}
+		now = func() time.Time { return at }
+	}
+
+	ctrl.SetLogger(zap.New(zap.WriteTo(os.Stderr)))
+
+	cfg, err := cleanupconfig.LoadConfigFromFile(*configPath)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "test: %v\n", err)
+		return 1
+	}
+
+	objects, err := offline.LoadObjects(*fixturesDir, scheme)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "test: %v\n", err)
+		return 1
+	}
+
+	expectations, err := offline.LoadExpectations(*expectPath)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "test: %v\n", err)
+		return 1
+	}
+
+	if now == nil {
+		if now, err = snapshotClock(*fixturesDir); err != nil {
+			fmt.Fprintf(os.Stderr, "test: %v\n", err)
+			return 1
+		}
+	}
+
+	k8sClient := offline.NewClient(scheme, objects)
+	podCleanController := controller.NewPodCleanController(k8sClient, scheme, cfg)
+	podCleanController.Now = now
+	matched := podCleanController.Preview(context.Background())
+	results := offline.Check(expectations, matched)
+
+	if err := writeOutput(os.Stdout, *output, results, func(w *tabwriter.Writer) {
+		fmt.Fprintln(w, "RULE\tRESULT\tUNEXPECTED\tMISSING")
+		for _, result := range results {
+			status := "pass"
+			if !result.Passed {
+				status = "FAIL"
+			}
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", result.Rule, status, strings.Join(result.Unexpected, ","), strings.Join(result.Missing, ","))
+		}
+	}); err != nil {
+		fmt.Fprintf(os.Stderr, "test: %v\n", err)
+		return 1
+	}
+
+	for _, result := range results {
+		if !result.Passed {
+			return 1
+		}
+	}
+	return 0
+}
@@ -0,0 +1,230 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+
+	cleanupconfig "github.com/infrautils/kubeclean/internal/cleanup_config"
+	"github.com/infrautils/kubeclean/internal/controller"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log/zap"
+)
+
+const tuiHelp = `Commands:
+  <n>[,<n>|<n>-<m>...]  toggle the selection of rows, e.g. "1,4-7"
+  all [rule]            select every row, or every row of a rule
+  none [rule]           deselect every row, or every row of a rule
+  delete                delete the selected pods after confirmation
+  refresh               re-evaluate the rules against the cluster
+  help                  show this help
+  quit                  exit without deleting anything`
+
+// runTUI implements `kubeclean tui`. It previews the pods the config's rules match and lets an
+// operator select which of them to delete, for human-in-the-loop cleanups.
+func runTUI(args []string) int {
+	fs := flag.NewFlagSet("tui", flag.ContinueOnError)
+	configPath := fs.String("config", "/etc/config/config.yaml", "Path to the configuration file")
+	logPath := fs.String("log-file", filepath.Join(os.TempDir(), "kubeclean-tui.log"), "File the log is appended to, keeping it off the screen")
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+
+	// Log lines would garble the screen, but they explain skipped pods and failed deletions.
+	logFile, err := os.OpenFile(*logPath, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0o600)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "tui: %v\n", err)
+		return 1
+	}
+	defer logFile.Close()
+	ctrl.SetLogger(zap.New(zap.WriteTo(logFile)))
+
+	cfg, err := cleanupconfig.LoadConfigFromFile(*configPath)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "tui: %v\n", err)
+		return 1
+	}
+
+	k8sClient, err := client.New(ctrl.GetConfigOrDie(), client.Options{Scheme: scheme})
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "tui: unable to create client: %v\n", err)
+		return 1
+	}
+
+	fmt.Fprintf(os.Stdout, "Logging to %s\n", *logPath)
+	session := &tuiSession{
+		controller: controller.NewPodCleanController(k8sClient, scheme, cfg),
+		in:         bufio.NewScanner(os.Stdin),
+		out:        os.Stdout,
+	}
+	if err := session.run(context.Background()); err != nil {
+		fmt.Fprintf(os.Stderr, "tui: %v\n", err)
+		return 1
+	}
+
+	return 0
+}
+
+// tuiSession holds the rows on screen and which of them are selected.
+type tuiSession struct {
+	controller *controller.PodCleanController
+	in         *bufio.Scanner
+	out        io.Writer
+
+	rows     []controller.PodRef
+	selected []bool
+}
+
+func (s *tuiSession) run(ctx context.Context) error {
+	s.refresh(ctx)
+
+	for {
+		s.render()
+		fmt.Fprint(s.out, "> ")
+		if !s.in.Scan() {
+			return s.in.Err()
+		}
+
+		fields := strings.Fields(s.in.Text())
+		if len(fields) == 0 {
+			continue
+		}
+
+		switch command, arg := fields[0], strings.Join(fields[1:], " "); command {
+		case "q", "quit", "exit":
+			return nil
+		case "h", "help", "?":
+			fmt.Fprintln(s.out, tuiHelp)
+		case "r", "refresh":
+			s.refresh(ctx)
+		case "all", "none":
+			s.selectRule(arg, command == "all")
+		case "d", "delete":
+			s.deleteSelected(ctx)
+		default:
+			if err := s.toggle(command); err != nil {
+				fmt.Fprintf(s.out, "%v; type \"help\" for commands\n", err)
+			}
+		}
+	}
+}
+
+// refresh re-evaluates the rules and clears the selection.
+func (s *tuiSession) refresh(ctx context.Context) {
+	s.rows = s.controller.Preview(ctx)
+	s.selected = make([]bool, len(s.rows))
+}
+
+func (s *tuiSession) render() {
+	counts := map[string]int{}
+	var rules []string
+	for _, row := range s.rows {
+		if counts[row.Rule] == 0 {
+			rules = append(rules, row.Rule)
+		}
+		counts[row.Rule]++
+	}
+
+	w := tabwriter.NewWriter(s.out, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, "\nRULE\tCANDIDATES")
+	for _, rule := range rules {
+		fmt.Fprintf(w, "%s\t%d\n", rule, counts[rule])
+	}
+	fmt.Fprintln(w, "\n\t#\tRULE\tNAMESPACE\tPOD\tOWNER\tWARNINGS")
+	for i, row := range s.rows {
+		mark := "[ ]"
+		if s.selected[i] {
+			mark = "[x]"
+		}
+		fmt.Fprintf(w, "%s\t%d\t%s\t%s\t%s\t%s\t%s\n", mark, i+1, row.Rule, row.Namespace, row.Name, row.Owner, strings.Join(row.Warnings, "; "))
+	}
+	_ = w.Flush()
+
+	if s.controller.CleanupConfig.DryRun {
+		fmt.Fprintln(s.out, "dryRun is set in the config: deletions will only be logged.")
+	}
+	fmt.Fprintf(s.out, "%d of %d pod(s) selected\n", s.selectedCount(), len(s.rows))
+}
+
+// toggle flips the selection of the rows in spec, a comma-separated list of 1-based rows or ranges.
+func (s *tuiSession) toggle(spec string) error {
+	for _, part := range strings.Split(spec, ",") {
+		first, last, isRange := strings.Cut(part, "-")
+		if !isRange {
+			last = first
+		}
+
+		from, err := strconv.Atoi(first)
+		if err != nil {
+			return fmt.Errorf("unknown command %q", spec)
+		}
+		to, err := strconv.Atoi(last)
+		if err != nil {
+			return fmt.Errorf("unknown command %q", spec)
+		}
+		if from < 1 || to > len(s.rows) || from > to {
+			return fmt.Errorf("rows must be between 1 and %d", len(s.rows))
+		}
+
+		for i := from - 1; i < to; i++ {
+			s.selected[i] = !s.selected[i]
+		}
+	}
+	return nil
+}
+
+// selectRule sets the selection of every row, or of the named rule's rows.
+func (s *tuiSession) selectRule(rule string, selected bool) {
+	for i, row := range s.rows {
+		if rule == "" || row.Rule == rule {
+			s.selected[i] = selected
+		}
+	}
+}
+
+func (s *tuiSession) selectedCount() int {
+	count := 0
+	for _, selected := range s.selected {
+		if selected {
+			count++
+		}
+	}
+	return count
+}
+
+// deleteSelected deletes the selected pods once the operator confirms, then refreshes the rows.
+func (s *tuiSession) deleteSelected(ctx context.Context) {
+	var refs []controller.PodRef
+	for i, row := range s.rows {
+		if s.selected[i] {
+			refs = append(refs, row)
+		}
+	}
+
+	if len(refs) == 0 {
+		fmt.Fprintln(s.out, "No pods selected.")
+		return
+	}
+
+	fmt.Fprintf(s.out, "Delete %d pod(s)? Type \"yes\" to confirm: ", len(refs))
+	if !s.in.Scan() || strings.TrimSpace(s.in.Text()) != "yes" {
+		fmt.Fprintln(s.out, "Aborted.")
+		return
+	}
+
+	deleted, err := s.controller.DeletePods(ctx, refs)
+	fmt.Fprintf(s.out, "Deleted %d of %d pod(s).\n", deleted, len(refs))
+	if err != nil {
+		fmt.Fprintf(s.out, "Some deletions failed:\n%v\n", err)
+	}
+
+	s.refresh(ctx)
+}
@@ -0,0 +1,99 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	cleanupconfig "github.com/infrautils/kubeclean/internal/cleanup_config"
+	"github.com/infrautils/kubeclean/internal/controller"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/log/zap"
+)
+
+// validationResult is the output of `kubeclean validate`.
+type validationResult struct {
+	Path  string `json:"path"`
+	Valid bool   `json:"valid"`
+	Error string `json:"error,omitempty"`
+
+	Impact []controller.RuleImpact `json:"impact,omitempty"` // Set with --impact.
+}
+
+// runValidate implements `kubeclean validate -f config.yaml`. It loads and validates the config
+// without contacting the cluster and exits non-zero when the config is invalid. With --impact it
+// also counts the objects each rule currently matches, and with --max-impact fails when a rule
+// matches more than that.
+func runValidate(args []string) int {
+	fs := flag.NewFlagSet("validate", flag.ContinueOnError)
+	configPath := fs.String("f", "/etc/config/config.yaml", "Path to the configuration file")
+	impact := fs.Bool("impact", false, "Count the objects each rule currently matches in the cluster")
+	maxImpact := fs.Int("max-impact", 0, "With --impact, fail if a rule matches more objects than this; 0 means no limit")
+	snapshotPath := fs.String("snapshot", "", "Snapshot file from kubeclean snapshot export to count matches in instead of the cluster")
+	output := outputFlag(fs, outputTable)
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+
+	if err := checkOutputFormat(*output); err != nil {
+		fmt.Fprintf(os.Stderr, "validate: %v\n", err)
+		return 2
+	}
+
+	result := validationResult{Path: *configPath, Valid: true}
+	cfg, err := cleanupconfig.LoadConfigFromFile(*configPath)
+	if err != nil {
+		result.Valid = false
+		result.Error = err.Error()
+	}
+
+	if *impact && result.Valid {
+		ctrl.SetLogger(zap.New())
+
+		k8sClient, now, err := newClient(*snapshotPath)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "validate: unable to create client: %v\n", err)
+			return 1
+		}
+
+		podCleanController := controller.NewPodCleanController(k8sClient, scheme, cfg)
+		podCleanController.Now = now
+		result.Impact = podCleanController.EstimateImpact(context.Background())
+		var exceeded []string
+		for _, ruleImpact := range result.Impact {
+			if *maxImpact > 0 && ruleImpact.Matched > *maxImpact {
+				exceeded = append(exceeded, fmt.Sprintf("rule %s matches %d %s(s), more than --max-impact %d",
+					ruleImpact.Rule, ruleImpact.Matched, ruleImpact.Kind, *maxImpact))
+			}
+		}
+		if len(exceeded) > 0 {
+			result.Valid = false
+			result.Error = strings.Join(exceeded, "\n")
+		}
+	}
+
+	if err := writeOutput(os.Stdout, *output, result, func(w *tabwriter.Writer) {
+		if result.Valid {
+			fmt.Fprintf(w, "%s: valid\n", result.Path)
+		} else {
+			fmt.Fprintf(w, "%s: invalid\n%s\n", result.Path, result.Error)
+		}
+		if len(result.Impact) > 0 {
+			fmt.Fprintln(w, "RULE\tKIND\tENABLED\tMATCHED\tERROR")
+			for _, ruleImpact := range result.Impact {
+				fmt.Fprintf(w, "%s\t%s\t%t\t%d\t%s\n", ruleImpact.Rule, ruleImpact.Kind, ruleImpact.Enabled, ruleImpact.Matched, ruleImpact.Error)
+			}
+		}
+	}); err != nil {
+		fmt.Fprintf(os.Stderr, "validate: %v\n", err)
+		return 1
+	}
+
+	if !result.Valid {
+		return 1
+	}
+	return 0
+}
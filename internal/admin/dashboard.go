@@ -0,0 +1,89 @@
+package admin
+
+import (
+	_ "embed"
+	"html/template"
+	"net/http"
+	"slices"
+	"time"
+
+	cleanupconfig "github.com/infrautils/kubeclean/internal/cleanup_config"
+	"github.com/infrautils/kubeclean/internal/controller"
+	"gopkg.in/yaml.v2"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+// DashboardPath is the path of the read-only status page.
+const DashboardPath = "/dashboard"
+
+// dashboardRows bounds the runs and deletions listed on the dashboard.
+const dashboardRows = 20
+
+//go:embed dashboard.html
+var dashboardHTML string
+
+var dashboardTemplate = template.Must(template.New("dashboard").Funcs(template.FuncMap{
+	"duration": func(start, end time.Time) time.Duration { return end.Sub(start).Round(time.Millisecond) },
+}).Parse(dashboardHTML))
+
+// dashboardData is rendered by dashboardTemplate.
+type dashboardData struct {
+	Now       time.Time
+	DryRun    bool
+	Rules     []controller.RuleStatus
+	Runs      []controller.RunSummary
+	Deletions []controller.Deletion
+	Config    string
+
+	Retries     []controller.RetryEntry
+	DeadLetters []controller.RetryEntry
+}
+
+// handleDashboard renders a read-only HTML page with the active config, recent runs, per-rule
+// statuses, recent deletions and failed deletions awaiting a retry or given up on.
+func (s *Server) handleDashboard(w http.ResponseWriter, r *http.Request) {
+	config, err := yaml.Marshal(redactConfig(s.controller.CleanupConfig))
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	data := dashboardData{
+		Now:       time.Now(),
+		DryRun:    s.controller.CleanupConfig.DryRun,
+		Rules:     s.controller.RuleStatuses(),
+		Runs:      s.controller.History(dashboardRows),
+		Deletions: s.controller.RecentDeletions(dashboardRows),
+		Config:    string(config),
+
+		Retries:     s.controller.PendingRetries(),
+		DeadLetters: s.controller.DeadLetters(dashboardRows),
+	}
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	if err := dashboardTemplate.Execute(w, data); err != nil {
+		log.FromContext(r.Context()).Error(err, "Failed to render dashboard")
+	}
+}
+
+// redactConfig returns a copy of cfg safe to display: notification sink and log forwarding URLs
+// often embed credentials, e.g. Slack webhook tokens, and SMTP passwords are credentials.
+func redactConfig(cfg *cleanupconfig.CleanupConfig) cleanupconfig.CleanupConfig {
+	redacted := *cfg
+	if redacted.LogForwarding.URL != "" {
+		redacted.LogForwarding.URL = "<redacted>"
+	}
+	redacted.Notifications.Sinks = slices.Clone(cfg.Notifications.Sinks)
+	for i := range redacted.Notifications.Sinks {
+		sink := &redacted.Notifications.Sinks[i]
+		if sink.URL != "" {
+			sink.URL = "<redacted>"
+		}
+		if sink.Email != nil && sink.Email.Password != "" {
+			email := *sink.Email
+			email.Password = "<redacted>"
+			sink.Email = &email
+		}
+	}
+	return redacted
+}
@@ -0,0 +1,273 @@
+package admin
+
+import (
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"time"
+
+	adminv1 "github.com/infrautils/kubeclean/api/admin/v1"
+	"github.com/infrautils/kubeclean/internal/auth"
+	cleanupconfig "github.com/infrautils/kubeclean/internal/cleanup_config"
+	"github.com/infrautils/kubeclean/internal/controller"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+// auditLog records every change made through the API.
+var auditLog = log.Log.WithName("audit")
+
+// maxConfigBytes bounds the size of configuration documents accepted by the API.
+const maxConfigBytes = 1 << 20
+
+// Server serves kubeclean's administrative HTTP API.
+type Server struct {
+	addr       string
+	controller *controller.PodCleanController
+
+	TLSConfig  *tls.Config      // Serves HTTPS, and mTLS when it requires client certificates, if set.
+	Authorizer *auth.Authorizer // Authenticates and authorizes every request, if set.
+}
+
+// NewServer returns an admin API server bound to addr that operates on the given controller.
+func NewServer(addr string, podCleanController *controller.PodCleanController) *Server {
+	return &Server{addr: addr, controller: podCleanController}
+}
+
+// Start serves the API until ctx is cancelled. It implements manager.Runnable.
+func (s *Server) Start(ctx context.Context) error {
+	srv := &http.Server{
+		Addr:              s.addr,
+		Handler:           s.Handler(),
+		ReadHeaderTimeout: 10 * time.Second,
+		TLSConfig:         s.TLSConfig,
+	}
+
+	errCh := make(chan error, 1)
+	go func() {
+		log.FromContext(ctx).Info("Starting admin API server", "address", s.addr, "tls", s.TLSConfig != nil)
+		if s.TLSConfig != nil {
+			errCh <- srv.ListenAndServeTLS("", "")
+			return
+		}
+		errCh <- srv.ListenAndServe()
+	}()
+
+	select {
+	case <-ctx.Done():
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		return srv.Shutdown(shutdownCtx)
+	case err := <-errCh:
+		if errors.Is(err, http.ErrServerClosed) {
+			return nil
+		}
+		return err
+	}
+}
+
+// NeedLeaderElection reports that the API is served by every replica, not just the leader.
+func (s *Server) NeedLeaderElection() bool {
+	return false
+}
+
+// Handler returns the HTTP handler serving the API routes behind the server's authorizer.
+func (s *Server) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET "+adminv1.OpenAPIPath, s.handleOpenAPI)
+	mux.HandleFunc("GET "+DashboardPath, s.handleDashboard)
+	mux.HandleFunc("POST /simulate", s.handleSimulate)
+	mux.HandleFunc("GET /rules/status", s.handleRuleStatus)
+	mux.HandleFunc("GET /retries", s.handleRetryQueue)
+	mux.HandleFunc("GET /config/pending", s.handlePendingConfig)
+	mux.HandleFunc("POST /config/pending/approve", s.handleApprovePendingConfig)
+	mux.HandleFunc("DELETE /config/pending", s.handleRejectPendingConfig)
+	mux.HandleFunc("PATCH /rules/{name}/enabled", s.handleSetRuleEnabled)
+	mux.HandleFunc("DELETE /rules/{name}/enabled", s.handleClearRuleEnabled)
+	return s.Authorizer.Middleware(mux)
+}
+
+// handleSimulate evaluates the posted candidate config against the live cluster and
+// returns the difference to the active config.
+func (s *Server) handleSimulate(w http.ResponseWriter, r *http.Request) {
+	body, err := io.ReadAll(io.LimitReader(r.Body, maxConfigBytes))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	candidate, err := cleanupconfig.LoadConfig(body)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, toSimulationResult(s.controller.Simulate(r.Context(), candidate)))
+}
+
+// handleOpenAPI returns the OpenAPI document describing the API.
+func (s *Server) handleOpenAPI(w http.ResponseWriter, _ *http.Request) {
+	document, err := adminv1.OpenAPI()
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_, _ = w.Write(document)
+}
+
+// handleRuleStatus returns the latest status of every rule evaluated since startup.
+func (s *Server) handleRuleStatus(w http.ResponseWriter, _ *http.Request) {
+	statuses := []adminv1.RuleStatus{}
+	for _, status := range s.controller.RuleStatuses() {
+		statuses = append(statuses, adminv1.RuleStatus(status))
+	}
+	writeJSON(w, http.StatusOK, statuses)
+}
+
+// handleRetryQueue returns the pods awaiting a deletion retry and those given up on.
+func (s *Server) handleRetryQueue(w http.ResponseWriter, _ *http.Request) {
+	writeJSON(w, http.StatusOK, adminv1.RetryQueue{
+		Pending:     toRetryEntries(s.controller.PendingRetries()),
+		DeadLetters: toRetryEntries(s.controller.DeadLetters(0)),
+	})
+}
+
+// handlePendingConfig returns the reloaded config held back by reloadSafety.
+func (s *Server) handlePendingConfig(w http.ResponseWriter, _ *http.Request) {
+	pending, ok := s.controller.PendingConfig()
+	if !ok {
+		writeError(w, http.StatusNotFound, controller.ErrNoPendingConfig)
+		return
+	}
+	writeJSON(w, http.StatusOK, toPendingConfig(pending))
+}
+
+// handleApprovePendingConfig replaces the active config with the held config.
+func (s *Server) handleApprovePendingConfig(w http.ResponseWriter, r *http.Request) {
+	pending, err := s.controller.ApprovePendingConfig()
+	if err != nil {
+		writeError(w, http.StatusNotFound, err)
+		return
+	}
+
+	auditLog.Info("Pending config approved", "heldSince", pending.Since,
+		"user", auth.UserFrom(r.Context()), "remoteAddr", r.RemoteAddr, "userAgent", r.UserAgent())
+	writeJSON(w, http.StatusOK, toPendingConfig(pending))
+}
+
+// handleRejectPendingConfig discards the held config and keeps the active config.
+func (s *Server) handleRejectPendingConfig(w http.ResponseWriter, r *http.Request) {
+	pending, err := s.controller.RejectPendingConfig()
+	if err != nil {
+		writeError(w, http.StatusNotFound, err)
+		return
+	}
+
+	auditLog.Info("Pending config rejected", "heldSince", pending.Since,
+		"user", auth.UserFrom(r.Context()), "remoteAddr", r.RemoteAddr, "userAgent", r.UserAgent())
+	writeJSON(w, http.StatusOK, toPendingConfig(pending))
+}
+
+// handleSetRuleEnabled enables or disables a rule at runtime without changing the config. The
+// kind query parameter picks between rules of several kinds sharing the name.
+func (s *Server) handleSetRuleEnabled(w http.ResponseWriter, r *http.Request) {
+	name, kind := r.PathValue("name"), r.URL.Query().Get("kind")
+
+	var req adminv1.RuleEnabled
+	if err := json.NewDecoder(io.LimitReader(r.Body, maxConfigBytes)).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	if req.Enabled == nil {
+		writeError(w, http.StatusBadRequest, errors.New("'enabled' must be set"))
+		return
+	}
+
+	kind, previous, err := s.controller.SetRuleEnabled(kind, name, *req.Enabled)
+	if err != nil {
+		writeError(w, ruleErrorStatus(err), err)
+		return
+	}
+
+	auditLog.Info("Rule enablement overridden", "rule", name, "kind", kind, "enabled", *req.Enabled, "previous", previous,
+		"user", auth.UserFrom(r.Context()), "remoteAddr", r.RemoteAddr, "userAgent", r.UserAgent())
+	writeJSON(w, http.StatusOK, adminv1.RuleEnabled{Rule: name, Kind: kind, Enabled: req.Enabled})
+}
+
+// handleClearRuleEnabled removes a runtime override so the rule follows the config again.
+func (s *Server) handleClearRuleEnabled(w http.ResponseWriter, r *http.Request) {
+	name, kind := r.PathValue("name"), r.URL.Query().Get("kind")
+
+	kind, enabled, err := s.controller.ClearRuleOverride(kind, name)
+	if err != nil {
+		writeError(w, ruleErrorStatus(err), err)
+		return
+	}
+
+	auditLog.Info("Rule enablement override cleared", "rule", name, "kind", kind, "enabled", enabled,
+		"user", auth.UserFrom(r.Context()), "remoteAddr", r.RemoteAddr, "userAgent", r.UserAgent())
+	writeJSON(w, http.StatusOK, adminv1.RuleEnabled{Rule: name, Kind: kind, Enabled: &enabled})
+}
+
+func ruleErrorStatus(err error) int {
+	if errors.Is(err, controller.ErrRuleNotFound) {
+		return http.StatusNotFound
+	}
+	if errors.Is(err, controller.ErrAmbiguousRule) {
+		return http.StatusConflict
+	}
+	return http.StatusUnprocessableEntity
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+func writeError(w http.ResponseWriter, status int, err error) {
+	writeJSON(w, status, adminv1.Error{Error: err.Error()})
+}
+
+func toSimulationResult(result controller.SimulationResult) adminv1.SimulationResult {
+	return adminv1.SimulationResult{
+		Active:    result.Active,
+		Candidate: result.Candidate,
+		Added:     toPodRefs(result.Added),
+		Removed:   toPodRefs(result.Removed),
+	}
+}
+
+func toPendingConfig(pending controller.PendingConfig) adminv1.PendingConfig {
+	return adminv1.PendingConfig{Since: pending.Since, Simulation: toSimulationResult(pending.Simulation)}
+}
+
+func toPodRefs(refs []controller.PodRef) []adminv1.PodRef {
+	out := make([]adminv1.PodRef, 0, len(refs))
+	for _, ref := range refs {
+		out = append(out, adminv1.PodRef{Rule: ref.Rule, Namespace: ref.Namespace, Name: ref.Name, Owner: ref.Owner,
+			ReasonCode: string(ref.ReasonCode), Warnings: ref.Warnings})
+	}
+	return out
+}
+
+func toRetryEntries(entries []controller.RetryEntry) []adminv1.RetryEntry {
+	out := make([]adminv1.RetryEntry, 0, len(entries))
+	for _, entry := range entries {
+		out = append(out, adminv1.RetryEntry{
+			Rule:        entry.Rule,
+			Namespace:   entry.Namespace,
+			Name:        entry.Name,
+			Attempts:    entry.Attempts,
+			Reason:      string(entry.Reason),
+			ReasonCode:  string(entry.Reason.Code()),
+			Error:       entry.Error,
+			FirstFailed: entry.FirstFailed,
+			LastFailed:  entry.LastFailed,
+		})
+	}
+	return out
+}
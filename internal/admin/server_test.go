@@ -0,0 +1,257 @@
+package admin
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	adminv1 "github.com/infrautils/kubeclean/api/admin/v1"
+	"github.com/infrautils/kubeclean/internal/auth"
+	cleanupconfig "github.com/infrautils/kubeclean/internal/cleanup_config"
+	"github.com/infrautils/kubeclean/internal/controller"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func newTestServer(t *testing.T) *Server {
+	t.Helper()
+
+	scheme := runtime.NewScheme()
+	require.NoError(t, corev1.AddToScheme(scheme))
+
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:              "failed-pod",
+			Namespace:         "default",
+			CreationTimestamp: metav1.NewTime(time.Now().Add(-2 * time.Hour)),
+		},
+		Status: corev1.PodStatus{Phase: corev1.PodFailed},
+	}
+
+	client := fake.NewClientBuilder().WithScheme(scheme).WithRuntimeObjects(pod).Build()
+	active := &cleanupconfig.CleanupConfig{BatchSize: 10}
+
+	return NewServer(":0", controller.NewPodCleanController(client, scheme, active))
+}
+
+func TestHandleSimulate(t *testing.T) {
+	server := newTestServer(t)
+
+	candidate := `
+podCleanupConfig:
+  enabled: true
+  rules:
+    - name: failed
+      enabled: true
+      ttl: "1h"
+      phase: Failed
+`
+
+	rec := httptest.NewRecorder()
+	server.Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/simulate", strings.NewReader(candidate)))
+	require.Equal(t, http.StatusOK, rec.Code)
+
+	var result adminv1.SimulationResult
+	require.NoError(t, json.NewDecoder(rec.Body).Decode(&result))
+	require.Equal(t, 1, result.Candidate["failed"])
+	require.Len(t, result.Added, 1)
+	require.Equal(t, "failed-pod", result.Added[0].Name)
+}
+
+func TestHandleSimulate_InvalidConfig(t *testing.T) {
+	server := newTestServer(t)
+
+	rec := httptest.NewRecorder()
+	server.Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/simulate", strings.NewReader("batchSize: -1")))
+	require.Equal(t, http.StatusBadRequest, rec.Code)
+	require.Contains(t, rec.Body.String(), "batch size cannot be negative")
+
+	rec = httptest.NewRecorder()
+	server.Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/simulate", nil))
+	require.Equal(t, http.StatusMethodNotAllowed, rec.Code)
+}
+
+func TestHandleRuleEnabled(t *testing.T) {
+	server := newTestServer(t)
+	server.controller.CleanupConfig.PodCleanupConfig = cleanupconfig.PodCleanupConfig{
+		Enabled: true,
+		Rules: []cleanupconfig.PodCleanRule{
+			{Name: "failed", Enabled: true, Phase: string(corev1.PodFailed), TTL: cleanupconfig.Duration{Duration: time.Hour}},
+			{Name: "broken", Enabled: false}, // Never validated because it is disabled.
+		},
+	}
+
+	patch := func(path, body string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		server.Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodPatch, path, strings.NewReader(body)))
+		return rec
+	}
+
+	rec := patch("/rules/failed/enabled", `{"enabled": false}`)
+	require.Equal(t, http.StatusOK, rec.Code)
+	require.JSONEq(t, `{"rule": "failed", "kind": "pod", "enabled": false}`, rec.Body.String())
+
+	summary := server.controller.RunCleanUp(context.Background())
+	require.Zero(t, summary.Matched, "disabled rule should not match")
+
+	rec = httptest.NewRecorder()
+	server.Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodDelete, "/rules/failed/enabled", nil))
+	require.Equal(t, http.StatusOK, rec.Code)
+	require.JSONEq(t, `{"rule": "failed", "kind": "pod", "enabled": true}`, rec.Body.String())
+
+	summary = server.controller.RunCleanUp(context.Background())
+	require.Equal(t, 1, summary.Matched)
+
+	require.Equal(t, http.StatusNotFound, patch("/rules/unknown/enabled", `{"enabled": true}`).Code)
+	require.Equal(t, http.StatusBadRequest, patch("/rules/failed/enabled", `{}`).Code)
+	require.Equal(t, http.StatusUnprocessableEntity, patch("/rules/broken/enabled", `{"enabled": true}`).Code)
+
+	// Rule names are only unique within their kind.
+	server.controller.CleanupConfig.OrphanCleanupConfig.Rules = []cleanupconfig.OrphanCleanRule{{Name: "failed", Kind: cleanupconfig.OrphanKindPodDisruptionBudget}}
+	require.Equal(t, http.StatusConflict, patch("/rules/failed/enabled", `{"enabled": false}`).Code)
+	rec = patch("/rules/failed/enabled?kind=orphan", `{"enabled": false}`)
+	require.Equal(t, http.StatusOK, rec.Code)
+	require.JSONEq(t, `{"rule": "failed", "kind": "orphan", "enabled": false}`, rec.Body.String())
+	require.Equal(t, http.StatusNotFound, patch("/rules/failed/enabled?kind=generic", `{"enabled": false}`).Code)
+}
+
+func TestHandlePendingConfig(t *testing.T) {
+	server := newTestServer(t)
+	server.controller.CleanupConfig.ReloadSafety = cleanupconfig.ReloadSafetyConfig{Enabled: true, MinMatches: 1}
+	for i := range 4 {
+		require.NoError(t, server.controller.Client.Create(context.Background(), &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: fmt.Sprintf("failed-%d", i), Namespace: "default", CreationTimestamp: metav1.NewTime(time.Now().Add(-2 * time.Hour))},
+			Status:     corev1.PodStatus{Phase: corev1.PodFailed},
+		}))
+	}
+
+	serve := func(method, path string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		server.Handler().ServeHTTP(rec, httptest.NewRequest(method, path, nil))
+		return rec
+	}
+	require.Equal(t, http.StatusNotFound, serve(http.MethodGet, "/config/pending").Code)
+
+	candidate := &cleanupconfig.CleanupConfig{BatchSize: 20, PodCleanupConfig: cleanupconfig.PodCleanupConfig{
+		Enabled: true,
+		Rules:   []cleanupconfig.PodCleanRule{{Name: "failed", Enabled: true, Phase: string(corev1.PodFailed), TTL: cleanupconfig.Duration{Duration: time.Hour}}},
+	}}
+	require.False(t, server.controller.AllowReload(context.Background(), candidate))
+
+	rec := serve(http.MethodGet, "/config/pending")
+	require.Equal(t, http.StatusOK, rec.Code)
+	var pending adminv1.PendingConfig
+	require.NoError(t, json.NewDecoder(rec.Body).Decode(&pending))
+	require.Equal(t, 5, pending.Simulation.Candidate["failed"])
+
+	require.Equal(t, http.StatusOK, serve(http.MethodPost, "/config/pending/approve").Code)
+	require.Equal(t, 20, server.controller.CleanupConfig.BatchSize)
+	require.Equal(t, http.StatusNotFound, serve(http.MethodPost, "/config/pending/approve").Code)
+	require.Equal(t, http.StatusNotFound, serve(http.MethodDelete, "/config/pending").Code)
+}
+
+func TestHandleRuleStatus(t *testing.T) {
+	server := newTestServer(t)
+	server.controller.CleanupConfig.PodCleanupConfig = cleanupconfig.PodCleanupConfig{
+		Enabled: true,
+		Rules: []cleanupconfig.PodCleanRule{
+			{Name: "failed", Enabled: true, Phase: string(corev1.PodFailed), TTL: cleanupconfig.Duration{Duration: time.Hour}},
+		},
+	}
+
+	server.controller.RunCleanUp(context.Background())
+
+	rec := httptest.NewRecorder()
+	server.Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/rules/status", nil))
+	require.Equal(t, http.StatusOK, rec.Code)
+
+	var statuses []adminv1.RuleStatus
+	require.NoError(t, json.NewDecoder(rec.Body).Decode(&statuses))
+	require.Len(t, statuses, 1)
+	require.Equal(t, "pod", statuses[0].Kind)
+	require.Equal(t, "failed", statuses[0].Name)
+	require.Equal(t, 1, statuses[0].LastMatched)
+	require.Equal(t, 1, statuses[0].LastDeleted)
+	require.Empty(t, statuses[0].LastError)
+	require.False(t, statuses[0].LastRunTime.IsZero())
+}
+
+func TestHandler_RequiresToken(t *testing.T) {
+	server := newTestServer(t)
+	server.Authorizer = &auth.Authorizer{Tokens: []string{"secret"}}
+
+	rec := httptest.NewRecorder()
+	server.Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/rules/status", nil))
+	require.Equal(t, http.StatusUnauthorized, rec.Code)
+
+	req := httptest.NewRequest(http.MethodGet, "/rules/status", nil)
+	req.Header.Set("Authorization", "Bearer secret")
+	rec = httptest.NewRecorder()
+	server.Handler().ServeHTTP(rec, req)
+	require.Equal(t, http.StatusOK, rec.Code)
+}
+
+func TestHandleOpenAPI(t *testing.T) {
+	server := newTestServer(t)
+
+	rec := httptest.NewRecorder()
+	server.Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, adminv1.OpenAPIPath, nil))
+	require.Equal(t, http.StatusOK, rec.Code)
+	require.Equal(t, "application/json", rec.Header().Get("Content-Type"))
+
+	var document struct {
+		Paths map[string]map[string]any `json:"paths"`
+	}
+	require.NoError(t, json.NewDecoder(rec.Body).Decode(&document))
+
+	// Every documented operation must be routed by the handler.
+	for _, op := range adminv1.Operations {
+		require.Contains(t, document.Paths[op.Path], strings.ToLower(op.Method))
+
+		path := strings.ReplaceAll(op.Path, "{name}", "unknown")
+		rec := httptest.NewRecorder()
+		server.Handler().ServeHTTP(rec, httptest.NewRequest(op.Method, path, strings.NewReader("{}")))
+		require.NotEqual(t, http.StatusMethodNotAllowed, rec.Code, "%s %s", op.Method, op.Path)
+		require.Equal(t, "application/json", rec.Header().Get("Content-Type"), "%s %s is not routed", op.Method, op.Path)
+	}
+}
+
+func TestHandleDashboard(t *testing.T) {
+	server := newTestServer(t)
+	server.controller.CleanupConfig.PodCleanupConfig = cleanupconfig.PodCleanupConfig{
+		Enabled: true,
+		Rules: []cleanupconfig.PodCleanRule{
+			{Name: "failed", Enabled: true, Phase: string(corev1.PodFailed), TTL: cleanupconfig.Duration{Duration: time.Hour}},
+		},
+	}
+	server.controller.CleanupConfig.Notifications.Sinks = []cleanupconfig.NotificationSink{
+		{Name: "chat", Type: cleanupconfig.SinkTypeSlack, URL: "https://hooks.slack.com/services/SECRET"},
+		{Name: "mail", Type: cleanupconfig.SinkTypeEmail, Email: &cleanupconfig.EmailSinkConfig{
+			Host: "127.0.0.1", Port: 1, From: "kubeclean@example.com", To: []string{"ops@example.com"},
+			Username: "kubeclean", Password: "SMTP-SECRET",
+		}},
+	}
+
+	server.controller.RunCleanUp(context.Background())
+
+	rec := httptest.NewRecorder()
+	server.Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, DashboardPath, nil))
+	require.Equal(t, http.StatusOK, rec.Code)
+	require.Contains(t, rec.Header().Get("Content-Type"), "text/html")
+
+	page := rec.Body.String()
+	require.Contains(t, page, "<td>failed</td>")     // Rule status.
+	require.Contains(t, page, "<td>failed-pod</td>") // Recent deletion.
+	require.Contains(t, page, "ttl: 1h0m0s")         // Active config.
+	require.Contains(t, page, "&lt;redacted&gt;")
+	require.NotContains(t, page, "SECRET")
+	require.Equal(t, "https://hooks.slack.com/services/SECRET", server.controller.CleanupConfig.Notifications.Sinks[0].URL)
+}
@@ -0,0 +1,173 @@
+// Package auth authenticates and authorizes callers of kubeclean's admin and gRPC APIs, with
+// static bearer tokens or by delegating to the Kubernetes TokenReview and SubjectAccessReview APIs.
+package auth
+
+import (
+	"context"
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+
+	authenticationv1 "k8s.io/api/authentication/v1"
+	authorizationv1 "k8s.io/api/authorization/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+var (
+	// ErrUnauthenticated is returned when a request carries no valid credentials.
+	ErrUnauthenticated = errors.New("unauthenticated")
+
+	// ErrForbidden is returned when an authenticated user may not make the request.
+	ErrForbidden = errors.New("forbidden")
+)
+
+// TokenUser is the user name of callers presenting a static token.
+const TokenUser = "kubeclean:static-token"
+
+// Attributes describe a request to authorize. Delegated authorization checks them as a
+// non-resource URL, so RBAC rules grant access with nonResourceURLs and verbs.
+type Attributes struct {
+	Path string // e.g. "/rules/status" or "/kubeclean.v1.Kubeclean/TriggerRun".
+	Verb string // Lowercase Kubernetes verb, e.g. "get" or "create".
+}
+
+// Authorizer checks bearer tokens. Static tokens grant full access; any other token is
+// authenticated with a TokenReview and authorized with a SubjectAccessReview when Client is set.
+// The zero Authorizer allows every request.
+type Authorizer struct {
+	Tokens []string      // Static bearer tokens granting full access.
+	Client client.Client // Client for delegated authentication and authorization, if enabled.
+}
+
+// Enabled reports whether requests must be authenticated.
+func (a *Authorizer) Enabled() bool {
+	return a != nil && (len(a.Tokens) > 0 || a.Client != nil)
+}
+
+// Authorize returns the user presenting token if they may make the request described by attrs,
+// or an error wrapping ErrUnauthenticated or ErrForbidden.
+func (a *Authorizer) Authorize(ctx context.Context, token string, attrs Attributes) (string, error) {
+	if !a.Enabled() {
+		return "", nil
+	}
+	if token == "" {
+		return "", fmt.Errorf("%w: bearer token required", ErrUnauthenticated)
+	}
+
+	for _, static := range a.Tokens {
+		if subtle.ConstantTimeCompare([]byte(token), []byte(static)) == 1 {
+			return TokenUser, nil
+		}
+	}
+
+	if a.Client == nil {
+		return "", fmt.Errorf("%w: invalid token", ErrUnauthenticated)
+	}
+
+	review := &authenticationv1.TokenReview{Spec: authenticationv1.TokenReviewSpec{Token: token}}
+	if err := a.Client.Create(ctx, review); err != nil {
+		return "", fmt.Errorf("token review: %w", err)
+	}
+	if !review.Status.Authenticated {
+		return "", fmt.Errorf("%w: invalid token", ErrUnauthenticated)
+	}
+
+	user := review.Status.User
+	extra := make(map[string]authorizationv1.ExtraValue, len(user.Extra))
+	for key, values := range user.Extra {
+		extra[key] = authorizationv1.ExtraValue(values)
+	}
+
+	access := &authorizationv1.SubjectAccessReview{Spec: authorizationv1.SubjectAccessReviewSpec{
+		User:                  user.Username,
+		UID:                   user.UID,
+		Groups:                user.Groups,
+		Extra:                 extra,
+		NonResourceAttributes: &authorizationv1.NonResourceAttributes{Path: attrs.Path, Verb: attrs.Verb},
+	}}
+	if err := a.Client.Create(ctx, access); err != nil {
+		return "", fmt.Errorf("subject access review: %w", err)
+	}
+	if !access.Status.Allowed {
+		return user.Username, fmt.Errorf("%w: user %q may not %s %s", ErrForbidden, user.Username, attrs.Verb, attrs.Path)
+	}
+
+	return user.Username, nil
+}
+
+type userKey struct{}
+
+// WithUser returns a context carrying the authenticated user name.
+func WithUser(ctx context.Context, user string) context.Context {
+	return context.WithValue(ctx, userKey{}, user)
+}
+
+// UserFrom returns the authenticated user of the request, or "" when authentication is disabled.
+func UserFrom(ctx context.Context) string {
+	user, _ := ctx.Value(userKey{}).(string)
+	return user
+}
+
+// BearerToken extracts the token of an "Authorization: Bearer <token>" header value.
+func BearerToken(header string) string {
+	token, ok := strings.CutPrefix(header, "Bearer ")
+	if !ok {
+		return ""
+	}
+	return strings.TrimSpace(token)
+}
+
+// httpVerbs maps HTTP methods to the Kubernetes verbs checked by delegated authorization.
+var httpVerbs = map[string]string{
+	http.MethodGet:    "get",
+	http.MethodHead:   "get",
+	http.MethodPost:   "create",
+	http.MethodPut:    "update",
+	http.MethodPatch:  "patch",
+	http.MethodDelete: "delete",
+}
+
+// Middleware rejects requests to next that the authorizer does not allow, with 401 or 403.
+func (a *Authorizer) Middleware(next http.Handler) http.Handler {
+	if !a.Enabled() {
+		return next
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attrs := Attributes{Path: r.URL.Path, Verb: httpVerbs[r.Method]}
+		user, err := a.Authorize(r.Context(), BearerToken(r.Header.Get("Authorization")), attrs)
+		switch {
+		case errors.Is(err, ErrUnauthenticated):
+			w.Header().Set("WWW-Authenticate", "Bearer")
+			http.Error(w, err.Error(), http.StatusUnauthorized)
+		case errors.Is(err, ErrForbidden):
+			http.Error(w, err.Error(), http.StatusForbidden)
+		case err != nil:
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+		default:
+			next.ServeHTTP(w, r.WithContext(WithUser(r.Context(), user)))
+		}
+	})
+}
+
+// LoadTokens reads static tokens from path, one per line. Blank lines are ignored.
+func LoadTokens(path string) ([]string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("unable to read token file %q: %w", path, err)
+	}
+
+	var tokens []string
+	for _, line := range strings.Split(string(data), "\n") {
+		if token := strings.TrimSpace(line); token != "" {
+			tokens = append(tokens, token)
+		}
+	}
+	if len(tokens) == 0 {
+		return nil, fmt.Errorf("token file %q holds no token", path)
+	}
+	return tokens, nil
+}
@@ -0,0 +1,146 @@
+package auth
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	authenticationv1 "k8s.io/api/authentication/v1"
+	authorizationv1 "k8s.io/api/authorization/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
+)
+
+// newDelegatingClient returns a client whose TokenReviews authenticate "alice-token" as alice and
+// whose SubjectAccessReviews only allow alice to get /rules/status.
+func newDelegatingClient() client.Client {
+	return fake.NewClientBuilder().WithInterceptorFuncs(interceptor.Funcs{
+		Create: func(_ context.Context, _ client.WithWatch, obj client.Object, _ ...client.CreateOption) error {
+			switch review := obj.(type) {
+			case *authenticationv1.TokenReview:
+				if review.Spec.Token == "alice-token" {
+					review.Status.Authenticated = true
+					review.Status.User = authenticationv1.UserInfo{Username: "alice"}
+				}
+			case *authorizationv1.SubjectAccessReview:
+				attrs := review.Spec.NonResourceAttributes
+				review.Status.Allowed = review.Spec.User == "alice" && attrs.Path == "/rules/status" && attrs.Verb == "get"
+			}
+			return nil
+		},
+	}).Build()
+}
+
+func TestAuthorizer_Authorize(t *testing.T) {
+	tests := []struct {
+		name       string
+		authorizer *Authorizer
+		token      string
+		attrs      Attributes
+		wantUser   string
+		wantErr    error
+	}{
+		{name: "disabled", authorizer: &Authorizer{}},
+		{name: "nil authorizer", authorizer: nil},
+		{name: "static token", authorizer: &Authorizer{Tokens: []string{"secret"}}, token: "secret", wantUser: TokenUser},
+		{name: "missing token", authorizer: &Authorizer{Tokens: []string{"secret"}}, wantErr: ErrUnauthenticated},
+		{name: "wrong static token", authorizer: &Authorizer{Tokens: []string{"secret"}}, token: "guess", wantErr: ErrUnauthenticated},
+		{
+			name:       "delegated allowed",
+			authorizer: &Authorizer{Client: newDelegatingClient()},
+			token:      "alice-token",
+			attrs:      Attributes{Path: "/rules/status", Verb: "get"},
+			wantUser:   "alice",
+		},
+		{
+			name:       "delegated forbidden",
+			authorizer: &Authorizer{Client: newDelegatingClient()},
+			token:      "alice-token",
+			attrs:      Attributes{Path: "/rules/foo/enabled", Verb: "patch"},
+			wantUser:   "alice",
+			wantErr:    ErrForbidden,
+		},
+		{
+			name:       "delegated unknown token",
+			authorizer: &Authorizer{Client: newDelegatingClient()},
+			token:      "mallory-token",
+			attrs:      Attributes{Path: "/rules/status", Verb: "get"},
+			wantErr:    ErrUnauthenticated,
+		},
+		{
+			name:       "static token bypasses delegation",
+			authorizer: &Authorizer{Tokens: []string{"secret"}, Client: newDelegatingClient()},
+			token:      "secret",
+			attrs:      Attributes{Path: "/rules/foo/enabled", Verb: "patch"},
+			wantUser:   TokenUser,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			user, err := tt.authorizer.Authorize(context.Background(), tt.token, tt.attrs)
+			if tt.wantErr != nil {
+				require.ErrorIs(t, err, tt.wantErr)
+			} else {
+				require.NoError(t, err)
+			}
+			require.Equal(t, tt.wantUser, user)
+		})
+	}
+}
+
+func TestAuthorizer_Middleware(t *testing.T) {
+	authorizer := &Authorizer{Client: newDelegatingClient()}
+	handler := authorizer.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(UserFrom(r.Context())))
+	}))
+
+	tests := []struct {
+		name     string
+		method   string
+		path     string
+		header   string
+		wantCode int
+	}{
+		{name: "no credentials", method: http.MethodGet, path: "/rules/status", wantCode: http.StatusUnauthorized},
+		{name: "not a bearer token", method: http.MethodGet, path: "/rules/status", header: "Basic YWxpY2U6cHc=", wantCode: http.StatusUnauthorized},
+		{name: "allowed", method: http.MethodGet, path: "/rules/status", header: "Bearer alice-token", wantCode: http.StatusOK},
+		{name: "forbidden verb", method: http.MethodPatch, path: "/rules/status", header: "Bearer alice-token", wantCode: http.StatusForbidden},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, tt.path, nil)
+			if tt.header != "" {
+				req.Header.Set("Authorization", tt.header)
+			}
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, req)
+
+			require.Equal(t, tt.wantCode, rec.Code)
+			if tt.wantCode == http.StatusOK {
+				require.Equal(t, "alice", rec.Body.String())
+			}
+		})
+	}
+}
+
+func TestLoadTokens(t *testing.T) {
+	dir := t.TempDir()
+
+	path := filepath.Join(dir, "tokens")
+	require.NoError(t, os.WriteFile(path, []byte("first\n\n  second  \n"), 0o600))
+	tokens, err := LoadTokens(path)
+	require.NoError(t, err)
+	require.Equal(t, []string{"first", "second"}, tokens)
+
+	empty := filepath.Join(dir, "empty")
+	require.NoError(t, os.WriteFile(empty, []byte("\n"), 0o600))
+	_, err = LoadTokens(empty)
+	require.Error(t, err)
+}
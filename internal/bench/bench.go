@@ -0,0 +1,157 @@
+// Package bench measures how fast kubeclean matches and deletes pods, against a cluster or an
+// in-memory client, and tracks heap usage across repeated rounds to catch leaks.
+package bench
+
+import (
+	"context"
+	"fmt"
+	"runtime"
+	"time"
+
+	cleanupconfig "github.com/infrautils/kubeclean/internal/cleanup_config"
+	"github.com/infrautils/kubeclean/internal/controller"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// Label marks the synthetic pods; its value identifies the round that created them.
+const Label = "kubeclean.io/bench"
+
+// schedulingGate keeps synthetic pods unscheduled, so a real cluster never runs them.
+const schedulingGate = "kubeclean.io/bench"
+
+// ttl is the age synthetic pods must reach before the benchmark rule matches them.
+const ttl = time.Second
+
+// Options configure a benchmark round.
+type Options struct {
+	Pods      int    // Synthetic pods created per round.
+	Namespace string // Namespace the pods are created in; created if missing.
+	BatchSize int    // Pods deleted per batch.
+}
+
+// Result is the outcome of a single round.
+type Result struct {
+	Round           int           `json:"round"`
+	Pods            int           `json:"pods"`
+	Matched         int           `json:"matched"`
+	Deleted         int           `json:"deleted"`
+	Create          time.Duration `json:"create"`
+	Match           time.Duration `json:"match"`
+	Delete          time.Duration `json:"delete"`
+	MatchPerSecond  float64       `json:"matchPerSecond"`
+	DeletePerSecond float64       `json:"deletePerSecond"`
+	HeapInUseBytes  uint64        `json:"heapInUseBytes"` // Live heap after the round, measured after a forced GC.
+	Goroutines      int           `json:"goroutines"`
+}
+
+// Run creates opts.Pods synthetic pods, then times matching them with a rule selecting the round's
+// pods and deleting the matches, as a cleanup pass would.
+func Run(ctx context.Context, k8sClient client.Client, opts Options, round int) (Result, error) {
+	result := Result{Round: round, Pods: opts.Pods}
+	id := fmt.Sprintf("round-%d-%d", round, time.Now().UnixNano())
+
+	if err := ensureNamespace(ctx, k8sClient, opts.Namespace); err != nil {
+		return result, err
+	}
+
+	start := time.Now()
+	for i := range opts.Pods {
+		if err := k8sClient.Create(ctx, syntheticPod(opts.Namespace, id, i)); err != nil {
+			return result, fmt.Errorf("create pod %d: %w", i, err)
+		}
+	}
+	result.Create = time.Since(start)
+
+	// The rule's TTL is measured from the pods' creation.
+	select {
+	case <-ctx.Done():
+		return result, ctx.Err()
+	case <-time.After(ttl):
+	}
+
+	rule := cleanupconfig.PodCleanRule{
+		Name:       "bench",
+		Enabled:    true,
+		Phase:      string(corev1.PodPending),
+		Namespaces: []string{opts.Namespace},
+		Selector:   metav1.LabelSelector{MatchLabels: map[string]string{Label: id}},
+		TTL:        cleanupconfig.Duration{Duration: ttl},
+	}
+
+	start = time.Now()
+	pods, err := controller.NewPodMatcher(k8sClient).FindPodsToCleanup(ctx, rule)
+	if err != nil {
+		return result, fmt.Errorf("match pods: %w", err)
+	}
+	result.Match = time.Since(start)
+	result.Matched = len(pods)
+	result.MatchPerSecond = perSecond(result.Matched, result.Match)
+
+	start = time.Now()
+	results := controller.BatchDeletePods(ctx, k8sClient, pods, opts.BatchSize, 0, false)
+	result.Delete = time.Since(start)
+	result.Deleted = results.Deleted()
+	result.DeletePerSecond = perSecond(result.Deleted, result.Delete)
+
+	runtime.GC()
+	var stats runtime.MemStats
+	runtime.ReadMemStats(&stats)
+	result.HeapInUseBytes = stats.HeapInuse
+	result.Goroutines = runtime.NumGoroutine()
+
+	if err := results.Err(); err != nil {
+		return result, err
+	}
+	// Rates over a partial match would flatter the matcher.
+	if result.Matched != result.Pods {
+		return result, fmt.Errorf("matched %d of %d synthetic pods", result.Matched, result.Pods)
+	}
+	return result, nil
+}
+
+// HeapGrowth returns the relative growth of the live heap from the first to the last round, e.g.
+// 0.25 for 25%. The first round is the baseline, as it includes one-off allocations such as
+// client caches and pools.
+func HeapGrowth(results []Result) float64 {
+	if len(results) < 2 || results[0].HeapInUseBytes == 0 {
+		return 0
+	}
+	first, last := results[0].HeapInUseBytes, results[len(results)-1].HeapInUseBytes
+	return float64(last)/float64(first) - 1
+}
+
+func ensureNamespace(ctx context.Context, k8sClient client.Client, name string) error {
+	namespace := &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: name}}
+	if err := k8sClient.Create(ctx, namespace); err != nil && !apierrors.IsAlreadyExists(err) {
+		return fmt.Errorf("create namespace %s: %w", name, err)
+	}
+	return nil
+}
+
+// syntheticPod returns the i-th pod of a round. Pods are held back by a scheduling gate, so they
+// stay Pending and cost a cluster nothing but API server storage.
+func syntheticPod(namespace, id string, i int) *corev1.Pod {
+	return &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      fmt.Sprintf("bench-%s-%d", id, i),
+			Namespace: namespace,
+			Labels:    map[string]string{Label: id},
+		},
+		Spec: corev1.PodSpec{
+			SchedulingGates: []corev1.PodSchedulingGate{{Name: schedulingGate}},
+			Containers:      []corev1.Container{{Name: "bench", Image: "registry.k8s.io/pause:3.10"}},
+		},
+		// The API server sets it anyway; in-memory clients keep what they are given.
+		Status: corev1.PodStatus{Phase: corev1.PodPending},
+	}
+}
+
+func perSecond(n int, d time.Duration) float64 {
+	if d <= 0 {
+		return 0
+	}
+	return float64(n) / d.Seconds()
+}
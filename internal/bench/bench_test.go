@@ -0,0 +1,83 @@
+package bench
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
+)
+
+func TestRun(t *testing.T) {
+	scheme := runtime.NewScheme()
+	_ = corev1.AddToScheme(scheme)
+	k8sClient := fake.NewClientBuilder().WithScheme(scheme).Build()
+
+	opts := Options{Pods: 25, Namespace: "bench", BatchSize: 10}
+	for round := 1; round <= 2; round++ {
+		result, err := Run(context.Background(), k8sClient, opts, round)
+		if err != nil {
+			t.Fatalf("Round %d: unexpected error: %v", round, err)
+		}
+		if result.Matched != 25 || result.Deleted != 25 {
+			t.Errorf("Round %d: expected 25 matched and deleted pods, got %d and %d", round, result.Matched, result.Deleted)
+		}
+		if result.HeapInUseBytes == 0 {
+			t.Errorf("Round %d: expected the heap in use to be measured", round)
+		}
+	}
+
+	var pods corev1.PodList
+	if err := k8sClient.List(context.Background(), &pods, client.InNamespace("bench")); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(pods.Items) != 0 {
+		t.Errorf("Expected every synthetic pod to be deleted, got %d left", len(pods.Items))
+	}
+}
+
+func TestRun_FailsOnPartialMatch(t *testing.T) {
+	scheme := runtime.NewScheme()
+	_ = corev1.AddToScheme(scheme)
+	// A pod that left the Pending phase is not matched.
+	k8sClient := fake.NewClientBuilder().WithScheme(scheme).WithInterceptorFuncs(interceptor.Funcs{
+		Create: func(ctx context.Context, c client.WithWatch, obj client.Object, opts ...client.CreateOption) error {
+			if pod, ok := obj.(*corev1.Pod); ok && strings.HasSuffix(pod.Name, "-0") {
+				pod.Status.Phase = corev1.PodRunning
+			}
+			return c.Create(ctx, obj, opts...)
+		},
+	}).Build()
+
+	result, err := Run(context.Background(), k8sClient, Options{Pods: 5, Namespace: "bench", BatchSize: 10}, 1)
+	if err == nil {
+		t.Fatalf("Expected a partial match to fail the run, got %+v", result)
+	}
+	if result.Matched != 4 {
+		t.Errorf("Expected 4 matched pods, got %d", result.Matched)
+	}
+}
+
+func TestHeapGrowth(t *testing.T) {
+	tests := []struct {
+		name    string
+		results []Result
+		want    float64
+	}{
+		{"single round", []Result{{HeapInUseBytes: 100}}, 0},
+		{"growth", []Result{{HeapInUseBytes: 100}, {HeapInUseBytes: 110}, {HeapInUseBytes: 150}}, 0.5},
+		{"shrink", []Result{{HeapInUseBytes: 200}, {HeapInUseBytes: 100}}, -0.5},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := HeapGrowth(tt.results); got != tt.want {
+				t.Errorf("HeapGrowth() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,81 @@
+package cleanupconfig
+
+import (
+	"fmt"
+	"slices"
+)
+
+//
+// Rule Action Configuration
+//
+
+// Actions a rule can take on the pods it matches. deleteOwner is not a rule action of its own;
+// it stands for deleteOwnerWhenEmpty so constraints and tenant bounds can forbid it.
+const (
+	ActionEvict           = "evict"           // Evict matched pods through the Eviction API, honoring PodDisruptionBudgets.
+	ActionLabelQuarantine = "labelQuarantine" // Label matched pods, e.g. to cut them off from Services while they are inspected.
+	ActionScaleToZero     = "scaleToZero"     // Scale the Deployment, StatefulSet or ReplicaSet owning matched pods to zero.
+	ActionAnnotatePatch   = "annotatePatch"   // Merge annotations into matched pods.
+)
+
+// KnownActions lists every action constraints and tenant bounds may refer to.
+var KnownActions = []string{ActionDelete, ActionDeleteOwner, ActionEvict, ActionLabelQuarantine, ActionScaleToZero, ActionAnnotatePatch}
+
+// QuarantineLabel is set to "true" by labelQuarantine when the rule configures no labels.
+const QuarantineLabel = "kubeclean.io/quarantined"
+
+// ActionConfig selects what a rule does to the pods it matches. Rules delete them by default.
+type ActionConfig struct {
+	Type        string            `yaml:"type,omitempty"`        // One of delete, evict, labelQuarantine, scaleToZero or annotatePatch; defaults to delete.
+	Labels      map[string]string `yaml:"labels,omitempty"`      // labelQuarantine: labels set on matched pods; defaults to kubeclean.io/quarantined=true.
+	Annotations map[string]string `yaml:"annotations,omitempty"` // annotatePatch: annotations merged into matched pods.
+}
+
+// EffectiveType returns the configured action, or delete when none is set.
+func (c *ActionConfig) EffectiveType() string {
+	if c.Type == "" {
+		return ActionDelete
+	}
+	return c.Type
+}
+
+// QuarantineLabels returns the labels labelQuarantine sets.
+func (c *ActionConfig) QuarantineLabels() map[string]string {
+	if len(c.Labels) == 0 {
+		return map[string]string{QuarantineLabel: "true"}
+	}
+	return c.Labels
+}
+
+// Validate ensures ActionConfig is correctly configured.
+func (c *ActionConfig) Validate() error {
+	switch c.EffectiveType() {
+	case ActionDelete, ActionEvict, ActionLabelQuarantine, ActionScaleToZero, ActionAnnotatePatch:
+	default:
+		return fmt.Errorf("type must be one of %q, %q, %q, %q or %q, got %q",
+			ActionDelete, ActionEvict, ActionLabelQuarantine, ActionScaleToZero, ActionAnnotatePatch, c.Type)
+	}
+
+	if len(c.Labels) > 0 && c.EffectiveType() != ActionLabelQuarantine {
+		return fmt.Errorf("labels only apply to the %q action", ActionLabelQuarantine)
+	}
+
+	if c.EffectiveType() == ActionAnnotatePatch && len(c.Annotations) == 0 {
+		return fmt.Errorf("the %q action requires annotations", ActionAnnotatePatch)
+	}
+	if len(c.Annotations) > 0 && c.EffectiveType() != ActionAnnotatePatch {
+		return fmt.Errorf("annotations only apply to the %q action", ActionAnnotatePatch)
+	}
+
+	return nil
+}
+
+// validateActionNames rejects names in actions that are not in KnownActions.
+func validateActionNames(field string, actions []string) error {
+	for _, action := range actions {
+		if !slices.Contains(KnownActions, action) {
+			return fmt.Errorf("%s must only contain known actions %q, got %q", field, KnownActions, action)
+		}
+	}
+	return nil
+}
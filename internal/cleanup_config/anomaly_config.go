@@ -0,0 +1,70 @@
+package cleanupconfig
+
+import "fmt"
+
+//
+// Anomaly Detection Configuration
+//
+
+// Defaults applied to unset AnomalyDetectionConfig fields.
+const (
+	DefaultAnomalyWindow     = 20  // Runs forming a rule's baseline.
+	DefaultAnomalyThreshold  = 3.0 // Multiple of the baseline a rule's matches must exceed.
+	DefaultAnomalyMinMatches = 10  // Matches below which no anomaly is reported.
+)
+
+// AnomalyDetectionConfig reports garbage spikes: runs in which a pod rule matches far more pods
+// than its recent baseline, the mean plus one standard deviation of its matched counts.
+type AnomalyDetectionConfig struct {
+	Enabled    bool    `yaml:"enabled,omitempty"`    // If false, anomaly detection is disabled.
+	Window     int     `yaml:"window,omitempty"`     // Number of previous runs forming the baseline; defaults to 20.
+	Threshold  float64 `yaml:"threshold,omitempty"`  // Multiple of the baseline that counts as a spike; defaults to 3.
+	MinMatches int     `yaml:"minMatches,omitempty"` // Spikes smaller than this are ignored; defaults to 10.
+
+	NotificationSinks []string `yaml:"notificationSinks,omitempty"` // Sinks notified of spikes; defaults to the rule's sinks.
+}
+
+// WindowSize returns the number of runs forming the baseline.
+func (c *AnomalyDetectionConfig) WindowSize() int {
+	if c.Window <= 0 {
+		return DefaultAnomalyWindow
+	}
+	return c.Window
+}
+
+// ThresholdMultiple returns the multiple of the baseline that counts as a spike.
+func (c *AnomalyDetectionConfig) ThresholdMultiple() float64 {
+	if c.Threshold <= 0 {
+		return DefaultAnomalyThreshold
+	}
+	return c.Threshold
+}
+
+// MinimumMatches returns the smallest matched count that can be reported as a spike.
+func (c *AnomalyDetectionConfig) MinimumMatches() int {
+	if c.MinMatches <= 0 {
+		return DefaultAnomalyMinMatches
+	}
+	return c.MinMatches
+}
+
+// Validate ensures AnomalyDetectionConfig is correctly configured.
+func (c *AnomalyDetectionConfig) Validate() error {
+	if !c.Enabled {
+		return nil
+	}
+
+	if c.Window < 0 {
+		return fmt.Errorf("window cannot be negative")
+	}
+
+	if c.Threshold < 0 || (c.Threshold > 0 && c.Threshold <= 1) {
+		return fmt.Errorf("threshold must be greater than 1")
+	}
+
+	if c.MinMatches < 0 {
+		return fmt.Errorf("minMatches cannot be negative")
+	}
+
+	return nil
+}
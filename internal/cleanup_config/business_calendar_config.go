@@ -0,0 +1,106 @@
+package cleanupconfig
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+)
+
+//
+// Business Calendar Configuration
+//
+
+// HolidayLayout is the format of BusinessCalendarConfig holidays.
+const HolidayLayout = "2006-01-02"
+
+// DefaultWorkDays are the business days of the week when workDays is unset.
+var DefaultWorkDays = []string{"Monday", "Tuesday", "Wednesday", "Thursday", "Friday"}
+
+// BusinessCalendarConfig defines the business days ttlBusinessDays counts: the days of the work
+// week that are not holidays, in the calendar's time zone.
+type BusinessCalendarConfig struct {
+	WorkDays []string `yaml:"workDays,omitempty"` // Days of the week, e.g. Monday; defaults to Monday to Friday.
+	Holidays []string `yaml:"holidays,omitempty"` // Dates that are not business days, as YYYY-MM-DD.
+	TimeZone string   `yaml:"timeZone,omitempty"` // IANA time zone days start and end in, e.g. Europe/Berlin; defaults to UTC.
+}
+
+// Location returns the time zone of the calendar, or UTC when it is unset or unknown.
+func (c *BusinessCalendarConfig) Location() *time.Location {
+	if c.TimeZone == "" {
+		return time.UTC
+	}
+	location, err := time.LoadLocation(c.TimeZone)
+	if err != nil {
+		return time.UTC
+	}
+	return location
+}
+
+// parseWeekday returns the day of the week named name, case-insensitively.
+func parseWeekday(name string) (time.Weekday, bool) {
+	for day := time.Sunday; day <= time.Saturday; day++ {
+		if strings.EqualFold(day.String(), name) {
+			return day, true
+		}
+	}
+	return 0, false
+}
+
+// IsBusinessDay reports whether the day of t, in the calendar's time zone, is a business day.
+func (c *BusinessCalendarConfig) IsBusinessDay(t time.Time) bool {
+	t = t.In(c.Location())
+
+	workDays := c.WorkDays
+	if len(workDays) == 0 {
+		workDays = DefaultWorkDays
+	}
+	if !slices.ContainsFunc(workDays, func(name string) bool {
+		day, ok := parseWeekday(name)
+		return ok && day == t.Weekday()
+	}) {
+		return false
+	}
+
+	return !slices.Contains(c.Holidays, t.Format(HolidayLayout))
+}
+
+// BusinessDaysAfter returns when days business days have passed since t: the start of the day
+// after the days-th business day following the day of t. The day of t itself never counts, so a
+// pod created on a Friday with 1 business day expires on Tuesday under the default work week.
+func (c *BusinessCalendarConfig) BusinessDaysAfter(t time.Time, days int) time.Time {
+	location := c.Location()
+	t = t.In(location)
+	day := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, location)
+
+	for counted := 0; counted < days; {
+		day = day.AddDate(0, 0, 1)
+		if c.IsBusinessDay(day) {
+			counted++
+		}
+	}
+	return day.AddDate(0, 0, 1)
+}
+
+// Validate ensures BusinessCalendarConfig is correctly configured.
+func (c *BusinessCalendarConfig) Validate() error {
+	for _, name := range c.WorkDays {
+		if _, ok := parseWeekday(name); !ok {
+			return fmt.Errorf("workDays: %q is not a day of the week", name)
+		}
+	}
+
+	for _, holiday := range c.Holidays {
+		if _, err := time.Parse(HolidayLayout, holiday); err != nil {
+			return fmt.Errorf("holidays: %q is not a date of the form YYYY-MM-DD", holiday)
+		}
+	}
+
+	if c.TimeZone != "" {
+		if _, err := time.LoadLocation(c.TimeZone); err != nil {
+			return fmt.Errorf("timeZone: %w", err)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,101 @@
+package cleanupconfig
+
+import (
+	"fmt"
+	"slices"
+)
+
+//
+// cert-manager Cleanup Configuration
+//
+
+// cert-manager kinds a CertManagerCleanRule can target.
+const (
+	CertManagerKindCertificateRequest = "CertificateRequest" // cert-manager.io/v1
+	CertManagerKindOrder              = "Order"              // acme.cert-manager.io/v1
+	CertManagerKindChallenge          = "Challenge"          // acme.cert-manager.io/v1
+)
+
+// certManagerStates lists the states a rule may match per kind. Only terminal states are
+// allowed so that a rule can never interrupt an issuance that is still in progress.
+var certManagerStates = map[string][]string{
+	CertManagerKindCertificateRequest: {"issued", "expired", "failed", "denied"},
+	CertManagerKindOrder:              {"valid", "invalid", "expired", "errored"},
+	CertManagerKindChallenge:          {"valid", "invalid", "expired", "errored"},
+}
+
+// CertManagerCleanupConfig defines rules for cleaning up resources cert-manager leaves behind.
+type CertManagerCleanupConfig struct {
+	Enabled bool                   `yaml:"enabled,omitempty"` // If false, cert-manager cleanup is disabled.
+	Rules   []CertManagerCleanRule `yaml:"rules,omitempty"`   // List of rules for selecting and cleaning up cert-manager resources.
+}
+
+// Validate ensures CertManagerCleanupConfig is correctly configured.
+func (c *CertManagerCleanupConfig) Validate() error {
+	if !c.Enabled {
+		return nil
+	}
+
+	var errorMessages string
+
+	for idx, rule := range c.Rules {
+		if err := rule.Validate(); err != nil {
+			errorMessages += fmt.Sprintf("rule %d (%s): %v\n", idx+1, rule.Name, err)
+		}
+	}
+
+	if errorMessages == "" {
+		return nil
+	}
+
+	return fmt.Errorf("cert-manager cleanup config validation errors:\n%s", errorMessages)
+}
+
+// CertManagerCleanRule selects CertificateRequests, Orders or Challenges in terminal states.
+type CertManagerCleanRule struct {
+	Name       string   `yaml:"name"`                 // Unique name of the rule for identification.
+	Enabled    bool     `yaml:"enabled,omitempty"`    // If false, the rule is skipped during processing.
+	Lane       string   `yaml:"lane,omitempty"`       // fast (default) or slow; see LanesConfig.
+	Kind       string   `yaml:"kind"`                 // One of CertificateRequest, Order or Challenge.
+	States     []string `yaml:"states"`               // Terminal states to match; see certManagerStates.
+	TTL        Duration `yaml:"ttl"`                  // Minimum age before a matching resource is deleted.
+	Namespaces []string `yaml:"namespaces,omitempty"` // Specific namespaces where the rule applies.
+
+	NotificationSinks []string `yaml:"notificationSinks,omitempty"` // Sinks notified for this rule; defaults to all global sinks.
+}
+
+// Validate checks that the rule targets a known kind and only terminal states of it.
+func (r *CertManagerCleanRule) Validate() error {
+	if !r.Enabled {
+		return nil
+	}
+
+	if r.Name == "" {
+		return fmt.Errorf("rule name must be provided")
+	}
+
+	if err := validateLane(r.Lane); err != nil {
+		return err
+	}
+
+	if r.TTL.Duration <= 0 {
+		return fmt.Errorf("ttl must be greater than zero")
+	}
+
+	allowed, ok := certManagerStates[r.Kind]
+	if !ok {
+		return fmt.Errorf("kind must be one of %q, %q or %q", CertManagerKindCertificateRequest, CertManagerKindOrder, CertManagerKindChallenge)
+	}
+
+	if len(r.States) == 0 {
+		return fmt.Errorf("at least one state must be specified")
+	}
+
+	for _, state := range r.States {
+		if !slices.Contains(allowed, state) {
+			return fmt.Errorf("state %q is not valid for %s; expected one of %v", state, r.Kind, allowed)
+		}
+	}
+
+	return nil
+}
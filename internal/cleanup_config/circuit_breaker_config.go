@@ -0,0 +1,83 @@
+package cleanupconfig
+
+import (
+	"fmt"
+	"time"
+)
+
+//
+// Circuit Breaker Configuration
+//
+
+// Defaults applied to unset CircuitBreakerConfig fields.
+const (
+	DefaultCircuitBreakerWindow        = time.Minute
+	DefaultCircuitBreakerMinRequests   = 20
+	DefaultCircuitBreakerMaxErrorRatio = 0.25
+	DefaultCircuitBreakerCooldown      = 5 * time.Minute
+)
+
+// CircuitBreakerConfig protects a struggling control plane from cleanup load. kubeclean measures
+// the error rate and latency of its own API requests; when either exceeds its threshold, the
+// breaker opens, runs are deferred and a run in progress stops acting on further resources.
+type CircuitBreakerConfig struct {
+	Enabled       bool     `yaml:"enabled,omitempty"`       // If false, runs proceed whatever the API server's health.
+	Window        Duration `yaml:"window,omitempty"`        // Period of requests the thresholds are measured over; defaults to 1m.
+	MinRequests   int      `yaml:"minRequests,omitempty"`   // Requests in the window below which the breaker stays closed; defaults to 20.
+	MaxErrorRatio float64  `yaml:"maxErrorRatio,omitempty"` // Share of requests failing with 5xx, 429 or a network error that opens the breaker; defaults to 0.25.
+	MaxLatency    Duration `yaml:"maxLatency,omitempty"`    // 90th percentile latency that opens the breaker; 0 ignores latency.
+	Cooldown      Duration `yaml:"cooldown,omitempty"`      // How long the breaker stays open before runs are tried again; defaults to 5m.
+}
+
+// WindowDuration returns the period of requests the thresholds are measured over.
+func (c *CircuitBreakerConfig) WindowDuration() time.Duration {
+	if c.Window.Duration <= 0 {
+		return DefaultCircuitBreakerWindow
+	}
+	return c.Window.Duration
+}
+
+// MinimumRequests returns the number of requests in the window below which the breaker stays closed.
+func (c *CircuitBreakerConfig) MinimumRequests() int {
+	if c.MinRequests <= 0 {
+		return DefaultCircuitBreakerMinRequests
+	}
+	return c.MinRequests
+}
+
+// ErrorRatio returns the share of failed requests that opens the breaker.
+func (c *CircuitBreakerConfig) ErrorRatio() float64 {
+	if c.MaxErrorRatio <= 0 {
+		return DefaultCircuitBreakerMaxErrorRatio
+	}
+	return c.MaxErrorRatio
+}
+
+// CooldownDuration returns how long the breaker stays open.
+func (c *CircuitBreakerConfig) CooldownDuration() time.Duration {
+	if c.Cooldown.Duration <= 0 {
+		return DefaultCircuitBreakerCooldown
+	}
+	return c.Cooldown.Duration
+}
+
+// Validate ensures CircuitBreakerConfig is correctly configured.
+func (c *CircuitBreakerConfig) Validate() error {
+	if !c.Enabled {
+		return nil
+	}
+
+	if c.Window.Duration < 0 || c.MaxLatency.Duration < 0 || c.Cooldown.Duration < 0 {
+		return fmt.Errorf("window, maxLatency and cooldown cannot be negative")
+	}
+
+	if c.MinRequests < 0 {
+		return fmt.Errorf("minRequests cannot be negative")
+	}
+
+	if c.MaxErrorRatio < 0 || c.MaxErrorRatio > 1 {
+		return fmt.Errorf("maxErrorRatio must be between 0 and 1")
+	}
+
+	return nil
+}
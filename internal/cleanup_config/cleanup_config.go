@@ -2,6 +2,8 @@ package cleanupconfig
 
 import (
 	"fmt"
+	"slices"
+	"strings"
 	"time"
 
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -14,11 +16,57 @@ import (
 // CleanupConfig defines the root configuration for the cleanup process.
 // It includes global settings such as dry run mode, batch size, and pod cleanup-specific config.
 type CleanupConfig struct {
-	DryRun           bool             `yaml:"dryRun,omitempty"`           // If true, performs a dry-run without actual deletion.
-	BatchSize        int              `yaml:"batchSize,omitempty"`        // Number of resources processed per batch; defaults to 10.
-	PodCleanupConfig PodCleanupConfig `yaml:"podCleanupConfig,omitempty"` // Configuration specific to pod cleanup.
+	APIVersion               string           `yaml:"apiVersion,omitempty"`               // Config schema version; older versions are migrated on load.
+	ClusterName              string           `yaml:"clusterName,omitempty"`              // Cluster the config is loaded on; selects clusterOverlays.
+	DryRun                   bool             `yaml:"dryRun,omitempty"`                   // If true, performs a dry-run without actual deletion.
+	BatchSize                int              `yaml:"batchSize,omitempty"`                // Number of resources processed per batch; defaults to 10.
+	BatchDelay               Duration         `yaml:"batchDelay,omitempty"`               // Pause between delete batches; defaults to 100ms.
+	MaxDeletionsPerRun       int              `yaml:"maxDeletionsPerRun,omitempty"`       // Global deletion budget per run; 0 means unlimited.
+	PerNamespaceMaxDeletions int              `yaml:"perNamespaceMaxDeletions,omitempty"` // Deletion budget per namespace per run; 0 means unlimited.
+	WarmupRuns               int              `yaml:"warmupRuns,omitempty"`               // Runs after startup or a config change forced to dry-run.
+	OverlapPolicy            string           `yaml:"overlapPolicy,omitempty"`            // queue (default) or skip; see OverlapPolicy constants.
+	MissedRunPolicy          string           `yaml:"missedRunPolicy,omitempty"`          // skip (default) or runOnce; see MissedRunPolicy constants.
+	StartingDeadline         Duration         `yaml:"startingDeadline,omitempty"`         // With runOnce, how late a missed run may still start; 0 means no deadline.
+	PodCleanupConfig         PodCleanupConfig `yaml:"podCleanupConfig,omitempty"`         // Configuration specific to pod cleanup.
+
+	CertManagerCleanupConfig CertManagerCleanupConfig `yaml:"certManagerCleanupConfig,omitempty"` // Cleanup of cert-manager leftovers.
+	OrphanCleanupConfig      OrphanCleanupConfig      `yaml:"orphanCleanupConfig,omitempty"`      // Cleanup of resources whose targets no longer exist.
+	IdleWorkloadConfig       IdleWorkloadConfig       `yaml:"idleWorkloadConfig,omitempty"`       // Scales idle Deployments and StatefulSets to zero.
+	StaleCronJobConfig       StaleCronJobConfig       `yaml:"staleCronJobConfig,omitempty"`       // Suspends CronJobs that stopped succeeding.
+	GenericCleanupConfig     GenericCleanupConfig     `yaml:"genericCleanupConfig,omitempty"`     // Cleanup of arbitrary resources by apiVersion and kind.
+
+	Notifications    NotificationConfig     `yaml:"notifications,omitempty"`    // Sinks that receive cleanup events.
+	AnomalyDetection AnomalyDetectionConfig `yaml:"anomalyDetection,omitempty"` // Alerts on sudden spikes of matched pods.
+	ExitStatus       ExitStatusConfig       `yaml:"exitStatus,omitempty"`       // Outcomes that fail `kubeclean run`.
+	TenantRules      TenantRulesConfig      `yaml:"tenantRules,omitempty"`      // Namespaced CleanupRule resources created by tenants.
+	Constraints      ConstraintsConfig      `yaml:"constraints,omitempty"`      // Guardrails every pod rule must respect.
+	LogForwarding    LogForwardingConfig    `yaml:"logForwarding,omitempty"`    // Pushes logs of deleted pods to a log store.
+	Receipts         ReceiptsConfig         `yaml:"receipts,omitempty"`         // Receipts of deletions written into their namespaces.
+
+	NamespaceNotifications NamespaceNotificationsConfig `yaml:"namespaceNotifications,omitempty"` // Aggregated notifications to namespace owners.
+	RetryQueue             RetryQueueConfig             `yaml:"retryQueue,omitempty"`             // Retries of pods whose deletion failed transiently.
+	RunEvents              RunEventsConfig              `yaml:"runEvents,omitempty"`              // Events summarizing each run on kubeclean's own Deployment.
+	LastRun                LastRunConfig                `yaml:"lastRun,omitempty"`                // Persistence of the last run and rule statuses across restarts.
+	ReloadSafety           ReloadSafetyConfig           `yaml:"reloadSafety,omitempty"`           // Holds back reloads that would change behavior massively.
+	Ledger                 LedgerConfig                 `yaml:"ledger,omitempty"`                 // Hash-chained record of every run for audits.
+	RuleCooldown           RuleCooldownConfig           `yaml:"ruleCooldown,omitempty"`           // Skips pod rules for a few runs after most of their actions failed.
+	CircuitBreaker         CircuitBreakerConfig         `yaml:"circuitBreaker,omitempty"`         // Defers runs while the API server is failing or slow.
+	Lanes                  LanesConfig                  `yaml:"lanes,omitempty"`                  // How often rules in the slow lane run.
 }
 
+// Overlap policies decide what a scheduled run does while the previous run is still active.
+const (
+	OverlapPolicyQueue = "queue" // Start once the previous run finishes.
+	OverlapPolicySkip  = "skip"  // Skip the run and wait for the next interval.
+)
+
+// Missed run policies decide whether a scheduled run missed while the controller was down starts
+// at startup. Missed runs are only known with a persisted lastRun.
+const (
+	MissedRunPolicySkip    = "skip"    // Wait for the next interval.
+	MissedRunPolicyRunOnce = "runOnce" // Run once right after startup, however many runs were missed.
+)
+
 // SetDefaults sets default values for CleanupConfig.
 // Currently, it ensures BatchSize is set to a reasonable default if not provided.
 func (c *CleanupConfig) SetDefaults() {
@@ -27,17 +75,230 @@ func (c *CleanupConfig) SetDefaults() {
 	}
 }
 
+// EffectiveBatchSize returns the batch size to delete with: batchSize, or 10 when unset, capped by
+// the maxBatchSize constraint.
+func (c *CleanupConfig) EffectiveBatchSize() int {
+	size := c.BatchSize
+	if size <= 0 {
+		size = 10
+	}
+	if c.Constraints.MaxBatchSize > 0 && size > c.Constraints.MaxBatchSize {
+		size = c.Constraints.MaxBatchSize
+	}
+	return size
+}
+
+// DefaultBatchDelay is the pause between delete batches when batchDelay is unset.
+const DefaultBatchDelay = 100 * time.Millisecond
+
+// EffectiveBatchDelay returns the pause between delete batches: batchDelay, or 100ms when unset.
+func (c *CleanupConfig) EffectiveBatchDelay() time.Duration {
+	if c.BatchDelay.Duration <= 0 {
+		return DefaultBatchDelay
+	}
+	return c.BatchDelay.Duration
+}
+
 // Validate checks the correctness of CleanupConfig.
 // It validates BatchSize and recursively validates PodCleanupConfig.
 func (c *CleanupConfig) Validate() error {
+	if c.APIVersion != "" && c.APIVersion != CurrentAPIVersion {
+		return fmt.Errorf("unsupported apiVersion %q; expected %q", c.APIVersion, CurrentAPIVersion)
+	}
+
 	if c.BatchSize < 0 {
 		return fmt.Errorf("batch size cannot be negative")
 	}
 
+	if c.BatchDelay.Duration < 0 {
+		return fmt.Errorf("batchDelay cannot be negative")
+	}
+
+	if c.MaxDeletionsPerRun < 0 {
+		return fmt.Errorf("maxDeletionsPerRun cannot be negative")
+	}
+
+	if c.PerNamespaceMaxDeletions < 0 {
+		return fmt.Errorf("perNamespaceMaxDeletions cannot be negative")
+	}
+
+	if c.WarmupRuns < 0 {
+		return fmt.Errorf("warmupRuns cannot be negative")
+	}
+
+	switch c.OverlapPolicy {
+	case "", OverlapPolicyQueue, OverlapPolicySkip:
+	default:
+		return fmt.Errorf("overlapPolicy must be %q or %q", OverlapPolicyQueue, OverlapPolicySkip)
+	}
+
+	switch c.MissedRunPolicy {
+	case "", MissedRunPolicySkip:
+	case MissedRunPolicyRunOnce:
+		if c.LastRun.ConfigMap == "" {
+			return fmt.Errorf("missedRunPolicy %q requires lastRun.configMap", MissedRunPolicyRunOnce)
+		}
+	default:
+		return fmt.Errorf("missedRunPolicy must be %q or %q", MissedRunPolicySkip, MissedRunPolicyRunOnce)
+	}
+
+	if c.StartingDeadline.Duration < 0 {
+		return fmt.Errorf("startingDeadline cannot be negative")
+	}
+
 	if err := c.PodCleanupConfig.Validate(); err != nil {
 		return fmt.Errorf("pod cleanup config error: %w", err)
 	}
 
+	if err := c.CertManagerCleanupConfig.Validate(); err != nil {
+		return fmt.Errorf("cert-manager cleanup config error: %w", err)
+	}
+
+	if err := c.OrphanCleanupConfig.Validate(); err != nil {
+		return fmt.Errorf("orphan cleanup config error: %w", err)
+	}
+
+	if err := c.IdleWorkloadConfig.Validate(); err != nil {
+		return fmt.Errorf("idle workload config error: %w", err)
+	}
+
+	if err := c.StaleCronJobConfig.Validate(); err != nil {
+		return fmt.Errorf("stale cronjob config error: %w", err)
+	}
+
+	if err := c.GenericCleanupConfig.Validate(); err != nil {
+		return fmt.Errorf("generic cleanup config error: %w", err)
+	}
+
+	if err := c.Notifications.Validate(); err != nil {
+		return fmt.Errorf("notifications config error: %w", err)
+	}
+
+	if err := c.AnomalyDetection.Validate(); err != nil {
+		return fmt.Errorf("anomaly detection config error: %w", err)
+	}
+
+	if err := c.TenantRules.Validate(); err != nil {
+		return fmt.Errorf("tenant rules config error: %w", err)
+	}
+
+	if err := c.Constraints.Validate(); err != nil {
+		return fmt.Errorf("constraints config error: %w", err)
+	}
+
+	if err := c.LogForwarding.Validate(); err != nil {
+		return fmt.Errorf("log forwarding config error: %w", err)
+	}
+
+	if err := c.Receipts.Validate(); err != nil {
+		return fmt.Errorf("receipts config error: %w", err)
+	}
+
+	if err := c.NamespaceNotifications.Validate(); err != nil {
+		return fmt.Errorf("namespace notifications config error: %w", err)
+	}
+
+	if err := c.RetryQueue.Validate(); err != nil {
+		return fmt.Errorf("retry queue config error: %w", err)
+	}
+
+	if err := c.RunEvents.Validate(); err != nil {
+		return fmt.Errorf("run events config error: %w", err)
+	}
+
+	if err := c.LastRun.Validate(); err != nil {
+		return fmt.Errorf("last run config error: %w", err)
+	}
+
+	if err := c.ReloadSafety.Validate(); err != nil {
+		return fmt.Errorf("reload safety config error: %w", err)
+	}
+
+	if err := c.Ledger.Validate(); err != nil {
+		return fmt.Errorf("ledger config error: %w", err)
+	}
+
+	if err := c.RuleCooldown.Validate(); err != nil {
+		return fmt.Errorf("rule cooldown config error: %w", err)
+	}
+
+	if err := c.CircuitBreaker.Validate(); err != nil {
+		return fmt.Errorf("circuit breaker config error: %w", err)
+	}
+
+	if err := c.Lanes.Validate(); err != nil {
+		return fmt.Errorf("lanes config error: %w", err)
+	}
+
+	if !c.Constraints.Clamps() {
+		if c.Constraints.MaxBatchSize > 0 && c.BatchSize > c.Constraints.MaxBatchSize {
+			return fmt.Errorf("batch size %d exceeds the maxBatchSize constraint of %d", c.BatchSize, c.Constraints.MaxBatchSize)
+		}
+
+		for _, rule := range c.PodCleanupConfig.EffectiveRules() {
+			if !rule.Enabled {
+				continue
+			}
+			if _, err := c.Constraints.Enforce(rule); err != nil {
+				return err
+			}
+		}
+	}
+
+	for _, sink := range c.AnomalyDetection.NotificationSinks {
+		if !c.Notifications.HasSink(sink) {
+			return fmt.Errorf("anomalyDetection references unknown notification sink %q", sink)
+		}
+	}
+
+	for _, sink := range c.ReloadSafety.NotificationSinks {
+		if !c.Notifications.HasSink(sink) {
+			return fmt.Errorf("reloadSafety references unknown notification sink %q", sink)
+		}
+	}
+
+	for _, sink := range c.RuleCooldown.NotificationSinks {
+		if !c.Notifications.HasSink(sink) {
+			return fmt.Errorf("ruleCooldown references unknown notification sink %q", sink)
+		}
+	}
+
+	for _, rule := range c.PodCleanupConfig.Rules {
+		if err := c.Notifications.checkReferences(rule.Name, rule.NotificationSinks); err != nil {
+			return err
+		}
+	}
+
+	for _, rule := range c.CertManagerCleanupConfig.Rules {
+		if err := c.Notifications.checkReferences(rule.Name, rule.NotificationSinks); err != nil {
+			return err
+		}
+	}
+
+	for _, rule := range c.OrphanCleanupConfig.Rules {
+		if err := c.Notifications.checkReferences(rule.Name, rule.NotificationSinks); err != nil {
+			return err
+		}
+	}
+
+	for _, rule := range c.IdleWorkloadConfig.Rules {
+		if err := c.Notifications.checkReferences(rule.Name, rule.NotificationSinks); err != nil {
+			return err
+		}
+	}
+
+	for _, rule := range c.StaleCronJobConfig.Rules {
+		if err := c.Notifications.checkReferences(rule.Name, rule.NotificationSinks); err != nil {
+			return err
+		}
+	}
+
+	for _, rule := range c.GenericCleanupConfig.Rules {
+		if err := c.Notifications.checkReferences(rule.Name, rule.NotificationSinks); err != nil {
+			return err
+		}
+	}
+
 	return nil
 }
 
@@ -50,33 +311,53 @@ type Duration struct {
 	time.Duration `yaml:"ttl"`
 }
 
-// UnmarshalYAML parses a string from YAML into a time.Duration.
-// Example YAML value: "5m", "1h30m", etc.
+// UnmarshalYAML parses a YAML value into a time.Duration.
+// Example YAML values: 90 (seconds), "5m", "1h30m", "PT1H30M", "P1D".
 func (d *Duration) UnmarshalYAML(unmarshal func(interface{}) error) error {
-	var s string
-	if err := unmarshal(&s); err != nil {
+	var value interface{}
+	if err := unmarshal(&value); err != nil {
 		return err
 	}
 
-	duration, err := time.ParseDuration(s)
+	duration, err := parseDuration(value)
 	if err != nil {
-		return fmt.Errorf("invalid duration %q: %w", s, err)
+		return err
 	}
 
 	d.Duration = duration
 	return nil
 }
 
+// MarshalYAML writes the duration in the form UnmarshalYAML reads, e.g. "1h30m0s".
+func (d Duration) MarshalYAML() (interface{}, error) {
+	return d.String(), nil
+}
+
 //
 // Pod Cleanup Configuration
 //
 
 // PodCleanupConfig defines rules and settings for cleaning up Kubernetes pods.
 type PodCleanupConfig struct {
-	Enabled bool           `yaml:"enabled,omitempty"` // If false, pod cleanup is disabled.
-	Rules   []PodCleanRule `yaml:"rules,omitempty"`   // List of rules for selecting and cleaning up pods.
+	Enabled                 bool           `yaml:"enabled,omitempty"`                 // If false, pod cleanup is disabled.
+	ExcludePriorityClasses  []string       `yaml:"excludePriorityClasses,omitempty"`  // Priority classes never matched by any rule.
+	SkipCordonedNodes       bool           `yaml:"skipCordonedNodes,omitempty"`       // Default rules to skip pods on cordoned or draining nodes.
+	RulePolicy              string         `yaml:"rulePolicy,omitempty"`              // firstMatch or allMatch (default); see RulePolicy constants.
+	InvalidAnnotationPolicy string         `yaml:"invalidAnnotationPolicy,omitempty"` // Default handling of malformed kubeclean annotations; see InvalidAnnotation constants.
+	Rules                   []PodCleanRule `yaml:"rules,omitempty"`                   // List of rules for selecting and cleaning up pods.
+	UseRecommendedDefaults  bool           `yaml:"useRecommendedDefaults,omitempty"`  // Adds the recommended rules not replaced by a rule of the same name; see RecommendedRules.
+
+	QuotaPressure    QuotaPressureConfig    `yaml:"quotaPressure,omitempty"`    // Prioritizes namespaces close to a ResourceQuota limit.
+	NodePressure     NodePressureConfig     `yaml:"nodePressure,omitempty"`     // Cleans up completed pods on nodes under pressure right away.
+	BusinessCalendar BusinessCalendarConfig `yaml:"businessCalendar,omitempty"` // Business days counted by ttlBusinessDays.
 }
 
+// Rule policies decide how many rules may act on the same pod within a run.
+const (
+	RulePolicyAllMatch   = "allMatch"   // Every matching rule acts on the pod.
+	RulePolicyFirstMatch = "firstMatch" // Only the highest-priority matching rule acts on the pod.
+)
+
 // Validate ensures PodCleanupConfig is correctly configured.
 // It validates each rule if the config is enabled.
 func (p *PodCleanupConfig) Validate() error {
@@ -86,12 +367,38 @@ func (p *PodCleanupConfig) Validate() error {
 
 	var errorMessages string
 
-	for idx, rule := range p.Rules {
+	switch p.RulePolicy {
+	case "", RulePolicyAllMatch, RulePolicyFirstMatch:
+	default:
+		errorMessages += fmt.Sprintf("rulePolicy must be %q or %q\n", RulePolicyAllMatch, RulePolicyFirstMatch)
+	}
+
+	if err := validateInvalidAnnotationPolicy(p.InvalidAnnotationPolicy); err != nil {
+		errorMessages += err.Error() + "\n"
+	}
+
+	if err := p.QuotaPressure.Validate(); err != nil {
+		errorMessages += fmt.Sprintf("quotaPressure: %v\n", err)
+	}
+
+	if err := p.NodePressure.Validate(); err != nil {
+		errorMessages += fmt.Sprintf("nodePressure: %v\n", err)
+	}
+
+	if err := p.BusinessCalendar.Validate(); err != nil {
+		errorMessages += fmt.Sprintf("businessCalendar: %v\n", err)
+	}
+
+	for idx, rule := range p.EffectiveRules() {
 		if err := rule.Validate(); err != nil {
 			errorMessages += fmt.Sprintf("rule %d (%s): %v\n", idx+1, rule.Name, err)
 		}
 	}
 
+	if err := validateRuleOrder(p.EffectiveRules()); err != nil {
+		errorMessages += err.Error() + "\n"
+	}
+
 	if errorMessages == "" {
 		return nil
 	}
@@ -99,18 +406,189 @@ func (p *PodCleanupConfig) Validate() error {
 	return fmt.Errorf("pod cleanup config validation errors:\n%s", errorMessages)
 }
 
+// validateRuleOrder checks that every rule named by an 'after' list exists and that the lists
+// do not form a cycle, which no order could satisfy.
+func validateRuleOrder(rules []PodCleanRule) error {
+	byName := make(map[string]PodCleanRule, len(rules))
+	for _, rule := range rules {
+		byName[rule.Name] = rule
+	}
+
+	for _, rule := range rules {
+		for _, name := range rule.After {
+			if name == rule.Name {
+				return fmt.Errorf("rule %q cannot run after itself", rule.Name)
+			}
+			if _, ok := byName[name]; !ok {
+				return fmt.Errorf("rule %q runs after unknown rule %q", rule.Name, name)
+			}
+		}
+	}
+
+	// Depth-first search; a rule reached again while still on the path closes a cycle.
+	const (
+		visiting = 1
+		visited  = 2
+	)
+	state := make(map[string]int, len(rules))
+	var path []string
+	var visit func(name string) error
+	visit = func(name string) error {
+		switch state[name] {
+		case visited:
+			return nil
+		case visiting:
+			cycle := append(slices.Clone(path[slices.Index(path, name):]), name)
+			return fmt.Errorf("rules depend on each other through 'after': %s", strings.Join(cycle, " -> "))
+		}
+
+		state[name] = visiting
+		path = append(path, name)
+		for _, dependency := range byName[name].After {
+			if err := visit(dependency); err != nil {
+				return err
+			}
+		}
+		path = path[:len(path)-1]
+		state[name] = visited
+		return nil
+	}
+
+	for _, rule := range rules {
+		if err := visit(rule.Name); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 //
 // Pod Cleanup Rule Configuration
 //
 
+// Invalid annotation policies decide how a rule treats pods whose kubeclean annotations are malformed.
+const (
+	InvalidAnnotationUseRuleTTL = "useRuleTTL" // Ignore the annotation and apply the rule TTL (default).
+	InvalidAnnotationSkip       = "skip"       // Never match the pod.
+	InvalidAnnotationFail       = "fail"       // Match nothing for the rule in this run and report an error.
+)
+
+// validateInvalidAnnotationPolicy checks that policy is empty or one of the InvalidAnnotation constants.
+func validateInvalidAnnotationPolicy(policy string) error {
+	switch policy {
+	case "", InvalidAnnotationUseRuleTTL, InvalidAnnotationSkip, InvalidAnnotationFail:
+		return nil
+	default:
+		return fmt.Errorf("invalidAnnotationPolicy must be one of %q, %q or %q", InvalidAnnotationUseRuleTTL, InvalidAnnotationSkip, InvalidAnnotationFail)
+	}
+}
+
+// Cordoned node modes control how a rule treats pods on unschedulable or draining nodes.
+const (
+	CordonedNodesInclude = "include" // Match pods regardless of node schedulability.
+	CordonedNodesSkip    = "skip"    // Never match pods on cordoned or draining nodes.
+	CordonedNodesOnly    = "only"    // Only match pods on cordoned or draining nodes.
+)
+
+// Actions a pod rule can take, used to bound the rules tenants may define.
+const (
+	ActionDelete      = "delete"      // Delete matched pods.
+	ActionDeleteOwner = "deleteOwner" // Delete the Job owning matched pods; see deleteOwnerWhenEmpty.
+)
+
 // PodCleanRule defines an individual cleanup rule for selecting and deleting pods.
 type PodCleanRule struct {
-	Name       string               `yaml:"name"`                 // Unique name of the rule for identification.
-	Enabled    bool                 `yaml:"enabled,omitempty"`    // If false, the rule is skipped during processing.
-	Selector   metav1.LabelSelector `yaml:"selector,omitempty"`   // Label selector to filter pods.
-	Phase      string               `yaml:"phase,omitempty"`      // Pod phase (e.g., "Succeeded", "Failed") to filter pods.
-	TTL        Duration             `yaml:"ttl"`                  // Time-to-live duration after which pods are eligible for cleanup.
-	Namespaces []string             `yaml:"namespaces,omitempty"` // Specific namespaces where the rule applies.
+	Name                   string               `yaml:"name"`                             // Unique name of the rule for identification.
+	Enabled                bool                 `yaml:"enabled,omitempty"`                // If false, the rule is skipped during processing.
+	Lane                   string               `yaml:"lane,omitempty"`                   // fast (default) or slow; see LanesConfig.
+	Priority               int                  `yaml:"priority,omitempty"`               // Rules are evaluated from highest to lowest priority; ties keep file order.
+	After                  []string             `yaml:"after,omitempty"`                  // Rules evaluated before this one within a run, whatever their priority.
+	Selector               metav1.LabelSelector `yaml:"selector,omitempty"`               // Label selector to filter pods.
+	Phase                  string               `yaml:"phase,omitempty"`                  // Pod phase (e.g., "Succeeded", "Failed") to filter pods.
+	Match                  *MatchCriteria       `yaml:"match,omitempty"`                  // Composed criteria; replaces 'phase' when set.
+	TTL                    Duration             `yaml:"ttl"`                              // Time-to-live duration after which pods are eligible for cleanup.
+	TTLBusinessDays        int                  `yaml:"ttlBusinessDays,omitempty"`        // Business days after which pods are eligible, in place of ttl; see BusinessCalendarConfig.
+	Namespaces             []string             `yaml:"namespaces,omitempty"`             // Specific namespaces where the rule applies.
+	ExcludePriorityClasses []string             `yaml:"excludePriorityClasses,omitempty"` // Priority classes this rule never matches.
+
+	NamespaceSelector *metav1.LabelSelector `yaml:"namespaceSelector,omitempty"` // Applies the rule to namespaces with these labels instead of a fixed list.
+
+	ExcludeSelector *metav1.LabelSelector `yaml:"excludeSelector,omitempty"` // Pods matching this selector are excluded after the include selector.
+
+	NodeSelector  *metav1.LabelSelector `yaml:"nodeSelector,omitempty"`  // Only match pods on nodes with these labels.
+	NodeNames     []string              `yaml:"nodeNames,omitempty"`     // Only match pods on these nodes.
+	CordonedNodes string                `yaml:"cordonedNodes,omitempty"` // One of include, skip or only; defaults from skipCordonedNodes.
+	Zones         []string              `yaml:"zones,omitempty"`         // Only match pods on nodes in these topology.kubernetes.io/zone values.
+	Regions       []string              `yaml:"regions,omitempty"`       // Only match pods on nodes in these topology.kubernetes.io/region values.
+	NodeDeleted   bool                  `yaml:"nodeDeleted,omitempty"`   // Only match pods bound to a node that no longer exists.
+
+	InvalidAnnotationPolicy string `yaml:"invalidAnnotationPolicy,omitempty"` // One of useRuleTTL, skip or fail; defaults from the pod cleanup config.
+
+	ForbiddenNamespaces []string `yaml:"-"` // Set from the constraints; pods in these namespaces are never matched.
+	MinTTL              Duration `yaml:"-"` // Set from the constraints; kubeclean/ttl annotations below it are raised to it.
+
+	QuotaPressure    QuotaPressureConfig    `yaml:"-"` // Set from the pod cleanup config; see QuotaPressureConfig.
+	BusinessCalendar BusinessCalendarConfig `yaml:"-"` // Set from the pod cleanup config; see BusinessCalendarConfig.
+
+	NotificationSinks []string `yaml:"notificationSinks,omitempty"` // Sinks notified for this rule; defaults to all global sinks.
+
+	GroupBySparkApplication bool `yaml:"groupBySparkApplication,omitempty"` // Treat pods sharing a spark-app-selector label as one unit.
+	DeleteOwnerWhenEmpty    bool `yaml:"deleteOwnerWhenEmpty,omitempty"`    // Delete the owning Job, with foreground propagation, once all its pods match.
+	SkipDuringRollout       bool `yaml:"skipDuringRollout,omitempty"`       // Leave pods of Deployments and StatefulSets alone while they roll out.
+	MinAvailable            int  `yaml:"minAvailable,omitempty"`            // Ready replicas each owner must keep; ready pods beyond that are left alone.
+	MaxPerNode              int  `yaml:"maxPerNode,omitempty"`              // Completed pods to keep per node; older ones are matched before their TTL.
+
+	Action ActionConfig `yaml:"action,omitempty"` // What to do with matched pods; deletes them by default.
+}
+
+// UnmarshalYAML decodes a PodCleanRule, translating its label selectors from their YAML form.
+func (r *PodCleanRule) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	type plain PodCleanRule
+	if err := unmarshal((*plain)(r)); err != nil {
+		return err
+	}
+
+	var selectors struct {
+		Selector          *yamlLabelSelector `yaml:"selector"`
+		NamespaceSelector *yamlLabelSelector `yaml:"namespaceSelector"`
+		ExcludeSelector   *yamlLabelSelector `yaml:"excludeSelector"`
+		NodeSelector      *yamlLabelSelector `yaml:"nodeSelector"`
+	}
+	if err := unmarshal(&selectors); err != nil {
+		return err
+	}
+
+	if selector := selectors.Selector.toLabelSelector(); selector != nil {
+		r.Selector = *selector
+	}
+	r.NamespaceSelector = selectors.NamespaceSelector.toLabelSelector()
+	r.ExcludeSelector = selectors.ExcludeSelector.toLabelSelector()
+	r.NodeSelector = selectors.NodeSelector.toLabelSelector()
+
+	return nil
+}
+
+// Actions returns the actions the rule takes on the pods it matches.
+func (r *PodCleanRule) Actions() []string {
+	actions := []string{r.Action.EffectiveType()}
+	if r.DeleteOwnerWhenEmpty {
+		actions = append(actions, ActionDeleteOwner)
+	}
+	return actions
+}
+
+// MinimumTTL returns the shortest time after which the rule matches a pod. A business day lasts
+// at least 24 hours, so ttlBusinessDays is at least as many days.
+func (r *PodCleanRule) MinimumTTL() time.Duration {
+	if r.TTLBusinessDays > 0 {
+		return time.Duration(r.TTLBusinessDays) * 24 * time.Hour
+	}
+	return r.TTL.Duration
+}
+
+// IsNodeScoped reports whether the rule restricts matching to specific nodes.
+func (r *PodCleanRule) IsNodeScoped() bool {
+	return r.NodeSelector != nil || len(r.NodeNames) > 0 || len(r.Zones) > 0 || len(r.Regions) > 0 || r.NodeDeleted
 }
 
 // Validate checks whether the PodCleanRule is correctly defined.
@@ -124,13 +602,166 @@ func (r *PodCleanRule) Validate() error {
 		return fmt.Errorf("rule name must be provided")
 	}
 
-	if r.TTL.Duration <= 0 {
+	if err := validateLane(r.Lane); err != nil {
+		return err
+	}
+
+	if r.TTLBusinessDays < 0 {
+		return fmt.Errorf("ttlBusinessDays cannot be negative")
+	}
+
+	if r.TTLBusinessDays > 0 && r.TTL.Duration != 0 {
+		return fmt.Errorf("'ttl' cannot be combined with 'ttlBusinessDays'")
+	}
+
+	if r.TTL.Duration <= 0 && r.TTLBusinessDays == 0 {
 		return fmt.Errorf("ttl must be greater than zero")
 	}
 
-	// Require at least 'phase' or 'selector.matchLabels' to be set.
-	if r.Phase == "" && len(r.Selector.MatchLabels) == 0 {
-		return fmt.Errorf("either 'phase' or 'selector.matchLabels' must be specified")
+	if r.MinAvailable < 0 {
+		return fmt.Errorf("minAvailable cannot be negative")
+	}
+
+	if r.MaxPerNode < 0 {
+		return fmt.Errorf("maxPerNode cannot be negative")
+	}
+
+	if r.Match != nil {
+		if r.Phase != "" {
+			return fmt.Errorf("'phase' cannot be combined with 'match'; express it as a match condition")
+		}
+
+		if err := r.Match.Validate(); err != nil {
+			return fmt.Errorf("invalid match: %w", err)
+		}
+	} else if r.Phase == "" && len(r.Selector.MatchLabels) == 0 && !r.NodeDeleted {
+		// Require at least 'phase', 'selector.matchLabels' or 'nodeDeleted' to be set.
+		return fmt.Errorf("either 'phase', 'selector.matchLabels', 'match' or 'nodeDeleted' must be specified")
+	}
+
+	switch r.Phase {
+	case "", "Pending", "Running", "Succeeded", "Failed", "Unknown":
+	default:
+		return fmt.Errorf("unknown pod phase %q", r.Phase)
+	}
+
+	if r.NodeDeleted && (r.NodeSelector != nil || len(r.Zones) > 0 || len(r.Regions) > 0 || r.CordonedNodes == CordonedNodesOnly) {
+		return fmt.Errorf("'nodeDeleted' cannot be combined with node labels, zones, regions or cordonedNodes: only")
+	}
+
+	if r.NamespaceSelector != nil {
+		if len(r.Namespaces) > 0 {
+			return fmt.Errorf("'namespaceSelector' cannot be combined with 'namespaces'")
+		}
+
+		if _, err := metav1.LabelSelectorAsSelector(r.NamespaceSelector); err != nil {
+			return fmt.Errorf("invalid namespaceSelector: %w", err)
+		}
+	}
+
+	if r.ExcludeSelector != nil {
+		if _, err := metav1.LabelSelectorAsSelector(r.ExcludeSelector); err != nil {
+			return fmt.Errorf("invalid excludeSelector: %w", err)
+		}
+	}
+
+	if r.NodeSelector != nil {
+		if _, err := metav1.LabelSelectorAsSelector(r.NodeSelector); err != nil {
+			return fmt.Errorf("invalid nodeSelector: %w", err)
+		}
+	}
+
+	switch r.CordonedNodes {
+	case "", CordonedNodesInclude, CordonedNodesSkip, CordonedNodesOnly:
+	default:
+		return fmt.Errorf("cordonedNodes must be one of %q, %q or %q", CordonedNodesInclude, CordonedNodesSkip, CordonedNodesOnly)
+	}
+
+	if err := r.Action.Validate(); err != nil {
+		return fmt.Errorf("invalid action: %w", err)
+	}
+
+	if r.DeleteOwnerWhenEmpty && r.Action.EffectiveType() != ActionDelete {
+		return fmt.Errorf("'deleteOwnerWhenEmpty' requires the %q action", ActionDelete)
+	}
+
+	return validateInvalidAnnotationPolicy(r.InvalidAnnotationPolicy)
+}
+
+//
+// Rule Match Criteria
+//
+
+// MatchCriteria composes condition blocks with explicit semantics: every condition in All must
+// hold, and when Any is set at least one of its conditions must hold as well.
+type MatchCriteria struct {
+	All []MatchCondition `yaml:"all,omitempty"` // Conditions that must all hold.
+	Any []MatchCondition `yaml:"any,omitempty"` // Conditions of which at least one must hold.
+}
+
+// Validate ensures the criteria contain at least one condition and that every condition is valid.
+func (m *MatchCriteria) Validate() error {
+	if len(m.All) == 0 && len(m.Any) == 0 {
+		return fmt.Errorf("at least one 'all' or 'any' condition must be specified")
+	}
+
+	for idx, condition := range m.All {
+		if err := condition.Validate(); err != nil {
+			return fmt.Errorf("all[%d]: %w", idx, err)
+		}
+	}
+
+	for idx, condition := range m.Any {
+		if err := condition.Validate(); err != nil {
+			return fmt.Errorf("any[%d]: %w", idx, err)
+		}
+	}
+
+	return nil
+}
+
+// MatchCondition is a single criterion block; every field that is set must hold for the block to match.
+type MatchCondition struct {
+	Phase       string                `yaml:"phase,omitempty"`       // Pod phase (e.g., "Failed").
+	Reason      string                `yaml:"reason,omitempty"`      // Pod status reason (e.g., "Evicted").
+	Selector    *metav1.LabelSelector `yaml:"selector,omitempty"`    // Label selector the pod must match.
+	Annotations map[string]string     `yaml:"annotations,omitempty"` // Annotations that must be present; an empty value matches any value.
+}
+
+// UnmarshalYAML decodes a MatchCondition, translating its label selector from its YAML form.
+func (c *MatchCondition) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	type plain MatchCondition
+	if err := unmarshal((*plain)(c)); err != nil {
+		return err
+	}
+
+	var selectors struct {
+		Selector *yamlLabelSelector `yaml:"selector"`
+	}
+	if err := unmarshal(&selectors); err != nil {
+		return err
+	}
+
+	c.Selector = selectors.Selector.toLabelSelector()
+	return nil
+}
+
+// Validate ensures the condition sets at least one criterion and that each criterion is well formed.
+func (c *MatchCondition) Validate() error {
+	if c.Phase == "" && c.Reason == "" && c.Selector == nil && len(c.Annotations) == 0 {
+		return fmt.Errorf("condition must set at least one of 'phase', 'reason', 'selector' or 'annotations'")
+	}
+
+	switch c.Phase {
+	case "", "Pending", "Running", "Succeeded", "Failed", "Unknown":
+	default:
+		return fmt.Errorf("unknown pod phase %q", c.Phase)
+	}
+
+	if c.Selector != nil {
+		if _, err := metav1.LabelSelectorAsSelector(c.Selector); err != nil {
+			return fmt.Errorf("invalid selector: %w", err)
+		}
 	}
 
 	return nil
@@ -2,12 +2,25 @@ package cleanupconfig
 
 import (
 	"context"
+	"crypto/ed25519"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
 	"os"
+	"path/filepath"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 
+	"github.com/prometheus/client_golang/prometheus/testutil"
 	"github.com/stretchr/testify/require"
+	yaml2 "gopkg.in/yaml.v2"
 	"gopkg.in/yaml.v3"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
@@ -47,6 +60,915 @@ func TestCleanupConfig_Validate(t *testing.T) {
 			},
 			expectErr: true,
 		},
+		{
+			name: "rule references configured sink",
+			config: CleanupConfig{
+				Notifications: NotificationConfig{
+					Sinks: []NotificationSink{{Name: "storage-oncall", Type: SinkTypeWebhook, URL: "https://hooks.example.com/storage"}},
+				},
+				PodCleanupConfig: PodCleanupConfig{
+					Rules: []PodCleanRule{{Name: "pvc", NotificationSinks: []string{"storage-oncall"}}},
+				},
+			},
+			expectErr: false,
+		},
+		{
+			name: "rule references unknown sink",
+			config: CleanupConfig{
+				PodCleanupConfig: PodCleanupConfig{
+					Rules: []PodCleanRule{{Name: "pvc", NotificationSinks: []string{"storage-oncall"}}},
+				},
+			},
+			expectErr: true,
+		},
+		{
+			name: "duplicate sink names",
+			config: CleanupConfig{
+				Notifications: NotificationConfig{
+					Sinks: []NotificationSink{
+						{Name: "chat", Type: SinkTypeSlack, URL: "https://hooks.slack.com/a"},
+						{Name: "chat", Type: SinkTypeSlack, URL: "https://hooks.slack.com/b"},
+					},
+				},
+			},
+			expectErr: true,
+		},
+		{
+			name: "unknown sink type",
+			config: CleanupConfig{
+				Notifications: NotificationConfig{
+					Sinks: []NotificationSink{{Name: "pager", Type: "carrier-pigeon", URL: "https://example.com"}},
+				},
+			},
+			expectErr: true,
+		},
+		{
+			name: "sink without absolute url",
+			config: CleanupConfig{
+				Notifications: NotificationConfig{
+					Sinks: []NotificationSink{{Name: "chat", Type: SinkTypeSlack, URL: "hooks.slack.com"}},
+				},
+			},
+			expectErr: true,
+		},
+		{
+			name: "sink with url read from a secret",
+			config: CleanupConfig{
+				Notifications: NotificationConfig{
+					Sinks: []NotificationSink{{Name: "chat", Type: SinkTypeSlack, URLFrom: &SecretKeyRef{Secret: "kubeclean/slack", Key: "url"}}},
+				},
+			},
+			expectErr: false,
+		},
+		{
+			name: "sink with both url and urlFrom",
+			config: CleanupConfig{
+				Notifications: NotificationConfig{
+					Sinks: []NotificationSink{{
+						Name: "chat", Type: SinkTypeSlack, URL: "https://hooks.slack.com/b",
+						URLFrom: &SecretKeyRef{Secret: "kubeclean/slack", Key: "url"},
+					}},
+				},
+			},
+			expectErr: true,
+		},
+		{
+			name: "email sink",
+			config: CleanupConfig{
+				Notifications: NotificationConfig{
+					Sinks: []NotificationSink{{
+						Name: "ops-mail", Type: SinkTypeEmail, Template: "{{.Message}}",
+						Email: &EmailSinkConfig{
+							Host: "smtp.internal", From: "kubeclean@example.com", To: []string{"ops@example.com"},
+							Username: "kubeclean", PasswordFrom: &SecretKeyRef{Secret: "kubeclean/smtp", Key: "password"},
+							TLS: SMTPTLSConfig{CAFile: "/etc/ssl/corp-ca.pem"},
+						},
+					}},
+				},
+			},
+			expectErr: false,
+		},
+		{
+			name: "email sink without recipients",
+			config: CleanupConfig{
+				Notifications: NotificationConfig{
+					Sinks: []NotificationSink{{
+						Name: "ops-mail", Type: SinkTypeEmail,
+						Email: &EmailSinkConfig{Host: "smtp.internal", From: "kubeclean@example.com"},
+					}},
+				},
+			},
+			expectErr: true,
+		},
+		{
+			name: "email sink with password but no username",
+			config: CleanupConfig{
+				Notifications: NotificationConfig{
+					Sinks: []NotificationSink{{
+						Name: "ops-mail", Type: SinkTypeEmail,
+						Email: &EmailSinkConfig{Host: "smtp.internal", From: "kubeclean@example.com", To: []string{"ops@example.com"}, Password: "secret"},
+					}},
+				},
+			},
+			expectErr: true,
+		},
+		{
+			name: "file sink with relative directory",
+			config: CleanupConfig{
+				Notifications: NotificationConfig{
+					Sinks: []NotificationSink{{Name: "drop", Type: SinkTypeFile, File: &FileSinkConfig{Directory: "spool"}}},
+				},
+			},
+			expectErr: true,
+		},
+		{
+			name: "file sink with invalid template",
+			config: CleanupConfig{
+				Notifications: NotificationConfig{
+					Sinks: []NotificationSink{{Name: "drop", Type: SinkTypeFile, File: &FileSinkConfig{Directory: "/var/spool/kubeclean"}, Template: "{{.Rule"}},
+				},
+			},
+			expectErr: true,
+		},
+		{
+			name: "slack sink with payload template",
+			config: CleanupConfig{
+				Notifications: NotificationConfig{
+					Sinks: []NotificationSink{{
+						Name: "chat", Type: SinkTypeSlack, URL: "https://hooks.slack.com/a",
+						Template: `{"text": {{toJson (printf "%s: %d of %d" .Rule .Pods .Summary.Matched)}}}`,
+					}},
+				},
+			},
+			expectErr: false,
+		},
+		{
+			name: "webhook sink with template calling an unknown function",
+			config: CleanupConfig{
+				Notifications: NotificationConfig{
+					Sinks: []NotificationSink{{Name: "hook", Type: SinkTypeWebhook, URL: "https://example.com", Template: "{{toYaml .}}"}},
+				},
+			},
+			expectErr: true,
+		},
+		{
+			name: "log forwarding url from a secret without key",
+			config: CleanupConfig{
+				LogForwarding: LogForwardingConfig{Enabled: true, Backend: LogBackendLoki, URLFrom: &SecretKeyRef{Secret: "kubeclean/loki"}},
+			},
+			expectErr: true,
+		},
+		{
+			name: "negative max deletions per run",
+			config: CleanupConfig{
+				MaxDeletionsPerRun: -1,
+			},
+			expectErr: true,
+		},
+		{
+			name: "negative per namespace max deletions",
+			config: CleanupConfig{
+				PerNamespaceMaxDeletions: -1,
+			},
+			expectErr: true,
+		},
+		{
+			name: "negative warmup runs",
+			config: CleanupConfig{
+				WarmupRuns: -1,
+			},
+			expectErr: true,
+		},
+		{
+			name: "valid anomaly detection",
+			config: CleanupConfig{
+				AnomalyDetection: AnomalyDetectionConfig{Enabled: true, Window: 10, Threshold: 2.5},
+			},
+			expectErr: false,
+		},
+		{
+			name: "anomaly threshold not above baseline",
+			config: CleanupConfig{
+				AnomalyDetection: AnomalyDetectionConfig{Enabled: true, Threshold: 0.5},
+			},
+			expectErr: true,
+		},
+		{
+			name: "anomaly detection with unknown sink",
+			config: CleanupConfig{
+				AnomalyDetection: AnomalyDetectionConfig{Enabled: true, NotificationSinks: []string{"pager"}},
+			},
+			expectErr: true,
+		},
+		{
+			name: "tenant rules with unknown allowed action",
+			config: CleanupConfig{
+				TenantRules: TenantRulesConfig{Enabled: true, AllowedActions: []string{"shred"}},
+			},
+			expectErr: true,
+		},
+		{
+			name: "file rule below the minTTL constraint",
+			config: CleanupConfig{
+				PodCleanupConfig: PodCleanupConfig{
+					Enabled: true,
+					Rules:   []PodCleanRule{{Name: "eager", Enabled: true, Phase: "Succeeded", TTL: Duration{Duration: time.Minute}}},
+				},
+				Constraints: ConstraintsConfig{MinTTL: Duration{Duration: time.Hour}},
+			},
+			expectErr: true,
+		},
+		{
+			name: "file rule below the minTTL constraint in clamp mode",
+			config: CleanupConfig{
+				PodCleanupConfig: PodCleanupConfig{
+					Enabled: true,
+					Rules:   []PodCleanRule{{Name: "eager", Enabled: true, Phase: "Succeeded", TTL: Duration{Duration: time.Minute}}},
+				},
+				Constraints: ConstraintsConfig{Mode: ConstraintModeClamp, MinTTL: Duration{Duration: time.Hour}},
+			},
+			expectErr: false,
+		},
+		{
+			name: "batch size above the maxBatchSize constraint",
+			config: CleanupConfig{
+				BatchSize:   50,
+				Constraints: ConstraintsConfig{MaxBatchSize: 20},
+			},
+			expectErr: true,
+		},
+		{
+			name: "valid loki log forwarding",
+			config: CleanupConfig{
+				LogForwarding: LogForwardingConfig{Enabled: true, Backend: LogBackendLoki, URL: "http://loki:3100", Phases: []string{"Failed", "Succeeded"}},
+			},
+			expectErr: false,
+		},
+		{
+			name: "log forwarding with unknown backend",
+			config: CleanupConfig{
+				LogForwarding: LogForwardingConfig{Enabled: true, Backend: "splunk", URL: "http://splunk:8088"},
+			},
+			expectErr: true,
+		},
+		{
+			name: "log forwarding without url",
+			config: CleanupConfig{
+				LogForwarding: LogForwardingConfig{Enabled: true, Backend: LogBackendElasticsearch},
+			},
+			expectErr: true,
+		},
+		{
+			name: "log forwarding with unknown phase",
+			config: CleanupConfig{
+				LogForwarding: LogForwardingConfig{Enabled: true, Backend: LogBackendLoki, URL: "http://loki:3100", Phases: []string{"Crashed"}},
+			},
+			expectErr: true,
+		},
+		{
+			name: "receipts with negative keep",
+			config: CleanupConfig{
+				Receipts: ReceiptsConfig{Enabled: true, Keep: -1},
+			},
+			expectErr: true,
+		},
+		{
+			name: "receipts with negative maxAge",
+			config: CleanupConfig{
+				Receipts: ReceiptsConfig{Enabled: true, MaxAge: Duration{Duration: -time.Hour}},
+			},
+			expectErr: true,
+		},
+		{
+			name: "ledger with negative maxRecords",
+			config: CleanupConfig{
+				Ledger: LedgerConfig{Enabled: true, MaxRecords: -1},
+			},
+			expectErr: true,
+		},
+		{
+			name: "namespace notifications with malformed ownership ConfigMap",
+			config: CleanupConfig{
+				NamespaceNotifications: NamespaceNotificationsConfig{Enabled: true, OwnershipConfigMap: "owners"},
+			},
+			expectErr: true,
+		},
+		{
+			name:      "negative batch delay",
+			config:    CleanupConfig{BatchDelay: Duration{Duration: -time.Second}},
+			expectErr: true,
+		},
+		{
+			name:      "skip overlap policy",
+			config:    CleanupConfig{OverlapPolicy: OverlapPolicySkip},
+			expectErr: false,
+		},
+		{
+			name:      "unknown overlap policy",
+			config:    CleanupConfig{OverlapPolicy: "parallel"},
+			expectErr: true,
+		},
+		{
+			name: "retry queue with negative max attempts",
+			config: CleanupConfig{
+				RetryQueue: RetryQueueConfig{Enabled: true, MaxAttempts: -1},
+			},
+			expectErr: true,
+		},
+		{
+			name: "retry queue persisted to a malformed ConfigMap",
+			config: CleanupConfig{
+				RetryQueue: RetryQueueConfig{Enabled: true, ConfigMap: "kubeclean-retries"},
+			},
+			expectErr: true,
+		},
+		{
+			name: "last run persisted to a malformed ConfigMap",
+			config: CleanupConfig{
+				LastRun: LastRunConfig{ConfigMap: "kubeclean-last-run"},
+			},
+			expectErr: true,
+		},
+		{
+			name: "missed runs without a persisted last run",
+			config: CleanupConfig{
+				MissedRunPolicy: MissedRunPolicyRunOnce,
+			},
+			expectErr: true,
+		},
+		{
+			name: "reload safety allowing any change",
+			config: CleanupConfig{
+				ReloadSafety: ReloadSafetyConfig{Enabled: true, MaxChangeFactor: 1},
+			},
+			expectErr: true,
+		},
+		{
+			name: "reload safety alerting an unknown sink",
+			config: CleanupConfig{
+				ReloadSafety: ReloadSafetyConfig{Enabled: true, NotificationSinks: []string{"oncall"}},
+			},
+			expectErr: true,
+		},
+		{
+			name: "unknown missed run policy",
+			config: CleanupConfig{
+				MissedRunPolicy: "runAll",
+				LastRun:         LastRunConfig{ConfigMap: "kubeclean/kubeclean-last-run"},
+			},
+			expectErr: true,
+		},
+		{
+			name: "negative starting deadline",
+			config: CleanupConfig{
+				StartingDeadline: Duration{Duration: -time.Minute},
+			},
+			expectErr: true,
+		},
+		{
+			name: "valid cert-manager rule",
+			config: CleanupConfig{
+				CertManagerCleanupConfig: CertManagerCleanupConfig{
+					Enabled: true,
+					Rules: []CertManagerCleanRule{{
+						Name: "orders", Enabled: true, Kind: CertManagerKindOrder,
+						States: []string{"invalid", "errored"}, TTL: Duration{Duration: time.Hour},
+					}},
+				},
+			},
+			expectErr: false,
+		},
+		{
+			name: "cert-manager rule with unknown kind",
+			config: CleanupConfig{
+				CertManagerCleanupConfig: CertManagerCleanupConfig{
+					Enabled: true,
+					Rules: []CertManagerCleanRule{{
+						Name: "certs", Enabled: true, Kind: "Certificate",
+						States: []string{"failed"}, TTL: Duration{Duration: time.Hour},
+					}},
+				},
+			},
+			expectErr: true,
+		},
+		{
+			name: "valid orphan rule",
+			config: CleanupConfig{
+				OrphanCleanupConfig: OrphanCleanupConfig{
+					Enabled: true,
+					Rules: []OrphanCleanRule{{
+						Name: "hpas", Enabled: true, Kind: OrphanKindHorizontalPodAutoscaler, TTL: Duration{Duration: time.Hour},
+					}},
+				},
+			},
+			expectErr: false,
+		},
+		{
+			name: "orphan rule with unknown kind",
+			config: CleanupConfig{
+				OrphanCleanupConfig: OrphanCleanupConfig{
+					Enabled: true,
+					Rules: []OrphanCleanRule{{
+						Name: "services", Enabled: true, Kind: "Service", TTL: Duration{Duration: time.Hour},
+					}},
+				},
+			},
+			expectErr: true,
+		},
+		{
+			name: "cluster role binding rule with namespaces",
+			config: CleanupConfig{
+				OrphanCleanupConfig: OrphanCleanupConfig{
+					Enabled: true,
+					Rules: []OrphanCleanRule{{
+						Name: "bindings", Enabled: true, Kind: OrphanKindClusterRoleBinding,
+						TTL: Duration{Duration: time.Hour}, Namespaces: []string{"default"},
+					}},
+				},
+			},
+			expectErr: true,
+		},
+		{
+			name: "valid idle workload rule",
+			config: CleanupConfig{
+				IdleWorkloadConfig: IdleWorkloadConfig{
+					Enabled: true,
+					Rules: []IdleWorkloadRule{{
+						Name: "idle-dev", Enabled: true, Kind: IdleKindDeployment,
+						Condition: IdleConditionNoTraffic, TTL: Duration{Duration: time.Hour},
+					}},
+				},
+			},
+			expectErr: false,
+		},
+		{
+			name: "idle annotation rule without ttl",
+			config: CleanupConfig{
+				IdleWorkloadConfig: IdleWorkloadConfig{
+					Enabled: true,
+					Rules: []IdleWorkloadRule{{
+						Name: "marked-idle", Enabled: true, Kind: IdleKindStatefulSet, Condition: IdleConditionAnnotation,
+					}},
+				},
+			},
+			expectErr: false,
+		},
+		{
+			name: "no-traffic rule without ttl",
+			config: CleanupConfig{
+				IdleWorkloadConfig: IdleWorkloadConfig{
+					Enabled: true,
+					Rules: []IdleWorkloadRule{{
+						Name: "idle-dev", Enabled: true, Kind: IdleKindDeployment, Condition: IdleConditionNoTraffic,
+					}},
+				},
+			},
+			expectErr: true,
+		},
+		{
+			name: "idle workload rule with unsupported kind",
+			config: CleanupConfig{
+				IdleWorkloadConfig: IdleWorkloadConfig{
+					Enabled: true,
+					Rules: []IdleWorkloadRule{{
+						Name: "idle-daemons", Enabled: true, Kind: "DaemonSet",
+						Condition: IdleConditionNoTraffic, TTL: Duration{Duration: time.Hour},
+					}},
+				},
+			},
+			expectErr: true,
+		},
+		{
+			name: "idle workload rule with unknown condition",
+			config: CleanupConfig{
+				IdleWorkloadConfig: IdleWorkloadConfig{
+					Enabled: true,
+					Rules: []IdleWorkloadRule{{
+						Name: "idle-dev", Enabled: true, Kind: IdleKindDeployment,
+						Condition: "lowCPU", TTL: Duration{Duration: time.Hour},
+					}},
+				},
+			},
+			expectErr: true,
+		},
+		{
+			name: "valid stale cronjob rule",
+			config: CleanupConfig{
+				StaleCronJobConfig: StaleCronJobConfig{
+					Enabled: true,
+					Rules:   []StaleCronJobRule{{Name: "zombies", Enabled: true, MaxConsecutiveFailures: 5}},
+				},
+			},
+			expectErr: false,
+		},
+		{
+			name: "stale cronjob rule without threshold",
+			config: CleanupConfig{
+				StaleCronJobConfig: StaleCronJobConfig{
+					Enabled: true,
+					Rules:   []StaleCronJobRule{{Name: "zombies", Enabled: true, NotifyOwners: true}},
+				},
+			},
+			expectErr: true,
+		},
+		{
+			name: "stale cronjob rule with negative threshold",
+			config: CleanupConfig{
+				StaleCronJobConfig: StaleCronJobConfig{
+					Enabled: true,
+					Rules: []StaleCronJobRule{{
+						Name: "zombies", Enabled: true, MaxSinceSuccess: Duration{Duration: time.Hour}, MaxConsecutiveFailures: -1,
+					}},
+				},
+			},
+			expectErr: true,
+		},
+		{
+			name: "valid generic rule",
+			config: CleanupConfig{
+				GenericCleanupConfig: GenericCleanupConfig{
+					Enabled: true,
+					Rules: []GenericCleanRule{{
+						Name: "workflows", Enabled: true, APIVersion: "argoproj.io/v1alpha1", Kind: "Workflow",
+						TTL: Duration{Duration: time.Hour}, TimestampPath: ".status.finishedAt",
+						Condition: &JSONPathCondition{Path: "{.status.phase}", Values: []string{"Succeeded"}},
+					}},
+				},
+			},
+			expectErr: false,
+		},
+		{
+			name: "generic rule without kind",
+			config: CleanupConfig{
+				GenericCleanupConfig: GenericCleanupConfig{
+					Enabled: true,
+					Rules:   []GenericCleanRule{{Name: "workflows", Enabled: true, APIVersion: "argoproj.io/v1alpha1", TTL: Duration{Duration: time.Hour}}},
+				},
+			},
+			expectErr: true,
+		},
+		{
+			name: "generic rule with invalid timestamp path",
+			config: CleanupConfig{
+				GenericCleanupConfig: GenericCleanupConfig{
+					Enabled: true,
+					Rules: []GenericCleanRule{{
+						Name: "workflows", Enabled: true, APIVersion: "argoproj.io/v1alpha1", Kind: "Workflow",
+						TTL: Duration{Duration: time.Hour}, TimestampPath: ".status[finishedAt",
+					}},
+				},
+			},
+			expectErr: true,
+		},
+		{
+			name: "generic rule with maxDeletePercent over 100",
+			config: CleanupConfig{
+				GenericCleanupConfig: GenericCleanupConfig{
+					Enabled: true,
+					Rules: []GenericCleanRule{{
+						Name: "workflows", Enabled: true, APIVersion: "argoproj.io/v1alpha1", Kind: "Workflow",
+						TTL: Duration{Duration: time.Hour}, MaxDeletePercent: 150,
+					}},
+				},
+			},
+			expectErr: true,
+		},
+		{
+			name: "generic rule with empty condition path",
+			config: CleanupConfig{
+				GenericCleanupConfig: GenericCleanupConfig{
+					Enabled: true,
+					Rules: []GenericCleanRule{{
+						Name: "workflows", Enabled: true, APIVersion: "argoproj.io/v1alpha1", Kind: "Workflow",
+						TTL: Duration{Duration: time.Hour}, Condition: &JSONPathCondition{Values: []string{"Succeeded"}},
+					}},
+				},
+			},
+			expectErr: true,
+		},
+		{
+			name: "cert-manager rule matching non-terminal state",
+			config: CleanupConfig{
+				CertManagerCleanupConfig: CertManagerCleanupConfig{
+					Enabled: true,
+					Rules: []CertManagerCleanRule{{
+						Name: "challenges", Enabled: true, Kind: CertManagerKindChallenge,
+						States: []string{"pending"}, TTL: Duration{Duration: time.Hour},
+					}},
+				},
+			},
+			expectErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.config.Validate()
+			if tt.expectErr {
+				require.Error(t, err)
+			} else {
+				require.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestOrphanCleanRule_IsDryRun(t *testing.T) {
+	var rule OrphanCleanRule
+	require.NoError(t, yaml2.Unmarshal([]byte("name: hpas\nkind: HorizontalPodAutoscaler\nttl: 1h\n"), &rule))
+	require.True(t, rule.IsDryRun(), "orphan rules should default to dry-run")
+
+	require.NoError(t, yaml2.Unmarshal([]byte("name: hpas\nkind: HorizontalPodAutoscaler\nttl: 1h\ndryRun: false\n"), &rule))
+	require.False(t, rule.IsDryRun())
+}
+
+func TestOrphanCleanRule_BurnInPeriod(t *testing.T) {
+	rule := OrphanCleanRule{Kind: OrphanKindHorizontalPodAutoscaler}
+	require.Zero(t, rule.BurnInPeriod(), "only image pull secret rules have a default burn-in")
+
+	rule = OrphanCleanRule{Kind: OrphanKindImagePullSecret}
+	require.Equal(t, DefaultImagePullSecretBurnIn, rule.BurnInPeriod())
+
+	rule.BurnIn = Duration{Duration: 48 * time.Hour}
+	require.Equal(t, 48*time.Hour, rule.BurnInPeriod())
+}
+
+func TestTenantRulesConfig_CheckBounds(t *testing.T) {
+	bounds := TenantRulesConfig{Enabled: true, MinTTL: Duration{Duration: time.Hour}}
+	rule := PodCleanRule{Name: "team-a/done", Enabled: true, Phase: "Succeeded", TTL: Duration{Duration: 2 * time.Hour}}
+	require.NoError(t, bounds.CheckBounds(rule))
+
+	rule.DeleteOwnerWhenEmpty = true
+	require.Error(t, bounds.CheckBounds(rule), "deleteOwner should not be allowed by default")
+
+	bounds.AllowedActions = []string{ActionDelete, ActionDeleteOwner}
+	require.NoError(t, bounds.CheckBounds(rule))
+
+	rule.TTL = Duration{Duration: time.Minute}
+	require.Error(t, bounds.CheckBounds(rule), "ttl below minTTL should be rejected")
+}
+
+func TestConstraintsConfig_Enforce(t *testing.T) {
+	rule := PodCleanRule{
+		Name:                 "jobs",
+		Enabled:              true,
+		Phase:                "Succeeded",
+		TTL:                  Duration{Duration: time.Minute},
+		Namespaces:           []string{"default", "kube-system"},
+		DeleteOwnerWhenEmpty: true,
+	}
+	constraints := ConstraintsConfig{
+		MinTTL:              Duration{Duration: time.Hour},
+		ForbiddenNamespaces: []string{"kube-system"},
+		ForbiddenActions:    []string{ActionDeleteOwner},
+	}
+	require.Len(t, constraints.Violations(rule), 3)
+
+	_, err := constraints.Enforce(rule)
+	require.Error(t, err, "reject mode should refuse out-of-bounds rules")
+
+	constraints.Mode = ConstraintModeClamp
+	clamped, err := constraints.Enforce(rule)
+	require.NoError(t, err)
+	require.Equal(t, time.Hour, clamped.TTL.Duration)
+	require.Equal(t, []string{"default"}, clamped.Namespaces)
+	require.False(t, clamped.DeleteOwnerWhenEmpty)
+	require.Equal(t, []string{"kube-system"}, clamped.ForbiddenNamespaces)
+	require.Equal(t, time.Hour, clamped.MinTTL.Duration)
+	require.Equal(t, []string{"default", "kube-system"}, rule.Namespaces, "the original rule should be left untouched")
+
+	rule.Namespaces = []string{"kube-system"}
+	_, err = constraints.Enforce(rule)
+	require.Error(t, err, "a rule left without namespaces cannot be clamped")
+
+	rule.Namespaces = []string{"default"}
+	rule.DeleteOwnerWhenEmpty = false
+	rule.Action = ActionConfig{Type: ActionScaleToZero}
+	constraints.ForbiddenActions = []string{ActionScaleToZero}
+	_, err = constraints.Enforce(rule)
+	require.Error(t, err, "a rule whose action is forbidden cannot be clamped")
+}
+
+func TestConstraintsConfig_EnforceBusinessDays(t *testing.T) {
+	rule := PodCleanRule{Name: "failed", Enabled: true, Phase: "Failed", TTLBusinessDays: 1}
+	constraints := ConstraintsConfig{Mode: ConstraintModeClamp, MinTTL: Duration{Duration: 36 * time.Hour}}
+	require.Len(t, constraints.Violations(rule), 1)
+
+	clamped, err := constraints.Enforce(rule)
+	require.NoError(t, err)
+	require.Equal(t, 2, clamped.TTLBusinessDays, "business days should be rounded up to cover the minimum TTL")
+	require.Zero(t, clamped.TTL.Duration)
+
+	rule.TTLBusinessDays = 2
+	require.Empty(t, constraints.Violations(rule))
+}
+
+func TestBusinessCalendarConfig_BusinessDaysAfter(t *testing.T) {
+	calendar := BusinessCalendarConfig{Holidays: []string{"2024-05-01"}}
+	friday := time.Date(2024, 4, 26, 15, 0, 0, 0, time.UTC)
+
+	require.True(t, calendar.IsBusinessDay(friday))
+	require.False(t, calendar.IsBusinessDay(friday.AddDate(0, 0, 1)), "Saturday is not a business day")
+	require.False(t, calendar.IsBusinessDay(time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)), "holidays are not business days")
+
+	require.Equal(t, time.Date(2024, 4, 30, 0, 0, 0, 0, time.UTC), calendar.BusinessDaysAfter(friday, 1),
+		"the creation day does not count, so Monday is the first business day")
+	require.Equal(t, time.Date(2024, 5, 3, 0, 0, 0, 0, time.UTC), calendar.BusinessDaysAfter(friday, 3),
+		"Monday, Tuesday and, skipping the holiday, Thursday")
+
+	calendar = BusinessCalendarConfig{WorkDays: []string{"sunday", "Monday"}, TimeZone: "America/New_York"}
+	location, err := time.LoadLocation("America/New_York")
+	require.NoError(t, err)
+	saturdayNight := time.Date(2024, 4, 27, 22, 0, 0, 0, location)
+	require.Equal(t, time.Date(2024, 4, 29, 0, 0, 0, 0, location), calendar.BusinessDaysAfter(saturdayNight, 1),
+		"days start in the calendar's time zone")
+}
+
+func TestBusinessCalendarConfig_Validate(t *testing.T) {
+	require.NoError(t, (&BusinessCalendarConfig{}).Validate())
+	require.NoError(t, (&BusinessCalendarConfig{WorkDays: []string{"monday"}, Holidays: []string{"2024-12-25"}, TimeZone: "Europe/Berlin"}).Validate())
+	require.Error(t, (&BusinessCalendarConfig{WorkDays: []string{"Funday"}}).Validate())
+	require.Error(t, (&BusinessCalendarConfig{Holidays: []string{"25/12/2024"}}).Validate())
+	require.Error(t, (&BusinessCalendarConfig{TimeZone: "Mars/Olympus"}).Validate())
+}
+
+func TestCleanupConfig_EffectiveBatchSize(t *testing.T) {
+	cfg := CleanupConfig{}
+	require.Equal(t, 10, cfg.EffectiveBatchSize())
+
+	cfg.BatchSize = 50
+	cfg.Constraints.MaxBatchSize = 20
+	require.Equal(t, 20, cfg.EffectiveBatchSize())
+}
+
+func TestDuration_UnmarshalYAML(t *testing.T) {
+	type durationWrapper struct {
+		TTL Duration `yaml:"ttl"`
+	}
+
+	yamlStr := "ttl: 1h30m"
+	var wrapper durationWrapper
+	err := yaml.Unmarshal([]byte(yamlStr), &wrapper)
+	require.NoError(t, err, "unmarshal should not return an error")
+
+	expectedDuration, _ := time.ParseDuration("1h30m")
+	require.Equal(t, expectedDuration, wrapper.TTL.Duration, "duration should match expected value")
+
+	yamlStr = "ttl: 1pk"
+	err = yaml.Unmarshal([]byte(yamlStr), &wrapper)
+	require.Error(t, err, "unmarshal should throw an error")
+
+	yamlStr = "ttl: [123]"
+	err = yaml.Unmarshal([]byte(yamlStr), &wrapper)
+	require.Error(t, err, "unmarshal should throw an error")
+}
+
+func TestDuration_UnmarshalYAMLFormats(t *testing.T) {
+	tests := []struct {
+		value    string
+		expected time.Duration
+		errMsg   string
+	}{
+		{value: "90", expected: 90 * time.Second},
+		{value: "0", expected: 0},
+		{value: "90s", expected: 90 * time.Second},
+		{value: "2h45m", expected: 2*time.Hour + 45*time.Minute},
+		{value: "PT2H45M", expected: 2*time.Hour + 45*time.Minute},
+		{value: "P1D", expected: 24 * time.Hour},
+		{value: "P1W", expected: 7 * 24 * time.Hour},
+		{value: "P1DT12H", expected: 36 * time.Hour},
+		{value: "PT1.5S", expected: 1500 * time.Millisecond},
+		{value: `"90"`, errMsg: "ambiguous duration"},
+		{value: "1.5", errMsg: "ambiguous duration"},
+		{value: "P1M", errMsg: "years and months have no fixed length"},
+		{value: "P1Y2D", errMsg: "years and months have no fixed length"},
+		{value: "P", errMsg: "invalid ISO 8601 duration"},
+		{value: "PT", errMsg: "invalid ISO 8601 duration"},
+		{value: "P1H", errMsg: "invalid ISO 8601 duration"},
+		{value: "99999999999999999", errMsg: "too large"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			var wrapper struct {
+				TTL Duration `yaml:"ttl"`
+			}
+			err := yaml2.Unmarshal([]byte("ttl: "+tt.value), &wrapper)
+			if tt.errMsg != "" {
+				require.ErrorContains(t, err, tt.errMsg)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.expected, wrapper.TTL.Duration)
+		})
+	}
+}
+func TestPodCleanupConfig_Validate(t *testing.T) {
+	validRule := PodCleanRule{
+		Name:    "test-rule",
+		Enabled: true,
+		TTL:     Duration{Duration: time.Hour},
+		Phase:   "Succeeded",
+	}
+
+	tests := []struct {
+		name      string
+		config    PodCleanupConfig
+		expectErr bool
+	}{
+		{
+			name: "disabled config",
+			config: PodCleanupConfig{
+				Enabled: false,
+			},
+			expectErr: false,
+		},
+		{
+			name: "valid rule",
+			config: PodCleanupConfig{
+				Enabled: true,
+				Rules:   []PodCleanRule{validRule},
+			},
+			expectErr: false,
+		},
+		{
+			name: "first match rule policy",
+			config: PodCleanupConfig{
+				Enabled:    true,
+				RulePolicy: RulePolicyFirstMatch,
+				Rules:      []PodCleanRule{validRule},
+			},
+			expectErr: false,
+		},
+		{
+			name: "unknown rule policy",
+			config: PodCleanupConfig{
+				Enabled:    true,
+				RulePolicy: "bestMatch",
+				Rules:      []PodCleanRule{validRule},
+			},
+			expectErr: true,
+		},
+		{
+			name: "quota pressure halving TTLs",
+			config: PodCleanupConfig{
+				Enabled:       true,
+				Rules:         []PodCleanRule{validRule},
+				QuotaPressure: QuotaPressureConfig{Enabled: true, ThresholdPercent: 80, TTLPercent: 50},
+			},
+			expectErr: false,
+		},
+		{
+			name: "quota pressure ttlPercent above 100",
+			config: PodCleanupConfig{
+				Enabled:       true,
+				Rules:         []PodCleanRule{validRule},
+				QuotaPressure: QuotaPressureConfig{Enabled: true, TTLPercent: 150},
+			},
+			expectErr: true,
+		},
+		{
+			name: "node pressure on disk pressure",
+			config: PodCleanupConfig{
+				Enabled:      true,
+				Rules:        []PodCleanRule{validRule},
+				NodePressure: NodePressureConfig{Enabled: true, DiskPressure: true},
+			},
+			expectErr: false,
+		},
+		{
+			name: "node pressure without a trigger",
+			config: PodCleanupConfig{
+				Enabled:      true,
+				Rules:        []PodCleanRule{validRule},
+				NodePressure: NodePressureConfig{Enabled: true},
+			},
+			expectErr: true,
+		},
+		{
+			name: "quota pressure negative triggerCooldown",
+			config: PodCleanupConfig{
+				Enabled:       true,
+				Rules:         []PodCleanRule{validRule},
+				QuotaPressure: QuotaPressureConfig{Enabled: true, TriggerOnEvents: true, TriggerCooldown: Duration{Duration: -time.Minute}},
+			},
+			expectErr: true,
+		},
+		{
+			name: "invalid rule inside config",
+			config: PodCleanupConfig{
+				Enabled: true,
+				Rules: []PodCleanRule{
+					{
+						Name:    "",
+						Enabled: true,
+						TTL:     Duration{Duration: time.Hour},
+						Phase:   "",
+					},
+				},
+			},
+			expectErr: true,
+		},
 	}
 
 	for _, tt := range tests {
@@ -61,134 +983,383 @@ func TestCleanupConfig_Validate(t *testing.T) {
 	}
 }
 
-func TestDuration_UnmarshalYAML(t *testing.T) {
-	type durationWrapper struct {
-		TTL Duration `yaml:"ttl"`
+func TestPodCleanupConfig_ValidateRuleOrder(t *testing.T) {
+	rule := func(name string, after ...string) PodCleanRule {
+		return PodCleanRule{Name: name, Enabled: true, Phase: "Succeeded", TTL: Duration{Duration: time.Hour}, After: after}
 	}
 
-	yamlStr := "ttl: 1h30m"
-	var wrapper durationWrapper
-	err := yaml.Unmarshal([]byte(yamlStr), &wrapper)
-	require.NoError(t, err, "unmarshal should not return an error")
-
-	expectedDuration, _ := time.ParseDuration("1h30m")
-	require.Equal(t, expectedDuration, wrapper.TTL.Duration, "duration should match expected value")
-
-	yamlStr = "ttl: 1pk"
-	err = yaml.Unmarshal([]byte(yamlStr), &wrapper)
-	require.Error(t, err, "unmarshal should throw an error")
+	tests := []struct {
+		name  string
+		rules []PodCleanRule
+		err   string
+	}{
+		{name: "chain", rules: []PodCleanRule{rule("jobs", "pods"), rule("pods"), rule("evicted", "pods", "jobs")}},
+		{name: "after a recommended rule", rules: []PodCleanRule{rule("jobs", RecommendedRuleSucceeded)}},
+		{name: "unknown rule", rules: []PodCleanRule{rule("jobs", "pods")}, err: `rule "jobs" runs after unknown rule "pods"`},
+		{name: "itself", rules: []PodCleanRule{rule("jobs", "jobs")}, err: `rule "jobs" cannot run after itself`},
+		{
+			name:  "cycle",
+			rules: []PodCleanRule{rule("a", "c"), rule("b", "a"), rule("c", "b")},
+			err:   "rules depend on each other through 'after': a -> c -> b -> a",
+		},
+	}
 
-	yamlStr = "ttl: [123]"
-	err = yaml.Unmarshal([]byte(yamlStr), &wrapper)
-	require.Error(t, err, "unmarshal should throw an error")
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := PodCleanupConfig{Enabled: true, UseRecommendedDefaults: true, Rules: tt.rules}
+			err := config.Validate()
+			if tt.err == "" {
+				require.NoError(t, err)
+			} else {
+				require.ErrorContains(t, err, tt.err)
+			}
+		})
+	}
 }
-func TestPodCleanupConfig_Validate(t *testing.T) {
-	validRule := PodCleanRule{
-		Name:    "test-rule",
+
+func TestPodCleanupConfig_EffectiveRules(t *testing.T) {
+	config := PodCleanupConfig{
 		Enabled: true,
-		TTL:     Duration{Duration: time.Hour},
-		Phase:   "Succeeded",
+		Rules: []PodCleanRule{
+			{Name: "jobs", Enabled: true, Phase: "Succeeded", TTL: Duration{Duration: time.Hour}},
+			{Name: RecommendedRuleFailed, Enabled: true, Phase: "Failed", TTL: Duration{Duration: 24 * time.Hour}},
+		},
+	}
+	require.Len(t, config.EffectiveRules(), 2, "recommended rules are opt-in")
+
+	config.UseRecommendedDefaults = true
+	require.NoError(t, config.Validate())
+
+	var names []string
+	for _, rule := range config.EffectiveRules() {
+		names = append(names, rule.Name)
+		if rule.Name == RecommendedRuleFailed {
+			require.Equal(t, 24*time.Hour, rule.TTL.Duration, "a configured rule replaces the recommended one of the same name")
+		}
 	}
+	require.Equal(t, []string{"jobs", RecommendedRuleFailed, RecommendedRuleSucceeded, RecommendedRuleEvicted}, names)
+}
+
+func TestRuleCooldownConfig(t *testing.T) {
+	cfg := RuleCooldownConfig{Enabled: true}
+	require.NoError(t, cfg.Validate())
+	require.Equal(t, DefaultCooldownRuns, cfg.CooldownRuns())
+	require.False(t, cfg.Trips(4, 4), "runs below minAttempts are not judged")
+	require.True(t, cfg.Trips(10, 5))
+	require.False(t, cfg.Trips(10, 4))
 
+	cfg.FailureRatio = 1
+	require.False(t, cfg.Trips(10, 9))
+	require.True(t, cfg.Trips(10, 10))
+
+	require.ErrorContains(t, (&RuleCooldownConfig{Enabled: true, FailureRatio: 1.5}).Validate(), "failureRatio must be between 0 and 1")
+	require.ErrorContains(t, (&RuleCooldownConfig{Enabled: true, Runs: -1}).Validate(), "runs cannot be negative")
+	require.False(t, (&RuleCooldownConfig{}).Trips(10, 10), "disabled cooldowns never trip")
+}
+
+func TestPodCleanRule_Validate(t *testing.T) {
 	tests := []struct {
 		name      string
-		config    PodCleanupConfig
+		rule      PodCleanRule
 		expectErr bool
 	}{
 		{
-			name: "disabled config",
-			config: PodCleanupConfig{
+			name: "disabled rule",
+			rule: PodCleanRule{
 				Enabled: false,
 			},
-			expectErr: false,
+			expectErr: false,
+		},
+		{
+			name: "missing rule name",
+			rule: PodCleanRule{
+				Enabled: true,
+				TTL:     Duration{Duration: time.Hour},
+				Phase:   "Failed",
+			},
+			expectErr: true,
+		},
+		{
+			name: "invalid TTL",
+			rule: PodCleanRule{
+				Name:    "invalid-ttl",
+				Enabled: true,
+				TTL:     Duration{Duration: 0},
+				Phase:   "Failed",
+			},
+			expectErr: true,
+		},
+		{
+			name: "unknown phase",
+			rule: PodCleanRule{
+				Name:    "completed",
+				Enabled: true,
+				TTL:     Duration{Duration: time.Hour},
+				Phase:   "Completed",
+			},
+			expectErr: true,
+		},
+		{
+			name: "business days in place of TTL",
+			rule: PodCleanRule{
+				Name:            "business-days",
+				Enabled:         true,
+				TTLBusinessDays: 3,
+				Phase:           "Failed",
+			},
+			expectErr: false,
+		},
+		{
+			name: "business days combined with TTL",
+			rule: PodCleanRule{
+				Name:            "business-days-and-ttl",
+				Enabled:         true,
+				TTL:             Duration{Duration: time.Hour},
+				TTLBusinessDays: 3,
+				Phase:           "Failed",
+			},
+			expectErr: true,
+		},
+		{
+			name: "negative business days",
+			rule: PodCleanRule{
+				Name:            "negative-business-days",
+				Enabled:         true,
+				TTLBusinessDays: -1,
+				Phase:           "Failed",
+			},
+			expectErr: true,
+		},
+		{
+			name: "negative minAvailable",
+			rule: PodCleanRule{
+				Name:         "negative-min-available",
+				Enabled:      true,
+				TTL:          Duration{Duration: time.Hour},
+				Phase:        "Running",
+				MinAvailable: -1,
+			},
+			expectErr: true,
+		},
+		{
+			name: "negative maxPerNode",
+			rule: PodCleanRule{
+				Name:       "negative-max-per-node",
+				Enabled:    true,
+				TTL:        Duration{Duration: time.Hour},
+				Phase:      "Succeeded",
+				MaxPerNode: -1,
+			},
+			expectErr: true,
+		},
+		{
+			name: "missing selector and phase",
+			rule: PodCleanRule{
+				Name:    "missing-selector-phase",
+				Enabled: true,
+				TTL:     Duration{Duration: time.Hour},
+			},
+			expectErr: true,
+		},
+		{
+			name: "valid rule with phase",
+			rule: PodCleanRule{
+				Name:    "valid",
+				Enabled: true,
+				TTL:     Duration{Duration: time.Hour},
+				Phase:   "Succeeded",
+			},
+			expectErr: false,
+		},
+		{
+			name: "invalid node selector",
+			rule: PodCleanRule{
+				Name:    "invalid-node-selector",
+				Enabled: true,
+				TTL:     Duration{Duration: time.Hour},
+				Phase:   "Succeeded",
+				NodeSelector: &metav1.LabelSelector{
+					MatchExpressions: []metav1.LabelSelectorRequirement{{Key: "pool", Operator: "Bogus"}},
+				},
+			},
+			expectErr: true,
+		},
+		{
+			name: "valid rule with match criteria",
+			rule: PodCleanRule{
+				Name:    "valid-match",
+				Enabled: true,
+				TTL:     Duration{Duration: time.Hour},
+				Match: &MatchCriteria{
+					Any: []MatchCondition{{Phase: "Failed"}, {Reason: "Evicted"}},
+				},
+			},
+			expectErr: false,
+		},
+		{
+			name: "phase combined with match",
+			rule: PodCleanRule{
+				Name:    "phase-and-match",
+				Enabled: true,
+				TTL:     Duration{Duration: time.Hour},
+				Phase:   "Failed",
+				Match:   &MatchCriteria{Any: []MatchCondition{{Reason: "Evicted"}}},
+			},
+			expectErr: true,
+		},
+		{
+			name: "empty match criteria",
+			rule: PodCleanRule{
+				Name:    "empty-match",
+				Enabled: true,
+				TTL:     Duration{Duration: time.Hour},
+				Match:   &MatchCriteria{},
+			},
+			expectErr: true,
+		},
+		{
+			name: "empty match condition",
+			rule: PodCleanRule{
+				Name:    "empty-condition",
+				Enabled: true,
+				TTL:     Duration{Duration: time.Hour},
+				Match:   &MatchCriteria{All: []MatchCondition{{}}},
+			},
+			expectErr: true,
+		},
+		{
+			name: "unknown phase in match condition",
+			rule: PodCleanRule{
+				Name:    "unknown-phase",
+				Enabled: true,
+				TTL:     Duration{Duration: time.Hour},
+				Match:   &MatchCriteria{All: []MatchCondition{{Phase: "Done"}}},
+			},
+			expectErr: true,
+		},
+		{
+			name: "invalid exclude selector",
+			rule: PodCleanRule{
+				Name:    "invalid-exclude-selector",
+				Enabled: true,
+				TTL:     Duration{Duration: time.Hour},
+				Phase:   "Succeeded",
+				ExcludeSelector: &metav1.LabelSelector{
+					MatchExpressions: []metav1.LabelSelectorRequirement{{Key: "tier", Operator: metav1.LabelSelectorOpIn}},
+				},
+			},
+			expectErr: true,
+		},
+		{
+			name: "namespace selector combined with namespaces",
+			rule: PodCleanRule{
+				Name:              "namespace-selector-and-list",
+				Enabled:           true,
+				TTL:               Duration{Duration: time.Hour},
+				Phase:             "Succeeded",
+				Namespaces:        []string{"default"},
+				NamespaceSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"env": "ephemeral"}},
+			},
+			expectErr: true,
+		},
+		{
+			name: "invalid cordoned nodes mode",
+			rule: PodCleanRule{
+				Name:          "invalid-cordoned-mode",
+				Enabled:       true,
+				TTL:           Duration{Duration: time.Hour},
+				Phase:         "Succeeded",
+				CordonedNodes: "sometimes",
+			},
+			expectErr: true,
+		},
+		{
+			name: "invalid annotation policy",
+			rule: PodCleanRule{
+				Name:                    "invalid-annotation-policy",
+				Enabled:                 true,
+				TTL:                     Duration{Duration: time.Hour},
+				Phase:                   "Succeeded",
+				InvalidAnnotationPolicy: "ignore",
+			},
+			expectErr: true,
 		},
 		{
-			name: "valid rule",
-			config: PodCleanupConfig{
-				Enabled: true,
-				Rules:   []PodCleanRule{validRule},
+			name: "node deleted as only criterion",
+			rule: PodCleanRule{
+				Name:        "node-deleted",
+				Enabled:     true,
+				TTL:         Duration{Duration: 5 * time.Minute},
+				NodeDeleted: true,
 			},
 			expectErr: false,
 		},
 		{
-			name: "invalid rule inside config",
-			config: PodCleanupConfig{
-				Enabled: true,
-				Rules: []PodCleanRule{
-					{
-						Name:    "",
-						Enabled: true,
-						TTL:     Duration{Duration: time.Hour},
-						Phase:   "",
-					},
-				},
+			name: "node deleted combined with zones",
+			rule: PodCleanRule{
+				Name:        "node-deleted",
+				Enabled:     true,
+				TTL:         Duration{Duration: 5 * time.Minute},
+				NodeDeleted: true,
+				Zones:       []string{"eu-west-1a"},
 			},
 			expectErr: true,
 		},
-	}
-
-	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
-			err := tt.config.Validate()
-			if tt.expectErr {
-				require.Error(t, err)
-			} else {
-				require.NoError(t, err)
-			}
-		})
-	}
-}
-
-func TestPodCleanRule_Validate(t *testing.T) {
-	tests := []struct {
-		name      string
-		rule      PodCleanRule
-		expectErr bool
-	}{
 		{
-			name: "disabled rule",
+			name: "quarantine action with labels",
 			rule: PodCleanRule{
-				Enabled: false,
+				Name:    "quarantine",
+				Enabled: true,
+				TTL:     Duration{Duration: time.Hour},
+				Phase:   "Failed",
+				Action:  ActionConfig{Type: ActionLabelQuarantine, Labels: map[string]string{"quarantine": "crashloop"}},
 			},
 			expectErr: false,
 		},
 		{
-			name: "missing rule name",
+			name: "unknown action",
 			rule: PodCleanRule{
+				Name:    "unknown-action",
 				Enabled: true,
 				TTL:     Duration{Duration: time.Hour},
 				Phase:   "Failed",
+				Action:  ActionConfig{Type: "shred"},
 			},
 			expectErr: true,
 		},
 		{
-			name: "invalid TTL",
+			name: "annotate action without annotations",
 			rule: PodCleanRule{
-				Name:    "invalid-ttl",
+				Name:    "annotate",
 				Enabled: true,
-				TTL:     Duration{Duration: 0},
+				TTL:     Duration{Duration: time.Hour},
 				Phase:   "Failed",
+				Action:  ActionConfig{Type: ActionAnnotatePatch},
 			},
 			expectErr: true,
 		},
 		{
-			name: "missing selector and phase",
+			name: "labels on the delete action",
 			rule: PodCleanRule{
-				Name:    "missing-selector-phase",
+				Name:    "delete-labels",
 				Enabled: true,
 				TTL:     Duration{Duration: time.Hour},
+				Phase:   "Failed",
+				Action:  ActionConfig{Labels: map[string]string{"quarantine": "true"}},
 			},
 			expectErr: true,
 		},
 		{
-			name: "valid rule with phase",
+			name: "delete owner when empty with evict action",
 			rule: PodCleanRule{
-				Name:    "valid",
-				Enabled: true,
-				TTL:     Duration{Duration: time.Hour},
-				Phase:   "Succeeded",
+				Name:                 "evict-owner",
+				Enabled:              true,
+				TTL:                  Duration{Duration: time.Hour},
+				Phase:                "Succeeded",
+				DeleteOwnerWhenEmpty: true,
+				Action:               ActionConfig{Type: ActionEvict},
 			},
-			expectErr: false,
+			expectErr: true,
 		},
 		{
 			name: "valid rule with selector",
@@ -245,6 +1416,133 @@ podCleanupConfig:
 	require.Equal(t, cfg.PodCleanupConfig.Rules[0].TTL.Duration, time.Hour)
 }
 
+func TestYAMLUnmarshal_Selectors(t *testing.T) {
+	yamlConfig := `
+podCleanupConfig:
+  enabled: true
+  rules:
+    - name: spot-nodes
+      enabled: true
+      ttl: "1h"
+      selector:
+        matchLabels:
+          app: batch
+      nodeSelector:
+        matchExpressions:
+          - key: node-pool
+            operator: In
+            values: [spot, batch]
+      nodeNames: [node-a]
+      namespaceSelector:
+        matchLabels:
+          env: ephemeral
+      excludeSelector:
+        matchLabels:
+          tier: gold
+`
+
+	cfg, err := LoadConfig([]byte(yamlConfig))
+	require.NoError(t, err)
+
+	rule := cfg.PodCleanupConfig.Rules[0]
+	require.Equal(t, map[string]string{"app": "batch"}, rule.Selector.MatchLabels)
+	require.NotNil(t, rule.NodeSelector)
+	require.Len(t, rule.NodeSelector.MatchExpressions, 1)
+	require.Equal(t, metav1.LabelSelectorOpIn, rule.NodeSelector.MatchExpressions[0].Operator)
+	require.Equal(t, []string{"spot", "batch"}, rule.NodeSelector.MatchExpressions[0].Values)
+	require.Equal(t, []string{"node-a"}, rule.NodeNames)
+	require.Equal(t, map[string]string{"tier": "gold"}, rule.ExcludeSelector.MatchLabels)
+	require.Equal(t, map[string]string{"env": "ephemeral"}, rule.NamespaceSelector.MatchLabels)
+	require.True(t, rule.IsNodeScoped())
+}
+
+func TestYAMLUnmarshal_IdleWorkloadSelector(t *testing.T) {
+	yamlConfig := `
+idleWorkloadConfig:
+  enabled: true
+  rules:
+    - name: idle-previews
+      enabled: true
+      kind: Deployment
+      condition: noTraffic
+      ttl: "8h"
+      selector:
+        matchLabels:
+          env: preview
+`
+
+	cfg, err := LoadConfig([]byte(yamlConfig))
+	require.NoError(t, err)
+
+	rule := cfg.IdleWorkloadConfig.Rules[0]
+	require.NotNil(t, rule.Selector)
+	require.Equal(t, map[string]string{"env": "preview"}, rule.Selector.MatchLabels)
+	require.Equal(t, 8*time.Hour, rule.TTL.Duration)
+}
+
+func TestYAMLUnmarshal_GenericRule(t *testing.T) {
+	yamlConfig := `
+genericCleanupConfig:
+  enabled: true
+  rules:
+    - name: finished-workflows
+      enabled: true
+      apiVersion: argoproj.io/v1alpha1
+      kind: Workflow
+      ttl: "24h"
+      timestampPath: .status.finishedAt
+      condition:
+        path: .status.phase
+        values: [Succeeded, Failed]
+      selector:
+        matchLabels:
+          team: data
+`
+
+	cfg, err := LoadConfig([]byte(yamlConfig))
+	require.NoError(t, err)
+
+	rule := cfg.GenericCleanupConfig.Rules[0]
+	require.Equal(t, "argoproj.io", rule.GroupVersionKind().Group)
+	require.Equal(t, ".status.finishedAt", rule.TimestampPath)
+	require.Equal(t, []string{"Succeeded", "Failed"}, rule.Condition.Values)
+	require.NotNil(t, rule.Selector)
+	require.Equal(t, map[string]string{"team": "data"}, rule.Selector.MatchLabels)
+}
+
+func TestYAMLUnmarshal_MatchCriteria(t *testing.T) {
+	yamlConfig := `
+podCleanupConfig:
+  enabled: true
+  rules:
+    - name: failed-or-evicted-batch
+      enabled: true
+      ttl: "1h"
+      match:
+        all:
+          - selector:
+              matchLabels:
+                app: batch
+        any:
+          - phase: Failed
+          - reason: Evicted
+            annotations:
+              team: ""
+`
+
+	cfg, err := LoadConfig([]byte(yamlConfig))
+	require.NoError(t, err)
+
+	match := cfg.PodCleanupConfig.Rules[0].Match
+	require.NotNil(t, match)
+	require.Len(t, match.All, 1)
+	require.Equal(t, map[string]string{"app": "batch"}, match.All[0].Selector.MatchLabels)
+	require.Len(t, match.Any, 2)
+	require.Equal(t, "Failed", match.Any[0].Phase)
+	require.Equal(t, "Evicted", match.Any[1].Reason)
+	require.Contains(t, match.Any[1].Annotations, "team")
+}
+
 func TestYAMLUnmarshal_EmptyConfig(t *testing.T) {
 	yamlConfig := `
 dryRun: true
@@ -412,7 +1710,7 @@ podCleanupConfig:
 
 	ticker := time.NewTicker(100 * time.Millisecond)
 
-	go WatchConfig(ctx, filePath, currentConfig, ticker)
+	go WatchConfig(ctx, filePath, currentConfig, ticker, nil)
 
 	// Give watcher some time to start
 	time.Sleep(150 * time.Millisecond)
@@ -447,3 +1745,408 @@ podCleanupConfig:
 	require.Equal(t, currentConfig, validConfig)
 
 }
+
+func Test_WatchConfig_ReportsStaleness(t *testing.T) {
+	filePath := writeTempConfig(t, "dryRun: true\n")
+	defer deleteTempFile(t, filePath)
+
+	modified := time.Now().Add(-time.Hour)
+	require.NoError(t, os.Chtimes(filePath, modified, modified))
+
+	currentConfig, err := LoadConfigFromFile(filePath)
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	go WatchConfig(ctx, filePath, currentConfig, time.NewTicker(50*time.Millisecond), nil)
+	time.Sleep(150 * time.Millisecond)
+
+	require.InDelta(t, time.Hour.Seconds(), testutil.ToFloat64(configAgeSeconds), 5)
+	require.Less(t, testutil.ToFloat64(configReloadAgeSeconds), 5.0)
+	require.Zero(t, testutil.ToFloat64(configStale))
+
+	// A file that keeps failing to reload leaves the old config active and is reported as stale.
+	require.NoError(t, os.WriteFile(filePath, []byte("batchSize: -1\n"), 0644))
+	time.Sleep(150 * time.Millisecond)
+
+	require.True(t, currentConfig.DryRun)
+	require.Equal(t, 1.0, testutil.ToFloat64(configStale))
+	require.InDelta(t, time.Hour.Seconds(), testutil.ToFloat64(configAgeSeconds), 5)
+
+	require.NoError(t, os.WriteFile(filePath, []byte("dryRun: false\n"), 0644))
+	time.Sleep(150 * time.Millisecond)
+
+	require.False(t, currentConfig.DryRun)
+	require.Zero(t, testutil.ToFloat64(configStale))
+	require.Less(t, testutil.ToFloat64(configAgeSeconds), 5.0)
+}
+
+// holdingGuard holds back every reload until approved is set.
+type holdingGuard struct {
+	held atomic.Bool
+}
+
+func (g *holdingGuard) AllowReload(context.Context, *CleanupConfig) bool {
+	g.held.Store(true)
+	return false
+}
+
+func (g *holdingGuard) ReloadHeld() bool {
+	return g.held.Load()
+}
+
+func Test_WatchConfig_HoldsBackGuardedReloads(t *testing.T) {
+	filePath := writeTempConfig(t, "dryRun: true\n")
+	defer deleteTempFile(t, filePath)
+
+	currentConfig, err := LoadConfigFromFile(filePath)
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	guard := &holdingGuard{}
+	go WatchConfig(ctx, filePath, currentConfig, time.NewTicker(50*time.Millisecond), guard)
+	time.Sleep(100 * time.Millisecond)
+
+	require.NoError(t, os.WriteFile(filePath, []byte("dryRun: false\n"), 0644))
+	time.Sleep(150 * time.Millisecond)
+
+	require.True(t, currentConfig.DryRun)
+	require.Equal(t, 1.0, testutil.ToFloat64(configStale))
+
+	// Approving applies the held config outside of the watcher, which then reports it current.
+	guard.held.Store(false)
+	time.Sleep(150 * time.Millisecond)
+
+	require.Zero(t, testutil.ToFloat64(configStale))
+	require.Less(t, testutil.ToFloat64(configReloadAgeSeconds), 1.0)
+}
+
+func Test_LoadConfig_MigratesUnversionedConfig(t *testing.T) {
+	cfg, err := LoadConfig([]byte("batchSize: 5\n"))
+	require.NoError(t, err)
+	require.Equal(t, CurrentAPIVersion, cfg.APIVersion)
+	require.Equal(t, 5, cfg.BatchSize)
+
+	cfg, err = LoadConfig([]byte("apiVersion: kubeclean/v1\nbatchSize: 7\n"))
+	require.NoError(t, err)
+	require.Equal(t, CurrentAPIVersion, cfg.APIVersion)
+	require.Equal(t, 7, cfg.BatchSize)
+}
+
+func Test_LoadConfig_ExpandsVariables(t *testing.T) {
+	t.Setenv("CLUSTER", "prod-eu")
+	t.Setenv("POD_NAMESPACE", "kubeclean")
+	RegisterVariableSource("test", func(name string) (string, bool) { return strings.ToUpper(name), name != "" })
+
+	cfg, err := LoadConfig([]byte(`
+podCleanupConfig:
+  enabled: true
+  rules:
+    - name: ${CLUSTER}-failed
+      enabled: true
+      phase: Failed
+      ttl: 1h
+      namespaces: ["${pod:namespace}", "team-${test:a}", "$${CLUSTER}"]
+`))
+	require.NoError(t, err)
+	rule := cfg.PodCleanupConfig.Rules[0]
+	require.Equal(t, "prod-eu-failed", rule.Name)
+	require.Equal(t, []string{"kubeclean", "team-A", "${CLUSTER}"}, rule.Namespaces)
+
+	_, err = LoadConfig([]byte("podCleanupConfig:\n  rules:\n    - name: ${UNDEFINED_CLUSTER}\n"))
+	require.ErrorContains(t, err, "podCleanupConfig.rules[0].name: undefined variable ${UNDEFINED_CLUSTER}")
+
+	_, err = LoadConfig([]byte("runEvents:\n  deployment: ${secret:token}\n"))
+	require.ErrorContains(t, err, `unknown variable source "secret"`)
+
+	_, err = LoadConfig([]byte("runEvents:\n  deployment: ${CLUSTER\n"))
+	require.ErrorContains(t, err, "unterminated variable reference")
+}
+
+func Test_LoadConfig_MergesClusterOverlays(t *testing.T) {
+	config := func(cluster string) string {
+		return `
+clusterName: ` + cluster + `
+dryRun: true
+podCleanupConfig:
+  enabled: true
+  rules:
+    - name: failed
+      enabled: true
+      phase: Failed
+      ttl: 1h
+      namespaces: [default]
+    - name: succeeded
+      enabled: true
+      phase: Succeeded
+      ttl: 1h
+clusterOverlays:
+  - clusters: [prod-eu, prod-us]
+    config:
+      dryRun: false
+      podCleanupConfig:
+        rules:
+          - name: failed
+            ttl: 72h
+          - name: unknown
+            enabled: true
+            phase: Unknown
+            ttl: 1h
+  - clusters: [prod-us]
+    config:
+      podCleanupConfig:
+        rules:
+          - name: failed
+            namespaces: [default, batch]
+`
+	}
+
+	cfg, err := LoadConfig([]byte(config("staging")))
+	require.NoError(t, err)
+	require.True(t, cfg.DryRun)
+	require.Len(t, cfg.PodCleanupConfig.Rules, 2)
+
+	cfg, err = LoadConfig([]byte(config("prod-us")))
+	require.NoError(t, err)
+	require.Equal(t, "prod-us", cfg.ClusterName)
+	require.False(t, cfg.DryRun)
+	require.Len(t, cfg.PodCleanupConfig.Rules, 3)
+	failed := cfg.PodCleanupConfig.Rules[0]
+	require.Equal(t, "failed", failed.Name)
+	require.Equal(t, "Failed", failed.Phase)
+	require.Equal(t, 72*time.Hour, failed.TTL.Duration)
+	require.Equal(t, []string{"default", "batch"}, failed.Namespaces)
+	require.Equal(t, "unknown", cfg.PodCleanupConfig.Rules[2].Name)
+
+	// An overlay that breaks another cluster's config fails everywhere.
+	_, err = LoadConfig([]byte(config("staging") + `
+  - clusters: [prod-eu]
+    config:
+      batchSize: -1
+`))
+	require.ErrorContains(t, err, `config of cluster "prod-eu"`)
+
+	_, err = LoadConfig([]byte("clusterOverlays:\n  - config: {dryRun: true}\n"))
+	require.ErrorContains(t, err, "clusters must be set")
+}
+
+func Test_LoadConfig_UnsupportedAPIVersion(t *testing.T) {
+	_, err := LoadConfig([]byte("apiVersion: kubeclean/v9\n"))
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "invalid config")
+	require.Contains(t, err.Error(), `unsupported apiVersion "kubeclean/v9"`)
+}
+
+func Test_ApplyMigrations_Chain(t *testing.T) {
+	steps := []migration{
+		{from: "", to: "kubeclean/v1", apply: func(doc yaml2.MapSlice) (yaml2.MapSlice, error) { return doc, nil }},
+		{from: "kubeclean/v1", to: "kubeclean/v2", apply: func(doc yaml2.MapSlice) (yaml2.MapSlice, error) {
+			// Example breaking change: rename 'batchSize' to 'batch'.
+			for i, item := range doc {
+				if item.Key == "batchSize" {
+					doc[i].Key = "batch"
+				}
+			}
+			return doc, nil
+		}},
+	}
+
+	doc := yaml2.MapSlice{{Key: "batchSize", Value: 3}}
+	migrated, err := applyMigrations(doc, steps, "kubeclean/v2")
+	require.NoError(t, err)
+	require.Equal(t, yaml2.MapSlice{{Key: "apiVersion", Value: "kubeclean/v2"}, {Key: "batch", Value: 3}}, migrated)
+}
+
+// configServer serves a config document with an ETag and, if key is set, its signature.
+type configServer struct {
+	mu          sync.Mutex
+	config      string
+	key         ed25519.PrivateKey
+	notModified int
+}
+
+func (s *configServer) set(config string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.config = config
+}
+
+func (s *configServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if r.URL.Path == "/config.yaml.sig" {
+		fmt.Fprintln(w, base64.StdEncoding.EncodeToString(ed25519.Sign(s.key, []byte(s.config))))
+		return
+	}
+	etag := fmt.Sprintf("%q", fmt.Sprintf("%x", sha256.Sum256([]byte(s.config))))
+	if r.Header.Get("If-None-Match") == etag {
+		s.notModified++
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+	w.Header().Set("ETag", etag)
+	fmt.Fprint(w, s.config)
+}
+
+func Test_WatchConfigURL_ReloadsOnChange(t *testing.T) {
+	server := &configServer{config: "dryRun: true\n"}
+	ts := httptest.NewTLSServer(server)
+	defer ts.Close()
+
+	source, err := NewURLSource(ts.URL+"/config.yaml", nil)
+	require.NoError(t, err)
+	source.Client = ts.Client()
+
+	currentConfig, err := LoadConfigFromURL(context.Background(), source)
+	require.NoError(t, err)
+	require.True(t, currentConfig.DryRun)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	go WatchConfigURL(ctx, source, currentConfig, time.NewTicker(50*time.Millisecond), nil)
+	time.Sleep(150 * time.Millisecond)
+
+	server.mu.Lock()
+	require.NotZero(t, server.notModified, "unchanged config should be answered with 304 Not Modified")
+	server.mu.Unlock()
+
+	server.set("dryRun: false\n")
+	time.Sleep(150 * time.Millisecond)
+	require.False(t, currentConfig.DryRun)
+}
+
+func Test_LoadConfigFromURL_VerifiesSignature(t *testing.T) {
+	publicKey, privateKey, err := ed25519.GenerateKey(nil)
+	require.NoError(t, err)
+	_, otherKey, err := ed25519.GenerateKey(nil)
+	require.NoError(t, err)
+
+	server := &configServer{config: "dryRun: true\n", key: privateKey}
+	ts := httptest.NewTLSServer(server)
+	defer ts.Close()
+
+	source, err := NewURLSource(ts.URL+"/config.yaml", publicKey)
+	require.NoError(t, err)
+	source.Client = ts.Client()
+
+	config, err := LoadConfigFromURL(context.Background(), source)
+	require.NoError(t, err)
+	require.True(t, config.DryRun)
+
+	server.mu.Lock()
+	server.config, server.key = "dryRun: false\n", otherKey
+	server.mu.Unlock()
+
+	_, err = LoadConfigFromURL(context.Background(), source)
+	require.ErrorContains(t, err, "signature does not match")
+}
+
+func TestNewURLSource_RequiresHTTPS(t *testing.T) {
+	_, err := NewURLSource("http://config.example.com/config.yaml", nil)
+	require.ErrorContains(t, err, "must be an https URL")
+}
+
+func TestLoadPublicKey(t *testing.T) {
+	publicKey, _, err := ed25519.GenerateKey(nil)
+	require.NoError(t, err)
+	der, err := x509.MarshalPKIXPublicKey(publicKey)
+	require.NoError(t, err)
+
+	dir := t.TempDir()
+	pemPath := filepath.Join(dir, "key.pem")
+	require.NoError(t, os.WriteFile(pemPath, pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der}), 0644))
+	rawPath := filepath.Join(dir, "key.b64")
+	require.NoError(t, os.WriteFile(rawPath, []byte(base64.StdEncoding.EncodeToString(publicKey)+"\n"), 0644))
+
+	for _, path := range []string{pemPath, rawPath} {
+		key, err := LoadPublicKey(path)
+		require.NoError(t, err)
+		require.True(t, publicKey.Equal(key))
+	}
+}
+
+func TestParseTemplate_Funcs(t *testing.T) {
+	tests := map[string]string{
+		`{{toJson .Message}}`:                     `"say \"hi\""`,
+		`{{default "none" .Empty}}`:               "none",
+		`{{trunc 3 .Message}}`:                    "say",
+		`{{join "," .List}} {{lower "A"}}`:        "a,b a",
+		`{{replace " " "-" (trim " a b ")}}`:      "a-b",
+		`{{if contains "hi" .Message}}yes{{end}}`: "yes",
+	}
+	data := map[string]any{"Message": `say "hi"`, "Empty": "", "List": []string{"a", "b"}}
+
+	for text, want := range tests {
+		tmpl, err := ParseTemplate("test", text)
+		require.NoError(t, err, text)
+		var out strings.Builder
+		require.NoError(t, tmpl.Execute(&out, data), text)
+		require.Equal(t, want, out.String(), text)
+	}
+}
+
+func TestCircuitBreakerConfig(t *testing.T) {
+	cfg := CircuitBreakerConfig{Enabled: true}
+	require.NoError(t, cfg.Validate())
+	require.Equal(t, DefaultCircuitBreakerWindow, cfg.WindowDuration())
+	require.Equal(t, DefaultCircuitBreakerMinRequests, cfg.MinimumRequests())
+	require.Equal(t, DefaultCircuitBreakerMaxErrorRatio, cfg.ErrorRatio())
+	require.Equal(t, DefaultCircuitBreakerCooldown, cfg.CooldownDuration())
+
+	require.ErrorContains(t, (&CircuitBreakerConfig{Enabled: true, MaxErrorRatio: 2}).Validate(), "maxErrorRatio must be between 0 and 1")
+	require.ErrorContains(t, (&CircuitBreakerConfig{Enabled: true, Cooldown: Duration{Duration: -time.Second}}).Validate(), "cannot be negative")
+	require.NoError(t, (&CircuitBreakerConfig{MaxErrorRatio: 2}).Validate(), "disabled breakers are not validated")
+}
+
+func TestLanesConfig(t *testing.T) {
+	cfg := LanesConfig{}
+	require.NoError(t, cfg.Validate())
+	require.Equal(t, DefaultSlowLaneEvery, cfg.SlowLaneEvery())
+	require.ErrorContains(t, (&LanesConfig{SlowEvery: -1}).Validate(), "slowEvery cannot be negative")
+
+	rule := PodCleanRule{Name: "evicted", Enabled: true, Lane: LaneSlow, Phase: "Failed", TTL: Duration{Duration: time.Hour}}
+	require.NoError(t, rule.Validate())
+	rule.Lane = "medium"
+	require.ErrorContains(t, rule.Validate(), `lane must be one of "fast" or "slow"`)
+
+	orphan := OrphanCleanRule{Name: "hpa", Enabled: true, Lane: "medium", Kind: OrphanKindHorizontalPodAutoscaler, TTL: Duration{Duration: time.Hour}}
+	require.ErrorContains(t, orphan.Validate(), "lane must be one of")
+}
+
+func TestYAMLUnmarshal_SelectorsRejectUnknownKeys(t *testing.T) {
+	cases := map[string]string{
+		"lower-cased matchLabels": `
+      selector:
+        matchlabels:
+          app: batch`,
+		"unknown selector field": `
+      namespaceSelector:
+        matchNames: [team-a]`,
+		"misspelled expression field": `
+      nodeSelector:
+        matchExpressions:
+          - key: node-pool
+            operator: In
+            value: [spot]`,
+	}
+
+	for name, selector := range cases {
+		t.Run(name, func(t *testing.T) {
+			yamlConfig := `
+podCleanupConfig:
+  enabled: true
+  rules:
+    - name: batch
+      enabled: true
+      ttl: "1h"` + selector + "\n"
+
+			_, err := LoadConfig([]byte(yamlConfig))
+			require.ErrorContains(t, err, "unknown")
+		})
+	}
+}
@@ -10,10 +10,49 @@ import (
 	ctrl "sigs.k8s.io/controller-runtime"
 )
 
-// LoadConfig loads CleanupConfig from YAML bytes.
+// LoadConfig loads CleanupConfig from YAML bytes, expanding variables in its values, merging the
+// overlays of its cluster and migrating older schema versions first. The configs of the other
+// clusters its overlays name are validated too, so that a broken overlay fails on every cluster.
 func LoadConfig(data []byte) (*CleanupConfig, error) {
-	var config CleanupConfig
+	var doc yaml.MapSlice
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal config: %w", err)
+	}
+
+	if err := expandVariables(doc); err != nil {
+		return nil, fmt.Errorf("invalid config: %w", err)
+	}
+
+	cluster, overlays, doc, err := clusterOverlays(doc)
+	if err != nil {
+		return nil, fmt.Errorf("invalid config: %w", err)
+	}
+
+	config, err := decodeConfig(overlayFor(doc, overlays, cluster))
+	if err != nil {
+		return nil, err
+	}
+
+	for _, other := range overlaidClusters(overlays) {
+		if other == cluster {
+			continue
+		}
+		if _, err := decodeConfig(overlayFor(doc, overlays, other)); err != nil {
+			return nil, fmt.Errorf("config of cluster %q: %w", other, err)
+		}
+	}
+
+	return config, nil
+}
+
+// decodeConfig migrates, decodes and validates a config document.
+func decodeConfig(doc yaml.MapSlice) (*CleanupConfig, error) {
+	data, err := migrateConfig(doc)
+	if err != nil {
+		return nil, fmt.Errorf("invalid config: %w", err)
+	}
 
+	var config CleanupConfig
 	if err := yaml.Unmarshal(data, &config); err != nil {
 		return nil, fmt.Errorf("failed to unmarshal config: %w", err)
 	}
@@ -35,41 +74,89 @@ func LoadConfigFromFile(configPath string) (*CleanupConfig, error) {
 	return LoadConfig(data)
 }
 
-// WatchConfig watches for configuration changes and reloads config.
-func WatchConfig(ctx context.Context, configPath string, currentConfig *CleanupConfig, ticker *time.Ticker) {
-	var setupLog = ctrl.Log.WithName("WatchConfig")
+// ReloadGuard can hold back a changed config instead of letting WatchConfig apply it.
+type ReloadGuard interface {
+	// AllowReload reports whether candidate may replace the active config.
+	AllowReload(ctx context.Context, candidate *CleanupConfig) bool
+	// ReloadHeld reports whether the last candidate was held back and has not been applied since.
+	ReloadHeld() bool
+}
+
+// WatchConfig watches for configuration changes and reloads config, unless guard, if set, holds
+// the new config back. How current the active config is, is exported as the kubeclean_config_*
+// metrics, so that a reload failing on every tick can be alerted on.
+func WatchConfig(ctx context.Context, configPath string, currentConfig *CleanupConfig, ticker *time.Ticker, guard ReloadGuard) {
+	watchConfig(ctx, newFileSource(configPath), currentConfig, ticker, guard, &watchedConfig)
+}
+
+// WatchConfigURL polls source for configuration changes and reloads config like WatchConfig.
+// source must have loaded currentConfig through LoadConfigFromURL.
+func WatchConfigURL(ctx context.Context, source *URLSource, currentConfig *CleanupConfig, ticker *time.Ticker, guard ReloadGuard) {
+	watchConfig(ctx, source, currentConfig, ticker, guard, &watchedConfig)
+}
+
+// WatchShadowConfig watches for changes of a shadow config and reloads it like WatchConfig, but
+// leaves the kubeclean_config_* metrics to the active config.
+func WatchShadowConfig(ctx context.Context, configPath string, shadowConfig *CleanupConfig, ticker *time.Ticker) {
+	watchConfig(ctx, newFileSource(configPath), shadowConfig, ticker, nil, &configStatus{})
+}
+
+// watchConfig reloads currentConfig when source changes and records how current it is in status.
+func watchConfig(ctx context.Context, source configSource, currentConfig *CleanupConfig, ticker *time.Ticker, guard ReloadGuard, status *configStatus) {
+	var setupLog = ctrl.Log.WithName("WatchConfig").WithValues("source", source.String())
 
 	defer ticker.Stop()
 
-	var lastModTime time.Time
-	if stat, err := os.Stat(configPath); err == nil {
-		lastModTime = stat.ModTime()
-	}
+	_, lastModTime, _, _ := source.poll(ctx)
+	status.loaded(lastModTime, time.Now())
+
+	// held is set while the source's content is held back by guard.
+	held := false
 
 	for {
 		select {
 		case <-ctx.Done():
 			return
 		case <-ticker.C:
-			stat, err := os.Stat(configPath)
+			data, modTime, changed, err := source.poll(ctx)
 			if err != nil {
-				setupLog.Error(err, "Failed to stat config file", "path", configPath)
+				setupLog.Error(err, "Failed to check config for changes")
+				status.setStale(true)
 				continue
 			}
 
-			if stat.ModTime().After(lastModTime) {
-				setupLog.Info("Configuration file changed, reloading...", "path", configPath)
-
-				newConfig, err := LoadConfigFromFile(configPath)
-				if err != nil {
-					setupLog.Error(err, "Failed to reload config file", "path", configPath)
-					continue
+			if !changed {
+				if held && !guard.ReloadHeld() {
+					// The held config was approved and applied since.
+					held = false
+					status.loaded(lastModTime, time.Now())
 				}
+				status.setStale(held)
+				continue
+			}
+
+			setupLog.Info("Configuration changed, reloading...")
 
-				*currentConfig = *newConfig
-				lastModTime = stat.ModTime()
-				setupLog.Info("Configuration reloaded successfully", "path", configPath)
+			newConfig, err := LoadConfig(data)
+			if err != nil {
+				setupLog.Error(err, "Failed to reload config")
+				status.setStale(true)
+				continue
 			}
+
+			source.accept()
+			lastModTime = modTime
+			if guard != nil && !guard.AllowReload(ctx, newConfig) {
+				setupLog.Info("Configuration reload held back pending approval")
+				held = true
+				status.setStale(true)
+				continue
+			}
+
+			*currentConfig = *newConfig
+			held = false
+			status.loaded(lastModTime, time.Now())
+			setupLog.Info("Configuration reloaded successfully")
 		}
 	}
 }
@@ -0,0 +1,162 @@
+package cleanupconfig
+
+import (
+	"fmt"
+	"slices"
+
+	"gopkg.in/yaml.v2"
+)
+
+//
+// Cluster Overlays
+//
+
+// Top-level keys of a config document selecting and holding cluster overlays.
+const (
+	clusterNameKey     = "clusterName"
+	clusterOverlaysKey = "clusterOverlays"
+)
+
+// ClusterOverlay adds to or overrides parts of the config on the named clusters. Config is a
+// config document merged onto the shared config: mappings are merged key by key, lists of named
+// items, such as rules and sinks, are merged item by item by name, and anything else replaces the
+// shared value.
+type ClusterOverlay struct {
+	Clusters []string      `yaml:"clusters"` // Values of clusterName the overlay applies to.
+	Config   yaml.MapSlice `yaml:"config"`   // Partial config document merged onto the shared config.
+}
+
+// clusterOverlays returns the cluster name and the overlays of doc, without the overlays.
+func clusterOverlays(doc yaml.MapSlice) (string, []ClusterOverlay, yaml.MapSlice, error) {
+	var cluster string
+	var overlays []ClusterOverlay
+	var rest yaml.MapSlice
+
+	for _, item := range doc {
+		switch item.Key {
+		case clusterNameKey:
+			name, ok := item.Value.(string)
+			if !ok {
+				return "", nil, nil, fmt.Errorf("clusterName must be a string")
+			}
+			cluster = name
+		case clusterOverlaysKey:
+			data, err := yaml.Marshal(item.Value)
+			if err == nil {
+				err = yaml.Unmarshal(data, &overlays)
+			}
+			if err != nil {
+				return "", nil, nil, fmt.Errorf("clusterOverlays: %w", err)
+			}
+			continue
+		}
+		rest = append(rest, item)
+	}
+
+	for i, overlay := range overlays {
+		if len(overlay.Clusters) == 0 {
+			return "", nil, nil, fmt.Errorf("clusterOverlays[%d]: clusters must be set", i)
+		}
+		for _, item := range overlay.Config {
+			switch item.Key {
+			case "apiVersion", clusterNameKey, clusterOverlaysKey:
+				return "", nil, nil, fmt.Errorf("clusterOverlays[%d]: %v cannot be overlaid", i, item.Key)
+			}
+		}
+	}
+
+	return cluster, overlays, rest, nil
+}
+
+// overlayFor merges the overlays naming cluster onto doc, in the order they are listed. doc is
+// left unchanged.
+func overlayFor(doc yaml.MapSlice, overlays []ClusterOverlay, cluster string) yaml.MapSlice {
+	for _, overlay := range overlays {
+		if slices.Contains(overlay.Clusters, cluster) {
+			doc = mergeMapping(doc, overlay.Config)
+		}
+	}
+	return doc
+}
+
+// overlaidClusters returns the clusters named by overlays, in order of first appearance.
+func overlaidClusters(overlays []ClusterOverlay) []string {
+	var clusters []string
+	for _, overlay := range overlays {
+		for _, cluster := range overlay.Clusters {
+			if !slices.Contains(clusters, cluster) {
+				clusters = append(clusters, cluster)
+			}
+		}
+	}
+	return clusters
+}
+
+// mergeMapping returns base with overlay merged onto it, key by key.
+func mergeMapping(base, overlay yaml.MapSlice) yaml.MapSlice {
+	merged := slices.Clone(base)
+	for _, item := range overlay {
+		i := slices.IndexFunc(merged, func(existing yaml.MapItem) bool { return existing.Key == item.Key })
+		if i < 0 {
+			merged = append(merged, item)
+			continue
+		}
+		merged[i].Value = mergeValue(merged[i].Value, item.Value)
+	}
+	return merged
+}
+
+// mergeValue merges overlay onto base: mappings key by key, lists of named items by name, and
+// otherwise by replacing base.
+func mergeValue(base, overlay interface{}) interface{} {
+	switch overlayValue := overlay.(type) {
+	case yaml.MapSlice:
+		if baseValue, ok := base.(yaml.MapSlice); ok {
+			return mergeMapping(baseValue, overlayValue)
+		}
+	case []interface{}:
+		if baseValue, ok := base.([]interface{}); ok && namedItems(baseValue) && namedItems(overlayValue) {
+			return mergeNamedItems(baseValue, overlayValue)
+		}
+	}
+	return overlay
+}
+
+// mergeNamedItems merges each overlay item onto the base item of the same name, or appends it.
+func mergeNamedItems(base, overlay []interface{}) []interface{} {
+	merged := slices.Clone(base)
+	for _, item := range overlay {
+		name := itemName(item)
+		i := slices.IndexFunc(merged, func(existing interface{}) bool { return itemName(existing) == name })
+		if i < 0 {
+			merged = append(merged, item)
+			continue
+		}
+		merged[i] = mergeMapping(merged[i].(yaml.MapSlice), item.(yaml.MapSlice))
+	}
+	return merged
+}
+
+// namedItems reports whether every item of list is a mapping with a name.
+func namedItems(list []interface{}) bool {
+	for _, item := range list {
+		if itemName(item) == nil {
+			return false
+		}
+	}
+	return true
+}
+
+// itemName returns the name of a list item, or nil if it is not a mapping with a name.
+func itemName(item interface{}) interface{} {
+	mapping, ok := item.(yaml.MapSlice)
+	if !ok {
+		return nil
+	}
+	for _, field := range mapping {
+		if field.Key == "name" {
+			return field.Value
+		}
+	}
+	return nil
+}
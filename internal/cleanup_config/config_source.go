@@ -0,0 +1,237 @@
+package cleanupconfig
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+)
+
+// maxRemoteConfigSize caps the size of a config or signature fetched from a URL.
+const maxRemoteConfigSize = 4 << 20
+
+// configSource is where a watcher reads the config from.
+type configSource interface {
+	// poll returns the config document and when it was modified if it changed since the version
+	// last accepted; changed is false otherwise.
+	poll(ctx context.Context) (data []byte, modTime time.Time, changed bool, err error)
+	// accept marks the version last returned by poll as the active one.
+	accept()
+	// String describes the source for logs.
+	String() string
+}
+
+// fileSource reads the config from a file, detecting changes by its modification time.
+type fileSource struct {
+	path                 string
+	modTime, pendingTime time.Time
+}
+
+func newFileSource(path string) *fileSource {
+	source := &fileSource{path: path}
+	if stat, err := os.Stat(path); err == nil {
+		source.modTime = stat.ModTime()
+	}
+	return source
+}
+
+func (s *fileSource) poll(context.Context) ([]byte, time.Time, bool, error) {
+	stat, err := os.Stat(s.path)
+	if err != nil {
+		return nil, time.Time{}, false, fmt.Errorf("failed to stat config file: %w", err)
+	}
+	if !stat.ModTime().After(s.modTime) {
+		return nil, s.modTime, false, nil
+	}
+
+	data, err := os.ReadFile(s.path)
+	if err != nil {
+		return nil, time.Time{}, false, fmt.Errorf("unable to read config file %q: %w", s.path, err)
+	}
+	s.pendingTime = stat.ModTime()
+	return data, stat.ModTime(), true, nil
+}
+
+func (s *fileSource) accept() { s.modTime = s.pendingTime }
+
+func (s *fileSource) String() string { return s.path }
+
+// URLSource fetches the config from an HTTPS URL. Unchanged documents are skipped with ETags, and
+// when PublicKey is set every document must come with a detached signature.
+type URLSource struct {
+	URL string
+	// PublicKey, if set, verifies the base64-encoded ed25519 signature fetched from URL + ".sig".
+	PublicKey ed25519.PublicKey
+	// Client fetches the config; defaults to a client with a 30s timeout.
+	Client *http.Client
+
+	etag, pendingETag       string
+	modTime, pendingModTime time.Time
+}
+
+// NewURLSource returns a source fetching the config from rawURL, which must be an https URL.
+func NewURLSource(rawURL string, publicKey ed25519.PublicKey) (*URLSource, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid config URL: %w", err)
+	}
+	if u.Scheme != "https" || u.Host == "" {
+		return nil, fmt.Errorf("config URL %q must be an https URL", rawURL)
+	}
+	return &URLSource{URL: rawURL, PublicKey: publicKey}, nil
+}
+
+// LoadConfigFromURL fetches and loads the config from source, which then watches for changes
+// from this version on.
+func LoadConfigFromURL(ctx context.Context, source *URLSource) (*CleanupConfig, error) {
+	data, _, _, err := source.poll(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if data == nil {
+		return nil, fmt.Errorf("config URL %q returned no config", source.URL)
+	}
+
+	config, err := LoadConfig(data)
+	if err != nil {
+		return nil, err
+	}
+	source.accept()
+	return config, nil
+}
+
+func (s *URLSource) poll(ctx context.Context) ([]byte, time.Time, bool, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, s.URL, nil)
+	if err != nil {
+		return nil, time.Time{}, false, err
+	}
+	if s.etag != "" {
+		req.Header.Set("If-None-Match", s.etag)
+	}
+
+	resp, err := s.client().Do(req)
+	if err != nil {
+		return nil, time.Time{}, false, fmt.Errorf("failed to fetch config: %w", err)
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusNotModified:
+		return nil, s.modTime, false, nil
+	case http.StatusOK:
+	default:
+		return nil, time.Time{}, false, fmt.Errorf("failed to fetch config: %s", resp.Status)
+	}
+
+	data, err := readLimited(resp.Body)
+	if err != nil {
+		return nil, time.Time{}, false, fmt.Errorf("failed to read config: %w", err)
+	}
+	etag := resp.Header.Get("ETag")
+	if etag != "" && etag == s.etag {
+		// The server ignored If-None-Match but the document is the same.
+		return nil, s.modTime, false, nil
+	}
+
+	if s.PublicKey != nil {
+		if err := s.verify(ctx, data); err != nil {
+			return nil, time.Time{}, false, err
+		}
+	}
+
+	modTime := time.Now()
+	if lastModified, err := http.ParseTime(resp.Header.Get("Last-Modified")); err == nil {
+		modTime = lastModified
+	}
+	s.pendingETag, s.pendingModTime = etag, modTime
+	return data, modTime, true, nil
+}
+
+// verify checks data against the detached signature published next to the config.
+func (s *URLSource) verify(ctx context.Context, data []byte) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, s.URL+".sig", nil)
+	if err != nil {
+		return err
+	}
+	resp, err := s.client().Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to fetch config signature: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("failed to fetch config signature: %s", resp.Status)
+	}
+
+	encoded, err := readLimited(resp.Body)
+	if err != nil {
+		return fmt.Errorf("failed to read config signature: %w", err)
+	}
+	signature, err := base64.StdEncoding.DecodeString(strings.TrimSpace(string(encoded)))
+	if err != nil {
+		return fmt.Errorf("invalid config signature: %w", err)
+	}
+	if !ed25519.Verify(s.PublicKey, data, signature) {
+		return errors.New("config signature does not match the public key")
+	}
+	return nil
+}
+
+func (s *URLSource) accept() { s.etag, s.modTime = s.pendingETag, s.pendingModTime }
+
+func (s *URLSource) String() string { return s.URL }
+
+func (s *URLSource) client() *http.Client {
+	if s.Client != nil {
+		return s.Client
+	}
+	return defaultHTTPClient
+}
+
+var defaultHTTPClient = &http.Client{Timeout: 30 * time.Second}
+
+func readLimited(r io.Reader) ([]byte, error) {
+	data, err := io.ReadAll(io.LimitReader(r, maxRemoteConfigSize+1))
+	if err != nil {
+		return nil, err
+	}
+	if len(data) > maxRemoteConfigSize {
+		return nil, fmt.Errorf("larger than %d bytes", maxRemoteConfigSize)
+	}
+	return data, nil
+}
+
+// LoadPublicKey reads an ed25519 public key, either PEM-encoded as written by
+// `openssl pkey -pubout` or as the base64 of the raw 32-byte key.
+func LoadPublicKey(path string) (ed25519.PublicKey, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("unable to read public key file %q: %w", path, err)
+	}
+
+	if block, _ := pem.Decode(data); block != nil {
+		key, err := x509.ParsePKIXPublicKey(block.Bytes)
+		if err != nil {
+			return nil, fmt.Errorf("invalid public key %q: %w", path, err)
+		}
+		publicKey, ok := key.(ed25519.PublicKey)
+		if !ok {
+			return nil, fmt.Errorf("public key %q is not an ed25519 key", path)
+		}
+		return publicKey, nil
+	}
+
+	raw, err := base64.StdEncoding.DecodeString(strings.TrimSpace(string(data)))
+	if err != nil || len(raw) != ed25519.PublicKeySize {
+		return nil, fmt.Errorf("public key %q is neither PEM nor a base64 ed25519 key", path)
+	}
+	return ed25519.PublicKey(raw), nil
+}
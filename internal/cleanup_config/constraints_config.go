@@ -0,0 +1,119 @@
+package cleanupconfig
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+)
+
+//
+// Constraints Configuration
+//
+
+// Constraint modes decide what happens to a rule outside the constraints.
+const (
+	ConstraintModeReject = "reject" // Refuse the rule: file configs fail validation, tenant rules are rejected.
+	ConstraintModeClamp  = "clamp"  // Bring the rule within bounds where possible, e.g. raise its TTL.
+)
+
+// ConstraintsConfig holds administrator guardrails that every pod rule, whether from the config
+// file or a tenant's CleanupRule, must respect.
+type ConstraintsConfig struct {
+	Mode                string   `yaml:"mode,omitempty"`                // One of reject or clamp; defaults to reject.
+	MinTTL              Duration `yaml:"minTTL,omitempty"`              // Shortest TTL a rule may have.
+	ForbiddenNamespaces []string `yaml:"forbiddenNamespaces,omitempty"` // Namespaces no rule may clean up.
+	MaxBatchSize        int      `yaml:"maxBatchSize,omitempty"`        // Upper bound on batchSize; 0 means unbounded.
+	ForbiddenActions    []string `yaml:"forbiddenActions,omitempty"`    // Actions no rule may take, e.g. deleteOwner.
+}
+
+// Clamps reports whether out-of-bounds rules are clamped rather than rejected.
+func (c *ConstraintsConfig) Clamps() bool {
+	return c.Mode == ConstraintModeClamp
+}
+
+// Violations lists how rule falls outside the constraints.
+func (c *ConstraintsConfig) Violations(rule PodCleanRule) []string {
+	var violations []string
+
+	if rule.MinimumTTL() < c.MinTTL.Duration {
+		violations = append(violations, fmt.Sprintf("ttl %s is below the minimum of %s", rule.MinimumTTL(), c.MinTTL.Duration))
+	}
+
+	for _, namespace := range rule.Namespaces {
+		if slices.Contains(c.ForbiddenNamespaces, namespace) {
+			violations = append(violations, fmt.Sprintf("namespace %q is forbidden", namespace))
+		}
+	}
+
+	for _, action := range rule.Actions() {
+		if slices.Contains(c.ForbiddenActions, action) {
+			violations = append(violations, fmt.Sprintf("action %q is forbidden", action))
+		}
+	}
+
+	return violations
+}
+
+// Enforce returns rule within the constraints. In reject mode any violation is an error; in clamp
+// mode the TTL is raised, forbidden namespaces are dropped and deleteOwnerWhenEmpty is turned off,
+// and only rules that cannot be brought within bounds are an error. Forbidden namespaces are
+// always carried on the returned rule so that rules spanning namespaces skip them.
+func (c *ConstraintsConfig) Enforce(rule PodCleanRule) (PodCleanRule, error) {
+	rule.ForbiddenNamespaces = c.ForbiddenNamespaces
+	rule.MinTTL = c.MinTTL
+
+	violations := c.Violations(rule)
+	if len(violations) == 0 {
+		return rule, nil
+	}
+	if !c.Clamps() {
+		return rule, fmt.Errorf("rule %q violates constraints: %s", rule.Name, strings.Join(violations, "; "))
+	}
+
+	if action := rule.Action.EffectiveType(); slices.Contains(c.ForbiddenActions, action) {
+		return rule, fmt.Errorf("rule %q violates constraints: action %q is forbidden", rule.Name, action)
+	}
+
+	if rule.MinimumTTL() < c.MinTTL.Duration {
+		if rule.TTLBusinessDays > 0 {
+			rule.TTLBusinessDays = int((c.MinTTL.Duration + 24*time.Hour - 1) / (24 * time.Hour))
+		} else {
+			rule.TTL = c.MinTTL
+		}
+	}
+
+	if len(rule.Namespaces) > 0 {
+		rule.Namespaces = slices.DeleteFunc(slices.Clone(rule.Namespaces), func(namespace string) bool {
+			return slices.Contains(c.ForbiddenNamespaces, namespace)
+		})
+		if len(rule.Namespaces) == 0 {
+			return rule, fmt.Errorf("rule %q violates constraints: all of its namespaces are forbidden", rule.Name)
+		}
+	}
+
+	if slices.Contains(c.ForbiddenActions, ActionDeleteOwner) {
+		rule.DeleteOwnerWhenEmpty = false
+	}
+
+	return rule, nil
+}
+
+// Validate ensures ConstraintsConfig is correctly configured.
+func (c *ConstraintsConfig) Validate() error {
+	switch c.Mode {
+	case "", ConstraintModeReject, ConstraintModeClamp:
+	default:
+		return fmt.Errorf("mode must be one of %q or %q", ConstraintModeReject, ConstraintModeClamp)
+	}
+
+	if c.MinTTL.Duration < 0 {
+		return fmt.Errorf("minTTL cannot be negative")
+	}
+
+	if c.MaxBatchSize < 0 {
+		return fmt.Errorf("maxBatchSize cannot be negative")
+	}
+
+	return validateActionNames("forbiddenActions", c.ForbiddenActions)
+}
@@ -0,0 +1,99 @@
+package cleanupconfig
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// iso8601Duration matches the ISO 8601 durations of fixed length: weeks, days, hours, minutes and
+// seconds, e.g. P1D, PT2H45M or P1W.
+var iso8601Duration = regexp.MustCompile(`^P(?:(\d+)W)?(?:(\d+)D)?(?:T(?:(\d+)H)?(?:(\d+)M)?(?:(\d+(?:\.\d+)?)S)?)?$`)
+
+// parseDuration parses a YAML duration value: an integer number of seconds, a Go duration string
+// such as "90s" or "2h45m", or an ISO 8601 duration such as "PT2H45M". Values whose meaning is
+// unclear, such as fractional numbers, quoted numbers without a unit and ISO 8601 years or
+// months, are rejected with an error explaining how to write them.
+func parseDuration(value interface{}) (time.Duration, error) {
+	switch v := value.(type) {
+	case int:
+		return secondsDuration(int64(v))
+	case int64:
+		return secondsDuration(v)
+	case uint64:
+		if v > math.MaxInt64 {
+			return 0, fmt.Errorf("invalid duration %d: too large", v)
+		}
+		return secondsDuration(int64(v))
+	case float64:
+		return 0, fmt.Errorf("ambiguous duration %v: use a whole number of seconds or a string with a unit, e.g. \"1.5s\" or \"90m\"", v)
+	case string:
+		return parseDurationString(v)
+	default:
+		return 0, fmt.Errorf("invalid duration %v: expected a number of seconds or a string such as \"90s\", \"2h45m\" or \"PT2H45M\"", v)
+	}
+}
+
+// secondsDuration returns a duration of seconds.
+func secondsDuration(seconds int64) (time.Duration, error) {
+	if seconds > int64(math.MaxInt64/time.Second) || seconds < int64(math.MinInt64/time.Second) {
+		return 0, fmt.Errorf("invalid duration %d: too large", seconds)
+	}
+	return time.Duration(seconds) * time.Second, nil
+}
+
+// parseDurationString parses a Go or ISO 8601 duration string.
+func parseDurationString(s string) (time.Duration, error) {
+	if _, err := strconv.ParseFloat(s, 64); err == nil {
+		return 0, fmt.Errorf("ambiguous duration %q: add a unit, e.g. \"%ss\", or write the number unquoted to mean seconds", s, s)
+	}
+
+	if strings.HasPrefix(s, "P") {
+		return parseISO8601Duration(s)
+	}
+
+	duration, err := time.ParseDuration(s)
+	if err != nil {
+		return 0, fmt.Errorf("invalid duration %q: %w", s, err)
+	}
+	return duration, nil
+}
+
+// parseISO8601Duration parses an ISO 8601 duration of weeks, days, hours, minutes and seconds.
+// Days are 24 hours long.
+func parseISO8601Duration(s string) (time.Duration, error) {
+	match := iso8601Duration.FindStringSubmatch(s)
+	if match == nil || s == "P" || strings.HasSuffix(s, "T") {
+		if date, _, _ := strings.Cut(s[1:], "T"); strings.ContainsAny(date, "YM") {
+			return 0, fmt.Errorf("ambiguous duration %q: years and months have no fixed length; use weeks or days, e.g. \"P30D\"", s)
+		}
+		return 0, fmt.Errorf("invalid ISO 8601 duration %q: expected e.g. \"P1D\" or \"PT2H45M\"", s)
+	}
+
+	units := []time.Duration{7 * 24 * time.Hour, 24 * time.Hour, time.Hour, time.Minute}
+	var duration time.Duration
+	for i, unit := range units {
+		if match[i+1] == "" {
+			continue
+		}
+		n, err := strconv.ParseInt(match[i+1], 10, 64)
+		if err != nil || n > int64(math.MaxInt64/unit) {
+			return 0, fmt.Errorf("invalid ISO 8601 duration %q: too large", s)
+		}
+		duration += time.Duration(n) * unit
+	}
+	if match[5] != "" {
+		seconds, err := strconv.ParseFloat(match[5], 64)
+		if err != nil {
+			return 0, fmt.Errorf("invalid ISO 8601 duration %q: %w", s, err)
+		}
+		duration += time.Duration(seconds * float64(time.Second))
+	}
+	if duration < 0 {
+		return 0, fmt.Errorf("invalid ISO 8601 duration %q: too large", s)
+	}
+	return duration, nil
+}
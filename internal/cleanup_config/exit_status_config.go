@@ -0,0 +1,25 @@
+package cleanupconfig
+
+//
+// Exit Status Configuration
+//
+
+// ExitStatusConfig decides which outcomes of `kubeclean run` produce a non-zero exit status, so
+// that CronJob-based alerting fires on the failures an operator cares about.
+type ExitStatusConfig struct {
+	FailOnDeleteErrors *bool `yaml:"failOnDeleteErrors,omitempty"` // Exit non-zero when any deletion fails; defaults to true.
+	FailOnAborted      *bool `yaml:"failOnAborted,omitempty"`      // Exit non-zero when the circuit breaker deferred or aborted the run; defaults to true.
+	FailOnSafetyCap    bool  `yaml:"failOnSafetyCap,omitempty"`    // Exit non-zero when a deletion budget deferred pods.
+	FailOnForbidden    bool  `yaml:"failOnForbidden,omitempty"`    // Exit non-zero when RBAC kept a rule from listing resources.
+}
+
+// FailsOnDeleteErrors reports whether failed deletions produce a non-zero exit status.
+func (c *ExitStatusConfig) FailsOnDeleteErrors() bool {
+	return c.FailOnDeleteErrors == nil || *c.FailOnDeleteErrors
+}
+
+// FailsOnAborted reports whether runs the circuit breaker deferred or aborted produce a non-zero
+// exit status.
+func (c *ExitStatusConfig) FailsOnAborted() bool {
+	return c.FailOnAborted == nil || *c.FailOnAborted
+}
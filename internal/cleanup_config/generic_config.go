@@ -0,0 +1,173 @@
+package cleanupconfig
+
+import (
+	"fmt"
+	"strings"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/util/jsonpath"
+)
+
+//
+// Generic Resource Cleanup Configuration
+//
+
+// GenericCleanupConfig defines rules for cleaning up arbitrary resources, such as custom resources
+// that operators leave behind, identified by their API version and kind.
+type GenericCleanupConfig struct {
+	Enabled bool               `yaml:"enabled,omitempty"` // If false, generic resource cleanup is disabled.
+	Rules   []GenericCleanRule `yaml:"rules,omitempty"`   // List of rules for selecting and cleaning up resources.
+}
+
+// Validate ensures GenericCleanupConfig is correctly configured.
+func (c *GenericCleanupConfig) Validate() error {
+	if !c.Enabled {
+		return nil
+	}
+
+	var errorMessages string
+
+	for idx, rule := range c.Rules {
+		if err := rule.Validate(); err != nil {
+			errorMessages += fmt.Sprintf("rule %d (%s): %v\n", idx+1, rule.Name, err)
+		}
+	}
+
+	if errorMessages == "" {
+		return nil
+	}
+
+	return fmt.Errorf("generic cleanup config validation errors:\n%s", errorMessages)
+}
+
+// DefaultGenericMaxDeletePercent is the share of a kind's resources a generic rule may delete in
+// one run when maxDeletePercent is unset.
+const DefaultGenericMaxDeletePercent = 50
+
+// GenericCleanRule deletes resources of one kind once they are older than the TTL. The age is
+// measured from the timestamp at timestampPath, so resources can be aged by their own lifecycle
+// fields, and condition restricts the rule to resources in a given state.
+type GenericCleanRule struct {
+	Name          string                `yaml:"name"`                    // Unique name of the rule for identification.
+	Enabled       bool                  `yaml:"enabled,omitempty"`       // If false, the rule is skipped during processing.
+	Lane          string                `yaml:"lane,omitempty"`          // fast (default) or slow; see LanesConfig.
+	APIVersion    string                `yaml:"apiVersion"`              // API version of the kind, e.g. argoproj.io/v1alpha1.
+	Kind          string                `yaml:"kind"`                    // Kind of the resources, e.g. Workflow.
+	Namespaces    []string              `yaml:"namespaces,omitempty"`    // Specific namespaces where the rule applies.
+	Selector      *metav1.LabelSelector `yaml:"-"`                       // Only resources with these labels are considered.
+	TTL           Duration              `yaml:"ttl"`                     // Minimum age before a matching resource is deleted.
+	TimestampPath string                `yaml:"timestampPath,omitempty"` // JSONPath to the RFC 3339 timestamp the TTL counts from; defaults to the creation time.
+	Condition     *JSONPathCondition    `yaml:"condition,omitempty"`     // Only resources whose fields satisfy the condition are matched.
+
+	MaxDeletePercent int  `yaml:"maxDeletePercent,omitempty"` // Largest share of the kind's resources in scope one run may delete; defaults to 50.
+	Force            bool `yaml:"force,omitempty"`            // Delete every match even when it exceeds maxDeletePercent.
+
+	NotificationSinks []string `yaml:"notificationSinks,omitempty"` // Sinks notified for this rule; defaults to all global sinks.
+}
+
+// JSONPathCondition matches resources by the values a JSONPath expression yields for them.
+type JSONPathCondition struct {
+	Path   string   `yaml:"path"`             // JSONPath evaluated against the resource, e.g. .status.phase.
+	Values []string `yaml:"values,omitempty"` // Matches when any yielded value is one of these; when empty, when the path yields any non-empty value.
+}
+
+// UnmarshalYAML decodes a GenericCleanRule, translating its label selector from its YAML form.
+func (r *GenericCleanRule) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	type plain GenericCleanRule
+	if err := unmarshal((*plain)(r)); err != nil {
+		return err
+	}
+
+	var selectors struct {
+		Selector *yamlLabelSelector `yaml:"selector"`
+	}
+	if err := unmarshal(&selectors); err != nil {
+		return err
+	}
+	r.Selector = selectors.Selector.toLabelSelector()
+
+	return nil
+}
+
+// EffectiveMaxDeletePercent returns maxDeletePercent, or DefaultGenericMaxDeletePercent when unset.
+func (r *GenericCleanRule) EffectiveMaxDeletePercent() int {
+	if r.MaxDeletePercent <= 0 {
+		return DefaultGenericMaxDeletePercent
+	}
+	return r.MaxDeletePercent
+}
+
+// GroupVersionKind returns the kind the rule targets.
+func (r *GenericCleanRule) GroupVersionKind() schema.GroupVersionKind {
+	return schema.FromAPIVersionAndKind(r.APIVersion, r.Kind)
+}
+
+// Validate checks that the rule names a kind and that its JSONPath expressions parse.
+func (r *GenericCleanRule) Validate() error {
+	if !r.Enabled {
+		return nil
+	}
+
+	if r.Name == "" {
+		return fmt.Errorf("rule name must be provided")
+	}
+
+	if err := validateLane(r.Lane); err != nil {
+		return err
+	}
+
+	if r.APIVersion == "" || r.Kind == "" {
+		return fmt.Errorf("both 'apiVersion' and 'kind' must be specified")
+	}
+
+	if _, err := schema.ParseGroupVersion(r.APIVersion); err != nil {
+		return fmt.Errorf("invalid apiVersion: %w", err)
+	}
+
+	if r.TTL.Duration <= 0 {
+		return fmt.Errorf("ttl must be greater than zero")
+	}
+
+	if r.MaxDeletePercent < 0 || r.MaxDeletePercent > 100 {
+		return fmt.Errorf("maxDeletePercent must be between 0 and 100")
+	}
+
+	if r.TimestampPath != "" {
+		if _, err := ParseJSONPath(r.TimestampPath); err != nil {
+			return fmt.Errorf("invalid timestampPath: %w", err)
+		}
+	}
+
+	if r.Condition != nil {
+		if r.Condition.Path == "" {
+			return fmt.Errorf("condition path must be provided")
+		}
+		if _, err := ParseJSONPath(r.Condition.Path); err != nil {
+			return fmt.Errorf("invalid condition path: %w", err)
+		}
+	}
+
+	if r.Selector != nil {
+		if _, err := metav1.LabelSelectorAsSelector(r.Selector); err != nil {
+			return fmt.Errorf("invalid selector: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// ParseJSONPath parses a JSONPath expression as kubectl accepts it: either a template such as
+// {.status.phase} or a bare path such as .status.phase. Missing keys yield no results.
+func ParseJSONPath(path string) (*jsonpath.JSONPath, error) {
+	template := strings.TrimSpace(path)
+	if !strings.HasPrefix(template, "{") {
+		template = "{" + template + "}"
+	}
+
+	parser := jsonpath.New("path").AllowMissingKeys(true)
+	if err := parser.Parse(template); err != nil {
+		return nil, err
+	}
+	return parser, nil
+}
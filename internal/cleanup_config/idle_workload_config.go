@@ -0,0 +1,127 @@
+package cleanupconfig
+
+import (
+	"fmt"
+	"slices"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+//
+// Idle Workload Configuration
+//
+
+// Kinds an IdleWorkloadRule can scale to zero.
+const (
+	IdleKindDeployment  = "Deployment"
+	IdleKindStatefulSet = "StatefulSet"
+)
+
+// Conditions under which an IdleWorkloadRule considers a workload idle.
+const (
+	IdleConditionNoTraffic  = "noTraffic"      // None of the workload's pods is a ready endpoint of a Service.
+	IdleConditionAnnotation = "idleAnnotation" // The workload carries IdleAnnotation set to "true".
+)
+
+// IdleAnnotation marks a workload as idle for rules with the idleAnnotation condition.
+const IdleAnnotation = "kubeclean/idle"
+
+var (
+	idleKinds      = []string{IdleKindDeployment, IdleKindStatefulSet}
+	idleConditions = []string{IdleConditionNoTraffic, IdleConditionAnnotation}
+)
+
+// IdleWorkloadConfig defines rules that scale idle workloads to zero instead of deleting anything.
+type IdleWorkloadConfig struct {
+	Enabled bool               `yaml:"enabled,omitempty"` // If false, idle workloads are left alone.
+	Rules   []IdleWorkloadRule `yaml:"rules,omitempty"`   // List of rules for detecting idle workloads.
+}
+
+// Validate ensures IdleWorkloadConfig is correctly configured.
+func (c *IdleWorkloadConfig) Validate() error {
+	if !c.Enabled {
+		return nil
+	}
+
+	var errorMessages string
+
+	for idx, rule := range c.Rules {
+		if err := rule.Validate(); err != nil {
+			errorMessages += fmt.Sprintf("rule %d (%s): %v\n", idx+1, rule.Name, err)
+		}
+	}
+
+	if errorMessages == "" {
+		return nil
+	}
+
+	return fmt.Errorf("idle workload config validation errors:\n%s", errorMessages)
+}
+
+// IdleWorkloadRule scales Deployments or StatefulSets to zero once they have been idle for TTL.
+type IdleWorkloadRule struct {
+	Name       string                `yaml:"name"`                 // Unique name of the rule for identification.
+	Enabled    bool                  `yaml:"enabled,omitempty"`    // If false, the rule is skipped during processing.
+	Lane       string                `yaml:"lane,omitempty"`       // fast (default) or slow; see LanesConfig.
+	Kind       string                `yaml:"kind"`                 // Deployment or StatefulSet.
+	Condition  string                `yaml:"condition"`            // What makes a workload idle; see the IdleCondition constants.
+	TTL        Duration              `yaml:"ttl"`                  // How long a workload must stay idle before it is scaled to zero.
+	Namespaces []string              `yaml:"namespaces,omitempty"` // Specific namespaces where the rule applies.
+	Selector   *metav1.LabelSelector `yaml:"-"`                    // Only workloads with these labels are considered.
+
+	NotificationSinks []string `yaml:"notificationSinks,omitempty"` // Sinks notified for this rule; defaults to all global sinks.
+}
+
+// UnmarshalYAML decodes an IdleWorkloadRule, translating its label selector from its YAML form.
+func (r *IdleWorkloadRule) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	type plain IdleWorkloadRule
+	if err := unmarshal((*plain)(r)); err != nil {
+		return err
+	}
+
+	var selectors struct {
+		Selector *yamlLabelSelector `yaml:"selector"`
+	}
+	if err := unmarshal(&selectors); err != nil {
+		return err
+	}
+	r.Selector = selectors.Selector.toLabelSelector()
+
+	return nil
+}
+
+// Validate checks that the rule targets a supported kind and condition.
+func (r *IdleWorkloadRule) Validate() error {
+	if !r.Enabled {
+		return nil
+	}
+
+	if r.Name == "" {
+		return fmt.Errorf("rule name must be provided")
+	}
+
+	if err := validateLane(r.Lane); err != nil {
+		return err
+	}
+
+	if !slices.Contains(idleKinds, r.Kind) {
+		return fmt.Errorf("kind must be one of %v", idleKinds)
+	}
+
+	if !slices.Contains(idleConditions, r.Condition) {
+		return fmt.Errorf("condition must be one of %v", idleConditions)
+	}
+
+	// An idle annotation is an explicit request, so it may take effect right away.
+	if r.TTL.Duration < 0 || (r.TTL.Duration == 0 && r.Condition == IdleConditionNoTraffic) {
+		return fmt.Errorf("ttl must be greater than zero")
+	}
+
+	if r.Selector != nil {
+		if _, err := metav1.LabelSelectorAsSelector(r.Selector); err != nil {
+			return fmt.Errorf("invalid selector: %w", err)
+		}
+	}
+
+	return nil
+}
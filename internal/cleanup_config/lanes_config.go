@@ -0,0 +1,49 @@
+package cleanupconfig
+
+import "fmt"
+
+//
+// Lanes Configuration
+//
+
+// Lanes a rule can run in, set by its lane field.
+const (
+	LaneFast = "fast" // Runs every full run; the default.
+	LaneSlow = "slow" // Runs every lanes.slowEvery full runs, for expensive rules such as reference analysis.
+)
+
+// DefaultSlowLaneEvery is the number of full runs between passes of the slow lane when unset.
+const DefaultSlowLaneEvery = 6
+
+// LanesConfig schedules the slow lane. Cheap, urgent rules, e.g. for evicted pods, stay in the
+// fast lane and run every interval; rules that list and cross-reference many resources can be
+// moved to the slow lane to run less often.
+type LanesConfig struct {
+	SlowEvery int `yaml:"slowEvery,omitempty"` // Full runs per pass of the slow lane, starting with the first; defaults to 6.
+}
+
+// SlowLaneEvery returns the number of full runs per pass of the slow lane.
+func (c *LanesConfig) SlowLaneEvery() int {
+	if c.SlowEvery <= 0 {
+		return DefaultSlowLaneEvery
+	}
+	return c.SlowEvery
+}
+
+// Validate ensures LanesConfig is correctly configured.
+func (c *LanesConfig) Validate() error {
+	if c.SlowEvery < 0 {
+		return fmt.Errorf("slowEvery cannot be negative")
+	}
+	return nil
+}
+
+// validateLane checks the lane field of a rule.
+func validateLane(lane string) error {
+	switch lane {
+	case "", LaneFast, LaneSlow:
+		return nil
+	default:
+		return fmt.Errorf("lane must be one of %q or %q", LaneFast, LaneSlow)
+	}
+}
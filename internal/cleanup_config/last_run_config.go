@@ -0,0 +1,33 @@
+package cleanupconfig
+
+import (
+	"fmt"
+	"strings"
+)
+
+//
+// Last Run Persistence Configuration
+//
+
+// LastRunConfig persists when the last run started and each rule's last status, so that the run
+// schedule and rule statuses survive controller restarts.
+type LastRunConfig struct {
+	ConfigMap string `yaml:"configMap,omitempty"` // "namespace/name" of a ConfigMap persisting the last run; empty keeps it in memory.
+}
+
+// ConfigMapKey returns the namespace and name of the ConfigMap persisting the last run, if any.
+func (c *LastRunConfig) ConfigMapKey() (namespace, name string, ok bool) {
+	namespace, name, ok = strings.Cut(c.ConfigMap, "/")
+	return namespace, name, ok && namespace != "" && name != ""
+}
+
+// Validate ensures LastRunConfig is correctly configured.
+func (c *LastRunConfig) Validate() error {
+	if c.ConfigMap != "" {
+		if _, _, ok := c.ConfigMapKey(); !ok {
+			return fmt.Errorf("configMap must be of the form namespace/name, got %q", c.ConfigMap)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,61 @@
+package cleanupconfig
+
+import (
+	"fmt"
+	"path/filepath"
+)
+
+//
+// Run Ledger Configuration
+//
+
+// Defaults applied to unset LedgerConfig fields.
+const (
+	DefaultLedgerPath = "/var/lib/kubeclean/ledger.jsonl" // File the run ledger is appended to.
+	DefaultLedgerKeep = 10                                // Rotated ledger files kept.
+)
+
+// LedgerConfig appends a record of every run to a hash-chained ledger file, so that runs can be
+// audited and tampering with their records is detected by `kubeclean ledger verify`.
+type LedgerConfig struct {
+	Enabled bool   `yaml:"enabled,omitempty"` // If false, runs are not recorded.
+	Path    string `yaml:"path,omitempty"`    // Ledger file, on a persistent volume; defaults to /var/lib/kubeclean/ledger.jsonl.
+
+	MaxRecords int `yaml:"maxRecords,omitempty"` // Records per file before it is rotated; 0 never rotates.
+	Keep       int `yaml:"keep,omitempty"`       // Rotated files kept, oldest removed first; defaults to 10.
+}
+
+// FilePath returns the file the ledger is appended to.
+func (c *LedgerConfig) FilePath() string {
+	if c.Path == "" {
+		return DefaultLedgerPath
+	}
+	return c.Path
+}
+
+// KeepCount returns the number of rotated ledger files kept.
+func (c *LedgerConfig) KeepCount() int {
+	if c.Keep <= 0 {
+		return DefaultLedgerKeep
+	}
+	return c.Keep
+}
+
+// Validate ensures LedgerConfig is correctly configured.
+func (c *LedgerConfig) Validate() error {
+	if !c.Enabled {
+		return nil
+	}
+
+	if c.Path != "" && !filepath.IsAbs(c.Path) {
+		return fmt.Errorf("path must be absolute, got %q", c.Path)
+	}
+	if c.MaxRecords < 0 {
+		return fmt.Errorf("maxRecords cannot be negative")
+	}
+	if c.Keep < 0 {
+		return fmt.Errorf("keep cannot be negative")
+	}
+
+	return nil
+}
@@ -0,0 +1,123 @@
+package cleanupconfig
+
+import (
+	"fmt"
+	"net/url"
+	"slices"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+//
+// Log Forwarding Configuration
+//
+
+// Log forwarding backends.
+const (
+	LogBackendLoki          = "loki"          // Pushes to Loki's /loki/api/v1/push.
+	LogBackendElasticsearch = "elasticsearch" // Indexes through Elasticsearch's _bulk API.
+)
+
+// Defaults of LogForwardingConfig.
+const (
+	DefaultLogIndex        = "kubeclean-logs"
+	DefaultLogTailLines    = 1000
+	DefaultLogLinesPerPush = 10000
+)
+
+// LogForwardingConfig captures the logs of pods right before they are deleted and pushes them to
+// a log store, labelled with the rule, namespace and pod, so they stay searchable afterwards.
+type LogForwardingConfig struct {
+	Enabled   bool          `yaml:"enabled,omitempty"`   // If false, logs are not captured.
+	Backend   string        `yaml:"backend,omitempty"`   // loki or elasticsearch.
+	URL       string        `yaml:"url,omitempty"`       // Base URL of the backend; userinfo is sent as basic auth.
+	URLFrom   *SecretKeyRef `yaml:"urlFrom,omitempty"`   // Secret key holding the URL instead of url, keeping credentials out of the config.
+	TenantID  string        `yaml:"tenantID,omitempty"`  // Loki tenant, sent as X-Scope-OrgID.
+	Index     string        `yaml:"index,omitempty"`     // Elasticsearch index; defaults to kubeclean-logs.
+	Phases    []string      `yaml:"phases,omitempty"`    // Phases of pods whose logs are captured; defaults to Failed.
+	TailLines int64         `yaml:"tailLines,omitempty"` // Last lines captured per container; defaults to 1000.
+
+	MaxLinesPerPush   int  `yaml:"maxLinesPerPush,omitempty"`   // Lines sent per push request, never splitting a pod's logs; defaults to 10000.
+	HoldOnPushFailure bool `yaml:"holdOnPushFailure,omitempty"` // Keep pods whose logs could not be pushed until a later run instead of deleting them.
+}
+
+// CapturesPhase reports whether the logs of pods in phase are captured.
+func (c *LogForwardingConfig) CapturesPhase(phase corev1.PodPhase) bool {
+	if len(c.Phases) == 0 {
+		return phase == corev1.PodFailed
+	}
+	return slices.Contains(c.Phases, string(phase))
+}
+
+// EffectiveTailLines returns the number of lines captured per container.
+func (c *LogForwardingConfig) EffectiveTailLines() int64 {
+	if c.TailLines <= 0 {
+		return DefaultLogTailLines
+	}
+	return c.TailLines
+}
+
+// LinesPerPush returns the number of lines sent per push request.
+func (c *LogForwardingConfig) LinesPerPush() int {
+	if c.MaxLinesPerPush <= 0 {
+		return DefaultLogLinesPerPush
+	}
+	return c.MaxLinesPerPush
+}
+
+// EffectiveIndex returns the Elasticsearch index logs are written to.
+func (c *LogForwardingConfig) EffectiveIndex() string {
+	if c.Index == "" {
+		return DefaultLogIndex
+	}
+	return c.Index
+}
+
+// ValidateURL ensures the backend URL is absolute. A URL read from a Secret is checked once resolved.
+func (c *LogForwardingConfig) ValidateURL() error {
+	parsed, err := url.Parse(c.URL)
+	if err != nil || !parsed.IsAbs() || parsed.Host == "" {
+		return fmt.Errorf("url must be an absolute URL")
+	}
+	return nil
+}
+
+// Validate ensures LogForwardingConfig is correctly configured.
+func (c *LogForwardingConfig) Validate() error {
+	if !c.Enabled {
+		return nil
+	}
+
+	if c.Backend != LogBackendLoki && c.Backend != LogBackendElasticsearch {
+		return fmt.Errorf("backend must be %q or %q, got %q", LogBackendLoki, LogBackendElasticsearch, c.Backend)
+	}
+
+	if c.URLFrom != nil {
+		if c.URL != "" {
+			return fmt.Errorf("url and urlFrom are mutually exclusive")
+		}
+		if err := c.URLFrom.Validate(); err != nil {
+			return fmt.Errorf("urlFrom: %w", err)
+		}
+	} else if err := c.ValidateURL(); err != nil {
+		return err
+	}
+
+	if c.TailLines < 0 {
+		return fmt.Errorf("tailLines cannot be negative")
+	}
+
+	if c.MaxLinesPerPush < 0 {
+		return fmt.Errorf("maxLinesPerPush cannot be negative")
+	}
+
+	for _, phase := range c.Phases {
+		switch phase {
+		case "Pending", "Running", "Succeeded", "Failed", "Unknown":
+		default:
+			return fmt.Errorf("unknown pod phase %q", phase)
+		}
+	}
+
+	return nil
+}
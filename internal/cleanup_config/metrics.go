@@ -0,0 +1,89 @@
+package cleanupconfig
+
+import (
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+)
+
+// configStatus tracks how current the active config is, for the staleness metrics.
+type configStatus struct {
+	mu       sync.Mutex
+	modTime  time.Time // Modification time of the file the active config was loaded from.
+	loadedAt time.Time // When the active config was last loaded, at startup or on a reload.
+	stale    bool      // The file on disk no longer matches the active config.
+}
+
+// loaded records that the file modified at modTime was loaded at now.
+func (s *configStatus) loaded(modTime, now time.Time) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.modTime, s.loadedAt, s.stale = modTime, now, false
+}
+
+func (s *configStatus) setStale(stale bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.stale = stale
+}
+
+// ages returns the seconds since the active config's file was modified and since it was
+// loaded; each is 0 while unknown.
+func (s *configStatus) ages(now time.Time) (config, reload float64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if !s.modTime.IsZero() {
+		config = now.Sub(s.modTime).Seconds()
+	}
+	if !s.loadedAt.IsZero() {
+		reload = now.Sub(s.loadedAt).Seconds()
+	}
+	return config, reload
+}
+
+var (
+	watchedConfig configStatus
+
+	configAgeSeconds = prometheus.NewGaugeFunc(
+		prometheus.GaugeOpts{
+			Name: "kubeclean_config_age_seconds",
+			Help: "Seconds since the config file the active config was loaded from was modified.",
+		},
+		func() float64 {
+			config, _ := watchedConfig.ages(time.Now())
+			return config
+		},
+	)
+
+	configReloadAgeSeconds = prometheus.NewGaugeFunc(
+		prometheus.GaugeOpts{
+			Name: "kubeclean_config_last_reload_age_seconds",
+			Help: "Seconds since the config was last loaded successfully, at startup or on a reload.",
+		},
+		func() float64 {
+			_, reload := watchedConfig.ages(time.Now())
+			return reload
+		},
+	)
+
+	configStale = prometheus.NewGaugeFunc(
+		prometheus.GaugeOpts{
+			Name: "kubeclean_config_stale",
+			Help: "1 when the config file on disk differs from the active config because it cannot be read or reloaded, 0 otherwise.",
+		},
+		func() float64 {
+			watchedConfig.mu.Lock()
+			defer watchedConfig.mu.Unlock()
+			if watchedConfig.stale {
+				return 1
+			}
+			return 0
+		},
+	)
+)
+
+func init() {
+	metrics.Registry.MustRegister(configAgeSeconds, configReloadAgeSeconds, configStale)
+}
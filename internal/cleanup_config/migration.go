@@ -0,0 +1,93 @@
+package cleanupconfig
+
+import (
+	"fmt"
+
+	"gopkg.in/yaml.v2"
+)
+
+//
+// Config Versioning and Migrations
+//
+
+// CurrentAPIVersion is the config schema version understood by this build.
+const CurrentAPIVersion = "kubeclean/v1"
+
+// migration upgrades a raw config document from one schema version to the next.
+type migration struct {
+	from  string
+	to    string
+	apply func(doc yaml.MapSlice) (yaml.MapSlice, error)
+}
+
+// migrations lists the upgrade steps in order; each step's 'to' is the next step's 'from'.
+var migrations = []migration{
+	// Unversioned configs predate the apiVersion field and already use the v1 schema.
+	{from: "", to: "kubeclean/v1", apply: func(doc yaml.MapSlice) (yaml.MapSlice, error) { return doc, nil }},
+}
+
+// migrateConfig upgrades a parsed config document to CurrentAPIVersion and re-encodes it.
+func migrateConfig(doc yaml.MapSlice) ([]byte, error) {
+	doc, err := applyMigrations(doc, migrations, CurrentAPIVersion)
+	if err != nil {
+		return nil, err
+	}
+
+	return yaml.Marshal(doc)
+}
+
+// applyMigrations walks the migration chain from the document's apiVersion up to target.
+func applyMigrations(doc yaml.MapSlice, steps []migration, target string) (yaml.MapSlice, error) {
+	version, err := documentVersion(doc)
+	if err != nil {
+		return nil, err
+	}
+
+	for version != target {
+		step, found := findMigration(steps, version)
+		if !found {
+			return nil, fmt.Errorf("unsupported apiVersion %q; expected %q", version, target)
+		}
+
+		if doc, err = step.apply(doc); err != nil {
+			return nil, fmt.Errorf("failed to migrate config from %q to %q: %w", step.from, step.to, err)
+		}
+
+		version = step.to
+		doc = setDocumentVersion(doc, version)
+	}
+
+	return doc, nil
+}
+
+func findMigration(steps []migration, from string) (migration, bool) {
+	for _, step := range steps {
+		if step.from == from {
+			return step, true
+		}
+	}
+	return migration{}, false
+}
+
+func documentVersion(doc yaml.MapSlice) (string, error) {
+	for _, item := range doc {
+		if item.Key == "apiVersion" {
+			version, ok := item.Value.(string)
+			if !ok {
+				return "", fmt.Errorf("apiVersion must be a string")
+			}
+			return version, nil
+		}
+	}
+	return "", nil
+}
+
+func setDocumentVersion(doc yaml.MapSlice, version string) yaml.MapSlice {
+	for i, item := range doc {
+		if item.Key == "apiVersion" {
+			doc[i].Value = version
+			return doc
+		}
+	}
+	return append(yaml.MapSlice{{Key: "apiVersion", Value: version}}, doc...)
+}
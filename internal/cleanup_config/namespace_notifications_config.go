@@ -0,0 +1,72 @@
+package cleanupconfig
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+//
+// Namespace Owner Notifications Configuration
+//
+
+// Defaults applied to unset NamespaceNotificationsConfig fields.
+const (
+	DefaultNamespaceNotificationInterval = time.Hour // Minimum time between notifications to a namespace's owner.
+	DefaultNamespaceNotificationMinPods  = 1         // Deletions below which owners are not notified.
+)
+
+// NamespaceNotificationsConfig sends the owner of a namespace one aggregated notification of the
+// pods deleted in it, at most once per interval. Owners are resolved from the kubeclean/owner-sink
+// namespace annotation, which names a configured sink, or from an ownership ConfigMap.
+type NamespaceNotificationsConfig struct {
+	Enabled            bool     `yaml:"enabled,omitempty"`            // If false, namespace owners are not notified.
+	OwnershipConfigMap string   `yaml:"ownershipConfigMap,omitempty"` // "namespace/name" of a ConfigMap mapping namespaces to owner sinks.
+	MinInterval        Duration `yaml:"minInterval,omitempty"`        // Minimum time between notifications per namespace; defaults to 1h.
+	MinPods            int      `yaml:"minPods,omitempty"`            // Deletions below which owners are not notified; defaults to 1.
+}
+
+// Interval returns the minimum time between notifications to a namespace's owner.
+func (c *NamespaceNotificationsConfig) Interval() time.Duration {
+	if c.MinInterval.Duration <= 0 {
+		return DefaultNamespaceNotificationInterval
+	}
+	return c.MinInterval.Duration
+}
+
+// MinimumPods returns the number of deletions a namespace needs for its owner to be notified.
+func (c *NamespaceNotificationsConfig) MinimumPods() int {
+	if c.MinPods <= 0 {
+		return DefaultNamespaceNotificationMinPods
+	}
+	return c.MinPods
+}
+
+// OwnershipConfigMapKey returns the namespace and name of the ownership ConfigMap, if any.
+func (c *NamespaceNotificationsConfig) OwnershipConfigMapKey() (namespace, name string, ok bool) {
+	namespace, name, ok = strings.Cut(c.OwnershipConfigMap, "/")
+	return namespace, name, ok && namespace != "" && name != ""
+}
+
+// Validate ensures NamespaceNotificationsConfig is correctly configured.
+func (c *NamespaceNotificationsConfig) Validate() error {
+	if !c.Enabled {
+		return nil
+	}
+
+	if c.OwnershipConfigMap != "" {
+		if _, _, ok := c.OwnershipConfigMapKey(); !ok {
+			return fmt.Errorf("ownershipConfigMap must be of the form namespace/name, got %q", c.OwnershipConfigMap)
+		}
+	}
+
+	if c.MinInterval.Duration < 0 {
+		return fmt.Errorf("minInterval cannot be negative")
+	}
+
+	if c.MinPods < 0 {
+		return fmt.Errorf("minPods cannot be negative")
+	}
+
+	return nil
+}
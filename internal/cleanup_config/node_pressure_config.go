@@ -0,0 +1,53 @@
+package cleanupconfig
+
+import (
+	"fmt"
+	"time"
+)
+
+//
+// Node Pressure Configuration
+//
+
+// DefaultNodePressureCooldown is the minimum time between two passes triggered for the same node
+// when cooldown is unset.
+const DefaultNodePressureCooldown = time.Minute
+
+// NodePressureConfig triggers a pass of the pod rules over the completed pods of a node as soon as
+// the node comes under pressure, instead of waiting for the next interval. Completed pods hold
+// disk for their logs and containers, and count towards the kubelet's pod limit.
+type NodePressureConfig struct {
+	Enabled           bool     `yaml:"enabled,omitempty"`           // If false, node pressure does not trigger cleanups.
+	DiskPressure      bool     `yaml:"diskPressure,omitempty"`      // Triggers when a node reports the DiskPressure condition.
+	MaxTerminatedPods int      `yaml:"maxTerminatedPods,omitempty"` // Triggers when a node holds at least this many completed pods; 0 disables.
+	Cooldown          Duration `yaml:"cooldown,omitempty"`          // Minimum time between two triggered passes for the same node; defaults to 1m.
+}
+
+// EffectiveCooldown returns the minimum time between two passes triggered for a node.
+func (c *NodePressureConfig) EffectiveCooldown() time.Duration {
+	if c.Cooldown.Duration <= 0 {
+		return DefaultNodePressureCooldown
+	}
+	return c.Cooldown.Duration
+}
+
+// Validate ensures NodePressureConfig is correctly configured.
+func (c *NodePressureConfig) Validate() error {
+	if !c.Enabled {
+		return nil
+	}
+
+	if c.MaxTerminatedPods < 0 {
+		return fmt.Errorf("maxTerminatedPods must not be negative")
+	}
+
+	if !c.DiskPressure && c.MaxTerminatedPods == 0 {
+		return fmt.Errorf("diskPressure or maxTerminatedPods must be set")
+	}
+
+	if c.Cooldown.Duration < 0 {
+		return fmt.Errorf("cooldown must not be negative")
+	}
+
+	return nil
+}
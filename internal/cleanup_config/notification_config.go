@@ -0,0 +1,250 @@
+package cleanupconfig
+
+import (
+	"fmt"
+	"net/mail"
+	"net/url"
+	"path/filepath"
+)
+
+//
+// Notification Configuration
+//
+
+// Notification sink types.
+const (
+	SinkTypeWebhook = "webhook" // POSTs the event as JSON.
+	SinkTypeSlack   = "slack"   // POSTs a Slack incoming-webhook payload.
+	SinkTypeEmail   = "email"   // Sends an email through an SMTP relay.
+	SinkTypeFile    = "file"    // Writes every event as a file into a directory.
+)
+
+// SMTP TLS modes.
+const (
+	SMTPTLSStartTLS = "starttls" // Upgrade the connection with STARTTLS; the default.
+	SMTPTLSImplicit = "tls"      // Connect over TLS, e.g. on port 465.
+	SMTPTLSNone     = "none"     // Plaintext; only for relays on localhost.
+)
+
+// NotificationConfig defines the sinks that receive cleanup events.
+type NotificationConfig struct {
+	Sinks []NotificationSink `yaml:"sinks,omitempty"` // Global sinks; rules without overrides notify all of them.
+}
+
+// NotificationSink defines a single destination for cleanup events.
+type NotificationSink struct {
+	Name string `yaml:"name"`          // Unique name referenced by rules.
+	Type string `yaml:"type"`          // One of webhook, slack, email or file.
+	URL  string `yaml:"url,omitempty"` // Endpoint events are posted to, for webhook and slack sinks.
+
+	URLFrom *SecretKeyRef `yaml:"urlFrom,omitempty"` // Secret key holding the URL instead of url, e.g. a Slack webhook.
+
+	Email    *EmailSinkConfig `yaml:"email,omitempty"`    // SMTP settings of an email sink.
+	File     *FileSinkConfig  `yaml:"file,omitempty"`     // Directory of a file sink.
+	Template string           `yaml:"template,omitempty"` // Go template of the request body, email body or file content, executed on the event.
+
+	TenantSelectable bool `yaml:"tenantSelectable,omitempty"` // Namespaces may name this sink in their kubeclean/owner-sink annotation.
+}
+
+// EmailSinkConfig sends events as emails through an SMTP relay.
+type EmailSinkConfig struct {
+	Host     string   `yaml:"host"`               // SMTP relay host.
+	Port     int      `yaml:"port,omitempty"`     // Defaults to 465 with tls and 587 otherwise.
+	From     string   `yaml:"from"`               // Sender address.
+	To       []string `yaml:"to"`                 // Recipient addresses.
+	Subject  string   `yaml:"subject,omitempty"`  // Go template of the subject; defaults to one naming the rule or namespace.
+	Username string   `yaml:"username,omitempty"` // Authenticates with PLAIN auth when set.
+	Password string   `yaml:"password,omitempty"` // Password of username.
+
+	PasswordFrom *SecretKeyRef `yaml:"passwordFrom,omitempty"` // Secret key holding the password instead of password.
+
+	TLS SMTPTLSConfig `yaml:"tls,omitempty"`
+}
+
+// SMTPTLSConfig secures the connection to the SMTP relay.
+type SMTPTLSConfig struct {
+	Mode       string `yaml:"mode,omitempty"`       // One of starttls, tls or none; defaults to starttls.
+	CAFile     string `yaml:"caFile,omitempty"`     // PEM bundle verifying the relay instead of the system roots.
+	ServerName string `yaml:"serverName,omitempty"` // Name verified in the relay's certificate; defaults to host.
+}
+
+// TLSMode returns the TLS mode of the connection, defaulting to starttls.
+func (c *SMTPTLSConfig) TLSMode() string {
+	if c.Mode == "" {
+		return SMTPTLSStartTLS
+	}
+	return c.Mode
+}
+
+// Address returns the host:port of the SMTP relay.
+func (c *EmailSinkConfig) Address() string {
+	port := c.Port
+	if port == 0 {
+		port = 587
+		if c.TLS.TLSMode() == SMTPTLSImplicit {
+			port = 465
+		}
+	}
+	return fmt.Sprintf("%s:%d", c.Host, port)
+}
+
+// Validate ensures EmailSinkConfig is correctly configured.
+func (c *EmailSinkConfig) Validate() error {
+	if c.Host == "" {
+		return fmt.Errorf("host must be set")
+	}
+	if c.Port < 0 || c.Port > 65535 {
+		return fmt.Errorf("port %d is out of range", c.Port)
+	}
+
+	if _, err := mail.ParseAddress(c.From); err != nil {
+		return fmt.Errorf("invalid from address %q: %w", c.From, err)
+	}
+	if len(c.To) == 0 {
+		return fmt.Errorf("to must list at least one address")
+	}
+	for _, to := range c.To {
+		if _, err := mail.ParseAddress(to); err != nil {
+			return fmt.Errorf("invalid to address %q: %w", to, err)
+		}
+	}
+
+	if _, err := ParseTemplate("subject", c.Subject); err != nil {
+		return fmt.Errorf("invalid subject template: %w", err)
+	}
+
+	if c.PasswordFrom != nil {
+		if c.Password != "" {
+			return fmt.Errorf("password and passwordFrom are mutually exclusive")
+		}
+		if err := c.PasswordFrom.Validate(); err != nil {
+			return fmt.Errorf("passwordFrom: %w", err)
+		}
+	}
+	if (c.Password != "" || c.PasswordFrom != nil) && c.Username == "" {
+		return fmt.Errorf("a password requires a username")
+	}
+
+	switch c.TLS.TLSMode() {
+	case SMTPTLSStartTLS, SMTPTLSImplicit, SMTPTLSNone:
+	default:
+		return fmt.Errorf("unknown tls mode %q", c.TLS.Mode)
+	}
+	if c.TLS.CAFile != "" && c.TLS.TLSMode() == SMTPTLSNone {
+		return fmt.Errorf("tls.caFile requires a tls mode other than none")
+	}
+
+	return nil
+}
+
+// FileSinkConfig writes every event as a file into a directory, for air-gapped environments
+// where another process ships the files on.
+type FileSinkConfig struct {
+	Directory string `yaml:"directory"` // Absolute path of the directory events are written to.
+}
+
+// Validate ensures FileSinkConfig is correctly configured.
+func (c *FileSinkConfig) Validate() error {
+	if !filepath.IsAbs(c.Directory) {
+		return fmt.Errorf("directory must be an absolute path, got %q", c.Directory)
+	}
+	return nil
+}
+
+// Validate ensures sink names are unique and every sink is correctly configured.
+func (n *NotificationConfig) Validate() error {
+	seen := map[string]bool{}
+
+	for idx, sink := range n.Sinks {
+		if sink.Name == "" {
+			return fmt.Errorf("sink %d: name must be provided", idx+1)
+		}
+
+		if seen[sink.Name] {
+			return fmt.Errorf("sink %q: duplicate name", sink.Name)
+		}
+		seen[sink.Name] = true
+
+		if err := sink.Validate(); err != nil {
+			return fmt.Errorf("sink %q: %w", sink.Name, err)
+		}
+	}
+
+	return nil
+}
+
+// Validate checks that the sink type is known and its endpoint is a valid absolute URL, or its
+// email or file settings are valid. A URL read from a Secret is checked once it is resolved.
+func (s *NotificationSink) Validate() error {
+	if _, err := ParseTemplate("template", s.Template); err != nil {
+		return fmt.Errorf("invalid template: %w", err)
+	}
+
+	switch s.Type {
+	case SinkTypeWebhook, SinkTypeSlack:
+		if s.Email != nil || s.File != nil {
+			return fmt.Errorf("email and file settings require a sink of type email or file")
+		}
+	case SinkTypeEmail:
+		if s.Email == nil {
+			return fmt.Errorf("email settings must be provided")
+		}
+		if s.URL != "" || s.URLFrom != nil || s.File != nil {
+			return fmt.Errorf("email sinks only take email settings")
+		}
+		if err := s.Email.Validate(); err != nil {
+			return fmt.Errorf("email: %w", err)
+		}
+		return nil
+	case SinkTypeFile:
+		if s.File == nil {
+			return fmt.Errorf("file settings must be provided")
+		}
+		if s.URL != "" || s.URLFrom != nil || s.Email != nil {
+			return fmt.Errorf("file sinks only take file settings")
+		}
+		if err := s.File.Validate(); err != nil {
+			return fmt.Errorf("file: %w", err)
+		}
+		return nil
+	default:
+		return fmt.Errorf("unknown sink type %q", s.Type)
+	}
+
+	if s.URLFrom != nil {
+		if s.URL != "" {
+			return fmt.Errorf("url and urlFrom are mutually exclusive")
+		}
+		if err := s.URLFrom.Validate(); err != nil {
+			return fmt.Errorf("urlFrom: %w", err)
+		}
+		return nil
+	}
+
+	parsed, err := url.Parse(s.URL)
+	if err != nil || parsed.Scheme == "" || parsed.Host == "" {
+		return fmt.Errorf("url must be an absolute URL")
+	}
+
+	return nil
+}
+
+// HasSink reports whether a sink with the given name is configured.
+func (n *NotificationConfig) HasSink(name string) bool {
+	for _, sink := range n.Sinks {
+		if sink.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// checkReferences ensures every sink a rule references is configured.
+func (n *NotificationConfig) checkReferences(rule string, sinks []string) error {
+	for _, sink := range sinks {
+		if !n.HasSink(sink) {
+			return fmt.Errorf("rule %q references unknown notification sink %q", rule, sink)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,127 @@
+package cleanupconfig
+
+import (
+	"fmt"
+	"slices"
+	"time"
+)
+
+//
+// Orphaned Resource Cleanup Configuration
+//
+
+// Kinds an OrphanCleanRule can target.
+const (
+	OrphanKindHorizontalPodAutoscaler = "HorizontalPodAutoscaler" // Orphaned when its scaleTargetRef no longer exists.
+	OrphanKindPodDisruptionBudget     = "PodDisruptionBudget"     // Orphaned when no pod or workload template matches its selector.
+	OrphanKindServiceAccount          = "ServiceAccount"          // Orphaned when no pod or workload template runs as it.
+	OrphanKindRoleBinding             = "RoleBinding"             // Orphaned when every subject is a ServiceAccount that no longer exists.
+	OrphanKindClusterRoleBinding      = "ClusterRoleBinding"      // Orphaned when every subject is a ServiceAccount that no longer exists.
+	OrphanKindImagePullSecret         = "ImagePullSecret"         // docker-registry Secret no ServiceAccount or pod template pulls with.
+	OrphanKindNetworkPolicy           = "NetworkPolicy"           // Orphaned while its podSelector matches no pod.
+)
+
+// DefaultImagePullSecretBurnIn is the burn-in applied to ImagePullSecret rules that do not set one.
+// Deleting a pull secret still in use breaks image pulls on the next reschedule, so these rules
+// always report for a while before they may delete.
+const DefaultImagePullSecretBurnIn = 7 * 24 * time.Hour
+
+// orphanKinds lists every kind with an orphan detector.
+var orphanKinds = []string{
+	OrphanKindHorizontalPodAutoscaler,
+	OrphanKindPodDisruptionBudget,
+	OrphanKindServiceAccount,
+	OrphanKindRoleBinding,
+	OrphanKindClusterRoleBinding,
+	OrphanKindImagePullSecret,
+	OrphanKindNetworkPolicy,
+}
+
+// OrphanCleanupConfig defines rules for resources whose targets no longer exist.
+type OrphanCleanupConfig struct {
+	Enabled bool              `yaml:"enabled,omitempty"` // If false, orphaned resource cleanup is disabled.
+	Rules   []OrphanCleanRule `yaml:"rules,omitempty"`   // List of rules for detecting and cleaning up orphaned resources.
+}
+
+// Validate ensures OrphanCleanupConfig is correctly configured.
+func (c *OrphanCleanupConfig) Validate() error {
+	if !c.Enabled {
+		return nil
+	}
+
+	var errorMessages string
+
+	for idx, rule := range c.Rules {
+		if err := rule.Validate(); err != nil {
+			errorMessages += fmt.Sprintf("rule %d (%s): %v\n", idx+1, rule.Name, err)
+		}
+	}
+
+	if errorMessages == "" {
+		return nil
+	}
+
+	return fmt.Errorf("orphan cleanup config validation errors:\n%s", errorMessages)
+}
+
+// OrphanCleanRule detects resources of one kind whose targets no longer exist.
+// Orphans are only reported unless dryRun is explicitly set to false.
+type OrphanCleanRule struct {
+	Name       string   `yaml:"name"`                 // Unique name of the rule for identification.
+	Enabled    bool     `yaml:"enabled,omitempty"`    // If false, the rule is skipped during processing.
+	Lane       string   `yaml:"lane,omitempty"`       // fast (default) or slow; see LanesConfig.
+	Kind       string   `yaml:"kind"`                 // Kind of resource to check; see the OrphanKind constants.
+	TTL        Duration `yaml:"ttl"`                  // How long a resource must stay orphaned before it is deleted.
+	Namespaces []string `yaml:"namespaces,omitempty"` // Specific namespaces where the rule applies.
+	DryRun     *bool    `yaml:"dryRun,omitempty"`     // Report orphans without deleting them; defaults to true.
+	BurnIn     Duration `yaml:"burnIn,omitempty"`     // Period after the rule is first evaluated during which it is forced to dry-run.
+
+	NotificationSinks []string `yaml:"notificationSinks,omitempty"` // Sinks notified for this rule; defaults to all global sinks.
+}
+
+// IsDryRun reports whether the rule only reports orphans. Rules are dry-run unless explicitly disabled.
+func (r *OrphanCleanRule) IsDryRun() bool {
+	return r.DryRun == nil || *r.DryRun
+}
+
+// BurnInPeriod returns how long the rule is forced to dry-run after it is first evaluated.
+// ImagePullSecret rules always have a burn-in and fall back to DefaultImagePullSecretBurnIn.
+func (r *OrphanCleanRule) BurnInPeriod() time.Duration {
+	if r.BurnIn.Duration <= 0 && r.Kind == OrphanKindImagePullSecret {
+		return DefaultImagePullSecretBurnIn
+	}
+	return r.BurnIn.Duration
+}
+
+// Validate checks that the rule targets a supported kind.
+func (r *OrphanCleanRule) Validate() error {
+	if !r.Enabled {
+		return nil
+	}
+
+	if r.Name == "" {
+		return fmt.Errorf("rule name must be provided")
+	}
+
+	if err := validateLane(r.Lane); err != nil {
+		return err
+	}
+
+	if r.TTL.Duration <= 0 {
+		return fmt.Errorf("ttl must be greater than zero")
+	}
+
+	if r.BurnIn.Duration < 0 {
+		return fmt.Errorf("burnIn cannot be negative")
+	}
+
+	if !slices.Contains(orphanKinds, r.Kind) {
+		return fmt.Errorf("kind must be one of %v", orphanKinds)
+	}
+
+	if r.Kind == OrphanKindClusterRoleBinding && len(r.Namespaces) > 0 {
+		return fmt.Errorf("namespaces cannot be set for cluster-scoped kind %s", r.Kind)
+	}
+
+	return nil
+}
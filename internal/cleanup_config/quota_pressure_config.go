@@ -0,0 +1,75 @@
+package cleanupconfig
+
+import (
+	"fmt"
+	"time"
+)
+
+//
+// ResourceQuota Pressure Configuration
+//
+
+// DefaultQuotaPressureThresholdPercent is the usage, as a percentage of a ResourceQuota's hard
+// limit, at which a namespace is under quota pressure when thresholdPercent is unset.
+const DefaultQuotaPressureThresholdPercent = 90
+
+// DefaultQuotaTriggerCooldown is the minimum time between two passes triggered for the same
+// namespace when triggerCooldown is unset.
+const DefaultQuotaTriggerCooldown = time.Minute
+
+// QuotaPressureConfig prioritizes the pods of namespaces close to a ResourceQuota limit, so that
+// cleanup relieves quota pressure where it matters most. A namespace is under pressure when any
+// resource of any of its quotas is used up to the threshold.
+type QuotaPressureConfig struct {
+	Enabled          bool `yaml:"enabled,omitempty"`          // If false, ResourceQuotas do not affect cleanup.
+	ThresholdPercent int  `yaml:"thresholdPercent,omitempty"` // Quota usage, in percent of the hard limit, that counts as pressure; defaults to 90.
+	TTLPercent       int  `yaml:"ttlPercent,omitempty"`       // Scales rule TTLs in namespaces under pressure, e.g. 50 halves them; 0 leaves them unchanged.
+
+	TriggerOnEvents bool     `yaml:"triggerOnEvents,omitempty"` // Runs the pod rules for a namespace as soon as a quota-exceeded event is reported there.
+	TriggerCooldown Duration `yaml:"triggerCooldown,omitempty"` // Minimum time between two triggered passes for the same namespace; defaults to 1m.
+}
+
+// Threshold returns the usage ratio of a quota's hard limit at which a namespace is under pressure.
+func (c *QuotaPressureConfig) Threshold() float64 {
+	if c.ThresholdPercent <= 0 {
+		return DefaultQuotaPressureThresholdPercent / 100.0
+	}
+	return float64(c.ThresholdPercent) / 100
+}
+
+// ScaleTTL returns the TTL a rule applies in namespaces under pressure in place of ttl.
+func (c *QuotaPressureConfig) ScaleTTL(ttl time.Duration) time.Duration {
+	if c.TTLPercent <= 0 {
+		return ttl
+	}
+	return ttl * time.Duration(c.TTLPercent) / 100
+}
+
+// EffectiveTriggerCooldown returns the minimum time between two passes triggered for a namespace.
+func (c *QuotaPressureConfig) EffectiveTriggerCooldown() time.Duration {
+	if c.TriggerCooldown.Duration <= 0 {
+		return DefaultQuotaTriggerCooldown
+	}
+	return c.TriggerCooldown.Duration
+}
+
+// Validate ensures QuotaPressureConfig is correctly configured.
+func (c *QuotaPressureConfig) Validate() error {
+	if !c.Enabled {
+		return nil
+	}
+
+	if c.ThresholdPercent < 0 || c.ThresholdPercent > 100 {
+		return fmt.Errorf("thresholdPercent must be between 0 and 100")
+	}
+
+	if c.TTLPercent < 0 || c.TTLPercent > 100 {
+		return fmt.Errorf("ttlPercent must be between 0 and 100")
+	}
+
+	if c.TriggerCooldown.Duration < 0 {
+		return fmt.Errorf("triggerCooldown must not be negative")
+	}
+
+	return nil
+}
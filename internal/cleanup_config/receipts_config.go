@@ -0,0 +1,57 @@
+package cleanupconfig
+
+import "fmt"
+
+//
+// Deletion Receipts Configuration
+//
+
+// Defaults applied to unset ReceiptsConfig fields.
+const (
+	DefaultReceiptsName = "kubeclean-receipts" // ConfigMap holding a namespace's receipts.
+	DefaultReceiptsKeep = 10                   // Receipts kept per namespace.
+)
+
+// ReceiptsConfig writes a receipt of every pass into each namespace it deleted pods in, so
+// namespace owners can see what was cleaned up without access to kubeclean's logs.
+type ReceiptsConfig struct {
+	Enabled bool   `yaml:"enabled,omitempty"` // If false, no receipts are written.
+	Name    string `yaml:"name,omitempty"`    // Name of the ConfigMap holding the receipts; defaults to kubeclean-receipts.
+	Keep    int    `yaml:"keep,omitempty"`    // Receipts kept per namespace, oldest dropped first; defaults to 10.
+
+	// MaxAge drops receipts older than this, and the ConfigMaps left without receipts, including in
+	// namespaces kubeclean no longer deletes pods in. 0 keeps receipts until keep drops them.
+	MaxAge Duration `yaml:"maxAge,omitempty"`
+}
+
+// ConfigMapName returns the name of the ConfigMap receipts are written to.
+func (c *ReceiptsConfig) ConfigMapName() string {
+	if c.Name == "" {
+		return DefaultReceiptsName
+	}
+	return c.Name
+}
+
+// KeepCount returns the number of receipts kept per namespace.
+func (c *ReceiptsConfig) KeepCount() int {
+	if c.Keep <= 0 {
+		return DefaultReceiptsKeep
+	}
+	return c.Keep
+}
+
+// Validate ensures ReceiptsConfig is correctly configured.
+func (c *ReceiptsConfig) Validate() error {
+	if !c.Enabled {
+		return nil
+	}
+
+	if c.Keep < 0 {
+		return fmt.Errorf("keep cannot be negative")
+	}
+	if c.MaxAge.Duration < 0 {
+		return fmt.Errorf("maxAge cannot be negative")
+	}
+
+	return nil
+}
@@ -0,0 +1,79 @@
+package cleanupconfig
+
+import (
+	"slices"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+//
+// Recommended Pod Rules
+//
+
+// Names of the recommended rules added by useRecommendedDefaults. A configured rule with the same
+// name replaces the recommended one, e.g. to change its TTL or disable it.
+const (
+	RecommendedRuleSucceeded = "recommended-succeeded"
+	RecommendedRuleFailed    = "recommended-failed"
+	RecommendedRuleEvicted   = "recommended-evicted"
+)
+
+// SystemNamespaces are the namespaces Kubernetes itself creates, which the recommended rules
+// leave alone.
+var SystemNamespaces = []string{"kube-system", "kube-public", "kube-node-lease"}
+
+// RecommendedRules returns the rules useRecommendedDefaults adds: Succeeded pods after 24 hours,
+// Failed pods after 72 hours and evicted pods after an hour, outside the system namespaces.
+func RecommendedRules() []PodCleanRule {
+	outsideSystem := func() *metav1.LabelSelector {
+		return &metav1.LabelSelector{MatchExpressions: []metav1.LabelSelectorRequirement{{
+			Key:      corev1.LabelMetadataName,
+			Operator: metav1.LabelSelectorOpNotIn,
+			Values:   slices.Clone(SystemNamespaces),
+		}}}
+	}
+
+	return []PodCleanRule{
+		{
+			Name:              RecommendedRuleSucceeded,
+			Enabled:           true,
+			Phase:             "Succeeded",
+			TTL:               Duration{Duration: 24 * time.Hour},
+			NamespaceSelector: outsideSystem(),
+		},
+		{
+			Name:              RecommendedRuleFailed,
+			Enabled:           true,
+			Phase:             "Failed",
+			TTL:               Duration{Duration: 72 * time.Hour},
+			NamespaceSelector: outsideSystem(),
+		},
+		{
+			Name:    RecommendedRuleEvicted,
+			Enabled: true,
+			Match: &MatchCriteria{All: []MatchCondition{
+				{Phase: "Failed", Reason: "Evicted"},
+			}},
+			TTL:               Duration{Duration: time.Hour},
+			NamespaceSelector: outsideSystem(),
+		},
+	}
+}
+
+// EffectiveRules returns the configured rules followed, with useRecommendedDefaults, by the
+// recommended rules no configured rule replaces by name.
+func (p *PodCleanupConfig) EffectiveRules() []PodCleanRule {
+	if !p.UseRecommendedDefaults {
+		return p.Rules
+	}
+
+	rules := slices.Clone(p.Rules)
+	for _, recommended := range RecommendedRules() {
+		if !slices.ContainsFunc(p.Rules, func(rule PodCleanRule) bool { return rule.Name == recommended.Name }) {
+			rules = append(rules, recommended)
+		}
+	}
+	return rules
+}
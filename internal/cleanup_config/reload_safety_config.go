@@ -0,0 +1,67 @@
+package cleanupconfig
+
+import (
+	"fmt"
+	"math"
+)
+
+//
+// Reload Safety Configuration
+//
+
+// Defaults applied to unset ReloadSafetyConfig fields.
+const (
+	DefaultReloadMaxChangeFactor = 3.0 // Multiple by which a reload may change the matched pods.
+	DefaultReloadMinMatches      = 10  // Matched pods below which a reload is never held back.
+)
+
+// ReloadSafetyConfig holds back reloaded configs that would change the number of matched pods by
+// more than MaxChangeFactor, in either direction, until they are approved through the admin API.
+// The active config's settings apply, so a reload cannot switch its own check off.
+type ReloadSafetyConfig struct {
+	Enabled         bool    `yaml:"enabled,omitempty"`         // If false, every valid reload is applied.
+	MaxChangeFactor float64 `yaml:"maxChangeFactor,omitempty"` // Largest allowed ratio of matched pods after and before; defaults to 3.
+	MinMatches      int     `yaml:"minMatches,omitempty"`      // Reloads matching fewer pods before and after are applied; defaults to 10.
+
+	NotificationSinks []string `yaml:"notificationSinks,omitempty"` // Sinks alerted of held configs; defaults to all sinks.
+}
+
+// ChangeFactor returns the largest allowed ratio of matched pods after and before a reload.
+func (c *ReloadSafetyConfig) ChangeFactor() float64 {
+	if c.MaxChangeFactor <= 0 {
+		return DefaultReloadMaxChangeFactor
+	}
+	return c.MaxChangeFactor
+}
+
+// MinimumMatches returns the matched pods below which a reload is never held back.
+func (c *ReloadSafetyConfig) MinimumMatches() int {
+	if c.MinMatches <= 0 {
+		return DefaultReloadMinMatches
+	}
+	return c.MinMatches
+}
+
+// Exceeds reports whether going from active to candidate matched pods changes behavior by more
+// than the allowed factor.
+func (c *ReloadSafetyConfig) Exceeds(active, candidate int) bool {
+	larger, smaller := max(active, candidate), min(active, candidate)
+	return larger >= c.MinimumMatches() && float64(larger) > c.ChangeFactor()*math.Max(float64(smaller), 1)
+}
+
+// Validate ensures ReloadSafetyConfig is correctly configured.
+func (c *ReloadSafetyConfig) Validate() error {
+	if !c.Enabled {
+		return nil
+	}
+
+	if c.MaxChangeFactor < 0 || (c.MaxChangeFactor > 0 && c.MaxChangeFactor <= 1) {
+		return fmt.Errorf("maxChangeFactor must be greater than 1")
+	}
+
+	if c.MinMatches < 0 {
+		return fmt.Errorf("minMatches cannot be negative")
+	}
+
+	return nil
+}
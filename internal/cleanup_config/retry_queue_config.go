@@ -0,0 +1,55 @@
+package cleanupconfig
+
+import (
+	"fmt"
+	"strings"
+)
+
+//
+// Retry Queue Configuration
+//
+
+// DefaultRetryMaxAttempts is the number of failed deletions after which a queued pod is dead-lettered.
+const DefaultRetryMaxAttempts = 3
+
+// RetryQueueConfig retries pods whose deletion failed with a transient error, such as a timeout
+// or throttling, at the start of the next runs ahead of new candidates. Pods still failing after
+// maxAttempts are moved to a dead-letter report.
+type RetryQueueConfig struct {
+	Enabled     bool   `yaml:"enabled,omitempty"`     // If false, failed deletions are only retried when their rule matches again.
+	MaxAttempts int    `yaml:"maxAttempts,omitempty"` // Failed deletions before a pod is dead-lettered; defaults to 3.
+	ConfigMap   string `yaml:"configMap,omitempty"`   // "namespace/name" of a ConfigMap persisting the queue across restarts.
+}
+
+// Attempts returns the number of failed deletions after which a pod is dead-lettered.
+func (c *RetryQueueConfig) Attempts() int {
+	if c.MaxAttempts <= 0 {
+		return DefaultRetryMaxAttempts
+	}
+	return c.MaxAttempts
+}
+
+// ConfigMapKey returns the namespace and name of the ConfigMap persisting the queue, if any.
+func (c *RetryQueueConfig) ConfigMapKey() (namespace, name string, ok bool) {
+	namespace, name, ok = strings.Cut(c.ConfigMap, "/")
+	return namespace, name, ok && namespace != "" && name != ""
+}
+
+// Validate ensures RetryQueueConfig is correctly configured.
+func (c *RetryQueueConfig) Validate() error {
+	if !c.Enabled {
+		return nil
+	}
+
+	if c.MaxAttempts < 0 {
+		return fmt.Errorf("maxAttempts cannot be negative")
+	}
+
+	if c.ConfigMap != "" {
+		if _, _, ok := c.ConfigMapKey(); !ok {
+			return fmt.Errorf("configMap must be of the form namespace/name, got %q", c.ConfigMap)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,76 @@
+package cleanupconfig
+
+import "fmt"
+
+//
+// Rule Cooldown Configuration
+//
+
+// Defaults applied to unset RuleCooldownConfig fields.
+const (
+	DefaultCooldownFailureRatio = 0.5 // Share of a run's attempts that must fail.
+	DefaultCooldownMinAttempts  = 5   // Attempts below which a run is not judged.
+	DefaultCooldownRuns         = 3   // Runs a rule in cooldown is skipped for.
+)
+
+// RuleCooldownConfig puts pod rules whose actions mostly fail, e.g. against a broken admission
+// webhook or a missing RBAC permission, in cooldown: they are skipped for a number of runs and
+// an alert is sent, instead of failing the same way every interval.
+type RuleCooldownConfig struct {
+	Enabled      bool    `yaml:"enabled,omitempty"`      // If false, failing rules run every interval.
+	FailureRatio float64 `yaml:"failureRatio,omitempty"` // Share of a run's attempted actions and pod lists that must fail, up to 1; defaults to 0.5.
+	MinAttempts  int     `yaml:"minAttempts,omitempty"`  // Attempts below which a run does not start a cooldown; defaults to 5.
+	Runs         int     `yaml:"runs,omitempty"`         // Runs a rule in cooldown is skipped for; defaults to 3.
+
+	NotificationSinks []string `yaml:"notificationSinks,omitempty"` // Sinks alerted of cooldowns; defaults to the rule's sinks.
+}
+
+// Ratio returns the share of a run's attempts that must fail to start a cooldown.
+func (c *RuleCooldownConfig) Ratio() float64 {
+	if c.FailureRatio <= 0 {
+		return DefaultCooldownFailureRatio
+	}
+	return c.FailureRatio
+}
+
+// MinimumAttempts returns the number of attempts below which a run does not start a cooldown.
+func (c *RuleCooldownConfig) MinimumAttempts() int {
+	if c.MinAttempts <= 0 {
+		return DefaultCooldownMinAttempts
+	}
+	return c.MinAttempts
+}
+
+// CooldownRuns returns the number of runs a rule in cooldown is skipped for.
+func (c *RuleCooldownConfig) CooldownRuns() int {
+	if c.Runs <= 0 {
+		return DefaultCooldownRuns
+	}
+	return c.Runs
+}
+
+// Trips reports whether a run in which failed of attempted actions failed starts a cooldown.
+func (c *RuleCooldownConfig) Trips(attempted, failed int) bool {
+	return c.Enabled && attempted >= c.MinimumAttempts() && float64(failed) >= c.Ratio()*float64(attempted)
+}
+
+// Validate ensures RuleCooldownConfig is correctly configured.
+func (c *RuleCooldownConfig) Validate() error {
+	if !c.Enabled {
+		return nil
+	}
+
+	if c.FailureRatio < 0 || c.FailureRatio > 1 {
+		return fmt.Errorf("failureRatio must be between 0 and 1")
+	}
+
+	if c.MinAttempts < 0 {
+		return fmt.Errorf("minAttempts cannot be negative")
+	}
+
+	if c.Runs < 0 {
+		return fmt.Errorf("runs cannot be negative")
+	}
+
+	return nil
+}
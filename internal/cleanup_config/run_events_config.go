@@ -0,0 +1,37 @@
+package cleanupconfig
+
+import (
+	"fmt"
+	"strings"
+)
+
+//
+// Run Events Configuration
+//
+
+// RunEventsConfig reports every run as a Kubernetes Event on kubeclean's own Deployment, so recent
+// activity shows in kubectl describe without access to its logs or metrics.
+type RunEventsConfig struct {
+	Enabled    bool   `yaml:"enabled,omitempty"`    // If false, runs are not reported as Events.
+	Deployment string `yaml:"deployment,omitempty"` // "namespace/name" of kubeclean's Deployment.
+	Annotate   bool   `yaml:"annotate,omitempty"`   // Also record the last run's summary in the kubeclean.io/last-run annotation.
+}
+
+// DeploymentKey returns the namespace and name of the Deployment runs are reported on.
+func (c *RunEventsConfig) DeploymentKey() (namespace, name string, ok bool) {
+	namespace, name, ok = strings.Cut(c.Deployment, "/")
+	return namespace, name, ok && namespace != "" && name != ""
+}
+
+// Validate ensures RunEventsConfig is correctly configured.
+func (c *RunEventsConfig) Validate() error {
+	if !c.Enabled {
+		return nil
+	}
+
+	if _, _, ok := c.DeploymentKey(); !ok {
+		return fmt.Errorf("deployment must be of the form namespace/name, got %q", c.Deployment)
+	}
+
+	return nil
+}
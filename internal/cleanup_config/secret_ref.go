@@ -0,0 +1,36 @@
+package cleanupconfig
+
+import (
+	"fmt"
+	"strings"
+)
+
+//
+// Secret References
+//
+
+// SecretKeyRef references the key of a Secret holding a credential, keeping it out of the config
+// file. The Secret is read whenever the credential is used, so rotations apply without a reload.
+type SecretKeyRef struct {
+	Secret string `yaml:"secret,omitempty"` // "namespace/name" of the Secret.
+	Key    string `yaml:"key,omitempty"`    // Key of the Secret's data holding the value.
+}
+
+// SecretKey returns the namespace and name of the referenced Secret.
+func (r *SecretKeyRef) SecretKey() (namespace, name string, ok bool) {
+	namespace, name, ok = strings.Cut(r.Secret, "/")
+	return namespace, name, ok && namespace != "" && name != ""
+}
+
+// Validate ensures SecretKeyRef names a Secret and a key.
+func (r *SecretKeyRef) Validate() error {
+	if _, _, ok := r.SecretKey(); !ok {
+		return fmt.Errorf("secret must be of the form namespace/name, got %q", r.Secret)
+	}
+
+	if r.Key == "" {
+		return fmt.Errorf("key must be set")
+	}
+
+	return nil
+}
@@ -0,0 +1,80 @@
+package cleanupconfig
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+//
+// Label Selector Helper for YAML Parsing
+//
+
+// yamlLabelSelector mirrors metav1.LabelSelector with YAML tags. The upstream type only carries
+// JSON tags, so decoding it directly would expect lower-cased keys such as "matchlabels".
+type yamlLabelSelector struct {
+	MatchLabels      map[string]string         `yaml:"matchLabels,omitempty"`
+	MatchExpressions []yamlSelectorRequirement `yaml:"matchExpressions,omitempty"`
+}
+
+// yamlSelectorRequirement mirrors metav1.LabelSelectorRequirement with YAML tags.
+type yamlSelectorRequirement struct {
+	Key      string   `yaml:"key"`
+	Operator string   `yaml:"operator"`
+	Values   []string `yaml:"values,omitempty"`
+}
+
+// UnmarshalYAML rejects unknown keys. A misspelled key such as "matchlabels" would otherwise be
+// dropped, leaving an empty selector that matches every pod.
+func (s *yamlLabelSelector) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	if err := checkKeys(unmarshal, "selector", "matchLabels", "matchExpressions"); err != nil {
+		return err
+	}
+	type plain yamlLabelSelector
+	return unmarshal((*plain)(s))
+}
+
+// UnmarshalYAML rejects unknown keys, such as a misspelled "values" that would empty the requirement.
+func (r *yamlSelectorRequirement) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	if err := checkKeys(unmarshal, "matchExpressions entry", "key", "operator", "values"); err != nil {
+		return err
+	}
+	type plain yamlSelectorRequirement
+	return unmarshal((*plain)(r))
+}
+
+// checkKeys returns an error naming the first key, in sorted order, of the mapping being
+// unmarshalled that is not one of known.
+func checkKeys(unmarshal func(interface{}) error, what string, known ...string) error {
+	var fields map[string]interface{}
+	if err := unmarshal(&fields); err != nil {
+		return err
+	}
+	for _, key := range slices.Sorted(maps.Keys(fields)) {
+		if !slices.Contains(known, key) {
+			return fmt.Errorf("unknown %s field %q; expected one of %s", what, key, strings.Join(known, ", "))
+		}
+	}
+	return nil
+}
+
+// toLabelSelector converts the YAML representation into a metav1.LabelSelector; nil stays nil.
+func (s *yamlLabelSelector) toLabelSelector() *metav1.LabelSelector {
+	if s == nil {
+		return nil
+	}
+
+	selector := &metav1.LabelSelector{MatchLabels: s.MatchLabels}
+	for _, expr := range s.MatchExpressions {
+		selector.MatchExpressions = append(selector.MatchExpressions, metav1.LabelSelectorRequirement{
+			Key:      expr.Key,
+			Operator: metav1.LabelSelectorOperator(expr.Operator),
+			Values:   expr.Values,
+		})
+	}
+
+	return selector
+}
@@ -0,0 +1,109 @@
+package cleanupconfig
+
+import (
+	"fmt"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+//
+// Stale CronJob Configuration
+//
+
+// ActionSuspend is the action of stale CronJob rules. It is not a pod action.
+const ActionSuspend = "suspend"
+
+// StaleCronJobConfig defines rules that suspend CronJobs which stopped succeeding.
+type StaleCronJobConfig struct {
+	Enabled bool               `yaml:"enabled,omitempty"` // If false, stale CronJobs are left alone.
+	Rules   []StaleCronJobRule `yaml:"rules,omitempty"`   // List of rules for detecting stale CronJobs.
+}
+
+// Validate ensures StaleCronJobConfig is correctly configured.
+func (c *StaleCronJobConfig) Validate() error {
+	if !c.Enabled {
+		return nil
+	}
+
+	var errorMessages string
+
+	for idx, rule := range c.Rules {
+		if err := rule.Validate(); err != nil {
+			errorMessages += fmt.Sprintf("rule %d (%s): %v\n", idx+1, rule.Name, err)
+		}
+	}
+
+	if errorMessages == "" {
+		return nil
+	}
+
+	return fmt.Errorf("stale cronjob config validation errors:\n%s", errorMessages)
+}
+
+// StaleCronJobRule suspends CronJobs that have not succeeded for too long or whose Jobs keep
+// failing. A CronJob is stale when either configured threshold is reached.
+type StaleCronJobRule struct {
+	Name                   string                `yaml:"name"`                             // Unique name of the rule for identification.
+	Enabled                bool                  `yaml:"enabled,omitempty"`                // If false, the rule is skipped during processing.
+	Lane                   string                `yaml:"lane,omitempty"`                   // fast (default) or slow; see LanesConfig.
+	Namespaces             []string              `yaml:"namespaces,omitempty"`             // Specific namespaces where the rule applies.
+	Selector               *metav1.LabelSelector `yaml:"-"`                                // Only CronJobs with these labels are considered.
+	MaxSinceSuccess        Duration              `yaml:"maxSinceSuccess,omitempty"`        // Suspend CronJobs without a successful run for this long; 0 disables the check.
+	MaxConsecutiveFailures int                   `yaml:"maxConsecutiveFailures,omitempty"` // Suspend CronJobs whose last N Jobs failed; 0 disables the check.
+	NotifyOwners           bool                  `yaml:"notifyOwners,omitempty"`           // Notify the owner of each namespace where CronJobs were suspended.
+
+	NotificationSinks []string `yaml:"notificationSinks,omitempty"` // Sinks notified for this rule; defaults to all global sinks.
+}
+
+// UnmarshalYAML decodes a StaleCronJobRule, translating its label selector from its YAML form.
+func (r *StaleCronJobRule) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	type plain StaleCronJobRule
+	if err := unmarshal((*plain)(r)); err != nil {
+		return err
+	}
+
+	var selectors struct {
+		Selector *yamlLabelSelector `yaml:"selector"`
+	}
+	if err := unmarshal(&selectors); err != nil {
+		return err
+	}
+	r.Selector = selectors.Selector.toLabelSelector()
+
+	return nil
+}
+
+// Validate checks that the rule sets at least one threshold.
+func (r *StaleCronJobRule) Validate() error {
+	if !r.Enabled {
+		return nil
+	}
+
+	if r.Name == "" {
+		return fmt.Errorf("rule name must be provided")
+	}
+
+	if err := validateLane(r.Lane); err != nil {
+		return err
+	}
+
+	if r.MaxSinceSuccess.Duration < 0 {
+		return fmt.Errorf("maxSinceSuccess cannot be negative")
+	}
+
+	if r.MaxConsecutiveFailures < 0 {
+		return fmt.Errorf("maxConsecutiveFailures cannot be negative")
+	}
+
+	if r.MaxSinceSuccess.Duration == 0 && r.MaxConsecutiveFailures == 0 {
+		return fmt.Errorf("either 'maxSinceSuccess' or 'maxConsecutiveFailures' must be specified")
+	}
+
+	if r.Selector != nil {
+		if _, err := metav1.LabelSelectorAsSelector(r.Selector); err != nil {
+			return fmt.Errorf("invalid selector: %w", err)
+		}
+	}
+
+	return nil
+}
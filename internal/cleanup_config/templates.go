@@ -0,0 +1,67 @@
+package cleanupconfig
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"text/template"
+	"time"
+)
+
+//
+// Notification Templates
+//
+
+// TemplateFuncs are the functions available to notification templates, in addition to Go's
+// built-ins. They are named after their sprig counterparts, so common chatops templates carry over.
+var TemplateFuncs = template.FuncMap{
+	"toJson": func(v any) (string, error) {
+		data, err := json.Marshal(v)
+		return string(data), err
+	},
+	"upper":     strings.ToUpper,
+	"lower":     strings.ToLower,
+	"trim":      strings.TrimSpace,
+	"replace":   func(old, new, s string) string { return strings.ReplaceAll(s, old, new) },
+	"contains":  func(substr, s string) bool { return strings.Contains(s, substr) },
+	"hasPrefix": func(prefix, s string) bool { return strings.HasPrefix(s, prefix) },
+	"join": func(sep string, v any) string {
+		var items []string
+		value := reflect.ValueOf(v)
+		if value.Kind() == reflect.Slice || value.Kind() == reflect.Array {
+			for i := range value.Len() {
+				items = append(items, fmt.Sprint(value.Index(i).Interface()))
+			}
+		}
+		return strings.Join(items, sep)
+	},
+	"trunc": func(n int, s string) string {
+		if runes := []rune(s); len(runes) > n {
+			return string(runes[:n])
+		}
+		return s
+	},
+	"default": func(fallback, v any) any {
+		if v == nil || reflect.ValueOf(v).IsZero() {
+			return fallback
+		}
+		return v
+	},
+	"keys": func(m map[string]int) []string {
+		keys := make([]string, 0, len(m))
+		for key := range m {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		return keys
+	},
+	"date": func(layout string, t time.Time) string { return t.Format(layout) },
+	"now":  time.Now,
+}
+
+// ParseTemplate parses a notification template with TemplateFuncs.
+func ParseTemplate(name, text string) (*template.Template, error) {
+	return template.New(name).Funcs(TemplateFuncs).Parse(text)
+}
@@ -0,0 +1,54 @@
+package cleanupconfig
+
+import (
+	"fmt"
+	"slices"
+)
+
+//
+// Tenant Rules Configuration
+//
+
+// TenantRulesConfig enables namespaced CleanupRule resources, letting tenants define pod rules
+// for their own namespaces within bounds set by the administrator.
+type TenantRulesConfig struct {
+	Enabled        bool     `yaml:"enabled,omitempty"`        // If false, CleanupRule resources are ignored.
+	MinTTL         Duration `yaml:"minTTL,omitempty"`         // Tenant rules with a shorter TTL are rejected.
+	AllowedActions []string `yaml:"allowedActions,omitempty"` // Actions tenant rules may take; defaults to delete only.
+}
+
+// PermittedActions returns the actions tenant rules may take.
+func (c *TenantRulesConfig) PermittedActions() []string {
+	if len(c.AllowedActions) == 0 {
+		return []string{ActionDelete}
+	}
+	return c.AllowedActions
+}
+
+// CheckBounds reports why a tenant rule falls outside the configured bounds, or nil when it does not.
+func (c *TenantRulesConfig) CheckBounds(rule PodCleanRule) error {
+	if rule.TTL.Duration < c.MinTTL.Duration {
+		return fmt.Errorf("ttl %s is below the minimum of %s", rule.TTL.Duration, c.MinTTL.Duration)
+	}
+
+	for _, action := range rule.Actions() {
+		if !slices.Contains(c.PermittedActions(), action) {
+			return fmt.Errorf("action %q is not allowed", action)
+		}
+	}
+
+	return nil
+}
+
+// Validate ensures TenantRulesConfig is correctly configured.
+func (c *TenantRulesConfig) Validate() error {
+	if !c.Enabled {
+		return nil
+	}
+
+	if c.MinTTL.Duration < 0 {
+		return fmt.Errorf("minTTL cannot be negative")
+	}
+
+	return validateActionNames("allowedActions", c.AllowedActions)
+}
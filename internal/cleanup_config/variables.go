@@ -0,0 +1,142 @@
+package cleanupconfig
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"gopkg.in/yaml.v2"
+)
+
+//
+// Config Variables
+//
+
+// VariableLookup resolves the name of a ${source:name} substitution. ok is false for undefined names.
+type VariableLookup func(name string) (value string, ok bool)
+
+// variableSources resolve substitutions by source; ${NAME} is short for ${env:NAME}.
+var variableSources = map[string]VariableLookup{
+	"env": os.LookupEnv,
+	"pod": lookupPodField,
+}
+
+// RegisterVariableSource makes ${source:name} substitutions in config values resolve through
+// lookup, replacing any source of that name. Sources must be registered before configs are
+// loaded, e.g. from an init function.
+func RegisterVariableSource(source string, lookup VariableLookup) {
+	variableSources[source] = lookup
+}
+
+// podFieldEnv maps the fields of the pod source to the environment variables the chart sets
+// from the downward API.
+var podFieldEnv = map[string]string{
+	"name":               "POD_NAME",
+	"namespace":          "POD_NAMESPACE",
+	"nodeName":           "NODE_NAME",
+	"serviceAccountName": "POD_SERVICE_ACCOUNT",
+}
+
+// serviceAccountNamespaceFile holds the namespace of the pod's service account in-cluster.
+const serviceAccountNamespaceFile = "/var/run/secrets/kubernetes.io/serviceaccount/namespace"
+
+// lookupPodField resolves fields of kubeclean's own pod, as exposed by the downward API. The
+// namespace falls back to the service account's namespace.
+func lookupPodField(field string) (string, bool) {
+	env, known := podFieldEnv[field]
+	if !known {
+		return "", false
+	}
+	if value, ok := os.LookupEnv(env); ok {
+		return value, true
+	}
+	if field == "namespace" {
+		if data, err := os.ReadFile(serviceAccountNamespaceFile); err == nil {
+			return strings.TrimSpace(string(data)), true
+		}
+	}
+	return "", false
+}
+
+// expandVariables substitutes the ${...} references in every string value of doc, in place.
+// Keys are left as they are.
+func expandVariables(doc yaml.MapSlice) error {
+	for i := range doc {
+		value, err := expandValue(doc[i].Value, fmt.Sprint(doc[i].Key))
+		if err != nil {
+			return err
+		}
+		doc[i].Value = value
+	}
+	return nil
+}
+
+// expandValue expands the strings of value found at path.
+func expandValue(value interface{}, path string) (interface{}, error) {
+	switch v := value.(type) {
+	case string:
+		expanded, err := expandString(v)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+		return expanded, nil
+	case yaml.MapSlice:
+		for i := range v {
+			expanded, err := expandValue(v[i].Value, fmt.Sprintf("%s.%v", path, v[i].Key))
+			if err != nil {
+				return nil, err
+			}
+			v[i].Value = expanded
+		}
+	case []interface{}:
+		for i := range v {
+			expanded, err := expandValue(v[i], fmt.Sprintf("%s[%d]", path, i))
+			if err != nil {
+				return nil, err
+			}
+			v[i] = expanded
+		}
+	}
+	return value, nil
+}
+
+// expandString substitutes the ${source:name} and ${NAME} references in s. $${ escapes a
+// literal ${. Undefined variables are an error rather than an empty string, so that a config
+// meant for another cluster fails to load instead of matching the wrong resources.
+func expandString(s string) (string, error) {
+	var expanded strings.Builder
+	for {
+		start := strings.Index(s, "${")
+		if start < 0 {
+			expanded.WriteString(s)
+			return expanded.String(), nil
+		}
+		if start > 0 && s[start-1] == '$' {
+			expanded.WriteString(s[:start-1] + "${")
+			s = s[start+2:]
+			continue
+		}
+
+		length := strings.IndexByte(s[start:], '}')
+		if length < 0 {
+			return "", fmt.Errorf("unterminated variable reference in %q", s)
+		}
+		reference := s[start+2 : start+length]
+
+		source, name, found := strings.Cut(reference, ":")
+		if !found {
+			source, name = "env", reference
+		}
+		lookup, ok := variableSources[source]
+		if !ok {
+			return "", fmt.Errorf("unknown variable source %q in ${%s}", source, reference)
+		}
+		value, ok := lookup(name)
+		if !ok {
+			return "", fmt.Errorf("undefined variable ${%s}", reference)
+		}
+
+		expanded.WriteString(s[:start] + value)
+		s = s[start+length+1:]
+	}
+}
@@ -0,0 +1,196 @@
+package controller
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+
+	cleanupconfig "github.com/infrautils/kubeclean/internal/cleanup_config"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	policyv1 "k8s.io/api/policy/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// Action is what a rule does to each pod it selects. The batch engine applies it pod by pod and
+// handles batching, pauses, dry runs and error reporting, so new behaviors only implement this.
+type Action interface {
+	// Name is the action's name in the config, e.g. "delete".
+	Name() string
+	// Apply acts on pod. A NotFound error means the pod is already gone and is not a failure.
+	Apply(ctx context.Context, k8sClient client.Client, pod *corev1.Pod) error
+	// Applied reports whether pod already reflects the action, so it is not selected again.
+	Applied(pod *corev1.Pod) bool
+	// Removes reports whether applying the action removes the pod, which makes it a deletion for
+	// receipts, recent deletions, owner cascades and the retry queue.
+	Removes() bool
+}
+
+// newAction returns the action a rule's config selects. Actions keep state for a single run.
+func newAction(cfg cleanupconfig.ActionConfig) Action {
+	switch cfg.EffectiveType() {
+	case cleanupconfig.ActionEvict:
+		return evictAction{}
+	case cleanupconfig.ActionLabelQuarantine:
+		return labelAction{labels: cfg.QuarantineLabels()}
+	case cleanupconfig.ActionScaleToZero:
+		return &scaleToZeroAction{scaled: map[Owner]bool{}}
+	case cleanupconfig.ActionAnnotatePatch:
+		return annotateAction{annotations: cfg.Annotations}
+	default:
+		return deleteAction{}
+	}
+}
+
+// deleteAction deletes pods.
+type deleteAction struct{}
+
+func (deleteAction) Name() string             { return cleanupconfig.ActionDelete }
+func (deleteAction) Applied(*corev1.Pod) bool { return false }
+func (deleteAction) Removes() bool            { return true }
+func (deleteAction) Apply(ctx context.Context, k8sClient client.Client, pod *corev1.Pod) error {
+	return k8sClient.Delete(ctx, pod)
+}
+
+// evictAction evicts pods through the Eviction API, so PodDisruptionBudgets are honored. An
+// eviction a budget blocks fails with TooManyRequests and is attempted again on the next run.
+type evictAction struct{}
+
+func (evictAction) Name() string             { return cleanupconfig.ActionEvict }
+func (evictAction) Applied(*corev1.Pod) bool { return false }
+func (evictAction) Removes() bool            { return true }
+func (evictAction) Apply(ctx context.Context, k8sClient client.Client, pod *corev1.Pod) error {
+	eviction := &policyv1.Eviction{ObjectMeta: metav1.ObjectMeta{Namespace: pod.Namespace, Name: pod.Name}}
+	return k8sClient.SubResource("eviction").Create(ctx, pod, eviction)
+}
+
+// labelAction sets labels on pods, e.g. to quarantine them for inspection.
+type labelAction struct {
+	labels map[string]string
+}
+
+func (labelAction) Name() string  { return cleanupconfig.ActionLabelQuarantine }
+func (labelAction) Removes() bool { return false }
+
+func (a labelAction) Applied(pod *corev1.Pod) bool {
+	return hasAll(pod.Labels, a.labels)
+}
+
+func (a labelAction) Apply(ctx context.Context, k8sClient client.Client, pod *corev1.Pod) error {
+	return mergePatchMetadata(ctx, k8sClient, pod, "labels", a.labels)
+}
+
+// annotateAction merges annotations into pods.
+type annotateAction struct {
+	annotations map[string]string
+}
+
+func (annotateAction) Name() string  { return cleanupconfig.ActionAnnotatePatch }
+func (annotateAction) Removes() bool { return false }
+
+func (a annotateAction) Applied(pod *corev1.Pod) bool {
+	return hasAll(pod.Annotations, a.annotations)
+}
+
+func (a annotateAction) Apply(ctx context.Context, k8sClient client.Client, pod *corev1.Pod) error {
+	return mergePatchMetadata(ctx, k8sClient, pod, "annotations", a.annotations)
+}
+
+// scaleToZeroAction scales the workload owning each pod to zero replicas, leaving the pods to
+// their controller. Deployments are scaled rather than their ReplicaSets, which they would scale
+// back up. Each owner is scaled once per run, however many of its pods are selected.
+type scaleToZeroAction struct {
+	resolver *ownerResolver
+	scaled   map[Owner]bool
+}
+
+func (*scaleToZeroAction) Name() string             { return cleanupconfig.ActionScaleToZero }
+func (*scaleToZeroAction) Applied(*corev1.Pod) bool { return false }
+func (*scaleToZeroAction) Removes() bool            { return false }
+
+func (a *scaleToZeroAction) Apply(ctx context.Context, k8sClient client.Client, pod *corev1.Pod) error {
+	if a.resolver == nil {
+		a.resolver = newOwnerResolver(k8sClient)
+	}
+	owner := a.resolver.resolve(ctx, pod)
+	if a.scaled[owner] {
+		return nil
+	}
+
+	var workload client.Object
+	switch owner.Kind {
+	case "Deployment":
+		workload = &appsv1.Deployment{}
+	case "StatefulSet":
+		workload = &appsv1.StatefulSet{}
+	case "ReplicaSet":
+		workload = &appsv1.ReplicaSet{}
+	default:
+		return fmt.Errorf("pod %s/%s has no owner that can be scaled to zero", pod.Namespace, pod.Name)
+	}
+	// Fetch the workload so its current replicas are recorded in scaledFromAnnotation.
+	if err := k8sClient.Get(ctx, types.NamespacedName{Namespace: owner.Namespace, Name: owner.Name}, workload); err != nil {
+		return fmt.Errorf("get %s %s/%s: %w", owner.Kind, owner.Namespace, owner.Name, err)
+	}
+
+	if err := scaleToZero(ctx, k8sClient, workload); err != nil {
+		return err
+	}
+	a.scaled[owner] = true
+	return nil
+}
+
+// scaledFromAnnotation records the replicas a workload had before kubeclean scaled it to zero, so
+// it can be scaled back up.
+const scaledFromAnnotation = "kubeclean.io/scaled-from"
+
+// scaleToZero patches workload, a Deployment, StatefulSet or ReplicaSet, to zero replicas. When
+// workload carries its current replica count, the count is kept in scaledFromAnnotation.
+func scaleToZero(ctx context.Context, k8sClient client.Client, workload client.Object) error {
+	patch := map[string]any{"spec": map[string]any{"replicas": 0}}
+	if replicas := specReplicas(workload); replicas != nil && *replicas > 0 {
+		patch["metadata"] = map[string]any{"annotations": map[string]string{scaledFromAnnotation: strconv.Itoa(int(*replicas))}}
+	}
+	return mergePatch(ctx, k8sClient, workload, patch)
+}
+
+// specReplicas returns the desired replicas of a scalable workload, or nil when they are unset.
+func specReplicas(workload client.Object) *int32 {
+	switch w := workload.(type) {
+	case *appsv1.Deployment:
+		return w.Spec.Replicas
+	case *appsv1.StatefulSet:
+		return w.Spec.Replicas
+	case *appsv1.ReplicaSet:
+		return w.Spec.Replicas
+	default:
+		return nil
+	}
+}
+
+// mergePatchMetadata merges values into the labels or annotations of pod.
+func mergePatchMetadata(ctx context.Context, k8sClient client.Client, pod *corev1.Pod, field string, values map[string]string) error {
+	return mergePatch(ctx, k8sClient, pod, map[string]any{"metadata": map[string]any{field: values}})
+}
+
+// mergePatch applies patch to obj as a JSON merge patch.
+func mergePatch(ctx context.Context, k8sClient client.Client, obj client.Object, patch map[string]any) error {
+	data, err := json.Marshal(patch)
+	if err != nil {
+		return err
+	}
+	return k8sClient.Patch(ctx, obj, client.RawPatch(types.MergePatchType, data))
+}
+
+// hasAll reports whether every key of want is set to its value in have.
+func hasAll(have, want map[string]string) bool {
+	for key, value := range want {
+		if current, ok := have[key]; !ok || current != value {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,118 @@
+package controller
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	cleanupconfig "github.com/infrautils/kubeclean/internal/cleanup_config"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestRunCleanUp_Actions(t *testing.T) {
+	scheme := runtime.NewScheme()
+	_ = corev1.AddToScheme(scheme)
+	_ = appsv1.AddToScheme(scheme)
+
+	newPod := func(name string, labels map[string]string, owners ...metav1.OwnerReference) *corev1.Pod {
+		return &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:              name,
+				Namespace:         "default",
+				UID:               types.UID(name),
+				Labels:            labels,
+				OwnerReferences:   owners,
+				CreationTimestamp: metav1.NewTime(time.Now().Add(-2 * time.Hour)),
+			},
+			Status: corev1.PodStatus{Phase: corev1.PodFailed},
+		}
+	}
+	isController := true
+	controllerRef := func(kind, name string) metav1.OwnerReference {
+		return metav1.OwnerReference{Kind: kind, Name: name, Controller: &isController}
+	}
+	replicas := int32(3)
+
+	deployment := &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "default"},
+		Spec:       appsv1.DeploymentSpec{Replicas: &replicas},
+	}
+	replicaSet := &appsv1.ReplicaSet{
+		ObjectMeta: metav1.ObjectMeta{Name: "web-abc", Namespace: "default", OwnerReferences: []metav1.OwnerReference{controllerRef("Deployment", "web")}},
+	}
+
+	client := fake.NewClientBuilder().WithScheme(scheme).WithRuntimeObjects(
+		deployment, replicaSet,
+		newPod("crashed", map[string]string{"action": "quarantine"}),
+		newPod("noted", map[string]string{"action": "annotate"}),
+		newPod("web-abc-1", map[string]string{"action": "scale"}, controllerRef("ReplicaSet", "web-abc")),
+		newPod("web-abc-2", map[string]string{"action": "scale"}, controllerRef("ReplicaSet", "web-abc")),
+		newPod("evicted", map[string]string{"action": "evict"}),
+	).Build()
+
+	rule := func(name string, action cleanupconfig.ActionConfig) cleanupconfig.PodCleanRule {
+		return cleanupconfig.PodCleanRule{
+			Name: name, Enabled: true, Phase: "Failed", TTL: cleanupconfig.Duration{Duration: time.Hour},
+			Selector: metav1.LabelSelector{MatchLabels: map[string]string{"action": name}},
+			Action:   action,
+		}
+	}
+	podCleanController := NewPodCleanController(client, scheme, &cleanupconfig.CleanupConfig{
+		BatchSize: 10,
+		PodCleanupConfig: cleanupconfig.PodCleanupConfig{
+			Enabled: true,
+			Rules: []cleanupconfig.PodCleanRule{
+				rule("quarantine", cleanupconfig.ActionConfig{Type: cleanupconfig.ActionLabelQuarantine}),
+				rule("annotate", cleanupconfig.ActionConfig{Type: cleanupconfig.ActionAnnotatePatch, Annotations: map[string]string{"example.com/reviewed": "false"}}),
+				rule("scale", cleanupconfig.ActionConfig{Type: cleanupconfig.ActionScaleToZero}),
+				rule("evict", cleanupconfig.ActionConfig{Type: cleanupconfig.ActionEvict}),
+			},
+		},
+	})
+
+	summary := podCleanController.RunCleanUp(context.Background())
+	if summary.Matched != 5 || summary.DeleteFailures != 0 {
+		t.Fatalf("Expected 5 matched pods and no failures, got %d and %d", summary.Matched, summary.DeleteFailures)
+	}
+
+	var pod corev1.Pod
+	if err := client.Get(context.Background(), types.NamespacedName{Namespace: "default", Name: "crashed"}, &pod); err != nil {
+		t.Fatalf("Expected the quarantined pod to be kept: %v", err)
+	}
+	if pod.Labels[cleanupconfig.QuarantineLabel] != "true" {
+		t.Errorf("Expected the quarantine label, got %v", pod.Labels)
+	}
+
+	if err := client.Get(context.Background(), types.NamespacedName{Namespace: "default", Name: "noted"}, &pod); err != nil {
+		t.Fatalf("Expected the annotated pod to be kept: %v", err)
+	}
+	if pod.Annotations["example.com/reviewed"] != "false" {
+		t.Errorf("Expected the annotation to be merged, got %v", pod.Annotations)
+	}
+
+	var scaled appsv1.Deployment
+	if err := client.Get(context.Background(), types.NamespacedName{Namespace: "default", Name: "web"}, &scaled); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if scaled.Spec.Replicas == nil || *scaled.Spec.Replicas != 0 {
+		t.Errorf("Expected the owning Deployment to be scaled to zero, got %v", scaled.Spec.Replicas)
+	}
+	if from := scaled.Annotations[scaledFromAnnotation]; from != "3" {
+		t.Errorf("Expected the Deployment's original replicas to be recorded, got %q", from)
+	}
+
+	if err := client.Get(context.Background(), types.NamespacedName{Namespace: "default", Name: "evicted"}, &pod); err == nil {
+		t.Errorf("Expected the evicted pod to be gone")
+	}
+
+	// Pods already carrying the label or annotations are not acted on again.
+	summary = podCleanController.RunCleanUp(context.Background())
+	if summary.MatchedByRule["quarantine"] != 0 || summary.MatchedByRule["annotate"] != 0 {
+		t.Errorf("Expected labelled and annotated pods not to match again, got %v", summary.MatchedByRule)
+	}
+}
@@ -0,0 +1,45 @@
+package controller
+
+import (
+	"fmt"
+	"time"
+)
+
+// Pod annotations understood by kubeclean.
+const (
+	AnnotationTTL      = "kubeclean/ttl"      // Overrides the rule TTL for the pod, e.g. "30m".
+	AnnotationDisabled = "kubeclean/disabled" // "true" opts the pod out of every rule.
+)
+
+// AnnotationOwnerSink on a namespace names the configured notification sink of its owner.
+const AnnotationOwnerSink = "kubeclean/owner-sink"
+
+// ParseTTLAnnotation parses the value of the kubeclean/ttl annotation.
+func ParseTTLAnnotation(value string) (time.Duration, error) {
+	ttl, err := time.ParseDuration(value)
+	if err != nil {
+		return 0, err
+	}
+	if ttl < 0 {
+		return 0, fmt.Errorf("ttl %q cannot be negative", value)
+	}
+	return ttl, nil
+}
+
+// ValidateAnnotations checks the kubeclean annotations among annotations and returns one
+// message per malformed annotation.
+func ValidateAnnotations(annotations map[string]string) []string {
+	var problems []string
+
+	if value, ok := annotations[AnnotationTTL]; ok {
+		if _, err := ParseTTLAnnotation(value); err != nil {
+			problems = append(problems, fmt.Sprintf("%s: %v", AnnotationTTL, err))
+		}
+	}
+
+	if value, ok := annotations[AnnotationDisabled]; ok && value != "true" && value != "false" {
+		problems = append(problems, fmt.Sprintf("%s: must be \"true\" or \"false\", got %q", AnnotationDisabled, value))
+	}
+
+	return problems
+}
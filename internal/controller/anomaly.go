@@ -0,0 +1,106 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"math"
+
+	cleanupconfig "github.com/infrautils/kubeclean/internal/cleanup_config"
+	"github.com/infrautils/kubeclean/internal/notify"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+// minAnomalySamples is the number of runs a rule needs before its baseline is trusted.
+const minAnomalySamples = 3
+
+// anomalyDetector keeps a rolling window of each rule's matched counts.
+type anomalyDetector struct {
+	history map[string][]int
+}
+
+func newAnomalyDetector() *anomalyDetector {
+	return &anomalyDetector{history: map[string][]int{}}
+}
+
+// observe adds the rule's matched count to its window and reports whether it is a spike compared
+// to the baseline of the previous runs, which it also returns.
+func (d *anomalyDetector) observe(rule string, matched int, cfg cleanupconfig.AnomalyDetectionConfig) (float64, bool) {
+	window := d.history[rule]
+	baseline := baselineOf(window)
+	spike := len(window) >= minAnomalySamples &&
+		matched >= cfg.MinimumMatches() &&
+		float64(matched) > cfg.ThresholdMultiple()*math.Max(baseline, 1)
+
+	window = append(window, matched)
+	if excess := len(window) - cfg.WindowSize(); excess > 0 {
+		window = window[excess:]
+	}
+	d.history[rule] = window
+
+	return baseline, spike
+}
+
+// baselineOf returns the mean plus one standard deviation of counts.
+func baselineOf(counts []int) float64 {
+	if len(counts) == 0 {
+		return 0
+	}
+
+	var sum float64
+	for _, count := range counts {
+		sum += float64(count)
+	}
+	mean := sum / float64(len(counts))
+
+	var variance float64
+	for _, count := range counts {
+		variance += (float64(count) - mean) * (float64(count) - mean)
+	}
+	variance /= float64(len(counts))
+
+	return mean + math.Sqrt(variance)
+}
+
+// detectAnomalies feeds every rule's matched count to the anomaly detector and notifies about spikes.
+func (c *PodCleanController) detectAnomalies(ctx context.Context, run *cleanupRun, plans []rulePlan) {
+	cfg := c.CleanupConfig.AnomalyDetection
+	if !cfg.Enabled {
+		return
+	}
+
+	logger := log.FromContext(ctx)
+
+	for _, plan := range plans {
+		rule := plan.Rule
+		matched := len(plan.Selected) + len(plan.Deferred)
+
+		baseline, spike := c.anomalies.observe(rule.Name, matched, cfg)
+		if !spike {
+			continue
+		}
+
+		anomaliesTotal.WithLabelValues(rule.Name).Inc()
+		logger.Info("Garbage spike detected", "rule", rule.Name, "matched", matched, "baseline", baseline)
+
+		if run.notifier == nil {
+			continue
+		}
+
+		sinks := cfg.NotificationSinks
+		if len(sinks) == 0 {
+			sinks = rule.NotificationSinks
+		}
+
+		event := notify.Event{
+			RunID:   run.ID,
+			Rule:    rule.Name,
+			Pods:    matched,
+			DryRun:  run.DryRun,
+			Summary: run.summarySoFar(),
+			Message: fmt.Sprintf("Garbage spike: rule %s matched %d pod(s), %.1fx its baseline of %.1f", rule.Name, matched, float64(matched)/math.Max(baseline, 1), baseline),
+		}
+		if err := run.notifier.Notify(ctx, sinks, event); err != nil {
+			logger.Error(err, "Failed to send notification", "rule", rule.Name)
+		}
+	}
+}
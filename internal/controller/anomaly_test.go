@@ -0,0 +1,50 @@
+package controller
+
+import (
+	"testing"
+
+	cleanupconfig "github.com/infrautils/kubeclean/internal/cleanup_config"
+)
+
+func TestAnomalyDetector_Observe(t *testing.T) {
+	cfg := cleanupconfig.AnomalyDetectionConfig{Enabled: true, Window: 4, Threshold: 3, MinMatches: 10}
+
+	tests := []struct {
+		name     string
+		history  []int
+		matched  int
+		expected bool
+	}{
+		{name: "too few samples", history: []int{2, 2}, matched: 100, expected: false},
+		{name: "within baseline", history: []int{4, 6, 5, 5}, matched: 12, expected: false},
+		{name: "spike", history: []int{4, 6, 5, 5}, matched: 40, expected: true},
+		{name: "spike below minimum matches", history: []int{0, 1, 0, 1}, matched: 9, expected: false},
+		{name: "spike over empty baseline", history: []int{0, 0, 0}, matched: 10, expected: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			detector := newAnomalyDetector()
+			for _, matched := range tt.history {
+				detector.observe("rule", matched, cfg)
+			}
+
+			if _, spike := detector.observe("rule", tt.matched, cfg); spike != tt.expected {
+				t.Errorf("Expected spike=%v for %d matches after %v", tt.expected, tt.matched, tt.history)
+			}
+		})
+	}
+}
+
+func TestAnomalyDetector_RollingWindow(t *testing.T) {
+	cfg := cleanupconfig.AnomalyDetectionConfig{Enabled: true, Window: 3}
+	detector := newAnomalyDetector()
+
+	for _, matched := range []int{100, 100, 100, 1, 1, 1} {
+		detector.observe("rule", matched, cfg)
+	}
+
+	if window := detector.history["rule"]; len(window) != 3 || window[0] != 1 {
+		t.Errorf("Expected the window to keep the last 3 counts, got %v", window)
+	}
+}
@@ -0,0 +1,130 @@
+package controller
+
+import (
+	"sort"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+// deletionBudget tracks how many deletions remain in a run, globally and per namespace.
+// A zero limit means unlimited.
+type deletionBudget struct {
+	global       int
+	perNamespace int
+	used         int
+	usedByNS     map[string]int
+
+	// urgent namespaces are served before all others, such as those under ResourceQuota pressure.
+	urgent map[string]bool
+}
+
+func newDeletionBudget(global, perNamespace int) *deletionBudget {
+	return &deletionBudget{
+		global:       global,
+		perNamespace: perNamespace,
+		usedByNS:     map[string]int{},
+	}
+}
+
+func (b *deletionBudget) globalExhausted() bool {
+	return b.global > 0 && b.used >= b.global
+}
+
+func (b *deletionBudget) namespaceExhausted(namespace string) bool {
+	return b.perNamespace > 0 && b.usedByNS[namespace] >= b.perNamespace
+}
+
+// allocate splits pods into those that fit the remaining budget and those deferred to a later run.
+// Namespaces are served round-robin so a single namespace cannot consume the whole global budget,
+// and budget left unused by namespaces with few candidates flows to the others. Urgent namespaces
+// are served first, round-robin among themselves, and their pods lead selected.
+func (b *deletionBudget) allocate(pods []corev1.Pod) (selected, deferred []corev1.Pod) {
+	return b.allocateUnits(pods, nil)
+}
+
+// allocateUnits is allocate for pods that unitOf groups into units selected or deferred as a
+// whole, such as the driver and executors of a Spark application. Pods unitOf maps to "" are units
+// of their own. A unit larger than a limit is only selected while nothing was charged against it.
+func (b *deletionBudget) allocateUnits(pods []corev1.Pod, unitOf func(*corev1.Pod) string) (selected, deferred []corev1.Pod) {
+	// Queue units of indexes rather than pods, so each pod is copied once, into selected or deferred.
+	byNamespace := map[string][][]int{}
+	units := map[string]int{} // Position of a named unit in its namespace's queue.
+	for i := range pods {
+		namespace := pods[i].Namespace
+		name := ""
+		if unitOf != nil {
+			name = unitOf(&pods[i])
+		}
+		if name != "" {
+			if position, ok := units[namespace+"/"+name]; ok {
+				byNamespace[namespace][position] = append(byNamespace[namespace][position], i)
+				continue
+			}
+			units[namespace+"/"+name] = len(byNamespace[namespace])
+		}
+		byNamespace[namespace] = append(byNamespace[namespace], []int{i})
+	}
+
+	var urgent, others []string
+	for namespace := range byNamespace {
+		if b.urgent[namespace] {
+			urgent = append(urgent, namespace)
+		} else {
+			others = append(others, namespace)
+		}
+	}
+	sort.Strings(urgent)
+	sort.Strings(others)
+
+	for _, namespaces := range [][]string{urgent, others} {
+		for progress := true; progress && !b.globalExhausted(); {
+			progress = false
+			for _, namespace := range namespaces {
+				queue := byNamespace[namespace]
+				if len(queue) == 0 || !b.fits(namespace, len(queue[0])) {
+					continue
+				}
+
+				for _, i := range queue[0] {
+					selected = append(selected, pods[i])
+				}
+				byNamespace[namespace] = queue[1:]
+				b.used += len(queue[0])
+				b.usedByNS[namespace] += len(queue[0])
+				progress = true
+			}
+		}
+	}
+
+	for _, namespace := range append(urgent, others...) {
+		for _, unit := range byNamespace[namespace] {
+			for _, i := range unit {
+				deferred = append(deferred, pods[i])
+			}
+		}
+	}
+
+	return selected, deferred
+}
+
+// fits reports whether a unit of size pods in namespace fits the remaining budget.
+func (b *deletionBudget) fits(namespace string, size int) bool {
+	if b.globalExhausted() || b.namespaceExhausted(namespace) {
+		return false
+	}
+	fitsGlobal := b.global == 0 || b.used+size <= b.global || b.used == 0
+	fitsNamespace := b.perNamespace == 0 || b.usedByNS[namespace]+size <= b.perNamespace || b.usedByNS[namespace] == 0
+	return fitsGlobal && fitsNamespace
+}
+
+// refund returns the budget charged for the pods of results that were not deleted, because their
+// deletion failed or they were already gone, so that only successful deletions count.
+func (b *deletionBudget) refund(results PodDeleteResults) {
+	for _, result := range results {
+		if result.Deleted || b.usedByNS[result.Namespace] == 0 {
+			continue
+		}
+		b.used--
+		b.usedByNS[result.Namespace]--
+	}
+}
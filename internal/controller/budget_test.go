@@ -0,0 +1,176 @@
+package controller
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	cleanupconfig "github.com/infrautils/kubeclean/internal/cleanup_config"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	ctrlclient "sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
+)
+
+func podsIn(namespace string, count int) []corev1.Pod {
+	pods := make([]corev1.Pod, 0, count)
+	for i := 0; i < count; i++ {
+		pods = append(pods, corev1.Pod{ObjectMeta: metav1.ObjectMeta{Namespace: namespace}})
+	}
+	return pods
+}
+
+func countByNamespace(pods []corev1.Pod) map[string]int {
+	counts := map[string]int{}
+	for _, pod := range pods {
+		counts[pod.Namespace]++
+	}
+	return counts
+}
+
+func TestDeletionBudget_Unlimited(t *testing.T) {
+	budget := newDeletionBudget(0, 0)
+
+	selected, deferred := budget.allocate(append(podsIn("a", 5), podsIn("b", 3)...))
+	if len(selected) != 8 || len(deferred) != 0 {
+		t.Errorf("Expected all pods selected, got %d selected and %d deferred", len(selected), len(deferred))
+	}
+}
+
+func TestDeletionBudget_PerNamespaceCapRedistributesLeftover(t *testing.T) {
+	budget := newDeletionBudget(10, 6)
+
+	// "runaway" has far more candidates than the others; "small" only needs one slot.
+	pods := append(podsIn("runaway", 100), podsIn("small", 1)...)
+	pods = append(pods, podsIn("medium", 5)...)
+
+	selected, deferred := budget.allocate(pods)
+	counts := countByNamespace(selected)
+
+	if len(selected) != 10 || len(deferred) != 96 {
+		t.Fatalf("Expected 10 selected and 96 deferred, got %d and %d", len(selected), len(deferred))
+	}
+
+	if counts["small"] != 1 {
+		t.Errorf("Expected small namespace to be fully served, got %d", counts["small"])
+	}
+
+	if counts["runaway"] > 6 {
+		t.Errorf("Runaway namespace exceeded its cap: %d", counts["runaway"])
+	}
+
+	if counts["runaway"]+counts["medium"] != 9 {
+		t.Errorf("Expected leftover budget to be redistributed, got %v", counts)
+	}
+}
+
+func TestDeletionBudget_SharedAcrossRules(t *testing.T) {
+	budget := newDeletionBudget(0, 3)
+
+	first, _ := budget.allocate(podsIn("team-a", 2))
+	second, deferred := budget.allocate(podsIn("team-a", 2))
+
+	if len(first) != 2 || len(second) != 1 || len(deferred) != 1 {
+		t.Errorf("Expected namespace cap to span rules, got %d, %d selected and %d deferred", len(first), len(second), len(deferred))
+	}
+}
+
+func TestDeletionBudget_UrgentNamespacesFirst(t *testing.T) {
+	budget := newDeletionBudget(4, 0)
+	budget.urgent = map[string]bool{"quota-full": true}
+
+	selected, deferred := budget.allocate(append(podsIn("a", 5), podsIn("quota-full", 3)...))
+	if got := countByNamespace(selected); got["quota-full"] != 3 || got["a"] != 1 {
+		t.Errorf("Expected the urgent namespace to be served first, got %v", got)
+	}
+	if selected[0].Namespace != "quota-full" || len(deferred) != 4 {
+		t.Errorf("Expected urgent pods to lead the selection, got %v first and %d deferred", selected[0].Namespace, len(deferred))
+	}
+}
+
+func TestDeletionBudget_Refund(t *testing.T) {
+	budget := newDeletionBudget(2, 0)
+
+	selected, deferred := budget.allocate(podsIn("a", 3))
+	budget.refund(PodDeleteResults{{Namespace: "a", Deleted: true}, {Namespace: "a", Err: errors.New("timeout")}})
+	more, deferred := budget.allocate(deferred)
+	if len(selected) != 2 || len(more) != 1 || len(deferred) != 0 {
+		t.Errorf("Expected the failed deletion to be refunded, got %d, %d selected and %d deferred", len(selected), len(more), len(deferred))
+	}
+}
+
+func TestRunCleanUp_BudgetChargesSuccessfulDeletionsOnly(t *testing.T) {
+	scheme := runtime.NewScheme()
+	_ = corev1.AddToScheme(scheme)
+
+	var objects []runtime.Object
+	for _, name := range []string{"a", "b", "c", "d"} {
+		objects = append(objects, &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default", CreationTimestamp: metav1.NewTime(time.Now().Add(-2 * time.Hour))},
+			Status:     corev1.PodStatus{Phase: corev1.PodSucceeded},
+		})
+	}
+	client := fake.NewClientBuilder().WithScheme(scheme).WithRuntimeObjects(objects...).
+		WithInterceptorFuncs(interceptor.Funcs{
+			Delete: func(ctx context.Context, c ctrlclient.WithWatch, obj ctrlclient.Object, opts ...ctrlclient.DeleteOption) error {
+				if obj.GetName() == "a" {
+					return apierrors.NewForbidden(schema.GroupResource{Resource: "pods"}, "a", nil)
+				}
+				return c.Delete(ctx, obj, opts...)
+			},
+		}).Build()
+
+	cfg := &cleanupconfig.CleanupConfig{
+		DryRun:             true,
+		MaxDeletionsPerRun: 2,
+		PodCleanupConfig: cleanupconfig.PodCleanupConfig{
+			Enabled: true,
+			Rules:   []cleanupconfig.PodCleanRule{{Name: "succeeded", Enabled: true, Phase: "Succeeded", TTL: cleanupconfig.Duration{Duration: time.Hour}}},
+		},
+	}
+	controller := NewPodCleanController(client, scheme, cfg)
+
+	if summary := controller.RunCleanUp(context.Background()); summary.Deferred != 0 {
+		t.Errorf("Expected dry runs not to be limited by the budget, got %d deferred", summary.Deferred)
+	}
+
+	cfg.DryRun = false
+	summary := controller.RunCleanUp(context.Background())
+	if summary.Deferred != 1 || summary.DeleteFailures != 1 {
+		t.Errorf("Expected the failed deletion to leave room for one deferred pod, got %d deferred and %d failures", summary.Deferred, summary.DeleteFailures)
+	}
+
+	var pods corev1.PodList
+	_ = client.List(context.Background(), &pods)
+	if len(pods.Items) != 2 {
+		t.Errorf("Expected 2 pods deleted within the budget, got %d left", len(pods.Items))
+	}
+}
+
+func TestDeletionBudget_SparkApplicationsAsUnits(t *testing.T) {
+	app := func(name string, count int) []corev1.Pod {
+		pods := podsIn("spark", count)
+		for i := range pods {
+			pods[i].Labels = map[string]string{SparkAppLabel: name}
+		}
+		return pods
+	}
+	units := budgetUnits(cleanupconfig.PodCleanRule{GroupBySparkApplication: true})
+
+	budget := newDeletionBudget(4, 0)
+	selected, deferred := budget.allocateUnits(append(app("first", 3), app("second", 3)...), units)
+	if len(selected) != 3 || len(deferred) != 3 || selected[0].Labels[SparkAppLabel] != "first" {
+		t.Errorf("Expected only the first application to fit, got %d selected and %d deferred", len(selected), len(deferred))
+	}
+
+	// An application larger than the whole budget is only selected alone.
+	budget = newDeletionBudget(2, 0)
+	if selected, _ := budget.allocateUnits(app("large", 3), units); len(selected) != 3 {
+		t.Errorf("Expected the oversized application to be selected whole, got %d", len(selected))
+	}
+}
@@ -0,0 +1,63 @@
+package controller
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// podKey identifies a pod across runs and recreations under the same name.
+type podKey struct {
+	types.NamespacedName
+	UID types.UID
+}
+
+// keyOf returns the key of pod.
+func keyOf(pod *corev1.Pod) podKey {
+	return podKey{NamespacedName: types.NamespacedName{Namespace: pod.Namespace, Name: pod.Name}, UID: pod.UID}
+}
+
+// candidateTracker remembers each rule's candidates from the previous run, so newly appearing
+// garbage can be told apart from pods carried over by budgets, dry-runs or failed deletions.
+type candidateTracker struct {
+	previous map[string]map[types.UID]struct{}
+}
+
+func newCandidateTracker() *candidateTracker {
+	return &candidateTracker{previous: map[string]map[types.UID]struct{}{}}
+}
+
+// diff records the rule's current candidates, given as one or more groups such as the selected
+// and deferred pods, and returns how many of them are new since the previous run and how many
+// were already candidates then. Only the candidates' UIDs are kept.
+func (t *candidateTracker) diff(rule string, groups ...[]corev1.Pod) (fresh, carried int) {
+	previous := t.previous[rule]
+	size := 0
+	for _, candidates := range groups {
+		size += len(candidates)
+	}
+	current := make(map[types.UID]struct{}, size)
+
+	for _, candidates := range groups {
+		for i := range candidates {
+			uid := candidates[i].UID
+			current[uid] = struct{}{}
+			if _, ok := previous[uid]; ok {
+				carried++
+			} else {
+				fresh++
+			}
+		}
+	}
+
+	t.previous[rule] = current
+	return fresh, carried
+}
+
+// retain forgets the candidates of rules not in rules, e.g. after they were disabled or removed.
+func (t *candidateTracker) retain(rules map[string]struct{}) {
+	for rule := range t.previous {
+		if _, ok := rules[rule]; !ok {
+			delete(t.previous, rule)
+		}
+	}
+}
@@ -0,0 +1,102 @@
+package controller
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	cleanupconfig "github.com/infrautils/kubeclean/internal/cleanup_config"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestPodCleanupController_CandidateDiff(t *testing.T) {
+	scheme := runtime.NewScheme()
+	_ = corev1.AddToScheme(scheme)
+
+	newPod := func(name string) *corev1.Pod {
+		return &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:              name,
+				Namespace:         "default",
+				UID:               types.UID(name),
+				CreationTimestamp: metav1.NewTime(time.Now().Add(-2 * time.Hour)),
+			},
+			Status: corev1.PodStatus{Phase: corev1.PodFailed},
+		}
+	}
+
+	client := fake.NewClientBuilder().WithScheme(scheme).WithRuntimeObjects(newPod("a"), newPod("b")).Build()
+
+	// Dry-run keeps every candidate around, so the second run sees them again.
+	cleanupCfg := &cleanupconfig.CleanupConfig{
+		DryRun:    true,
+		BatchSize: 10,
+		PodCleanupConfig: cleanupconfig.PodCleanupConfig{
+			Enabled: true,
+			Rules: []cleanupconfig.PodCleanRule{
+				{Name: "failed", Enabled: true, Phase: "Failed", TTL: cleanupconfig.Duration{Duration: time.Hour}},
+			},
+		},
+	}
+
+	controller := NewPodCleanController(client, scheme, cleanupCfg)
+
+	summary := controller.RunCleanUp(context.Background())
+	if summary.New != 2 || summary.CarriedOver != 0 || summary.NewByRule["failed"] != 2 {
+		t.Errorf("Expected 2 new candidates on the first run, got %+v", summary)
+	}
+
+	if err := client.Create(context.Background(), newPod("c")); err != nil {
+		t.Fatalf("Failed to create pod: %v", err)
+	}
+
+	summary = controller.RunCleanUp(context.Background())
+	if summary.New != 1 || summary.CarriedOver != 2 || summary.NewByRule["failed"] != 1 {
+		t.Errorf("Expected 1 new and 2 carried-over candidates, got %+v", summary)
+	}
+}
+
+func TestFindPodsToCleanup_KeepsOnlyCandidatesOfEachNamespace(t *testing.T) {
+	scheme := runtime.NewScheme()
+	_ = corev1.AddToScheme(scheme)
+
+	newPod := func(name, namespace string, phase corev1.PodPhase) *corev1.Pod {
+		return &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:              name,
+				Namespace:         namespace,
+				UID:               types.UID(namespace + "/" + name),
+				Labels:            map[string]string{"namespace": namespace},
+				CreationTimestamp: metav1.NewTime(time.Now().Add(-2 * time.Hour)),
+			},
+			Status: corev1.PodStatus{Phase: phase},
+		}
+	}
+	client := fake.NewClientBuilder().WithScheme(scheme).WithRuntimeObjects(
+		newPod("worker", "a", corev1.PodSucceeded),
+		newPod("server", "a", corev1.PodRunning),
+		newPod("worker", "b", corev1.PodSucceeded),
+		newPod("server", "b", corev1.PodRunning),
+	).Build()
+
+	pods, err := NewPodMatcher(client).FindPodsToCleanup(context.Background(), cleanupconfig.PodCleanRule{
+		Name: "succeeded", Enabled: true, Phase: "Succeeded", TTL: cleanupconfig.Duration{Duration: time.Hour},
+		Namespaces: []string{"a", "b"},
+	})
+	if err != nil {
+		t.Fatalf("FindPodsToCleanup failed: %v", err)
+	}
+
+	if len(pods) != 2 {
+		t.Fatalf("Expected the succeeded pod of each namespace, got %d pods", len(pods))
+	}
+	for i := range pods {
+		if pods[i].Name != "worker" || pods[i].Labels["namespace"] != pods[i].Namespace || pods[i].UID != types.UID(pods[i].Namespace+"/worker") {
+			t.Errorf("Expected only intact succeeded pods, got %s/%s with labels %v", pods[i].Namespace, pods[i].Name, pods[i].Labels)
+		}
+	}
+}
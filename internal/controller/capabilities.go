@@ -0,0 +1,78 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	cleanupconfig "github.com/infrautils/kubeclean/internal/cleanup_config"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/discovery"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+// orphanKinds maps the kinds an orphan rule can target to the resource its detector lists.
+var orphanKinds = map[string]schema.GroupVersionKind{
+	cleanupconfig.OrphanKindHorizontalPodAutoscaler: {Group: "autoscaling", Version: "v2", Kind: "HorizontalPodAutoscaler"},
+	cleanupconfig.OrphanKindPodDisruptionBudget:     {Group: "policy", Version: "v1", Kind: "PodDisruptionBudget"},
+	cleanupconfig.OrphanKindServiceAccount:          {Version: "v1", Kind: "ServiceAccount"},
+	cleanupconfig.OrphanKindRoleBinding:             {Group: "rbac.authorization.k8s.io", Version: "v1", Kind: "RoleBinding"},
+	cleanupconfig.OrphanKindClusterRoleBinding:      {Group: "rbac.authorization.k8s.io", Version: "v1", Kind: "ClusterRoleBinding"},
+	cleanupconfig.OrphanKindImagePullSecret:         {Version: "v1", Kind: "Secret"},
+	cleanupconfig.OrphanKindNetworkPolicy:           {Group: "networking.k8s.io", Version: "v1", Kind: "NetworkPolicy"},
+}
+
+// servedKinds caches which kinds the API server serves, as discovery reports them. A pass uses a
+// fresh cache, so kinds installed or removed between passes are picked up by the next one.
+type servedKinds struct {
+	discovery discovery.ServerResourcesInterface
+	kinds     map[schema.GroupVersion]map[string]bool // Kinds served per group version; empty when it is not served.
+}
+
+func newServedKinds(discovery discovery.ServerResourcesInterface) *servedKinds {
+	return &servedKinds{discovery: discovery, kinds: map[schema.GroupVersion]map[string]bool{}}
+}
+
+// served reports whether the API server serves gvk. Without discovery, or when discovery fails,
+// every kind is assumed to be served, so rules run and report their own errors.
+func (s *servedKinds) served(ctx context.Context, gvk schema.GroupVersionKind) bool {
+	if s == nil || s.discovery == nil {
+		return true
+	}
+
+	gv := gvk.GroupVersion()
+	kinds, ok := s.kinds[gv]
+	if !ok {
+		resources, err := s.discovery.ServerResourcesForGroupVersion(gv.String())
+		if err != nil && !apierrors.IsNotFound(err) {
+			log.FromContext(ctx).Error(err, "Failed to discover served kinds; assuming they are served", "groupVersion", gv)
+			return true
+		}
+		kinds = map[string]bool{}
+		if err == nil {
+			for _, resource := range resources.APIResources {
+				kinds[resource.Kind] = true
+			}
+		}
+		s.kinds[gv] = kinds
+	}
+	return kinds[gvk.Kind]
+}
+
+// ruleUnavailable reports whether the named rule of kind is skipped because the API server does
+// not serve gvk, and records why in the rule's status.
+func (c *PodCleanController) ruleUnavailable(ctx context.Context, run *cleanupRun, kind, name string, gvk schema.GroupVersionKind) bool {
+	if run.served.served(ctx, gvk) {
+		return false
+	}
+	c.skipUnavailableRule(ctx, kind, name, fmt.Errorf("%s: %w", gvk, errKindNotInstalled))
+	return true
+}
+
+// skipUnavailableRule records that the named rule of kind was skipped because err, which wraps
+// errKindNotInstalled, made it unavailable. Unavailable rules are not failures.
+func (c *PodCleanController) skipUnavailableRule(ctx context.Context, kind, name string, err error) {
+	log.FromContext(ctx).V(1).Info("Kind not served by the API server; skipping rule", "kind", kind, "rule", name, "reason", err.Error())
+	c.statuses.recordUnavailable(kind, name, time.Now(), err)
+}
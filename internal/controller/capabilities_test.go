@@ -0,0 +1,87 @@
+package controller
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	cleanupconfig "github.com/infrautils/kubeclean/internal/cleanup_config"
+	autoscalingv2 "k8s.io/api/autoscaling/v2"
+	corev1 "k8s.io/api/core/v1"
+	policyv1 "k8s.io/api/policy/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	fakediscovery "k8s.io/client-go/discovery/fake"
+	clienttesting "k8s.io/client-go/testing"
+	ctrlclient "sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
+)
+
+func TestRunCleanUp_SkipsRulesForUnservedKinds(t *testing.T) {
+	scheme := runtime.NewScheme()
+	_ = corev1.AddToScheme(scheme)
+	_ = autoscalingv2.AddToScheme(scheme)
+	_ = policyv1.AddToScheme(scheme)
+
+	var listedPDBs int
+	k8sClient := fake.NewClientBuilder().WithScheme(scheme).WithInterceptorFuncs(interceptor.Funcs{
+		List: func(ctx context.Context, client ctrlclient.WithWatch, list ctrlclient.ObjectList, opts ...ctrlclient.ListOption) error {
+			if _, ok := list.(*policyv1.PodDisruptionBudgetList); ok {
+				listedPDBs++
+			}
+			return client.List(ctx, list, opts...)
+		},
+	}).Build()
+
+	// The cluster serves HorizontalPodAutoscalers, but neither PodDisruptionBudgets nor cert-manager.
+	discovery := &fakediscovery.FakeDiscovery{Fake: &clienttesting.Fake{}}
+	discovery.Resources = []*metav1.APIResourceList{
+		{GroupVersion: "autoscaling/v2", APIResources: []metav1.APIResource{{Name: "horizontalpodautoscalers", Kind: "HorizontalPodAutoscaler", Namespaced: true}}},
+	}
+
+	ttl := cleanupconfig.Duration{Duration: time.Hour}
+	cfg := &cleanupconfig.CleanupConfig{
+		BatchSize: 10,
+		OrphanCleanupConfig: cleanupconfig.OrphanCleanupConfig{Enabled: true, Rules: []cleanupconfig.OrphanCleanRule{
+			{Name: "hpas", Enabled: true, Kind: cleanupconfig.OrphanKindHorizontalPodAutoscaler, TTL: ttl},
+			{Name: "pdbs", Enabled: true, Kind: cleanupconfig.OrphanKindPodDisruptionBudget, TTL: ttl},
+		}},
+		CertManagerCleanupConfig: cleanupconfig.CertManagerCleanupConfig{Enabled: true, Rules: []cleanupconfig.CertManagerCleanRule{
+			{Name: "requests", Enabled: true, Kind: cleanupconfig.CertManagerKindCertificateRequest, States: []string{"failed"}, TTL: ttl},
+		}},
+	}
+	controller := NewPodCleanController(k8sClient, scheme, cfg)
+	controller.Discovery = discovery
+	controller.RunCleanUp(context.Background())
+
+	if listedPDBs != 0 {
+		t.Errorf("Expected PodDisruptionBudgets not to be listed, got %d list(s)", listedPDBs)
+	}
+	if status, _ := controller.RuleStatus(RuleKindOrphan, "hpas"); status.Unavailable != "" || status.LastRunTime.IsZero() {
+		t.Errorf("Expected the HorizontalPodAutoscaler rule to run, got %+v", status)
+	}
+	for _, key := range []ruleKey{{kind: RuleKindOrphan, name: "pdbs"}, {kind: RuleKindCertManager, name: "requests"}} {
+		status, ok := controller.RuleStatus(key.kind, key.name)
+		if !ok || status.Unavailable == "" || status.LastError != "" || status.ConsecutiveFailures != 0 {
+			t.Errorf("Expected %s rule %s to be reported as unavailable without failing, got %+v", key.kind, key.name, status)
+		}
+	}
+}
+
+func TestServedKinds_AssumesServedWhenDiscoveryFails(t *testing.T) {
+	discovery := &fakediscovery.FakeDiscovery{Fake: &clienttesting.Fake{}}
+	discovery.PrependReactor("get", "resource", func(clienttesting.Action) (bool, runtime.Object, error) {
+		return true, nil, errors.New("connection refused")
+	})
+
+	gvk := schema.GroupVersionKind{Group: "policy", Version: "v1", Kind: "PodDisruptionBudget"}
+	if !newServedKinds(discovery).served(context.Background(), gvk) {
+		t.Errorf("Expected %s to be assumed served when discovery fails", gvk)
+	}
+	if !newServedKinds(nil).served(context.Background(), gvk) {
+		t.Errorf("Expected %s to be assumed served without discovery", gvk)
+	}
+}
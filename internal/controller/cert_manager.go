@@ -0,0 +1,188 @@
+package controller
+
+import (
+	"context"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+
+	cleanupconfig "github.com/infrautils/kubeclean/internal/cleanup_config"
+	"github.com/infrautils/kubeclean/internal/notify"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+// certManagerKinds maps the kinds a cert-manager rule can target to their API versions.
+var certManagerKinds = map[string]schema.GroupVersionKind{
+	cleanupconfig.CertManagerKindCertificateRequest: {Group: "cert-manager.io", Version: "v1", Kind: "CertificateRequest"},
+	cleanupconfig.CertManagerKindOrder:              {Group: "acme.cert-manager.io", Version: "v1", Kind: "Order"},
+	cleanupconfig.CertManagerKindChallenge:          {Group: "acme.cert-manager.io", Version: "v1", Kind: "Challenge"},
+}
+
+// errKindNotInstalled is returned when the CRD backing a rule is not served by the cluster.
+var errKindNotInstalled = errors.New("resource kind is not installed in the cluster")
+
+// cleanUpCertManager executes every cert-manager rule. cert-manager is an optional add-on, so
+// rules whose CRDs are not installed are skipped and reported as unavailable rather than failed.
+func (c *PodCleanController) cleanUpCertManager(ctx context.Context, run *cleanupRun) {
+	logger := log.FromContext(ctx)
+	logger.Info("Starting cert-manager cleanup")
+
+	for _, rule := range c.CleanupConfig.CertManagerCleanupConfig.Rules {
+		if !c.overrides.isEnabled(RuleKindCertManager, rule.Name, rule.Enabled) || !run.inLane(rule.Lane) {
+			continue
+		}
+		ctx := withRule(ctx, rule.Name)
+		if gvk, ok := certManagerKinds[rule.Kind]; ok && c.ruleUnavailable(ctx, run, RuleKindCertManager, rule.Name, gvk) {
+			continue
+		}
+
+		objects, err := FindCertManagerLeftovers(ctx, c.Client, rule)
+		if errors.Is(err, errKindNotInstalled) {
+			c.skipUnavailableRule(ctx, RuleKindCertManager, rule.Name, err)
+			continue
+		}
+		if err != nil {
+			for reason, count := range ErrorReasons(err) {
+				listErrorsTotal.WithLabelValues(rule.Name, string(reason)).Add(float64(count))
+			}
+			logger.Error(err, "Failed to find cert-manager resources", "rule", rule.Name)
+		}
+
+		if len(objects) == 0 {
+			logger.V(1).Info("No cert-manager resources to cleanup for rule", "rule", rule.Name)
+			c.statuses.record(RuleKindCertManager, rule.Name, time.Now(), 0, 0, 0, err)
+			continue
+		}
+
+		logger.Info("Found cert-manager resources to cleanup", "rule", rule.Name, "kind", rule.Kind, "count", len(objects))
+		toDelete := make([]client.Object, len(objects))
+		for i := range objects {
+			toDelete[i] = &objects[i]
+		}
+		deleteErr := BatchDeleteObjects(ctx, c.Client, rule.Kind, toDelete, c.CleanupConfig.EffectiveBatchSize(), c.CleanupConfig.EffectiveBatchDelay(), run.DryRun)
+		failed := run.recordDeleteFailures(rule.Name, deleteErr)
+		c.statuses.record(RuleKindCertManager, rule.Name, time.Now(), len(objects), deletedCount(len(objects), run.DryRun)-failed, failed,
+			ruleError(err, deleteErr, len(toDelete), failed))
+
+		c.notifyRule(ctx, run, rule.Name, rule.NotificationSinks,
+			notify.Event{Kind: rule.Kind, Resources: len(objects)}, rule.Kind+"(s)")
+	}
+}
+
+// FindCertManagerLeftovers lists the resources of the rule's kind that are in one of its states
+// and older than its TTL. It returns errKindNotInstalled when the kind's CRD is missing.
+func FindCertManagerLeftovers(ctx context.Context, k8sClient client.Client, rule cleanupconfig.CertManagerCleanRule) ([]unstructured.Unstructured, error) {
+	gvk, ok := certManagerKinds[rule.Kind]
+	if !ok {
+		return nil, fmt.Errorf("unknown cert-manager kind %q", rule.Kind)
+	}
+
+	namespaces := rule.Namespaces
+	if len(namespaces) == 0 {
+		namespaces = []string{""} // All namespaces
+	}
+
+	var matched []unstructured.Unstructured
+	var errs []error
+
+	for _, namespace := range namespaces {
+		list := &unstructured.UnstructuredList{}
+		list.SetGroupVersionKind(gvk.GroupVersion().WithKind(gvk.Kind + "List"))
+
+		if err := withThrottleRetry(ctx, "list", func() error {
+			return k8sClient.List(ctx, list, client.InNamespace(namespace))
+		}); err != nil {
+			if meta.IsNoMatchError(err) || apierrors.IsNotFound(err) {
+				return nil, fmt.Errorf("%s: %w", gvk, errKindNotInstalled)
+			}
+			errs = append(errs, newListError(rule.Kind, namespace, err))
+			continue
+		}
+
+		for _, obj := range list.Items {
+			if obj.GetAnnotations()[AnnotationDisabled] == "true" {
+				continue
+			}
+
+			if time.Since(obj.GetCreationTimestamp().Time) <= rule.TTL.Duration {
+				continue
+			}
+
+			if slices.Contains(rule.States, certManagerState(&obj, rule.Kind)) {
+				matched = append(matched, obj)
+			}
+		}
+	}
+
+	return matched, errors.Join(errs...)
+}
+
+// certManagerState returns the lowercase state of a cert-manager resource, or "" when unknown.
+// ACME Orders and Challenges report it directly; CertificateRequests derive it from conditions.
+func certManagerState(obj *unstructured.Unstructured, kind string) string {
+	if kind != cleanupconfig.CertManagerKindCertificateRequest {
+		state, _, _ := unstructured.NestedString(obj.Object, "status", "state")
+		return state
+	}
+
+	conditions, _, _ := unstructured.NestedSlice(obj.Object, "status", "conditions")
+	status := map[string]string{}
+	reason := map[string]string{}
+	for _, c := range conditions {
+		condition, ok := c.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		conditionType, _ := condition["type"].(string)
+		status[conditionType], _ = condition["status"].(string)
+		reason[conditionType], _ = condition["reason"].(string)
+	}
+
+	switch {
+	case status["Denied"] == "True":
+		return "denied"
+	case status["InvalidRequest"] == "True", status["Ready"] == "False" && reason["Ready"] == "Failed":
+		return "failed"
+	case status["Ready"] == "True":
+		if certificateExpired(obj) {
+			return "expired"
+		}
+		return "issued"
+	default:
+		return "pending"
+	}
+}
+
+// certificateExpired reports whether the certificate issued for a CertificateRequest has expired.
+func certificateExpired(obj *unstructured.Unstructured) bool {
+	encoded, _, _ := unstructured.NestedString(obj.Object, "status", "certificate")
+	if encoded == "" {
+		return false
+	}
+
+	data, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return false
+	}
+
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return false
+	}
+
+	cert, err := x509.ParseCertificate(block.Bytes)
+	if err != nil {
+		return false
+	}
+
+	return time.Now().After(cert.NotAfter)
+}
@@ -0,0 +1,151 @@
+package controller
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	cleanupconfig "github.com/infrautils/kubeclean/internal/cleanup_config"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
+)
+
+func newCertManagerObject(kind, name string, age time.Duration, status map[string]interface{}) *unstructured.Unstructured {
+	obj := &unstructured.Unstructured{Object: map[string]interface{}{"status": status}}
+	obj.SetGroupVersionKind(certManagerKinds[kind])
+	obj.SetName(name)
+	obj.SetNamespace("default")
+	obj.SetCreationTimestamp(metav1.NewTime(time.Now().Add(-age)))
+	return obj
+}
+
+func readyCondition(status, reason string) map[string]interface{} {
+	return map[string]interface{}{
+		"conditions": []interface{}{
+			map[string]interface{}{"type": "Ready", "status": status, "reason": reason},
+		},
+	}
+}
+
+func TestPodCleanupController_CertManagerCleanup(t *testing.T) {
+	scheme := runtime.NewScheme()
+	_ = corev1.AddToScheme(scheme)
+
+	mapper := meta.NewDefaultRESTMapper(nil)
+	for _, gvk := range certManagerKinds {
+		mapper.Add(gvk, meta.RESTScopeNamespace)
+	}
+
+	client := fake.NewClientBuilder().WithScheme(scheme).WithRESTMapper(mapper).WithObjects(
+		newCertManagerObject(cleanupconfig.CertManagerKindCertificateRequest, "cr-failed-old", 3*time.Hour, readyCondition("False", "Failed")),
+		newCertManagerObject(cleanupconfig.CertManagerKindCertificateRequest, "cr-failed-new", 10*time.Minute, readyCondition("False", "Failed")),
+		newCertManagerObject(cleanupconfig.CertManagerKindCertificateRequest, "cr-pending-old", 3*time.Hour, readyCondition("False", "Pending")),
+		newCertManagerObject(cleanupconfig.CertManagerKindOrder, "order-invalid", 3*time.Hour, map[string]interface{}{"state": "invalid"}),
+		newCertManagerObject(cleanupconfig.CertManagerKindOrder, "order-pending", 3*time.Hour, map[string]interface{}{"state": "pending"}),
+		newCertManagerObject(cleanupconfig.CertManagerKindChallenge, "challenge-valid", 3*time.Hour, map[string]interface{}{"state": "valid"}),
+	).Build()
+
+	cleanupCfg := &cleanupconfig.CleanupConfig{
+		BatchSize: 10,
+		CertManagerCleanupConfig: cleanupconfig.CertManagerCleanupConfig{
+			Enabled: true,
+			Rules: []cleanupconfig.CertManagerCleanRule{
+				{
+					Name:    "failed-requests",
+					Enabled: true,
+					Kind:    cleanupconfig.CertManagerKindCertificateRequest,
+					States:  []string{"failed", "denied"},
+					TTL:     cleanupconfig.Duration{Duration: time.Hour},
+				},
+				{
+					Name:    "finished-orders",
+					Enabled: true,
+					Kind:    cleanupconfig.CertManagerKindOrder,
+					States:  []string{"valid", "invalid", "errored", "expired"},
+					TTL:     cleanupconfig.Duration{Duration: time.Hour},
+				},
+			},
+		},
+	}
+
+	NewPodCleanController(client, scheme, cleanupCfg).RunCleanUp(context.Background())
+
+	expected := map[string]map[string]bool{
+		cleanupconfig.CertManagerKindCertificateRequest: {"cr-failed-new": true, "cr-pending-old": true},
+		cleanupconfig.CertManagerKindOrder:              {"order-pending": true},
+		cleanupconfig.CertManagerKindChallenge:          {"challenge-valid": true},
+	}
+
+	for kind, want := range expected {
+		gvk := certManagerKinds[kind]
+		list := &unstructured.UnstructuredList{}
+		list.SetGroupVersionKind(gvk.GroupVersion().WithKind(gvk.Kind + "List"))
+		if err := client.List(context.Background(), list); err != nil {
+			t.Fatalf("Failed to list %s: %v", kind, err)
+		}
+
+		remaining := map[string]bool{}
+		for _, obj := range list.Items {
+			remaining[obj.GetName()] = true
+		}
+
+		if len(remaining) != len(want) {
+			t.Errorf("Expected remaining %s %v, got %v", kind, want, remaining)
+			continue
+		}
+		for name := range want {
+			if !remaining[name] {
+				t.Errorf("Expected %s %s to be kept, remaining: %v", kind, name, remaining)
+			}
+		}
+	}
+}
+
+func TestFindCertManagerLeftovers_NotInstalled(t *testing.T) {
+	scheme := runtime.NewScheme()
+	_ = corev1.AddToScheme(scheme)
+
+	// The fake client serves any kind, so simulate an API server without the cert-manager CRDs.
+	k8sClient := fake.NewClientBuilder().WithScheme(scheme).WithInterceptorFuncs(interceptor.Funcs{
+		List: func(ctx context.Context, c client.WithWatch, list client.ObjectList, opts ...client.ListOption) error {
+			gvk := list.GetObjectKind().GroupVersionKind()
+			return &meta.NoKindMatchError{GroupKind: gvk.GroupKind(), SearchedVersions: []string{gvk.Version}}
+		},
+	}).Build()
+
+	rule := cleanupconfig.CertManagerCleanRule{
+		Name:    "orders",
+		Enabled: true,
+		Kind:    cleanupconfig.CertManagerKindOrder,
+		States:  []string{"invalid"},
+		TTL:     cleanupconfig.Duration{Duration: time.Hour},
+	}
+
+	objects, err := FindCertManagerLeftovers(context.Background(), k8sClient, rule)
+	if !errors.Is(err, errKindNotInstalled) {
+		t.Fatalf("Expected errKindNotInstalled, got %v", err)
+	}
+	if len(objects) != 0 {
+		t.Errorf("Expected no objects, got %d", len(objects))
+	}
+
+	// A missing CRD must not fail the run.
+	cleanupCfg := &cleanupconfig.CleanupConfig{
+		BatchSize: 10,
+		CertManagerCleanupConfig: cleanupconfig.CertManagerCleanupConfig{
+			Enabled: true,
+			Rules:   []cleanupconfig.CertManagerCleanRule{rule},
+		},
+	}
+	summary := NewPodCleanController(k8sClient, scheme, cleanupCfg).RunCleanUp(context.Background())
+	if len(summary.ListErrors) != 0 {
+		t.Errorf("Expected no list errors, got %v", summary.ListErrors)
+	}
+}
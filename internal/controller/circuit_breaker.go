@@ -0,0 +1,183 @@
+package controller
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"sync"
+	"time"
+
+	cleanupconfig "github.com/infrautils/kubeclean/internal/cleanup_config"
+	"k8s.io/client-go/transport"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+// ErrCircuitBreakerOpen is why actions left pods alone once the circuit breaker opened mid-run.
+var ErrCircuitBreakerOpen = errors.New("the API server circuit breaker is open")
+
+// maxAPISamples bounds the requests APIHealth remembers, whatever the window.
+const maxAPISamples = 10000
+
+// APIHealth measures the error rate and latency of kubeclean's own API requests and opens a
+// circuit breaker when the API server looks unhealthy, so runs back off instead of adding load.
+// Install it on the REST config with Wrap.
+type APIHealth struct {
+	mu       sync.Mutex
+	samples  []apiSample // Oldest first.
+	openedAt time.Time
+	reason   string // Why the breaker opened; empty while it is closed.
+
+	now func() time.Time
+}
+
+// apiSample is the outcome of one API request.
+type apiSample struct {
+	at      time.Time
+	latency time.Duration
+	failed  bool
+}
+
+func NewAPIHealth() *APIHealth {
+	return &APIHealth{now: time.Now}
+}
+
+// Wrap returns a transport wrapper recording the outcome of every request except watches, whose
+// latency is their lifetime. Requests cancelled by their caller are not recorded either.
+func (h *APIHealth) Wrap() transport.WrapperFunc {
+	return func(rt http.RoundTripper) http.RoundTripper {
+		return &apiHealthRoundTripper{health: h, rt: rt}
+	}
+}
+
+type apiHealthRoundTripper struct {
+	health *APIHealth
+	rt     http.RoundTripper
+}
+
+func (r *apiHealthRoundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.URL.Query().Get("watch") == "true" {
+		return r.rt.RoundTrip(req)
+	}
+
+	started := r.health.now()
+	resp, err := r.rt.RoundTrip(req)
+	if req.Context().Err() != nil {
+		return resp, err
+	}
+
+	failed := err != nil || resp.StatusCode >= http.StatusInternalServerError || resp.StatusCode == http.StatusTooManyRequests
+	r.health.observe(r.health.now().Sub(started), failed)
+	return resp, err
+}
+
+func (r *apiHealthRoundTripper) WrappedRoundTripper() http.RoundTripper {
+	return r.rt
+}
+
+// observe records a request that took latency.
+func (h *APIHealth) observe(latency time.Duration, failed bool) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.samples = append(h.samples, apiSample{at: h.now(), latency: latency, failed: failed})
+	if excess := len(h.samples) - maxAPISamples; excess > 0 {
+		h.samples = slices.Delete(h.samples, 0, excess)
+	}
+}
+
+// check returns why the breaker is open under cfg, or "" while runs may proceed. A closed breaker
+// opens when the requests of the window exceed a threshold. An open breaker closes after the
+// cooldown; the requests it saw open are forgotten, so that it is judged on fresh ones.
+func (h *APIHealth) check(cfg cleanupconfig.CircuitBreakerConfig) string {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	now := h.now()
+	if h.reason != "" {
+		if now.Before(h.openedAt.Add(cfg.CooldownDuration())) {
+			return h.reason
+		}
+		h.reason = ""
+		circuitBreakerOpen.Set(0)
+	}
+
+	since := now.Add(-cfg.WindowDuration())
+	first, _ := slices.BinarySearchFunc(h.samples, since, func(sample apiSample, since time.Time) int {
+		return sample.at.Compare(since)
+	})
+	h.samples = slices.Delete(h.samples, 0, first)
+	if len(h.samples) < cfg.MinimumRequests() {
+		return ""
+	}
+
+	var failed int
+	latencies := make([]time.Duration, 0, len(h.samples))
+	for _, sample := range h.samples {
+		if sample.failed {
+			failed++
+		}
+		latencies = append(latencies, sample.latency)
+	}
+	slices.Sort(latencies)
+	p90 := latencies[len(latencies)*9/10]
+
+	switch ratio := float64(failed) / float64(len(h.samples)); {
+	case ratio > cfg.ErrorRatio():
+		h.reason = fmt.Sprintf("%d of the last %d API requests failed", failed, len(h.samples))
+	case cfg.MaxLatency.Duration > 0 && p90 > cfg.MaxLatency.Duration:
+		h.reason = fmt.Sprintf("90th percentile API latency is %s over the last %d requests", p90.Round(time.Millisecond), len(h.samples))
+	default:
+		return ""
+	}
+
+	h.openedAt = now
+	h.samples = nil
+	circuitBreakerOpen.Set(1)
+	circuitBreakerTripsTotal.Inc()
+	return h.reason
+}
+
+// apiUnhealthy returns why the circuit breaker is open, or "" while runs may proceed.
+func (c *PodCleanController) apiUnhealthy() string {
+	if c.APIHealth == nil || !c.CleanupConfig.CircuitBreaker.Enabled {
+		return ""
+	}
+	return c.APIHealth.check(c.CleanupConfig.CircuitBreaker)
+}
+
+// interrupted reports whether run must stop because the circuit breaker opened, which it records
+// in the run the first time.
+func (c *PodCleanController) interrupted(ctx context.Context, run *cleanupRun) bool {
+	if run.aborted != "" {
+		return true
+	}
+	reason := c.apiUnhealthy()
+	if reason == "" {
+		return false
+	}
+
+	run.aborted = reason
+	interruptedRunsTotal.WithLabelValues("aborted").Inc()
+	log.FromContext(ctx).Info("Aborting run; the API server circuit breaker is open", "reason", reason)
+	return true
+}
+
+type circuitBreakerContextKey struct{}
+
+// withCircuitBreaker makes check, which returns why the circuit breaker is open, available to the
+// actions taken with ctx so they can stop early.
+func withCircuitBreaker(ctx context.Context, check func() string) context.Context {
+	return context.WithValue(ctx, circuitBreakerContextKey{}, check)
+}
+
+// circuitBreakerOpenIn returns why the circuit breaker of ctx is open, or "" if it is closed or ctx
+// has none.
+func circuitBreakerOpenIn(ctx context.Context) string {
+	check, ok := ctx.Value(circuitBreakerContextKey{}).(func() string)
+	if !ok {
+		return ""
+	}
+	return check()
+}
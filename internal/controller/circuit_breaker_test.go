@@ -0,0 +1,231 @@
+package controller
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	cleanupconfig "github.com/infrautils/kubeclean/internal/cleanup_config"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	ctrlclient "sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
+)
+
+func TestAPIHealth_Check(t *testing.T) {
+	now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	health := NewAPIHealth()
+	health.now = func() time.Time { return now }
+	cfg := cleanupconfig.CircuitBreakerConfig{Enabled: true, MinRequests: 10, MaxLatency: cleanupconfig.Duration{Duration: time.Second}}
+
+	for i := 0; i < 9; i++ {
+		health.observe(10*time.Millisecond, true)
+	}
+	if reason := health.check(cfg); reason != "" {
+		t.Fatalf("Expected the breaker to stay closed below minRequests, got %q", reason)
+	}
+
+	// The failures age out of the window.
+	now = now.Add(2 * time.Minute)
+	for i := 0; i < 10; i++ {
+		health.observe(10*time.Millisecond, i < 2)
+	}
+	if reason := health.check(cfg); reason != "" {
+		t.Fatalf("Expected the breaker to stay closed at 2 failures out of 10, got %q", reason)
+	}
+
+	health.observe(10*time.Millisecond, true)
+	if reason := health.check(cfg); reason != "3 of the last 11 API requests failed" {
+		t.Fatalf("Expected the breaker to open on errors, got %q", reason)
+	}
+
+	now = now.Add(4 * time.Minute)
+	if reason := health.check(cfg); reason == "" {
+		t.Errorf("Expected the breaker to stay open during its cooldown")
+	}
+
+	now = now.Add(time.Minute)
+	for i := 0; i < 10; i++ {
+		health.observe(2*time.Second, false)
+	}
+	if reason := health.check(cfg); reason != "90th percentile API latency is 2s over the last 10 requests" {
+		t.Errorf("Expected the breaker to reopen on latency after its cooldown, got %q", reason)
+	}
+}
+
+func TestAPIHealth_Wrap(t *testing.T) {
+	status := http.StatusOK
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(status)
+	}))
+	defer server.Close()
+
+	health := NewAPIHealth()
+	client := &http.Client{Transport: health.Wrap()(http.DefaultTransport)}
+	for _, status = range []int{http.StatusOK, http.StatusNotFound, http.StatusTooManyRequests, http.StatusServiceUnavailable} {
+		resp, err := client.Get(server.URL + "/api/v1/pods")
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+	}
+	resp, err := client.Get(server.URL + "/api/v1/pods?watch=true")
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+
+	var failed int
+	for _, sample := range health.samples {
+		if sample.failed {
+			failed++
+		}
+	}
+	if len(health.samples) != 4 || failed != 2 {
+		t.Errorf("Expected 4 requests with 2 failures, watches excluded, got %d with %d", len(health.samples), failed)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	req, _ := http.NewRequestWithContext(ctx, http.MethodGet, server.URL, nil)
+	if _, err := client.Do(req); !errors.Is(err, context.Canceled) {
+		t.Fatalf("Expected the cancelled request to fail, got %v", err)
+	}
+	if len(health.samples) != 4 {
+		t.Errorf("Expected cancelled requests not to be recorded, got %d requests", len(health.samples))
+	}
+}
+
+func TestRunCleanUp_DeferredWhileCircuitBreakerOpen(t *testing.T) {
+	scheme := runtime.NewScheme()
+	_ = corev1.AddToScheme(scheme)
+
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "done", Namespace: "default", CreationTimestamp: metav1.NewTime(time.Now().Add(-2 * time.Hour))},
+		Status:     corev1.PodStatus{Phase: corev1.PodSucceeded},
+	}
+	client := fake.NewClientBuilder().WithScheme(scheme).WithRuntimeObjects(pod).Build()
+
+	controller := NewPodCleanController(client, scheme, &cleanupconfig.CleanupConfig{
+		PodCleanupConfig: cleanupconfig.PodCleanupConfig{
+			Enabled: true,
+			Rules: []cleanupconfig.PodCleanRule{
+				{Name: "succeeded", Enabled: true, Phase: "Succeeded", TTL: cleanupconfig.Duration{Duration: time.Hour}},
+			},
+		},
+		CircuitBreaker: cleanupconfig.CircuitBreakerConfig{Enabled: true, MinRequests: 5},
+	})
+	controller.APIHealth = NewAPIHealth()
+	for i := 0; i < 5; i++ {
+		controller.APIHealth.observe(time.Millisecond, true)
+	}
+
+	summary := controller.RunCleanUp(context.Background())
+	if summary.Aborted != "5 of the last 5 API requests failed" || summary.Matched != 0 {
+		t.Fatalf("Expected the run to be deferred, got %+v", summary)
+	}
+	if err := client.Get(context.Background(), ctrlclient.ObjectKeyFromObject(pod), &corev1.Pod{}); err != nil {
+		t.Errorf("Expected the pod to be kept while the breaker is open, got %v", err)
+	}
+
+	controller.APIHealth.openedAt = time.Now().Add(-time.Hour)
+	if summary := controller.RunCleanUp(context.Background()); summary.Aborted != "" || summary.Matched != 1 {
+		t.Errorf("Expected the run to proceed after the cooldown, got %+v", summary)
+	}
+	if err := client.Get(context.Background(), ctrlclient.ObjectKeyFromObject(pod), &corev1.Pod{}); !apierrors.IsNotFound(err) {
+		t.Errorf("Expected the pod to be deleted after the cooldown, got %v", err)
+	}
+}
+
+func TestRunCleanUp_CircuitBreakerOpensMidBatch(t *testing.T) {
+	scheme := runtime.NewScheme()
+	_ = corev1.AddToScheme(scheme)
+
+	var objects []runtime.Object
+	for _, name := range []string{"a", "b", "c"} {
+		objects = append(objects, &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default", CreationTimestamp: metav1.NewTime(time.Now().Add(-2 * time.Hour))},
+			Status:     corev1.PodStatus{Phase: corev1.PodSucceeded},
+		})
+	}
+
+	health := NewAPIHealth()
+	client := fake.NewClientBuilder().WithScheme(scheme).WithRuntimeObjects(objects...).
+		WithInterceptorFuncs(interceptor.Funcs{
+			Delete: func(ctx context.Context, client ctrlclient.WithWatch, obj ctrlclient.Object, opts ...ctrlclient.DeleteOption) error {
+				// The API server degrades right after the first deletion.
+				for i := 0; i < 5; i++ {
+					health.observe(time.Millisecond, true)
+				}
+				return client.Delete(ctx, obj, opts...)
+			},
+		}).Build()
+
+	controller := NewPodCleanController(client, scheme, &cleanupconfig.CleanupConfig{
+		BatchSize: 1,
+		PodCleanupConfig: cleanupconfig.PodCleanupConfig{
+			Enabled: true,
+			Rules: []cleanupconfig.PodCleanRule{
+				{Name: "succeeded", Enabled: true, Phase: "Succeeded", TTL: cleanupconfig.Duration{Duration: time.Hour}},
+			},
+		},
+		CircuitBreaker: cleanupconfig.CircuitBreakerConfig{Enabled: true, MinRequests: 5},
+	})
+	controller.APIHealth = health
+
+	summary := controller.RunCleanUp(context.Background())
+	if summary.Aborted == "" || summary.DeleteFailures != 0 {
+		t.Errorf("Expected the run to be aborted without failed deletions, got %+v", summary)
+	}
+	if status, _ := controller.RuleStatus(RuleKindPod, "succeeded"); status.LastDeleted != 1 || status.LastFailed != 0 || status.LastError != "" {
+		t.Errorf("Expected only the pod deleted before the breaker opened to be reported, got %+v", status)
+	}
+
+	var pods corev1.PodList
+	if err := client.List(context.Background(), &pods); err != nil {
+		t.Fatalf("Failed to list pods: %v", err)
+	}
+	if len(pods.Items) != 2 {
+		t.Errorf("Expected the pods after the first batch to be kept, got %d pod(s)", len(pods.Items))
+	}
+}
+
+func TestBatchApply_SkipsRemainingPodsOnceCircuitBreakerOpens(t *testing.T) {
+	scheme := runtime.NewScheme()
+	_ = corev1.AddToScheme(scheme)
+
+	pods := []corev1.Pod{
+		{ObjectMeta: metav1.ObjectMeta{Name: "a", Namespace: "default"}},
+		{ObjectMeta: metav1.ObjectMeta{Name: "b", Namespace: "default"}},
+		{ObjectMeta: metav1.ObjectMeta{Name: "c", Namespace: "default"}},
+	}
+	client := fake.NewClientBuilder().WithScheme(scheme).WithObjects(&pods[0], &pods[1], &pods[2]).Build()
+
+	var reason string
+	ctx := withCircuitBreaker(context.Background(), func() string { return reason })
+	results := BatchApply(ctx, client, deleteAction{}, pods, 2, 0, false)
+	if results.Deleted() != 3 {
+		t.Fatalf("Expected every pod to be deleted while the breaker is closed, got %+v", results)
+	}
+
+	client = fake.NewClientBuilder().WithScheme(scheme).WithObjects(&pods[0], &pods[1], &pods[2]).
+		WithInterceptorFuncs(interceptor.Funcs{
+			Delete: func(ctx context.Context, client ctrlclient.WithWatch, obj ctrlclient.Object, opts ...ctrlclient.DeleteOption) error {
+				reason = "5 of the last 5 API requests failed"
+				return client.Delete(ctx, obj, opts...)
+			},
+		}).Build()
+	results = BatchApply(ctx, client, deleteAction{}, pods, 2, 0, false)
+	if len(results) != 3 || results.Deleted() != 2 || results.Failed() != 0 || results.Skipped() != 1 {
+		t.Fatalf("Expected one result per pod, the last one skipped, got %+v", results)
+	}
+	if !errors.Is(results[2].Skipped, ErrCircuitBreakerOpen) {
+		t.Errorf("Expected the last pod to be skipped for the open breaker, got %v", results[2].Skipped)
+	}
+}
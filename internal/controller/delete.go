@@ -0,0 +1,51 @@
+package controller
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+// BatchDeleteObjects deletes objects of the given kind in batches of batchSize, pausing batchDelay
+// between batches and continuing past failures, and returns the failures joined. Objects that are
+// already gone are not reported as failures. When ctx is done during a pause, the remaining
+// objects fail with its error.
+func BatchDeleteObjects(ctx context.Context, k8sClient client.Client, kind string, objects []client.Object, batchSize int, batchDelay time.Duration, dryRun bool) error {
+	logger := log.FromContext(ctx)
+	var errs []error
+
+	for i := 0; i < len(objects); i += batchSize {
+		end := min(i+batchSize, len(objects))
+
+		for _, obj := range objects[i:end] {
+			if dryRun {
+				logger.Info("DRY RUN: Would delete resource", "kind", kind, "name", obj.GetName(), "namespace", obj.GetNamespace())
+				continue
+			}
+
+			logger.Info("Deleting resource", "kind", kind, "name", obj.GetName(), "namespace", obj.GetNamespace())
+			if err := withThrottleRetry(ctx, "delete", func() error {
+				return client.IgnoreNotFound(k8sClient.Delete(ctx, obj))
+			}); err != nil {
+				logger.Error(err, "Failed to delete resource", "kind", kind, "name", obj.GetName(), "namespace", obj.GetNamespace())
+				errs = append(errs, fmt.Errorf("delete %s %s/%s: %w", kind, obj.GetNamespace(), obj.GetName(), err))
+			}
+		}
+
+		if end < len(objects) {
+			if err := waitFor(ctx, batchDelay); err != nil {
+				logger.Info("Stopping deletions; the run was cancelled", "kind", kind, "remaining", len(objects)-end)
+				for _, obj := range objects[end:] {
+					errs = append(errs, fmt.Errorf("delete %s %s/%s: %w", kind, obj.GetNamespace(), obj.GetName(), err))
+				}
+				break
+			}
+		}
+	}
+
+	return errors.Join(errs...)
+}
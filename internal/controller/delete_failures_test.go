@@ -0,0 +1,119 @@
+package controller
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	cleanupconfig "github.com/infrautils/kubeclean/internal/cleanup_config"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	ctrlclient "sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
+)
+
+func TestRunCleanUp_ClassifiesDeleteFailures(t *testing.T) {
+	scheme := runtime.NewScheme()
+	_ = corev1.AddToScheme(scheme)
+
+	podsResource := schema.GroupResource{Resource: "pods"}
+	failures := map[string]error{
+		"forbidden-a": apierrors.NewForbidden(podsResource, "forbidden-a", nil),
+		"forbidden-b": apierrors.NewForbidden(podsResource, "forbidden-b", nil),
+		"guarded": &apierrors.StatusError{ErrStatus: metav1.Status{
+			Status:  metav1.StatusFailure,
+			Code:    403,
+			Reason:  metav1.StatusReasonForbidden,
+			Message: `admission webhook "guard.example.com" denied the request: pod is protected`,
+		}},
+		"recreated": apierrors.NewConflict(podsResource, "recreated", nil),
+	}
+
+	var objects []runtime.Object
+	for _, name := range []string{"deletable", "forbidden-a", "forbidden-b", "guarded", "recreated"} {
+		objects = append(objects, &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default", CreationTimestamp: metav1.NewTime(time.Now().Add(-2 * time.Hour))},
+			Status:     corev1.PodStatus{Phase: corev1.PodSucceeded},
+		})
+	}
+
+	client := fake.NewClientBuilder().WithScheme(scheme).WithRuntimeObjects(objects...).
+		WithInterceptorFuncs(interceptor.Funcs{
+			Delete: func(ctx context.Context, c ctrlclient.WithWatch, obj ctrlclient.Object, opts ...ctrlclient.DeleteOption) error {
+				if err, ok := failures[obj.GetName()]; ok {
+					return err
+				}
+				return c.Delete(ctx, obj, opts...)
+			},
+		}).Build()
+
+	podCleanController := NewPodCleanController(client, scheme, &cleanupconfig.CleanupConfig{
+		BatchSize: 10,
+		PodCleanupConfig: cleanupconfig.PodCleanupConfig{
+			Enabled: true,
+			Rules:   []cleanupconfig.PodCleanRule{{Name: "succeeded", Enabled: true, Phase: "Succeeded", TTL: cleanupconfig.Duration{Duration: time.Hour}}},
+		},
+	})
+
+	summary := podCleanController.RunCleanUp(context.Background())
+
+	if summary.DeleteFailures != 4 {
+		t.Errorf("Expected 4 failed deletions, got %d", summary.DeleteFailures)
+	}
+	expected := map[ErrorReason]int{ErrorReasonForbidden: 2, ErrorReasonWebhookDenied: 1, ErrorReasonConflict: 1}
+	for reason, count := range expected {
+		if summary.DeleteErrors[reason] != count {
+			t.Errorf("Expected %d %s deletion failure(s), got %v", count, reason, summary.DeleteErrors)
+		}
+	}
+	if top := summary.TopDeleteErrors(2); top != "Forbidden: 2, Conflict: 1" {
+		t.Errorf("Unexpected top delete errors %q", top)
+	}
+
+	// One deletion succeeded, so the failures are reported as counts without failing the rule.
+	status, _ := podCleanController.RuleStatus(RuleKindPod, "succeeded")
+	if status.LastFailed != 4 || status.LastError != "" || status.ConsecutiveFailures != 0 {
+		t.Errorf("Expected a partially failed rule to record 4 failures without an error, got %+v", status)
+	}
+}
+
+func TestBatchDeletePods_ReportsPerPodResults(t *testing.T) {
+	scheme := runtime.NewScheme()
+	_ = corev1.AddToScheme(scheme)
+
+	pods := []corev1.Pod{
+		{ObjectMeta: metav1.ObjectMeta{Name: "deletable", Namespace: "default"}},
+		{ObjectMeta: metav1.ObjectMeta{Name: "forbidden", Namespace: "default"}},
+		{ObjectMeta: metav1.ObjectMeta{Name: "gone", Namespace: "default"}},
+	}
+	client := fake.NewClientBuilder().WithScheme(scheme).WithObjects(&pods[0], &pods[1]).
+		WithInterceptorFuncs(interceptor.Funcs{
+			Delete: func(ctx context.Context, c ctrlclient.WithWatch, obj ctrlclient.Object, opts ...ctrlclient.DeleteOption) error {
+				if obj.GetName() == "forbidden" {
+					return apierrors.NewForbidden(schema.GroupResource{Resource: "pods"}, "forbidden", nil)
+				}
+				return c.Delete(ctx, obj, opts...)
+			},
+		}).Build()
+
+	results := BatchDeletePods(context.Background(), client, pods, 2, 0, false)
+
+	if len(results) != 3 || results.Deleted() != 1 || results.Failed() != 1 {
+		t.Fatalf("Expected 1 deleted and 1 failed pod out of 3, got %+v", results)
+	}
+	if !results[0].Deleted || results[0].Err != nil {
+		t.Errorf("Expected the deletable pod to be deleted, got %+v", results[0])
+	}
+	var deleteErr *PodDeleteError
+	if !errors.As(results[1].Err, &deleteErr) || deleteErr.Name != "forbidden" || ClassifyError(deleteErr) != ErrorReasonForbidden {
+		t.Errorf("Expected the forbidden pod's failure to be attributed to it, got %+v", results[1])
+	}
+	if results[2].Deleted || results[2].Err != nil {
+		t.Errorf("Expected the pod that was already gone to be neither deleted nor failed, got %+v", results[2])
+	}
+}
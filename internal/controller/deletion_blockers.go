@@ -0,0 +1,143 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	admissionregistrationv1 "k8s.io/api/admissionregistration/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+// podDeleteWebhook is a validating webhook whose rules intercept pod deletion.
+type podDeleteWebhook struct {
+	Config            string
+	Name              string
+	FailurePolicy     admissionregistrationv1.FailurePolicyType
+	NamespaceSelector labels.Selector
+	ObjectSelector    labels.Selector
+}
+
+// deletionChecker explains why deleting a matched pod may not complete, so dry runs can tell
+// which "would delete" entries would actually hang or fail. Webhook configurations are listed
+// once per checker; when they cannot be listed, only finalizers are checked.
+type deletionChecker struct {
+	matcher  *PodMatcher
+	webhooks []podDeleteWebhook
+	loaded   bool
+}
+
+func newDeletionChecker(matcher *PodMatcher) *deletionChecker {
+	return &deletionChecker{matcher: matcher}
+}
+
+// warnings returns a description of every finalizer and validating webhook standing in the way
+// of deleting pod; it is empty when nothing is known to block the deletion.
+func (dc *deletionChecker) warnings(ctx context.Context, pod *corev1.Pod) []string {
+	var warnings []string
+	if len(pod.Finalizers) > 0 {
+		warnings = append(warnings, fmt.Sprintf("finalizers %s keep the pod terminating until removed", strings.Join(pod.Finalizers, ", ")))
+	}
+
+	dc.loadWebhooks(ctx)
+	if len(dc.webhooks) == 0 {
+		return warnings
+	}
+
+	var namespaceLabels labels.Set
+	if namespace, err := dc.matcher.getNamespace(ctx, pod.Namespace); err == nil && namespace != nil {
+		namespaceLabels = namespace.Labels
+	}
+
+	for _, webhook := range dc.webhooks {
+		if !webhook.NamespaceSelector.Matches(namespaceLabels) || !webhook.ObjectSelector.Matches(labels.Set(pod.Labels)) {
+			continue
+		}
+		warnings = append(warnings, fmt.Sprintf("validating webhook %s/%s intercepts pod deletion (failurePolicy %s)",
+			webhook.Config, webhook.Name, webhook.FailurePolicy))
+	}
+
+	return warnings
+}
+
+// loadWebhooks lists the validating webhook configurations and keeps the webhooks matching pod DELETE.
+func (dc *deletionChecker) loadWebhooks(ctx context.Context) {
+	if dc.loaded {
+		return
+	}
+	dc.loaded = true
+
+	var configs admissionregistrationv1.ValidatingWebhookConfigurationList
+	if err := withThrottleRetry(ctx, "list", func() error {
+		return dc.matcher.client.List(ctx, &configs)
+	}); err != nil {
+		log.FromContext(ctx).Info("Unable to list validating webhook configurations; only finalizers are checked", "error", err)
+		return
+	}
+
+	for _, config := range configs.Items {
+		for _, webhook := range config.Webhooks {
+			if !slices.ContainsFunc(webhook.Rules, interceptsPodDelete) {
+				continue
+			}
+
+			namespaceSelector, err := selectorOrEverything(webhook.NamespaceSelector)
+			if err != nil {
+				continue
+			}
+			objectSelector, err := selectorOrEverything(webhook.ObjectSelector)
+			if err != nil {
+				continue
+			}
+
+			failurePolicy := admissionregistrationv1.Fail
+			if webhook.FailurePolicy != nil {
+				failurePolicy = *webhook.FailurePolicy
+			}
+
+			dc.webhooks = append(dc.webhooks, podDeleteWebhook{
+				Config:            config.Name,
+				Name:              webhook.Name,
+				FailurePolicy:     failurePolicy,
+				NamespaceSelector: namespaceSelector,
+				ObjectSelector:    objectSelector,
+			})
+		}
+	}
+}
+
+// interceptsPodDelete reports whether the webhook rule covers DELETE of core pods.
+func interceptsPodDelete(rule admissionregistrationv1.RuleWithOperations) bool {
+	if rule.Scope != nil && *rule.Scope == admissionregistrationv1.ClusterScope {
+		return false
+	}
+
+	return slices.ContainsFunc(rule.Operations, func(op admissionregistrationv1.OperationType) bool {
+		return op == admissionregistrationv1.Delete || op == admissionregistrationv1.OperationAll
+	}) &&
+		(slices.Contains(rule.APIGroups, "") || slices.Contains(rule.APIGroups, "*")) &&
+		(slices.Contains(rule.Resources, "pods") || slices.Contains(rule.Resources, "*") || slices.Contains(rule.Resources, "*/*"))
+}
+
+// selectorOrEverything converts a webhook selector, where nil matches every object.
+func selectorOrEverything(selector *metav1.LabelSelector) (labels.Selector, error) {
+	if selector == nil {
+		return labels.Everything(), nil
+	}
+	return metav1.LabelSelectorAsSelector(selector)
+}
+
+// logDeletionWarnings logs the pods of a dry run whose deletion would not complete as reported.
+func logDeletionWarnings(ctx context.Context, checker *deletionChecker, rule string, pods []corev1.Pod) {
+	logger := log.FromContext(ctx)
+	for i := range pods {
+		if warnings := checker.warnings(ctx, &pods[i]); len(warnings) > 0 {
+			logger.Info("DRY RUN: Pod deletion may not complete", "pod", pods[i].Name, "namespace", pods[i].Namespace,
+				"rule", rule, "warnings", warnings)
+		}
+	}
+}
@@ -0,0 +1,87 @@
+package controller
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	cleanupconfig "github.com/infrautils/kubeclean/internal/cleanup_config"
+	admissionregistrationv1 "k8s.io/api/admissionregistration/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestPreview_WarnsAboutFinalizersAndDeleteWebhooks(t *testing.T) {
+	scheme := runtime.NewScheme()
+	_ = corev1.AddToScheme(scheme)
+	_ = admissionregistrationv1.AddToScheme(scheme)
+
+	newPod := func(name, namespace string, finalizers ...string) *corev1.Pod {
+		return &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:              name,
+				Namespace:         namespace,
+				Finalizers:        finalizers,
+				CreationTimestamp: metav1.NewTime(time.Now().Add(-2 * time.Hour)),
+			},
+			Status: corev1.PodStatus{Phase: corev1.PodSucceeded},
+		}
+	}
+
+	ignore := admissionregistrationv1.Ignore
+	webhook := func(name string, operations ...admissionregistrationv1.OperationType) admissionregistrationv1.ValidatingWebhook {
+		return admissionregistrationv1.ValidatingWebhook{
+			Name:              name,
+			FailurePolicy:     &ignore,
+			NamespaceSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"protected": "true"}},
+			Rules: []admissionregistrationv1.RuleWithOperations{{
+				Operations: operations,
+				Rule:       admissionregistrationv1.Rule{APIGroups: []string{""}, APIVersions: []string{"v1"}, Resources: []string{"pods"}},
+			}},
+		}
+	}
+
+	client := fake.NewClientBuilder().WithScheme(scheme).WithRuntimeObjects(
+		&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "default"}},
+		&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "guarded", Labels: map[string]string{"protected": "true"}}},
+		&admissionregistrationv1.ValidatingWebhookConfiguration{
+			ObjectMeta: metav1.ObjectMeta{Name: "guard"},
+			Webhooks: []admissionregistrationv1.ValidatingWebhook{
+				webhook("deletes.guard.example.com", admissionregistrationv1.Delete),
+				webhook("creates.guard.example.com", admissionregistrationv1.Create),
+			},
+		},
+		newPod("plain", "default"),
+		newPod("finalized", "default", "example.com/cleanup"),
+		newPod("guarded", "guarded"),
+	).Build()
+
+	podCleanController := NewPodCleanController(client, scheme, &cleanupconfig.CleanupConfig{
+		DryRun: true,
+		PodCleanupConfig: cleanupconfig.PodCleanupConfig{
+			Enabled: true,
+			Rules:   []cleanupconfig.PodCleanRule{{Name: "succeeded", Enabled: true, Phase: "Succeeded", TTL: cleanupconfig.Duration{Duration: time.Hour}}},
+		},
+	})
+
+	warnings := map[string][]string{}
+	for _, ref := range podCleanController.Preview(context.Background()) {
+		warnings[ref.Name] = ref.Warnings
+	}
+
+	if len(warnings) != 3 {
+		t.Fatalf("Expected 3 previewed pods, got %v", warnings)
+	}
+	if len(warnings["plain"]) != 0 {
+		t.Errorf("Expected no warnings for a plain pod, got %v", warnings["plain"])
+	}
+	if got := warnings["finalized"]; len(got) != 1 || !strings.Contains(got[0], "example.com/cleanup") {
+		t.Errorf("Expected a finalizer warning, got %v", got)
+	}
+	if got := warnings["guarded"]; len(got) != 1 || !strings.Contains(got[0], "guard/deletes.guard.example.com") || !strings.Contains(got[0], "Ignore") {
+		t.Errorf("Expected a single warning for the DELETE webhook, got %v", got)
+	}
+}
@@ -0,0 +1,165 @@
+package controller
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// ErrorReason classifies an API error so permission problems can be told apart from transient failures.
+type ErrorReason string
+
+const (
+	ErrorReasonForbidden ErrorReason = "Forbidden"
+	ErrorReasonTimeout   ErrorReason = "Timeout"
+	ErrorReasonNotFound  ErrorReason = "NotFound"
+	ErrorReasonThrottled ErrorReason = "Throttled"
+	ErrorReasonConflict  ErrorReason = "Conflict" // The object changed, e.g. a pod was recreated under the same name.
+	ErrorReasonUnknown   ErrorReason = "Unknown"
+
+	ErrorReasonWebhookDenied ErrorReason = "WebhookDenied" // An admission webhook rejected the request or could not be called.
+
+	ErrorReasonInvalidAnnotation ErrorReason = "InvalidAnnotation" // A pod carries a malformed kubeclean annotation.
+)
+
+// ClassifyError maps an API error to an ErrorReason.
+func ClassifyError(err error) ErrorReason {
+	switch {
+	case isWebhookError(err):
+		return ErrorReasonWebhookDenied
+	case apierrors.IsForbidden(err), apierrors.IsUnauthorized(err):
+		return ErrorReasonForbidden
+	case apierrors.IsTimeout(err), apierrors.IsServerTimeout(err):
+		return ErrorReasonTimeout
+	case apierrors.IsNotFound(err):
+		return ErrorReasonNotFound
+	case apierrors.IsTooManyRequests(err):
+		return ErrorReasonThrottled
+	case apierrors.IsConflict(err):
+		return ErrorReasonConflict
+	default:
+		return ErrorReasonUnknown
+	}
+}
+
+// isWebhookError reports whether the API server failed a request on behalf of an admission
+// webhook. Denials carry the webhook's status code, often 403, so only the message tells them
+// apart from RBAC failures.
+func isWebhookError(err error) bool {
+	var statusErr apierrors.APIStatus
+	if !errors.As(err, &statusErr) {
+		return false
+	}
+	message := statusErr.Status().Message
+	return (strings.HasPrefix(message, "admission webhook ") && strings.Contains(message, " denied the request")) ||
+		strings.Contains(message, "failed calling webhook")
+}
+
+// ListError is returned when listing a resource in a namespace fails.
+type ListError struct {
+	Resource  string
+	Namespace string
+	Reason    ErrorReason
+	Err       error
+}
+
+func newListError(resource, namespace string, err error) *ListError {
+	return &ListError{Resource: resource, Namespace: namespace, Reason: ClassifyError(err), Err: err}
+}
+
+func (e *ListError) Error() string {
+	namespace := e.Namespace
+	if namespace == "" {
+		namespace = "<all>"
+	}
+	return fmt.Sprintf("list %s in namespace %s (%s): %v", e.Resource, namespace, e.Reason, e.Err)
+}
+
+func (e *ListError) Unwrap() error {
+	return e.Err
+}
+
+// PodDeleteError is returned when deleting a pod fails.
+type PodDeleteError struct {
+	Namespace string
+	Name      string
+	UID       types.UID
+	Err       error
+}
+
+func (e *PodDeleteError) Error() string {
+	return fmt.Sprintf("delete pod %s/%s: %v", e.Namespace, e.Name, e.Err)
+}
+
+func (e *PodDeleteError) Unwrap() error {
+	return e.Err
+}
+
+// AnnotationError is returned when a rule with the fail invalidAnnotationPolicy lists a pod
+// carrying a malformed kubeclean annotation.
+type AnnotationError struct {
+	Namespace  string
+	Pod        string
+	Annotation string
+	Err        error
+}
+
+func (e *AnnotationError) Error() string {
+	return fmt.Sprintf("pod %s/%s has invalid annotation %s: %v", e.Namespace, e.Pod, e.Annotation, e.Err)
+}
+
+func (e *AnnotationError) Unwrap() error {
+	return e.Err
+}
+
+// ErrorReasons counts the reasons of every error contained in err, which may be joined.
+func ErrorReasons(err error) map[ErrorReason]int {
+	reasons := map[ErrorReason]int{}
+	if err == nil {
+		return reasons
+	}
+
+	errs := []error{err}
+	if joined, ok := err.(interface{ Unwrap() []error }); ok {
+		errs = joined.Unwrap()
+	}
+
+	for _, e := range errs {
+		var annotationErr *AnnotationError
+		if listErr, ok := e.(*ListError); ok {
+			reasons[listErr.Reason]++
+		} else if errors.As(e, &annotationErr) {
+			reasons[ErrorReasonInvalidAnnotation]++
+		} else {
+			reasons[ClassifyError(e)]++
+		}
+	}
+
+	return reasons
+}
+
+// TopErrorReasons formats the n most frequent reasons, e.g. "Forbidden: 3, WebhookDenied: 1".
+func TopErrorReasons(reasons map[ErrorReason]int, n int) string {
+	sorted := make([]ErrorReason, 0, len(reasons))
+	for reason, count := range reasons {
+		if count > 0 {
+			sorted = append(sorted, reason)
+		}
+	}
+	sort.Slice(sorted, func(i, j int) bool {
+		if reasons[sorted[i]] != reasons[sorted[j]] {
+			return reasons[sorted[i]] > reasons[sorted[j]]
+		}
+		return sorted[i] < sorted[j]
+	})
+
+	parts := make([]string, 0, n)
+	for _, reason := range sorted[:min(n, len(sorted))] {
+		parts = append(parts, fmt.Sprintf("%s: %d", reason, reasons[reason]))
+	}
+	return strings.Join(parts, ", ")
+}
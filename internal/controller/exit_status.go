@@ -0,0 +1,31 @@
+package controller
+
+import (
+	cleanupconfig "github.com/infrautils/kubeclean/internal/cleanup_config"
+)
+
+// Exit statuses of `kubeclean run`. 1 and 2 are left to configuration and usage errors.
+const (
+	ExitOK             = 0
+	ExitDeleteFailures = 3 // Some deletions failed.
+	ExitForbidden      = 4 // RBAC kept a rule from listing resources.
+	ExitSafetyCap      = 5 // A deletion budget deferred pods to a later run.
+	ExitAborted        = 6 // The circuit breaker deferred or aborted the run.
+)
+
+// ExitStatus maps the summary to an exit status according to policy. When several outcomes
+// apply, delete failures take precedence over aborted runs, then RBAC errors, then safety caps.
+func (s RunSummary) ExitStatus(policy cleanupconfig.ExitStatusConfig) int {
+	switch {
+	case policy.FailsOnDeleteErrors() && s.DeleteFailures > 0:
+		return ExitDeleteFailures
+	case policy.FailsOnAborted() && s.Aborted != "":
+		return ExitAborted
+	case policy.FailOnForbidden && s.ListErrors[ErrorReasonForbidden] > 0:
+		return ExitForbidden
+	case policy.FailOnSafetyCap && s.Deferred > 0:
+		return ExitSafetyCap
+	default:
+		return ExitOK
+	}
+}
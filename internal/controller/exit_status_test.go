@@ -0,0 +1,66 @@
+package controller
+
+import (
+	"testing"
+
+	cleanupconfig "github.com/infrautils/kubeclean/internal/cleanup_config"
+)
+
+func TestRunSummary_ExitStatus(t *testing.T) {
+	disabled := false
+
+	tests := []struct {
+		name     string
+		summary  RunSummary
+		policy   cleanupconfig.ExitStatusConfig
+		expected int
+	}{
+		{name: "clean run", summary: RunSummary{}, expected: ExitOK},
+		{name: "delete failures by default", summary: RunSummary{DeleteFailures: 2}, expected: ExitDeleteFailures},
+		{
+			name:     "delete failures ignored",
+			summary:  RunSummary{DeleteFailures: 2},
+			policy:   cleanupconfig.ExitStatusConfig{FailOnDeleteErrors: &disabled},
+			expected: ExitOK,
+		},
+		{name: "aborted by default", summary: RunSummary{Aborted: "5 of the last 5 API requests failed"}, expected: ExitAborted},
+		{
+			name:     "aborted ignored",
+			summary:  RunSummary{Aborted: "5 of the last 5 API requests failed", Deferred: 5},
+			policy:   cleanupconfig.ExitStatusConfig{FailOnAborted: &disabled, FailOnSafetyCap: true},
+			expected: ExitSafetyCap,
+		},
+		{
+			name:     "delete failures take precedence over aborted",
+			summary:  RunSummary{Aborted: "5 of the last 5 API requests failed", DeleteFailures: 1},
+			expected: ExitDeleteFailures,
+		},
+		{name: "safety cap ignored by default", summary: RunSummary{Deferred: 5}, expected: ExitOK},
+		{
+			name:     "safety cap",
+			summary:  RunSummary{Deferred: 5},
+			policy:   cleanupconfig.ExitStatusConfig{FailOnSafetyCap: true},
+			expected: ExitSafetyCap,
+		},
+		{
+			name:     "forbidden takes precedence over safety cap",
+			summary:  RunSummary{Deferred: 5, ListErrors: map[ErrorReason]int{ErrorReasonForbidden: 1}},
+			policy:   cleanupconfig.ExitStatusConfig{FailOnSafetyCap: true, FailOnForbidden: true},
+			expected: ExitForbidden,
+		},
+		{
+			name:     "timeouts are not forbidden",
+			summary:  RunSummary{ListErrors: map[ErrorReason]int{ErrorReasonTimeout: 1}},
+			policy:   cleanupconfig.ExitStatusConfig{FailOnForbidden: true},
+			expected: ExitOK,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.summary.ExitStatus(tt.policy); got != tt.expected {
+				t.Errorf("Expected exit status %d, got %d", tt.expected, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,277 @@
+package controller
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+
+	cleanupconfig "github.com/infrautils/kubeclean/internal/cleanup_config"
+	"github.com/infrautils/kubeclean/internal/notify"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/client-go/util/jsonpath"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+// cleanUpGenericResources executes every generic rule. Like cert-manager rules, rules whose kind
+// is not served by the cluster are skipped and reported as unavailable rather than failed.
+func (c *PodCleanController) cleanUpGenericResources(ctx context.Context, run *cleanupRun) {
+	logger := log.FromContext(ctx)
+	logger.Info("Starting generic resource cleanup")
+
+	for _, rule := range c.CleanupConfig.GenericCleanupConfig.Rules {
+		if !c.overrides.isEnabled(RuleKindGeneric, rule.Name, rule.Enabled) || !run.inLane(rule.Lane) {
+			continue
+		}
+		ctx := withRule(ctx, rule.Name)
+		if c.ruleUnavailable(ctx, run, RuleKindGeneric, rule.Name, rule.GroupVersionKind()) {
+			continue
+		}
+
+		objects, total, err := FindGenericResources(ctx, c.Client, rule, time.Now())
+		if errors.Is(err, errKindNotInstalled) {
+			c.skipUnavailableRule(ctx, RuleKindGeneric, rule.Name, err)
+			continue
+		}
+		if err != nil {
+			for reason, count := range ErrorReasons(err) {
+				listErrorsTotal.WithLabelValues(rule.Name, string(reason)).Add(float64(count))
+			}
+			logger.Error(err, "Failed to find resources", "rule", rule.Name, "kind", rule.Kind)
+		}
+
+		if len(objects) == 0 {
+			logger.V(1).Info("No resources to cleanup for rule", "rule", rule.Name)
+			c.statuses.record(RuleKindGeneric, rule.Name, time.Now(), 0, 0, 0, err)
+			continue
+		}
+
+		if guardErr := checkGenericDeleteShare(rule, len(objects), total); guardErr != nil {
+			genericDeletionsRefusedTotal.WithLabelValues(rule.Name).Inc()
+			logger.Error(guardErr, "Refusing to clean up resources", "rule", rule.Name, "kind", rule.Kind, "matched", len(objects), "total", total)
+			c.statuses.record(RuleKindGeneric, rule.Name, time.Now(), len(objects), 0, 0, errors.Join(err, guardErr))
+			continue
+		}
+
+		logger.Info("Found resources to cleanup", "rule", rule.Name, "kind", rule.Kind, "count", len(objects))
+		toDelete := make([]client.Object, len(objects))
+		for i := range objects {
+			toDelete[i] = &objects[i]
+		}
+		deleteErr := BatchDeleteObjects(ctx, c.Client, rule.Kind, toDelete, c.CleanupConfig.EffectiveBatchSize(), c.CleanupConfig.EffectiveBatchDelay(), run.DryRun)
+		failed := run.recordDeleteFailures(rule.Name, deleteErr)
+		c.statuses.record(RuleKindGeneric, rule.Name, time.Now(), len(objects), deletedCount(len(objects), run.DryRun)-failed, failed,
+			ruleError(err, deleteErr, len(toDelete), failed))
+
+		c.notifyRule(ctx, run, rule.Name, rule.NotificationSinks,
+			notify.Event{Kind: rule.Kind, Resources: len(objects)}, rule.Kind+"(s)")
+	}
+}
+
+// errNamespacesOnClusterScopedKind is returned for a generic rule that sets namespaces for a
+// cluster-scoped kind, whose resources live in no namespace.
+var errNamespacesOnClusterScopedKind = errors.New("namespaces cannot be set for a cluster-scoped kind")
+
+// genericKindNamespaced reports whether mapper knows the rule's kind as namespaced. It returns
+// errKindNotInstalled when the cluster does not serve the kind.
+func genericKindNamespaced(mapper meta.RESTMapper, rule cleanupconfig.GenericCleanRule) (bool, error) {
+	gvk := rule.GroupVersionKind()
+	mapping, err := mapper.RESTMapping(gvk.GroupKind(), gvk.Version)
+	if meta.IsNoMatchError(err) {
+		return false, fmt.Errorf("%s: %w", gvk, errKindNotInstalled)
+	}
+	if err != nil {
+		return false, fmt.Errorf("failed to resolve the scope of %s: %w", gvk, err)
+	}
+	return mapping.Scope.Name() == meta.RESTScopeNameNamespace, nil
+}
+
+// ValidateGenericRuleScopes checks the enabled generic rules of cfg against the kinds the cluster
+// serves, as mapper knows them: namespaces may only be set for namespaced kinds. Rules whose kind
+// is not installed are not errors; they are skipped until it is.
+func ValidateGenericRuleScopes(mapper meta.RESTMapper, cfg *cleanupconfig.CleanupConfig) error {
+	if !cfg.GenericCleanupConfig.Enabled {
+		return nil
+	}
+
+	var errorMessages string
+	for idx, rule := range cfg.GenericCleanupConfig.Rules {
+		if !rule.Enabled {
+			continue
+		}
+
+		namespaced, err := genericKindNamespaced(mapper, rule)
+		if errors.Is(err, errKindNotInstalled) {
+			continue
+		}
+		if err == nil && !namespaced && len(rule.Namespaces) > 0 {
+			err = fmt.Errorf("%w: %s is cluster-scoped", errNamespacesOnClusterScopedKind, rule.GroupVersionKind().GroupKind())
+		}
+		if err != nil {
+			errorMessages += fmt.Sprintf("rule %d (%s): %v\n", idx+1, rule.Name, err)
+		}
+	}
+
+	if errorMessages == "" {
+		return nil
+	}
+
+	return fmt.Errorf("generic cleanup config validation errors:\n%s", errorMessages)
+}
+
+// errDeleteShareExceeded is returned when a generic rule matches a larger share of its kind than
+// it may delete in one run.
+var errDeleteShareExceeded = errors.New("rule matches too large a share of its kind")
+
+// checkGenericDeleteShare refuses a run of the rule that would delete more than maxDeletePercent of
+// the total resources of its kind in scope, unless the rule is forced. Selectors on unfamiliar
+// kinds are easy to get wrong, and a rule matching most of a kind usually is.
+func checkGenericDeleteShare(rule cleanupconfig.GenericCleanRule, matched, total int) error {
+	limit := rule.EffectiveMaxDeletePercent()
+	if rule.Force || matched*100 <= total*limit {
+		return nil
+	}
+	return fmt.Errorf("%w: %d of %d %s(s) exceed maxDeletePercent %d%%; set force: true to delete them anyway",
+		errDeleteShareExceeded, matched, total, rule.Kind, limit)
+}
+
+// FindGenericResources lists the resources of the rule's kind that satisfy its condition and whose
+// TTL, counted from the timestamp at its timestampPath, expired before now. Resources where the
+// path yields no timestamp have not reached that point of their lifecycle and are not matched.
+// It also returns the total number of resources of the kind in the rule's namespaces, regardless
+// of its selector, to bound the share of them a run deletes. It returns errKindNotInstalled when
+// the cluster does not serve the kind. Cluster-scoped kinds are listed once, and a rule setting
+// namespaces for one fails with errNamespacesOnClusterScopedKind.
+func FindGenericResources(ctx context.Context, k8sClient client.Client, rule cleanupconfig.GenericCleanRule, now time.Time) ([]unstructured.Unstructured, int, error) {
+	gvk := rule.GroupVersionKind()
+
+	namespaced, err := genericKindNamespaced(k8sClient.RESTMapper(), rule)
+	if err != nil {
+		return nil, 0, err
+	}
+	if !namespaced && len(rule.Namespaces) > 0 {
+		return nil, 0, fmt.Errorf("%w: %s is cluster-scoped", errNamespacesOnClusterScopedKind, gvk.GroupKind())
+	}
+
+	var timestampPath, conditionPath *jsonpath.JSONPath
+	if rule.TimestampPath != "" {
+		if timestampPath, err = cleanupconfig.ParseJSONPath(rule.TimestampPath); err != nil {
+			return nil, 0, fmt.Errorf("invalid timestampPath: %w", err)
+		}
+	}
+	if rule.Condition != nil {
+		if conditionPath, err = cleanupconfig.ParseJSONPath(rule.Condition.Path); err != nil {
+			return nil, 0, fmt.Errorf("invalid condition path: %w", err)
+		}
+	}
+
+	// The selector is applied here rather than by the API server, so the total covers every
+	// resource of the kind the rule could reach.
+	selector := labels.Everything()
+	if rule.Selector != nil {
+		if selector, err = metav1.LabelSelectorAsSelector(rule.Selector); err != nil {
+			return nil, 0, err
+		}
+	}
+
+	namespaces := rule.Namespaces
+	if len(namespaces) == 0 {
+		namespaces = []string{""} // All namespaces, or no namespace for cluster-scoped kinds
+	}
+
+	var matched []unstructured.Unstructured
+	var total int
+	var errs []error
+
+	for _, namespace := range namespaces {
+		list := &unstructured.UnstructuredList{}
+		list.SetGroupVersionKind(gvk.GroupVersion().WithKind(gvk.Kind + "List"))
+
+		if err := withThrottleRetry(ctx, "list", func() error {
+			return k8sClient.List(ctx, list, client.InNamespace(namespace))
+		}); err != nil {
+			if meta.IsNoMatchError(err) || apierrors.IsNotFound(err) {
+				return nil, 0, fmt.Errorf("%s: %w", gvk, errKindNotInstalled)
+			}
+			errs = append(errs, newListError(rule.Kind, namespace, err))
+			continue
+		}
+
+		total += len(list.Items)
+		for _, obj := range list.Items {
+			if !selector.Matches(labels.Set(obj.GetLabels())) {
+				continue
+			}
+
+			if obj.GetAnnotations()[AnnotationDisabled] == "true" {
+				continue
+			}
+
+			if conditionPath != nil && !matchesJSONPathCondition(conditionPath, &obj, rule.Condition.Values) {
+				continue
+			}
+
+			since := obj.GetCreationTimestamp().Time
+			if timestampPath != nil {
+				var ok bool
+				if since, ok = jsonPathTimestamp(timestampPath, &obj); !ok {
+					continue
+				}
+			}
+
+			if now.Sub(since) > rule.TTL.Duration {
+				matched = append(matched, obj)
+			}
+		}
+	}
+
+	return matched, total, errors.Join(errs...)
+}
+
+// jsonPathValues returns the values path yields for obj, formatted as kubectl would print them.
+func jsonPathValues(path *jsonpath.JSONPath, obj *unstructured.Unstructured) []string {
+	results, err := path.FindResults(obj.Object)
+	if err != nil {
+		return nil
+	}
+
+	var values []string
+	for _, result := range results {
+		for _, value := range result {
+			if !value.IsValid() || !value.CanInterface() || value.Interface() == nil {
+				continue
+			}
+			values = append(values, fmt.Sprint(value.Interface()))
+		}
+	}
+	return values
+}
+
+// matchesJSONPathCondition reports whether path yields one of values for obj or, without values,
+// whether it yields any non-empty value.
+func matchesJSONPathCondition(path *jsonpath.JSONPath, obj *unstructured.Unstructured, values []string) bool {
+	for _, value := range jsonPathValues(path, obj) {
+		if len(values) == 0 && value != "" || slices.Contains(values, value) {
+			return true
+		}
+	}
+	return false
+}
+
+// jsonPathTimestamp returns the RFC 3339 timestamp path yields for obj. When it yields several,
+// such as the transition times of all conditions, the latest one wins.
+func jsonPathTimestamp(path *jsonpath.JSONPath, obj *unstructured.Unstructured) (time.Time, bool) {
+	var latest time.Time
+	for _, value := range jsonPathValues(path, obj) {
+		if timestamp, err := time.Parse(time.RFC3339, value); err == nil && timestamp.After(latest) {
+			latest = timestamp
+		}
+	}
+	return latest, !latest.IsZero()
+}
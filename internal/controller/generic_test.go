@@ -0,0 +1,248 @@
+package controller
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+	"time"
+
+	cleanupconfig "github.com/infrautils/kubeclean/internal/cleanup_config"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+var workflowGVK = schema.GroupVersionKind{Group: "argoproj.io", Version: "v1alpha1", Kind: "Workflow"}
+
+func newWorkflow(name, phase string, age time.Duration, finished *time.Duration) *unstructured.Unstructured {
+	status := map[string]interface{}{"phase": phase}
+	if finished != nil {
+		status["finishedAt"] = time.Now().Add(-*finished).UTC().Format(time.RFC3339)
+	}
+	obj := &unstructured.Unstructured{Object: map[string]interface{}{"status": status}}
+	obj.SetGroupVersionKind(workflowGVK)
+	obj.SetName(name)
+	obj.SetNamespace("default")
+	obj.SetCreationTimestamp(metav1.NewTime(time.Now().Add(-age)))
+	return obj
+}
+
+func TestPodCleanController_GenericCleanup(t *testing.T) {
+	scheme := runtime.NewScheme()
+	_ = corev1.AddToScheme(scheme)
+
+	mapper := meta.NewDefaultRESTMapper(nil)
+	mapper.Add(workflowGVK, meta.RESTScopeNamespace)
+
+	threeHours, tenMinutes := 3*time.Hour, 10*time.Minute
+	k8sClient := fake.NewClientBuilder().WithScheme(scheme).WithRESTMapper(mapper).WithObjects(
+		newWorkflow("finished-long-ago", "Succeeded", 4*time.Hour, &threeHours),
+		newWorkflow("finished-recently", "Succeeded", 4*time.Hour, &tenMinutes),
+		newWorkflow("failed-long-ago", "Failed", 4*time.Hour, &threeHours),
+		newWorkflow("still-running", "Running", 4*time.Hour, nil),
+	).Build()
+
+	cfg := &cleanupconfig.CleanupConfig{
+		GenericCleanupConfig: cleanupconfig.GenericCleanupConfig{Enabled: true, Rules: []cleanupconfig.GenericCleanRule{{
+			Name: "finished-workflows", Enabled: true, APIVersion: "argoproj.io/v1alpha1", Kind: "Workflow",
+			TTL: cleanupconfig.Duration{Duration: time.Hour}, TimestampPath: ".status.finishedAt",
+			Condition: &cleanupconfig.JSONPathCondition{Path: "{.status.phase}", Values: []string{"Succeeded"}},
+		}}},
+	}
+	NewPodCleanController(k8sClient, scheme, cfg).RunCleanUp(context.Background())
+
+	list := &unstructured.UnstructuredList{}
+	list.SetGroupVersionKind(workflowGVK.GroupVersion().WithKind("WorkflowList"))
+	if err := k8sClient.List(context.Background(), list); err != nil {
+		t.Fatalf("Failed to list Workflows: %v", err)
+	}
+	var remaining []string
+	for _, obj := range list.Items {
+		remaining = append(remaining, obj.GetName())
+	}
+	slices.Sort(remaining)
+
+	if want := []string{"failed-long-ago", "finished-recently", "still-running"}; !slices.Equal(remaining, want) {
+		t.Errorf("Expected %v to remain, got %v", want, remaining)
+	}
+}
+
+func TestPodCleanController_GenericCleanupRefusesLargeShare(t *testing.T) {
+	scheme := runtime.NewScheme()
+	_ = corev1.AddToScheme(scheme)
+
+	mapper := meta.NewDefaultRESTMapper(nil)
+	mapper.Add(workflowGVK, meta.RESTScopeNamespace)
+
+	threeHours := 3 * time.Hour
+	k8sClient := fake.NewClientBuilder().WithScheme(scheme).WithRESTMapper(mapper).WithObjects(
+		newWorkflow("a", "Succeeded", 4*time.Hour, &threeHours),
+		newWorkflow("b", "Succeeded", 4*time.Hour, &threeHours),
+		newWorkflow("c", "Failed", 4*time.Hour, &threeHours),
+		newWorkflow("d", "Running", 10*time.Minute, nil),
+	).Build()
+
+	rule := cleanupconfig.GenericCleanRule{
+		Name: "old-workflows", Enabled: true, APIVersion: "argoproj.io/v1alpha1", Kind: "Workflow",
+		TTL: cleanupconfig.Duration{Duration: time.Hour},
+	}
+	remaining := func() int {
+		t.Helper()
+		list := &unstructured.UnstructuredList{}
+		list.SetGroupVersionKind(workflowGVK.GroupVersion().WithKind("WorkflowList"))
+		if err := k8sClient.List(context.Background(), list); err != nil {
+			t.Fatalf("Failed to list Workflows: %v", err)
+		}
+		return len(list.Items)
+	}
+	run := func(rule cleanupconfig.GenericCleanRule) *PodCleanController {
+		cfg := &cleanupconfig.CleanupConfig{
+			GenericCleanupConfig: cleanupconfig.GenericCleanupConfig{Enabled: true, Rules: []cleanupconfig.GenericCleanRule{rule}},
+		}
+		controller := NewPodCleanController(k8sClient, scheme, cfg)
+		controller.RunCleanUp(context.Background())
+		return controller
+	}
+
+	// Three of four Workflows exceed the default share of 50%.
+	controller := run(rule)
+	if got := remaining(); got != 4 {
+		t.Errorf("Expected the rule to delete nothing, %d Workflows remain", got)
+	}
+	if status, _ := controller.RuleStatus(RuleKindGeneric, rule.Name); status.LastError == "" {
+		t.Errorf("Expected the refusal to be reported in the rule status, got %+v", status)
+	}
+
+	rule.MaxDeletePercent = 75
+	run(rule)
+	if got := remaining(); got != 1 {
+		t.Errorf("Expected a raised maxDeletePercent to allow the deletion, %d Workflows remain", got)
+	}
+}
+
+func TestValidateGenericRuleScopes(t *testing.T) {
+	clusterTemplateGVK := schema.GroupVersionKind{Group: "argoproj.io", Version: "v1alpha1", Kind: "ClusterWorkflowTemplate"}
+	mapper := meta.NewDefaultRESTMapper(nil)
+	mapper.Add(workflowGVK, meta.RESTScopeNamespace)
+	mapper.Add(clusterTemplateGVK, meta.RESTScopeRoot)
+
+	rule := func(kind string, namespaces ...string) cleanupconfig.GenericCleanRule {
+		return cleanupconfig.GenericCleanRule{
+			Name: kind, Enabled: true, APIVersion: "argoproj.io/v1alpha1", Kind: kind,
+			TTL: cleanupconfig.Duration{Duration: time.Hour}, Namespaces: namespaces,
+		}
+	}
+	tests := []struct {
+		name      string
+		rule      cleanupconfig.GenericCleanRule
+		expectErr bool
+	}{
+		{name: "namespaced kind with namespaces", rule: rule("Workflow", "ci")},
+		{name: "cluster-scoped kind without namespaces", rule: rule("ClusterWorkflowTemplate")},
+		{name: "cluster-scoped kind with namespaces", rule: rule("ClusterWorkflowTemplate", "ci"), expectErr: true},
+		{name: "kind not installed", rule: rule("CronWorkflow", "ci")},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &cleanupconfig.CleanupConfig{GenericCleanupConfig: cleanupconfig.GenericCleanupConfig{
+				Enabled: true, Rules: []cleanupconfig.GenericCleanRule{tt.rule},
+			}}
+			err := ValidateGenericRuleScopes(mapper, cfg)
+			if (err != nil) != tt.expectErr {
+				t.Errorf("ValidateGenericRuleScopes() error = %v, expectErr %v", err, tt.expectErr)
+			}
+		})
+	}
+}
+
+func TestFindGenericResources_ClusterScoped(t *testing.T) {
+	scheme := runtime.NewScheme()
+	clusterTemplateGVK := schema.GroupVersionKind{Group: "argoproj.io", Version: "v1alpha1", Kind: "ClusterWorkflowTemplate"}
+	mapper := meta.NewDefaultRESTMapper(nil)
+	mapper.Add(clusterTemplateGVK, meta.RESTScopeRoot)
+
+	template := &unstructured.Unstructured{Object: map[string]interface{}{}}
+	template.SetGroupVersionKind(clusterTemplateGVK)
+	template.SetName("legacy")
+	template.SetCreationTimestamp(metav1.NewTime(time.Now().Add(-3 * time.Hour)))
+	k8sClient := fake.NewClientBuilder().WithScheme(scheme).WithRESTMapper(mapper).WithObjects(template).Build()
+
+	rule := cleanupconfig.GenericCleanRule{
+		Name: "templates", Enabled: true, APIVersion: "argoproj.io/v1alpha1", Kind: "ClusterWorkflowTemplate",
+		TTL: cleanupconfig.Duration{Duration: time.Hour}, Force: true,
+	}
+	objects, total, err := FindGenericResources(context.Background(), k8sClient, rule, time.Now())
+	if err != nil {
+		t.Fatalf("FindGenericResources failed: %v", err)
+	}
+	if len(objects) != 1 || total != 1 {
+		t.Errorf("Expected the cluster-scoped template to match, got %d of %d", len(objects), total)
+	}
+
+	// A reloaded config bypasses startup validation; the rule then fails on every run.
+	rule.Namespaces = []string{"ci"}
+	if _, _, err := FindGenericResources(context.Background(), k8sClient, rule, time.Now()); !errors.Is(err, errNamespacesOnClusterScopedKind) {
+		t.Errorf("Expected errNamespacesOnClusterScopedKind, got %v", err)
+	}
+}
+
+func TestCheckGenericDeleteShare(t *testing.T) {
+	rule := cleanupconfig.GenericCleanRule{Kind: "Workflow"}
+	if err := checkGenericDeleteShare(rule, 5, 10); err != nil {
+		t.Errorf("Expected half of the Workflows to be allowed, got %v", err)
+	}
+	if err := checkGenericDeleteShare(rule, 6, 10); !errors.Is(err, errDeleteShareExceeded) {
+		t.Errorf("Expected errDeleteShareExceeded, got %v", err)
+	}
+
+	rule.Force = true
+	if err := checkGenericDeleteShare(rule, 10, 10); err != nil {
+		t.Errorf("Expected force to bypass the guard, got %v", err)
+	}
+}
+
+func TestJSONPathTimestamp(t *testing.T) {
+	obj := &unstructured.Unstructured{Object: map[string]interface{}{
+		"status": map[string]interface{}{
+			"conditions": []interface{}{
+				map[string]interface{}{"type": "Ready", "lastTransitionTime": "2024-05-01T10:00:00Z"},
+				map[string]interface{}{"type": "Complete", "lastTransitionTime": "2024-05-02T10:00:00Z"},
+				map[string]interface{}{"type": "Archived", "lastTransitionTime": "not a time"},
+			},
+		},
+	}}
+
+	tests := []struct {
+		name   string
+		path   string
+		want   string
+		wantOK bool
+	}{
+		{name: "filtered condition", path: `.status.conditions[?(@.type=="Ready")].lastTransitionTime`, want: "2024-05-01T10:00:00Z", wantOK: true},
+		{name: "latest of several", path: ".status.conditions[*].lastTransitionTime", want: "2024-05-02T10:00:00Z", wantOK: true},
+		{name: "missing field", path: ".status.completionTime", wantOK: false},
+		{name: "not a timestamp", path: `{.status.conditions[?(@.type=="Archived")].lastTransitionTime}`, wantOK: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path, err := cleanupconfig.ParseJSONPath(tt.path)
+			if err != nil {
+				t.Fatalf("ParseJSONPath failed: %v", err)
+			}
+			got, ok := jsonPathTimestamp(path, obj)
+			if ok != tt.wantOK {
+				t.Fatalf("jsonPathTimestamp() ok = %v, want %v", ok, tt.wantOK)
+			}
+			if ok && got.Format(time.RFC3339) != tt.want {
+				t.Errorf("jsonPathTimestamp() = %v, want %s", got, tt.want)
+			}
+		})
+	}
+}
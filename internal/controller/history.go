@@ -0,0 +1,88 @@
+package controller
+
+import (
+	"context"
+	"slices"
+	"sync"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+const (
+	historySize   = 50  // Completed passes kept in memory.
+	deletionsSize = 100 // Pod deletions kept in memory.
+)
+
+// recent keeps the most recent items recorded, up to size.
+type recent[T any] struct {
+	mu    sync.Mutex
+	size  int
+	items []T
+}
+
+// record appends item, dropping the oldest item once the list is full.
+func (r *recent[T]) record(item T) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.items = append(r.items, item)
+	if len(r.items) > r.size {
+		r.items = slices.Delete(r.items, 0, len(r.items)-r.size)
+	}
+}
+
+// latest returns up to limit items, newest first; a limit of 0 returns all of them.
+func (r *recent[T]) latest(limit int) []T {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	items := slices.Clone(r.items)
+	slices.Reverse(items)
+	if limit > 0 && len(items) > limit {
+		items = items[:limit]
+	}
+	return items
+}
+
+// History returns the summaries of up to limit recent cleanup passes, newest first. A limit of 0
+// returns every pass kept in memory.
+func (c *PodCleanController) History(limit int) []RunSummary {
+	return c.history.latest(limit)
+}
+
+// Deletion records a pod deleted by a cleanup pass.
+type Deletion struct {
+	RunID     string    `json:"runID"`
+	Rule      string    `json:"rule"`
+	Namespace string    `json:"namespace"`
+	Name      string    `json:"name"`
+	Time      time.Time `json:"time"`
+}
+
+// RecentDeletions returns up to limit pods recently deleted by cleanup passes, newest first. A
+// limit of 0 returns every deletion kept in memory. Dry-runs delete nothing and are not recorded.
+func (c *PodCleanController) RecentDeletions(limit int) []Deletion {
+	return c.deletions.latest(limit)
+}
+
+// reportDeletion records that pod was deleted by the context's pass and rule. Like reportBatch,
+// it is a no-op outside a cleanup pass.
+func reportDeletion(ctx context.Context, pod *corev1.Pod) {
+	reporter, ok := ctx.Value(progressKey{}).(progressReporter)
+	if !ok || reporter.deletions == nil {
+		return
+	}
+
+	rule, _ := ruleFromContext(ctx)
+	if reporter.deleted != nil {
+		reporter.deleted.add(pod.Namespace, rule)
+	}
+	reporter.deletions.record(Deletion{
+		RunID:     reporter.runID,
+		Rule:      rule,
+		Namespace: pod.Namespace,
+		Name:      pod.Name,
+		Time:      time.Now(),
+	})
+}
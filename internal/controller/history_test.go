@@ -0,0 +1,67 @@
+package controller
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	cleanupconfig "github.com/infrautils/kubeclean/internal/cleanup_config"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestRecent_KeepsNewestItems(t *testing.T) {
+	r := recent[int]{size: 3}
+	for i := 1; i <= 5; i++ {
+		r.record(i)
+	}
+
+	if got := r.latest(0); len(got) != 3 || got[0] != 5 || got[2] != 3 {
+		t.Errorf("Expected the 3 newest items newest first, got %v", got)
+	}
+	if got := r.latest(2); len(got) != 2 || got[0] != 5 {
+		t.Errorf("Expected the 2 newest items, got %v", got)
+	}
+}
+
+func TestRecentDeletions(t *testing.T) {
+	scheme := runtime.NewScheme()
+	_ = corev1.AddToScheme(scheme)
+
+	pod := func(name string) *corev1.Pod {
+		return &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default", CreationTimestamp: metav1.NewTime(time.Now().Add(-2 * time.Hour))},
+			Status:     corev1.PodStatus{Phase: corev1.PodSucceeded},
+		}
+	}
+	client := fake.NewClientBuilder().WithScheme(scheme).WithRuntimeObjects(pod("a"), pod("b")).Build()
+
+	cfg := &cleanupconfig.CleanupConfig{
+		DryRun: true,
+		PodCleanupConfig: cleanupconfig.PodCleanupConfig{
+			Enabled: true,
+			Rules:   []cleanupconfig.PodCleanRule{{Name: "succeeded", Enabled: true, Phase: "Succeeded", TTL: cleanupconfig.Duration{Duration: time.Hour}}},
+		},
+	}
+	controller := NewPodCleanController(client, scheme, cfg)
+
+	controller.RunCleanUp(context.Background())
+	if deletions := controller.RecentDeletions(0); len(deletions) != 0 {
+		t.Fatalf("Expected dry-runs not to record deletions, got %+v", deletions)
+	}
+
+	cfg.DryRun = false
+	summary := controller.RunCleanUp(context.Background())
+
+	deletions := controller.RecentDeletions(0)
+	if len(deletions) != 2 {
+		t.Fatalf("Expected 2 deletions, got %+v", deletions)
+	}
+	for _, deletion := range deletions {
+		if deletion.Rule != "succeeded" || deletion.RunID != summary.RunID || deletion.Namespace != "default" {
+			t.Errorf("Unexpected deletion %+v", deletion)
+		}
+	}
+}
@@ -0,0 +1,254 @@
+package controller
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	cleanupconfig "github.com/infrautils/kubeclean/internal/cleanup_config"
+	"github.com/infrautils/kubeclean/internal/notify"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	discoveryv1 "k8s.io/api/discovery/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+// cleanUpIdleWorkloads executes every idle workload rule. Workloads are scaled to zero once they
+// have been idle for longer than the rule's TTL, measured from when kubeclean first observed them
+// idle, unless the run is dry-run.
+func (c *PodCleanController) cleanUpIdleWorkloads(ctx context.Context, run *cleanupRun) {
+	logger := log.FromContext(ctx)
+	logger.Info("Starting idle workload cleanup")
+
+	if c.idle == nil {
+		c.idle = newOrphanTracker()
+	}
+
+	for _, rule := range c.CleanupConfig.IdleWorkloadConfig.Rules {
+		if !c.overrides.isEnabled(RuleKindIdleWorkload, rule.Name, rule.Enabled) || !run.inLane(rule.Lane) {
+			continue
+		}
+		ctx := withRule(ctx, rule.Name)
+
+		namespaces := rule.Namespaces
+		if len(namespaces) == 0 {
+			namespaces = []string{""} // All namespaces
+		}
+
+		var idle []client.Object
+		var errs []error
+		for _, namespace := range namespaces {
+			found, err := findIdleWorkloads(ctx, c.Client, rule, namespace)
+			idle = append(idle, found...)
+			if err != nil {
+				errs = append(errs, err)
+			}
+		}
+
+		if err := errors.Join(errs...); err != nil {
+			for reason, count := range ErrorReasons(err) {
+				listErrorsTotal.WithLabelValues(rule.Name, string(reason)).Add(float64(count))
+			}
+			logger.Error(err, "Failed to detect idle workloads", "rule", rule.Name)
+		}
+
+		now := time.Now()
+		firstSeen := c.idle.observe(rule.Name, idle, now, len(errs) == 0)
+		idleWorkloads.WithLabelValues(rule.Name).Set(float64(len(idle)))
+
+		var expired []client.Object
+		for _, obj := range idle {
+			idleFor := now.Sub(firstSeen[client.ObjectKeyFromObject(obj)])
+			logger.Info("Found idle workload", "rule", rule.Name, "kind", rule.Kind,
+				"name", obj.GetName(), "namespace", obj.GetNamespace(), "idleFor", idleFor.Round(time.Second))
+			if idleFor >= rule.TTL.Duration {
+				expired = append(expired, obj)
+			}
+		}
+
+		if len(expired) == 0 {
+			c.statuses.record(RuleKindIdleWorkload, rule.Name, now, len(idle), 0, 0, errors.Join(errs...))
+			continue
+		}
+
+		scaleErr := scaleWorkloadsToZero(ctx, c.Client, rule.Kind, expired, run.DryRun)
+		failed := run.recordDeleteFailures(rule.Name, scaleErr)
+		c.statuses.record(RuleKindIdleWorkload, rule.Name, now, len(idle), deletedCount(len(expired), run.DryRun)-failed, failed,
+			ruleError(errors.Join(errs...), scaleErr, len(expired), failed))
+
+		c.notifyRule(ctx, run, rule.Name, rule.NotificationSinks,
+			notify.Event{Kind: rule.Kind, Resources: len(expired), Action: cleanupconfig.ActionScaleToZero}, rule.Kind+"(s)")
+	}
+}
+
+// scaleWorkloadsToZero scales every workload to zero replicas, recording the replicas each had.
+func scaleWorkloadsToZero(ctx context.Context, k8sClient client.Client, kind string, workloads []client.Object, dryRun bool) error {
+	logger := log.FromContext(ctx)
+	var errs []error
+
+	for _, workload := range workloads {
+		if dryRun {
+			logger.Info("DRY RUN: Would scale workload to zero", "kind", kind, "name", workload.GetName(), "namespace", workload.GetNamespace())
+			continue
+		}
+
+		logger.Info("Scaling workload to zero", "kind", kind, "name", workload.GetName(), "namespace", workload.GetNamespace())
+		if err := withThrottleRetry(ctx, "patch", func() error {
+			return client.IgnoreNotFound(scaleToZero(ctx, k8sClient, workload))
+		}); err != nil {
+			logger.Error(err, "Failed to scale workload to zero", "kind", kind, "name", workload.GetName(), "namespace", workload.GetNamespace())
+			errs = append(errs, fmt.Errorf("scale %s %s/%s: %w", kind, workload.GetNamespace(), workload.GetName(), err))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// findIdleWorkloads returns the workloads of the rule's kind in namespace that are running and
+// meet the rule's condition. Workloads already scaled to zero are never idle.
+func findIdleWorkloads(ctx context.Context, k8sClient client.Client, rule cleanupconfig.IdleWorkloadRule, namespace string) ([]client.Object, error) {
+	opts := []client.ListOption{client.InNamespace(namespace)}
+	if rule.Selector != nil {
+		selector, err := metav1.LabelSelectorAsSelector(rule.Selector)
+		if err != nil {
+			return nil, err
+		}
+		opts = append(opts, client.MatchingLabelsSelector{Selector: selector})
+	}
+
+	workloads, err := listScalableWorkloads(ctx, k8sClient, rule.Kind, opts...)
+	if err != nil {
+		return nil, newListError(strings.ToLower(rule.Kind)+"s", namespace, err)
+	}
+
+	var running []client.Object
+	for _, workload := range workloads {
+		if replicas := specReplicas(workload); replicas == nil || *replicas > 0 {
+			running = append(running, workload)
+		}
+	}
+	if len(running) == 0 {
+		return nil, nil
+	}
+
+	if rule.Condition == cleanupconfig.IdleConditionAnnotation {
+		var idle []client.Object
+		for _, workload := range running {
+			if workload.GetAnnotations()[cleanupconfig.IdleAnnotation] == "true" {
+				idle = append(idle, workload)
+			}
+		}
+		return idle, nil
+	}
+
+	return findWorkloadsWithoutTraffic(ctx, k8sClient, running, namespace)
+}
+
+// findWorkloadsWithoutTraffic returns the workloads none of whose pods is a ready endpoint of a
+// Service in namespace. Workloads no Service selects therefore count as idle too.
+func findWorkloadsWithoutTraffic(ctx context.Context, k8sClient client.Client, workloads []client.Object, namespace string) ([]client.Object, error) {
+	var endpointSlices discoveryv1.EndpointSliceList
+	if err := withThrottleRetry(ctx, "list", func() error {
+		return k8sClient.List(ctx, &endpointSlices, client.InNamespace(namespace))
+	}); err != nil {
+		return nil, newListError("endpointslices", namespace, err)
+	}
+
+	serving := map[types.NamespacedName]bool{}
+	for i := range endpointSlices.Items {
+		for _, endpoint := range endpointSlices.Items[i].Endpoints {
+			ref := endpoint.TargetRef
+			if ref == nil || ref.Kind != "Pod" {
+				continue
+			}
+			// A missing ready condition means the endpoint's readiness is unknown and must be
+			// treated as ready.
+			if ready := endpoint.Conditions.Ready; ready != nil && !*ready {
+				continue
+			}
+			serving[types.NamespacedName{Namespace: endpointSlices.Items[i].Namespace, Name: ref.Name}] = true
+		}
+	}
+
+	var pods corev1.PodList
+	if err := withThrottleRetry(ctx, "list", func() error {
+		return k8sClient.List(ctx, &pods, client.InNamespace(namespace))
+	}); err != nil {
+		return nil, newListError("pods", namespace, err)
+	}
+
+	var idle []client.Object
+	for _, workload := range workloads {
+		// Leave workloads whose selector is missing, empty or invalid alone rather than guess.
+		podSelector := workloadSelector(workload)
+		if podSelector == nil {
+			continue
+		}
+		selector, err := metav1.LabelSelectorAsSelector(podSelector)
+		if err != nil || selector.Empty() {
+			continue
+		}
+
+		receivesTraffic := false
+		for i := range pods.Items {
+			pod := &pods.Items[i]
+			if pod.Namespace == workload.GetNamespace() && selector.Matches(labels.Set(pod.Labels)) &&
+				serving[types.NamespacedName{Namespace: pod.Namespace, Name: pod.Name}] {
+				receivesTraffic = true
+				break
+			}
+		}
+
+		if !receivesTraffic {
+			idle = append(idle, workload)
+		}
+	}
+
+	return idle, nil
+}
+
+// listScalableWorkloads lists the Deployments or StatefulSets matching opts.
+func listScalableWorkloads(ctx context.Context, k8sClient client.Client, kind string, opts ...client.ListOption) ([]client.Object, error) {
+	var workloads []client.Object
+
+	switch kind {
+	case cleanupconfig.IdleKindDeployment:
+		var deployments appsv1.DeploymentList
+		if err := withThrottleRetry(ctx, "list", func() error { return k8sClient.List(ctx, &deployments, opts...) }); err != nil {
+			return nil, err
+		}
+		for i := range deployments.Items {
+			workloads = append(workloads, &deployments.Items[i])
+		}
+	case cleanupconfig.IdleKindStatefulSet:
+		var statefulSets appsv1.StatefulSetList
+		if err := withThrottleRetry(ctx, "list", func() error { return k8sClient.List(ctx, &statefulSets, opts...) }); err != nil {
+			return nil, err
+		}
+		for i := range statefulSets.Items {
+			workloads = append(workloads, &statefulSets.Items[i])
+		}
+	default:
+		return nil, fmt.Errorf("unsupported kind %q", kind)
+	}
+
+	return workloads, nil
+}
+
+// workloadSelector returns the pod selector of a Deployment or StatefulSet.
+func workloadSelector(workload client.Object) *metav1.LabelSelector {
+	switch w := workload.(type) {
+	case *appsv1.Deployment:
+		return w.Spec.Selector
+	case *appsv1.StatefulSet:
+		return w.Spec.Selector
+	default:
+		return nil
+	}
+}
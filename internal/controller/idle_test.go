@@ -0,0 +1,154 @@
+package controller
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	cleanupconfig "github.com/infrautils/kubeclean/internal/cleanup_config"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	discoveryv1 "k8s.io/api/discovery/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func newScaledDeployment(name string, replicas int32, annotations map[string]string) *appsv1.Deployment {
+	return &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "dev", Annotations: annotations},
+		Spec: appsv1.DeploymentSpec{
+			Replicas: &replicas,
+			Selector: &metav1.LabelSelector{MatchLabels: map[string]string{"app": name}},
+			Template: corev1.PodTemplateSpec{ObjectMeta: metav1.ObjectMeta{Labels: map[string]string{"app": name}}},
+		},
+	}
+}
+
+func newEndpointSlice(name, pod string, ready bool) *discoveryv1.EndpointSlice {
+	return &discoveryv1.EndpointSlice{
+		ObjectMeta:  metav1.ObjectMeta{Name: name, Namespace: "dev"},
+		AddressType: discoveryv1.AddressTypeIPv4,
+		Endpoints: []discoveryv1.Endpoint{{
+			Addresses:  []string{"10.0.0.1"},
+			Conditions: discoveryv1.EndpointConditions{Ready: &ready},
+			TargetRef:  &corev1.ObjectReference{Kind: "Pod", Namespace: "dev", Name: pod},
+		}},
+	}
+}
+
+func TestPodCleanController_IdleWorkloads(t *testing.T) {
+	scheme := runtime.NewScheme()
+	_ = corev1.AddToScheme(scheme)
+	_ = appsv1.AddToScheme(scheme)
+	_ = discoveryv1.AddToScheme(scheme)
+
+	pod := func(name, app string) *corev1.Pod {
+		return &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "dev", Labels: map[string]string{"app": app}}}
+	}
+
+	k8sClient := fake.NewClientBuilder().WithScheme(scheme).WithObjects(
+		newScaledDeployment("web", 2, nil),
+		newScaledDeployment("unready", 3, nil),
+		newScaledDeployment("unexposed", 1, nil),
+		newScaledDeployment("stopped", 0, nil),
+		newScaledDeployment("marked", 1, map[string]string{cleanupconfig.IdleAnnotation: "true"}),
+		pod("web-1", "web"), pod("unready-1", "unready"), pod("unexposed-1", "unexposed"),
+		newEndpointSlice("web", "web-1", true),
+		newEndpointSlice("unready", "unready-1", false),
+	).Build()
+
+	cleanupCfg := &cleanupconfig.CleanupConfig{
+		DryRun: true,
+		IdleWorkloadConfig: cleanupconfig.IdleWorkloadConfig{Enabled: true, Rules: []cleanupconfig.IdleWorkloadRule{
+			{
+				Name: "no-traffic", Enabled: true, Kind: cleanupconfig.IdleKindDeployment,
+				Condition: cleanupconfig.IdleConditionNoTraffic, TTL: cleanupconfig.Duration{Duration: time.Hour},
+			},
+			{
+				Name: "marked", Enabled: true, Kind: cleanupconfig.IdleKindDeployment, Condition: cleanupconfig.IdleConditionAnnotation,
+			},
+		}},
+	}
+	controller := NewPodCleanController(k8sClient, scheme, cleanupCfg)
+
+	replicas := func(name string) (int32, string) {
+		t.Helper()
+		var deployment appsv1.Deployment
+		if err := k8sClient.Get(context.Background(), types.NamespacedName{Namespace: "dev", Name: name}, &deployment); err != nil {
+			t.Fatalf("Failed to get deployment %s: %v", name, err)
+		}
+		return *deployment.Spec.Replicas, deployment.Annotations[scaledFromAnnotation]
+	}
+
+	// A dry run only tracks idle workloads.
+	controller.RunCleanUp(context.Background())
+
+	seen := controller.idle.firstSeen["no-traffic"]
+	if len(seen) != 3 {
+		t.Fatalf("Expected the unready, unexposed and marked deployments to be idle, got %v", seen)
+	}
+	if _, ok := seen[types.NamespacedName{Namespace: "dev", Name: "web"}]; ok {
+		t.Errorf("Expected a deployment with a ready endpoint not to be idle, got %v", seen)
+	}
+	if got, _ := replicas("marked"); got != 1 {
+		t.Errorf("Expected dry runs not to scale workloads, got %d replicas", got)
+	}
+
+	// The marked deployment has no TTL; the others are scaled once idle for longer than theirs.
+	cleanupCfg.DryRun = false
+	controller.RunCleanUp(context.Background())
+
+	if got, from := replicas("marked"); got != 0 || from != "1" {
+		t.Errorf("Expected the marked deployment to be scaled from 1 to 0, got %d replicas scaled from %q", got, from)
+	}
+	if got, _ := replicas("unready"); got != 3 {
+		t.Errorf("Expected workloads idle for less than the TTL to keep running, got %d replicas", got)
+	}
+
+	for key := range controller.idle.firstSeen["no-traffic"] {
+		controller.idle.firstSeen["no-traffic"][key] = time.Now().Add(-2 * time.Hour)
+	}
+	controller.RunCleanUp(context.Background())
+
+	for name, want := range map[string]int32{"web": 2, "unready": 0, "unexposed": 0, "stopped": 0} {
+		if got, _ := replicas(name); got != want {
+			t.Errorf("Expected deployment %s to have %d replicas, got %d", name, want, got)
+		}
+	}
+	if _, from := replicas("unready"); from != "3" {
+		t.Errorf("Expected the original replicas to be recorded, got %q", from)
+	}
+
+	// Scaled workloads are no longer idle; scaling them back up restarts their TTL.
+	controller.RunCleanUp(context.Background())
+	if seen := controller.idle.firstSeen["no-traffic"]; len(seen) != 0 {
+		t.Errorf("Expected workloads scaled to zero to be forgotten, got %v", seen)
+	}
+}
+
+func TestScaleToZero_RecordsReplicasOnlyWhenKnown(t *testing.T) {
+	scheme := runtime.NewScheme()
+	_ = appsv1.AddToScheme(scheme)
+
+	k8sClient := fake.NewClientBuilder().WithScheme(scheme).WithObjects(newScaledDeployment("api", 4, nil)).Build()
+
+	// A workload that was not fetched has no known replica count to record.
+	unfetched := &appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Namespace: "dev", Name: "api"}}
+	if err := scaleToZero(context.Background(), k8sClient, unfetched); err != nil {
+		t.Fatalf("Failed to scale: %v", err)
+	}
+
+	var deployment appsv1.Deployment
+	if err := k8sClient.Get(context.Background(), client.ObjectKeyFromObject(unfetched), &deployment); err != nil {
+		t.Fatalf("Failed to get deployment: %v", err)
+	}
+	if *deployment.Spec.Replicas != 0 {
+		t.Errorf("Expected 0 replicas, got %d", *deployment.Spec.Replicas)
+	}
+	if from, ok := deployment.Annotations[scaledFromAnnotation]; ok {
+		t.Errorf("Expected no %s annotation, got %q", scaledFromAnnotation, from)
+	}
+}
@@ -0,0 +1,54 @@
+package controller
+
+import (
+	"context"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+// RuleImpact is the number of objects a rule currently matches.
+type RuleImpact struct {
+	Rule    string `json:"rule"`
+	Kind    string `json:"kind"`
+	Enabled bool   `json:"enabled"`
+	Matched int    `json:"matched"`
+	Error   string `json:"error,omitempty"`
+}
+
+// EstimateImpact returns how many objects each pod and generic rule of the config currently
+// matches, in config order. Disabled rules are estimated as if they were enabled, so that a rule
+// matching far more than intended is caught before it is enabled. Each rule is evaluated on its
+// own, ignoring deletion budgets and the pods other rules claim, and nothing is acted on.
+func (c *PodCleanController) EstimateImpact(ctx context.Context) []RuleImpact {
+	cfg := c.CleanupConfig
+	matcher := c.newMatcher()
+	var impacts []RuleImpact
+
+	for _, rule := range cfg.PodCleanupConfig.EffectiveRules() {
+		impact := RuleImpact{Rule: rule.Name, Kind: "Pod", Enabled: cfg.PodCleanupConfig.Enabled && rule.Enabled}
+
+		rule, err := cfg.Constraints.Enforce(withGlobalSettings(rule, cfg.PodCleanupConfig))
+		if err == nil {
+			var pods []corev1.Pod
+			pods, err = matcher.FindPodsToCleanup(withRule(ctx, rule.Name), rule)
+			impact.Matched = len(pods)
+		}
+		if err != nil {
+			impact.Error = err.Error()
+		}
+		impacts = append(impacts, impact)
+	}
+
+	for _, rule := range cfg.GenericCleanupConfig.Rules {
+		impact := RuleImpact{Rule: rule.Name, Kind: rule.Kind, Enabled: cfg.GenericCleanupConfig.Enabled && rule.Enabled}
+
+		objects, _, err := FindGenericResources(withRule(ctx, rule.Name), c.Client, rule, matcher.clock())
+		impact.Matched = len(objects)
+		if err != nil {
+			impact.Error = err.Error()
+		}
+		impacts = append(impacts, impact)
+	}
+
+	return impacts
+}
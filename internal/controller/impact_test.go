@@ -0,0 +1,78 @@
+package controller
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	cleanupconfig "github.com/infrautils/kubeclean/internal/cleanup_config"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestPodCleanController_EstimateImpact(t *testing.T) {
+	scheme := runtime.NewScheme()
+	_ = corev1.AddToScheme(scheme)
+
+	mapper := meta.NewDefaultRESTMapper(nil)
+	mapper.Add(workflowGVK, meta.RESTScopeNamespace)
+
+	newPod := func(name string, phase corev1.PodPhase) *corev1.Pod {
+		return &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:              name,
+				Namespace:         "default",
+				CreationTimestamp: metav1.NewTime(time.Now().Add(-2 * time.Hour)),
+			},
+			Status: corev1.PodStatus{Phase: phase},
+		}
+	}
+	threeHours := 3 * time.Hour
+	k8sClient := fake.NewClientBuilder().WithScheme(scheme).WithRESTMapper(mapper).WithObjects(
+		newPod("succeeded-1", corev1.PodSucceeded),
+		newPod("succeeded-2", corev1.PodSucceeded),
+		newPod("failed", corev1.PodFailed),
+		newPod("running", corev1.PodRunning),
+		newWorkflow("finished", "Succeeded", 4*time.Hour, &threeHours),
+	).Build()
+
+	cfg := &cleanupconfig.CleanupConfig{
+		PodCleanupConfig: cleanupconfig.PodCleanupConfig{Enabled: true, Rules: []cleanupconfig.PodCleanRule{
+			{Name: "succeeded", Enabled: true, Phase: "Succeeded", TTL: cleanupconfig.Duration{Duration: time.Hour}},
+			{Name: "failed", Enabled: false, Phase: "Failed", TTL: cleanupconfig.Duration{Duration: time.Hour}},
+		}},
+		GenericCleanupConfig: cleanupconfig.GenericCleanupConfig{Rules: []cleanupconfig.GenericCleanRule{
+			{
+				Name: "workflows", Enabled: true, APIVersion: "argoproj.io/v1alpha1", Kind: "Workflow",
+				TTL: cleanupconfig.Duration{Duration: time.Hour}, TimestampPath: ".status.finishedAt",
+			},
+			{Name: "missing", Enabled: true, APIVersion: "example.com/v1", Kind: "Missing", TTL: cleanupconfig.Duration{Duration: time.Hour}},
+		}},
+	}
+	controller := NewPodCleanController(k8sClient, scheme, cfg)
+
+	impacts := controller.EstimateImpact(context.Background())
+	want := []RuleImpact{
+		{Rule: "succeeded", Kind: "Pod", Enabled: true, Matched: 2},
+		{Rule: "failed", Kind: "Pod", Enabled: false, Matched: 1},
+		{Rule: "workflows", Kind: "Workflow", Enabled: false, Matched: 1},
+	}
+	if len(impacts) != 4 {
+		t.Fatalf("Expected 4 impacts, got %+v", impacts)
+	}
+	for i, impact := range want {
+		if impacts[i] != impact {
+			t.Errorf("Expected impact %+v, got %+v", impact, impacts[i])
+		}
+	}
+	if impacts[3].Rule != "missing" || impacts[3].Error == "" {
+		t.Errorf("Expected an error for a rule whose kind is not installed, got %+v", impacts[3])
+	}
+
+	if got := remainingPodNames(t, k8sClient); len(got) != 4 {
+		t.Errorf("Expected estimating to delete nothing, got remaining pods %v", got)
+	}
+}
@@ -0,0 +1,24 @@
+package controller
+
+import (
+	cleanupconfig "github.com/infrautils/kubeclean/internal/cleanup_config"
+)
+
+// laneScheduler counts full runs to decide when the slow lane is due. Guarded by runMu.
+type laneScheduler struct {
+	fullRuns int
+}
+
+// next records a full run under cfg and reports whether the slow lane runs in it: the first full
+// run after startup does, then every cfg.Lanes.SlowLaneEvery() runs.
+func (s *laneScheduler) next(cfg *cleanupconfig.CleanupConfig) bool {
+	due := s.fullRuns%cfg.Lanes.SlowLaneEvery() == 0
+	s.fullRuns++
+	return due
+}
+
+// inLane reports whether a rule in lane runs in run. Evaluations outside a run, which have no
+// run, cover every lane.
+func (r *cleanupRun) inLane(lane string) bool {
+	return r == nil || lane != cleanupconfig.LaneSlow || r.slowLane
+}
@@ -0,0 +1,93 @@
+package controller
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	cleanupconfig "github.com/infrautils/kubeclean/internal/cleanup_config"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestRunCleanUp_Lanes(t *testing.T) {
+	scheme := runtime.NewScheme()
+	_ = corev1.AddToScheme(scheme)
+
+	created := metav1.NewTime(time.Now().Add(-2 * time.Hour))
+	client := fake.NewClientBuilder().WithScheme(scheme).WithRuntimeObjects(
+		&corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: "evicted", Namespace: "default", CreationTimestamp: created},
+			Status:     corev1.PodStatus{Phase: corev1.PodFailed},
+		},
+		&corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: "done", Namespace: "default", CreationTimestamp: created},
+			Status:     corev1.PodStatus{Phase: corev1.PodSucceeded},
+		},
+	).Build()
+
+	controller := NewPodCleanController(client, scheme, &cleanupconfig.CleanupConfig{
+		DryRun: true,
+		PodCleanupConfig: cleanupconfig.PodCleanupConfig{
+			Enabled: true,
+			Rules: []cleanupconfig.PodCleanRule{
+				{Name: "failed", Enabled: true, Lane: cleanupconfig.LaneFast, Phase: "Failed", TTL: cleanupconfig.Duration{Duration: time.Hour}},
+				{Name: "succeeded", Enabled: true, Lane: cleanupconfig.LaneSlow, Phase: "Succeeded", TTL: cleanupconfig.Duration{Duration: time.Hour}},
+			},
+		},
+		Lanes: cleanupconfig.LanesConfig{SlowEvery: 2},
+	})
+
+	for run, slow := range []bool{true, false, true, false} {
+		summary := controller.RunCleanUp(context.Background())
+		expected := map[string]int{"failed": 1}
+		if slow {
+			expected["succeeded"] = 1
+		}
+		if summary.SlowLane != slow || len(summary.MatchedByRule) != len(expected) || summary.MatchedByRule["succeeded"] != expected["succeeded"] {
+			t.Errorf("Run %d: expected the slow lane to run: %t, got %t and %v matched", run+1, slow, summary.SlowLane, summary.MatchedByRule)
+		}
+	}
+
+	// Targeted passes run every lane without moving the schedule.
+	if summary := controller.runCleanUp(context.Background(), "targeted", runScope{Namespace: "default"}); summary.MatchedByRule["succeeded"] != 1 {
+		t.Errorf("Expected the targeted pass to run the slow lane, got %v matched", summary.MatchedByRule)
+	}
+	if summary := controller.RunCleanUp(context.Background()); !summary.SlowLane {
+		t.Errorf("Expected the fifth full run to run the slow lane")
+	}
+}
+
+func TestRunCleanUp_LanesApplyToOverriddenRules(t *testing.T) {
+	scheme := runtime.NewScheme()
+	_ = corev1.AddToScheme(scheme)
+
+	client := fake.NewClientBuilder().WithScheme(scheme).WithRuntimeObjects(&corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "done", Namespace: "default", CreationTimestamp: metav1.NewTime(time.Now().Add(-2 * time.Hour))},
+		Status:     corev1.PodStatus{Phase: corev1.PodSucceeded},
+	}).Build()
+
+	controller := NewPodCleanController(client, scheme, &cleanupconfig.CleanupConfig{
+		DryRun: true,
+		PodCleanupConfig: cleanupconfig.PodCleanupConfig{
+			Enabled: true,
+			Rules: []cleanupconfig.PodCleanRule{
+				{Name: "succeeded", Enabled: false, Lane: cleanupconfig.LaneSlow, Phase: "Succeeded", TTL: cleanupconfig.Duration{Duration: time.Hour}},
+			},
+		},
+		Lanes: cleanupconfig.LanesConfig{SlowEvery: 2},
+	})
+	if _, _, err := controller.SetRuleEnabled(RuleKindPod, "succeeded", true); err != nil {
+		t.Fatalf("Failed to enable rule: %v", err)
+	}
+
+	// A rule enabled at runtime still only runs with its lane.
+	for run, slow := range []bool{true, false, true} {
+		summary := controller.RunCleanUp(context.Background())
+		if matched := summary.MatchedByRule["succeeded"]; (matched == 1) != slow {
+			t.Errorf("Run %d: expected the rule to run: %t, got %d matched", run+1, slow, matched)
+		}
+	}
+}
@@ -0,0 +1,185 @@
+package controller
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"time"
+
+	cleanupconfig "github.com/infrautils/kubeclean/internal/cleanup_config"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/util/retry"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+// Keys of the ConfigMap persisting the last run.
+const (
+	lastRunStartedKey = "started" // When the last full run started, in RFC 3339.
+	lastRunRulesKey   = "rules"   // RuleStatus of every rule, with its kind.
+)
+
+// restore adds the statuses and cooldowns of rules that were not evaluated or put in cooldown
+// since startup.
+func (s *ruleStatuses) restore(statuses []RuleStatus) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, status := range statuses {
+		if _, ok := s.cooldowns[status.Name]; !ok && status.Kind == RuleKindPod && status.CooldownRuns > 0 {
+			s.cooldowns[status.Name] = status.CooldownRuns
+		}
+		status.CooldownRuns = 0
+		key := ruleKey{kind: status.Kind, name: status.Name}
+		if _, ok := s.statuses[key]; !ok && !status.LastRunTime.IsZero() {
+			s.statuses[key] = status
+		}
+	}
+}
+
+// decodeRuleStatuses decodes the persisted rule statuses. Statuses persisted by name only, before
+// they had kinds, cannot be told apart; only their cooldowns, which only pod rules have, are kept.
+func decodeRuleStatuses(data string) ([]RuleStatus, error) {
+	var statuses []RuleStatus
+	if !strings.HasPrefix(strings.TrimSpace(data), "{") {
+		return statuses, unmarshalIfSet(data, &statuses)
+	}
+
+	var byName map[string]RuleStatus
+	if err := unmarshalIfSet(data, &byName); err != nil {
+		return nil, err
+	}
+	for name, status := range byName {
+		if status.CooldownRuns > 0 {
+			statuses = append(statuses, RuleStatus{Kind: RuleKindPod, Name: name, CooldownRuns: status.CooldownRuns})
+		}
+	}
+	return statuses, nil
+}
+
+// stateReader returns the reader persisted state is loaded with.
+func (c *PodCleanController) stateReader() client.Reader {
+	if c.APIReader != nil {
+		return c.APIReader
+	}
+	return c.Client
+}
+
+// loadLastRun reads the persisted last run, restores the statuses of rules not evaluated since
+// startup, and returns when the last full run started. It returns the zero time when nothing is
+// persisted or the ConfigMap cannot be read.
+func (c *PodCleanController) loadLastRun(ctx context.Context) time.Time {
+	cfg := c.CleanupConfig.LastRun
+	namespace, name, ok := cfg.ConfigMapKey()
+	if !ok {
+		return time.Time{}
+	}
+	logger := log.FromContext(ctx).WithValues("configMap", cfg.ConfigMap)
+
+	c.runMu.Lock()
+	defer c.runMu.Unlock()
+
+	configMap := &corev1.ConfigMap{}
+	err := withThrottleRetry(ctx, "get", func() error {
+		return c.stateReader().Get(ctx, types.NamespacedName{Namespace: namespace, Name: name}, configMap)
+	})
+	if apierrors.IsNotFound(err) {
+		return time.Time{}
+	}
+	if err != nil {
+		logger.Error(err, "Failed to load the last run")
+		return time.Time{}
+	}
+
+	statuses, err := decodeRuleStatuses(configMap.Data[lastRunRulesKey])
+	if err != nil {
+		logger.Error(err, "Failed to decode the persisted rule statuses")
+	}
+	c.statuses.restore(statuses)
+
+	started, err := time.Parse(time.RFC3339, configMap.Data[lastRunStartedKey])
+	if err != nil {
+		logger.Error(err, "Failed to decode the start of the last run")
+		return time.Time{}
+	}
+	c.lastStarted = started
+	return started
+}
+
+// saveLastRun persists the rule statuses and when the last full run started to the configured
+// ConfigMap, if any. Failures are logged; the schedule then falls back to a full interval after
+// the next restart. Callers hold runMu.
+func (c *PodCleanController) saveLastRun(ctx context.Context) {
+	cfg := c.CleanupConfig.LastRun
+	namespace, name, ok := cfg.ConfigMapKey()
+	if !ok {
+		return
+	}
+
+	rules, err := json.Marshal(c.RuleStatuses())
+	if err != nil {
+		log.FromContext(ctx).Error(err, "Failed to encode the rule statuses")
+		return
+	}
+	data := map[string]string{lastRunRulesKey: string(rules)}
+	if !c.lastStarted.IsZero() {
+		data[lastRunStartedKey] = c.lastStarted.UTC().Format(time.RFC3339)
+	}
+
+	key := types.NamespacedName{Namespace: namespace, Name: name}
+	if err := retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		configMap := &corev1.ConfigMap{}
+		err := withThrottleRetry(ctx, "get", func() error { return c.Client.Get(ctx, key, configMap) })
+		if apierrors.IsNotFound(err) {
+			configMap = &corev1.ConfigMap{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      name,
+					Namespace: namespace,
+					Labels:    map[string]string{managedByLabel: managedByKubeclean},
+				},
+				Data: data,
+			}
+			return withThrottleRetry(ctx, "create", func() error { return c.Client.Create(ctx, configMap) })
+		}
+		if err != nil {
+			return err
+		}
+
+		configMap.Data = data
+		return withThrottleRetry(ctx, "update", func() error { return c.Client.Update(ctx, configMap) })
+	}); err != nil {
+		log.FromContext(ctx).Error(err, "Failed to persist the last run", "configMap", cfg.ConfigMap)
+	}
+}
+
+// startupDelay returns how long after startup the first scheduled run starts. With a persisted
+// last run, it is an interval after that run, so restarts keep the schedule's cadence instead of
+// postponing it. A run missed while the controller was down starts right away with the runOnce
+// missedRunPolicy, unless it is later than startingDeadline, and a full interval later otherwise.
+func (c *PodCleanController) startupDelay(ctx context.Context, interval time.Duration, now time.Time) time.Duration {
+	last := c.loadLastRun(ctx)
+	if last.IsZero() {
+		return interval
+	}
+
+	next := last.Add(interval)
+	if next.After(now) {
+		return next.Sub(now)
+	}
+
+	logger := log.FromContext(ctx).WithValues("lastRun", last)
+	if c.CleanupConfig.MissedRunPolicy != cleanupconfig.MissedRunPolicyRunOnce {
+		logger.Info("Skipping the scheduled run missed while the controller was down")
+		return interval
+	}
+	if deadline := c.CleanupConfig.StartingDeadline.Duration; deadline > 0 && now.Sub(next) > deadline {
+		logger.Info("Skipping the scheduled run missed while the controller was down, its starting deadline passed",
+			"startingDeadline", deadline)
+		return interval
+	}
+	logger.Info("Running the scheduled run missed while the controller was down")
+	return 0
+}
@@ -0,0 +1,102 @@
+package controller
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	cleanupconfig "github.com/infrautils/kubeclean/internal/cleanup_config"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestRunCleanUp_PersistsLastRun(t *testing.T) {
+	scheme := runtime.NewScheme()
+	_ = corev1.AddToScheme(scheme)
+
+	client := fake.NewClientBuilder().WithScheme(scheme).WithRuntimeObjects(&corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "done", Namespace: "default", CreationTimestamp: metav1.NewTime(time.Now().Add(-2 * time.Hour))},
+		Status:     corev1.PodStatus{Phase: corev1.PodSucceeded},
+	}).Build()
+
+	cfg := &cleanupconfig.CleanupConfig{
+		PodCleanupConfig: cleanupconfig.PodCleanupConfig{
+			Enabled: true,
+			Rules:   []cleanupconfig.PodCleanRule{{Name: "succeeded", Enabled: true, Phase: "Succeeded", TTL: cleanupconfig.Duration{Duration: time.Hour}}},
+		},
+		LastRun: cleanupconfig.LastRunConfig{ConfigMap: "kubeclean/kubeclean-last-run"},
+	}
+	summary := NewPodCleanController(client, scheme, cfg).RunCleanUp(context.Background())
+
+	// A restarted controller picks up the last run and the rule statuses.
+	restarted := NewPodCleanController(client, scheme, cfg)
+	started := restarted.loadLastRun(context.Background())
+	if !started.Equal(summary.Started.Truncate(time.Second)) {
+		t.Errorf("Expected the last run to have started at %v, got %v", summary.Started.Truncate(time.Second), started)
+	}
+	status, ok := restarted.RuleStatus(RuleKindPod, "succeeded")
+	if !ok || status.LastMatched != 1 || status.LastDeleted != 1 {
+		t.Errorf("Expected the rule status to be restored, got %+v", status)
+	}
+}
+
+func TestPodCleanController_StartupDelay(t *testing.T) {
+	scheme := runtime.NewScheme()
+	_ = corev1.AddToScheme(scheme)
+
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	newController := func(lastRun *time.Time, policy string, deadline time.Duration) *PodCleanController {
+		builder := fake.NewClientBuilder().WithScheme(scheme)
+		if lastRun != nil {
+			builder = builder.WithObjects(&corev1.ConfigMap{
+				ObjectMeta: metav1.ObjectMeta{Name: "last-run", Namespace: "kubeclean"},
+				Data:       map[string]string{lastRunStartedKey: lastRun.Format(time.RFC3339)},
+			})
+		}
+		cfg := &cleanupconfig.CleanupConfig{
+			LastRun:          cleanupconfig.LastRunConfig{ConfigMap: "kubeclean/last-run"},
+			MissedRunPolicy:  policy,
+			StartingDeadline: cleanupconfig.Duration{Duration: deadline},
+		}
+		return NewPodCleanController(builder.Build(), scheme, cfg)
+	}
+	at := func(d time.Duration) *time.Time {
+		t := now.Add(d)
+		return &t
+	}
+
+	tests := []struct {
+		name     string
+		lastRun  *time.Time
+		policy   string
+		deadline time.Duration
+		expected time.Duration
+	}{
+		{name: "nothing persisted", policy: cleanupconfig.MissedRunPolicyRunOnce, expected: 10 * time.Minute},
+		{name: "keeps the cadence", lastRun: at(-4 * time.Minute), policy: cleanupconfig.MissedRunPolicyRunOnce, expected: 6 * time.Minute},
+		{name: "missed run waits an interval by default", lastRun: at(-time.Hour), expected: 10 * time.Minute},
+		{name: "missed run skipped", lastRun: at(-time.Hour), policy: cleanupconfig.MissedRunPolicySkip, expected: 10 * time.Minute},
+		{name: "missed run runs once at startup", lastRun: at(-time.Hour), policy: cleanupconfig.MissedRunPolicyRunOnce, expected: 0},
+		{name: "missed run within its starting deadline", lastRun: at(-time.Hour), policy: cleanupconfig.MissedRunPolicyRunOnce, deadline: time.Hour, expected: 0},
+		{name: "missed run past its starting deadline", lastRun: at(-time.Hour), policy: cleanupconfig.MissedRunPolicyRunOnce, deadline: 30 * time.Minute, expected: 10 * time.Minute},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := newController(tt.lastRun, tt.policy, tt.deadline).startupDelay(context.Background(), 10*time.Minute, now); got != tt.expected {
+				t.Errorf("Expected a startup delay of %v, got %v", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestDecodeRuleStatuses_KeepsCooldownsOfLegacyMap(t *testing.T) {
+	statuses, err := decodeRuleStatuses(`{"succeeded":{"lastMatched":3,"cooldownRuns":2},"stale":{"lastMatched":1}}`)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(statuses) != 1 || statuses[0].Kind != RuleKindPod || statuses[0].Name != "succeeded" || statuses[0].CooldownRuns != 2 {
+		t.Errorf("Expected only the pod rule cooldown to be kept, got %+v", statuses)
+	}
+}
@@ -0,0 +1,68 @@
+package controller
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+
+	cleanupconfig "github.com/infrautils/kubeclean/internal/cleanup_config"
+	"github.com/infrautils/kubeclean/internal/ledger"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+// recordRun appends run, which finished with summary, to the run ledger when it is enabled, after
+// rotating a full ledger file. The new head hash is logged, so that the logs anchor the chain outside the ledger file. Failures
+// are logged; a missing record never fails the run.
+func (c *PodCleanController) recordRun(ctx context.Context, summary RunSummary, run *cleanupRun) {
+	cfg := c.CleanupConfig.Ledger
+	if !cfg.Enabled {
+		return
+	}
+	logger := log.FromContext(ctx).WithValues("ledger", cfg.FilePath())
+
+	configHash, err := hashConfig(c.CleanupConfig)
+	if err != nil {
+		logger.Error(err, "Failed to hash the config for the run ledger")
+		return
+	}
+
+	record := ledger.Record{
+		Time:           summary.Finished,
+		RunID:          summary.RunID,
+		Namespace:      summary.Namespace,
+		Node:           summary.Node,
+		DryRun:         run.DryRun,
+		ConfigHash:     configHash,
+		Matched:        summary.Matched,
+		DeleteFailures: summary.DeleteFailures,
+	}
+	for _, rules := range run.deleted {
+		if record.Deleted == nil {
+			record.Deleted = map[string]int{}
+		}
+		for rule, count := range rules {
+			record.Deleted[rule] += count
+		}
+	}
+
+	if err := c.ledger.Rotate(cfg.FilePath(), cfg.MaxRecords, cfg.KeepCount()); err != nil {
+		logger.Error(err, "Failed to rotate the ledger")
+	}
+	record, err = c.ledger.Append(cfg.FilePath(), record)
+	if err != nil {
+		logger.Error(err, "Failed to append the run to the ledger")
+		return
+	}
+	logger.Info("Recorded run in the ledger", "seq", record.Seq, "hash", record.Hash)
+}
+
+// hashConfig returns the SHA-256 of the config's JSON encoding.
+func hashConfig(cfg *cleanupconfig.CleanupConfig) (string, error) {
+	data, err := json.Marshal(cfg)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:]), nil
+}
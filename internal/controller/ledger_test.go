@@ -0,0 +1,92 @@
+package controller
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	cleanupconfig "github.com/infrautils/kubeclean/internal/cleanup_config"
+	"github.com/infrautils/kubeclean/internal/ledger"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestRunCleanUp_RecordsRunInLedger(t *testing.T) {
+	scheme := runtime.NewScheme()
+	_ = corev1.AddToScheme(scheme)
+
+	newPod := func(name string) *corev1.Pod {
+		return &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default", CreationTimestamp: metav1.NewTime(time.Now().Add(-2 * time.Hour))},
+			Status:     corev1.PodStatus{Phase: corev1.PodSucceeded},
+		}
+	}
+	client := fake.NewClientBuilder().WithScheme(scheme).WithRuntimeObjects(newPod("a"), newPod("b")).Build()
+
+	path := filepath.Join(t.TempDir(), "ledger.jsonl")
+	cfg := &cleanupconfig.CleanupConfig{
+		PodCleanupConfig: cleanupconfig.PodCleanupConfig{
+			Enabled: true,
+			Rules:   []cleanupconfig.PodCleanRule{{Name: "succeeded", Enabled: true, Phase: "Succeeded", TTL: cleanupconfig.Duration{Duration: time.Hour}}},
+		},
+		Ledger: cleanupconfig.LedgerConfig{Enabled: true, Path: path},
+	}
+	controller := NewPodCleanController(client, scheme, cfg)
+	first := controller.RunCleanUp(context.Background())
+	second := controller.RunCleanUp(context.Background())
+
+	file, err := os.Open(path)
+	if err != nil {
+		t.Fatalf("Failed to open the ledger: %v", err)
+	}
+	defer file.Close()
+	records, err := ledger.Read(file)
+	if err != nil {
+		t.Fatalf("Expected a valid ledger, got %v", err)
+	}
+	if len(records) != 2 {
+		t.Fatalf("Expected two records, got %+v", records)
+	}
+
+	if records[0].RunID != first.RunID || records[0].Matched != 2 || records[0].Deleted["succeeded"] != 2 {
+		t.Errorf("Expected the first run to be recorded with 2 deletions, got %+v", records[0])
+	}
+	if records[1].RunID != second.RunID || records[1].Matched != 0 || len(records[1].Deleted) != 0 {
+		t.Errorf("Expected the second run to be recorded without deletions, got %+v", records[1])
+	}
+	if records[0].ConfigHash == "" || records[0].ConfigHash != records[1].ConfigHash {
+		t.Errorf("Expected both runs to record the same config hash, got %q and %q", records[0].ConfigHash, records[1].ConfigHash)
+	}
+}
+
+func TestRunCleanUp_RotatesLedger(t *testing.T) {
+	scheme := runtime.NewScheme()
+	_ = corev1.AddToScheme(scheme)
+	client := fake.NewClientBuilder().WithScheme(scheme).Build()
+
+	path := filepath.Join(t.TempDir(), "ledger.jsonl")
+	cfg := &cleanupconfig.CleanupConfig{
+		PodCleanupConfig: cleanupconfig.PodCleanupConfig{
+			Enabled: true,
+			Rules:   []cleanupconfig.PodCleanRule{{Name: "succeeded", Enabled: true, Phase: "Succeeded", TTL: cleanupconfig.Duration{Duration: time.Hour}}},
+		},
+		Ledger: cleanupconfig.LedgerConfig{Enabled: true, Path: path, MaxRecords: 1, Keep: 1},
+	}
+	controller := NewPodCleanController(client, scheme, cfg)
+	for range 3 {
+		controller.RunCleanUp(context.Background())
+	}
+
+	// The first record was rotated out and removed; the second is in the one rotated file kept.
+	records, err := ledger.ReadFiles(path)
+	if err != nil {
+		t.Fatalf("Expected a valid ledger, got %v", err)
+	}
+	if len(records) != 2 || records[0].Seq != 2 || records[1].Seq != 3 {
+		t.Errorf("Expected records 2 and 3 to be kept, got %+v", records)
+	}
+}
@@ -0,0 +1,98 @@
+package controller
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"sync"
+
+	"github.com/infrautils/kubeclean/internal/logship"
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+// logCaptureConcurrency is the number of pods whose logs are captured at once.
+const logCaptureConcurrency = 4
+
+// forwardLogs captures the logs of the pods a rule is about to delete and pushes them to the
+// run's log backend, in requests of at most maxLinesPerPush lines. Failures are logged and
+// counted. With holdOnPushFailure, it returns the pods whose logs could not be pushed, which must
+// not be deleted in this run; capture failures, e.g. of containers that never started, never
+// hold a pod back.
+func (c *PodCleanController) forwardLogs(ctx context.Context, run *cleanupRun, rule string, pods []corev1.Pod) []corev1.Pod {
+	if run.logs == nil || run.DryRun {
+		return nil
+	}
+
+	cfg := c.CleanupConfig.LogForwarding
+	captured := make([][]logship.Entry, len(pods))
+	captureErrs := make([]error, len(pods))
+
+	var wg sync.WaitGroup
+	slots := make(chan struct{}, logCaptureConcurrency)
+	for i := range pods {
+		pod := &pods[i]
+		if !cfg.CapturesPhase(pod.Status.Phase) {
+			continue
+		}
+		wg.Add(1)
+		slots <- struct{}{}
+		go func() {
+			defer func() { <-slots; wg.Done() }()
+			entries, err := logship.Capture(ctx, c.Logs, rule, pod, cfg.EffectiveTailLines())
+			if err != nil {
+				err = fmt.Errorf("capture logs of pod %s/%s: %w", pod.Namespace, pod.Name, err)
+			}
+			captured[i], captureErrs[i] = entries, err
+		}()
+	}
+	wg.Wait()
+
+	errs := slices.DeleteFunc(captureErrs, func(err error) bool { return err == nil })
+	var held []corev1.Pod
+
+	// A pod's entries always go into the same push, so a failed push holds back whole pods.
+	var entries []logship.Entry
+	var pushed []corev1.Pod
+	var lines int
+	flush := func() {
+		if len(entries) == 0 {
+			return
+		}
+		if err := run.logs.Push(ctx, entries); err != nil {
+			errs = append(errs, fmt.Errorf("push logs of %d pod(s): %w", len(pushed), err))
+			if cfg.HoldOnPushFailure {
+				held = append(held, pushed...)
+			}
+		} else {
+			forwardedLogsTotal.WithLabelValues(rule).Add(float64(len(entries)))
+		}
+		entries, pushed, lines = nil, nil, 0
+	}
+	for i := range pods {
+		if len(captured[i]) == 0 {
+			continue
+		}
+		podLines := 0
+		for _, entry := range captured[i] {
+			podLines += len(entry.Lines)
+		}
+		if lines > 0 && lines+podLines > cfg.LinesPerPush() {
+			flush()
+		}
+		entries = append(entries, captured[i]...)
+		pushed = append(pushed, pods[i])
+		lines += podLines
+	}
+	flush()
+
+	if err := errors.Join(errs...); err != nil {
+		logForwardFailuresTotal.WithLabelValues(rule).Add(float64(len(errs)))
+		log.FromContext(ctx).Error(err, "Failed to forward logs of deleted pods", "rule", rule)
+	}
+	if len(held) > 0 {
+		log.FromContext(ctx).Info("Holding back pods whose logs could not be pushed", "rule", rule, "count", len(held))
+	}
+	return held
+}
@@ -0,0 +1,129 @@
+package controller
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	cleanupconfig "github.com/infrautils/kubeclean/internal/cleanup_config"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	kubefake "k8s.io/client-go/kubernetes/fake"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestRunCleanUp_ForwardsLogsBeforeDeleting(t *testing.T) {
+	scheme := runtime.NewScheme()
+	_ = corev1.AddToScheme(scheme)
+
+	var mu sync.Mutex
+	var pushes []string
+	loki := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		mu.Lock()
+		pushes = append(pushes, string(body))
+		mu.Unlock()
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer loki.Close()
+
+	newPod := func(name string, phase corev1.PodPhase) *corev1.Pod {
+		return &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default", CreationTimestamp: metav1.NewTime(time.Now().Add(-2 * time.Hour))},
+			Spec:       corev1.PodSpec{Containers: []corev1.Container{{Name: "main"}}},
+			Status:     corev1.PodStatus{Phase: phase},
+		}
+	}
+	failed, succeeded := newPod("failed", corev1.PodFailed), newPod("succeeded", corev1.PodSucceeded)
+
+	cfg := &cleanupconfig.CleanupConfig{
+		DryRun: true,
+		PodCleanupConfig: cleanupconfig.PodCleanupConfig{
+			Enabled: true,
+			Rules: []cleanupconfig.PodCleanRule{
+				{Name: "failed", Enabled: true, Phase: "Failed", TTL: cleanupconfig.Duration{Duration: time.Hour}},
+				{Name: "succeeded", Enabled: true, Phase: "Succeeded", TTL: cleanupconfig.Duration{Duration: time.Hour}},
+			},
+		},
+		LogForwarding: cleanupconfig.LogForwardingConfig{Enabled: true, Backend: cleanupconfig.LogBackendLoki, URL: loki.URL},
+	}
+	controller := NewPodCleanController(fake.NewClientBuilder().WithScheme(scheme).WithRuntimeObjects(failed, succeeded).Build(), scheme, cfg)
+	controller.Logs = kubefake.NewClientset(failed, succeeded).CoreV1()
+
+	controller.RunCleanUp(context.Background())
+	if len(pushes) != 0 {
+		t.Fatalf("Expected dry-runs not to forward logs, got %v", pushes)
+	}
+
+	cfg.DryRun = false
+	controller.RunCleanUp(context.Background())
+
+	if len(pushes) != 1 {
+		t.Fatalf("Expected the logs of the failed pod only to be pushed once, got %v", pushes)
+	}
+	for _, label := range []string{`"rule":"failed"`, `"pod":"failed"`, `"namespace":"default"`, `"container":"main"`} {
+		if !strings.Contains(pushes[0], label) {
+			t.Errorf("Expected the push to carry %s, got %s", label, pushes[0])
+		}
+	}
+}
+
+func TestRunCleanUp_HoldsPodsWhoseLogsCouldNotBePushed(t *testing.T) {
+	scheme := runtime.NewScheme()
+	_ = corev1.AddToScheme(scheme)
+
+	var mu sync.Mutex
+	var pushes int
+	loki := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		mu.Lock()
+		pushes++
+		mu.Unlock()
+		if strings.Contains(string(body), `"pod":"unlucky"`) {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer loki.Close()
+
+	newPod := func(name string) *corev1.Pod {
+		return &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default", UID: types.UID(name), CreationTimestamp: metav1.NewTime(time.Now().Add(-2 * time.Hour))},
+			Spec:       corev1.PodSpec{Containers: []corev1.Container{{Name: "main"}}},
+			Status:     corev1.PodStatus{Phase: corev1.PodFailed},
+		}
+	}
+	pods := []runtime.Object{newPod("first"), newPod("unlucky"), newPod("last")}
+
+	cfg := &cleanupconfig.CleanupConfig{
+		PodCleanupConfig: cleanupconfig.PodCleanupConfig{
+			Enabled: true,
+			Rules:   []cleanupconfig.PodCleanRule{{Name: "failed", Enabled: true, Phase: "Failed", TTL: cleanupconfig.Duration{Duration: time.Hour}}},
+		},
+		LogForwarding: cleanupconfig.LogForwardingConfig{
+			Enabled: true, Backend: cleanupconfig.LogBackendLoki, URL: loki.URL,
+			MaxLinesPerPush: 1, HoldOnPushFailure: true,
+		},
+	}
+	k8sClient := fake.NewClientBuilder().WithScheme(scheme).WithRuntimeObjects(pods...).Build()
+	controller := NewPodCleanController(k8sClient, scheme, cfg)
+	controller.Logs = kubefake.NewClientset(pods...).CoreV1()
+
+	controller.RunCleanUp(context.Background())
+
+	if pushes != 3 {
+		t.Errorf("Expected one push per pod, got %d", pushes)
+	}
+	remaining := remainingPodNames(t, k8sClient)
+	if len(remaining) != 1 || !remaining["unlucky"] {
+		t.Errorf("Expected only the pod whose logs were not pushed to be kept, remaining: %v", remaining)
+	}
+}
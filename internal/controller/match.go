@@ -0,0 +1,91 @@
+package controller
+
+import (
+	cleanupconfig "github.com/infrautils/kubeclean/internal/cleanup_config"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+)
+
+// SkipReason explains why a rule does not select a pod it listed.
+type SkipReason string
+
+const (
+	SkipReasonNone          SkipReason = ""
+	SkipReasonCriteria      SkipReason = "CriteriaNotMet"        // Phase or match criteria do not hold.
+	SkipReasonMirrorPod     SkipReason = "MirrorPod"             // Mirror of a static pod; the kubelet recreates it.
+	SkipReasonTerminating   SkipReason = "Terminating"           // Already has a deletionTimestamp; deleting it again is redundant.
+	SkipReasonDisabled      SkipReason = "Disabled"              // Opted out via the kubeclean/disabled annotation.
+	SkipReasonPriorityClass SkipReason = "PriorityClassExcluded" // Priority class excluded globally or by the rule.
+	SkipReasonExcluded      SkipReason = "ExcludedBySelector"    // Matches the rule's excludeSelector.
+	SkipReasonTTL           SkipReason = "TTLNotExpired"         // Younger than the rule or annotation TTL.
+
+	SkipReasonNamespaceTerminating SkipReason = "NamespaceTerminating" // The namespace is being deleted along with its pods.
+	SkipReasonInvalidAnnotation    SkipReason = "InvalidAnnotation"    // Malformed kubeclean annotation under the skip or fail policy.
+	SkipReasonNamespaceForbidden   SkipReason = "NamespaceForbidden"   // The namespace is forbidden by the constraints.
+	SkipReasonOwnerRollingOut      SkipReason = "OwnerRollingOut"      // The owning Deployment or StatefulSet is mid-rollout.
+	SkipReasonMinAvailable         SkipReason = "MinAvailable"         // Removing the pod would leave its owner below minAvailable ready replicas.
+	SkipReasonLookupFailed         SkipReason = "LookupFailed"         // The pod's namespace, owners or node could not be read.
+)
+
+// isMirrorPod reports whether pod is the API server mirror of a static pod managed by a kubelet.
+func isMirrorPod(pod *corev1.Pod) bool {
+	if _, ok := pod.Annotations[corev1.MirrorPodAnnotationKey]; ok {
+		return true
+	}
+	for _, owner := range pod.OwnerReferences {
+		if owner.Kind == "Node" && owner.Controller != nil && *owner.Controller {
+			return true
+		}
+	}
+	return false
+}
+
+// matchesCriteria evaluates composed rule criteria: all conditions in All and, when Any is set,
+// at least one condition in Any must hold.
+func matchesCriteria(pod *corev1.Pod, criteria *cleanupconfig.MatchCriteria) bool {
+	for _, condition := range criteria.All {
+		if !matchesCondition(pod, condition) {
+			return false
+		}
+	}
+
+	if len(criteria.Any) == 0 {
+		return true
+	}
+
+	for _, condition := range criteria.Any {
+		if matchesCondition(pod, condition) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// matchesCondition reports whether every criterion set on the condition holds for the pod.
+func matchesCondition(pod *corev1.Pod, condition cleanupconfig.MatchCondition) bool {
+	if condition.Phase != "" && string(pod.Status.Phase) != condition.Phase {
+		return false
+	}
+
+	if condition.Reason != "" && pod.Status.Reason != condition.Reason {
+		return false
+	}
+
+	if condition.Selector != nil {
+		selector, err := metav1.LabelSelectorAsSelector(condition.Selector)
+		if err != nil || !selector.Matches(labels.Set(pod.Labels)) {
+			return false
+		}
+	}
+
+	for key, value := range condition.Annotations {
+		actual, exists := pod.Annotations[key]
+		if !exists || (value != "" && actual != value) {
+			return false
+		}
+	}
+
+	return true
+}
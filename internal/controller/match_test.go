@@ -0,0 +1,228 @@
+package controller
+
+import (
+	"testing"
+	"time"
+
+	cleanupconfig "github.com/infrautils/kubeclean/internal/cleanup_config"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestMatchesCriteria(t *testing.T) {
+	evicted := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Labels:      map[string]string{"app": "batch"},
+			Annotations: map[string]string{"team": "data"},
+		},
+		Status: corev1.PodStatus{Phase: corev1.PodFailed, Reason: "Evicted"},
+	}
+
+	succeeded := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Labels: map[string]string{"app": "web"}},
+		Status:     corev1.PodStatus{Phase: corev1.PodSucceeded},
+	}
+
+	phaseOrReason := &cleanupconfig.MatchCriteria{
+		Any: []cleanupconfig.MatchCondition{
+			{Phase: string(corev1.PodSucceeded)},
+			{Reason: "Evicted"},
+		},
+	}
+
+	selectorAndAnnotation := &cleanupconfig.MatchCriteria{
+		All: []cleanupconfig.MatchCondition{
+			{Selector: &metav1.LabelSelector{MatchLabels: map[string]string{"app": "batch"}}},
+			{Annotations: map[string]string{"team": ""}},
+		},
+	}
+
+	allAndAny := &cleanupconfig.MatchCriteria{
+		All: []cleanupconfig.MatchCondition{{Selector: &metav1.LabelSelector{MatchLabels: map[string]string{"app": "web"}}}},
+		Any: []cleanupconfig.MatchCondition{{Reason: "Evicted"}, {Phase: string(corev1.PodSucceeded)}},
+	}
+
+	tests := []struct {
+		name     string
+		pod      *corev1.Pod
+		criteria *cleanupconfig.MatchCriteria
+		expected bool
+	}{
+		{name: "any matches reason", pod: evicted, criteria: phaseOrReason, expected: true},
+		{name: "any matches phase", pod: succeeded, criteria: phaseOrReason, expected: true},
+		{name: "all matches selector and annotation", pod: evicted, criteria: selectorAndAnnotation, expected: true},
+		{name: "all fails on selector", pod: succeeded, criteria: selectorAndAnnotation, expected: false},
+		{name: "all and any both hold", pod: succeeded, criteria: allAndAny, expected: true},
+		{name: "all holds but any fails", pod: evicted, criteria: allAndAny, expected: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := matchesCriteria(tt.pod, tt.criteria); got != tt.expected {
+				t.Errorf("matchesCriteria() = %v, expected %v", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestEvaluatePod_SkipReasons(t *testing.T) {
+	old := metav1.NewTime(time.Now().Add(-2 * time.Hour))
+	isController := true
+
+	newPod := func(mutate func(pod *corev1.Pod)) *corev1.Pod {
+		pod := &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: "pod", Labels: map[string]string{"app": "batch"}, CreationTimestamp: old},
+			Status:     corev1.PodStatus{Phase: corev1.PodSucceeded},
+		}
+		if mutate != nil {
+			mutate(pod)
+		}
+		return pod
+	}
+
+	rule := cleanupconfig.PodCleanRule{
+		Name:                   "succeeded",
+		Phase:                  string(corev1.PodSucceeded),
+		TTL:                    cleanupconfig.Duration{Duration: time.Hour},
+		ExcludePriorityClasses: []string{"critical"},
+		ExcludeSelector:        &metav1.LabelSelector{MatchLabels: map[string]string{"keep": "true"}},
+	}
+
+	tests := []struct {
+		name     string
+		pod      *corev1.Pod
+		expected SkipReason
+	}{
+		{name: "eligible pod", pod: newPod(nil), expected: SkipReasonNone},
+		{
+			name:     "phase does not match",
+			pod:      newPod(func(pod *corev1.Pod) { pod.Status.Phase = corev1.PodRunning }),
+			expected: SkipReasonCriteria,
+		},
+		{
+			name: "mirror pod annotation",
+			pod: newPod(func(pod *corev1.Pod) {
+				pod.Annotations = map[string]string{corev1.MirrorPodAnnotationKey: "abc123"}
+			}),
+			expected: SkipReasonMirrorPod,
+		},
+		{
+			name: "mirror pod owned by node",
+			pod: newPod(func(pod *corev1.Pod) {
+				pod.OwnerReferences = []metav1.OwnerReference{{APIVersion: "v1", Kind: "Node", Name: "node-a", Controller: &isController}}
+			}),
+			expected: SkipReasonMirrorPod,
+		},
+		{
+			name: "terminating pod",
+			pod: newPod(func(pod *corev1.Pod) {
+				deleted := metav1.NewTime(time.Now())
+				pod.DeletionTimestamp = &deleted
+				pod.Finalizers = []string{"example.com/cleanup"}
+			}),
+			expected: SkipReasonTerminating,
+		},
+		{
+			name: "mirror pod in another phase still fails criteria first",
+			pod: newPod(func(pod *corev1.Pod) {
+				pod.Status.Phase = corev1.PodRunning
+				pod.Annotations = map[string]string{corev1.MirrorPodAnnotationKey: "x"}
+			}),
+			expected: SkipReasonCriteria,
+		},
+		{
+			name:     "disabled annotation",
+			pod:      newPod(func(pod *corev1.Pod) { pod.Annotations = map[string]string{"kubeclean/disabled": "true"} }),
+			expected: SkipReasonDisabled,
+		},
+		{
+			name:     "excluded priority class",
+			pod:      newPod(func(pod *corev1.Pod) { pod.Spec.PriorityClassName = "critical" }),
+			expected: SkipReasonPriorityClass,
+		},
+		{
+			name:     "exclude selector",
+			pod:      newPod(func(pod *corev1.Pod) { pod.Labels["keep"] = "true" }),
+			expected: SkipReasonExcluded,
+		},
+		{
+			name:     "ttl not expired",
+			pod:      newPod(func(pod *corev1.Pod) { pod.CreationTimestamp = metav1.Now() }),
+			expected: SkipReasonTTL,
+		},
+	}
+
+	matcher := NewPodMatcher(nil)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := matcher.EvaluatePod(tt.pod, rule); got != tt.expected {
+				t.Errorf("Expected skip reason %q, got %q", tt.expected, got)
+			}
+			if got := matcher.ShouldCleanupPod(tt.pod, rule); got != (tt.expected == SkipReasonNone) {
+				t.Errorf("ShouldCleanupPod = %v, inconsistent with skip reason %q", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestEvaluatePod_AnnotationTTLRespectsMinTTL(t *testing.T) {
+	rule := cleanupconfig.PodCleanRule{Name: "failed", Enabled: true, Phase: "Failed", TTL: cleanupconfig.Duration{Duration: 2 * time.Hour}}
+	newPod := func(age time.Duration, ttl string) *corev1.Pod {
+		return &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:              "pod",
+				Annotations:       map[string]string{AnnotationTTL: ttl},
+				CreationTimestamp: metav1.NewTime(time.Now().Add(-age)),
+			},
+			Status: corev1.PodStatus{Phase: corev1.PodFailed},
+		}
+	}
+
+	constraints := cleanupconfig.ConstraintsConfig{MinTTL: cleanupconfig.Duration{Duration: time.Hour}}
+	rule, err := constraints.Enforce(rule)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	matcher := NewPodMatcher(nil)
+	if got := matcher.EvaluatePod(newPod(30*time.Minute, "1m"), rule); got != SkipReasonTTL {
+		t.Errorf("Expected an annotation below minTTL to be raised to it, got %q", got)
+	}
+	if got := matcher.EvaluatePod(newPod(90*time.Minute, "1m"), rule); got != SkipReasonNone {
+		t.Errorf("Expected a pod older than minTTL to be matched, got %q", got)
+	}
+	if got := matcher.EvaluatePod(newPod(150*time.Minute, "3h"), rule); got != SkipReasonTTL {
+		t.Errorf("Expected an annotation above minTTL to apply, got %q", got)
+	}
+}
+
+func TestEvaluatePod_BusinessDays(t *testing.T) {
+	rule := cleanupconfig.PodCleanRule{Name: "failed", Enabled: true, Phase: "Failed", TTLBusinessDays: 1}
+	newPod := func(age time.Duration, annotations map[string]string) *corev1.Pod {
+		return &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:              "pod",
+				Annotations:       annotations,
+				CreationTimestamp: metav1.NewTime(time.Now().Add(-age)),
+			},
+			Status: corev1.PodStatus{Phase: corev1.PodFailed},
+		}
+	}
+
+	matcher := NewPodMatcher(nil)
+	if got := matcher.EvaluatePod(newPod(10*24*time.Hour, nil), rule); got != SkipReasonNone {
+		t.Errorf("Expected a pod older than a week to be matched, got %q", got)
+	}
+	if got := matcher.EvaluatePod(newPod(time.Hour, nil), rule); got != SkipReasonTTL {
+		t.Errorf("Expected a pod created within the day to be kept, got %q", got)
+	}
+	if got := matcher.EvaluatePod(newPod(time.Hour, map[string]string{AnnotationTTL: "30m"}), rule); got != SkipReasonNone {
+		t.Errorf("Expected the TTL annotation to take precedence over business days, got %q", got)
+	}
+
+	// With today as the only work day, a pod from three days ago is kept until today ends.
+	rule.BusinessCalendar = cleanupconfig.BusinessCalendarConfig{WorkDays: []string{time.Now().UTC().Weekday().String()}}
+	if got := matcher.EvaluatePod(newPod(3*24*time.Hour, nil), rule); got != SkipReasonTTL {
+		t.Errorf("Expected a pod younger than the next business day to be kept, got %q", got)
+	}
+}
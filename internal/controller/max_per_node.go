@@ -0,0 +1,43 @@
+package controller
+
+import (
+	"cmp"
+	"maps"
+	"slices"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+// isPodCompleted reports whether pod ran to completion, successfully or not.
+func isPodCompleted(pod *corev1.Pod) bool {
+	return pod.Status.Phase == corev1.PodSucceeded || pod.Status.Phase == corev1.PodFailed
+}
+
+// trimPerNode splits pods into the newest keep pods of each node, which are kept, and the older
+// ones, which are trimmed. The kubelet only garbage collects terminated pods once the cluster-wide
+// count exceeds its threshold, so a busy node can pile up completed pods long before that.
+func trimPerNode(pods []corev1.Pod, keep int) (trimmed, kept []corev1.Pod) {
+	byNode := map[string][]corev1.Pod{}
+	for _, pod := range pods {
+		byNode[pod.Spec.NodeName] = append(byNode[pod.Spec.NodeName], pod)
+	}
+
+	for _, node := range slices.Sorted(maps.Keys(byNode)) {
+		pods := byNode[node]
+		// Newest first, so the oldest pods beyond keep are trimmed.
+		slices.SortFunc(pods, func(a, b corev1.Pod) int {
+			if c := b.CreationTimestamp.Compare(a.CreationTimestamp.Time); c != 0 {
+				return c
+			}
+			return cmp.Compare(a.Namespace+"/"+a.Name, b.Namespace+"/"+b.Name)
+		})
+
+		if len(pods) <= keep {
+			kept = append(kept, pods...)
+			continue
+		}
+		kept = append(kept, pods[:keep]...)
+		trimmed = append(trimmed, pods[keep:]...)
+	}
+	return trimmed, kept
+}
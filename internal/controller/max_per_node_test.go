@@ -0,0 +1,89 @@
+package controller
+
+import (
+	"context"
+	"slices"
+	"testing"
+	"time"
+
+	cleanupconfig "github.com/infrautils/kubeclean/internal/cleanup_config"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestFindPodsToCleanup_MaxPerNode(t *testing.T) {
+	scheme := runtime.NewScheme()
+	_ = corev1.AddToScheme(scheme)
+
+	newPod := func(name, node string, phase corev1.PodPhase, age time.Duration) *corev1.Pod {
+		return &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:              name,
+				Namespace:         "default",
+				CreationTimestamp: metav1.NewTime(time.Now().Add(-age)),
+			},
+			Spec:   corev1.PodSpec{NodeName: node},
+			Status: corev1.PodStatus{Phase: phase},
+		}
+	}
+	client := fake.NewClientBuilder().WithScheme(scheme).WithRuntimeObjects(
+		newPod("a-expired", "a", corev1.PodSucceeded, 3*time.Hour),
+		newPod("a-40m", "a", corev1.PodSucceeded, 40*time.Minute),
+		newPod("a-30m", "a", corev1.PodSucceeded, 30*time.Minute),
+		newPod("a-20m", "a", corev1.PodSucceeded, 20*time.Minute),
+		newPod("a-10m", "a", corev1.PodSucceeded, 10*time.Minute),
+		newPod("a-running", "a", corev1.PodRunning, 50*time.Minute),
+		newPod("b-30m", "b", corev1.PodSucceeded, 30*time.Minute),
+		newPod("b-20m", "b", corev1.PodSucceeded, 20*time.Minute),
+	).Build()
+
+	rule := cleanupconfig.PodCleanRule{
+		Name:       "completed",
+		Enabled:    true,
+		Match:      &cleanupconfig.MatchCriteria{Any: []cleanupconfig.MatchCondition{{Phase: "Succeeded"}, {Phase: "Running"}}},
+		TTL:        cleanupconfig.Duration{Duration: time.Hour},
+		MaxPerNode: 2,
+	}
+
+	pods, err := NewPodMatcher(client).FindPodsToCleanup(context.Background(), rule)
+	if err != nil {
+		t.Fatalf("FindPodsToCleanup failed: %v", err)
+	}
+
+	var names []string
+	for _, pod := range pods {
+		names = append(names, pod.Name)
+	}
+	slices.Sort(names)
+
+	// Node a keeps its two newest completed pods; node b is within the limit. The running pod is
+	// never trimmed.
+	if want := []string{"a-30m", "a-40m", "a-expired"}; !slices.Equal(names, want) {
+		t.Errorf("Expected %v to match, got %v", want, names)
+	}
+}
+
+func TestTrimPerNode(t *testing.T) {
+	pod := func(name, node string, age time.Duration) corev1.Pod {
+		return corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: name, CreationTimestamp: metav1.NewTime(time.Now().Add(-age))},
+			Spec:       corev1.PodSpec{NodeName: node},
+		}
+	}
+
+	trimmed, kept := trimPerNode([]corev1.Pod{
+		pod("a-old", "a", 3*time.Minute),
+		pod("a-new", "a", time.Minute),
+		pod("a-mid", "a", 2*time.Minute),
+		pod("b-only", "b", 5*time.Minute),
+	}, 1)
+
+	if len(trimmed) != 2 || trimmed[0].Name != "a-mid" || trimmed[1].Name != "a-old" {
+		t.Errorf("Expected the two older pods of node a to be trimmed, got %v", trimmed)
+	}
+	if len(kept) != 2 || kept[0].Name != "a-new" || kept[1].Name != "b-only" {
+		t.Errorf("Expected the newest pod of each node to be kept, got %v", kept)
+	}
+}
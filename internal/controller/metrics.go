@@ -0,0 +1,221 @@
+package controller
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+)
+
+var (
+	listErrorsTotal = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "kubeclean_list_errors_total",
+			Help: "Number of failed pod list calls, partitioned by rule and error reason.",
+		},
+		[]string{"rule", "reason"},
+	)
+
+	deleteFailuresTotal = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "kubeclean_delete_failures_total",
+			Help: "Number of failed deletions, partitioned by rule and error reason.",
+		},
+		[]string{"rule", "reason"},
+	)
+
+	retryQueueLength = prometheus.NewGauge(
+		prometheus.GaugeOpts{
+			Name: "kubeclean_retry_queue_length",
+			Help: "Number of pods whose deletion failed transiently and awaits another attempt.",
+		},
+	)
+
+	deadLetteredDeletionsTotal = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "kubeclean_dead_lettered_deletions_total",
+			Help: "Number of pods whose deletion was given up on after failed retries, partitioned by rule and last error reason.",
+		},
+		[]string{"rule", "reason"},
+	)
+
+	skippedRunsTotal = prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Name: "kubeclean_skipped_runs_total",
+			Help: "Number of scheduled runs skipped because the previous run was still active.",
+		},
+	)
+
+	quotaTriggeredRunsTotal = prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Name: "kubeclean_quota_triggered_runs_total",
+			Help: "Number of namespace passes triggered by events reporting an exhausted ResourceQuota.",
+		},
+	)
+
+	nodePressureTriggeredRunsTotal = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "kubeclean_node_pressure_triggered_runs_total",
+			Help: "Number of node passes triggered by node pressure, by reason.",
+		},
+		[]string{"reason"},
+	)
+
+	throttledTotal = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "kubeclean_throttled_requests_total",
+			Help: "Number of API requests rejected with 429 Too Many Requests, partitioned by operation.",
+		},
+		[]string{"operation"},
+	)
+
+	throttleWaitSecondsTotal = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "kubeclean_throttle_wait_seconds_total",
+			Help: "Total time spent pausing for Retry-After hints, partitioned by operation.",
+		},
+		[]string{"operation"},
+	)
+
+	circuitBreakerOpen = prometheus.NewGauge(
+		prometheus.GaugeOpts{
+			Name: "kubeclean_circuit_breaker_open",
+			Help: "1 while the API server circuit breaker is open and runs are deferred, 0 otherwise.",
+		},
+	)
+
+	circuitBreakerTripsTotal = prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Name: "kubeclean_circuit_breaker_trips_total",
+			Help: "Number of times the API server circuit breaker opened.",
+		},
+	)
+
+	interruptedRunsTotal = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "kubeclean_interrupted_runs_total",
+			Help: "Number of runs the API server circuit breaker deferred or aborted, partitioned by outcome.",
+		},
+		[]string{"outcome"},
+	)
+
+	pendingConfig = prometheus.NewGauge(
+		prometheus.GaugeOpts{
+			Name: "kubeclean_pending_config",
+			Help: "1 while a reloaded config is held back by reloadSafety pending approval, 0 otherwise.",
+		},
+	)
+
+	shadowMatchedPods = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "kubeclean_shadow_matched_pods",
+			Help: "Pods matched in the last full pass, partitioned by config (active or shadow) and rule.",
+		},
+		[]string{"config", "rule"},
+	)
+
+	shadowAddedPods = prometheus.NewGauge(
+		prometheus.GaugeOpts{
+			Name: "kubeclean_shadow_added_pods",
+			Help: "Pods only the shadow config matched in the last full pass.",
+		},
+	)
+
+	shadowRemovedPods = prometheus.NewGauge(
+		prometheus.GaugeOpts{
+			Name: "kubeclean_shadow_removed_pods",
+			Help: "Pods only the active config matched in the last full pass.",
+		},
+	)
+
+	deferredPodsTotal = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "kubeclean_deferred_pods_total",
+			Help: "Number of matched pods deferred to a later run because a deletion budget was exhausted.",
+		},
+		[]string{"rule"},
+	)
+
+	skippedPodsTotal = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "kubeclean_skipped_pods_total",
+			Help: "Number of pods meeting a rule's criteria that were skipped, partitioned by rule and skip reason.",
+		},
+		[]string{"rule", "reason"},
+	)
+
+	newCandidates = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "kubeclean_new_candidates",
+			Help: "Number of matched pods that were not candidates in the previous run, partitioned by rule.",
+		},
+		[]string{"rule"},
+	)
+
+	ruleCooldownsTotal = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "kubeclean_rule_cooldowns_total",
+			Help: "Number of times a rule was put in cooldown because most of its actions failed, partitioned by rule.",
+		},
+		[]string{"rule"},
+	)
+
+	anomaliesTotal = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "kubeclean_anomalies_total",
+			Help: "Number of runs in which a rule matched far more pods than its baseline, partitioned by rule.",
+		},
+		[]string{"rule"},
+	)
+
+	orphanedResources = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "kubeclean_orphaned_resources",
+			Help: "Number of resources whose targets no longer exist, as of the last run, partitioned by rule.",
+		},
+		[]string{"rule"},
+	)
+
+	idleWorkloads = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "kubeclean_idle_workloads",
+			Help: "Number of running workloads found idle, as of the last run, partitioned by rule.",
+		},
+		[]string{"rule"},
+	)
+
+	staleCronJobs = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "kubeclean_stale_cronjobs",
+			Help: "Number of unsuspended CronJobs found stale, as of the last run, partitioned by rule.",
+		},
+		[]string{"rule"},
+	)
+
+	genericDeletionsRefusedTotal = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "kubeclean_generic_deletions_refused_total",
+			Help: "Number of runs in which a generic rule deleted nothing because it matched more than maxDeletePercent of its kind, partitioned by rule.",
+		},
+		[]string{"rule"},
+	)
+
+	forwardedLogsTotal = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "kubeclean_forwarded_logs_total",
+			Help: "Number of container logs of deleted pods pushed to the log forwarding backend, partitioned by rule.",
+		},
+		[]string{"rule"},
+	)
+
+	logForwardFailuresTotal = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "kubeclean_log_forward_failures_total",
+			Help: "Number of failures to capture or push the logs of deleted pods, partitioned by rule.",
+		},
+		[]string{"rule"},
+	)
+)
+
+func init() {
+	metrics.Registry.MustRegister(listErrorsTotal, deleteFailuresTotal, retryQueueLength, deadLetteredDeletionsTotal, skippedRunsTotal, quotaTriggeredRunsTotal, nodePressureTriggeredRunsTotal, throttledTotal, throttleWaitSecondsTotal, pendingConfig, shadowMatchedPods, shadowAddedPods, shadowRemovedPods, deferredPodsTotal, skippedPodsTotal, newCandidates, anomaliesTotal, ruleCooldownsTotal, orphanedResources, idleWorkloads, staleCronJobs,
+		genericDeletionsRefusedTotal, forwardedLogsTotal, logForwardFailuresTotal, circuitBreakerOpen, circuitBreakerTripsTotal, interruptedRunsTotal)
+}
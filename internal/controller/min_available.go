@@ -0,0 +1,105 @@
+package controller
+
+import (
+	"context"
+
+	cleanupconfig "github.com/infrautils/kubeclean/internal/cleanup_config"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// keepMinAvailable drops the ready pods whose removal would leave their owning Deployment,
+// StatefulSet, ReplicaSet or DaemonSet with fewer than the rule's minAvailable ready replicas.
+// Unlike a PodDisruptionBudget, the guard holds for every action and needs no cooperation from
+// the workload's owners. Ready pods selected earlier in the run, by any rule, count as removed.
+func (pm *PodMatcher) keepMinAvailable(ctx context.Context, pods []corev1.Pod, rule cleanupconfig.PodCleanRule) ([]corev1.Pod, error) {
+	if rule.MinAvailable <= 0 {
+		return pods, nil
+	}
+
+	if pm.owners == nil {
+		pm.owners = newOwnerResolver(pm.client)
+	}
+	if pm.readyRemoved == nil {
+		pm.readyRemoved = map[Owner]int{}
+	}
+
+	kept := pods[:0]
+	for i := range pods {
+		pod := &pods[i]
+		if !isPodReady(pod) {
+			kept = append(kept, *pod)
+			continue
+		}
+
+		owner := pm.owners.resolve(ctx, pod)
+		ready, guarded, err := pm.readyReplicas(ctx, owner)
+		if err != nil {
+			return nil, err
+		}
+		if guarded && ready-pm.readyRemoved[owner]-1 < rule.MinAvailable {
+			skippedPodsTotal.WithLabelValues(rule.Name, string(SkipReasonMinAvailable)).Inc()
+			continue
+		}
+
+		if guarded {
+			pm.readyRemoved[owner]++
+		}
+		kept = append(kept, *pod)
+	}
+
+	return kept, nil
+}
+
+// readyReplicas returns the ready replicas of owner as its controller last reported them, and
+// whether owner is a kind the minAvailable guard applies to. Counts are cached for the run.
+func (pm *PodMatcher) readyReplicas(ctx context.Context, owner Owner) (int, bool, error) {
+	if ready, ok := pm.readyCounts[owner]; ok {
+		return ready, true, nil
+	}
+
+	var ready func() int
+	var obj client.Object
+	switch owner.Kind {
+	case "Deployment":
+		deployment := &appsv1.Deployment{}
+		obj, ready = deployment, func() int { return int(deployment.Status.ReadyReplicas) }
+	case "StatefulSet":
+		statefulSet := &appsv1.StatefulSet{}
+		obj, ready = statefulSet, func() int { return int(statefulSet.Status.ReadyReplicas) }
+	case "ReplicaSet":
+		replicaSet := &appsv1.ReplicaSet{}
+		obj, ready = replicaSet, func() int { return int(replicaSet.Status.ReadyReplicas) }
+	case "DaemonSet":
+		daemonSet := &appsv1.DaemonSet{}
+		obj, ready = daemonSet, func() int { return int(daemonSet.Status.NumberReady) }
+	default:
+		return 0, false, nil
+	}
+
+	found, err := pm.getOwner(ctx, owner.Namespace, owner.Name, obj)
+	if err != nil || !found {
+		// A pod whose owner is gone is not serving on its behalf.
+		return 0, false, err
+	}
+
+	if pm.readyCounts == nil {
+		pm.readyCounts = map[Owner]int{}
+	}
+	pm.readyCounts[owner] = ready()
+	return pm.readyCounts[owner], true, nil
+}
+
+// isPodReady reports whether pod is running with its Ready condition true.
+func isPodReady(pod *corev1.Pod) bool {
+	if pod.Status.Phase != corev1.PodRunning {
+		return false
+	}
+	for _, condition := range pod.Status.Conditions {
+		if condition.Type == corev1.PodReady {
+			return condition.Status == corev1.ConditionTrue
+		}
+	}
+	return false
+}
@@ -0,0 +1,92 @@
+package controller
+
+import (
+	"context"
+	"slices"
+	"testing"
+	"time"
+
+	cleanupconfig "github.com/infrautils/kubeclean/internal/cleanup_config"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestFindPodsToCleanup_MinAvailable(t *testing.T) {
+	scheme := runtime.NewScheme()
+	_ = corev1.AddToScheme(scheme)
+	_ = appsv1.AddToScheme(scheme)
+
+	pod := func(name, kind, owner string, ready bool) *corev1.Pod {
+		status := corev1.ConditionFalse
+		if ready {
+			status = corev1.ConditionTrue
+		}
+		return &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: name, Namespace: "default", Labels: map[string]string{"tier": "cache"},
+				OwnerReferences:   controllerRef(kind, owner),
+				CreationTimestamp: metav1.NewTime(time.Now().Add(-2 * time.Hour)),
+			},
+			Status: corev1.PodStatus{
+				Phase:      corev1.PodRunning,
+				Conditions: []corev1.PodCondition{{Type: corev1.PodReady, Status: status}},
+			},
+		}
+	}
+
+	k8sClient := fake.NewClientBuilder().WithScheme(scheme).WithObjects(
+		&appsv1.Deployment{
+			ObjectMeta: metav1.ObjectMeta{Name: "cache", Namespace: "default"},
+			Status:     appsv1.DeploymentStatus{ReadyReplicas: 3},
+		},
+		&appsv1.ReplicaSet{ObjectMeta: metav1.ObjectMeta{Name: "cache-1", Namespace: "default", OwnerReferences: controllerRef("Deployment", "cache")}},
+		&appsv1.StatefulSet{
+			ObjectMeta: metav1.ObjectMeta{Name: "db", Namespace: "default"},
+			Status:     appsv1.StatefulSetStatus{ReadyReplicas: 1},
+		},
+		pod("cache-1-a", "ReplicaSet", "cache-1", true),
+		pod("cache-1-b", "ReplicaSet", "cache-1", true),
+		pod("cache-1-c", "ReplicaSet", "cache-1", true),
+		pod("cache-1-d", "ReplicaSet", "cache-1", false),
+		pod("db-0", "StatefulSet", "db", true),
+	).Build()
+
+	rule := cleanupconfig.PodCleanRule{
+		Name: "running", Enabled: true, Phase: "Running", TTL: cleanupconfig.Duration{Duration: time.Hour},
+		Selector: metav1.LabelSelector{MatchLabels: map[string]string{"tier": "cache"}}, MinAvailable: 2,
+	}
+	matcher := NewPodMatcher(k8sClient)
+	names := func() []string {
+		t.Helper()
+		pods, err := matcher.FindPodsToCleanup(context.Background(), rule)
+		if err != nil {
+			t.Fatalf("FindPodsToCleanup failed: %v", err)
+		}
+		var names []string
+		for _, pod := range pods {
+			names = append(names, pod.Name)
+		}
+		slices.Sort(names)
+		return names
+	}
+
+	// Three ready cache replicas leave room for one; unready pods never count against the guard,
+	// and the single ready database replica is kept.
+	if got, want := names(), []string{"cache-1-a", "cache-1-d"}; !slices.Equal(got, want) {
+		t.Errorf("Expected %v, got %v", want, got)
+	}
+
+	// Ready pods selected earlier in the run count as removed.
+	if got, want := names(), []string{"cache-1-d"}; !slices.Equal(got, want) {
+		t.Errorf("Expected the allowance to be spent for the run, got %v", got)
+	}
+
+	matcher.ResetCache()
+	rule.MinAvailable = 0
+	if got := names(); len(got) != 5 {
+		t.Errorf("Expected every running pod without the guard, got %v", got)
+	}
+}
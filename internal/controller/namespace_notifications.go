@@ -0,0 +1,166 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	cleanupconfig "github.com/infrautils/kubeclean/internal/cleanup_config"
+	"github.com/infrautils/kubeclean/internal/notify"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+// namespaceNotifier rate-limits notifications to namespace owners. Deletions in a namespace whose
+// owner was notified too recently are accumulated until its next notification.
+type namespaceNotifier struct {
+	mu       sync.Mutex
+	lastSent map[string]time.Time
+	pending  map[string]map[string]int // Deleted pods not yet reported, by namespace and rule.
+}
+
+func newNamespaceNotifier() *namespaceNotifier {
+	return &namespaceNotifier{lastSent: map[string]time.Time{}, pending: map[string]map[string]int{}}
+}
+
+// notifyNamespaceOwners sends the owner of every namespace with enough unreported deletions one
+// aggregated notification, unless its owner was notified less than the configured interval ago.
+func (c *PodCleanController) notifyNamespaceOwners(ctx context.Context, run *cleanupRun) {
+	cfg := c.CleanupConfig.NamespaceNotifications
+	if !cfg.Enabled {
+		return
+	}
+	logger := log.FromContext(ctx)
+
+	n := c.namespaceNotifier
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	for namespace, rules := range run.deleted {
+		if n.pending[namespace] == nil {
+			n.pending[namespace] = map[string]int{}
+		}
+		for rule, count := range rules {
+			n.pending[namespace][rule] += count
+		}
+	}
+
+	ownership := c.namespaceOwnership(ctx)
+
+	now := time.Now()
+	for _, namespace := range slices.Sorted(maps.Keys(n.pending)) {
+		rules := n.pending[namespace]
+		total := 0
+		for _, count := range rules {
+			total += count
+		}
+		if total < cfg.MinimumPods() || now.Sub(n.lastSent[namespace]) < cfg.Interval() {
+			continue
+		}
+
+		sink, err := c.namespaceOwnerSink(ctx, namespace, ownership)
+		if err != nil {
+			logger.Error(err, "Failed to resolve the namespace owner", "namespace", namespace)
+			delete(n.pending, namespace)
+			continue
+		}
+		if sink == nil {
+			delete(n.pending, namespace) // Nobody to notify.
+			continue
+		}
+
+		notifier, err := notify.NewNotifier(cleanupconfig.NotificationConfig{Sinks: []cleanupconfig.NotificationSink{*sink}}, nil)
+		if err == nil {
+			err = notifier.Notify(ctx, nil, namespaceEvent(run.ID, namespace, total, rules))
+		}
+		if err != nil {
+			logger.Error(err, "Failed to notify the namespace owner", "namespace", namespace, "sink", sink.Name)
+			continue // Retried with the next pass.
+		}
+
+		n.lastSent[namespace] = now
+		delete(n.pending, namespace)
+	}
+}
+
+// namespaceOwnership returns the ownership ConfigMap's mapping of namespaces to owner sinks, or nil
+// when none is configured or it cannot be read.
+func (c *PodCleanController) namespaceOwnership(ctx context.Context) map[string]string {
+	cfg := c.CleanupConfig.NamespaceNotifications
+	namespace, name, ok := cfg.OwnershipConfigMapKey()
+	if !ok {
+		return nil
+	}
+
+	configMap := &corev1.ConfigMap{}
+	if err := withThrottleRetry(ctx, "get", func() error {
+		return c.Client.Get(ctx, types.NamespacedName{Namespace: namespace, Name: name}, configMap)
+	}); err != nil {
+		log.FromContext(ctx).Error(err, "Failed to read the namespace ownership ConfigMap", "configMap", cfg.OwnershipConfigMap)
+	}
+	return configMap.Data
+}
+
+// namespaceOwnerSink returns the sink notifying the owner of namespace, or nil when it has none.
+// The kubeclean/owner-sink annotation of the namespace names a configured sink marked
+// tenantSelectable. Otherwise the ownership ConfigMap maps the namespace to any configured sink
+// name or to "<type>:<url>".
+func (c *PodCleanController) namespaceOwnerSink(ctx context.Context, namespace string, ownership map[string]string) (*cleanupconfig.NotificationSink, error) {
+	ns := &corev1.Namespace{}
+	if err := withThrottleRetry(ctx, "get", func() error {
+		return c.Client.Get(ctx, types.NamespacedName{Name: namespace}, ns)
+	}); err != nil {
+		return nil, err
+	}
+
+	owner := ns.Annotations[AnnotationOwnerSink]
+	annotated := owner != ""
+	if !annotated {
+		owner = ownership[namespace]
+	}
+	if owner == "" {
+		return nil, nil
+	}
+
+	for _, sink := range c.CleanupConfig.Notifications.Sinks {
+		if sink.Name == owner {
+			// Namespace annotations are tenant-controlled and may only pick sinks opened up to tenants.
+			if annotated && !sink.TenantSelectable {
+				return nil, fmt.Errorf("owner sink %q is not tenantSelectable", owner)
+			}
+			return &sink, nil
+		}
+	}
+
+	// Only the administrator-managed ConfigMap may point kubeclean at arbitrary endpoints.
+	if sinkType, url, ok := strings.Cut(owner, ":"); ok && !annotated {
+		sink := cleanupconfig.NotificationSink{Name: "owner of " + namespace, Type: sinkType, URL: url}
+		if err := sink.Validate(); err != nil {
+			return nil, fmt.Errorf("invalid owner sink %q: %w", owner, err)
+		}
+		return &sink, nil
+	}
+
+	return nil, fmt.Errorf("unknown owner sink %q", owner)
+}
+
+// namespaceEvent builds the aggregated notification of the pods deleted in namespace.
+func namespaceEvent(runID, namespace string, total int, rules map[string]int) notify.Event {
+	var perRule []string
+	for _, rule := range slices.Sorted(maps.Keys(rules)) {
+		perRule = append(perRule, fmt.Sprintf("%s (%d)", rule, rules[rule]))
+	}
+
+	return notify.Event{
+		RunID:     runID,
+		Namespace: namespace,
+		Pods:      total,
+		Rules:     maps.Clone(rules),
+		Message:   fmt.Sprintf("Deleted %d pod(s) in namespace %s: %s", total, namespace, strings.Join(perRule, ", ")),
+	}
+}
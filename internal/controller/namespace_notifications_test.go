@@ -0,0 +1,129 @@
+package controller
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	cleanupconfig "github.com/infrautils/kubeclean/internal/cleanup_config"
+	"github.com/infrautils/kubeclean/internal/notify"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestRunCleanUp_NotifiesNamespaceOwners(t *testing.T) {
+	scheme := runtime.NewScheme()
+	_ = corev1.AddToScheme(scheme)
+
+	var mu sync.Mutex
+	received := map[string][]notify.Event{} // By request path.
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var event notify.Event
+		_ = json.NewDecoder(r.Body).Decode(&event)
+		mu.Lock()
+		received[r.URL.Path] = append(received[r.URL.Path], event)
+		mu.Unlock()
+	}))
+	defer server.Close()
+
+	newPod := func(name, namespace string) *corev1.Pod {
+		return &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace, CreationTimestamp: metav1.NewTime(time.Now().Add(-2 * time.Hour))},
+			Status:     corev1.PodStatus{Phase: corev1.PodSucceeded},
+		}
+	}
+	client := fake.NewClientBuilder().WithScheme(scheme).WithRuntimeObjects(
+		&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "team-a", Annotations: map[string]string{AnnotationOwnerSink: "team-a-hook"}}},
+		&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "team-b"}},
+		&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "team-c"}},
+		&corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{Name: "owners", Namespace: "kubeclean"},
+			Data:       map[string]string{"team-b": "webhook:" + server.URL + "/team-b"},
+		},
+		newPod("a1", "team-a"), newPod("a2", "team-a"), newPod("b1", "team-b"), newPod("c1", "team-c"),
+	).Build()
+
+	cfg := &cleanupconfig.CleanupConfig{
+		PodCleanupConfig: cleanupconfig.PodCleanupConfig{
+			Enabled: true,
+			Rules:   []cleanupconfig.PodCleanRule{{Name: "succeeded", Enabled: true, Phase: "Succeeded", TTL: cleanupconfig.Duration{Duration: time.Hour}}},
+		},
+		Notifications: cleanupconfig.NotificationConfig{
+			Sinks: []cleanupconfig.NotificationSink{{Name: "team-a-hook", Type: cleanupconfig.SinkTypeWebhook, TenantSelectable: true, URL: server.URL + "/team-a"}},
+		},
+		NamespaceNotifications: cleanupconfig.NamespaceNotificationsConfig{Enabled: true, OwnershipConfigMap: "kubeclean/owners"},
+	}
+	controller := NewPodCleanController(client, scheme, cfg)
+	controller.RunCleanUp(context.Background())
+
+	teamA := received["/team-a"]
+	var aggregated []notify.Event
+	for _, event := range teamA {
+		if event.Namespace != "" {
+			aggregated = append(aggregated, event)
+		}
+	}
+	if len(aggregated) != 1 || aggregated[0].Namespace != "team-a" || aggregated[0].Pods != 2 || aggregated[0].Rules["succeeded"] != 2 {
+		t.Errorf("Expected one aggregated notification of 2 pods to team-a's owner, got %+v", aggregated)
+	}
+	if teamB := received["/team-b"]; len(teamB) != 1 || teamB[0].Namespace != "team-b" || teamB[0].Pods != 1 {
+		t.Errorf("Expected one notification to team-b's owner from the ownership ConfigMap, got %+v", teamB)
+	}
+
+	// A later pass within the interval is held back and reported with the next notification.
+	if err := client.Create(context.Background(), newPod("a3", "team-a")); err != nil {
+		t.Fatal(err)
+	}
+	controller.RunCleanUp(context.Background())
+
+	aggregated = nil
+	for _, event := range received["/team-a"] {
+		if event.Namespace != "" {
+			aggregated = append(aggregated, event)
+		}
+	}
+	if len(aggregated) != 1 {
+		t.Fatalf("Expected the second notification to be rate-limited, got %+v", aggregated)
+	}
+	if pending := controller.namespaceNotifier.pending["team-a"]["succeeded"]; pending != 1 {
+		t.Errorf("Expected the held-back deletion to be pending, got %d", pending)
+	}
+}
+
+func TestNamespaceOwnerSink_AnnotationsOnlyPickTenantSelectableSinks(t *testing.T) {
+	scheme := runtime.NewScheme()
+	_ = corev1.AddToScheme(scheme)
+
+	client := fake.NewClientBuilder().WithScheme(scheme).WithRuntimeObjects(
+		&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "team-a", Annotations: map[string]string{AnnotationOwnerSink: "team-a-hook"}}},
+		&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "team-b", Annotations: map[string]string{AnnotationOwnerSink: "oncall"}}},
+		&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "team-c"}},
+	).Build()
+
+	cfg := &cleanupconfig.CleanupConfig{
+		Notifications: cleanupconfig.NotificationConfig{
+			Sinks: []cleanupconfig.NotificationSink{
+				{Name: "team-a-hook", Type: cleanupconfig.SinkTypeWebhook, URL: "http://team-a", TenantSelectable: true},
+				{Name: "oncall", Type: cleanupconfig.SinkTypeWebhook, URL: "http://oncall"},
+			},
+		},
+	}
+	controller := NewPodCleanController(client, scheme, cfg)
+	ownership := map[string]string{"team-b": "team-a-hook", "team-c": "oncall"}
+
+	if sink, err := controller.namespaceOwnerSink(context.Background(), "team-a", ownership); err != nil || sink == nil || sink.Name != "team-a-hook" {
+		t.Errorf("Expected the annotation to pick the tenant-selectable sink, got %+v, %v", sink, err)
+	}
+	if sink, err := controller.namespaceOwnerSink(context.Background(), "team-b", ownership); err == nil {
+		t.Errorf("Expected the annotation naming a sink that is not tenant-selectable to be rejected, got %+v", sink)
+	}
+	if sink, err := controller.namespaceOwnerSink(context.Background(), "team-c", ownership); err != nil || sink == nil || sink.Name != "oncall" {
+		t.Errorf("Expected the ownership ConfigMap to pick any configured sink, got %+v, %v", sink, err)
+	}
+}
@@ -0,0 +1,190 @@
+package controller
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/watch"
+	corev1client "k8s.io/client-go/kubernetes/typed/core/v1"
+	toolscache "k8s.io/client-go/tools/cache"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+// Reasons a node pass is triggered for.
+const (
+	nodePressureDisk           = "DiskPressure"
+	nodePressureTerminatedPods = "TerminatedPods"
+)
+
+// completedPodsSelector selects the completed pods bound to a node. Field selectors cannot
+// express a set of phases, so the other phases are excluded instead.
+var completedPodsSelector = fields.AndSelectors(
+	fields.OneTermNotEqualSelector("spec.nodeName", ""),
+	fields.OneTermNotEqualSelector("status.phase", string(corev1.PodPending)),
+	fields.OneTermNotEqualSelector("status.phase", string(corev1.PodRunning)),
+	fields.OneTermNotEqualSelector("status.phase", string(corev1.PodUnknown)),
+).String()
+
+// nodeIndex indexes completed pods by the node they ran on.
+const nodeIndex = "node"
+
+// hasDiskPressure reports whether node reports the DiskPressure condition.
+func hasDiskPressure(node *corev1.Node) bool {
+	for _, condition := range node.Status.Conditions {
+		if condition.Type == corev1.NodeDiskPressure {
+			return condition.Status == corev1.ConditionTrue
+		}
+	}
+	return false
+}
+
+// NodePressureTrigger watches nodes for DiskPressure and counts the completed pods on each node,
+// and triggers a pass over the completed pods of a node that comes under pressure. It implements
+// manager.Runnable.
+type NodePressureTrigger struct {
+	Controller *PodCleanController
+	Client     corev1client.CoreV1Interface
+
+	mu        sync.Mutex
+	triggered map[string]time.Time // Last triggered pass by node.
+}
+
+// NewNodePressureTrigger returns a trigger for controller that watches nodes and pods through
+// k8sClient.
+func NewNodePressureTrigger(controller *PodCleanController, k8sClient corev1client.CoreV1Interface) *NodePressureTrigger {
+	return &NodePressureTrigger{Controller: controller, Client: k8sClient, triggered: map[string]time.Time{}}
+}
+
+// Start watches nodes and completed pods, as the config at startup asks for, until ctx is
+// cancelled.
+func (t *NodePressureTrigger) Start(ctx context.Context) error {
+	cfg := t.Controller.CleanupConfig.PodCleanupConfig.NodePressure
+	var informers []toolscache.Controller
+
+	if cfg.DiskPressure {
+		_, informer := toolscache.NewInformerWithOptions(toolscache.InformerOptions{
+			ListerWatcher: &toolscache.ListWatch{
+				ListWithContextFunc: func(ctx context.Context, options metav1.ListOptions) (runtime.Object, error) {
+					return t.Client.Nodes().List(ctx, options)
+				},
+				WatchFuncWithContext: func(ctx context.Context, options metav1.ListOptions) (watch.Interface, error) {
+					return t.Client.Nodes().Watch(ctx, options)
+				},
+			},
+			ObjectType: &corev1.Node{},
+			Handler: toolscache.ResourceEventHandlerFuncs{
+				AddFunc:    func(obj any) { t.observeNode(ctx, obj) },
+				UpdateFunc: func(_, obj any) { t.observeNode(ctx, obj) },
+			},
+		})
+		informers = append(informers, informer)
+	}
+
+	if cfg.MaxTerminatedPods > 0 {
+		var completed toolscache.Indexer
+		observe := func(obj any) {
+			if pod, ok := obj.(*corev1.Pod); ok {
+				if keys, err := completed.IndexKeys(nodeIndex, pod.Spec.NodeName); err == nil {
+					t.observeCompletedPods(ctx, pod.Spec.NodeName, len(keys))
+				}
+			}
+		}
+
+		store, informer := toolscache.NewInformerWithOptions(toolscache.InformerOptions{
+			ListerWatcher: &toolscache.ListWatch{
+				ListWithContextFunc: func(ctx context.Context, options metav1.ListOptions) (runtime.Object, error) {
+					options.FieldSelector = completedPodsSelector
+					return t.Client.Pods("").List(ctx, options)
+				},
+				WatchFuncWithContext: func(ctx context.Context, options metav1.ListOptions) (watch.Interface, error) {
+					options.FieldSelector = completedPodsSelector
+					return t.Client.Pods("").Watch(ctx, options)
+				},
+			},
+			ObjectType: &corev1.Pod{},
+			Handler: toolscache.ResourceEventHandlerFuncs{
+				AddFunc:    observe,
+				UpdateFunc: func(_, obj any) { observe(obj) },
+			},
+			Indexers: toolscache.Indexers{nodeIndex: func(obj any) ([]string, error) {
+				if pod, ok := obj.(*corev1.Pod); ok {
+					return []string{pod.Spec.NodeName}, nil
+				}
+				return nil, nil
+			}},
+		})
+		completed = store.(toolscache.Indexer)
+		informers = append(informers, informer)
+	}
+
+	log.FromContext(ctx).Info("Watching nodes for pressure", "diskPressure", cfg.DiskPressure, "maxTerminatedPods", cfg.MaxTerminatedPods)
+
+	var wg sync.WaitGroup
+	for _, informer := range informers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			informer.RunWithContext(ctx)
+		}()
+	}
+	wg.Wait()
+	return nil
+}
+
+// observeNode triggers a pass for the node in obj if it reports DiskPressure.
+func (t *NodePressureTrigger) observeNode(ctx context.Context, obj any) {
+	if node, ok := obj.(*corev1.Node); ok && hasDiskPressure(node) {
+		cfg := t.Controller.CleanupConfig.PodCleanupConfig.NodePressure
+		if cfg.DiskPressure {
+			t.trigger(ctx, node.Name, nodePressureDisk)
+		}
+	}
+}
+
+// observeCompletedPods triggers a pass for node if it holds at least maxTerminatedPods completed
+// pods.
+func (t *NodePressureTrigger) observeCompletedPods(ctx context.Context, node string, completed int) {
+	cfg := t.Controller.CleanupConfig.PodCleanupConfig.NodePressure
+	if cfg.MaxTerminatedPods > 0 && completed >= cfg.MaxTerminatedPods {
+		t.trigger(ctx, node, nodePressureTerminatedPods)
+	}
+}
+
+// trigger starts a pass for node unless node pressure is disabled, the node's cooldown has not
+// passed or another pass is running. Passes refused because another one is running are not
+// counted towards the cooldown, so the node is tried again on its next update.
+func (t *NodePressureTrigger) trigger(ctx context.Context, node, reason string) {
+	podConfig := t.Controller.CleanupConfig.PodCleanupConfig
+	if !podConfig.Enabled || !podConfig.NodePressure.Enabled {
+		return
+	}
+	logger := log.FromContext(ctx).WithValues("node", node, "reason", reason)
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	now := time.Now()
+	if last, ok := t.triggered[node]; ok && now.Sub(last) < podConfig.NodePressure.EffectiveCooldown() {
+		return
+	}
+
+	runID, err := t.Controller.TriggerNodeRun(ctx, node)
+	if errors.Is(err, ErrRunInProgress) {
+		logger.V(1).Info("Node under pressure while a run is in progress; not triggering another")
+		return
+	}
+	if err != nil {
+		logger.Error(err, "Failed to trigger a cleanup for the node")
+		return
+	}
+
+	t.triggered[node] = now
+	nodePressureTriggeredRunsTotal.WithLabelValues(reason).Inc()
+	logger.Info("Node under pressure; triggered a cleanup of its completed pods", "triggeredRunID", runID)
+}
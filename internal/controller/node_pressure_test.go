@@ -0,0 +1,110 @@
+package controller
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	cleanupconfig "github.com/infrautils/kubeclean/internal/cleanup_config"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestHasDiskPressure(t *testing.T) {
+	node := func(conditions ...corev1.NodeCondition) *corev1.Node {
+		return &corev1.Node{Status: corev1.NodeStatus{Conditions: conditions}}
+	}
+
+	tests := []struct {
+		name string
+		node *corev1.Node
+		want bool
+	}{
+		{name: "disk pressure", node: node(corev1.NodeCondition{Type: corev1.NodeDiskPressure, Status: corev1.ConditionTrue}), want: true},
+		{name: "disk pressure resolved", node: node(corev1.NodeCondition{Type: corev1.NodeDiskPressure, Status: corev1.ConditionFalse})},
+		{name: "memory pressure only", node: node(corev1.NodeCondition{Type: corev1.NodeMemoryPressure, Status: corev1.ConditionTrue})},
+		{name: "no conditions", node: node()},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := hasDiskPressure(tt.node); got != tt.want {
+				t.Errorf("hasDiskPressure() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNodePressureTrigger_CleansUpCompletedPodsOfNode(t *testing.T) {
+	scheme := runtime.NewScheme()
+	_ = corev1.AddToScheme(scheme)
+
+	newPod := func(name, node string, phase corev1.PodPhase) *corev1.Pod {
+		return &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:              name,
+				Namespace:         "default",
+				CreationTimestamp: metav1.NewTime(time.Now().Add(-2 * time.Hour)),
+			},
+			Spec:   corev1.PodSpec{NodeName: node},
+			Status: corev1.PodStatus{Phase: phase},
+		}
+	}
+	client := fake.NewClientBuilder().WithScheme(scheme).WithRuntimeObjects(
+		newPod("full-succeeded", "full", corev1.PodSucceeded),
+		newPod("full-failed", "full", corev1.PodFailed),
+		newPod("full-running", "full", corev1.PodRunning),
+		newPod("other-succeeded", "other", corev1.PodSucceeded),
+	).Build()
+
+	cleanupCfg := &cleanupconfig.CleanupConfig{
+		BatchSize: 10,
+		PodCleanupConfig: cleanupconfig.PodCleanupConfig{
+			Enabled: true,
+			Rules: []cleanupconfig.PodCleanRule{
+				{Name: "succeeded", Enabled: true, Phase: "Succeeded", TTL: cleanupconfig.Duration{Duration: time.Hour}},
+				{Name: "failed", Enabled: true, Phase: "Failed", TTL: cleanupconfig.Duration{Duration: time.Hour}},
+				{Name: "long-running", Enabled: true, Phase: "Running", TTL: cleanupconfig.Duration{Duration: time.Hour}},
+			},
+			NodePressure: cleanupconfig.NodePressureConfig{Enabled: true, DiskPressure: true, MaxTerminatedPods: 5},
+		},
+	}
+	controller := NewPodCleanController(client, scheme, cleanupCfg)
+	trigger := NewNodePressureTrigger(controller, nil)
+
+	// Fewer completed pods than maxTerminatedPods trigger nothing.
+	trigger.observeCompletedPods(context.Background(), "full", 2)
+	if got := len(controller.History(0)); got != 0 {
+		t.Fatalf("Expected no pass below maxTerminatedPods, got %d", got)
+	}
+
+	trigger.observeNode(context.Background(), &corev1.Node{
+		ObjectMeta: metav1.ObjectMeta{Name: "full"},
+		Status: corev1.NodeStatus{Conditions: []corev1.NodeCondition{
+			{Type: corev1.NodeDiskPressure, Status: corev1.ConditionTrue},
+		}},
+	})
+
+	// Wait for the triggered pass to release the run lock.
+	controller.runMu.Lock()
+	controller.runMu.Unlock()
+
+	history := controller.History(0)
+	if len(history) != 1 || history[0].Node != "full" {
+		t.Fatalf("Expected one pass restricted to the node, got %+v", history)
+	}
+	remaining := remainingPodNames(t, client)
+	if remaining["full-succeeded"] || remaining["full-failed"] || !remaining["full-running"] || !remaining["other-succeeded"] {
+		t.Errorf("Expected only the completed pods of the node under pressure to be deleted, got %v", remaining)
+	}
+
+	// The node is in its cooldown, however many completed pods it reports.
+	trigger.observeCompletedPods(context.Background(), "full", 10)
+	controller.runMu.Lock()
+	controller.runMu.Unlock()
+	if got := len(controller.History(0)); got != 1 {
+		t.Errorf("Expected the cooldown to suppress a second pass, got %d passes", got)
+	}
+}
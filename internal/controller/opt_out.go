@@ -0,0 +1,89 @@
+package controller
+
+import (
+	"context"
+
+	appsv1 "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// isOptedOut reports whether the pod's namespace or one of its controllers, such as the Job or
+// the Deployment behind it, carries the kubeclean/disabled annotation. The pod's own annotation
+// is checked by EvaluatePod.
+func (pm *PodMatcher) isOptedOut(ctx context.Context, pod *corev1.Pod) (bool, error) {
+	namespace, err := pm.getNamespace(ctx, pod.Namespace)
+	if err != nil {
+		return false, err
+	}
+	if namespace != nil && namespace.Annotations[AnnotationDisabled] == "true" {
+		return true, nil
+	}
+
+	return pm.isOwnerDisabled(ctx, pod.Namespace, metav1.GetControllerOf(pod))
+}
+
+// isOwnerDisabled walks the controller chain starting at ref and reports whether any controller
+// of a known workload kind carries the kubeclean/disabled annotation. Controllers that no longer
+// exist do not opt the pod out.
+func (pm *PodMatcher) isOwnerDisabled(ctx context.Context, namespace string, ref *metav1.OwnerReference) (bool, error) {
+	if ref == nil {
+		return false, nil
+	}
+
+	obj := newWorkload(ref.Kind)
+	if obj == nil {
+		return false, nil
+	}
+
+	owner := Owner{Kind: ref.Kind, Namespace: namespace, Name: ref.Name}
+	if disabled, ok := pm.disabledOwners[owner]; ok {
+		return disabled, nil
+	}
+
+	err := withThrottleRetry(ctx, "get", func() error {
+		return pm.client.Get(ctx, types.NamespacedName{Namespace: namespace, Name: ref.Name}, obj)
+	})
+	if err != nil && !apierrors.IsNotFound(err) {
+		return false, err
+	}
+
+	disabled := false
+	if err == nil {
+		disabled = obj.GetAnnotations()[AnnotationDisabled] == "true"
+		if !disabled {
+			if disabled, err = pm.isOwnerDisabled(ctx, namespace, metav1.GetControllerOf(obj)); err != nil {
+				return false, err
+			}
+		}
+	}
+
+	if pm.disabledOwners == nil {
+		pm.disabledOwners = map[Owner]bool{}
+	}
+	pm.disabledOwners[owner] = disabled
+	return disabled, nil
+}
+
+// newWorkload returns an empty metadata-only object of a workload kind that may own pods, or nil
+// for other kinds. Only annotations and owner references are read, so the workloads' specs are
+// neither fetched nor cached.
+func newWorkload(kind string) client.Object {
+	var gv schema.GroupVersion
+	switch kind {
+	case "Job", "CronJob":
+		gv = batchv1.SchemeGroupVersion
+	case "ReplicaSet", "Deployment", "StatefulSet", "DaemonSet":
+		gv = appsv1.SchemeGroupVersion
+	default:
+		return nil
+	}
+	obj := &metav1.PartialObjectMetadata{}
+	obj.SetGroupVersionKind(gv.WithKind(kind))
+	return obj
+}
@@ -0,0 +1,120 @@
+package controller
+
+import (
+	"context"
+	"slices"
+	"testing"
+	"time"
+
+	cleanupconfig "github.com/infrautils/kubeclean/internal/cleanup_config"
+	appsv1 "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	ctrlclient "sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
+)
+
+func TestFindPodsToCleanup_HonorsDisabledNamespacesAndOwners(t *testing.T) {
+	scheme := runtime.NewScheme()
+	_ = corev1.AddToScheme(scheme)
+	_ = appsv1.AddToScheme(scheme)
+	_ = batchv1.AddToScheme(scheme)
+
+	isController := true
+	disabled := map[string]string{AnnotationDisabled: "true"}
+	controlledBy := func(kind, name string) []metav1.OwnerReference {
+		return []metav1.OwnerReference{{Kind: kind, Name: name, Controller: &isController}}
+	}
+
+	newPod := func(name, namespace string, owners []metav1.OwnerReference) *corev1.Pod {
+		return &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:              name,
+				Namespace:         namespace,
+				OwnerReferences:   owners,
+				CreationTimestamp: metav1.NewTime(time.Now().Add(-2 * time.Hour)),
+			},
+			Status: corev1.PodStatus{Phase: corev1.PodSucceeded},
+		}
+	}
+
+	client := fake.NewClientBuilder().WithScheme(scheme).WithRuntimeObjects(
+		&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "default"}},
+		&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "tenant", Annotations: disabled}},
+		&batchv1.Job{ObjectMeta: metav1.ObjectMeta{Name: "kept-job", Namespace: "default", Annotations: disabled}},
+		&batchv1.Job{ObjectMeta: metav1.ObjectMeta{Name: "plain-job", Namespace: "default"}},
+		&appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "default", Annotations: disabled}},
+		&appsv1.ReplicaSet{ObjectMeta: metav1.ObjectMeta{Name: "web-abc", Namespace: "default", OwnerReferences: controlledBy("Deployment", "web")}},
+		newPod("standalone", "default", nil),
+		newPod("tenant-pod", "tenant", nil),
+		newPod("kept-job-pod", "default", controlledBy("Job", "kept-job")),
+		newPod("plain-job-pod", "default", controlledBy("Job", "plain-job")),
+		newPod("orphaned-job-pod", "default", controlledBy("Job", "deleted-job")),
+		newPod("web-pod", "default", controlledBy("ReplicaSet", "web-abc")),
+	).Build()
+
+	rule := cleanupconfig.PodCleanRule{Name: "succeeded", Enabled: true, Phase: "Succeeded", TTL: cleanupconfig.Duration{Duration: time.Hour}}
+
+	pods, err := NewPodMatcher(client).FindPodsToCleanup(context.Background(), rule)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	var names []string
+	for _, pod := range pods {
+		names = append(names, pod.Name)
+	}
+	slices.Sort(names)
+
+	expected := []string{"orphaned-job-pod", "plain-job-pod", "standalone"}
+	if !slices.Equal(names, expected) {
+		t.Errorf("Expected pods %v, got %v", expected, names)
+	}
+}
+
+func TestFindPodsToCleanup_SkipsPodsWhoseOwnersCannotBeRead(t *testing.T) {
+	scheme := runtime.NewScheme()
+	_ = corev1.AddToScheme(scheme)
+	_ = batchv1.AddToScheme(scheme)
+
+	isController := true
+	newPod := func(name, job string) *corev1.Pod {
+		pod := &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default", CreationTimestamp: metav1.NewTime(time.Now().Add(-2 * time.Hour))},
+			Status:     corev1.PodStatus{Phase: corev1.PodSucceeded},
+		}
+		if job != "" {
+			pod.OwnerReferences = []metav1.OwnerReference{{Kind: "Job", Name: job, Controller: &isController}}
+		}
+		return pod
+	}
+
+	client := fake.NewClientBuilder().WithScheme(scheme).WithRuntimeObjects(
+		&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "default"}},
+		&batchv1.Job{ObjectMeta: metav1.ObjectMeta{Name: "secret-job", Namespace: "default"}},
+		newPod("standalone", ""),
+		newPod("secret-job-pod", "secret-job"),
+	).WithInterceptorFuncs(interceptor.Funcs{
+		Get: func(ctx context.Context, c ctrlclient.WithWatch, key ctrlclient.ObjectKey, obj ctrlclient.Object, opts ...ctrlclient.GetOption) error {
+			if key.Name == "secret-job" {
+				return apierrors.NewForbidden(schema.GroupResource{Group: "batch", Resource: "jobs"}, key.Name, nil)
+			}
+			return c.Get(ctx, key, obj, opts...)
+		},
+	}).Build()
+
+	rule := cleanupconfig.PodCleanRule{Name: "succeeded", Enabled: true, Phase: "Succeeded", TTL: cleanupconfig.Duration{Duration: time.Hour}}
+
+	pods, err := NewPodMatcher(client).FindPodsToCleanup(context.Background(), rule)
+	if reasons := ErrorReasons(err); reasons[ErrorReasonForbidden] != 1 {
+		t.Errorf("Expected the Forbidden owner lookup to be reported, got %v", err)
+	}
+	if len(pods) != 1 || pods[0].Name != "standalone" {
+		t.Errorf("Expected only the standalone pod to be selected, got %d pod(s)", len(pods))
+	}
+}
@@ -0,0 +1,346 @@
+package controller
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	cleanupconfig "github.com/infrautils/kubeclean/internal/cleanup_config"
+	"github.com/infrautils/kubeclean/internal/notify"
+	appsv1 "k8s.io/api/apps/v1"
+	autoscalingv2 "k8s.io/api/autoscaling/v2"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	policyv1 "k8s.io/api/policy/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+// orphanDetector lists the resources of one kind in a namespace and returns those whose
+// targets no longer exist. Failures are reported as errors alongside the orphans found.
+type orphanDetector func(ctx context.Context, k8sClient client.Client, namespace string) ([]client.Object, error)
+
+var orphanDetectors = map[string]orphanDetector{
+	cleanupconfig.OrphanKindHorizontalPodAutoscaler: findOrphanedHPAs,
+	cleanupconfig.OrphanKindPodDisruptionBudget:     findOrphanedPDBs,
+	cleanupconfig.OrphanKindServiceAccount:          findUnusedServiceAccounts,
+	cleanupconfig.OrphanKindRoleBinding:             findDanglingRoleBindings,
+	cleanupconfig.OrphanKindClusterRoleBinding:      findDanglingClusterRoleBindings,
+	cleanupconfig.OrphanKindImagePullSecret:         findUnusedImagePullSecrets,
+	cleanupconfig.OrphanKindNetworkPolicy:           findIdleNetworkPolicies,
+}
+
+// orphanTracker remembers when each orphan was first observed, so a rule's TTL measures how
+// long a resource has been orphaned rather than its age. State is lost on restart, except for
+// the kinds in persistedOrphanKinds, whose first observation is also kept on the resource.
+type orphanTracker struct {
+	firstSeen map[string]map[types.NamespacedName]time.Time // Keyed by rule name.
+	started   map[string]time.Time                          // When each rule was first evaluated.
+}
+
+func newOrphanTracker() *orphanTracker {
+	return &orphanTracker{
+		firstSeen: map[string]map[types.NamespacedName]time.Time{},
+		started:   map[string]time.Time{},
+	}
+}
+
+// inBurnIn reports whether rule was first evaluated less than burnIn before now.
+func (t *orphanTracker) inBurnIn(rule string, burnIn time.Duration, now time.Time) bool {
+	started, ok := t.started[rule]
+	if !ok {
+		started = now
+		t.started[rule] = now
+	}
+	return now.Sub(started) < burnIn
+}
+
+// observe records the orphans found by rule at now and returns when each was first seen.
+// Orphans new to the tracker that carry an earlier unusedSinceAnnotation were first seen then.
+// When complete is true, resources no longer reported as orphaned are forgotten; after a
+// partial listing they are kept so a transient error does not restart their TTL.
+func (t *orphanTracker) observe(rule string, orphans []client.Object, now time.Time, complete bool) map[types.NamespacedName]time.Time {
+	previous := t.firstSeen[rule]
+	current := map[types.NamespacedName]time.Time{}
+
+	if !complete {
+		for key, seen := range previous {
+			current[key] = seen
+		}
+	}
+
+	for _, obj := range orphans {
+		key := client.ObjectKeyFromObject(obj)
+		if seen, ok := previous[key]; ok {
+			current[key] = seen
+		} else if since, ok := unusedSince(obj); ok && since.Before(now) {
+			current[key] = since
+		} else {
+			current[key] = now
+		}
+	}
+
+	t.firstSeen[rule] = current
+	return current
+}
+
+// cleanUpOrphans executes every orphan rule. Orphans are deleted once they have been orphaned
+// for longer than the rule's TTL, and only when neither the rule nor the config is dry-run and
+// the rule's burn-in period has passed. Rules whose kind the cluster does not serve, such as
+// PodDisruptionBudgets on old clusters, are skipped and reported as unavailable.
+func (c *PodCleanController) cleanUpOrphans(ctx context.Context, run *cleanupRun) {
+	logger := log.FromContext(ctx)
+	logger.Info("Starting orphaned resource cleanup")
+
+	if c.orphans == nil {
+		c.orphans = newOrphanTracker()
+	}
+
+	for _, rule := range c.CleanupConfig.OrphanCleanupConfig.Rules {
+		if !c.overrides.isEnabled(RuleKindOrphan, rule.Name, rule.Enabled) || !run.inLane(rule.Lane) {
+			continue
+		}
+		ctx := withRule(ctx, rule.Name)
+
+		detect, ok := orphanDetectors[rule.Kind]
+		if !ok {
+			logger.Error(fmt.Errorf("unsupported kind %q", rule.Kind), "Skipping orphan rule", "rule", rule.Name)
+			continue
+		}
+		if c.ruleUnavailable(ctx, run, RuleKindOrphan, rule.Name, orphanKinds[rule.Kind]) {
+			continue
+		}
+
+		namespaces := rule.Namespaces
+		if len(namespaces) == 0 {
+			namespaces = []string{""} // All namespaces, or the cluster scope for cluster-scoped kinds
+		}
+
+		var orphans []client.Object
+		var errs []error
+		for _, namespace := range namespaces {
+			found, err := detect(ctx, c.Client, namespace)
+			orphans = append(orphans, found...)
+			if err != nil {
+				errs = append(errs, err)
+			}
+		}
+
+		if err := errors.Join(errs...); err != nil {
+			for reason, count := range ErrorReasons(err) {
+				listErrorsTotal.WithLabelValues(rule.Name, string(reason)).Add(float64(count))
+			}
+			logger.Error(err, "Failed to detect orphaned resources", "rule", rule.Name)
+		}
+
+		now := time.Now()
+		burningIn := c.orphans.inBurnIn(rule.Name, rule.BurnInPeriod(), now)
+		firstSeen := c.orphans.observe(rule.Name, orphans, now, len(errs) == 0)
+		orphanedResources.WithLabelValues(rule.Name).Set(float64(len(orphans)))
+
+		if gvk, ok := persistedOrphanKinds[rule.Kind]; ok && !run.DryRun {
+			if err := persistUnusedSince(ctx, c.Client, gvk, namespaces, orphans, firstSeen, len(errs) == 0); err != nil {
+				logger.Error(err, "Failed to record when resources were first seen unused", "rule", rule.Name)
+			}
+		}
+
+		var expired []client.Object
+		for _, obj := range orphans {
+			orphanedFor := now.Sub(firstSeen[client.ObjectKeyFromObject(obj)])
+			logger.Info("Found orphaned resource", "rule", rule.Name, "kind", rule.Kind,
+				"name", obj.GetName(), "namespace", obj.GetNamespace(), "orphanedFor", orphanedFor.Round(time.Second))
+			if orphanedFor > rule.TTL.Duration {
+				expired = append(expired, obj)
+			}
+		}
+
+		if len(expired) == 0 {
+			c.statuses.record(RuleKindOrphan, rule.Name, now, len(orphans), 0, 0, errors.Join(errs...))
+			continue
+		}
+
+		dryRun := run.DryRun || rule.IsDryRun() || burningIn
+		if burningIn && !rule.IsDryRun() {
+			logger.Info("Rule is in its burn-in period; reporting only", "rule", rule.Name, "burnIn", rule.BurnInPeriod())
+		}
+		deleteErr := BatchDeleteObjects(ctx, c.Client, rule.Kind, expired, c.CleanupConfig.EffectiveBatchSize(), c.CleanupConfig.EffectiveBatchDelay(), dryRun)
+		failed := run.recordDeleteFailures(rule.Name, deleteErr)
+		c.statuses.record(RuleKindOrphan, rule.Name, now, len(orphans), deletedCount(len(expired), dryRun)-failed, failed,
+			ruleError(errors.Join(errs...), deleteErr, len(expired), failed))
+
+		c.notifyRule(ctx, run, rule.Name, rule.NotificationSinks,
+			notify.Event{Kind: rule.Kind, Resources: len(expired), DryRun: dryRun}, rule.Kind+"(s)")
+	}
+}
+
+// findOrphanedHPAs returns the HorizontalPodAutoscalers whose scaleTargetRef no longer exists.
+func findOrphanedHPAs(ctx context.Context, k8sClient client.Client, namespace string) ([]client.Object, error) {
+	var hpas autoscalingv2.HorizontalPodAutoscalerList
+	if err := withThrottleRetry(ctx, "list", func() error {
+		return k8sClient.List(ctx, &hpas, client.InNamespace(namespace))
+	}); err != nil {
+		return nil, newListError("horizontalpodautoscalers", namespace, err)
+	}
+
+	var orphans []client.Object
+	var errs []error
+
+	for i := range hpas.Items {
+		hpa := &hpas.Items[i]
+		ref := hpa.Spec.ScaleTargetRef
+
+		gv, err := schema.ParseGroupVersion(ref.APIVersion)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("hpa %s/%s: invalid scaleTargetRef apiVersion: %w", hpa.Namespace, hpa.Name, err))
+			continue
+		}
+
+		// Targets are fetched as unstructured objects so any scalable kind, including custom
+		// resources, can be checked without adding it to the scheme or the informer cache.
+		target := &unstructured.Unstructured{}
+		target.SetGroupVersionKind(gv.WithKind(ref.Kind))
+
+		err = withThrottleRetry(ctx, "get", func() error {
+			return k8sClient.Get(ctx, types.NamespacedName{Namespace: hpa.Namespace, Name: ref.Name}, target)
+		})
+		switch {
+		case err == nil:
+		case apierrors.IsNotFound(err), meta.IsNoMatchError(err):
+			orphans = append(orphans, hpa)
+		default:
+			errs = append(errs, fmt.Errorf("hpa %s/%s: get %s %s: %w", hpa.Namespace, hpa.Name, ref.Kind, ref.Name, err))
+		}
+	}
+
+	return orphans, errors.Join(errs...)
+}
+
+// findOrphanedPDBs returns the PodDisruptionBudgets whose selector matches neither a pod nor
+// the pod template of a workload, so budgets for workloads scaled to zero are kept.
+func findOrphanedPDBs(ctx context.Context, k8sClient client.Client, namespace string) ([]client.Object, error) {
+	var pdbs policyv1.PodDisruptionBudgetList
+	if err := withThrottleRetry(ctx, "list", func() error {
+		return k8sClient.List(ctx, &pdbs, client.InNamespace(namespace))
+	}); err != nil {
+		return nil, newListError("poddisruptionbudgets", namespace, err)
+	}
+
+	if len(pdbs.Items) == 0 {
+		return nil, nil
+	}
+
+	templates, err := listPodTemplates(ctx, k8sClient, namespace)
+	if err != nil {
+		return nil, err
+	}
+
+	var orphans []client.Object
+	var errs []error
+
+	for i := range pdbs.Items {
+		pdb := &pdbs.Items[i]
+
+		// A nil selector selects nothing; leave such budgets alone rather than guess.
+		if pdb.Spec.Selector == nil {
+			continue
+		}
+
+		selector, err := metav1.LabelSelectorAsSelector(pdb.Spec.Selector)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("pdb %s/%s: invalid selector: %w", pdb.Namespace, pdb.Name, err))
+			continue
+		}
+
+		matched := false
+		for _, template := range templates {
+			if template.Namespace == pdb.Namespace && selector.Matches(template.Labels) {
+				matched = true
+				break
+			}
+		}
+
+		if !matched {
+			orphans = append(orphans, pdb)
+		}
+	}
+
+	return orphans, errors.Join(errs...)
+}
+
+// podTemplate is the namespace, labels and spec of a pod or of a workload's pod template.
+type podTemplate struct {
+	Namespace string
+	Labels    labels.Set
+	Spec      *corev1.PodSpec
+}
+
+// listPodTemplates returns every pod in namespace along with the pod templates of the workloads
+// that may create pods later, so workloads scaled to zero or between CronJob runs are seen too.
+func listPodTemplates(ctx context.Context, k8sClient client.Client, namespace string) ([]podTemplate, error) {
+	var pods corev1.PodList
+	var deployments appsv1.DeploymentList
+	var statefulSets appsv1.StatefulSetList
+	var daemonSets appsv1.DaemonSetList
+	var replicaSets appsv1.ReplicaSetList
+	var jobs batchv1.JobList
+	var cronJobs batchv1.CronJobList
+
+	lists := []struct {
+		resource string
+		list     client.ObjectList
+	}{
+		{"pods", &pods},
+		{"deployments", &deployments},
+		{"statefulsets", &statefulSets},
+		{"daemonsets", &daemonSets},
+		{"replicasets", &replicaSets},
+		{"jobs", &jobs},
+		{"cronjobs", &cronJobs},
+	}
+
+	for _, l := range lists {
+		if err := withThrottleRetry(ctx, "list", func() error {
+			return k8sClient.List(ctx, l.list, client.InNamespace(namespace))
+		}); err != nil {
+			return nil, newListError(l.resource, namespace, err)
+		}
+	}
+
+	var templates []podTemplate
+	add := func(ns string, meta metav1.ObjectMeta, spec *corev1.PodSpec) {
+		templates = append(templates, podTemplate{Namespace: ns, Labels: labels.Set(meta.Labels), Spec: spec})
+	}
+
+	for i := range pods.Items {
+		add(pods.Items[i].Namespace, pods.Items[i].ObjectMeta, &pods.Items[i].Spec)
+	}
+	for i := range deployments.Items {
+		add(deployments.Items[i].Namespace, deployments.Items[i].Spec.Template.ObjectMeta, &deployments.Items[i].Spec.Template.Spec)
+	}
+	for i := range statefulSets.Items {
+		add(statefulSets.Items[i].Namespace, statefulSets.Items[i].Spec.Template.ObjectMeta, &statefulSets.Items[i].Spec.Template.Spec)
+	}
+	for i := range daemonSets.Items {
+		add(daemonSets.Items[i].Namespace, daemonSets.Items[i].Spec.Template.ObjectMeta, &daemonSets.Items[i].Spec.Template.Spec)
+	}
+	for i := range replicaSets.Items {
+		add(replicaSets.Items[i].Namespace, replicaSets.Items[i].Spec.Template.ObjectMeta, &replicaSets.Items[i].Spec.Template.Spec)
+	}
+	for i := range jobs.Items {
+		add(jobs.Items[i].Namespace, jobs.Items[i].Spec.Template.ObjectMeta, &jobs.Items[i].Spec.Template.Spec)
+	}
+	for i := range cronJobs.Items {
+		template := &cronJobs.Items[i].Spec.JobTemplate.Spec.Template
+		add(cronJobs.Items[i].Namespace, template.ObjectMeta, &template.Spec)
+	}
+
+	return templates, nil
+}
@@ -0,0 +1,65 @@
+package controller
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// findIdleNetworkPolicies returns the NetworkPolicies whose podSelector matches no running pod.
+// Policies with an empty podSelector apply to the whole namespace, such as default-deny
+// policies, and are kept even in empty namespaces because future pods rely on them.
+func findIdleNetworkPolicies(ctx context.Context, k8sClient client.Client, namespace string) ([]client.Object, error) {
+	var policies networkingv1.NetworkPolicyList
+	if err := withThrottleRetry(ctx, "list", func() error {
+		return k8sClient.List(ctx, &policies, client.InNamespace(namespace))
+	}); err != nil {
+		return nil, newListError("networkpolicies", namespace, err)
+	}
+
+	if len(policies.Items) == 0 {
+		return nil, nil
+	}
+
+	var pods corev1.PodList
+	if err := withThrottleRetry(ctx, "list", func() error {
+		return k8sClient.List(ctx, &pods, client.InNamespace(namespace))
+	}); err != nil {
+		return nil, newListError("pods", namespace, err)
+	}
+
+	var idle []client.Object
+	var errs []error
+	for i := range policies.Items {
+		policy := &policies.Items[i]
+		if len(policy.Spec.PodSelector.MatchLabels) == 0 && len(policy.Spec.PodSelector.MatchExpressions) == 0 {
+			continue
+		}
+
+		selector, err := metav1.LabelSelectorAsSelector(&policy.Spec.PodSelector)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("networkpolicy %s/%s: invalid podSelector: %w", policy.Namespace, policy.Name, err))
+			continue
+		}
+
+		matched := false
+		for i := range pods.Items {
+			if pods.Items[i].Namespace == policy.Namespace && selector.Matches(labels.Set(pods.Items[i].Labels)) {
+				matched = true
+				break
+			}
+		}
+
+		if !matched {
+			idle = append(idle, policy)
+		}
+	}
+
+	return idle, errors.Join(errs...)
+}
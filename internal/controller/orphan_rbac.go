@@ -0,0 +1,185 @@
+package controller
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// systemNamespaces hold ServiceAccounts used by control-plane components through credentials
+// rather than pods, so reference analysis would wrongly flag them as unused.
+var systemNamespaces = map[string]bool{
+	"kube-system":     true,
+	"kube-public":     true,
+	"kube-node-lease": true,
+}
+
+// isSystemBinding reports whether a binding is managed by Kubernetes itself.
+func isSystemBinding(name string) bool {
+	return strings.HasPrefix(name, "system:")
+}
+
+// findUnusedServiceAccounts returns the ServiceAccounts no pod or workload template runs as.
+// Every namespace's default ServiceAccount is recreated on deletion and is therefore ignored.
+func findUnusedServiceAccounts(ctx context.Context, k8sClient client.Client, namespace string) ([]client.Object, error) {
+	var serviceAccounts corev1.ServiceAccountList
+	if err := withThrottleRetry(ctx, "list", func() error {
+		return k8sClient.List(ctx, &serviceAccounts, client.InNamespace(namespace))
+	}); err != nil {
+		return nil, newListError("serviceaccounts", namespace, err)
+	}
+
+	if len(serviceAccounts.Items) == 0 {
+		return nil, nil
+	}
+
+	templates, err := listPodTemplates(ctx, k8sClient, namespace)
+	if err != nil {
+		return nil, err
+	}
+
+	inUse := map[types.NamespacedName]bool{}
+	for _, template := range templates {
+		name := template.Spec.ServiceAccountName
+		if name == "" {
+			name = "default"
+		}
+		inUse[types.NamespacedName{Namespace: template.Namespace, Name: name}] = true
+	}
+
+	var unused []client.Object
+	for i := range serviceAccounts.Items {
+		sa := &serviceAccounts.Items[i]
+		if sa.Name == "default" || systemNamespaces[sa.Namespace] {
+			continue
+		}
+		if !inUse[client.ObjectKeyFromObject(sa)] {
+			unused = append(unused, sa)
+		}
+	}
+
+	return unused, nil
+}
+
+// findDanglingRoleBindings returns the RoleBindings whose subjects no longer exist.
+func findDanglingRoleBindings(ctx context.Context, k8sClient client.Client, namespace string) ([]client.Object, error) {
+	var bindings rbacv1.RoleBindingList
+	if err := withThrottleRetry(ctx, "list", func() error {
+		return k8sClient.List(ctx, &bindings, client.InNamespace(namespace))
+	}); err != nil {
+		return nil, newListError("rolebindings", namespace, err)
+	}
+
+	checker := newSubjectChecker(k8sClient)
+	var dangling []client.Object
+	var errs []error
+
+	for i := range bindings.Items {
+		binding := &bindings.Items[i]
+		if isSystemBinding(binding.Name) || systemNamespaces[binding.Namespace] {
+			continue
+		}
+
+		gone, err := checker.allSubjectsGone(ctx, binding.Subjects, binding.Namespace)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("rolebinding %s/%s: %w", binding.Namespace, binding.Name, err))
+			continue
+		}
+		if gone {
+			dangling = append(dangling, binding)
+		}
+	}
+
+	return dangling, errors.Join(errs...)
+}
+
+// findDanglingClusterRoleBindings returns the ClusterRoleBindings whose subjects no longer exist.
+// ClusterRoleBindings are cluster-scoped, so namespace is ignored.
+func findDanglingClusterRoleBindings(ctx context.Context, k8sClient client.Client, _ string) ([]client.Object, error) {
+	var bindings rbacv1.ClusterRoleBindingList
+	if err := withThrottleRetry(ctx, "list", func() error {
+		return k8sClient.List(ctx, &bindings)
+	}); err != nil {
+		return nil, newListError("clusterrolebindings", "", err)
+	}
+
+	checker := newSubjectChecker(k8sClient)
+	var dangling []client.Object
+	var errs []error
+
+	for i := range bindings.Items {
+		binding := &bindings.Items[i]
+		if isSystemBinding(binding.Name) {
+			continue
+		}
+
+		gone, err := checker.allSubjectsGone(ctx, binding.Subjects, "")
+		if err != nil {
+			errs = append(errs, fmt.Errorf("clusterrolebinding %s: %w", binding.Name, err))
+			continue
+		}
+		if gone {
+			dangling = append(dangling, binding)
+		}
+	}
+
+	return dangling, errors.Join(errs...)
+}
+
+// subjectChecker resolves whether binding subjects exist, remembering each ServiceAccount lookup.
+type subjectChecker struct {
+	client client.Client
+	exists map[types.NamespacedName]bool
+}
+
+func newSubjectChecker(k8sClient client.Client) *subjectChecker {
+	return &subjectChecker{client: k8sClient, exists: map[types.NamespacedName]bool{}}
+}
+
+// allSubjectsGone reports whether a binding has subjects and all of them are ServiceAccounts
+// that no longer exist. Users and groups live outside the cluster and are assumed to exist.
+func (sc *subjectChecker) allSubjectsGone(ctx context.Context, subjects []rbacv1.Subject, bindingNamespace string) (bool, error) {
+	if len(subjects) == 0 {
+		return false, nil
+	}
+
+	for _, subject := range subjects {
+		if subject.Kind != rbacv1.ServiceAccountKind {
+			return false, nil
+		}
+
+		key := types.NamespacedName{Namespace: subject.Namespace, Name: subject.Name}
+		if key.Namespace == "" {
+			key.Namespace = bindingNamespace
+		}
+
+		exists, known := sc.exists[key]
+		if !known {
+			err := withThrottleRetry(ctx, "get", func() error {
+				return sc.client.Get(ctx, key, &corev1.ServiceAccount{})
+			})
+			switch {
+			case err == nil:
+				exists = true
+			case apierrors.IsNotFound(err):
+				exists = false
+			default:
+				return false, fmt.Errorf("get serviceaccount %s: %w", key, err)
+			}
+			sc.exists[key] = exists
+		}
+
+		if exists {
+			return false, nil
+		}
+	}
+
+	return true, nil
+}
@@ -0,0 +1,69 @@
+package controller
+
+import (
+	"context"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// findUnusedImagePullSecrets returns the docker-registry Secrets that neither a ServiceAccount
+// nor a pod or workload template lists in its imagePullSecrets.
+func findUnusedImagePullSecrets(ctx context.Context, k8sClient client.Client, namespace string) ([]client.Object, error) {
+	var secrets corev1.SecretList
+	if err := withThrottleRetry(ctx, "list", func() error {
+		return k8sClient.List(ctx, &secrets, client.InNamespace(namespace))
+	}); err != nil {
+		return nil, newListError("secrets", namespace, err)
+	}
+
+	var candidates []*corev1.Secret
+	for i := range secrets.Items {
+		secret := &secrets.Items[i]
+		if secret.Type != corev1.SecretTypeDockerConfigJson && secret.Type != corev1.SecretTypeDockercfg {
+			continue
+		}
+		if systemNamespaces[secret.Namespace] {
+			continue
+		}
+		candidates = append(candidates, secret)
+	}
+
+	if len(candidates) == 0 {
+		return nil, nil
+	}
+
+	var serviceAccounts corev1.ServiceAccountList
+	if err := withThrottleRetry(ctx, "list", func() error {
+		return k8sClient.List(ctx, &serviceAccounts, client.InNamespace(namespace))
+	}); err != nil {
+		return nil, newListError("serviceaccounts", namespace, err)
+	}
+
+	templates, err := listPodTemplates(ctx, k8sClient, namespace)
+	if err != nil {
+		return nil, err
+	}
+
+	referenced := map[types.NamespacedName]bool{}
+	for _, sa := range serviceAccounts.Items {
+		for _, ref := range sa.ImagePullSecrets {
+			referenced[types.NamespacedName{Namespace: sa.Namespace, Name: ref.Name}] = true
+		}
+	}
+	for _, template := range templates {
+		for _, ref := range template.Spec.ImagePullSecrets {
+			referenced[types.NamespacedName{Namespace: template.Namespace, Name: ref.Name}] = true
+		}
+	}
+
+	var unused []client.Object
+	for _, secret := range candidates {
+		if !referenced[client.ObjectKeyFromObject(secret)] {
+			unused = append(unused, secret)
+		}
+	}
+
+	return unused, nil
+}
@@ -0,0 +1,335 @@
+package controller
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	cleanupconfig "github.com/infrautils/kubeclean/internal/cleanup_config"
+	appsv1 "k8s.io/api/apps/v1"
+	autoscalingv2 "k8s.io/api/autoscaling/v2"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	policyv1 "k8s.io/api/policy/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func newHPA(name, target string) *autoscalingv2.HorizontalPodAutoscaler {
+	return &autoscalingv2.HorizontalPodAutoscaler{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default"},
+		Spec: autoscalingv2.HorizontalPodAutoscalerSpec{
+			ScaleTargetRef: autoscalingv2.CrossVersionObjectReference{APIVersion: "apps/v1", Kind: "Deployment", Name: target},
+			MaxReplicas:    3,
+		},
+	}
+}
+
+func newPDB(name, app string) *policyv1.PodDisruptionBudget {
+	return &policyv1.PodDisruptionBudget{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default"},
+		Spec: policyv1.PodDisruptionBudgetSpec{
+			Selector: &metav1.LabelSelector{MatchLabels: map[string]string{"app": app}},
+		},
+	}
+}
+
+func TestPodCleanupController_OrphanCleanup(t *testing.T) {
+	scheme := runtime.NewScheme()
+	_ = corev1.AddToScheme(scheme)
+	_ = appsv1.AddToScheme(scheme)
+	_ = autoscalingv2.AddToScheme(scheme)
+	_ = policyv1.AddToScheme(scheme)
+	_ = batchv1.AddToScheme(scheme)
+
+	deployment := &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "default"},
+		Spec: appsv1.DeploymentSpec{
+			Selector: &metav1.LabelSelector{MatchLabels: map[string]string{"app": "web"}},
+			Template: corev1.PodTemplateSpec{ObjectMeta: metav1.ObjectMeta{Labels: map[string]string{"app": "web"}}},
+		},
+	}
+	pod := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "batch-1", Namespace: "default", Labels: map[string]string{"app": "batch"}}}
+	invalidPDB := newPDB("invalid", "invalid")
+	invalidPDB.Spec.Selector.MatchExpressions = []metav1.LabelSelectorRequirement{{Key: "app", Operator: "Bogus"}}
+
+	k8sClient := fake.NewClientBuilder().WithScheme(scheme).WithObjects(
+		deployment, pod,
+		newHPA("web", "web"),
+		newHPA("removed", "removed"),
+		newPDB("web", "web"),     // Matches the deployment's pod template.
+		newPDB("batch", "batch"), // Matches a running pod.
+		newPDB("removed", "removed"),
+		invalidPDB,
+	).Build()
+
+	// Orphans are aged from their first observation, so the TTL passes between two runs.
+	rules := []cleanupconfig.OrphanCleanRule{
+		{Name: "hpas", Enabled: true, Kind: cleanupconfig.OrphanKindHorizontalPodAutoscaler, TTL: cleanupconfig.Duration{Duration: time.Nanosecond}},
+		{Name: "pdbs", Enabled: true, Kind: cleanupconfig.OrphanKindPodDisruptionBudget, TTL: cleanupconfig.Duration{Duration: time.Nanosecond}},
+	}
+	cleanupCfg := &cleanupconfig.CleanupConfig{
+		BatchSize:           10,
+		OrphanCleanupConfig: cleanupconfig.OrphanCleanupConfig{Enabled: true, Rules: rules},
+	}
+	controller := NewPodCleanController(k8sClient, scheme, cleanupCfg)
+
+	// First run: orphans are only recorded; none has been orphaned for longer than the TTL yet.
+	controller.RunCleanUp(context.Background())
+
+	for _, rule := range []string{"hpas", "pdbs"} {
+		if status, _ := controller.RuleStatus(RuleKindOrphan, rule); status.LastMatched != 1 || status.LastDeleted != 0 {
+			t.Errorf("Expected rule %s to find exactly one orphan and delete nothing, got %+v", rule, status)
+		}
+	}
+	if status, _ := controller.RuleStatus(RuleKindOrphan, "pdbs"); !strings.Contains(status.LastError, "pdb default/invalid: invalid selector") {
+		t.Errorf("Expected the invalid selector to be reported, got %q", status.LastError)
+	}
+
+	// The TTL has passed, but rules are dry-run by default, so nothing is deleted.
+	controller.RunCleanUp(context.Background())
+
+	assertExists := func(obj client.Object, name string, want bool) {
+		t.Helper()
+		err := k8sClient.Get(context.Background(), types.NamespacedName{Namespace: "default", Name: name}, obj)
+		if exists := err == nil; exists != want {
+			t.Errorf("Expected %T %s to exist=%v, got err %v", obj, name, want, err)
+		}
+	}
+	assertExists(&autoscalingv2.HorizontalPodAutoscaler{}, "removed", true)
+	assertExists(&policyv1.PodDisruptionBudget{}, "removed", true)
+
+	// Explicitly disabling dry-run deletes the orphans and keeps everything else.
+	disabled := false
+	for i := range cleanupCfg.OrphanCleanupConfig.Rules {
+		cleanupCfg.OrphanCleanupConfig.Rules[i].DryRun = &disabled
+	}
+	controller.RunCleanUp(context.Background())
+
+	assertExists(&autoscalingv2.HorizontalPodAutoscaler{}, "removed", false)
+	assertExists(&policyv1.PodDisruptionBudget{}, "removed", false)
+	assertExists(&autoscalingv2.HorizontalPodAutoscaler{}, "web", true)
+	assertExists(&policyv1.PodDisruptionBudget{}, "web", true)
+	assertExists(&policyv1.PodDisruptionBudget{}, "batch", true)
+	assertExists(&policyv1.PodDisruptionBudget{}, "invalid", true)
+}
+
+func TestOrphanDetectors_ServiceAccountsAndBindings(t *testing.T) {
+	scheme := runtime.NewScheme()
+	_ = corev1.AddToScheme(scheme)
+	_ = appsv1.AddToScheme(scheme)
+	_ = batchv1.AddToScheme(scheme)
+	_ = rbacv1.AddToScheme(scheme)
+
+	sa := func(namespace, name string) *corev1.ServiceAccount {
+		return &corev1.ServiceAccount{ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace}}
+	}
+	saSubject := func(namespace, name string) rbacv1.Subject {
+		return rbacv1.Subject{Kind: rbacv1.ServiceAccountKind, Namespace: namespace, Name: name}
+	}
+
+	cronJob := &batchv1.CronJob{
+		ObjectMeta: metav1.ObjectMeta{Name: "nightly", Namespace: "default"},
+		Spec: batchv1.CronJobSpec{JobTemplate: batchv1.JobTemplateSpec{Spec: batchv1.JobSpec{
+			Template: corev1.PodTemplateSpec{Spec: corev1.PodSpec{ServiceAccountName: "nightly"}},
+		}}},
+	}
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "api-0", Namespace: "default"},
+		Spec:       corev1.PodSpec{ServiceAccountName: "api"},
+	}
+
+	k8sClient := fake.NewClientBuilder().WithScheme(scheme).WithObjects(
+		cronJob, pod,
+		sa("default", "default"),
+		sa("default", "api"),
+		sa("default", "nightly"),
+		sa("default", "unused"),
+		sa("kube-system", "deployment-controller"),
+		&rbacv1.RoleBinding{
+			ObjectMeta: metav1.ObjectMeta{Name: "api", Namespace: "default"},
+			Subjects:   []rbacv1.Subject{{Kind: rbacv1.ServiceAccountKind, Name: "api"}},
+		},
+		&rbacv1.RoleBinding{
+			ObjectMeta: metav1.ObjectMeta{Name: "deleted-sa", Namespace: "default"},
+			Subjects:   []rbacv1.Subject{{Kind: rbacv1.ServiceAccountKind, Name: "deleted"}},
+		},
+		&rbacv1.ClusterRoleBinding{
+			ObjectMeta: metav1.ObjectMeta{Name: "mixed"},
+			Subjects:   []rbacv1.Subject{saSubject("default", "deleted"), {Kind: rbacv1.UserKind, Name: "alice"}},
+		},
+		&rbacv1.ClusterRoleBinding{
+			ObjectMeta: metav1.ObjectMeta{Name: "gone"},
+			Subjects:   []rbacv1.Subject{saSubject("team-a", "deleted"), saSubject("team-b", "deleted")},
+		},
+		&rbacv1.ClusterRoleBinding{
+			ObjectMeta: metav1.ObjectMeta{Name: "system:gone"},
+			Subjects:   []rbacv1.Subject{saSubject("team-a", "deleted")},
+		},
+	).Build()
+
+	names := func(objects []client.Object) []string {
+		var result []string
+		for _, obj := range objects {
+			result = append(result, obj.GetName())
+		}
+		return result
+	}
+
+	tests := []struct {
+		kind string
+		want []string
+	}{
+		{cleanupconfig.OrphanKindServiceAccount, []string{"unused"}},
+		{cleanupconfig.OrphanKindRoleBinding, []string{"deleted-sa"}},
+		{cleanupconfig.OrphanKindClusterRoleBinding, []string{"gone"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.kind, func(t *testing.T) {
+			found, err := orphanDetectors[tt.kind](context.Background(), k8sClient, "")
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if got := names(found); !slices.Equal(got, tt.want) {
+				t.Errorf("Expected orphans %v, got %v", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestPodCleanupController_ImagePullSecretBurnIn(t *testing.T) {
+	scheme := runtime.NewScheme()
+	_ = corev1.AddToScheme(scheme)
+	_ = appsv1.AddToScheme(scheme)
+	_ = batchv1.AddToScheme(scheme)
+
+	pullSecret := func(name string) *corev1.Secret {
+		return &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default"},
+			Type:       corev1.SecretTypeDockerConfigJson,
+		}
+	}
+
+	k8sClient := fake.NewClientBuilder().WithScheme(scheme).WithObjects(
+		pullSecret("used-by-sa"),
+		pullSecret("used-by-deployment"),
+		pullSecret("unused"),
+		&corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: "opaque", Namespace: "default"}, Type: corev1.SecretTypeOpaque},
+		&corev1.ServiceAccount{
+			ObjectMeta:       metav1.ObjectMeta{Name: "builder", Namespace: "default"},
+			ImagePullSecrets: []corev1.LocalObjectReference{{Name: "used-by-sa"}},
+		},
+		&appsv1.Deployment{
+			ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "default"},
+			Spec: appsv1.DeploymentSpec{Template: corev1.PodTemplateSpec{Spec: corev1.PodSpec{
+				ImagePullSecrets: []corev1.LocalObjectReference{{Name: "used-by-deployment"}},
+			}}},
+		},
+	).Build()
+
+	disabled := false
+	cleanupCfg := &cleanupconfig.CleanupConfig{
+		BatchSize: 10,
+		OrphanCleanupConfig: cleanupconfig.OrphanCleanupConfig{
+			Enabled: true,
+			Rules: []cleanupconfig.OrphanCleanRule{{
+				Name:    "pull-secrets",
+				Enabled: true,
+				Kind:    cleanupconfig.OrphanKindImagePullSecret,
+				TTL:     cleanupconfig.Duration{Duration: time.Nanosecond},
+				DryRun:  &disabled,
+			}},
+		},
+	}
+	controller := NewPodCleanController(k8sClient, scheme, cleanupCfg)
+
+	controller.RunCleanUp(context.Background())
+
+	if status, _ := controller.RuleStatus(RuleKindOrphan, "pull-secrets"); status.LastMatched != 1 {
+		t.Fatalf("Expected only the unused pull secret to be found, got %+v", status)
+	}
+
+	// The TTL has passed but the rule is still in its default burn-in period.
+	controller.RunCleanUp(context.Background())
+	if err := k8sClient.Get(context.Background(), types.NamespacedName{Namespace: "default", Name: "unused"}, &corev1.Secret{}); err != nil {
+		t.Fatalf("Expected unused secret to survive the burn-in period, got %v", err)
+	}
+
+	controller.orphans.started["pull-secrets"] = time.Now().Add(-cleanupconfig.DefaultImagePullSecretBurnIn)
+	controller.RunCleanUp(context.Background())
+
+	var secrets corev1.SecretList
+	if err := k8sClient.List(context.Background(), &secrets); err != nil {
+		t.Fatalf("Failed to list secrets: %v", err)
+	}
+	var remaining []string
+	for _, secret := range secrets.Items {
+		remaining = append(remaining, secret.Name)
+	}
+	if want := []string{"opaque", "used-by-deployment", "used-by-sa"}; !slices.Equal(remaining, want) {
+		t.Errorf("Expected remaining secrets %v, got %v", want, remaining)
+	}
+}
+
+func TestFindIdleNetworkPolicies(t *testing.T) {
+	scheme := runtime.NewScheme()
+	_ = corev1.AddToScheme(scheme)
+	_ = networkingv1.AddToScheme(scheme)
+
+	policy := func(name string, selector metav1.LabelSelector) *networkingv1.NetworkPolicy {
+		return &networkingv1.NetworkPolicy{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default"},
+			Spec:       networkingv1.NetworkPolicySpec{PodSelector: selector},
+		}
+	}
+
+	k8sClient := fake.NewClientBuilder().WithScheme(scheme).WithObjects(
+		&corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "web-0", Namespace: "default", Labels: map[string]string{"app": "web"}}},
+		policy("web", metav1.LabelSelector{MatchLabels: map[string]string{"app": "web"}}),
+		policy("legacy", metav1.LabelSelector{MatchLabels: map[string]string{"app": "legacy"}}),
+		policy("default-deny", metav1.LabelSelector{}),
+		policy("invalid", metav1.LabelSelector{MatchExpressions: []metav1.LabelSelectorRequirement{{Key: "app", Operator: "Bogus"}}}),
+	).Build()
+
+	idle, err := findIdleNetworkPolicies(context.Background(), k8sClient, "")
+	if err == nil || !strings.Contains(err.Error(), "networkpolicy default/invalid: invalid podSelector") {
+		t.Errorf("Expected the invalid podSelector to be reported, got %v", err)
+	}
+	if len(idle) != 1 || idle[0].GetName() != "legacy" {
+		t.Errorf("Expected only the legacy policy to be idle, got %v", idle)
+	}
+}
+
+func TestOrphanTracker_Observe(t *testing.T) {
+	tracker := newOrphanTracker()
+	start := time.Now()
+
+	a := newPDB("a", "a")
+	b := newPDB("b", "b")
+
+	tracker.observe("rule", []client.Object{a, b}, start, true)
+
+	// b is no longer orphaned after a complete run and is forgotten; a keeps its first-seen time.
+	seen := tracker.observe("rule", []client.Object{a}, start.Add(time.Hour), true)
+	if got := seen[client.ObjectKeyFromObject(a)]; !got.Equal(start) {
+		t.Errorf("Expected a to keep first-seen time %v, got %v", start, got)
+	}
+	if _, ok := seen[client.ObjectKeyFromObject(b)]; ok {
+		t.Errorf("Expected b to be forgotten")
+	}
+
+	// After a partial run, orphans that were not reported are kept.
+	tracker.observe("rule", []client.Object{a, b}, start.Add(2*time.Hour), true)
+	seen = tracker.observe("rule", nil, start.Add(3*time.Hour), false)
+	if len(seen) != 2 {
+		t.Errorf("Expected both orphans to be kept after a partial run, got %v", seen)
+	}
+}
@@ -0,0 +1,222 @@
+package controller
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+
+	cleanupconfig "github.com/infrautils/kubeclean/internal/cleanup_config"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+// ErrRuleNotFound is returned when a runtime override names a rule that is not configured.
+var ErrRuleNotFound = errors.New("rule not found")
+
+// ErrAmbiguousRule is returned when a runtime override names a rule without its kind, and rules
+// of several kinds have that name.
+var ErrAmbiguousRule = errors.New("rules of several kinds have this name; set the kind")
+
+// Rule kinds, one per section of the config. Rule names are only unique within their kind.
+const (
+	RuleKindPod          = "pod"
+	RuleKindCertManager  = "certManager"
+	RuleKindOrphan       = "orphan"
+	RuleKindIdleWorkload = "idleWorkload"
+	RuleKindStaleCronJob = "staleCronJob"
+	RuleKindGeneric      = "generic"
+)
+
+// ruleKey identifies a rule across the sections of the config.
+type ruleKey struct {
+	kind string
+	name string
+}
+
+// ruleOverrides holds enable/disable switches set at runtime. They take precedence over the
+// rules' enabled field and are lost when the controller restarts. Reloads drop the overrides of
+// rules that are gone, and those enabling a rule that no longer validates.
+type ruleOverrides struct {
+	mu      sync.RWMutex
+	enabled map[ruleKey]bool
+}
+
+func newRuleOverrides() *ruleOverrides {
+	return &ruleOverrides{enabled: map[ruleKey]bool{}}
+}
+
+// isEnabled returns the runtime override for the rule of kind named name, falling back to
+// configured.
+func (o *ruleOverrides) isEnabled(kind, name string, configured bool) bool {
+	if o == nil {
+		return configured
+	}
+
+	o.mu.RLock()
+	defer o.mu.RUnlock()
+
+	if enabled, ok := o.enabled[ruleKey{kind: kind, name: name}]; ok {
+		return enabled
+	}
+	return configured
+}
+
+// revalidate drops the overrides that no longer apply to cfg: those of rules cfg does not have,
+// and those enabling a rule that is not valid in cfg.
+func (o *ruleOverrides) revalidate(ctx context.Context, cfg *cleanupconfig.CleanupConfig) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	for key, enabled := range o.enabled {
+		rule, ok := findConfiguredRule(cfg, key)
+		if !ok {
+			delete(o.enabled, key)
+			log.FromContext(ctx).Info("Dropping runtime override of a rule removed from the config", "rule", key.name, "kind", key.kind)
+			continue
+		}
+		if enabled {
+			if err := rule.validateEnabled(); err != nil {
+				delete(o.enabled, key)
+				log.FromContext(ctx).Error(err, "Dropping runtime override enabling a rule that is no longer valid", "rule", key.name, "kind", key.kind)
+			}
+		}
+	}
+}
+
+// SetRuleEnabled overrides whether the named rule of kind runs and returns the rule's kind and
+// previous effective state. An empty kind looks the name up across all kinds. Rules disabled in
+// the config are not validated on load, so enabling one validates it first.
+func (c *PodCleanController) SetRuleEnabled(kind, name string, enabled bool) (string, bool, error) {
+	key, rule, err := resolveRule(c.CleanupConfig, kind, name)
+	if err != nil {
+		return "", false, err
+	}
+
+	if enabled {
+		if err := rule.validateEnabled(); err != nil {
+			return "", false, fmt.Errorf("rule %q cannot be enabled: %w", name, err)
+		}
+	}
+
+	c.overrides.mu.Lock()
+	defer c.overrides.mu.Unlock()
+
+	previous, overridden := c.overrides.enabled[key]
+	if !overridden {
+		previous = rule.enabled
+	}
+	c.overrides.enabled[key] = enabled
+
+	return key.kind, previous, nil
+}
+
+// ClearRuleOverride drops the runtime override for the named rule of kind so its configured state
+// applies again, and returns the rule's kind and configured state. An empty kind looks the name up
+// across all kinds.
+func (c *PodCleanController) ClearRuleOverride(kind, name string) (string, bool, error) {
+	key, rule, err := resolveRule(c.CleanupConfig, kind, name)
+	if err != nil {
+		return "", false, err
+	}
+
+	c.overrides.mu.Lock()
+	defer c.overrides.mu.Unlock()
+	delete(c.overrides.enabled, key)
+
+	return key.kind, rule.enabled, nil
+}
+
+// resolveRule finds the named rule of kind in cfg. With an empty kind, the name must be unique
+// across kinds.
+func resolveRule(cfg *cleanupconfig.CleanupConfig, kind, name string) (ruleKey, configuredRule, error) {
+	kinds := []string{kind}
+	if kind == "" {
+		kinds = []string{RuleKindPod, RuleKindCertManager, RuleKindOrphan, RuleKindIdleWorkload, RuleKindStaleCronJob, RuleKindGeneric}
+	}
+
+	var found []ruleKey
+	var rule configuredRule
+	for _, kind := range kinds {
+		key := ruleKey{kind: kind, name: name}
+		if r, ok := findConfiguredRule(cfg, key); ok {
+			found = append(found, key)
+			rule = r
+		}
+	}
+
+	switch len(found) {
+	case 0:
+		return ruleKey{}, configuredRule{}, ErrRuleNotFound
+	case 1:
+		return found[0], rule, nil
+	default:
+		return ruleKey{}, configuredRule{}, ErrAmbiguousRule
+	}
+}
+
+// configuredRule is a rule of any kind as found in the config.
+type configuredRule struct {
+	enabled         bool
+	validateEnabled func() error // Validates the rule as if it were enabled.
+}
+
+// findConfiguredRule looks the rule of key up in cfg.
+func findConfiguredRule(cfg *cleanupconfig.CleanupConfig, key ruleKey) (configuredRule, bool) {
+	switch key.kind {
+	case RuleKindPod:
+		for _, rule := range cfg.PodCleanupConfig.EffectiveRules() {
+			if rule.Name == key.name {
+				return configuredRule{enabled: rule.Enabled, validateEnabled: func() error {
+					rule.Enabled = true
+					return rule.Validate()
+				}}, true
+			}
+		}
+	case RuleKindCertManager:
+		for _, rule := range cfg.CertManagerCleanupConfig.Rules {
+			if rule.Name == key.name {
+				return configuredRule{enabled: rule.Enabled, validateEnabled: func() error {
+					rule.Enabled = true
+					return rule.Validate()
+				}}, true
+			}
+		}
+	case RuleKindOrphan:
+		for _, rule := range cfg.OrphanCleanupConfig.Rules {
+			if rule.Name == key.name {
+				return configuredRule{enabled: rule.Enabled, validateEnabled: func() error {
+					rule.Enabled = true
+					return rule.Validate()
+				}}, true
+			}
+		}
+	case RuleKindIdleWorkload:
+		for _, rule := range cfg.IdleWorkloadConfig.Rules {
+			if rule.Name == key.name {
+				return configuredRule{enabled: rule.Enabled, validateEnabled: func() error {
+					rule.Enabled = true
+					return rule.Validate()
+				}}, true
+			}
+		}
+	case RuleKindStaleCronJob:
+		for _, rule := range cfg.StaleCronJobConfig.Rules {
+			if rule.Name == key.name {
+				return configuredRule{enabled: rule.Enabled, validateEnabled: func() error {
+					rule.Enabled = true
+					return rule.Validate()
+				}}, true
+			}
+		}
+	case RuleKindGeneric:
+		for _, rule := range cfg.GenericCleanupConfig.Rules {
+			if rule.Name == key.name {
+				return configuredRule{enabled: rule.Enabled, validateEnabled: func() error {
+					rule.Enabled = true
+					return rule.Validate()
+				}}, true
+			}
+		}
+	}
+	return configuredRule{}, false
+}
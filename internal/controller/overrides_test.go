@@ -0,0 +1,55 @@
+package controller
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	cleanupconfig "github.com/infrautils/kubeclean/internal/cleanup_config"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestRuleOverrides_KeyedByKindAndRevalidatedOnReload(t *testing.T) {
+	scheme := runtime.NewScheme()
+	_ = corev1.AddToScheme(scheme)
+
+	podRule := func(name string, ttl time.Duration) cleanupconfig.PodCleanRule {
+		return cleanupconfig.PodCleanRule{Name: name, Phase: "Failed", TTL: cleanupconfig.Duration{Duration: ttl}}
+	}
+	newConfig := func(rules ...cleanupconfig.PodCleanRule) *cleanupconfig.CleanupConfig {
+		return &cleanupconfig.CleanupConfig{
+			PodCleanupConfig:    cleanupconfig.PodCleanupConfig{Enabled: true, Rules: rules},
+			OrphanCleanupConfig: cleanupconfig.OrphanCleanupConfig{Enabled: true, Rules: []cleanupconfig.OrphanCleanRule{{Name: "stale", Kind: cleanupconfig.OrphanKindPodDisruptionBudget}}},
+		}
+	}
+	controller := NewPodCleanController(fake.NewClientBuilder().WithScheme(scheme).Build(), scheme,
+		newConfig(podRule("stale", time.Hour), podRule("failed", time.Hour), podRule("removed", time.Hour)))
+
+	if _, _, err := controller.SetRuleEnabled("", "stale", true); !errors.Is(err, ErrAmbiguousRule) {
+		t.Errorf("Expected a name shared by two kinds to be ambiguous, got %v", err)
+	}
+	for _, name := range []string{"stale", "failed", "removed"} {
+		if _, _, err := controller.SetRuleEnabled(RuleKindPod, name, true); err != nil {
+			t.Fatalf("Failed to enable %s: %v", name, err)
+		}
+	}
+	if controller.overrides.isEnabled(RuleKindOrphan, "stale", false) {
+		t.Error("Expected enabling the pod rule to leave the orphan rule of the same name alone")
+	}
+
+	// The reload makes "failed" invalid and removes "removed".
+	controller.AllowReload(context.Background(), newConfig(podRule("stale", time.Hour), podRule("failed", 0)))
+
+	if !controller.overrides.isEnabled(RuleKindPod, "stale", false) {
+		t.Error("Expected the override of a rule still valid to survive the reload")
+	}
+	if controller.overrides.isEnabled(RuleKindPod, "failed", false) {
+		t.Error("Expected the override enabling a rule that is no longer valid to be dropped")
+	}
+	if _, ok := controller.overrides.enabled[ruleKey{kind: RuleKindPod, name: "removed"}]; ok {
+		t.Error("Expected the override of a removed rule to be dropped")
+	}
+}
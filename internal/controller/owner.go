@@ -0,0 +1,152 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	appsv1 "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// Owner identifies the top-level controller of a pod, such as the CronJob behind a Job's pods.
+// The zero Owner stands for pods without a controller.
+type Owner struct {
+	Kind      string
+	Namespace string
+	Name      string
+}
+
+func (o Owner) String() string {
+	if o.Kind == "" {
+		return "none"
+	}
+	return fmt.Sprintf("%s %s/%s", o.Kind, o.Namespace, o.Name)
+}
+
+// ownerResolver walks controller references up to the top-level owner, caching intermediate
+// Jobs and ReplicaSets for the duration of a run.
+type ownerResolver struct {
+	client  client.Client
+	parents map[Owner]Owner
+}
+
+func newOwnerResolver(k8sClient client.Client) *ownerResolver {
+	return &ownerResolver{client: k8sClient, parents: map[Owner]Owner{}}
+}
+
+// resolve returns the top-level owner of pod. Jobs are resolved to their CronJob and
+// ReplicaSets to their Deployment; when an intermediate owner cannot be read, it is returned as is.
+func (r *ownerResolver) resolve(ctx context.Context, pod *corev1.Pod) Owner {
+	ref := metav1.GetControllerOf(pod)
+	if ref == nil {
+		return Owner{}
+	}
+
+	owner := Owner{Kind: ref.Kind, Namespace: pod.Namespace, Name: ref.Name}
+
+	var intermediate client.Object
+	switch ref.Kind {
+	case "Job":
+		intermediate = &batchv1.Job{}
+	case "ReplicaSet":
+		intermediate = &appsv1.ReplicaSet{}
+	default:
+		return owner
+	}
+
+	if parent, ok := r.parents[owner]; ok {
+		return parent
+	}
+
+	parent := owner
+	if err := r.client.Get(ctx, types.NamespacedName{Namespace: owner.Namespace, Name: owner.Name}, intermediate); err == nil {
+		if parentRef := metav1.GetControllerOf(intermediate); parentRef != nil {
+			parent = Owner{Kind: parentRef.Kind, Namespace: owner.Namespace, Name: parentRef.Name}
+		}
+	}
+
+	r.parents[owner] = parent
+	return parent
+}
+
+// ownerGroup is the direct controller of pods along with its top-level owner.
+type ownerGroup struct {
+	Direct Owner // Controller of the pods, such as a Job; zero for pods without one.
+	Top    Owner // Top-level owner of Direct, such as the Job's CronJob; Direct when it has none.
+}
+
+// groupByOwner counts pods per direct controller.
+func (r *ownerResolver) groupByOwner(ctx context.Context, pods []corev1.Pod) map[ownerGroup]int {
+	groups := map[ownerGroup]int{}
+	for i := range pods {
+		pod := &pods[i]
+		var direct Owner
+		if ref := metav1.GetControllerOf(pod); ref != nil {
+			direct = Owner{Kind: ref.Kind, Namespace: pod.Namespace, Name: ref.Name}
+		}
+		groups[ownerGroup{Direct: direct, Top: r.resolve(ctx, pod)}]++
+	}
+	return groups
+}
+
+// describeOwners summarizes owner groups by the pods' direct controllers, naming the top-level
+// owner they share, e.g. "across 12 Job(s) (CronJob default/nightly) and 3 standalone pod(s)".
+func describeOwners(groups map[ownerGroup]int) string {
+	type kindOf struct {
+		kind string
+		top  Owner // Zero for controllers without an owner of their own.
+	}
+	controllers := map[kindOf]map[Owner]struct{}{}
+	standalone := 0
+	for group, pods := range groups {
+		if group.Direct.Kind == "" {
+			standalone += pods
+			continue
+		}
+		key := kindOf{kind: group.Direct.Kind}
+		if group.Top != group.Direct {
+			key.top = group.Top
+		}
+		if controllers[key] == nil {
+			controllers[key] = map[Owner]struct{}{}
+		}
+		controllers[key][group.Direct] = struct{}{}
+	}
+
+	var parts []string
+	for key, owners := range controllers {
+		part := fmt.Sprintf("%d %s(s)", len(owners), key.kind)
+		if key.top.Kind != "" {
+			part += " (" + key.top.String() + ")"
+		}
+		parts = append(parts, part)
+	}
+	sort.Strings(parts)
+	if standalone > 0 {
+		parts = append(parts, fmt.Sprintf("%d standalone pod(s)", standalone))
+	}
+
+	switch len(parts) {
+	case 0:
+		return ""
+	case 1:
+		return "across " + parts[0]
+	default:
+		return "across " + strings.Join(parts[:len(parts)-1], ", ") + " and " + parts[len(parts)-1]
+	}
+}
+
+// ownerCounts counts the pods of owner groups per top-level owner, keyed by its string form.
+func ownerCounts(groups map[ownerGroup]int) map[string]int {
+	counts := make(map[string]int, len(groups))
+	for group, pods := range groups {
+		counts[group.Top.String()] += pods
+	}
+	return counts
+}
@@ -0,0 +1,113 @@
+package controller
+
+import (
+	"context"
+
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+// deleteEmptyOwners deletes, with foreground propagation, every Job whose pods are all in pods,
+// so no empty Job object is left behind. It returns the pods that still need to be deleted
+// individually; pods of deleted Jobs are removed by the garbage collector.
+func deleteEmptyOwners(ctx context.Context, k8sClient client.Client, pods []corev1.Pod, dryRun bool) []corev1.Pod {
+	logger := log.FromContext(ctx)
+
+	selected := map[types.NamespacedName]bool{}
+	jobs := map[types.NamespacedName]bool{}
+	for i := range pods {
+		pod := &pods[i]
+		selected[client.ObjectKeyFromObject(pod)] = true
+		if ref := metav1.GetControllerOf(pod); ref != nil && ref.Kind == "Job" {
+			jobs[types.NamespacedName{Namespace: pod.Namespace, Name: ref.Name}] = true
+		}
+	}
+
+	deleted := map[types.NamespacedName]bool{}
+	for key := range jobs {
+		complete, err := allJobPodsSelected(ctx, k8sClient, key, selected)
+		if err != nil {
+			logger.Error(err, "Failed to check pods of owner Job; deleting its pods individually", "job", key.Name, "namespace", key.Namespace)
+			continue
+		}
+		if !complete {
+			continue
+		}
+
+		if dryRun {
+			logger.Info("DRY RUN: Would delete owner Job", "job", key.Name, "namespace", key.Namespace)
+			deleted[key] = true
+			continue
+		}
+
+		job := &batchv1.Job{ObjectMeta: metav1.ObjectMeta{Namespace: key.Namespace, Name: key.Name}}
+		logger.Info("Deleting owner Job", "job", key.Name, "namespace", key.Namespace)
+		if err := withThrottleRetry(ctx, "delete", func() error {
+			return client.IgnoreNotFound(k8sClient.Delete(ctx, job, client.PropagationPolicy(metav1.DeletePropagationForeground)))
+		}); err != nil {
+			logger.Error(err, "Failed to delete owner Job; deleting its pods individually", "job", key.Name, "namespace", key.Namespace)
+			continue
+		}
+		deleted[key] = true
+	}
+
+	if len(deleted) == 0 {
+		return pods
+	}
+
+	remaining := make([]corev1.Pod, 0, len(pods))
+	for _, pod := range pods {
+		if ref := metav1.GetControllerOf(&pod); ref != nil && ref.Kind == "Job" &&
+			deleted[types.NamespacedName{Namespace: pod.Namespace, Name: ref.Name}] {
+			continue
+		}
+		remaining = append(remaining, pod)
+	}
+	return remaining
+}
+
+// allJobPodsSelected reports whether the Job has finished and every pod it controls is in
+// selected. A Job without a Complete or Failed condition may still create pods, for example to
+// retry a failed one, so it is never reported.
+func allJobPodsSelected(ctx context.Context, k8sClient client.Client, key types.NamespacedName, selected map[types.NamespacedName]bool) (bool, error) {
+	var job batchv1.Job
+	if err := withThrottleRetry(ctx, "get", func() error {
+		return k8sClient.Get(ctx, key, &job)
+	}); err != nil {
+		return false, client.IgnoreNotFound(err)
+	}
+
+	if job.Spec.Selector == nil || !(jobConditionTrue(&job, batchv1.JobComplete) || jobConditionTrue(&job, batchv1.JobFailed)) {
+		return false, nil
+	}
+
+	selector, err := metav1.LabelSelectorAsSelector(job.Spec.Selector)
+	if err != nil {
+		return false, err
+	}
+
+	var podList corev1.PodList
+	if err := withThrottleRetry(ctx, "list", func() error {
+		return k8sClient.List(ctx, &podList, &client.ListOptions{Namespace: key.Namespace, LabelSelector: selector})
+	}); err != nil {
+		return false, newListError("pods", key.Namespace, err)
+	}
+
+	owned := 0
+	for i := range podList.Items {
+		pod := &podList.Items[i]
+		if ref := metav1.GetControllerOf(pod); ref == nil || ref.UID != job.UID {
+			continue
+		}
+		if !selected[client.ObjectKeyFromObject(pod)] {
+			return false, nil
+		}
+		owned++
+	}
+
+	return owned > 0, nil
+}
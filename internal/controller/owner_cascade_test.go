@@ -0,0 +1,100 @@
+package controller
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	cleanupconfig "github.com/infrautils/kubeclean/internal/cleanup_config"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestPodCleanupController_DeleteOwnerWhenEmpty(t *testing.T) {
+	scheme := runtime.NewScheme()
+	_ = corev1.AddToScheme(scheme)
+	_ = batchv1.AddToScheme(scheme)
+
+	isController := true
+	newJob := func(name string, finished bool) *batchv1.Job {
+		job := &batchv1.Job{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default", UID: types.UID(name + "-uid")},
+			Spec:       batchv1.JobSpec{Selector: &metav1.LabelSelector{MatchLabels: map[string]string{"job-name": name}}},
+		}
+		if finished {
+			job.Status.Conditions = []batchv1.JobCondition{{Type: batchv1.JobComplete, Status: corev1.ConditionTrue}}
+		}
+		return job
+	}
+	newJobPod := func(name string, job *batchv1.Job, phase corev1.PodPhase) *corev1.Pod {
+		return &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:              name,
+				Namespace:         "default",
+				Labels:            map[string]string{"job-name": job.Name},
+				CreationTimestamp: metav1.NewTime(time.Now().Add(-2 * time.Hour)),
+				OwnerReferences:   []metav1.OwnerReference{{Kind: "Job", Name: job.Name, UID: job.UID, Controller: &isController}},
+			},
+			Status: corev1.PodStatus{Phase: phase},
+		}
+	}
+
+	done := newJob("done", true)
+	partial := newJob("partial", true)
+	// Its pod succeeded, but the Job has not reported that it finished.
+	retrying := newJob("retrying", false)
+
+	k8sClient := fake.NewClientBuilder().WithScheme(scheme).WithObjects(
+		done, partial, retrying,
+		newJobPod("retrying-a", retrying, corev1.PodSucceeded),
+		newJobPod("done-a", done, corev1.PodSucceeded),
+		newJobPod("done-b", done, corev1.PodSucceeded),
+		newJobPod("partial-a", partial, corev1.PodSucceeded),
+		newJobPod("partial-b", partial, corev1.PodRunning),
+	).Build()
+
+	cleanupCfg := &cleanupconfig.CleanupConfig{
+		BatchSize: 10,
+		PodCleanupConfig: cleanupconfig.PodCleanupConfig{
+			Enabled: true,
+			Rules: []cleanupconfig.PodCleanRule{{
+				Name:                 "jobs",
+				Enabled:              true,
+				Phase:                string(corev1.PodSucceeded),
+				TTL:                  cleanupconfig.Duration{Duration: time.Hour},
+				DeleteOwnerWhenEmpty: true,
+			}},
+		},
+	}
+
+	NewPodCleanController(k8sClient, scheme, cleanupCfg).RunCleanUp(context.Background())
+
+	err := k8sClient.Get(context.Background(), types.NamespacedName{Namespace: "default", Name: "done"}, &batchv1.Job{})
+	if !apierrors.IsNotFound(err) {
+		t.Errorf("Expected Job done to be deleted, got %v", err)
+	}
+
+	if err := k8sClient.Get(context.Background(), types.NamespacedName{Namespace: "default", Name: "partial"}, &batchv1.Job{}); err != nil {
+		t.Errorf("Expected Job partial to be kept, got %v", err)
+	}
+
+	if err := k8sClient.Get(context.Background(), types.NamespacedName{Namespace: "default", Name: "retrying"}, &batchv1.Job{}); err != nil {
+		t.Errorf("Expected unfinished Job retrying to be kept, got %v", err)
+	}
+
+	remaining := remainingPodNames(t, k8sClient)
+	if remaining["retrying-a"] {
+		t.Errorf("Expected retrying-a to be deleted individually, remaining: %v", remaining)
+	}
+	if remaining["partial-a"] {
+		t.Errorf("Expected partial-a to be deleted individually, remaining: %v", remaining)
+	}
+	if !remaining["partial-b"] {
+		t.Errorf("Expected running partial-b to be kept, remaining: %v", remaining)
+	}
+}
@@ -0,0 +1,71 @@
+package controller
+
+import (
+	"context"
+	"testing"
+
+	appsv1 "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func controllerRef(kind, name string) []metav1.OwnerReference {
+	isController := true
+	return []metav1.OwnerReference{{Kind: kind, Name: name, Controller: &isController}}
+}
+
+func TestOwnerResolver_GroupByOwner(t *testing.T) {
+	scheme := runtime.NewScheme()
+	_ = corev1.AddToScheme(scheme)
+	_ = appsv1.AddToScheme(scheme)
+	_ = batchv1.AddToScheme(scheme)
+
+	k8sClient := fake.NewClientBuilder().WithScheme(scheme).WithObjects(
+		&batchv1.Job{ObjectMeta: metav1.ObjectMeta{Name: "nightly-1", Namespace: "default", OwnerReferences: controllerRef("CronJob", "nightly")}},
+		&batchv1.Job{ObjectMeta: metav1.ObjectMeta{Name: "nightly-2", Namespace: "default", OwnerReferences: controllerRef("CronJob", "nightly")}},
+		&batchv1.Job{ObjectMeta: metav1.ObjectMeta{Name: "migrate", Namespace: "default"}},
+		&appsv1.ReplicaSet{ObjectMeta: metav1.ObjectMeta{Name: "web-abc", Namespace: "default", OwnerReferences: controllerRef("Deployment", "web")}},
+	).Build()
+
+	pod := func(name string, owners []metav1.OwnerReference) corev1.Pod {
+		return corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default", OwnerReferences: owners}}
+	}
+
+	pods := []corev1.Pod{
+		pod("nightly-1-a", controllerRef("Job", "nightly-1")),
+		pod("nightly-1-b", controllerRef("Job", "nightly-1")),
+		pod("nightly-2-a", controllerRef("Job", "nightly-2")),
+		pod("migrate-a", controllerRef("Job", "migrate")),
+		pod("web-abc-x", controllerRef("ReplicaSet", "web-abc")),
+		pod("gone-a", controllerRef("Job", "gone")), // Job already deleted; grouped under the Job itself.
+		pod("bare", nil),
+	}
+
+	groups := newOwnerResolver(k8sClient).groupByOwner(context.Background(), pods)
+
+	expected := map[string]int{
+		"CronJob default/nightly": 3,
+		"Job default/migrate":     1,
+		"Deployment default/web":  1,
+		"Job default/gone":        1,
+		"none":                    1,
+	}
+
+	counts := ownerCounts(groups)
+	if len(counts) != len(expected) {
+		t.Fatalf("Expected %d top-level owners, got %v", len(expected), counts)
+	}
+	for owner, count := range expected {
+		if counts[owner] != count {
+			t.Errorf("Expected %d pod(s) for %s, got %d", count, owner, counts[owner])
+		}
+	}
+
+	want := "across 1 ReplicaSet(s) (Deployment default/web), 2 Job(s), 2 Job(s) (CronJob default/nightly) and 1 standalone pod(s)"
+	if got := describeOwners(groups); got != want {
+		t.Errorf("Expected description %q, got %q", want, got)
+	}
+}
@@ -2,13 +2,28 @@ package controller
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"maps"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
 	"time"
 
 	cleanupconfig "github.com/infrautils/kubeclean/internal/cleanup_config"
+	"github.com/infrautils/kubeclean/internal/ledger"
+	"github.com/infrautils/kubeclean/internal/logship"
+	"github.com/infrautils/kubeclean/internal/notify"
 	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/uuid"
+	"k8s.io/client-go/discovery"
+	corev1client "k8s.io/client-go/kubernetes/typed/core/v1"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/log"
 )
@@ -18,6 +33,36 @@ type PodCleanController struct {
 	Scheme        *runtime.Scheme
 	CleanupConfig *cleanupconfig.CleanupConfig
 	PodMatcher    *PodMatcher
+	Logs          corev1client.PodsGetter            // Reads pod logs for log forwarding; forwarding is skipped when nil.
+	APIReader     client.Reader                      // Uncached reader for state loaded before the cache starts; Client is used when nil.
+	ShadowConfig  *cleanupconfig.CleanupConfig       // Pod rules compared with the active config on full passes without acting; skipped when nil.
+	APIHealth     *APIHealth                         // Health of the API server as seen by Client, for the circuit breaker; never opens when nil.
+	Discovery     discovery.ServerResourcesInterface // Finds the kinds the API server serves, to skip rules for others; every kind is assumed served when nil.
+	Now           func() time.Time                   // Time Preview, Simulate and impact estimates evaluate rules against; the current time when nil.
+
+	orphans    *orphanTracker
+	idle       *orphanTracker
+	cronJobs   *cronJobTracker
+	overrides  *ruleOverrides
+	warmup     warmupCounter
+	lanes      laneScheduler
+	secrets    secretVersions
+	reload     reloadGate
+	ledger     ledger.Ledger
+	statuses   *ruleStatuses
+	candidates *candidateTracker
+	anomalies  *anomalyDetector
+
+	namespaceNotifier *namespaceNotifier
+	retries           *retryQueue
+	progress          *progressHub
+	history           recent[RunSummary]
+	deletions         recent[Deletion]
+
+	// runMu serializes cleanup passes, whether periodic or triggered.
+	runMu sync.Mutex
+	// lastStarted is when the last full pass started, as persisted by lastRun. Guarded by runMu.
+	lastStarted time.Time
 }
 
 func NewPodCleanController(k8sClient client.Client, scheme *runtime.Scheme, cleanupConfig *cleanupconfig.CleanupConfig) *PodCleanController {
@@ -26,115 +71,1495 @@ func NewPodCleanController(k8sClient client.Client, scheme *runtime.Scheme, clea
 		Scheme:        scheme,
 		CleanupConfig: cleanupConfig,
 		PodMatcher:    NewPodMatcher(k8sClient),
+		orphans:       newOrphanTracker(),
+		idle:          newOrphanTracker(),
+		cronJobs:      newCronJobTracker(),
+		overrides:     newRuleOverrides(),
+		statuses:      newRuleStatuses(),
+		candidates:    newCandidateTracker(),
+		anomalies:     newAnomalyDetector(),
+
+		namespaceNotifier: newNamespaceNotifier(),
+		retries:           newRetryQueue(),
+		progress:          newProgressHub(),
+		history:           recent[RunSummary]{size: historySize},
+		deletions:         recent[Deletion]{size: deletionsSize},
 	}
 }
 
 type PodMatcher struct {
 	client client.Client
+
+	// now is the time rules are evaluated against; the current time when nil.
+	now func() time.Time
+
+	// nodes caches the cluster's nodes for the duration of a run; nil until first needed.
+	nodes map[string]*corev1.Node
+
+	// missingNodes records when each deleted node a nodeDeleted rule looked for was first found
+	// missing. Unlike the caches, it is kept across runs.
+	missingNodes map[string]time.Time
+
+	// namespaces caches the cluster's namespaces for the duration of a run; nil until first needed.
+	// When listing them failed, namespacesListed is false and namespaces are read one at a time,
+	// caching failed reads in namespaceErrs.
+	namespaces       map[string]*corev1.Namespace
+	namespacesListed bool
+	namespaceErrs    map[string]error
+
+	// disabledOwners caches whether a workload or one of its controllers opted out via the
+	// kubeclean/disabled annotation, for the duration of a run.
+	disabledOwners map[Owner]bool
+
+	// rollingOutOwners caches whether a Deployment or StatefulSet has a rollout in flight, for the
+	// duration of a run.
+	rollingOutOwners map[Owner]bool
+
+	// owners, readyCounts and readyRemoved back the minAvailable guard for the duration of a run:
+	// the owners' ready replicas and the ready pods already selected from each.
+	owners       *ownerResolver
+	readyCounts  map[Owner]int
+	readyRemoved map[Owner]int
+
+	// pressuredNamespaces caches the namespaces under ResourceQuota pressure for the duration of
+	// a run; nil until first needed.
+	pressuredNamespaces map[string]bool
+
+	// trimmed records the pods rules selected through maxPerNode rather than their TTL, for the
+	// duration of a run.
+	trimmed map[trimmedPod]struct{}
+
+	// scope restricts every rule to the namespace or node of a targeted pass. Unlike the caches,
+	// it is set for each pass rather than reset.
+	scope runScope
 }
 
 func NewPodMatcher(k8sClient client.Client) *PodMatcher {
 	return &PodMatcher{client: k8sClient}
 }
 
-func (c *PodCleanController) RunCleanUp(ctx context.Context) {
-	if !c.CleanupConfig.PodCleanupConfig.Enabled {
-		return
+// newMatcher returns a matcher of its own for an evaluation outside cleanup runs, evaluating rules
+// against c.Now.
+func (c *PodCleanController) newMatcher() *PodMatcher {
+	matcher := NewPodMatcher(c.Client)
+	matcher.now = c.Now
+	return matcher
+}
+
+// clock returns the time rules are evaluated against.
+func (pm *PodMatcher) clock() time.Time {
+	if pm.now != nil {
+		return pm.now()
+	}
+	return time.Now()
+}
+
+// ResetCache drops cached cluster state so the next run observes fresh data.
+func (pm *PodMatcher) ResetCache() {
+	pm.nodes = nil
+	pm.namespaces = nil
+	pm.namespaceErrs = nil
+	pm.disabledOwners = nil
+	pm.rollingOutOwners = nil
+	pm.owners = nil
+	pm.readyCounts = nil
+	pm.readyRemoved = nil
+	pm.pressuredNamespaces = nil
+	pm.trimmed = nil
+}
+
+// getNode returns the named node, listing all nodes once per run to populate the cache.
+func (pm *PodMatcher) getNode(ctx context.Context, name string) (*corev1.Node, error) {
+	if pm.nodes == nil {
+		var nodeList corev1.NodeList
+		if err := withThrottleRetry(ctx, "list", func() error {
+			return pm.client.List(ctx, &nodeList)
+		}); err != nil {
+			return nil, newListError("nodes", "", err)
+		}
+
+		pm.nodes = make(map[string]*corev1.Node, len(nodeList.Items))
+		for i := range nodeList.Items {
+			pm.nodes[nodeList.Items[i].Name] = &nodeList.Items[i]
+		}
+	}
+
+	return pm.nodes[name], nil
+}
+
+// matchesNode reports whether the pod is scheduled on a node selected by the rule.
+func (pm *PodMatcher) matchesNode(ctx context.Context, pod *corev1.Pod, rule cleanupconfig.PodCleanRule) (bool, error) {
+	mode := rule.CordonedNodes
+	checksCordon := mode == cleanupconfig.CordonedNodesSkip || mode == cleanupconfig.CordonedNodesOnly
+
+	if !rule.IsNodeScoped() && !checksCordon {
+		return true, nil
+	}
+
+	if pod.Spec.NodeName == "" {
+		return !rule.IsNodeScoped() && mode != cleanupconfig.CordonedNodesOnly, nil
+	}
+
+	if len(rule.NodeNames) > 0 && !slices.Contains(rule.NodeNames, pod.Spec.NodeName) {
+		return false, nil
+	}
+
+	if rule.NodeDeleted {
+		return pm.isNodeDeleted(ctx, pod.Spec.NodeName)
+	}
+
+	checksLabels := rule.NodeSelector != nil || len(rule.Zones) > 0 || len(rule.Regions) > 0
+	if !checksLabels && !checksCordon {
+		return true, nil
+	}
+
+	node, err := pm.getNode(ctx, pod.Spec.NodeName)
+	if err != nil {
+		return false, err
+	}
+
+	if rule.NodeSelector != nil {
+		selector, err := metav1.LabelSelectorAsSelector(rule.NodeSelector)
+		if err != nil {
+			return false, fmt.Errorf("invalid node selector: %w", err)
+		}
+
+		if node == nil || !selector.Matches(labels.Set(node.Labels)) {
+			return false, nil
+		}
+	}
+
+	if !matchesTopology(node, corev1.LabelTopologyZone, rule.Zones) ||
+		!matchesTopology(node, corev1.LabelTopologyRegion, rule.Regions) {
+		return false, nil
+	}
+
+	cordoned := node != nil && isNodeCordoned(node)
+	switch mode {
+	case cleanupconfig.CordonedNodesSkip:
+		return !cordoned, nil
+	case cleanupconfig.CordonedNodesOnly:
+		return cordoned, nil
+	default:
+		return true, nil
+	}
+}
+
+// isNodeDeleted reports whether the named node no longer exists. A cache miss is confirmed with
+// a direct read so a node created after the cache was filled is not mistaken for a deleted one.
+// Nodes found missing are remembered across runs, so nodeMissingSince can tell how long ago.
+func (pm *PodMatcher) isNodeDeleted(ctx context.Context, name string) (bool, error) {
+	node, err := pm.getNode(ctx, name)
+	if err != nil || node != nil {
+		delete(pm.missingNodes, name)
+		return false, err
+	}
+
+	node = &corev1.Node{}
+	err = withThrottleRetry(ctx, "get", func() error {
+		return pm.client.Get(ctx, types.NamespacedName{Name: name}, node)
+	})
+	switch {
+	case err == nil:
+		pm.nodes[name] = node
+		delete(pm.missingNodes, name)
+		return false, nil
+	case apierrors.IsNotFound(err):
+		if _, ok := pm.missingNodes[name]; !ok {
+			if pm.missingNodes == nil {
+				pm.missingNodes = map[string]time.Time{}
+			}
+			pm.missingNodes[name] = pm.clock()
+		}
+		return true, nil
+	default:
+		return false, err
+	}
+}
+
+// podReasonNodeLost is the status reason the node controller gives pods of an unreachable node.
+const podReasonNodeLost = "NodeLost"
+
+// nodeMissingSince returns when the node of pod, known to be deleted, was lost. The pod tells
+// when the node controller or the pod garbage collector gave up on the node: its DisruptionTarget
+// condition, or its Ready condition once the pod is marked NodeLost. Otherwise it is when the
+// matcher first found the node missing, which a restart starts over.
+func (pm *PodMatcher) nodeMissingSince(pod *corev1.Pod) time.Time {
+	for _, condition := range pod.Status.Conditions {
+		switch {
+		case condition.Type == corev1.DisruptionTarget && condition.Status == corev1.ConditionTrue,
+			condition.Type == corev1.PodReady && condition.Status == corev1.ConditionFalse && pod.Status.Reason == podReasonNodeLost:
+			if !condition.LastTransitionTime.IsZero() {
+				return condition.LastTransitionTime.Time
+			}
+		}
+	}
+	if since, ok := pm.missingNodes[pod.Spec.NodeName]; ok {
+		return since
+	}
+	return pm.clock()
+}
+
+// getNamespace returns the named namespace, listing all namespaces once per run to populate the
+// cache, or reading it alone when the list failed.
+func (pm *PodMatcher) getNamespace(ctx context.Context, name string) (*corev1.Namespace, error) {
+	if pm.namespaces == nil {
+		var namespaceList corev1.NamespaceList
+		err := withThrottleRetry(ctx, "list", func() error {
+			return pm.client.List(ctx, &namespaceList)
+		})
+		if err != nil {
+			log.FromContext(ctx).Error(newListError("namespaces", "", err), "Failed to list namespaces; reading them one at a time")
+		}
+
+		pm.namespaces = make(map[string]*corev1.Namespace, len(namespaceList.Items))
+		for i := range namespaceList.Items {
+			pm.namespaces[namespaceList.Items[i].Name] = &namespaceList.Items[i]
+		}
+		pm.namespacesListed = err == nil
+	}
+
+	if namespace, ok := pm.namespaces[name]; ok || pm.namespacesListed {
+		return namespace, nil
+	}
+	if err, ok := pm.namespaceErrs[name]; ok {
+		return nil, err
+	}
+
+	// Without the list, each namespace is read once per run, so a failure only affects its pods.
+	namespace := &corev1.Namespace{}
+	err := withThrottleRetry(ctx, "get", func() error {
+		return pm.client.Get(ctx, types.NamespacedName{Name: name}, namespace)
+	})
+	switch {
+	case apierrors.IsNotFound(err):
+		namespace = nil
+	case err != nil:
+		if pm.namespaceErrs == nil {
+			pm.namespaceErrs = map[string]error{}
+		}
+		pm.namespaceErrs[name] = fmt.Errorf("get namespace %s: %w", name, err)
+		return nil, pm.namespaceErrs[name]
+	}
+	pm.namespaces[name] = namespace
+	return namespace, nil
+}
+
+// isNamespaceTerminating reports whether the namespace has a deletionTimestamp.
+func (pm *PodMatcher) isNamespaceTerminating(ctx context.Context, name string) (bool, error) {
+	namespace, err := pm.getNamespace(ctx, name)
+	if err != nil || namespace == nil {
+		return false, err
+	}
+	return namespace.DeletionTimestamp != nil, nil
+}
+
+// matchesTopology reports whether the node's topology label is one of values; empty values match any node.
+func matchesTopology(node *corev1.Node, label string, values []string) bool {
+	if len(values) == 0 {
+		return true
+	}
+
+	return node != nil && slices.Contains(values, node.Labels[label])
+}
+
+// isNodeCordoned reports whether the node is unschedulable or tainted for removal by a drain.
+func isNodeCordoned(node *corev1.Node) bool {
+	if node.Spec.Unschedulable {
+		return true
+	}
+
+	for _, taint := range node.Spec.Taints {
+		if taint.Key == corev1.TaintNodeUnschedulable || taint.Key == "ToBeDeletedByClusterAutoscaler" {
+			return true
+		}
+	}
+
+	return false
+}
+
+// RunSummary captures the outcome of a single cleanup pass.
+type RunSummary struct {
+	RunID          string              `json:"runID"`
+	Namespace      string              `json:"namespace,omitempty"` // Namespace a targeted pass was restricted to.
+	Node           string              `json:"node,omitempty"`      // Node a targeted pass was restricted to.
+	Started        time.Time           `json:"started"`
+	Finished       time.Time           `json:"finished"`
+	Matched        int                 `json:"matched"`
+	MatchedByRule  map[string]int      `json:"matchedByRule"`
+	Deferred       int                 `json:"deferred"`
+	New            int                 `json:"new"` // Matched pods that were not candidates in the previous run.
+	NewByRule      map[string]int      `json:"newByRule"`
+	CarriedOver    int                 `json:"carriedOver"`    // Matched pods that were already candidates in the previous run.
+	DeleteFailures int                 `json:"deleteFailures"` // Deletions of any kind that failed.
+	DeleteErrors   map[ErrorReason]int `json:"deleteErrors"`   // Failed deletions by reason.
+	Retried        int                 `json:"retried"`        // Queued pods deleted on retry.
+	DeadLettered   int                 `json:"deadLettered"`   // Pods given up on after failed retries.
+	ListErrors     map[ErrorReason]int `json:"listErrors"`
+	Aborted        string              `json:"aborted,omitempty"` // Why the run was deferred or stopped early by the circuit breaker.
+	SlowLane       bool                `json:"slowLane"`          // Whether rules in the slow lane ran.
+
+	// DeleteErrorCodes and ListErrorCodes count DeleteErrors and ListErrors by reason code.
+	DeleteErrorCodes map[ReasonCode]int `json:"deleteErrorCodes,omitempty"`
+	ListErrorCodes   map[ReasonCode]int `json:"listErrorCodes,omitempty"`
+}
+
+// TopDeleteErrors formats the n most frequent reasons deletions failed for during the run.
+func (s RunSummary) TopDeleteErrors(n int) string {
+	return TopErrorReasons(s.DeleteErrors, n)
+}
+
+// rulePlan is the evaluated, not yet executed outcome of a single rule within a run.
+type rulePlan struct {
+	Rule       cleanupconfig.PodCleanRule
+	Selected   []corev1.Pod // Pods to act on in this run.
+	Deferred   []corev1.Pod // Matched pods held back by deletion budgets.
+	ListErrors map[ErrorReason]int
+	Err        error // Error finding the rule's pods, if any.
+}
+
+// runScope restricts a targeted pass to the pods of a namespace or to the completed pods of a
+// node. The zero value is a full pass.
+type runScope struct {
+	Namespace string
+	Node      string
+}
+
+// targeted reports whether the scope restricts the pass.
+func (s runScope) targeted() bool {
+	return s != runScope{}
+}
+
+// includesPod reports whether pod is within the scope. Namespaces are already restricted when
+// listing; a node scope only includes the node's completed pods.
+func (s runScope) includesPod(pod *corev1.Pod) bool {
+	if s.Node == "" {
+		return true
+	}
+	return pod.Spec.NodeName == s.Node && isPodCompleted(pod)
+}
+
+// cleanupRun carries the state shared by every cleanup step of a single pass.
+type cleanupRun struct {
+	ID       string
+	Scope    runScope // Restriction of a targeted pass; the zero value for a full pass.
+	DryRun   bool     // Effective dry-run for the pass: the config's setting or a warm-up run.
+	notifier *notify.Notifier
+	logs     logship.Backend // Log forwarding backend; nil when log forwarding is disabled.
+	deleted  deletionTally   // Pods deleted during the pass, for receipts.
+	summary  *RunSummary     // Summary of the pass so far, for notification templates; nil until known.
+
+	deleteFailures int                 // Deletions of any kind that failed during the pass.
+	deleteErrors   map[ErrorReason]int // Failed deletions by reason.
+	retried        int                 // Queued pods deleted on retry.
+	deadLettered   int                 // Pods given up on after failed retries.
+	aborted        string              // Why the pass stopped early; empty if it did not.
+	slowLane       bool                // Whether rules in the slow lane run in the pass.
+	cooledDown     map[string]bool     // Pod rules skipped in the pass because they are in cooldown.
+	served         *servedKinds        // Kinds the API server serves, as discovered during the pass.
+}
+
+// summarySoFar returns a copy of the summary of the pass so far for notification templates, or
+// nil before it is known.
+func (r *cleanupRun) summarySoFar() any {
+	if r.summary == nil {
+		return nil
+	}
+	summary := *r.summary
+	summary.RunID = r.ID
+	return summary
+}
+
+// recordDeleteFailures counts the failed deletions of the rule joined in err by reason and
+// returns their number.
+func (r *cleanupRun) recordDeleteFailures(rule string, err error) int {
+	if err == nil {
+		return 0
+	}
+
+	if r.deleteErrors == nil {
+		r.deleteErrors = map[ErrorReason]int{}
+	}
+
+	var failed int
+	for reason, count := range ErrorReasons(err) {
+		r.deleteErrors[reason] += count
+		deleteFailuresTotal.WithLabelValues(rule, string(reason)).Add(float64(count))
+		failed += count
+	}
+	r.deleteFailures += failed
+	return failed
+}
+
+// RunCleanUp runs a cleanup pass, waiting for a pass already in progress to finish first.
+func (c *PodCleanController) RunCleanUp(ctx context.Context) RunSummary {
+	c.runMu.Lock()
+	defer c.runMu.Unlock()
+
+	return c.runCleanUp(ctx, string(uuid.NewUUID()), runScope{})
+}
+
+// runScheduled runs the pass scheduled at tick. A tick that fired while the previous pass, periodic
+// or triggered, was still active either waits for it or is skipped, as the overlap policy says.
+func (c *PodCleanController) runScheduled(ctx context.Context, tick time.Time) {
+	if c.CleanupConfig.OverlapPolicy == cleanupconfig.OverlapPolicySkip {
+		if !c.runMu.TryLock() {
+			c.skipScheduledRun(ctx, tick)
+			return
+		}
+		if previous := c.history.latest(1); len(previous) > 0 && tick.Before(previous[0].Finished) {
+			c.runMu.Unlock()
+			c.skipScheduledRun(ctx, tick)
+			return
+		}
+	} else {
+		c.runMu.Lock()
+	}
+	defer c.runMu.Unlock()
+
+	runCtx, cancel := context.WithTimeout(ctx, runTimeout)
+	defer cancel()
+	c.runCleanUp(runCtx, string(uuid.NewUUID()), runScope{})
+}
+
+func (c *PodCleanController) skipScheduledRun(ctx context.Context, tick time.Time) {
+	skippedRunsTotal.Inc()
+	log.FromContext(ctx).Info("Skipping scheduled run; the previous run is still active", "scheduled", tick)
+}
+
+// TriggerRun starts a cleanup pass in the background and returns its run ID, so callers can
+// follow it with SubscribeProgress. It fails with ErrRunInProgress while another pass is running.
+// The pass outlives ctx, bounded by runTimeout.
+func (c *PodCleanController) TriggerRun(ctx context.Context) (string, error) {
+	return c.triggerRun(ctx, runScope{})
+}
+
+// TriggerNamespaceRun starts a pass of the pod rules restricted to namespace in the background
+// and returns its run ID. Like TriggerRun, it fails with ErrRunInProgress while another pass is
+// running. Other cleanup sections only run in full passes.
+func (c *PodCleanController) TriggerNamespaceRun(ctx context.Context, namespace string) (string, error) {
+	return c.triggerRun(ctx, runScope{Namespace: namespace})
+}
+
+// TriggerNodeRun starts a pass of the pod rules restricted to the completed pods on node in the
+// background and returns its run ID, like TriggerNamespaceRun.
+func (c *PodCleanController) TriggerNodeRun(ctx context.Context, node string) (string, error) {
+	return c.triggerRun(ctx, runScope{Node: node})
+}
+
+func (c *PodCleanController) triggerRun(ctx context.Context, scope runScope) (string, error) {
+	if !c.runMu.TryLock() {
+		return "", ErrRunInProgress
+	}
+
+	runID := string(uuid.NewUUID())
+	runCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), runTimeout)
+
+	go func() {
+		defer c.runMu.Unlock()
+		defer cancel()
+		c.runCleanUp(runCtx, runID, scope)
+	}()
+
+	return runID, nil
+}
+
+// runCleanUp runs a cleanup pass under runID. A targeted pass, restricted by scope, only runs the
+// pod rules. Callers hold runMu.
+func (c *PodCleanController) runCleanUp(ctx context.Context, runID string, scope runScope) RunSummary {
+	summary := summarize(nil)
+	summary.RunID = runID
+	summary.Namespace, summary.Node = scope.Namespace, scope.Node
+	summary.Started = time.Now()
+
+	if !c.CleanupConfig.PodCleanupConfig.Enabled &&
+		!c.CleanupConfig.CertManagerCleanupConfig.Enabled &&
+		!c.CleanupConfig.OrphanCleanupConfig.Enabled &&
+		!c.CleanupConfig.IdleWorkloadConfig.Enabled &&
+		!c.CleanupConfig.StaleCronJobConfig.Enabled &&
+		!c.CleanupConfig.GenericCleanupConfig.Enabled {
+		summary.Finished = time.Now()
+		c.history.record(summary)
+		c.progress.publish(ProgressEvent{RunID: runID, Done: true, Time: summary.Finished, Summary: &summary})
+		return summary
+	}
+
+	// Every log line of this pass carries the run ID so a deletion can be traced end-to-end.
+	logger := log.FromContext(ctx).WithValues("runID", runID)
+	if scope.Namespace != "" {
+		logger = logger.WithValues("namespace", scope.Namespace)
+	}
+	if scope.Node != "" {
+		logger = logger.WithValues("node", scope.Node)
+	}
+	ctx = log.IntoContext(ctx, logger)
+
+	if reason := c.apiUnhealthy(); reason != "" {
+		logger.Info("Deferring run; the API server circuit breaker is open", "reason", reason)
+		interruptedRunsTotal.WithLabelValues("deferred").Inc()
+		summary.Aborted = reason
+		summary.Finished = time.Now()
+		c.history.record(summary)
+		c.progress.publish(ProgressEvent{RunID: runID, Done: true, Time: summary.Finished, Summary: &summary})
+		return summary
+	}
+	ctx = withCircuitBreaker(ctx, c.apiUnhealthy)
+
+	run := &cleanupRun{ID: runID, Scope: scope, DryRun: c.CleanupConfig.DryRun, deleted: deletionTally{}, summary: &summary,
+		served: newServedKinds(c.Discovery)}
+
+	// Targeted passes do not count towards the warm-up, but are dry-runs while it lasts.
+	if scope.targeted() {
+		if c.warmup.active(c.CleanupConfig) {
+			logger.Info("Warm-up in progress; forcing dry-run", "warmupRuns", c.CleanupConfig.WarmupRuns)
+			run.DryRun = true
+		}
+	} else if warmupRun, warmingUp := c.warmup.next(c.CleanupConfig); warmingUp {
+		logger.Info("Warm-up run; forcing dry-run", "run", warmupRun, "warmupRuns", c.CleanupConfig.WarmupRuns)
+		run.DryRun = true
+	}
+
+	// Targeted passes run every lane; the slow lane is only scheduled across full passes.
+	run.slowLane = scope.targeted() || c.lanes.next(c.CleanupConfig)
+	if !run.slowLane {
+		logger.Info("Slow lane not due; running fast lane rules only", "slowEvery", c.CleanupConfig.Lanes.SlowLaneEvery())
+	}
+
+	notifier, err := notify.NewNotifier(c.notificationConfig(ctx), nil)
+	if err != nil {
+		logger.Error(err, "Failed to set up notifications")
+	}
+	run.notifier = notifier
+
+	if c.CleanupConfig.LogForwarding.Enabled {
+		if c.Logs == nil {
+			logger.Error(errors.New("no log client configured"), "Failed to set up log forwarding")
+		} else if forwarding, err := c.logForwardingConfig(ctx); err != nil {
+			logger.Error(err, "Failed to resolve the log forwarding URL")
+		} else if run.logs, err = logship.NewBackend(forwarding, nil); err != nil {
+			logger.Error(err, "Failed to set up log forwarding")
+		}
+	}
+
+	if c.CleanupConfig.PodCleanupConfig.Enabled {
+		started := summary.Started
+		summary = c.cleanUpPods(withProgress(ctx, c.progress, &c.deletions, run), run)
+		summary.RunID = runID
+		summary.Namespace, summary.Node = scope.Namespace, scope.Node
+		summary.Started = started
+		run.summary = &summary
+		c.writeReceipts(ctx, run, started)
+		c.notifyNamespaceOwners(ctx, run)
 	}
 
+	if scope.targeted() {
+		return c.finishRun(ctx, summary, run)
+	}
+
+	if c.CleanupConfig.CertManagerCleanupConfig.Enabled && !c.interrupted(ctx, run) {
+		c.cleanUpCertManager(ctx, run)
+	}
+
+	if c.CleanupConfig.OrphanCleanupConfig.Enabled && !c.interrupted(ctx, run) {
+		c.cleanUpOrphans(ctx, run)
+	}
+
+	if c.CleanupConfig.IdleWorkloadConfig.Enabled && !c.interrupted(ctx, run) {
+		c.cleanUpIdleWorkloads(ctx, run)
+	}
+
+	if c.CleanupConfig.StaleCronJobConfig.Enabled && !c.interrupted(ctx, run) {
+		c.suspendStaleCronJobs(ctx, run)
+	}
+
+	if c.CleanupConfig.GenericCleanupConfig.Enabled && !c.interrupted(ctx, run) {
+		c.cleanUpGenericResources(ctx, run)
+	}
+
+	return c.finishRun(ctx, summary, run)
+}
+
+// finishRun completes summary with the outcome of run, then records, publishes and reports it.
+func (c *PodCleanController) finishRun(ctx context.Context, summary RunSummary, run *cleanupRun) RunSummary {
+	summary.DeleteFailures = run.deleteFailures
+	maps.Copy(summary.DeleteErrors, run.deleteErrors)
+	summary.DeleteErrorCodes = ErrorCodes(summary.DeleteErrors)
+	summary.ListErrorCodes = ErrorCodes(summary.ListErrors)
+	summary.Retried = run.retried
+	summary.DeadLettered = run.deadLettered
+	summary.Aborted = run.aborted
+	summary.SlowLane = run.slowLane
+	summary.Finished = time.Now()
+
+	c.history.record(summary)
+	c.progress.publish(ProgressEvent{RunID: run.ID, DryRun: run.DryRun, Done: true, Time: summary.Finished, Summary: &summary})
+	c.reportRun(ctx, summary, run)
+	c.recordRun(ctx, summary, run)
+	if !run.Scope.targeted() {
+		c.lastStarted = summary.Started
+	}
+	c.saveLastRun(ctx)
+	return summary
+}
+
+// cleanUpPods plans and executes every pod rule.
+func (c *PodCleanController) cleanUpPods(ctx context.Context, run *cleanupRun) RunSummary {
 	logger := log.FromContext(ctx)
 	logger.Info("Starting pod cleanup")
 
-	for _, rule := range c.CleanupConfig.PodCleanupConfig.Rules {
-		if !rule.Enabled {
+	cfg, tenants := c.withTenantRules(ctx)
+	c.reportTenantRules(ctx, tenants)
+	c.markCooledDownRules(ctx, cfg, run)
+
+	// Dry runs delete nothing, so they are not limited by the deletion budgets.
+	budget := newDeletionBudget(cfg.MaxDeletionsPerRun, cfg.PerNamespaceMaxDeletions)
+	if run.DryRun {
+		budget = newDeletionBudget(0, 0)
+	}
+	c.PodMatcher.scope = run.Scope
+
+	// A targeted pass leaves the retry queue, candidate tracking and anomaly baselines, which
+	// cover the whole cluster, to full passes.
+	var retried map[types.NamespacedName]bool
+	if !run.Scope.targeted() {
+		retried = c.retryFailedDeletions(ctx, cfg, run, budget)
+	}
+	defer c.saveRetryQueue(ctx)
+
+	plans := planRulesWithin(ctx, c.PodMatcher, cfg, c.overrides, budget, run)
+	summary := summarize(plans)
+	run.summary = &summary
+	resolver := newOwnerResolver(c.Client)
+	checker := newDeletionChecker(c.PodMatcher)
+
+	// Under firstMatch, pods taken from a rule's deferred pods must not be acted on by another rule.
+	var claimed map[types.NamespacedName]struct{}
+	if cfg.PodCleanupConfig.RulePolicy == cleanupconfig.RulePolicyFirstMatch {
+		claimed = map[types.NamespacedName]struct{}{}
+		for _, plan := range plans {
+			claim(plan.Selected, claimed)
+		}
+	}
+	if !run.Scope.targeted() {
+		c.diffCandidates(ctx, plans, &summary)
+		c.detectAnomalies(ctx, run, plans)
+		c.compareShadow(ctx, plans)
+	}
+
+	for _, plan := range plans {
+		if c.interrupted(ctx, run) {
+			break
+		}
+
+		rule := plan.Rule
+		ctx := withSelectionCodes(withRule(ctx, rule.Name), func(pod *corev1.Pod) ReasonCode {
+			return c.PodMatcher.selectionCode(rule.Name, pod)
+		})
+
+		for reason, count := range plan.ListErrors {
+			listErrorsTotal.WithLabelValues(rule.Name, string(reason)).Add(float64(count))
+		}
+
+		var listFailures int
+		for _, count := range plan.ListErrors {
+			listFailures += count
+		}
+
+		matched := len(plan.Selected) + len(plan.Deferred)
+		action := newAction(rule.Action)
+
+		// Deletions that failed earlier in the run were refunded, which may leave room for pods the
+		// budget deferred when the rules were planned.
+		selected, deferred := plan.Selected, plan.Deferred
+		if claimed != nil {
+			deferred = unclaimed(deferred, claimed)
+		}
+		if len(deferred) > 0 {
+			var more []corev1.Pod
+			more, deferred = budget.allocateUnits(deferred, budgetUnits(rule))
+			selected = append(slices.Clone(selected), more...)
+			if claimed != nil {
+				claim(more, claimed)
+			}
+		}
+
+		if len(selected) == 0 {
+			c.statuses.record(RuleKindPod, rule.Name, time.Now(), matched, 0, 0, plan.Err)
+			c.checkCooldown(ctx, run, rule, listFailures, listFailures, plan.ListErrors)
+			recordDeferred(&summary, rule.Name, plan, deferred)
+			continue
+		}
+
+		var attempted []corev1.Pod
+		var results PodDeleteResults
+		var cascaded int // Pods removed along with their owner Job.
+		for batch := selected; len(batch) > 0; {
+			// Logs are captured before owners are deleted, since that deletes their pods too. Pods
+			// whose logs could not be pushed may be held back, and give their budget back.
+			pods := batch
+			if held := c.forwardLogs(ctx, run, rule.Name, batch); len(held) > 0 {
+				budget.refund(skippedResults(held))
+				holding := map[types.NamespacedName]bool{}
+				for _, pod := range held {
+					holding[types.NamespacedName{Namespace: pod.Namespace, Name: pod.Name}] = true
+				}
+				pods = slices.DeleteFunc(slices.Clone(pods), func(pod corev1.Pod) bool {
+					return holding[types.NamespacedName{Namespace: pod.Namespace, Name: pod.Name}]
+				})
+			}
+			attempted = append(attempted, pods...)
+
+			if rule.DeleteOwnerWhenEmpty {
+				remaining := deleteEmptyOwners(ctx, c.Client, pods, run.DryRun)
+				cascaded += len(pods) - len(remaining)
+				pods = remaining
+			}
+			if run.DryRun && action.Removes() {
+				logDeletionWarnings(ctx, checker, rule.Name, pods)
+			}
+			if len(retried) > 0 {
+				pods = slices.DeleteFunc(slices.Clone(pods), func(pod corev1.Pod) bool {
+					return retried[types.NamespacedName{Namespace: pod.Namespace, Name: pod.Name}]
+				})
+			}
+
+			batchResults := BatchApply(ctx, c.Client, action, pods, c.CleanupConfig.EffectiveBatchSize(), c.CleanupConfig.EffectiveBatchDelay(), run.DryRun)
+			results = append(results, batchResults...)
+			if !action.Removes() {
+				break
+			}
+
+			// Only successful deletions are charged; the pods the budget deferred may use the rest.
+			budget.refund(batchResults)
+			if batch, deferred = budget.allocateUnits(deferred, budgetUnits(rule)); len(batch) == 0 || c.interrupted(ctx, run) {
+				deferred = append(batch, deferred...)
+				break
+			}
+			if claimed != nil {
+				claim(batch, claimed)
+			}
+			logger.Info("Acting on deferred pods with the budget failed deletions left", "rule", rule.Name, "count", len(batch))
+			if err := waitFor(ctx, c.CleanupConfig.EffectiveBatchDelay()); err != nil {
+				budget.refund(skippedResults(batch))
+				deferred = append(batch, deferred...)
+				break
+			}
+		}
+		recordDeferred(&summary, rule.Name, plan, deferred)
+		if results.Skipped() > 0 {
+			// The breaker opened mid-batch; record it so the run reports being aborted.
+			c.interrupted(ctx, run)
+		}
+
+		err := results.Err()
+		failed := run.recordDeleteFailures(rule.Name, err)
+		if err != nil {
+			logger.Error(err, "Failed to act on some pods", "rule", rule.Name, "action", action.Name(), "failed", failed,
+				"applied", results.Deleted(), "reasons", ErrorReasons(err), "reasonCodes", ErrorCodes(ErrorReasons(err)))
+			// The queue retries by deleting; pods another action failed on are matched again next run.
+			if action.Name() == cleanupconfig.ActionDelete {
+				c.queueFailedDeletions(ctx, run, rule.Name, err)
+			}
+		}
+		tried := len(results) - results.Skipped()
+		c.statuses.record(RuleKindPod, rule.Name, time.Now(), matched, results.Deleted()+deletedCount(cascaded, run.DryRun), failed,
+			ruleError(plan.Err, err, tried, failed))
+
+		reasons := ErrorReasons(err)
+		for reason, count := range plan.ListErrors {
+			reasons[reason] += count
+		}
+		c.checkCooldown(ctx, run, rule, tried+listFailures, failed+listFailures, reasons)
+
+		// Only pods the action succeeded on are reported, so failed and skipped pods do not count as deleted.
+		applied := appliedPods(attempted, results, retried, run.DryRun)
+		owners := resolver.groupByOwner(ctx, applied)
+		logger.Info("Completed cleanup for rule", "rule", rule.Name, "processed", len(attempted), "applied", len(applied),
+			"failed", results.Failed(), "owners", describeOwners(owners))
+
+		event := notify.Event{Pods: len(applied), Failed: results.Failed(), Owners: ownerCounts(owners)}
+		if action.Name() != cleanupconfig.ActionDelete {
+			event.Action = action.Name()
+		}
+		c.notifyRule(ctx, run, rule.Name, rule.NotificationSinks, event, strings.TrimSpace("pod(s) "+describeOwners(owners)))
+	}
+
+	logger.Info("Pod cleanup completed", "matched", summary.Matched, "new", summary.New, "carriedOver", summary.CarriedOver,
+		"deferred", summary.Deferred, "listErrors", summary.ListErrors, "listErrorCodes", ErrorCodes(summary.ListErrors))
+	return summary
+}
+
+// recordDeferred reports the pods of plan the deletion budgets still deferred once the rule ran,
+// which may be fewer than planned when failed deletions were refunded.
+func recordDeferred(summary *RunSummary, rule string, plan rulePlan, deferred []corev1.Pod) {
+	summary.Deferred -= len(plan.Deferred) - len(deferred)
+	if len(deferred) > 0 {
+		deferredPodsTotal.WithLabelValues(rule).Add(float64(len(deferred)))
+	}
+}
+
+// appliedPods returns the pods of attempted the action succeeded on, or would have in a dry run.
+// Pods removed along with their owner have no result and count as applied; pods the retry queue
+// already deleted are left out.
+func appliedPods(attempted []corev1.Pod, results PodDeleteResults, retried map[types.NamespacedName]bool, dryRun bool) []corev1.Pod {
+	succeeded := make(map[types.NamespacedName]bool, len(results))
+	for _, result := range results {
+		succeeded[types.NamespacedName{Namespace: result.Namespace, Name: result.Name}] = result.Deleted ||
+			dryRun && result.Err == nil && result.Skipped == nil
+	}
+	return slices.DeleteFunc(slices.Clone(attempted), func(pod corev1.Pod) bool {
+		key := types.NamespacedName{Namespace: pod.Namespace, Name: pod.Name}
+		ok, acted := succeeded[key]
+		return retried[key] || acted && !ok
+	})
+}
+
+// skippedResults returns results for pods that were not acted on.
+func skippedResults(pods []corev1.Pod) PodDeleteResults {
+	results := make(PodDeleteResults, 0, len(pods))
+	for _, pod := range pods {
+		results = append(results, PodDeleteResult{Namespace: pod.Namespace, Name: pod.Name})
+	}
+	return results
+}
+
+// diffCandidates compares each rule's matched pods with its candidates from the previous run and
+// records the new and carried-over counts in summary. A spike of new candidates usually means a
+// workload started producing garbage, whereas carried-over ones are held back by budgets or dry-runs.
+func (c *PodCleanController) diffCandidates(ctx context.Context, plans []rulePlan, summary *RunSummary) {
+	planned := make(map[string]struct{}, len(plans))
+
+	for _, plan := range plans {
+		rule := plan.Rule.Name
+		planned[rule] = struct{}{}
+
+		fresh, carried := c.candidates.diff(rule, plan.Selected, plan.Deferred)
+		newCandidates.WithLabelValues(rule).Set(float64(fresh))
+		if fresh > 0 {
+			summary.NewByRule[rule] = fresh
+		}
+		summary.New += fresh
+		summary.CarriedOver += carried
+
+		if fresh > 0 || carried > 0 {
+			log.FromContext(ctx).Info("Compared candidates with previous run", "rule", rule, "new", fresh, "carriedOver", carried)
+		}
+	}
+
+	c.candidates.retain(planned)
+}
+
+// deletedCount returns how many of processed resources were deleted: none on a dry-run.
+func deletedCount(processed int, dryRun bool) int {
+	if dryRun {
+		return 0
+	}
+	return processed
+}
+
+// planRules evaluates every enabled rule of cfg in priority order without acting on any pod.
+// Runtime overrides, when given, decide which rules are enabled. Deletion budgets are applied as if
+// every selected pod were deleted.
+func planRules(ctx context.Context, matcher *PodMatcher, cfg *cleanupconfig.CleanupConfig, overrides *ruleOverrides) []rulePlan {
+	return planRulesWithin(ctx, matcher, cfg, overrides, newDeletionBudget(cfg.MaxDeletionsPerRun, cfg.PerNamespaceMaxDeletions), nil)
+}
+
+// planRulesWithin is planRules charging budget for the pods selected for removal. Actions that
+// keep pods, such as quarantine labels, are not charged. Within a run, rules whose lane does not
+// run and rules in cooldown are skipped, whatever their overrides; a nil run plans every rule.
+func planRulesWithin(ctx context.Context, matcher *PodMatcher, cfg *cleanupconfig.CleanupConfig, overrides *ruleOverrides, budget *deletionBudget, run *cleanupRun) []rulePlan {
+	if !cfg.PodCleanupConfig.Enabled {
+		return nil
+	}
+
+	logger := log.FromContext(ctx)
+	matcher.ResetCache()
+
+	if quotaPressure := cfg.PodCleanupConfig.QuotaPressure; quotaPressure.Enabled {
+		pressured, err := matcher.quotaPressuredNamespaces(ctx, quotaPressure.Threshold())
+		if err != nil {
+			logger.Error(err, "Failed to check ResourceQuota pressure; namespaces are not prioritized")
+		} else if len(pressured) > 0 {
+			logger.Info("Prioritizing namespaces under ResourceQuota pressure", "namespaces", slices.Sorted(maps.Keys(pressured)))
+		}
+		budget.urgent = pressured
+	}
+	firstMatch := cfg.PodCleanupConfig.RulePolicy == cleanupconfig.RulePolicyFirstMatch
+	claimed := map[types.NamespacedName]struct{}{}
+
+	var plans []rulePlan
+
+	for _, rule := range orderedRules(cfg.PodCleanupConfig.EffectiveRules()) {
+		if !overrides.isEnabled(RuleKindPod, rule.Name, rule.Enabled) {
+			continue
+		}
+		if !run.inLane(rule.Lane) {
+			logger.V(1).Info("Skipping rule outside the lanes of this run", "rule", rule.Name, "lane", rule.Lane)
+			continue
+		}
+		if run.coolingDown(rule.Name) {
+			continue
+		}
+
+		rule, err := cfg.Constraints.Enforce(withGlobalSettings(rule, cfg.PodCleanupConfig))
+		ctx := withRule(ctx, rule.Name)
+		plan := rulePlan{Rule: rule, ListErrors: map[ErrorReason]int{}}
+		if err != nil {
+			plan.Err = err
+			logger.Error(err, "Skipping rule outside the constraints", "rule", rule.Name)
+			plans = append(plans, plan)
 			continue
 		}
 
 		logger.Info("Processing cleanup rule", "rule", rule.Name)
 
-		pods, err := c.PodMatcher.FindPodsToCleanup(ctx, rule)
+		pods, err := matcher.FindPodsToCleanup(ctx, rule)
 		if err != nil {
+			plan.Err = err
+			plan.ListErrors = ErrorReasons(err)
 			logger.Error(err, "Failed to find pods", "rule", rule.Name)
-			continue
+		}
+
+		// Pods already labelled or annotated as the rule's action would do are not acted on again.
+		action := newAction(rule.Action)
+		if !action.Removes() {
+			pending := pods[:0]
+			for i := range pods {
+				if !action.Applied(&pods[i]) {
+					pending = append(pending, pods[i])
+				}
+			}
+			pods = pending
+		}
+
+		if firstMatch {
+			pods = unclaimed(pods, claimed)
 		}
 
 		if len(pods) == 0 {
 			logger.V(1).Info("No pods to cleanup for rule", "rule", rule.Name)
+			plans = append(plans, plan)
 			continue
 		}
 
 		logger.Info("Found pods to cleanup", "rule", rule.Name, "count", len(pods))
 
-		if err := BatchDeletePods(ctx, c.Client, pods, c.CleanupConfig.BatchSize, c.CleanupConfig.DryRun); err != nil {
-			logger.Error(err, "Failed to batch delete pods", "rule", rule.Name)
-			continue
+		if !action.Removes() {
+			plan.Selected = pods
+		} else if plan.Selected, plan.Deferred = budget.allocateUnits(pods, budgetUnits(rule)); len(plan.Deferred) > 0 {
+			logger.Info("Deletion budget exhausted; deferring pods to a later run", "rule", rule.Name, "deferred", len(plan.Deferred),
+				"reasonCode", ReasonCodeDeferredByBudget)
+		}
+
+		// Deferred pods stay unclaimed, so a lower-priority rule may act on them in this run.
+		if firstMatch {
+			claim(plan.Selected, claimed)
+		}
+
+		plans = append(plans, plan)
+	}
+
+	return plans
+}
+
+// summarize aggregates rule plans into a RunSummary.
+func summarize(plans []rulePlan) RunSummary {
+	summary := RunSummary{MatchedByRule: map[string]int{}, NewByRule: map[string]int{}, DeleteErrors: map[ErrorReason]int{}, ListErrors: map[ErrorReason]int{}}
+
+	for _, plan := range plans {
+		matched := len(plan.Selected) + len(plan.Deferred)
+		if matched > 0 {
+			summary.MatchedByRule[plan.Rule.Name] = matched
+		}
+		summary.Matched += matched
+		summary.Deferred += len(plan.Deferred)
+
+		for reason, count := range plan.ListErrors {
+			summary.ListErrors[reason] += count
+		}
+	}
+
+	return summary
+}
+
+// notifyRule reports a rule's outcome to its notification sinks, falling back to all global sinks.
+// event carries the processed counts; the remaining fields are filled in here.
+func (c *PodCleanController) notifyRule(ctx context.Context, run *cleanupRun, ruleName string, sinks []string, event notify.Event, noun string) {
+	if run.notifier == nil {
+		return
+	}
+
+	event.RunID = run.ID
+	event.DryRun = event.DryRun || run.DryRun
+	event.Summary = run.summarySoFar()
+
+	verb := actionVerb(event.Action, event.DryRun)
+
+	processed := event.Pods + event.Resources
+	event.Rule = ruleName
+	event.Message = fmt.Sprintf("%s %d %s for rule %s", verb, processed, noun, ruleName)
+	if event.Failed > 0 {
+		event.Message += fmt.Sprintf("; %d failed", event.Failed)
+	}
+
+	if err := run.notifier.Notify(ctx, sinks, event); err != nil {
+		log.FromContext(ctx).Error(err, "Failed to send notification", "rule", ruleName)
+	}
+}
+
+// actionVerb describes in a notification what action did, or would have done in a dry run. An
+// empty action is a deletion.
+func actionVerb(action string, dryRun bool) string {
+	var done, wouldDo string
+	switch action {
+	case cleanupconfig.ActionEvict:
+		done, wouldDo = "Evicted", "Would evict"
+	case cleanupconfig.ActionLabelQuarantine:
+		done, wouldDo = "Quarantined", "Would quarantine"
+	case cleanupconfig.ActionAnnotatePatch:
+		done, wouldDo = "Annotated", "Would annotate"
+	case cleanupconfig.ActionScaleToZero:
+		done, wouldDo = "Scaled to zero", "Would scale to zero"
+	case cleanupconfig.ActionSuspend:
+		done, wouldDo = "Suspended", "Would suspend"
+	default:
+		done, wouldDo = "Deleted", "Would delete"
+	}
+	if dryRun {
+		return wouldDo
+	}
+	return done
+}
+
+// orderedRules returns the rules sorted by descending priority, keeping file order for equal
+// priorities, except that a rule comes after every rule named in its 'after' list.
+func orderedRules(rules []cleanupconfig.PodCleanRule) []cleanupconfig.PodCleanRule {
+	byPriority := slices.Clone(rules)
+	sort.SliceStable(byPriority, func(i, j int) bool {
+		return byPriority[i].Priority > byPriority[j].Priority
+	})
+
+	pending := map[string]bool{}
+	for _, rule := range byPriority {
+		pending[rule.Name] = true
+	}
+	ready := func(rule cleanupconfig.PodCleanRule) bool {
+		return !slices.ContainsFunc(rule.After, func(name string) bool { return pending[name] })
+	}
+
+	// Each round places the first rule whose predecessors are all placed. Validation rejects
+	// cycles; should one remain, its rules keep their priority order.
+	ordered := make([]cleanupconfig.PodCleanRule, 0, len(byPriority))
+	for len(byPriority) > 0 {
+		next := slices.IndexFunc(byPriority, ready)
+		if next < 0 {
+			next = 0
+		}
+		ordered = append(ordered, byPriority[next])
+		delete(pending, byPriority[next].Name)
+		byPriority = slices.Delete(byPriority, next, next+1)
+	}
+	return ordered
+}
+
+// unclaimed returns the pods not yet claimed by a higher-priority rule.
+func unclaimed(pods []corev1.Pod, claimed map[types.NamespacedName]struct{}) []corev1.Pod {
+	var free []corev1.Pod
+	for i := range pods {
+		if _, taken := claimed[types.NamespacedName{Namespace: pods[i].Namespace, Name: pods[i].Name}]; !taken {
+			free = append(free, pods[i])
 		}
+	}
+	return free
+}
 
-		logger.Info("Completed cleanup for rule", "rule", rule.Name, "processed", len(pods))
+// claim records that a rule acts on pods, so no other rule does under the firstMatch policy.
+func claim(pods []corev1.Pod, claimed map[types.NamespacedName]struct{}) {
+	for i := range pods {
+		claimed[types.NamespacedName{Namespace: pods[i].Namespace, Name: pods[i].Name}] = struct{}{}
 	}
+}
 
-	logger.Info("Pod cleanup completed")
+// withGlobalSettings returns a copy of rule extended with the settings that apply to every rule.
+func withGlobalSettings(rule cleanupconfig.PodCleanRule, podConfig cleanupconfig.PodCleanupConfig) cleanupconfig.PodCleanRule {
+	rule.ExcludePriorityClasses = append(slices.Clone(podConfig.ExcludePriorityClasses), rule.ExcludePriorityClasses...)
+	if rule.CordonedNodes == "" && podConfig.SkipCordonedNodes {
+		rule.CordonedNodes = cleanupconfig.CordonedNodesSkip
+	}
+	if rule.InvalidAnnotationPolicy == "" {
+		rule.InvalidAnnotationPolicy = podConfig.InvalidAnnotationPolicy
+	}
+	rule.QuotaPressure = podConfig.QuotaPressure
+	rule.BusinessCalendar = podConfig.BusinessCalendar
+	return rule
 }
 
+// FindPodsToCleanup lists the pods matching rule. Namespaces that cannot be listed are reported
+// as *ListError values in a joined error alongside the pods found in the remaining namespaces.
 func (pm *PodMatcher) FindPodsToCleanup(ctx context.Context, rule cleanupconfig.PodCleanRule) ([]corev1.Pod, error) {
-	logger := log.FromContext(ctx)
 	selector, err := metav1.LabelSelectorAsSelector(&rule.Selector)
 	if err != nil {
 		return nil, fmt.Errorf("invalid label selector: %w", err)
 	}
 
-	namespaces := rule.Namespaces
-	if len(namespaces) == 0 {
-		namespaces = []string{""} // All namespaces
+	namespaces, err := pm.ruleNamespaces(ctx, rule)
+	if err != nil {
+		return nil, err
 	}
 
-	var podsToCleanup []corev1.Pod
+	var errs []error
+
+	// Candidates are recorded by key while scanning, and each namespace's list is compacted to its
+	// candidates, so the other pods are released and matched pods are copied out only once. The
+	// value is true for completed pods the rule matches but for their TTL; maxPerNode trims the
+	// oldest of them.
+	candidates := map[podKey]bool{}
+	var matched [][]corev1.Pod
+
+	// Namespaces under ResourceQuota pressure may apply a shorter TTL.
+	var pressured map[string]bool
+	pressuredRule := rule
+	if rule.QuotaPressure.Enabled && rule.QuotaPressure.TTLPercent > 0 {
+		if pressured, err = pm.quotaPressuredNamespaces(ctx, rule.QuotaPressure.Threshold()); err != nil {
+			errs = append(errs, err)
+		}
+		pressuredRule.TTL.Duration = rule.QuotaPressure.ScaleTTL(rule.TTL.Duration)
+	}
 
 	for _, namespace := range namespaces {
 		var podList corev1.PodList
-		if err := pm.client.List(ctx, &podList, &client.ListOptions{
-			Namespace:     namespace,
-			LabelSelector: selector,
+		if err := withThrottleRetry(ctx, "list", func() error {
+			return pm.client.List(ctx, &podList, &client.ListOptions{
+				Namespace:     namespace,
+				LabelSelector: selector,
+			})
 		}); err != nil {
-			logger.Error(err, "Failed to list pods", "namespace", namespace)
+			errs = append(errs, newListError("pods", namespace, err))
 			continue
 		}
 
 		for i := range podList.Items {
 			pod := &podList.Items[i]
-			if pm.ShouldCleanupPod(pod, rule) {
-				podsToCleanup = append(podsToCleanup, *pod)
+			if !pm.scope.includesPod(pod) {
+				continue
+			}
+
+			if slices.Contains(rule.ForbiddenNamespaces, pod.Namespace) {
+				skippedPodsTotal.WithLabelValues(rule.Name, string(SkipReasonNamespaceForbidden)).Inc()
+				continue
+			}
+
+			evaluated := rule
+			if pressured[pod.Namespace] {
+				evaluated = pressuredRule
+			}
+			reason := pm.EvaluatePod(pod, evaluated)
+			trimmable := reason == SkipReasonTTL && rule.MaxPerNode > 0 && pod.Spec.NodeName != "" && isPodCompleted(pod)
+			if reason != SkipReasonNone && !trimmable {
+				if reason == SkipReasonInvalidAnnotation && rule.InvalidAnnotationPolicy == cleanupconfig.InvalidAnnotationFail {
+					_, err := ParseTTLAnnotation(pod.Annotations[AnnotationTTL])
+					return nil, errors.Join(append(errs, &AnnotationError{
+						Namespace:  pod.Namespace,
+						Pod:        pod.Name,
+						Annotation: AnnotationTTL,
+						Err:        err,
+					})...)
+				}
+				if reason != SkipReasonCriteria {
+					skippedPodsTotal.WithLabelValues(rule.Name, string(reason)).Inc()
+				}
+				continue
+			}
+
+			// A pod whose surroundings cannot be read is skipped; the rest of the rule goes on.
+			reason, err := pm.evaluateSurroundings(ctx, pod, rule)
+			if err != nil {
+				log.FromContext(ctx).Error(err, "Failed to check pod's namespace, owners or node; skipping it", "pod", pod.Name, "namespace", pod.Namespace, "rule", rule.Name)
+				if !slices.ContainsFunc(errs, func(e error) bool { return e.Error() == err.Error() }) {
+					errs = append(errs, err)
+				}
+				reason = SkipReasonLookupFailed
+			}
+			if reason != SkipReasonNone {
+				if reason != SkipReasonCriteria {
+					skippedPodsTotal.WithLabelValues(rule.Name, string(reason)).Inc()
+				}
+				continue
+			}
+
+			candidates[keyOf(pod)] = trimmable
+		}
+
+		if kept := slices.DeleteFunc(podList.Items, func(pod corev1.Pod) bool {
+			_, ok := candidates[keyOf(&pod)]
+			return !ok
+		}); len(kept) > 0 {
+			matched = append(matched, kept)
+		}
+	}
+
+	var podsToCleanup, young []corev1.Pod
+	for _, pods := range matched {
+		for i := range pods {
+			if candidates[keyOf(&pods[i])] {
+				young = append(young, pods[i])
+			} else {
+				podsToCleanup = append(podsToCleanup, pods[i])
 			}
 		}
 	}
 
-	return podsToCleanup, nil
+	if rule.MaxPerNode > 0 {
+		trimmed, kept := trimPerNode(young, rule.MaxPerNode)
+		podsToCleanup = append(podsToCleanup, trimmed...)
+		if len(trimmed) > 0 && pm.trimmed == nil {
+			pm.trimmed = map[trimmedPod]struct{}{}
+		}
+		for _, pod := range trimmed {
+			pm.trimmed[trimmedPod{rule: rule.Name, pod: types.NamespacedName{Namespace: pod.Namespace, Name: pod.Name}}] = struct{}{}
+		}
+		skippedPodsTotal.WithLabelValues(rule.Name, string(SkipReasonTTL)).Add(float64(len(kept)))
+	}
+
+	podsToCleanup, err = pm.filterSparkApplications(ctx, podsToCleanup, rule)
+	if err != nil {
+		return nil, errors.Join(append(errs, err)...)
+	}
+
+	podsToCleanup, err = pm.keepMinAvailable(ctx, podsToCleanup, rule)
+	if err != nil {
+		return nil, errors.Join(append(errs, err)...)
+	}
+
+	return podsToCleanup, errors.Join(errs...)
+}
+
+// evaluateSurroundings returns why rule does not select pod given its namespace, owners and node,
+// or SkipReasonNone when it does. EvaluatePod checks the pod itself.
+func (pm *PodMatcher) evaluateSurroundings(ctx context.Context, pod *corev1.Pod, rule cleanupconfig.PodCleanRule) (SkipReason, error) {
+	// The namespace controller deletes these pods itself; deleting them too only adds noise.
+	terminating, err := pm.isNamespaceTerminating(ctx, pod.Namespace)
+	if err != nil {
+		return SkipReasonNone, err
+	}
+	if terminating {
+		return SkipReasonNamespaceTerminating, nil
+	}
+
+	disabled, err := pm.isOptedOut(ctx, pod)
+	if err != nil {
+		return SkipReasonNone, err
+	}
+	if disabled {
+		return SkipReasonDisabled, nil
+	}
+
+	if rule.SkipDuringRollout {
+		rolling, err := pm.isOwnerRollingOut(ctx, pod)
+		if err != nil {
+			return SkipReasonNone, err
+		}
+		if rolling {
+			return SkipReasonOwnerRollingOut, nil
+		}
+	}
+
+	onNode, err := pm.matchesNode(ctx, pod, rule)
+	if err != nil {
+		return SkipReasonNone, err
+	}
+	if !onNode {
+		return SkipReasonCriteria, nil
+	}
+
+	// The pod may have run for long before its node went away; the TTL counts from the loss.
+	if rule.NodeDeleted && pm.clock().Sub(pm.nodeMissingSince(pod)) <= rule.TTL.Duration {
+		return SkipReasonTTL, nil
+	}
+	return SkipReasonNone, nil
+}
+
+// ruleNamespaces returns the namespaces to list pods in for rule. An empty namespace stands for
+// all namespaces; a namespaceSelector that matches no namespace yields none. In a targeted pass,
+// it yields the pass's namespace if the rule covers it, and none otherwise.
+func (pm *PodMatcher) ruleNamespaces(ctx context.Context, rule cleanupconfig.PodCleanRule) ([]string, error) {
+	namespaces, err := pm.configuredNamespaces(ctx, rule)
+	if err != nil || pm.scope.Namespace == "" {
+		return namespaces, err
+	}
+
+	if slices.Contains(namespaces, "") || slices.Contains(namespaces, pm.scope.Namespace) {
+		return []string{pm.scope.Namespace}, nil
+	}
+	return nil, nil
+}
+
+// configuredNamespaces returns the namespaces rule's namespaces or namespaceSelector select.
+func (pm *PodMatcher) configuredNamespaces(ctx context.Context, rule cleanupconfig.PodCleanRule) ([]string, error) {
+	if rule.NamespaceSelector == nil {
+		if len(rule.Namespaces) == 0 {
+			return []string{""}, nil // All namespaces
+		}
+		return rule.Namespaces, nil
+	}
+
+	selector, err := metav1.LabelSelectorAsSelector(rule.NamespaceSelector)
+	if err != nil {
+		return nil, fmt.Errorf("invalid namespace selector: %w", err)
+	}
+
+	var namespaceList corev1.NamespaceList
+	if err := withThrottleRetry(ctx, "list", func() error {
+		return pm.client.List(ctx, &namespaceList, &client.ListOptions{LabelSelector: selector})
+	}); err != nil {
+		return nil, newListError("namespaces", "", err)
+	}
+
+	namespaces := make([]string, 0, len(namespaceList.Items))
+	for _, namespace := range namespaceList.Items {
+		namespaces = append(namespaces, namespace.Name)
+	}
+	return namespaces, nil
 }
 
+// ShouldCleanupPod reports whether rule selects pod for cleanup.
 func (pm *PodMatcher) ShouldCleanupPod(pod *corev1.Pod, rule cleanupconfig.PodCleanRule) bool {
-	if string(pod.Status.Phase) != rule.Phase {
-		return false
+	return pm.EvaluatePod(pod, rule) == SkipReasonNone
+}
+
+// EvaluatePod returns why rule does not select pod for cleanup, or SkipReasonNone when it does.
+func (pm *PodMatcher) EvaluatePod(pod *corev1.Pod, rule cleanupconfig.PodCleanRule) SkipReason {
+	switch {
+	case rule.Match != nil:
+		if !matchesCriteria(pod, rule.Match) {
+			return SkipReasonCriteria
+		}
+	case rule.Phase == "" && rule.NodeDeleted:
+		// Pods on a deleted node keep whatever phase they last reported; the node check decides.
+	case string(pod.Status.Phase) != rule.Phase:
+		return SkipReasonCriteria
+	}
+
+	// Mirror pods are the API server's view of static pods; deleting one is undone by the kubelet.
+	if isMirrorPod(pod) {
+		return SkipReasonMirrorPod
+	}
+
+	if pod.DeletionTimestamp != nil {
+		return SkipReasonTerminating
+	}
+
+	if pod.Annotations[AnnotationDisabled] == "true" {
+		return SkipReasonDisabled
+	}
+
+	if pod.Spec.PriorityClassName != "" && slices.Contains(rule.ExcludePriorityClasses, pod.Spec.PriorityClassName) {
+		return SkipReasonPriorityClass
 	}
 
-	if pod.Annotations["kubeclean/disabled"] == "true" {
-		return false
+	if rule.ExcludeSelector != nil {
+		exclude, err := metav1.LabelSelectorAsSelector(rule.ExcludeSelector)
+		if err != nil || exclude.Matches(labels.Set(pod.Labels)) {
+			return SkipReasonExcluded
+		}
 	}
 
-	ttl := rule.TTL.Duration
-	if ttlStr, exists := pod.Annotations["kubeclean/ttl"]; exists {
-		if parsedTTL, err := time.ParseDuration(ttlStr); err == nil {
-			ttl = parsedTTL
-		} else {
+	ttl, annotated := rule.TTL.Duration, false
+	if ttlStr, exists := pod.Annotations[AnnotationTTL]; exists {
+		parsedTTL, err := ParseTTLAnnotation(ttlStr)
+		switch {
+		case err == nil:
+			// Annotations may lengthen a rule's TTL freely, but not go below the constraints.
+			ttl, annotated = max(parsedTTL, rule.MinTTL.Duration), true
+		case rule.InvalidAnnotationPolicy == cleanupconfig.InvalidAnnotationSkip,
+			rule.InvalidAnnotationPolicy == cleanupconfig.InvalidAnnotationFail:
+			return SkipReasonInvalidAnnotation
+		default:
 			log.FromContext(context.TODO()).Info("Invalid TTL annotation; using rule TTL", "pod", pod.Name, "error", err)
 		}
 	}
 
-	age := time.Since(pod.CreationTimestamp.Time)
-	return age > ttl
+	// A TTL annotation, which is a duration, takes precedence over business days too.
+	if rule.TTLBusinessDays > 0 && !annotated {
+		if pm.clock().Before(rule.BusinessCalendar.BusinessDaysAfter(pod.CreationTimestamp.Time, rule.TTLBusinessDays)) {
+			return SkipReasonTTL
+		}
+	} else if pm.clock().Sub(pod.CreationTimestamp.Time) <= ttl {
+		return SkipReasonTTL
+	}
+
+	return SkipReasonNone
+}
+
+// PodDeleteResult is the outcome of deleting one pod.
+type PodDeleteResult struct {
+	Namespace string
+	Name      string
+	Deleted   bool  // Whether the rule's action, a deletion by default, was applied; false on dry-runs, failures and pods already gone.
+	Err       error // A *PodDeleteError if the action failed.
+	Skipped   error // Why the action was not attempted, such as ErrCircuitBreakerOpen; nil if it was.
+}
+
+// PodDeleteResults are the outcomes of a BatchDeletePods or BatchApply call, in the order of its pods.
+type PodDeleteResults []PodDeleteResult
+
+// Deleted returns the number of pods that were deleted.
+func (r PodDeleteResults) Deleted() int {
+	var deleted int
+	for _, result := range r {
+		if result.Deleted {
+			deleted++
+		}
+	}
+	return deleted
+}
+
+// Failed returns the number of pods whose deletion failed.
+func (r PodDeleteResults) Failed() int {
+	var failed int
+	for _, result := range r {
+		if result.Err != nil {
+			failed++
+		}
+	}
+	return failed
+}
+
+// Skipped returns the number of pods the action was not attempted on.
+func (r PodDeleteResults) Skipped() int {
+	var skipped int
+	for _, result := range r {
+		if result.Skipped != nil {
+			skipped++
+		}
+	}
+	return skipped
+}
+
+// Err joins the failed deletions, or returns nil if none failed.
+func (r PodDeleteResults) Err() error {
+	var errs []error
+	for _, result := range r {
+		if result.Err != nil {
+			errs = append(errs, result.Err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// BatchDeletePods deletes pods in batches of batchSize, pausing batchDelay between batches and
+// continuing past failures, and returns the outcome of every pod. Pods that are already gone are
+// not reported as failures. When ctx is done during a pause, the remaining pods fail with its error.
+func BatchDeletePods(ctx context.Context, k8sClient client.Client, pods []corev1.Pod, batchSize int, batchDelay time.Duration, dryRun bool) PodDeleteResults {
+	return BatchApply(ctx, k8sClient, deleteAction{}, pods, batchSize, batchDelay, dryRun)
 }
 
-func BatchDeletePods(ctx context.Context, k8sClient client.Client, pods []corev1.Pod, batchSize int, dryRun bool) error {
+// BatchApply applies action to pods in batches of batchSize, like BatchDeletePods. A result's
+// Deleted field reports whether the action was applied to its pod. When the circuit breaker of ctx
+// opens between batches, the remaining pods are skipped with ErrCircuitBreakerOpen.
+func BatchApply(ctx context.Context, k8sClient client.Client, action Action, pods []corev1.Pod, batchSize int, batchDelay time.Duration, dryRun bool) PodDeleteResults {
 	logger := log.FromContext(ctx)
+	results := make(PodDeleteResults, 0, len(pods))
 
 	for i := 0; i < len(pods); i += batchSize {
 		end := i + batchSize
@@ -142,39 +1567,95 @@ func BatchDeletePods(ctx context.Context, k8sClient client.Client, pods []corev1
 			end = len(pods)
 		}
 
+		if i > 0 {
+			if reason := circuitBreakerOpenIn(ctx); reason != "" {
+				logger.Info("Stopping "+action.Name()+" actions; the API server circuit breaker is open", "remaining", len(pods)-i, "reason", reason)
+				skipped := fmt.Errorf("%w: %s", ErrCircuitBreakerOpen, reason)
+				for _, pod := range pods[i:] {
+					results = append(results, PodDeleteResult{Namespace: pod.Namespace, Name: pod.Name, Skipped: skipped})
+				}
+				break
+			}
+		}
+
 		batch := pods[i:end]
-		logger.Info("Processing batch", "range", fmt.Sprintf("%d-%d", i+1, end), "total", len(pods))
+		logger.Info("Processing batch", "range", fmt.Sprintf("%d-%d", i+1, end), "total", len(pods), "action", action.Name())
 
-		for _, pod := range batch {
+		for j := range batch {
+			pod := &batch[j]
+			result := PodDeleteResult{Namespace: pod.Namespace, Name: pod.Name}
+			podKeys := []any{"pod", pod.Name, "namespace", pod.Namespace}
+			if code := selectionCodeFromContext(ctx, pod); code != "" {
+				podKeys = append(podKeys, "reasonCode", code)
+			}
 			if dryRun {
-				logger.Info("DRY RUN: Would delete pod", "pod", pod.Name, "namespace", pod.Namespace)
+				logger.Info("DRY RUN: Would "+action.Name()+" pod", podKeys...)
+				results = append(results, result)
 				continue
 			}
 
-			logger.Info("Deleting pod", "pod", pod.Name, "namespace", pod.Namespace)
-			if err := k8sClient.Delete(ctx, &pod); err != nil {
-				logger.Error(err, "Failed to delete pod", "pod", pod.Name, "namespace", pod.Namespace)
+			logger.Info("Applying "+action.Name()+" to pod", podKeys...)
+			if err := withThrottleRetry(ctx, action.Name(), func() error {
+				return action.Apply(ctx, k8sClient, pod)
+			}); err != nil {
+				logger.Error(err, "Failed to "+action.Name()+" pod", "pod", pod.Name, "namespace", pod.Namespace,
+					"reason", ClassifyError(err), "reasonCode", ClassifyError(err).Code())
+				if !apierrors.IsNotFound(err) {
+					result.Err = &PodDeleteError{Namespace: pod.Namespace, Name: pod.Name, UID: pod.UID, Err: err}
+				}
+				results = append(results, result)
+				continue
+			}
+			result.Deleted = true
+			results = append(results, result)
+			if action.Removes() {
+				reportDeletion(ctx, pod)
 			}
 		}
 
+		reportBatch(ctx, end, len(pods))
+
 		if end < len(pods) {
-			time.Sleep(100 * time.Millisecond)
+			if err := waitFor(ctx, batchDelay); err != nil {
+				logger.Info("Stopping "+action.Name()+" actions; the run was cancelled", "remaining", len(pods)-end)
+				for _, pod := range pods[end:] {
+					results = append(results, PodDeleteResult{Namespace: pod.Namespace, Name: pod.Name,
+						Err: &PodDeleteError{Namespace: pod.Namespace, Name: pod.Name, UID: pod.UID, Err: err}})
+				}
+				break
+			}
 		}
 	}
 
-	return nil
+	return results
 }
 
+// runTimeout bounds a single cleanup pass.
+const runTimeout = 10 * time.Minute
+
+// ErrRunInProgress is returned by TriggerRun while a cleanup pass is already running.
+var ErrRunInProgress = errors.New("a cleanup run is already in progress")
+
+// RunPodCleanJob runs a scheduled cleanup pass every interval until ctx is cancelled. The first
+// pass starts after the controller's startupDelay.
 func RunPodCleanJob(ctx context.Context, controller *PodCleanController, interval time.Duration) {
+	first := time.NewTimer(controller.startupDelay(ctx, interval, time.Now()))
+	defer first.Stop()
+
+	select {
+	case tick := <-first.C:
+		controller.runScheduled(ctx, tick)
+	case <-ctx.Done():
+		return
+	}
+
 	ticker := time.NewTicker(interval)
 	defer ticker.Stop()
 
 	for {
 		select {
-		case <-ticker.C:
-			runCtx, cancel := context.WithTimeout(ctx, 10*time.Minute)
-			controller.RunCleanUp(runCtx)
-			cancel()
+		case tick := <-ticker.C:
+			controller.runScheduled(ctx, tick)
 
 		case <-ctx.Done():
 			return
@@ -2,15 +2,26 @@ package controller
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"sync"
 	"testing"
 	"time"
 
 	cleanupconfig "github.com/infrautils/kubeclean/internal/cleanup_config"
 	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
 	ctrlclient "sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
 )
 
 func TestPodCleanupController(t *testing.T) {
@@ -339,3 +350,1302 @@ func TestPodCleanupController_InvalidSelector(t *testing.T) {
 		t.Errorf("Unexpected pods after cleanup: %+v", podList.Items)
 	}
 }
+
+func TestFindPodsToCleanup_AggregatesTypedListErrors(t *testing.T) {
+	scheme := runtime.NewScheme()
+	_ = corev1.AddToScheme(scheme)
+
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:              "visible-pod",
+			Namespace:         "default",
+			CreationTimestamp: metav1.NewTime(time.Now().Add(-2 * time.Hour)),
+		},
+		Status: corev1.PodStatus{Phase: corev1.PodSucceeded},
+	}
+
+	client := fake.NewClientBuilder().WithScheme(scheme).WithRuntimeObjects(pod).
+		WithInterceptorFuncs(interceptor.Funcs{
+			List: func(ctx context.Context, c ctrlclient.WithWatch, list ctrlclient.ObjectList, opts ...ctrlclient.ListOption) error {
+				listOpts := &ctrlclient.ListOptions{}
+				listOpts.ApplyOptions(opts)
+				switch listOpts.Namespace {
+				case "restricted":
+					return apierrors.NewForbidden(schema.GroupResource{Resource: "pods"}, "", errors.New("denied"))
+				case "slow":
+					return apierrors.NewTimeoutError("list timed out", 1)
+				}
+				return c.List(ctx, list, opts...)
+			},
+		}).Build()
+
+	rule := cleanupconfig.PodCleanRule{
+		Name:       "typed-errors",
+		Enabled:    true,
+		Phase:      string(corev1.PodSucceeded),
+		TTL:        cleanupconfig.Duration{Duration: time.Hour},
+		Namespaces: []string{"restricted", "slow", "default"},
+	}
+
+	pods, err := NewPodMatcher(client).FindPodsToCleanup(context.Background(), rule)
+	if err == nil {
+		t.Fatal("Expected aggregated error, got nil")
+	}
+
+	if len(pods) != 1 || pods[0].Name != "visible-pod" {
+		t.Errorf("Expected pods from listable namespaces to be returned, got %+v", pods)
+	}
+
+	reasons := ErrorReasons(err)
+	if reasons[ErrorReasonForbidden] != 1 || reasons[ErrorReasonTimeout] != 1 {
+		t.Errorf("Unexpected error reasons: %v", reasons)
+	}
+
+	var listErr *ListError
+	if !errors.As(err, &listErr) {
+		t.Errorf("Expected error to contain a *ListError, got %v", err)
+	}
+}
+
+func TestBatchDeletePods_StopsWhenContextDone(t *testing.T) {
+	scheme := runtime.NewScheme()
+	_ = corev1.AddToScheme(scheme)
+
+	var pods []corev1.Pod
+	var objects []runtime.Object
+	for _, name := range []string{"a", "b", "c"} {
+		pod := corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default"}}
+		pods = append(pods, pod)
+		objects = append(objects, &pod)
+	}
+	client := fake.NewClientBuilder().WithScheme(scheme).WithRuntimeObjects(objects...).Build()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	results := BatchDeletePods(ctx, client, pods, 1, time.Hour, false)
+
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("Expected the pause between batches to end with the context, waited %v", elapsed)
+	}
+	if len(results) != 3 || results.Deleted() != 1 || results.Failed() != 2 {
+		t.Fatalf("Expected the first pod deleted and the rest failed, got %+v", results)
+	}
+	if !errors.Is(results[2].Err, context.DeadlineExceeded) {
+		t.Errorf("Expected the remaining pods to fail with the context's error, got %v", results[2].Err)
+	}
+}
+
+func TestBatchDeletePods_HonorsRetryAfter(t *testing.T) {
+	scheme := runtime.NewScheme()
+	_ = corev1.AddToScheme(scheme)
+
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "throttled-pod", Namespace: "default"},
+	}
+
+	attempts := 0
+	client := fake.NewClientBuilder().WithScheme(scheme).WithRuntimeObjects(pod).
+		WithInterceptorFuncs(interceptor.Funcs{
+			Delete: func(ctx context.Context, c ctrlclient.WithWatch, obj ctrlclient.Object, opts ...ctrlclient.DeleteOption) error {
+				attempts++
+				if attempts == 1 {
+					return apierrors.NewTooManyRequests("slow down", 1)
+				}
+				return c.Delete(ctx, obj, opts...)
+			},
+		}).Build()
+
+	start := time.Now()
+	if err := BatchDeletePods(context.Background(), client, []corev1.Pod{*pod}, 10, 0, false).Err(); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if attempts != 2 {
+		t.Errorf("Expected delete to be retried once after 429, got %d attempts", attempts)
+	}
+
+	if elapsed := time.Since(start); elapsed < time.Second {
+		t.Errorf("Expected run to pause for Retry-After, only waited %v", elapsed)
+	}
+
+	if err := client.Get(context.Background(), ctrlclient.ObjectKeyFromObject(pod), &corev1.Pod{}); !apierrors.IsNotFound(err) {
+		t.Errorf("Expected pod to be deleted after retry, got %v", err)
+	}
+}
+
+func TestPodCleanupController_ExcludePriorityClasses(t *testing.T) {
+	scheme := runtime.NewScheme()
+	_ = corev1.AddToScheme(scheme)
+
+	newPod := func(name, priorityClass string) *corev1.Pod {
+		return &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:              name,
+				Namespace:         "default",
+				Labels:            map[string]string{"app": "test"},
+				CreationTimestamp: metav1.NewTime(time.Now().Add(-2 * time.Hour)),
+			},
+			Spec:   corev1.PodSpec{PriorityClassName: priorityClass},
+			Status: corev1.PodStatus{Phase: corev1.PodSucceeded},
+		}
+	}
+
+	client := fake.NewClientBuilder().WithScheme(scheme).WithRuntimeObjects(
+		newPod("critical-pod", "system-cluster-critical"),
+		newPod("gold-pod", "gold"),
+		newPod("plain-pod", ""),
+	).Build()
+
+	cleanupCfg := &cleanupconfig.CleanupConfig{
+		BatchSize: 10,
+		PodCleanupConfig: cleanupconfig.PodCleanupConfig{
+			Enabled:                true,
+			ExcludePriorityClasses: []string{"system-cluster-critical"},
+			Rules: []cleanupconfig.PodCleanRule{
+				{
+					Name:                   "priority-rule",
+					Enabled:                true,
+					Phase:                  string(corev1.PodSucceeded),
+					TTL:                    cleanupconfig.Duration{Duration: time.Hour},
+					Selector:               metav1.LabelSelector{MatchLabels: map[string]string{"app": "test"}},
+					ExcludePriorityClasses: []string{"gold"},
+				},
+			},
+		},
+	}
+
+	ctx := context.Background()
+	NewPodCleanController(client, scheme, cleanupCfg).RunCleanUp(ctx)
+
+	podList := &corev1.PodList{}
+	if err := client.List(ctx, podList); err != nil {
+		t.Fatalf("Failed to list pods: %v", err)
+	}
+
+	remaining := map[string]bool{}
+	for _, pod := range podList.Items {
+		remaining[pod.Name] = true
+	}
+
+	if !remaining["critical-pod"] || !remaining["gold-pod"] || remaining["plain-pod"] {
+		t.Errorf("Unexpected pods after cleanup: %v", remaining)
+	}
+}
+
+func TestPodCleanupController_NodeScopedRule(t *testing.T) {
+	scheme := runtime.NewScheme()
+	_ = corev1.AddToScheme(scheme)
+
+	newNode := func(name, pool string) *corev1.Node {
+		return &corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: name, Labels: map[string]string{"node-pool": pool}}}
+	}
+
+	newPod := func(name, nodeName string) *corev1.Pod {
+		return &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:              name,
+				Namespace:         "default",
+				CreationTimestamp: metav1.NewTime(time.Now().Add(-2 * time.Hour)),
+			},
+			Spec:   corev1.PodSpec{NodeName: nodeName},
+			Status: corev1.PodStatus{Phase: corev1.PodSucceeded},
+		}
+	}
+
+	client := fake.NewClientBuilder().WithScheme(scheme).WithRuntimeObjects(
+		newNode("spot-1", "spot"),
+		newNode("spot-2", "spot"),
+		newNode("system-1", "system"),
+		newPod("on-spot-1", "spot-1"),
+		newPod("on-spot-2", "spot-2"),
+		newPod("on-system", "system-1"),
+		newPod("unscheduled", ""),
+	).Build()
+
+	cleanupCfg := &cleanupconfig.CleanupConfig{
+		BatchSize: 10,
+		PodCleanupConfig: cleanupconfig.PodCleanupConfig{
+			Enabled: true,
+			Rules: []cleanupconfig.PodCleanRule{
+				{
+					Name:         "spot-only",
+					Enabled:      true,
+					Phase:        string(corev1.PodSucceeded),
+					TTL:          cleanupconfig.Duration{Duration: time.Hour},
+					NodeSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"node-pool": "spot"}},
+					NodeNames:    []string{"spot-1", "system-1"},
+				},
+			},
+		},
+	}
+
+	ctx := context.Background()
+	NewPodCleanController(client, scheme, cleanupCfg).RunCleanUp(ctx)
+
+	podList := &corev1.PodList{}
+	if err := client.List(ctx, podList); err != nil {
+		t.Fatalf("Failed to list pods: %v", err)
+	}
+
+	remaining := map[string]bool{}
+	for _, pod := range podList.Items {
+		remaining[pod.Name] = true
+	}
+
+	if remaining["on-spot-1"] || !remaining["on-spot-2"] || !remaining["on-system"] || !remaining["unscheduled"] {
+		t.Errorf("Unexpected pods after cleanup: %v", remaining)
+	}
+}
+
+// remainingPodNames lists the pods left in the fake cluster.
+func remainingPodNames(t *testing.T, client ctrlclient.Client) map[string]bool {
+	t.Helper()
+
+	podList := &corev1.PodList{}
+	if err := client.List(context.Background(), podList); err != nil {
+		t.Fatalf("Failed to list pods: %v", err)
+	}
+
+	remaining := map[string]bool{}
+	for _, pod := range podList.Items {
+		remaining[pod.Name] = true
+	}
+	return remaining
+}
+
+func TestPodCleanupController_CordonedNodes(t *testing.T) {
+	scheme := runtime.NewScheme()
+	_ = corev1.AddToScheme(scheme)
+
+	newPod := func(name, nodeName string) *corev1.Pod {
+		return &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:              name,
+				Namespace:         "default",
+				Labels:            map[string]string{"app": name},
+				CreationTimestamp: metav1.NewTime(time.Now().Add(-2 * time.Hour)),
+			},
+			Spec:   corev1.PodSpec{NodeName: nodeName},
+			Status: corev1.PodStatus{Phase: corev1.PodSucceeded},
+		}
+	}
+
+	newRule := func(app, mode string) cleanupconfig.PodCleanRule {
+		return cleanupconfig.PodCleanRule{
+			Name:          app,
+			Enabled:       true,
+			Phase:         string(corev1.PodSucceeded),
+			TTL:           cleanupconfig.Duration{Duration: time.Hour},
+			Selector:      metav1.LabelSelector{MatchLabels: map[string]string{"app": app}},
+			CordonedNodes: mode,
+		}
+	}
+
+	client := fake.NewClientBuilder().WithScheme(scheme).WithRuntimeObjects(
+		&corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "healthy"}},
+		&corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "cordoned"}, Spec: corev1.NodeSpec{Unschedulable: true}},
+		&corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "draining"}, Spec: corev1.NodeSpec{
+			Taints: []corev1.Taint{{Key: corev1.TaintNodeUnschedulable, Effect: corev1.TaintEffectNoSchedule}},
+		}},
+		newPod("default-healthy", "healthy"),
+		newPod("default-cordoned", "cordoned"),
+		newPod("only-healthy", "healthy"),
+		newPod("only-draining", "draining"),
+		newPod("include-cordoned", "cordoned"),
+	).Build()
+
+	cleanupCfg := &cleanupconfig.CleanupConfig{
+		BatchSize: 10,
+		PodCleanupConfig: cleanupconfig.PodCleanupConfig{
+			Enabled:           true,
+			SkipCordonedNodes: true,
+			Rules: []cleanupconfig.PodCleanRule{
+				newRule("default-healthy", ""),
+				newRule("default-cordoned", ""),
+				newRule("only-healthy", cleanupconfig.CordonedNodesOnly),
+				newRule("only-draining", cleanupconfig.CordonedNodesOnly),
+				newRule("include-cordoned", cleanupconfig.CordonedNodesInclude),
+			},
+		},
+	}
+
+	NewPodCleanController(client, scheme, cleanupCfg).RunCleanUp(context.Background())
+
+	remaining := remainingPodNames(t, client)
+	expected := map[string]bool{"default-cordoned": true, "only-healthy": true}
+	if len(remaining) != len(expected) || !remaining["default-cordoned"] || !remaining["only-healthy"] {
+		t.Errorf("Unexpected pods after cleanup: %v, expected %v", remaining, expected)
+	}
+}
+
+func TestPodCleanupController_ZoneAndRegionFilters(t *testing.T) {
+	scheme := runtime.NewScheme()
+	_ = corev1.AddToScheme(scheme)
+
+	newNode := func(name, region, zone string) *corev1.Node {
+		return &corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: name, Labels: map[string]string{
+			corev1.LabelTopologyRegion: region,
+			corev1.LabelTopologyZone:   zone,
+		}}}
+	}
+
+	newPod := func(name, nodeName string) *corev1.Pod {
+		return &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:              name,
+				Namespace:         "default",
+				CreationTimestamp: metav1.NewTime(time.Now().Add(-2 * time.Hour)),
+			},
+			Spec:   corev1.PodSpec{NodeName: nodeName},
+			Status: corev1.PodStatus{Phase: corev1.PodSucceeded},
+		}
+	}
+
+	client := fake.NewClientBuilder().WithScheme(scheme).WithRuntimeObjects(
+		newNode("eu-a", "eu-west-1", "eu-west-1a"),
+		newNode("eu-b", "eu-west-1", "eu-west-1b"),
+		newNode("us-a", "us-east-1", "us-east-1a"),
+		newPod("pod-eu-a", "eu-a"),
+		newPod("pod-eu-b", "eu-b"),
+		newPod("pod-us-a", "us-a"),
+	).Build()
+
+	cleanupCfg := &cleanupconfig.CleanupConfig{
+		BatchSize: 10,
+		PodCleanupConfig: cleanupconfig.PodCleanupConfig{
+			Enabled: true,
+			Rules: []cleanupconfig.PodCleanRule{
+				{
+					Name:    "eu-west-1a-rollout",
+					Enabled: true,
+					Phase:   string(corev1.PodSucceeded),
+					TTL:     cleanupconfig.Duration{Duration: time.Hour},
+					Regions: []string{"eu-west-1"},
+					Zones:   []string{"eu-west-1a", "us-east-1a"},
+				},
+			},
+		},
+	}
+
+	NewPodCleanController(client, scheme, cleanupCfg).RunCleanUp(context.Background())
+
+	remaining := remainingPodNames(t, client)
+	if remaining["pod-eu-a"] || !remaining["pod-eu-b"] || !remaining["pod-us-a"] {
+		t.Errorf("Unexpected pods after cleanup: %v", remaining)
+	}
+}
+
+func TestPodCleanupController_NodeDeleted(t *testing.T) {
+	scheme := runtime.NewScheme()
+	_ = corev1.AddToScheme(scheme)
+
+	newPod := func(name, nodeName string, phase corev1.PodPhase) *corev1.Pod {
+		return &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:              name,
+				Namespace:         "default",
+				CreationTimestamp: metav1.NewTime(time.Now().Add(-2 * time.Hour)),
+			},
+			Spec:   corev1.PodSpec{NodeName: nodeName},
+			Status: corev1.PodStatus{Phase: phase},
+		}
+	}
+	lostPod := func(name, nodeName string, lost time.Duration) *corev1.Pod {
+		pod := newPod(name, nodeName, corev1.PodRunning)
+		pod.Status.Reason = "NodeLost"
+		pod.Status.Conditions = []corev1.PodCondition{{Type: corev1.PodReady, Status: corev1.ConditionFalse, LastTransitionTime: metav1.NewTime(time.Now().Add(-lost))}}
+		return pod
+	}
+
+	client := fake.NewClientBuilder().WithScheme(scheme).WithRuntimeObjects(
+		&corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "live"}},
+		newPod("ds-on-live", "live", corev1.PodRunning),
+		lostPod("ds-on-gone", "gone", 90*time.Minute),
+		lostPod("ds-on-just-gone", "just-gone", 10*time.Minute),
+		newPod("job-on-gone", "gone", corev1.PodSucceeded),
+		newPod("unscheduled", "", corev1.PodPending),
+	).Build()
+
+	cleanupCfg := &cleanupconfig.CleanupConfig{
+		BatchSize: 10,
+		PodCleanupConfig: cleanupconfig.PodCleanupConfig{
+			Enabled: true,
+			Rules: []cleanupconfig.PodCleanRule{
+				{
+					Name:        "node-deleted",
+					Enabled:     true,
+					TTL:         cleanupconfig.Duration{Duration: time.Hour},
+					NodeDeleted: true,
+				},
+			},
+		},
+	}
+
+	podCleanController := NewPodCleanController(client, scheme, cleanupCfg)
+	podCleanController.RunCleanUp(context.Background())
+
+	// Pods without a condition telling when their node was lost wait a TTL from when kubeclean
+	// first found the node missing, however old they are.
+	remaining := remainingPodNames(t, client)
+	if remaining["ds-on-gone"] || !remaining["ds-on-just-gone"] || !remaining["job-on-gone"] || !remaining["ds-on-live"] || !remaining["unscheduled"] {
+		t.Errorf("Unexpected pods after the first cleanup: %v", remaining)
+	}
+
+	podCleanController.PodMatcher.missingNodes["gone"] = time.Now().Add(-2 * time.Hour)
+	podCleanController.RunCleanUp(context.Background())
+
+	remaining = remainingPodNames(t, client)
+	if remaining["job-on-gone"] || !remaining["ds-on-just-gone"] {
+		t.Errorf("Unexpected pods after the second cleanup: %v", remaining)
+	}
+}
+
+func TestPodCleanupController_ExcludeSelector(t *testing.T) {
+	scheme := runtime.NewScheme()
+	_ = corev1.AddToScheme(scheme)
+
+	newPod := func(name, tier string) *corev1.Pod {
+		return &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:              name,
+				Namespace:         "default",
+				Labels:            map[string]string{"app": "batch", "tier": tier},
+				CreationTimestamp: metav1.NewTime(time.Now().Add(-2 * time.Hour)),
+			},
+			Status: corev1.PodStatus{Phase: corev1.PodSucceeded},
+		}
+	}
+
+	client := fake.NewClientBuilder().WithScheme(scheme).WithRuntimeObjects(
+		newPod("gold-pod", "gold"),
+		newPod("silver-pod", "silver"),
+	).Build()
+
+	cleanupCfg := &cleanupconfig.CleanupConfig{
+		BatchSize: 10,
+		PodCleanupConfig: cleanupconfig.PodCleanupConfig{
+			Enabled: true,
+			Rules: []cleanupconfig.PodCleanRule{
+				{
+					Name:            "batch-except-gold",
+					Enabled:         true,
+					Phase:           string(corev1.PodSucceeded),
+					TTL:             cleanupconfig.Duration{Duration: time.Hour},
+					Selector:        metav1.LabelSelector{MatchLabels: map[string]string{"app": "batch"}},
+					ExcludeSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"tier": "gold"}},
+				},
+			},
+		},
+	}
+
+	NewPodCleanController(client, scheme, cleanupCfg).RunCleanUp(context.Background())
+
+	remaining := remainingPodNames(t, client)
+	if !remaining["gold-pod"] || remaining["silver-pod"] {
+		t.Errorf("Unexpected pods after cleanup: %v", remaining)
+	}
+}
+
+func TestPodCleanupController_RulePolicy(t *testing.T) {
+	scheme := runtime.NewScheme()
+	_ = corev1.AddToScheme(scheme)
+
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:              "contested-pod",
+			Namespace:         "default",
+			Labels:            map[string]string{"app": "batch"},
+			CreationTimestamp: metav1.NewTime(time.Now().Add(-2 * time.Hour)),
+		},
+		Status: corev1.PodStatus{Phase: corev1.PodFailed},
+	}
+
+	rules := []cleanupconfig.PodCleanRule{
+		{
+			Name:    "delete-failed",
+			Enabled: true,
+			Phase:   string(corev1.PodFailed),
+			TTL:     cleanupconfig.Duration{Duration: time.Hour},
+		},
+		{
+			Name:     "quarantine-batch",
+			Enabled:  true,
+			Priority: 10,
+			Phase:    string(corev1.PodFailed),
+			TTL:      cleanupconfig.Duration{Duration: time.Hour},
+			Selector: metav1.LabelSelector{MatchLabels: map[string]string{"app": "batch"}},
+		},
+	}
+
+	tests := []struct {
+		policy   string
+		expected map[string]int
+	}{
+		{policy: cleanupconfig.RulePolicyAllMatch, expected: map[string]int{"quarantine-batch": 1, "delete-failed": 1}},
+		{policy: cleanupconfig.RulePolicyFirstMatch, expected: map[string]int{"quarantine-batch": 1}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.policy, func(t *testing.T) {
+			client := fake.NewClientBuilder().WithScheme(scheme).WithRuntimeObjects(pod.DeepCopy()).Build()
+
+			cleanupCfg := &cleanupconfig.CleanupConfig{
+				BatchSize: 10,
+				DryRun:    true,
+				PodCleanupConfig: cleanupconfig.PodCleanupConfig{
+					Enabled:    true,
+					RulePolicy: tt.policy,
+					Rules:      rules,
+				},
+			}
+
+			summary := NewPodCleanController(client, scheme, cleanupCfg).RunCleanUp(context.Background())
+			if len(summary.MatchedByRule) != len(tt.expected) {
+				t.Fatalf("Unexpected rule matches: %v, expected %v", summary.MatchedByRule, tt.expected)
+			}
+			for name, count := range tt.expected {
+				if summary.MatchedByRule[name] != count {
+					t.Errorf("Unexpected rule matches: %v, expected %v", summary.MatchedByRule, tt.expected)
+				}
+			}
+		})
+	}
+}
+
+func TestPlanRules_FirstMatchReleasesDeferredPods(t *testing.T) {
+	scheme := runtime.NewScheme()
+	_ = corev1.AddToScheme(scheme)
+
+	var objects []runtime.Object
+	for _, name := range []string{"a", "b"} {
+		objects = append(objects, &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default", CreationTimestamp: metav1.NewTime(time.Now().Add(-2 * time.Hour))},
+			Status:     corev1.PodStatus{Phase: corev1.PodFailed},
+		})
+	}
+	client := fake.NewClientBuilder().WithScheme(scheme).WithRuntimeObjects(objects...).Build()
+
+	cfg := &cleanupconfig.CleanupConfig{
+		MaxDeletionsPerRun: 1,
+		PodCleanupConfig: cleanupconfig.PodCleanupConfig{
+			Enabled:    true,
+			RulePolicy: cleanupconfig.RulePolicyFirstMatch,
+			Rules: []cleanupconfig.PodCleanRule{
+				{Name: "delete-failed", Enabled: true, Priority: 10, Phase: "Failed", TTL: cleanupconfig.Duration{Duration: time.Hour}},
+				{Name: "quarantine-failed", Enabled: true, Phase: "Failed", TTL: cleanupconfig.Duration{Duration: time.Hour},
+					Action: cleanupconfig.ActionConfig{Type: cleanupconfig.ActionLabelQuarantine}},
+			},
+		},
+	}
+
+	plans := planRules(context.Background(), NewPodMatcher(client), cfg, nil)
+	if len(plans) != 2 || len(plans[0].Selected) != 1 || len(plans[0].Deferred) != 1 {
+		t.Fatalf("Expected the delete rule to select one pod and defer the other, got %+v", plans)
+	}
+	if len(plans[1].Selected) != 1 || plans[1].Selected[0].Name != plans[0].Deferred[0].Name {
+		t.Errorf("Expected the quarantine rule to take the deferred pod, got %v", plans[1].Selected)
+	}
+}
+
+func TestOrderedRules_PriorityThenFileOrder(t *testing.T) {
+	rules := []cleanupconfig.PodCleanRule{
+		{Name: "a"},
+		{Name: "b", Priority: 5},
+		{Name: "c"},
+		{Name: "d", Priority: 5},
+	}
+
+	var names []string
+	for _, rule := range orderedRules(rules) {
+		names = append(names, rule.Name)
+	}
+
+	if !slices.Equal(names, []string{"b", "d", "a", "c"}) {
+		t.Errorf("Unexpected rule order: %v", names)
+	}
+}
+
+func TestOrderedRules_After(t *testing.T) {
+	rules := []cleanupconfig.PodCleanRule{
+		{Name: "jobs", Priority: 10, After: []string{"pods"}},
+		{Name: "pods"},
+		{Name: "evicted", Priority: 5},
+		{Name: "cleanup", Priority: 20, After: []string{"jobs", "unknown"}},
+	}
+
+	var names []string
+	for _, rule := range orderedRules(rules) {
+		names = append(names, rule.Name)
+	}
+
+	// Rules without pending predecessors go by priority; unknown names do not hold a rule back.
+	if !slices.Equal(names, []string{"evicted", "pods", "jobs", "cleanup"}) {
+		t.Errorf("Unexpected rule order: %v", names)
+	}
+}
+
+func TestPodCleanupController_NotificationSinkOverrides(t *testing.T) {
+	scheme := runtime.NewScheme()
+	_ = corev1.AddToScheme(scheme)
+
+	received := map[string]int{}
+	var mu sync.Mutex
+	newSink := func(name string) *httptest.Server {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			mu.Lock()
+			received[name]++
+			mu.Unlock()
+		}))
+		t.Cleanup(server.Close)
+		return server
+	}
+
+	storage := newSink("storage-oncall")
+	chat := newSink("chat")
+
+	newPod := func(name, app string) *corev1.Pod {
+		return &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:              name,
+				Namespace:         "default",
+				Labels:            map[string]string{"app": app},
+				CreationTimestamp: metav1.NewTime(time.Now().Add(-2 * time.Hour)),
+			},
+			Status: corev1.PodStatus{Phase: corev1.PodSucceeded},
+		}
+	}
+
+	client := fake.NewClientBuilder().WithScheme(scheme).WithRuntimeObjects(
+		newPod("storage-pod", "storage"),
+		newPod("web-pod", "web"),
+	).Build()
+
+	newRule := func(app string, sinks []string) cleanupconfig.PodCleanRule {
+		return cleanupconfig.PodCleanRule{
+			Name:              app,
+			Enabled:           true,
+			Phase:             string(corev1.PodSucceeded),
+			TTL:               cleanupconfig.Duration{Duration: time.Hour},
+			Selector:          metav1.LabelSelector{MatchLabels: map[string]string{"app": app}},
+			NotificationSinks: sinks,
+		}
+	}
+
+	cleanupCfg := &cleanupconfig.CleanupConfig{
+		BatchSize: 10,
+		DryRun:    true,
+		Notifications: cleanupconfig.NotificationConfig{
+			Sinks: []cleanupconfig.NotificationSink{
+				{Name: "storage-oncall", Type: cleanupconfig.SinkTypeWebhook, URL: storage.URL},
+				{Name: "chat", Type: cleanupconfig.SinkTypeSlack, URL: chat.URL},
+			},
+		},
+		PodCleanupConfig: cleanupconfig.PodCleanupConfig{
+			Enabled: true,
+			Rules: []cleanupconfig.PodCleanRule{
+				newRule("storage", []string{"storage-oncall"}),
+				newRule("web", nil),
+			},
+		},
+	}
+
+	NewPodCleanController(client, scheme, cleanupCfg).RunCleanUp(context.Background())
+
+	mu.Lock()
+	defer mu.Unlock()
+	if received["storage-oncall"] != 2 || received["chat"] != 1 {
+		t.Errorf("Unexpected notifications: %v", received)
+	}
+}
+
+func TestPodCleanupController_NotificationTemplatesReadRunSummary(t *testing.T) {
+	scheme := runtime.NewScheme()
+	_ = corev1.AddToScheme(scheme)
+
+	var mu sync.Mutex
+	var bodies []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		mu.Lock()
+		bodies = append(bodies, string(body))
+		mu.Unlock()
+	}))
+	t.Cleanup(server.Close)
+
+	newPod := func(name string, phase corev1.PodPhase) *corev1.Pod {
+		return &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default", CreationTimestamp: metav1.NewTime(time.Now().Add(-2 * time.Hour))},
+			Status:     corev1.PodStatus{Phase: phase},
+		}
+	}
+	client := fake.NewClientBuilder().WithScheme(scheme).WithRuntimeObjects(
+		newPod("succeeded", corev1.PodSucceeded), newPod("failed-1", corev1.PodFailed), newPod("failed-2", corev1.PodFailed),
+	).Build()
+
+	cfg := &cleanupconfig.CleanupConfig{
+		DryRun: true,
+		Notifications: cleanupconfig.NotificationConfig{Sinks: []cleanupconfig.NotificationSink{{
+			Name: "hook", Type: cleanupconfig.SinkTypeWebhook, URL: server.URL,
+			Template: `{"text": "{{.Rule}}: {{.Pods}} of {{.Summary.Matched}} in run {{.Summary.RunID}}"}`,
+		}}},
+		PodCleanupConfig: cleanupconfig.PodCleanupConfig{Enabled: true, Rules: []cleanupconfig.PodCleanRule{
+			{Name: "failed", Enabled: true, Phase: string(corev1.PodFailed), TTL: cleanupconfig.Duration{Duration: time.Hour}},
+		}},
+	}
+	summary := NewPodCleanController(client, scheme, cfg).RunCleanUp(context.Background())
+
+	mu.Lock()
+	defer mu.Unlock()
+	want := `{"text": "failed: 2 of 2 in run ` + summary.RunID + `"}`
+	if len(bodies) != 1 || bodies[0] != want {
+		t.Errorf("Expected the notification %s, got %v", want, bodies)
+	}
+}
+
+func TestPodCleanupController_NotificationsCountOnlyDeletedPods(t *testing.T) {
+	scheme := runtime.NewScheme()
+	_ = corev1.AddToScheme(scheme)
+
+	var events []map[string]any
+	var mu sync.Mutex
+	sink := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var event map[string]any
+		_ = json.NewDecoder(r.Body).Decode(&event)
+		mu.Lock()
+		events = append(events, event)
+		mu.Unlock()
+	}))
+	t.Cleanup(sink.Close)
+
+	newPod := func(name string) *corev1.Pod {
+		return &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default", CreationTimestamp: metav1.NewTime(time.Now().Add(-2 * time.Hour))},
+			Status:     corev1.PodStatus{Phase: corev1.PodFailed},
+		}
+	}
+	client := fake.NewClientBuilder().WithScheme(scheme).WithRuntimeObjects(newPod("stuck"), newPod("gone")).
+		WithInterceptorFuncs(interceptor.Funcs{
+			Delete: func(ctx context.Context, client ctrlclient.WithWatch, obj ctrlclient.Object, opts ...ctrlclient.DeleteOption) error {
+				if obj.GetName() == "stuck" {
+					return apierrors.NewForbidden(schema.GroupResource{Resource: "pods"}, obj.GetName(), errors.New("denied"))
+				}
+				return client.Delete(ctx, obj, opts...)
+			},
+		}).Build()
+
+	cfg := &cleanupconfig.CleanupConfig{
+		BatchSize: 10,
+		Notifications: cleanupconfig.NotificationConfig{Sinks: []cleanupconfig.NotificationSink{
+			{Name: "hook", Type: cleanupconfig.SinkTypeWebhook, URL: sink.URL},
+		}},
+		PodCleanupConfig: cleanupconfig.PodCleanupConfig{Enabled: true, Rules: []cleanupconfig.PodCleanRule{
+			{Name: "failed", Enabled: true, Phase: string(corev1.PodFailed), TTL: cleanupconfig.Duration{Duration: time.Hour}},
+		}},
+	}
+	NewPodCleanController(client, scheme, cfg).RunCleanUp(context.Background())
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(events) != 1 {
+		t.Fatalf("Expected one notification, got %v", events)
+	}
+	if event := events[0]; event["pods"] != float64(1) || event["failed"] != float64(1) || event["message"] != "Deleted 1 pod(s) across 1 standalone pod(s) for rule failed; 1 failed" {
+		t.Errorf("Expected the notification to count the failed deletion apart, got %v", event)
+	}
+}
+
+func TestRunScheduled_OverlapPolicy(t *testing.T) {
+	scheme := runtime.NewScheme()
+	_ = corev1.AddToScheme(scheme)
+	client := fake.NewClientBuilder().WithScheme(scheme).Build()
+
+	cfg := &cleanupconfig.CleanupConfig{OverlapPolicy: cleanupconfig.OverlapPolicySkip}
+	controller := NewPodCleanController(client, scheme, cfg)
+	ctx := context.Background()
+
+	// A triggered run holds the run lock.
+	controller.runMu.Lock()
+	controller.runScheduled(ctx, time.Now())
+	controller.runMu.Unlock()
+	if runs := controller.History(0); len(runs) != 0 {
+		t.Fatalf("Expected the scheduled run to be skipped while another run is active, got %d run(s)", len(runs))
+	}
+
+	controller.runScheduled(ctx, time.Now())
+	finished := controller.History(1)[0].Finished
+
+	// A tick that fired while the previous run was still active is skipped.
+	controller.runScheduled(ctx, finished.Add(-time.Second))
+	if runs := controller.History(0); len(runs) != 1 {
+		t.Errorf("Expected a tick from during the previous run to be skipped, got %d run(s)", len(runs))
+	}
+
+	cfg.OverlapPolicy = cleanupconfig.OverlapPolicyQueue
+	controller.runScheduled(ctx, finished.Add(-time.Second))
+	if runs := controller.History(0); len(runs) != 2 {
+		t.Errorf("Expected the queue policy to run late ticks, got %d run(s)", len(runs))
+	}
+}
+
+func TestPodCleanupController_RunIDCorrelation(t *testing.T) {
+	scheme := runtime.NewScheme()
+	_ = corev1.AddToScheme(scheme)
+
+	var mu sync.Mutex
+	var notifiedRunIDs []string
+	sink := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var event struct {
+			RunID string `json:"runID"`
+		}
+		_ = json.NewDecoder(r.Body).Decode(&event)
+		mu.Lock()
+		notifiedRunIDs = append(notifiedRunIDs, event.RunID)
+		mu.Unlock()
+	}))
+	defer sink.Close()
+
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:              "traced-pod",
+			Namespace:         "default",
+			CreationTimestamp: metav1.NewTime(time.Now().Add(-2 * time.Hour)),
+		},
+		Status: corev1.PodStatus{Phase: corev1.PodSucceeded},
+	}
+
+	client := fake.NewClientBuilder().WithScheme(scheme).WithRuntimeObjects(pod).Build()
+
+	cleanupCfg := &cleanupconfig.CleanupConfig{
+		BatchSize: 10,
+		DryRun:    true,
+		Notifications: cleanupconfig.NotificationConfig{
+			Sinks: []cleanupconfig.NotificationSink{{Name: "hook", Type: cleanupconfig.SinkTypeWebhook, URL: sink.URL}},
+		},
+		PodCleanupConfig: cleanupconfig.PodCleanupConfig{
+			Enabled: true,
+			Rules: []cleanupconfig.PodCleanRule{{
+				Name:    "traced",
+				Enabled: true,
+				Phase:   string(corev1.PodSucceeded),
+				TTL:     cleanupconfig.Duration{Duration: time.Hour},
+			}},
+		},
+	}
+
+	controller := NewPodCleanController(client, scheme, cleanupCfg)
+	first := controller.RunCleanUp(context.Background())
+	second := controller.RunCleanUp(context.Background())
+
+	if first.RunID == "" || first.RunID == second.RunID {
+		t.Errorf("Expected unique run IDs, got %q and %q", first.RunID, second.RunID)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if !slices.Equal(notifiedRunIDs, []string{first.RunID, second.RunID}) {
+		t.Errorf("Notifications should carry the run ID, got %v", notifiedRunIDs)
+	}
+}
+
+func TestPodCleanupController_WarmupRuns(t *testing.T) {
+	scheme := runtime.NewScheme()
+	_ = corev1.AddToScheme(scheme)
+
+	newPod := func(name string) *corev1.Pod {
+		return &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:              name,
+				Namespace:         "default",
+				CreationTimestamp: metav1.NewTime(time.Now().Add(-2 * time.Hour)),
+			},
+			Status: corev1.PodStatus{Phase: corev1.PodSucceeded},
+		}
+	}
+
+	client := fake.NewClientBuilder().WithScheme(scheme).WithRuntimeObjects(newPod("first")).Build()
+
+	cleanupCfg := &cleanupconfig.CleanupConfig{
+		BatchSize:  10,
+		WarmupRuns: 1,
+		PodCleanupConfig: cleanupconfig.PodCleanupConfig{
+			Enabled: true,
+			Rules: []cleanupconfig.PodCleanRule{
+				{Name: "succeeded", Enabled: true, Phase: "Succeeded", TTL: cleanupconfig.Duration{Duration: time.Hour}},
+			},
+		},
+	}
+
+	controller := NewPodCleanController(client, scheme, cleanupCfg)
+
+	controller.RunCleanUp(context.Background())
+	if !remainingPodNames(t, client)["first"] {
+		t.Fatalf("Expected the warm-up run not to delete pods")
+	}
+
+	controller.RunCleanUp(context.Background())
+	if remainingPodNames(t, client)["first"] {
+		t.Fatalf("Expected the run after warm-up to delete pods")
+	}
+
+	// A config change starts a new warm-up period.
+	if err := client.Create(context.Background(), newPod("second")); err != nil {
+		t.Fatalf("Failed to create pod: %v", err)
+	}
+	changed := *cleanupCfg
+	changed.BatchSize = 5
+	controller.CleanupConfig = &changed
+
+	controller.RunCleanUp(context.Background())
+	if !remainingPodNames(t, client)["second"] {
+		t.Errorf("Expected the run after a config change not to delete pods")
+	}
+}
+
+func TestPodCleanupController_CountsDeleteFailures(t *testing.T) {
+	scheme := runtime.NewScheme()
+	_ = corev1.AddToScheme(scheme)
+
+	newPod := func(name string) *corev1.Pod {
+		return &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:              name,
+				Namespace:         "default",
+				CreationTimestamp: metav1.NewTime(time.Now().Add(-2 * time.Hour)),
+			},
+			Status: corev1.PodStatus{Phase: corev1.PodFailed},
+		}
+	}
+
+	client := fake.NewClientBuilder().WithScheme(scheme).WithRuntimeObjects(newPod("protected"), newPod("deletable")).
+		WithInterceptorFuncs(interceptor.Funcs{
+			Delete: func(ctx context.Context, c ctrlclient.WithWatch, obj ctrlclient.Object, opts ...ctrlclient.DeleteOption) error {
+				if obj.GetName() == "protected" {
+					return apierrors.NewForbidden(corev1.Resource("pods"), obj.GetName(), errors.New("denied by webhook"))
+				}
+				return c.Delete(ctx, obj, opts...)
+			},
+		}).Build()
+
+	cleanupCfg := &cleanupconfig.CleanupConfig{
+		BatchSize: 10,
+		PodCleanupConfig: cleanupconfig.PodCleanupConfig{
+			Enabled: true,
+			Rules: []cleanupconfig.PodCleanRule{
+				{Name: "failed", Enabled: true, Phase: "Failed", TTL: cleanupconfig.Duration{Duration: time.Hour}},
+			},
+		},
+	}
+
+	summary := NewPodCleanController(client, scheme, cleanupCfg).RunCleanUp(context.Background())
+
+	if summary.DeleteFailures != 1 {
+		t.Errorf("Expected 1 delete failure, got %d", summary.DeleteFailures)
+	}
+	if remaining := remainingPodNames(t, client); remaining["deletable"] || !remaining["protected"] {
+		t.Errorf("Unexpected pods after cleanup: %v", remaining)
+	}
+}
+
+func TestPodCleanupController_NamespaceSelector(t *testing.T) {
+	scheme := runtime.NewScheme()
+	_ = corev1.AddToScheme(scheme)
+
+	newNamespace := func(name, env string) *corev1.Namespace {
+		return &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: name, Labels: map[string]string{"env": env}}}
+	}
+	newPod := func(name, namespace string) *corev1.Pod {
+		return &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:              name,
+				Namespace:         namespace,
+				CreationTimestamp: metav1.NewTime(time.Now().Add(-2 * time.Hour)),
+			},
+			Status: corev1.PodStatus{Phase: corev1.PodSucceeded},
+		}
+	}
+
+	client := fake.NewClientBuilder().WithScheme(scheme).WithRuntimeObjects(
+		newNamespace("pr-1", "ephemeral"),
+		newNamespace("pr-2", "ephemeral"),
+		newNamespace("prod", "production"),
+		newPod("pr-1-pod", "pr-1"),
+		newPod("pr-2-pod", "pr-2"),
+		newPod("prod-pod", "prod"),
+	).Build()
+
+	cleanupCfg := &cleanupconfig.CleanupConfig{
+		BatchSize: 10,
+		PodCleanupConfig: cleanupconfig.PodCleanupConfig{
+			Enabled: true,
+			Rules: []cleanupconfig.PodCleanRule{
+				{
+					Name:              "ephemeral",
+					Enabled:           true,
+					Phase:             "Succeeded",
+					TTL:               cleanupconfig.Duration{Duration: time.Hour},
+					NamespaceSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"env": "ephemeral"}},
+				},
+			},
+		},
+	}
+
+	NewPodCleanController(client, scheme, cleanupCfg).RunCleanUp(context.Background())
+
+	remaining := remainingPodNames(t, client)
+	if remaining["pr-1-pod"] || remaining["pr-2-pod"] || !remaining["prod-pod"] {
+		t.Errorf("Unexpected pods after cleanup: %v", remaining)
+	}
+}
+
+func TestPodCleanupController_SkipsTerminatingNamespaces(t *testing.T) {
+	scheme := runtime.NewScheme()
+	_ = corev1.AddToScheme(scheme)
+
+	newPod := func(name, namespace string) *corev1.Pod {
+		return &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:              name,
+				Namespace:         namespace,
+				CreationTimestamp: metav1.NewTime(time.Now().Add(-2 * time.Hour)),
+			},
+			Status: corev1.PodStatus{Phase: corev1.PodSucceeded},
+		}
+	}
+
+	deleted := metav1.Now()
+	client := fake.NewClientBuilder().WithScheme(scheme).WithRuntimeObjects(
+		&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "active"}},
+		&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "terminating", DeletionTimestamp: &deleted, Finalizers: []string{"kubernetes"}}},
+		newPod("active-pod", "active"),
+		newPod("terminating-pod", "terminating"),
+	).Build()
+
+	rule := cleanupconfig.PodCleanRule{Name: "succeeded", Enabled: true, Phase: "Succeeded", TTL: cleanupconfig.Duration{Duration: time.Hour}}
+
+	pods, err := NewPodMatcher(client).FindPodsToCleanup(context.Background(), rule)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if len(pods) != 1 || pods[0].Name != "active-pod" {
+		t.Errorf("Expected only active-pod to be selected, got %v", pods)
+	}
+}
+
+func TestPodCleanupController_NamespaceLookupFailuresSkipOnlyTheirPods(t *testing.T) {
+	scheme := runtime.NewScheme()
+	_ = corev1.AddToScheme(scheme)
+
+	newPod := func(name, namespace string) *corev1.Pod {
+		return &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:              name,
+				Namespace:         namespace,
+				CreationTimestamp: metav1.NewTime(time.Now().Add(-2 * time.Hour)),
+			},
+			Status: corev1.PodStatus{Phase: corev1.PodSucceeded},
+		}
+	}
+
+	namespacesResource := schema.GroupResource{Resource: "namespaces"}
+	gets := map[string]int{}
+	client := fake.NewClientBuilder().WithScheme(scheme).WithRuntimeObjects(
+		&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "readable"}},
+		&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "hidden"}},
+		newPod("readable-pod", "readable"),
+		newPod("hidden-pod-a", "hidden"),
+		newPod("hidden-pod-b", "hidden"),
+	).WithInterceptorFuncs(interceptor.Funcs{
+		List: func(ctx context.Context, c ctrlclient.WithWatch, list ctrlclient.ObjectList, opts ...ctrlclient.ListOption) error {
+			if _, ok := list.(*corev1.NamespaceList); ok {
+				return apierrors.NewForbidden(namespacesResource, "", nil)
+			}
+			return c.List(ctx, list, opts...)
+		},
+		Get: func(ctx context.Context, c ctrlclient.WithWatch, key ctrlclient.ObjectKey, obj ctrlclient.Object, opts ...ctrlclient.GetOption) error {
+			if _, ok := obj.(*corev1.Namespace); ok {
+				gets[key.Name]++
+				if key.Name == "hidden" {
+					return apierrors.NewForbidden(namespacesResource, key.Name, nil)
+				}
+			}
+			return c.Get(ctx, key, obj, opts...)
+		},
+	}).Build()
+
+	rule := cleanupconfig.PodCleanRule{Name: "succeeded", Enabled: true, Phase: "Succeeded", TTL: cleanupconfig.Duration{Duration: time.Hour}}
+
+	pods, err := NewPodMatcher(client).FindPodsToCleanup(context.Background(), rule)
+	if reasons := ErrorReasons(err); reasons[ErrorReasonForbidden] != 1 {
+		t.Errorf("Expected the hidden namespace to be reported once, got %v", err)
+	}
+	if len(pods) != 1 || pods[0].Name != "readable-pod" {
+		t.Errorf("Expected only readable-pod to be selected, got %d pod(s)", len(pods))
+	}
+	if gets["hidden"] != 1 || gets["readable"] != 1 {
+		t.Errorf("Expected each namespace to be read once, got %v", gets)
+	}
+}
+
+func TestPodCleanupController_InvalidAnnotationPolicy(t *testing.T) {
+	scheme := runtime.NewScheme()
+	_ = corev1.AddToScheme(scheme)
+
+	newPod := func(name string, annotations map[string]string) *corev1.Pod {
+		return &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:              name,
+				Namespace:         "default",
+				Annotations:       annotations,
+				CreationTimestamp: metav1.NewTime(time.Now().Add(-2 * time.Hour)),
+			},
+			Status: corev1.PodStatus{Phase: corev1.PodSucceeded},
+		}
+	}
+
+	client := fake.NewClientBuilder().WithScheme(scheme).WithRuntimeObjects(
+		newPod("valid", nil),
+		newPod("malformed", map[string]string{AnnotationTTL: "soon"}),
+	).Build()
+
+	tests := []struct {
+		policy      string
+		expected    []string
+		expectError bool
+	}{
+		{policy: "", expected: []string{"malformed", "valid"}},
+		{policy: cleanupconfig.InvalidAnnotationUseRuleTTL, expected: []string{"malformed", "valid"}},
+		{policy: cleanupconfig.InvalidAnnotationSkip, expected: []string{"valid"}},
+		{policy: cleanupconfig.InvalidAnnotationFail, expectError: true},
+	}
+
+	for _, tt := range tests {
+		t.Run("policy "+tt.policy, func(t *testing.T) {
+			rule := cleanupconfig.PodCleanRule{
+				Name:                    "succeeded",
+				Enabled:                 true,
+				Phase:                   "Succeeded",
+				TTL:                     cleanupconfig.Duration{Duration: time.Hour},
+				InvalidAnnotationPolicy: tt.policy,
+			}
+
+			pods, err := NewPodMatcher(client).FindPodsToCleanup(context.Background(), rule)
+			if tt.expectError {
+				if ErrorReasons(err)[ErrorReasonInvalidAnnotation] != 1 {
+					t.Errorf("Expected an InvalidAnnotation error, got %v", err)
+				}
+				if len(pods) != 0 {
+					t.Errorf("Expected no pods when the rule fails, got %d", len(pods))
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+
+			var names []string
+			for _, pod := range pods {
+				names = append(names, pod.Name)
+			}
+			slices.Sort(names)
+			if !slices.Equal(names, tt.expected) {
+				t.Errorf("Expected pods %v, got %v", tt.expected, names)
+			}
+		})
+	}
+}
+
+func TestPodCleanupController_ConstraintsForbidNamespaces(t *testing.T) {
+	scheme := runtime.NewScheme()
+	_ = corev1.AddToScheme(scheme)
+
+	newPod := func(name, namespace string) *corev1.Pod {
+		return &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:              name,
+				Namespace:         namespace,
+				CreationTimestamp: metav1.NewTime(time.Now().Add(-2 * time.Hour)),
+			},
+			Status: corev1.PodStatus{Phase: corev1.PodSucceeded},
+		}
+	}
+
+	client := fake.NewClientBuilder().WithScheme(scheme).WithRuntimeObjects(
+		newPod("app", "default"),
+		newPod("system", "kube-system"),
+	).Build()
+
+	controller := NewPodCleanController(client, scheme, &cleanupconfig.CleanupConfig{
+		PodCleanupConfig: cleanupconfig.PodCleanupConfig{
+			Enabled: true,
+			Rules: []cleanupconfig.PodCleanRule{
+				{Name: "everywhere", Enabled: true, Phase: "Succeeded", TTL: cleanupconfig.Duration{Duration: time.Hour}},
+				{Name: "system", Enabled: true, Phase: "Succeeded", TTL: cleanupconfig.Duration{Duration: time.Hour}, Namespaces: []string{"kube-system"}},
+			},
+		},
+		Constraints: cleanupconfig.ConstraintsConfig{ForbiddenNamespaces: []string{"kube-system"}},
+	})
+
+	summary := controller.RunCleanUp(context.Background())
+	if summary.Matched != 1 || summary.MatchedByRule["everywhere"] != 1 {
+		t.Errorf("Expected only the default namespace pod to match, got %v", summary.MatchedByRule)
+	}
+
+	if status, _ := controller.RuleStatus(RuleKindPod, "system"); status.LastError == "" {
+		t.Errorf("Expected the rule targeting a forbidden namespace to report an error, got %+v", status)
+	}
+
+	var pod corev1.Pod
+	if err := client.Get(context.Background(), types.NamespacedName{Namespace: "kube-system", Name: "system"}, &pod); err != nil {
+		t.Errorf("Expected the kube-system pod to be kept: %v", err)
+	}
+}
+
+func TestPodCleanupController_RecommendedDefaults(t *testing.T) {
+	scheme := runtime.NewScheme()
+	_ = corev1.AddToScheme(scheme)
+
+	newNamespace := func(name string) *corev1.Namespace {
+		return &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: name, Labels: map[string]string{corev1.LabelMetadataName: name}}}
+	}
+	newPod := func(name, namespace string, phase corev1.PodPhase, reason string, age time.Duration) *corev1.Pod {
+		return &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:              name,
+				Namespace:         namespace,
+				CreationTimestamp: metav1.NewTime(time.Now().Add(-age)),
+			},
+			Status: corev1.PodStatus{Phase: phase, Reason: reason},
+		}
+	}
+
+	client := fake.NewClientBuilder().WithScheme(scheme).WithRuntimeObjects(
+		newNamespace("default"),
+		newNamespace("kube-system"),
+		newPod("succeeded-day-old", "default", corev1.PodSucceeded, "", 25*time.Hour),
+		newPod("succeeded-recent", "default", corev1.PodSucceeded, "", 2*time.Hour),
+		newPod("system-succeeded", "kube-system", corev1.PodSucceeded, "", 25*time.Hour),
+		newPod("failed-recent", "default", corev1.PodFailed, "Error", 25*time.Hour),
+		newPod("evicted", "default", corev1.PodFailed, "Evicted", 2*time.Hour),
+	).Build()
+
+	cleanupCfg := &cleanupconfig.CleanupConfig{
+		BatchSize: 10,
+		PodCleanupConfig: cleanupconfig.PodCleanupConfig{
+			Enabled:                true,
+			UseRecommendedDefaults: true,
+		},
+	}
+
+	NewPodCleanController(client, scheme, cleanupCfg).RunCleanUp(context.Background())
+
+	remaining := remainingPodNames(t, client)
+	if remaining["succeeded-day-old"] || remaining["evicted"] {
+		t.Errorf("Expected the recommended rules to clean up old and evicted pods, got %v", remaining)
+	}
+	if !remaining["succeeded-recent"] || !remaining["system-succeeded"] || !remaining["failed-recent"] {
+		t.Errorf("Expected recent pods and system namespaces to be kept, got %v", remaining)
+	}
+}
@@ -0,0 +1,79 @@
+package controller
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+// Preview returns the pods every enabled rule currently matches, in rule order, without acting on
+// any of them. Pods deferred by deletion budgets are included, and pods whose deletion would hang
+// on finalizers or be intercepted by validating webhooks carry warnings.
+func (c *PodCleanController) Preview(ctx context.Context) []PodRef {
+	cfg, _ := c.withTenantRules(ctx)
+	matcher := c.newMatcher()
+	plans := planRules(ctx, matcher, cfg, c.overrides)
+	resolver := newOwnerResolver(c.Client)
+	checker := newDeletionChecker(matcher)
+
+	var refs []PodRef
+	for _, plan := range plans {
+		for deferred, pods := range [][]corev1.Pod{plan.Selected, plan.Deferred} {
+			for i := range pods {
+				pod := &pods[i]
+				ref := PodRef{Rule: plan.Rule.Name, Namespace: pod.Namespace, Name: pod.Name, UID: pod.UID,
+					ReasonCode: matcher.matchCode(plan.Rule.Name, pod, deferred == 1)}
+				if owner := resolver.resolve(ctx, pod); owner.Kind != "" {
+					ref.Owner = owner.String()
+				}
+				ref.Warnings = checker.warnings(ctx, pod)
+				refs = append(refs, ref)
+			}
+		}
+	}
+
+	return refs
+}
+
+// DeletePods deletes the referenced pods and returns how many were deleted. A pod is only
+// deleted if it still has the UID it was previewed with, so pods recreated under the same name
+// are left alone. The config's dry-run setting is honored.
+func (c *PodCleanController) DeletePods(ctx context.Context, refs []PodRef) (int, error) {
+	logger := log.FromContext(ctx)
+
+	var deleted int
+	var errs []error
+
+	for _, ref := range refs {
+		if c.CleanupConfig.DryRun {
+			logger.Info("DRY RUN: Would delete pod", "pod", ref.Name, "namespace", ref.Namespace, "rule", ref.Rule)
+			continue
+		}
+
+		pod := &corev1.Pod{}
+		pod.Namespace, pod.Name = ref.Namespace, ref.Name
+
+		var opts []client.DeleteOption
+		if ref.UID != "" {
+			opts = append(opts, client.Preconditions{UID: &ref.UID})
+		}
+
+		logger.Info("Deleting pod", "pod", ref.Name, "namespace", ref.Namespace, "rule", ref.Rule)
+		err := withThrottleRetry(ctx, "delete", func() error {
+			return c.Client.Delete(withRule(ctx, ref.Rule), pod, opts...)
+		})
+		if client.IgnoreNotFound(err) != nil {
+			errs = append(errs, fmt.Errorf("delete pod %s/%s: %w", ref.Namespace, ref.Name, err))
+			continue
+		}
+		if err == nil {
+			deleted++
+		}
+	}
+
+	return deleted, errors.Join(errs...)
+}
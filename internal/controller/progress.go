@@ -0,0 +1,107 @@
+package controller
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// ProgressEvent reports the progress of a cleanup pass: one event per deleted batch of pods,
+// followed by a final event with Done set once the pass completes.
+type ProgressEvent struct {
+	RunID   string    `json:"runID"`
+	Rule    string    `json:"rule,omitempty"`
+	Deleted int       `json:"deleted"` // Pods of the rule processed so far in this run.
+	Total   int       `json:"total"`   // Pods the rule selected in this run.
+	DryRun  bool      `json:"dryRun,omitempty"`
+	Done    bool      `json:"done,omitempty"` // The pass completed; Summary is set.
+	Time    time.Time `json:"time"`
+
+	Summary *RunSummary `json:"summary,omitempty"`
+}
+
+// progressBufferSize bounds the events queued for a slow subscriber before newer ones are dropped.
+const progressBufferSize = 64
+
+// progressHub fans progress events out to subscribers. Publishing never blocks a cleanup pass.
+type progressHub struct {
+	mu          sync.Mutex
+	subscribers map[chan ProgressEvent]struct{}
+}
+
+func newProgressHub() *progressHub {
+	return &progressHub{subscribers: map[chan ProgressEvent]struct{}{}}
+}
+
+// subscribe returns a channel receiving every later event and a function ending the subscription.
+func (h *progressHub) subscribe() (<-chan ProgressEvent, func()) {
+	ch := make(chan ProgressEvent, progressBufferSize)
+
+	h.mu.Lock()
+	h.subscribers[ch] = struct{}{}
+	h.mu.Unlock()
+
+	return ch, func() {
+		h.mu.Lock()
+		defer h.mu.Unlock()
+		if _, ok := h.subscribers[ch]; ok {
+			delete(h.subscribers, ch)
+			close(ch)
+		}
+	}
+}
+
+// publish delivers event to every subscriber with room in its buffer.
+func (h *progressHub) publish(event ProgressEvent) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	for ch := range h.subscribers {
+		select {
+		case ch <- event:
+		default:
+		}
+	}
+}
+
+// SubscribeProgress returns a channel receiving the progress of every later cleanup pass and a
+// function that ends the subscription. Events are dropped for subscribers that fall behind.
+func (c *PodCleanController) SubscribeProgress() (<-chan ProgressEvent, func()) {
+	return c.progress.subscribe()
+}
+
+type progressKey struct{}
+
+// progressReporter publishes the batch progress of a single pass and records its deletions.
+type progressReporter struct {
+	hub       *progressHub
+	deletions *recent[Deletion]
+	deleted   deletionTally
+	runID     string
+	dryRun    bool
+}
+
+// withProgress returns a context under which BatchDeletePods reports each batch to hub and
+// records each deleted pod in deletions.
+func withProgress(ctx context.Context, hub *progressHub, deletions *recent[Deletion], run *cleanupRun) context.Context {
+	return context.WithValue(ctx, progressKey{}, progressReporter{hub: hub, deletions: deletions, deleted: run.deleted, runID: run.ID, dryRun: run.DryRun})
+}
+
+// reportBatch publishes that deleted of total pods of the context's rule have been processed.
+// It is a no-op outside a cleanup pass, e.g. for interactive deletions.
+func reportBatch(ctx context.Context, deleted, total int) {
+	reporter, ok := ctx.Value(progressKey{}).(progressReporter)
+	if !ok {
+		return
+	}
+
+	rule, _ := ruleFromContext(ctx)
+	reporter.hub.publish(ProgressEvent{
+		RunID:   reporter.runID,
+		Rule:    rule,
+		Deleted: deleted,
+		Total:   total,
+		DryRun:  reporter.dryRun,
+		Time:    time.Now(),
+	})
+}
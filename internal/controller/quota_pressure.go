@@ -0,0 +1,43 @@
+package controller
+
+import (
+	"context"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+// quotaPressuredNamespaces returns the namespaces with a ResourceQuota whose usage of any resource
+// reached threshold, a ratio of its hard limit. ResourceQuotas are listed once per run.
+func (pm *PodMatcher) quotaPressuredNamespaces(ctx context.Context, threshold float64) (map[string]bool, error) {
+	if pm.pressuredNamespaces != nil {
+		return pm.pressuredNamespaces, nil
+	}
+
+	var quotas corev1.ResourceQuotaList
+	if err := withThrottleRetry(ctx, "list", func() error { return pm.client.List(ctx, &quotas) }); err != nil {
+		return nil, newListError("resourcequotas", "", err)
+	}
+
+	pm.pressuredNamespaces = map[string]bool{}
+	for i := range quotas.Items {
+		if isQuotaUnderPressure(&quotas.Items[i], threshold) {
+			pm.pressuredNamespaces[quotas.Items[i].Namespace] = true
+		}
+	}
+	return pm.pressuredNamespaces, nil
+}
+
+// isQuotaUnderPressure reports whether quota uses at least threshold of the hard limit of any of
+// its resources, as last reported by the quota controller.
+func isQuotaUnderPressure(quota *corev1.ResourceQuota, threshold float64) bool {
+	for resource, hard := range quota.Status.Hard {
+		used, ok := quota.Status.Used[resource]
+		if !ok || hard.IsZero() {
+			continue
+		}
+		if used.AsApproximateFloat64() >= threshold*hard.AsApproximateFloat64() {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,111 @@
+package controller
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	cleanupconfig "github.com/infrautils/kubeclean/internal/cleanup_config"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func newQuota(namespace string, hard, used corev1.ResourceList) *corev1.ResourceQuota {
+	return &corev1.ResourceQuota{
+		ObjectMeta: metav1.ObjectMeta{Name: "quota", Namespace: namespace},
+		Status:     corev1.ResourceQuotaStatus{Hard: hard, Used: used},
+	}
+}
+
+func TestIsQuotaUnderPressure(t *testing.T) {
+	tests := []struct {
+		name  string
+		quota *corev1.ResourceQuota
+		want  bool
+	}{
+		{
+			name: "pods near the limit",
+			quota: newQuota("a",
+				corev1.ResourceList{corev1.ResourcePods: resource.MustParse("10"), corev1.ResourceRequestsCPU: resource.MustParse("4")},
+				corev1.ResourceList{corev1.ResourcePods: resource.MustParse("9"), corev1.ResourceRequestsCPU: resource.MustParse("500m")}),
+			want: true,
+		},
+		{
+			name: "memory below the threshold",
+			quota: newQuota("a",
+				corev1.ResourceList{corev1.ResourceRequestsMemory: resource.MustParse("8Gi")},
+				corev1.ResourceList{corev1.ResourceRequestsMemory: resource.MustParse("6Gi")}),
+			want: false,
+		},
+		{
+			name:  "usage not reported yet",
+			quota: newQuota("a", corev1.ResourceList{corev1.ResourcePods: resource.MustParse("10")}, nil),
+			want:  false,
+		},
+		{
+			name: "zero hard limit",
+			quota: newQuota("a",
+				corev1.ResourceList{corev1.ResourceServicesLoadBalancers: resource.MustParse("0")},
+				corev1.ResourceList{corev1.ResourceServicesLoadBalancers: resource.MustParse("0")}),
+			want: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := isQuotaUnderPressure(tt.quota, 0.9); got != tt.want {
+				t.Errorf("isQuotaUnderPressure() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPlanRules_QuotaPressure(t *testing.T) {
+	scheme := runtime.NewScheme()
+	_ = corev1.AddToScheme(scheme)
+
+	pod := func(namespace, name string, age time.Duration) *corev1.Pod {
+		return &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace, CreationTimestamp: metav1.NewTime(time.Now().Add(-age))},
+			Status:     corev1.PodStatus{Phase: corev1.PodSucceeded},
+		}
+	}
+	pods := corev1.ResourceList{corev1.ResourcePods: resource.MustParse("10")}
+
+	k8sClient := fake.NewClientBuilder().WithScheme(scheme).WithObjects(
+		newQuota("full", pods, corev1.ResourceList{corev1.ResourcePods: resource.MustParse("10")}),
+		newQuota("roomy", pods, corev1.ResourceList{corev1.ResourcePods: resource.MustParse("2")}),
+		pod("full", "old", 2*time.Hour),
+		pod("full", "recent", 40*time.Minute),
+		pod("roomy", "old", 2*time.Hour),
+		pod("roomy", "recent", 40*time.Minute),
+	).Build()
+
+	cfg := &cleanupconfig.CleanupConfig{
+		MaxDeletionsPerRun: 2,
+		PodCleanupConfig: cleanupconfig.PodCleanupConfig{
+			Enabled: true,
+			Rules: []cleanupconfig.PodCleanRule{{
+				Name: "succeeded", Enabled: true, Phase: "Succeeded", TTL: cleanupconfig.Duration{Duration: time.Hour},
+			}},
+			QuotaPressure: cleanupconfig.QuotaPressureConfig{Enabled: true, TTLPercent: 50},
+		},
+	}
+
+	plans := planRules(context.Background(), NewPodMatcher(k8sClient), cfg, nil)
+	if len(plans) != 1 {
+		t.Fatalf("Expected one plan, got %d", len(plans))
+	}
+
+	// The halved TTL matches the recent pod of the full namespace, and its pods use up the budget.
+	plan := plans[0]
+	if got := countByNamespace(plan.Selected); got["full"] != 2 {
+		t.Errorf("Expected both pods of the namespace under pressure to be selected, got %v", got)
+	}
+	if len(plan.Deferred) != 1 || plan.Deferred[0].Namespace != "roomy" || plan.Deferred[0].Name != "old" {
+		t.Errorf("Expected only the old pod of the roomy namespace to be deferred, got %v", plan.Deferred)
+	}
+}
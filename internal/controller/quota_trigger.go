@@ -0,0 +1,137 @@
+package controller
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/watch"
+	corev1client "k8s.io/client-go/kubernetes/typed/core/v1"
+	toolscache "k8s.io/client-go/tools/cache"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+// quotaExceededMessage is part of the message the ResourceQuota admission plugin rejects objects
+// with, which controllers such as the ReplicaSet and Job controllers repeat in their events.
+const quotaExceededMessage = "exceeded quota"
+
+// isQuotaExceededEvent reports whether event reports a pod that could not be created or scheduled
+// because a ResourceQuota is exhausted.
+func isQuotaExceededEvent(event *corev1.Event) bool {
+	if event.Type != corev1.EventTypeWarning {
+		return false
+	}
+	if event.Reason != "FailedCreate" && event.Reason != "FailedScheduling" {
+		return false
+	}
+	return strings.Contains(event.Message, quotaExceededMessage)
+}
+
+// eventTime returns when event last occurred.
+func eventTime(event *corev1.Event) time.Time {
+	switch {
+	case !event.LastTimestamp.IsZero():
+		return event.LastTimestamp.Time
+	case !event.EventTime.IsZero():
+		return event.EventTime.Time
+	default:
+		return event.FirstTimestamp.Time
+	}
+}
+
+// QuotaEventTrigger watches Warning events for exhausted ResourceQuotas and triggers a pass of the
+// pod rules for the namespace they occur in, instead of leaving it to the next scheduled run. It
+// implements manager.Runnable.
+type QuotaEventTrigger struct {
+	Controller *PodCleanController
+	Events     corev1client.EventsGetter
+
+	mu        sync.Mutex
+	triggered map[string]time.Time // Last triggered pass by namespace.
+}
+
+// NewQuotaEventTrigger returns a trigger for controller that watches events through events.
+func NewQuotaEventTrigger(controller *PodCleanController, events corev1client.EventsGetter) *QuotaEventTrigger {
+	return &QuotaEventTrigger{Controller: controller, Events: events, triggered: map[string]time.Time{}}
+}
+
+// Start watches events until ctx is cancelled. Events that occurred before it started are ignored.
+func (t *QuotaEventTrigger) Start(ctx context.Context) error {
+	started := time.Now()
+	selector := fields.OneTermEqualSelector("type", corev1.EventTypeWarning).String()
+
+	handle := func(obj any) {
+		if event, ok := obj.(*corev1.Event); ok && !eventTime(event).Before(started) {
+			t.observe(ctx, event)
+		}
+	}
+
+	_, informer := toolscache.NewInformerWithOptions(toolscache.InformerOptions{
+		ListerWatcher: &toolscache.ListWatch{
+			ListWithContextFunc: func(ctx context.Context, options metav1.ListOptions) (runtime.Object, error) {
+				options.FieldSelector = selector
+				return t.Events.Events("").List(ctx, options)
+			},
+			WatchFuncWithContext: func(ctx context.Context, options metav1.ListOptions) (watch.Interface, error) {
+				options.FieldSelector = selector
+				return t.Events.Events("").Watch(ctx, options)
+			},
+		},
+		ObjectType: &corev1.Event{},
+		Handler: toolscache.ResourceEventHandlerFuncs{
+			AddFunc:    handle,
+			UpdateFunc: func(_, obj any) { handle(obj) },
+		},
+	})
+
+	log.FromContext(ctx).Info("Watching events for exhausted ResourceQuotas")
+	informer.RunWithContext(ctx)
+	return nil
+}
+
+// observe triggers a pass for the namespace of event if it reports an exhausted quota, the
+// namespace's cooldown has passed and no other pass is running. Passes refused because another
+// one is running are not counted towards the cooldown, so the next event triggers again.
+func (t *QuotaEventTrigger) observe(ctx context.Context, event *corev1.Event) {
+	quotaPressure := t.Controller.CleanupConfig.PodCleanupConfig.QuotaPressure
+	if !t.Controller.CleanupConfig.PodCleanupConfig.Enabled || !quotaPressure.Enabled || !quotaPressure.TriggerOnEvents {
+		return
+	}
+	if !isQuotaExceededEvent(event) {
+		return
+	}
+
+	namespace := event.InvolvedObject.Namespace
+	if namespace == "" {
+		namespace = event.Namespace
+	}
+	logger := log.FromContext(ctx).WithValues("namespace", namespace, "reason", event.Reason)
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	now := time.Now()
+	if last, ok := t.triggered[namespace]; ok && now.Sub(last) < quotaPressure.EffectiveTriggerCooldown() {
+		return
+	}
+
+	runID, err := t.Controller.TriggerNamespaceRun(ctx, namespace)
+	if errors.Is(err, ErrRunInProgress) {
+		logger.V(1).Info("Quota exhausted while a run is in progress; not triggering another")
+		return
+	}
+	if err != nil {
+		logger.Error(err, "Failed to trigger a cleanup for the namespace")
+		return
+	}
+
+	t.triggered[namespace] = now
+	quotaTriggeredRunsTotal.Inc()
+	logger.Info("Quota exhausted; triggered a cleanup for the namespace", "triggeredRunID", runID, "message", event.Message)
+}
@@ -0,0 +1,108 @@
+package controller
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	cleanupconfig "github.com/infrautils/kubeclean/internal/cleanup_config"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func quotaEvent(namespace, reason, message string) *corev1.Event {
+	return &corev1.Event{
+		ObjectMeta:     metav1.ObjectMeta{Name: "event", Namespace: namespace},
+		InvolvedObject: corev1.ObjectReference{Kind: "ReplicaSet", Namespace: namespace, Name: "web"},
+		Type:           corev1.EventTypeWarning,
+		Reason:         reason,
+		Message:        message,
+		LastTimestamp:  metav1.Now(),
+	}
+}
+
+func TestIsQuotaExceededEvent(t *testing.T) {
+	exceeded := `pods "web-6d4cf56db6-x2x9z" is forbidden: exceeded quota: compute, requested: pods=1, used: pods=10, limited: pods=10`
+
+	normal := quotaEvent("a", "FailedCreate", exceeded)
+	normal.Type = corev1.EventTypeNormal
+
+	tests := []struct {
+		name  string
+		event *corev1.Event
+		want  bool
+	}{
+		{name: "pod creation rejected by quota", event: quotaEvent("a", "FailedCreate", exceeded), want: true},
+		{name: "scheduling blocked by quota", event: quotaEvent("a", "FailedScheduling", "0/3 nodes are available: exceeded quota: gpu"), want: true},
+		{name: "creation failed for another reason", event: quotaEvent("a", "FailedCreate", `pods "web" is forbidden: error looking up service account a/web`)},
+		{name: "unrelated reason", event: quotaEvent("a", "BackOff", exceeded)},
+		{name: "normal event", event: normal},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := isQuotaExceededEvent(tt.event); got != tt.want {
+				t.Errorf("isQuotaExceededEvent() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestQuotaEventTrigger_RunsNamespacePass(t *testing.T) {
+	scheme := runtime.NewScheme()
+	_ = corev1.AddToScheme(scheme)
+
+	newPod := func(name, namespace string) *corev1.Pod {
+		return &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:              name,
+				Namespace:         namespace,
+				CreationTimestamp: metav1.NewTime(time.Now().Add(-2 * time.Hour)),
+			},
+			Status: corev1.PodStatus{Phase: corev1.PodSucceeded},
+		}
+	}
+	client := fake.NewClientBuilder().WithScheme(scheme).WithRuntimeObjects(
+		newPod("full-pod", "full"),
+		newPod("other-pod", "other"),
+	).Build()
+
+	cleanupCfg := &cleanupconfig.CleanupConfig{
+		BatchSize: 10,
+		PodCleanupConfig: cleanupconfig.PodCleanupConfig{
+			Enabled: true,
+			Rules: []cleanupconfig.PodCleanRule{{
+				Name: "succeeded", Enabled: true, Phase: "Succeeded", TTL: cleanupconfig.Duration{Duration: time.Hour},
+			}},
+			QuotaPressure: cleanupconfig.QuotaPressureConfig{Enabled: true, TriggerOnEvents: true},
+		},
+	}
+	controller := NewPodCleanController(client, scheme, cleanupCfg)
+	trigger := NewQuotaEventTrigger(controller, nil)
+
+	event := quotaEvent("full", "FailedCreate", "pods \"web\" is forbidden: exceeded quota: compute")
+	trigger.observe(context.Background(), event)
+
+	// Wait for the triggered pass to release the run lock.
+	controller.runMu.Lock()
+	controller.runMu.Unlock()
+
+	history := controller.History(0)
+	if len(history) != 1 || history[0].Namespace != "full" {
+		t.Fatalf("Expected one pass restricted to the namespace, got %+v", history)
+	}
+	remaining := remainingPodNames(t, client)
+	if remaining["full-pod"] || !remaining["other-pod"] {
+		t.Errorf("Expected only the pod of the namespace under pressure to be deleted, got %v", remaining)
+	}
+
+	// A repeated event within the cooldown does not trigger another pass.
+	trigger.observe(context.Background(), event)
+	controller.runMu.Lock()
+	controller.runMu.Unlock()
+	if got := len(controller.History(0)); got != 1 {
+		t.Errorf("Expected the cooldown to suppress a second pass, got %d passes", got)
+	}
+}
@@ -0,0 +1,182 @@
+package controller
+
+import (
+	"context"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// ReasonCode is a stable, machine-readable code for why kubeclean selected, skipped or failed to
+// act on a pod. Codes are emitted alongside the human-readable reasons in logs, Events and API
+// responses so automation need not parse English. A code is never reused for another meaning.
+//
+// 1xxx codes explain why a pod was selected, 2xxx why it was not and 3xxx why acting on it failed.
+type ReasonCode string
+
+const (
+	ReasonCodeTTLExpired         ReasonCode = "KC1001" // Matched a rule and outlived its TTL.
+	ReasonCodeMaxPerNodeExceeded ReasonCode = "KC1002" // Trimmed as one of the oldest completed pods beyond the rule's maxPerNode.
+
+	ReasonCodeCriteriaNotMet        ReasonCode = "KC2001"
+	ReasonCodeMirrorPod             ReasonCode = "KC2002"
+	ReasonCodeProtectedNamespace    ReasonCode = "KC2003"
+	ReasonCodeTerminating           ReasonCode = "KC2004"
+	ReasonCodeOptedOut              ReasonCode = "KC2005"
+	ReasonCodePriorityClassExcluded ReasonCode = "KC2006"
+	ReasonCodeExcludedBySelector    ReasonCode = "KC2007"
+	ReasonCodeTTLNotExpired         ReasonCode = "KC2008"
+	ReasonCodeNamespaceTerminating  ReasonCode = "KC2009"
+	ReasonCodeInvalidAnnotation     ReasonCode = "KC2010"
+	ReasonCodeOwnerRollingOut       ReasonCode = "KC2011"
+	ReasonCodeMinAvailable          ReasonCode = "KC2012"
+	ReasonCodeDeferredByBudget      ReasonCode = "KC2013" // Matched, but held back by a deletion budget until a later run.
+
+	ReasonCodeForbidden       ReasonCode = "KC3001"
+	ReasonCodeTimeout         ReasonCode = "KC3002"
+	ReasonCodeNotFound        ReasonCode = "KC3003"
+	ReasonCodeThrottled       ReasonCode = "KC3004"
+	ReasonCodeConflict        ReasonCode = "KC3005"
+	ReasonCodeWebhookDenied   ReasonCode = "KC3006"
+	ReasonCodeAnnotationError ReasonCode = "KC3007" // A malformed annotation failed the rule under the fail policy.
+	ReasonCodeUnknownError    ReasonCode = "KC3999"
+)
+
+// reasonCodeNames holds the symbolic name of every code.
+var reasonCodeNames = map[ReasonCode]string{
+	ReasonCodeTTLExpired:         "TTL_EXPIRED",
+	ReasonCodeMaxPerNodeExceeded: "MAX_PER_NODE_EXCEEDED",
+
+	ReasonCodeCriteriaNotMet:        "CRITERIA_NOT_MET",
+	ReasonCodeMirrorPod:             "MIRROR_POD",
+	ReasonCodeProtectedNamespace:    "PROTECTED_NAMESPACE",
+	ReasonCodeTerminating:           "TERMINATING",
+	ReasonCodeOptedOut:              "OPTED_OUT",
+	ReasonCodePriorityClassExcluded: "PRIORITY_CLASS_EXCLUDED",
+	ReasonCodeExcludedBySelector:    "EXCLUDED_BY_SELECTOR",
+	ReasonCodeTTLNotExpired:         "TTL_NOT_EXPIRED",
+	ReasonCodeNamespaceTerminating:  "NAMESPACE_TERMINATING",
+	ReasonCodeInvalidAnnotation:     "INVALID_ANNOTATION",
+	ReasonCodeOwnerRollingOut:       "OWNER_ROLLING_OUT",
+	ReasonCodeMinAvailable:          "MIN_AVAILABLE",
+	ReasonCodeDeferredByBudget:      "DEFERRED_BY_BUDGET",
+
+	ReasonCodeForbidden:       "FORBIDDEN",
+	ReasonCodeTimeout:         "TIMEOUT",
+	ReasonCodeNotFound:        "NOT_FOUND",
+	ReasonCodeThrottled:       "THROTTLED",
+	ReasonCodeConflict:        "CONFLICT",
+	ReasonCodeWebhookDenied:   "WEBHOOK_DENIED",
+	ReasonCodeAnnotationError: "ANNOTATION_ERROR",
+	ReasonCodeUnknownError:    "UNKNOWN_ERROR",
+}
+
+// Name returns the symbolic name of the code, e.g. "TTL_EXPIRED", or "" for an unknown code.
+func (c ReasonCode) Name() string {
+	return reasonCodeNames[c]
+}
+
+// Code returns the reason code of a skip reason, or "" for SkipReasonNone.
+func (r SkipReason) Code() ReasonCode {
+	switch r {
+	case SkipReasonCriteria:
+		return ReasonCodeCriteriaNotMet
+	case SkipReasonMirrorPod:
+		return ReasonCodeMirrorPod
+	case SkipReasonNamespaceForbidden:
+		return ReasonCodeProtectedNamespace
+	case SkipReasonTerminating:
+		return ReasonCodeTerminating
+	case SkipReasonDisabled:
+		return ReasonCodeOptedOut
+	case SkipReasonPriorityClass:
+		return ReasonCodePriorityClassExcluded
+	case SkipReasonExcluded:
+		return ReasonCodeExcludedBySelector
+	case SkipReasonTTL:
+		return ReasonCodeTTLNotExpired
+	case SkipReasonNamespaceTerminating:
+		return ReasonCodeNamespaceTerminating
+	case SkipReasonInvalidAnnotation:
+		return ReasonCodeInvalidAnnotation
+	case SkipReasonOwnerRollingOut:
+		return ReasonCodeOwnerRollingOut
+	case SkipReasonMinAvailable:
+		return ReasonCodeMinAvailable
+	default:
+		return ""
+	}
+}
+
+// Code returns the reason code of an error reason.
+func (r ErrorReason) Code() ReasonCode {
+	switch r {
+	case ErrorReasonForbidden:
+		return ReasonCodeForbidden
+	case ErrorReasonTimeout:
+		return ReasonCodeTimeout
+	case ErrorReasonNotFound:
+		return ReasonCodeNotFound
+	case ErrorReasonThrottled:
+		return ReasonCodeThrottled
+	case ErrorReasonConflict:
+		return ReasonCodeConflict
+	case ErrorReasonWebhookDenied:
+		return ReasonCodeWebhookDenied
+	case ErrorReasonInvalidAnnotation:
+		return ReasonCodeAnnotationError
+	default:
+		return ReasonCodeUnknownError
+	}
+}
+
+// ErrorCodes converts counts by error reason to counts by reason code.
+func ErrorCodes(reasons map[ErrorReason]int) map[ReasonCode]int {
+	codes := make(map[ReasonCode]int, len(reasons))
+	for reason, count := range reasons {
+		if count > 0 {
+			codes[reason.Code()] += count
+		}
+	}
+	return codes
+}
+
+// trimmedPod identifies a pod a rule selected through maxPerNode rather than its TTL.
+type trimmedPod struct {
+	rule string
+	pod  types.NamespacedName
+}
+
+// selectionCode returns why the named rule selected pod in this run.
+func (pm *PodMatcher) selectionCode(rule string, pod *corev1.Pod) ReasonCode {
+	if _, ok := pm.trimmed[trimmedPod{rule: rule, pod: types.NamespacedName{Namespace: pod.Namespace, Name: pod.Name}}]; ok {
+		return ReasonCodeMaxPerNodeExceeded
+	}
+	return ReasonCodeTTLExpired
+}
+
+// matchCode returns why the named rule matched pod in this run: selectionCode for a selected pod
+// and ReasonCodeDeferredByBudget for one held back by a budget.
+func (pm *PodMatcher) matchCode(rule string, pod *corev1.Pod, deferred bool) ReasonCode {
+	if deferred {
+		return ReasonCodeDeferredByBudget
+	}
+	return pm.selectionCode(rule, pod)
+}
+
+type selectionCodeContextKey struct{}
+
+// withSelectionCodes makes codes, which returns why a pod was selected, available to the actions
+// taken with ctx so they can log it.
+func withSelectionCodes(ctx context.Context, codes func(*corev1.Pod) ReasonCode) context.Context {
+	return context.WithValue(ctx, selectionCodeContextKey{}, codes)
+}
+
+// selectionCodeFromContext returns why pod was selected, or "" if ctx does not say.
+func selectionCodeFromContext(ctx context.Context, pod *corev1.Pod) ReasonCode {
+	codes, ok := ctx.Value(selectionCodeContextKey{}).(func(*corev1.Pod) ReasonCode)
+	if !ok {
+		return ""
+	}
+	return codes(pod)
+}
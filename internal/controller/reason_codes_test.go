@@ -0,0 +1,125 @@
+package controller
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	cleanupconfig "github.com/infrautils/kubeclean/internal/cleanup_config"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestReasonCodes(t *testing.T) {
+	skipReasons := []SkipReason{
+		SkipReasonCriteria, SkipReasonMirrorPod, SkipReasonTerminating, SkipReasonDisabled, SkipReasonPriorityClass,
+		SkipReasonExcluded, SkipReasonTTL, SkipReasonNamespaceTerminating, SkipReasonInvalidAnnotation,
+		SkipReasonNamespaceForbidden, SkipReasonOwnerRollingOut, SkipReasonMinAvailable,
+	}
+	errorReasons := []ErrorReason{
+		ErrorReasonForbidden, ErrorReasonTimeout, ErrorReasonNotFound, ErrorReasonThrottled, ErrorReasonConflict,
+		ErrorReasonUnknown, ErrorReasonWebhookDenied, ErrorReasonInvalidAnnotation,
+	}
+
+	// Every reason has its own code, and every code a name.
+	seen := map[ReasonCode]string{}
+	check := func(reason string, code ReasonCode, prefix string) {
+		if !strings.HasPrefix(string(code), prefix) || code.Name() == "" {
+			t.Errorf("Expected %s to have a named %sxxx code, got %q", reason, prefix, code)
+		}
+		if other, ok := seen[code]; ok {
+			t.Errorf("Expected %s and %s to have different codes, both have %s", reason, other, code)
+		}
+		seen[code] = reason
+	}
+	for _, reason := range skipReasons {
+		check(string(reason), reason.Code(), "KC2")
+	}
+	for _, reason := range errorReasons {
+		check(string(reason), reason.Code(), "KC3")
+	}
+
+	if SkipReasonNone.Code() != "" {
+		t.Errorf("Expected no code for SkipReasonNone, got %q", SkipReasonNone.Code())
+	}
+	if code := SkipReasonNamespaceForbidden.Code(); code != "KC2003" || code.Name() != "PROTECTED_NAMESPACE" {
+		t.Errorf("Expected KC2003 PROTECTED_NAMESPACE, got %s %s", code, code.Name())
+	}
+	if ReasonCodeTTLExpired != "KC1001" || ReasonCodeTTLExpired.Name() != "TTL_EXPIRED" {
+		t.Errorf("Expected KC1001 TTL_EXPIRED, got %s %s", ReasonCodeTTLExpired, ReasonCodeTTLExpired.Name())
+	}
+}
+
+func TestPreview_ReasonCodes(t *testing.T) {
+	scheme := runtime.NewScheme()
+	_ = corev1.AddToScheme(scheme)
+
+	newPod := func(name string, age time.Duration) *corev1.Pod {
+		return &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default", CreationTimestamp: metav1.NewTime(time.Now().Add(-age))},
+			Spec:       corev1.PodSpec{NodeName: "a"},
+			Status:     corev1.PodStatus{Phase: corev1.PodSucceeded},
+		}
+	}
+	client := fake.NewClientBuilder().WithScheme(scheme).WithRuntimeObjects(
+		newPod("expired", 3*time.Hour),
+		newPod("old", 40*time.Minute),
+		newPod("new", 10*time.Minute),
+	).Build()
+
+	cfg := &cleanupconfig.CleanupConfig{
+		PodCleanupConfig: cleanupconfig.PodCleanupConfig{
+			Enabled: true,
+			Rules: []cleanupconfig.PodCleanRule{{
+				Name: "succeeded", Enabled: true, Phase: "Succeeded",
+				TTL: cleanupconfig.Duration{Duration: time.Hour}, MaxPerNode: 1,
+			}},
+		},
+	}
+
+	codes := map[string]ReasonCode{}
+	for _, ref := range NewPodCleanController(client, scheme, cfg).Preview(context.Background()) {
+		codes[ref.Name] = ref.ReasonCode
+	}
+
+	want := map[string]ReasonCode{"expired": ReasonCodeTTLExpired, "old": ReasonCodeMaxPerNodeExceeded}
+	if len(codes) != len(want) || codes["expired"] != want["expired"] || codes["old"] != want["old"] {
+		t.Errorf("Expected reason codes %v, got %v", want, codes)
+	}
+}
+
+func TestRunCleanUp_AnnotatesRunEventWithReasonCodes(t *testing.T) {
+	scheme := runtime.NewScheme()
+	_ = corev1.AddToScheme(scheme)
+	_ = appsv1.AddToScheme(scheme)
+
+	client := fake.NewClientBuilder().WithScheme(scheme).WithRuntimeObjects(
+		&appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Name: "kubeclean", Namespace: "kubeclean"}},
+	).Build()
+	cfg := &cleanupconfig.CleanupConfig{
+		RunEvents: cleanupconfig.RunEventsConfig{Enabled: true, Deployment: "kubeclean/kubeclean"},
+	}
+	controller := NewPodCleanController(client, scheme, cfg)
+
+	run := &cleanupRun{deleteFailures: 3, deleteErrors: map[ErrorReason]int{ErrorReasonTimeout: 2, ErrorReasonForbidden: 1}}
+	summary := controller.finishRun(context.Background(), RunSummary{RunID: "1", Started: time.Now(),
+		DeleteErrors: map[ErrorReason]int{}, ListErrors: map[ErrorReason]int{}}, run)
+	if summary.DeleteErrorCodes[ReasonCodeTimeout] != 2 || summary.DeleteErrorCodes[ReasonCodeForbidden] != 1 {
+		t.Errorf("Expected the summary to count delete errors by code, got %v", summary.DeleteErrorCodes)
+	}
+
+	var events corev1.EventList
+	if err := client.List(context.Background(), &events); err != nil {
+		t.Fatalf("Failed to list events: %v", err)
+	}
+	if len(events.Items) != 1 {
+		t.Fatalf("Expected one Event, got %d", len(events.Items))
+	}
+	if got, want := events.Items[0].Annotations[reasonCodesAnnotation], `{"KC3001":1,"KC3002":2}`; got != want {
+		t.Errorf("Expected the %s annotation %s, got %q", reasonCodesAnnotation, want, got)
+	}
+}
@@ -0,0 +1,189 @@
+package controller
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+
+	cleanupconfig "github.com/infrautils/kubeclean/internal/cleanup_config"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/util/retry"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+// managedByLabel marks the objects kubeclean creates and owns; managedByKubeclean is its value.
+const (
+	managedByLabel     = "app.kubernetes.io/managed-by"
+	managedByKubeclean = "kubeclean"
+)
+
+// Receipt records what a pass deleted in one namespace. Receipts are stored as JSON in a ConfigMap
+// of that namespace, keyed by the pass's start time and run ID so keys sort chronologically.
+type Receipt struct {
+	RunID   string         `json:"runID"`
+	Time    time.Time      `json:"time"`
+	Deleted int            `json:"deleted"`        // Pods deleted in the namespace.
+	Rules   map[string]int `json:"rules"`          // Pods deleted per rule.
+	Logs    string         `json:"logs,omitempty"` // Backend the pods' logs were forwarded to, e.g. "loki".
+}
+
+// deletionTally counts the pods a pass deleted, by namespace and rule.
+type deletionTally map[string]map[string]int
+
+func (t deletionTally) add(namespace, rule string) {
+	if t[namespace] == nil {
+		t[namespace] = map[string]int{}
+	}
+	t[namespace][rule]++
+}
+
+// receiptKeyTime is the layout of the start time receipt keys begin with.
+const receiptKeyTime = "20060102T150405Z"
+
+// writeReceipts writes a receipt into every namespace the pass deleted pods in, after dropping
+// receipts older than the configured maximum age on full passes. Failures are logged; a missing
+// receipt never fails the pass.
+func (c *PodCleanController) writeReceipts(ctx context.Context, run *cleanupRun, started time.Time) {
+	cfg := c.CleanupConfig.Receipts
+	if !cfg.Enabled {
+		return
+	}
+	if cfg.MaxAge.Duration > 0 && !run.DryRun && !run.Scope.targeted() {
+		if err := pruneReceipts(ctx, c.Client, cfg, time.Now()); err != nil {
+			log.FromContext(ctx).Error(err, "Failed to drop expired deletion receipts")
+		}
+	}
+	if len(run.deleted) == 0 {
+		return
+	}
+
+	var logs string
+	if run.logs != nil {
+		logs = c.CleanupConfig.LogForwarding.Backend
+	}
+
+	key := started.UTC().Format(receiptKeyTime) + "-" + run.ID
+	for namespace, rules := range run.deleted {
+		receipt := Receipt{RunID: run.ID, Time: started, Rules: rules, Logs: logs}
+		for _, count := range rules {
+			receipt.Deleted += count
+		}
+
+		if err := writeReceipt(ctx, c.Client, namespace, cfg, key, receipt); err != nil {
+			log.FromContext(ctx).Error(err, "Failed to write deletion receipt", "namespace", namespace)
+		}
+	}
+}
+
+// writeReceipt stores receipt under key in the namespace's receipts ConfigMap, creating it if
+// needed and dropping the oldest receipts beyond the configured count, and expired ones. A ConfigMap of that name
+// without kubeclean's managed-by label belongs to someone else and is left alone. Writes racing
+// another writer, including a concurrent creation, are retried.
+func writeReceipt(ctx context.Context, k8sClient client.Client, namespace string, cfg cleanupconfig.ReceiptsConfig, key string, receipt Receipt) error {
+	data, err := json.Marshal(receipt)
+	if err != nil {
+		return err
+	}
+
+	name := types.NamespacedName{Namespace: namespace, Name: cfg.ConfigMapName()}
+	raced := func(err error) bool { return apierrors.IsConflict(err) || apierrors.IsAlreadyExists(err) }
+	return retry.OnError(retry.DefaultRetry, raced, func() error {
+		configMap := &corev1.ConfigMap{}
+		err := withThrottleRetry(ctx, "get", func() error { return k8sClient.Get(ctx, name, configMap) })
+		if apierrors.IsNotFound(err) {
+			configMap = &corev1.ConfigMap{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      name.Name,
+					Namespace: namespace,
+					Labels:    map[string]string{managedByLabel: managedByKubeclean},
+				},
+				Data: map[string]string{key: string(data)},
+			}
+			return withThrottleRetry(ctx, "create", func() error { return k8sClient.Create(ctx, configMap) })
+		}
+		if err != nil {
+			return err
+		}
+		if configMap.Labels[managedByLabel] != managedByKubeclean {
+			return fmt.Errorf("configmap %s is not managed by kubeclean", name)
+		}
+
+		if configMap.Data == nil {
+			configMap.Data = map[string]string{}
+		}
+		configMap.Data[key] = string(data)
+
+		keys := make([]string, 0, len(configMap.Data))
+		for existing := range configMap.Data {
+			keys = append(keys, existing)
+		}
+		slices.Sort(keys)
+		for _, stale := range keys[:max(0, len(keys)-cfg.KeepCount())] {
+			delete(configMap.Data, stale)
+		}
+		dropExpiredReceipts(configMap, cfg, time.Now())
+
+		return withThrottleRetry(ctx, "update", func() error { return k8sClient.Update(ctx, configMap) })
+	})
+}
+
+// dropExpiredReceipts removes the receipts of configMap older than the configured maximum age and
+// reports whether it removed any. Keys that do not start with a time are left alone.
+func dropExpiredReceipts(configMap *corev1.ConfigMap, cfg cleanupconfig.ReceiptsConfig, now time.Time) bool {
+	if cfg.MaxAge.Duration <= 0 {
+		return false
+	}
+
+	var dropped bool
+	for key := range configMap.Data {
+		started, err := time.Parse(receiptKeyTime, key[:min(len(key), len(receiptKeyTime))])
+		if err == nil && now.Sub(started) > cfg.MaxAge.Duration {
+			delete(configMap.Data, key)
+			dropped = true
+		}
+	}
+	return dropped
+}
+
+// pruneReceipts drops expired receipts from every receipts ConfigMap kubeclean manages, and
+// deletes the ConfigMaps left without receipts, so namespaces kubeclean no longer deletes pods in
+// do not keep them forever.
+func pruneReceipts(ctx context.Context, k8sClient client.Client, cfg cleanupconfig.ReceiptsConfig, now time.Time) error {
+	var configMaps corev1.ConfigMapList
+	if err := withThrottleRetry(ctx, "list", func() error {
+		return k8sClient.List(ctx, &configMaps, client.MatchingLabels{managedByLabel: managedByKubeclean})
+	}); err != nil {
+		return newListError("configmaps", "", err)
+	}
+
+	var errs []error
+	for i := range configMaps.Items {
+		configMap := &configMaps.Items[i]
+		if configMap.Name != cfg.ConfigMapName() || !dropExpiredReceipts(configMap, cfg, now) {
+			continue
+		}
+
+		var err error
+		if len(configMap.Data) == 0 {
+			// The precondition keeps a receipt written since the listing from being deleted with it.
+			unchanged := client.Preconditions{ResourceVersion: &configMap.ResourceVersion}
+			err = withThrottleRetry(ctx, "delete", func() error {
+				return client.IgnoreNotFound(k8sClient.Delete(ctx, configMap, unchanged))
+			})
+		} else {
+			err = withThrottleRetry(ctx, "update", func() error { return k8sClient.Update(ctx, configMap) })
+		}
+		// A conflicting write is a pass writing a receipt, which drops expired ones too.
+		if err != nil && !apierrors.IsConflict(err) {
+			errs = append(errs, fmt.Errorf("configmap %s/%s: %w", configMap.Namespace, configMap.Name, err))
+		}
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,188 @@
+package controller
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	cleanupconfig "github.com/infrautils/kubeclean/internal/cleanup_config"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	ctrlclient "sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
+)
+
+func TestRunCleanUp_WritesReceipts(t *testing.T) {
+	scheme := runtime.NewScheme()
+	_ = corev1.AddToScheme(scheme)
+
+	newPod := func(name, namespace string) *corev1.Pod {
+		return &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace, CreationTimestamp: metav1.NewTime(time.Now().Add(-2 * time.Hour))},
+			Status:     corev1.PodStatus{Phase: corev1.PodSucceeded},
+		}
+	}
+	client := fake.NewClientBuilder().WithScheme(scheme).WithRuntimeObjects(
+		newPod("a", "team-a"), newPod("b", "team-a"), newPod("c", "team-b"),
+	).Build()
+
+	cfg := &cleanupconfig.CleanupConfig{
+		PodCleanupConfig: cleanupconfig.PodCleanupConfig{
+			Enabled: true,
+			Rules:   []cleanupconfig.PodCleanRule{{Name: "succeeded", Enabled: true, Phase: "Succeeded", TTL: cleanupconfig.Duration{Duration: time.Hour}}},
+		},
+		Receipts: cleanupconfig.ReceiptsConfig{Enabled: true, Keep: 1},
+	}
+	controller := NewPodCleanController(client, scheme, cfg)
+
+	first := controller.RunCleanUp(context.Background())
+
+	receipts := func(namespace string) map[string]string {
+		t.Helper()
+		configMap := &corev1.ConfigMap{}
+		if err := client.Get(context.Background(), types.NamespacedName{Namespace: namespace, Name: cleanupconfig.DefaultReceiptsName}, configMap); err != nil {
+			t.Fatalf("Expected a receipts ConfigMap in %s: %v", namespace, err)
+		}
+		return configMap.Data
+	}
+
+	data := receipts("team-a")
+	if len(data) != 1 {
+		t.Fatalf("Expected one receipt, got %v", data)
+	}
+	for _, value := range data {
+		var receipt Receipt
+		if err := json.Unmarshal([]byte(value), &receipt); err != nil {
+			t.Fatalf("Invalid receipt %q: %v", value, err)
+		}
+		if receipt.RunID != first.RunID || receipt.Deleted != 2 || receipt.Rules["succeeded"] != 2 {
+			t.Errorf("Unexpected receipt %+v", receipt)
+		}
+	}
+	if data := receipts("team-b"); len(data) != 1 {
+		t.Errorf("Expected one receipt in team-b, got %v", data)
+	}
+
+	// A later pass replaces the receipt, since only one is kept.
+	if err := client.Create(context.Background(), newPod("d", "team-a")); err != nil {
+		t.Fatal(err)
+	}
+	time.Sleep(time.Second) // Receipt keys have a resolution of one second.
+	second := controller.RunCleanUp(context.Background())
+
+	data = receipts("team-a")
+	if len(data) != 1 {
+		t.Fatalf("Expected the oldest receipt to be dropped, got %v", data)
+	}
+	for key := range data {
+		if key[len(key)-len(second.RunID):] != second.RunID {
+			t.Errorf("Expected the receipt of the second pass to be kept, got %s", key)
+		}
+	}
+
+	// Dry-runs delete nothing and write no receipt.
+	cfg.DryRun = true
+	if err := client.Create(context.Background(), newPod("e", "team-c")); err != nil {
+		t.Fatal(err)
+	}
+	controller.RunCleanUp(context.Background())
+	if err := client.Get(context.Background(), types.NamespacedName{Namespace: "team-c", Name: cleanupconfig.DefaultReceiptsName}, &corev1.ConfigMap{}); err == nil {
+		t.Error("Expected no receipt for a dry-run")
+	}
+}
+
+func TestWriteReceipt_OwnershipAndRaces(t *testing.T) {
+	scheme := runtime.NewScheme()
+	_ = corev1.AddToScheme(scheme)
+	cfg := cleanupconfig.ReceiptsConfig{Enabled: true}
+	receipt := Receipt{RunID: "run", Deleted: 1, Rules: map[string]int{"succeeded": 1}}
+
+	foreign := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: cleanupconfig.DefaultReceiptsName, Namespace: "team-a"},
+		Data:       map[string]string{"app.conf": "keep me"},
+	}
+	client := fake.NewClientBuilder().WithScheme(scheme).WithObjects(foreign).Build()
+	if err := writeReceipt(context.Background(), client, "team-a", cfg, "key", receipt); err == nil {
+		t.Error("Expected a ConfigMap kubeclean does not manage to be refused")
+	}
+	configMap := &corev1.ConfigMap{}
+	_ = client.Get(context.Background(), types.NamespacedName{Namespace: "team-a", Name: cleanupconfig.DefaultReceiptsName}, configMap)
+	if len(configMap.Data) != 1 || configMap.Data["app.conf"] != "keep me" {
+		t.Errorf("Expected the foreign ConfigMap to be left alone, got %v", configMap.Data)
+	}
+
+	// Another writer creates the ConfigMap between the read and the create.
+	raced := false
+	client = fake.NewClientBuilder().WithScheme(scheme).WithInterceptorFuncs(interceptor.Funcs{
+		Create: func(ctx context.Context, c ctrlclient.WithWatch, obj ctrlclient.Object, opts ...ctrlclient.CreateOption) error {
+			if !raced {
+				raced = true
+				other := obj.DeepCopyObject().(*corev1.ConfigMap)
+				other.Data = map[string]string{"other": "{}"}
+				if err := c.Create(ctx, other, opts...); err != nil {
+					return err
+				}
+			}
+			return c.Create(ctx, obj, opts...)
+		},
+	}).Build()
+	if err := writeReceipt(context.Background(), client, "team-b", cfg, "key", receipt); err != nil {
+		t.Fatalf("Expected the write to be retried, got %v", err)
+	}
+	_ = client.Get(context.Background(), types.NamespacedName{Namespace: "team-b", Name: cleanupconfig.DefaultReceiptsName}, configMap)
+	if configMap.Data["other"] == "" || configMap.Data["key"] == "" {
+		t.Errorf("Expected both receipts to be kept, got %v", configMap.Data)
+	}
+}
+
+func TestPruneReceipts_DropsExpiredReceipts(t *testing.T) {
+	scheme := runtime.NewScheme()
+	_ = corev1.AddToScheme(scheme)
+
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	oldKey := now.Add(-48*time.Hour).Format(receiptKeyTime) + "-1"
+	newKey := now.Add(-time.Hour).Format(receiptKeyTime) + "-2"
+	newConfigMap := func(namespace, name string, managed bool, keys ...string) *corev1.ConfigMap {
+		configMap := &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace}, Data: map[string]string{}}
+		if managed {
+			configMap.Labels = map[string]string{managedByLabel: managedByKubeclean}
+		}
+		for _, key := range keys {
+			configMap.Data[key] = "{}"
+		}
+		return configMap
+	}
+	name := cleanupconfig.DefaultReceiptsName
+	client := fake.NewClientBuilder().WithScheme(scheme).WithObjects(
+		newConfigMap("team-a", name, true, oldKey, newKey),
+		newConfigMap("team-b", name, true, oldKey),
+		newConfigMap("team-c", name, false, oldKey),
+		newConfigMap("team-d", "other", true, oldKey),
+	).Build()
+
+	cfg := cleanupconfig.ReceiptsConfig{Enabled: true, MaxAge: cleanupconfig.Duration{Duration: 24 * time.Hour}}
+	if err := pruneReceipts(context.Background(), client, cfg, now); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	get := func(namespace, name string) (*corev1.ConfigMap, bool) {
+		configMap := &corev1.ConfigMap{}
+		err := client.Get(context.Background(), types.NamespacedName{Namespace: namespace, Name: name}, configMap)
+		return configMap, err == nil
+	}
+	if configMap, _ := get("team-a", name); len(configMap.Data) != 1 || configMap.Data[newKey] == "" {
+		t.Errorf("Expected only the recent receipt to be kept in team-a, got %v", configMap.Data)
+	}
+	if _, ok := get("team-b", name); ok {
+		t.Error("Expected the receipts ConfigMap left without receipts to be deleted")
+	}
+	for _, key := range []types.NamespacedName{{Namespace: "team-c", Name: name}, {Namespace: "team-d", Name: "other"}} {
+		if configMap, ok := get(key.Namespace, key.Name); !ok || len(configMap.Data) != 1 {
+			t.Errorf("Expected ConfigMap %s not owned as receipts to be left alone, got %v", key, configMap.Data)
+		}
+	}
+}
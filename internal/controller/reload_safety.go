@@ -0,0 +1,134 @@
+package controller
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	cleanupconfig "github.com/infrautils/kubeclean/internal/cleanup_config"
+	"github.com/infrautils/kubeclean/internal/notify"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+// ErrNoPendingConfig is returned when approving or rejecting a pending config while none is held.
+var ErrNoPendingConfig = errors.New("no config is pending approval")
+
+// PendingConfig is a reloaded config held back by reloadSafety until it is approved.
+type PendingConfig struct {
+	Config     *cleanupconfig.CleanupConfig
+	Simulation SimulationResult // Matches of the active config compared with the held config.
+	Since      time.Time        // When the config was held back.
+}
+
+// reloadGate holds the config held back by reloadSafety.
+type reloadGate struct {
+	mu      sync.Mutex
+	pending *PendingConfig
+	// held is set from holding a config back until a config is applied; a rejected config stays held,
+	// since the file on disk still differs from the active config.
+	held bool
+}
+
+// AllowReload simulates a reloaded config and reports whether it may replace the active config.
+// A config changing the number of matched pods by more than reloadSafety allows is held back,
+// replacing any config held before, and alerted on until it is approved or rejected. It implements
+// cleanupconfig.ReloadGuard.
+func (c *PodCleanController) AllowReload(ctx context.Context, candidate *cleanupconfig.CleanupConfig) bool {
+	cfg := c.CleanupConfig.ReloadSafety
+
+	c.reload.mu.Lock()
+	defer c.reload.mu.Unlock()
+
+	c.reload.pending = nil
+	c.reload.held = false
+	pendingConfig.Set(0)
+	if !cfg.Enabled {
+		c.overrides.revalidate(ctx, candidate)
+		return true
+	}
+
+	result := c.Simulate(ctx, candidate)
+	active, next := totalMatched(result.Active), totalMatched(result.Candidate)
+	if !cfg.Exceeds(active, next) {
+		c.overrides.revalidate(ctx, candidate)
+		return true
+	}
+
+	c.reload.pending = &PendingConfig{Config: candidate, Simulation: result, Since: time.Now()}
+	c.reload.held = true
+	pendingConfig.Set(1)
+
+	message := fmt.Sprintf("Config reload held back pending approval: it matches %d pod(s), the active config %d", next, active)
+	logger := log.FromContext(ctx)
+	logger.Info(message, "maxChangeFactor", cfg.ChangeFactor())
+
+	notifier, err := notify.NewNotifier(c.notificationConfig(ctx), nil)
+	if err == nil {
+		err = notifier.Notify(ctx, cfg.NotificationSinks, notify.Event{Pods: next, Message: message})
+	}
+	if err != nil {
+		logger.Error(err, "Failed to send notification about the held config")
+	}
+
+	return false
+}
+
+// ReloadHeld reports whether the last reloaded config was held back and has not been applied
+// since. It implements cleanupconfig.ReloadGuard.
+func (c *PodCleanController) ReloadHeld() bool {
+	c.reload.mu.Lock()
+	defer c.reload.mu.Unlock()
+	return c.reload.held
+}
+
+// PendingConfig returns the config held back by reloadSafety, if any.
+func (c *PodCleanController) PendingConfig() (PendingConfig, bool) {
+	c.reload.mu.Lock()
+	defer c.reload.mu.Unlock()
+	if c.reload.pending == nil {
+		return PendingConfig{}, false
+	}
+	return *c.reload.pending, true
+}
+
+// ApprovePendingConfig replaces the active config with the held config and returns it.
+func (c *PodCleanController) ApprovePendingConfig() (PendingConfig, error) {
+	c.reload.mu.Lock()
+	defer c.reload.mu.Unlock()
+	if c.reload.pending == nil {
+		return PendingConfig{}, ErrNoPendingConfig
+	}
+
+	pending := *c.reload.pending
+	c.overrides.revalidate(context.TODO(), pending.Config)
+	*c.CleanupConfig = *pending.Config
+	c.reload.pending = nil
+	c.reload.held = false
+	pendingConfig.Set(0)
+	return pending, nil
+}
+
+// RejectPendingConfig discards the held config and returns it. The active config stays in place
+// until the config file changes again.
+func (c *PodCleanController) RejectPendingConfig() (PendingConfig, error) {
+	c.reload.mu.Lock()
+	defer c.reload.mu.Unlock()
+	if c.reload.pending == nil {
+		return PendingConfig{}, ErrNoPendingConfig
+	}
+
+	pending := *c.reload.pending
+	c.reload.pending = nil
+	pendingConfig.Set(0)
+	return pending, nil
+}
+
+func totalMatched(matchedByRule map[string]int) int {
+	var total int
+	for _, matched := range matchedByRule {
+		total += matched
+	}
+	return total
+}
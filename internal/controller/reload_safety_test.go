@@ -0,0 +1,78 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	cleanupconfig "github.com/infrautils/kubeclean/internal/cleanup_config"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestPodCleanController_AllowReload(t *testing.T) {
+	scheme := runtime.NewScheme()
+	_ = corev1.AddToScheme(scheme)
+
+	builder := fake.NewClientBuilder().WithScheme(scheme)
+	for i := range 12 {
+		builder = builder.WithObjects(&corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: fmt.Sprintf("failed-%d", i), Namespace: "default", CreationTimestamp: metav1.NewTime(time.Now().Add(-2 * time.Hour))},
+			Status:     corev1.PodStatus{Phase: corev1.PodFailed},
+		})
+	}
+	failedRule := func(namespaces ...string) cleanupconfig.PodCleanupConfig {
+		return cleanupconfig.PodCleanupConfig{Enabled: true, Rules: []cleanupconfig.PodCleanRule{
+			{Name: "failed", Enabled: true, Phase: "Failed", TTL: cleanupconfig.Duration{Duration: time.Hour}, Namespaces: namespaces},
+		}}
+	}
+
+	active := &cleanupconfig.CleanupConfig{
+		PodCleanupConfig: failedRule("kube-system"),
+		ReloadSafety:     cleanupconfig.ReloadSafetyConfig{Enabled: true},
+	}
+	controller := NewPodCleanController(builder.Build(), scheme, active)
+
+	// Matching 12 pods instead of none is more than the default factor of 3.
+	candidate := &cleanupconfig.CleanupConfig{PodCleanupConfig: failedRule("default"), ReloadSafety: active.ReloadSafety}
+	if controller.AllowReload(context.Background(), candidate) {
+		t.Fatal("Expected the reload to be held back")
+	}
+	pending, ok := controller.PendingConfig()
+	if !ok || pending.Config != candidate || pending.Simulation.Candidate["failed"] != 12 {
+		t.Fatalf("Expected the candidate to be pending with 12 matches, got %+v", pending)
+	}
+	if !controller.ReloadHeld() {
+		t.Error("Expected the reload to be held")
+	}
+
+	if _, err := controller.RejectPendingConfig(); err != nil {
+		t.Fatalf("Failed to reject the pending config: %v", err)
+	}
+	if _, ok := controller.PendingConfig(); ok || !controller.ReloadHeld() {
+		t.Error("Expected a rejected config to stay held without being pending")
+	}
+	if active.PodCleanupConfig.Rules[0].Namespaces[0] != "kube-system" {
+		t.Error("Expected a rejected config not to be applied")
+	}
+
+	controller.AllowReload(context.Background(), candidate)
+	if _, err := controller.ApprovePendingConfig(); err != nil {
+		t.Fatalf("Failed to approve the pending config: %v", err)
+	}
+	if active.PodCleanupConfig.Rules[0].Namespaces[0] != "default" || controller.ReloadHeld() {
+		t.Errorf("Expected the approved config to be applied, got namespaces %v", active.PodCleanupConfig.Rules[0].Namespaces)
+	}
+	if _, err := controller.ApprovePendingConfig(); err != ErrNoPendingConfig {
+		t.Errorf("Expected %v without a pending config, got %v", ErrNoPendingConfig, err)
+	}
+
+	// Small changes are applied right away.
+	candidate = &cleanupconfig.CleanupConfig{PodCleanupConfig: failedRule("default", "kube-system"), ReloadSafety: active.ReloadSafety}
+	if !controller.AllowReload(context.Background(), candidate) {
+		t.Error("Expected a reload matching the same pods to be allowed")
+	}
+}
@@ -0,0 +1,402 @@
+package controller
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"slices"
+	"sync"
+	"time"
+
+	cleanupconfig "github.com/infrautils/kubeclean/internal/cleanup_config"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/util/retry"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+// deadLettersSize bounds the dead-lettered pods kept in memory and in the persisted queue.
+const deadLettersSize = 100
+
+// Keys of the ConfigMap persisting the retry queue.
+const (
+	retryQueuePendingKey     = "pending"
+	retryQueueDeadLettersKey = "deadLetters"
+)
+
+// RetryEntry is a pod whose deletion failed with a transient error.
+type RetryEntry struct {
+	Rule        string      `json:"rule"`
+	Namespace   string      `json:"namespace"`
+	Name        string      `json:"name"`
+	UID         types.UID   `json:"uid,omitempty"` // Guards retries against pods recreated under the same name.
+	Attempts    int         `json:"attempts"`      // Failed deletions so far.
+	Reason      ErrorReason `json:"reason"`        // Reason of the last failure.
+	Error       string      `json:"error"`         // Message of the last failure.
+	FirstFailed time.Time   `json:"firstFailed"`
+	LastFailed  time.Time   `json:"lastFailed"`
+}
+
+// retryQueue holds the pods awaiting another deletion attempt and those that exhausted their
+// attempts. It is loaded from the configured ConfigMap before its first use.
+type retryQueue struct {
+	mu      sync.Mutex
+	loaded  bool
+	dirty   bool // Changed since it was last persisted.
+	pending map[types.NamespacedName]*RetryEntry
+	dead    recent[RetryEntry]
+}
+
+func newRetryQueue() *retryQueue {
+	return &retryQueue{pending: map[types.NamespacedName]*RetryEntry{}, dead: recent[RetryEntry]{size: deadLettersSize}}
+}
+
+// PendingRetries returns the pods awaiting another deletion attempt, oldest failure first.
+func (c *PodCleanController) PendingRetries() []RetryEntry {
+	q := c.retries
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return q.sortedPending()
+}
+
+// DeadLetters returns up to limit pods whose deletion was given up on, newest first. A limit of 0
+// returns every dead letter kept.
+func (c *PodCleanController) DeadLetters(limit int) []RetryEntry {
+	return c.retries.dead.latest(limit)
+}
+
+// sortedPending returns copies of the pending entries, oldest failure first. Callers hold mu.
+func (q *retryQueue) sortedPending() []RetryEntry {
+	entries := make([]RetryEntry, 0, len(q.pending))
+	for _, entry := range q.pending {
+		entries = append(entries, *entry)
+	}
+	slices.SortFunc(entries, func(a, b RetryEntry) int { return a.FirstFailed.Compare(b.FirstFailed) })
+	return entries
+}
+
+// isTransient reports whether a deletion failing for reason may succeed when retried unchanged.
+// Failures kubeclean cannot classify are not retried.
+func isTransient(reason ErrorReason) bool {
+	switch reason {
+	case ErrorReasonTimeout, ErrorReasonThrottled:
+		return true
+	default:
+		return false
+	}
+}
+
+// retryDeleteAction deletes queued pods, failing with a conflict for a pod recreated under the
+// same name since it was checked.
+type retryDeleteAction struct{ deleteAction }
+
+func (retryDeleteAction) Apply(ctx context.Context, k8sClient client.Client, pod *corev1.Pod) error {
+	return k8sClient.Delete(ctx, pod, client.Preconditions{UID: &pod.UID})
+}
+
+// retryFailedDeletions retries the queued pods ahead of the run's new candidates, within budget
+// and in batches like them. Each pod is first checked against its rule in cfg, the effective
+// config of the run: entries whose rule is gone, disabled or in cooldown, or no longer selects the
+// pod, are dropped. It returns the pods it attempted, so rules matching them again do not retry
+// them a second time in the run.
+func (c *PodCleanController) retryFailedDeletions(ctx context.Context, cfg *cleanupconfig.CleanupConfig, run *cleanupRun, budget *deletionBudget) map[types.NamespacedName]bool {
+	retryCfg := c.CleanupConfig.RetryQueue
+	if !retryCfg.Enabled || run.DryRun {
+		return nil
+	}
+	logger := log.FromContext(ctx)
+
+	q := c.retries
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	c.loadRetryQueue(ctx)
+
+	rules := map[string]cleanupconfig.PodCleanRule{}
+	for _, rule := range cfg.PodCleanupConfig.EffectiveRules() {
+		rules[rule.Name] = rule
+	}
+	c.PodMatcher.ResetCache()
+
+	var order []string
+	byRule := map[string][]corev1.Pod{}
+	for _, entry := range q.sortedPending() {
+		key := types.NamespacedName{Namespace: entry.Namespace, Name: entry.Name}
+		pod, drop, err := c.recheckRetry(ctx, cfg, run, rules, entry)
+		switch {
+		case errors.Is(err, errOutsideLane):
+			continue
+		case err != nil:
+			logger.Error(err, "Failed to check queued pod; keeping it for a later run", "pod", entry.Name, "namespace", entry.Namespace, "rule", entry.Rule)
+			continue
+		case drop != "":
+			logger.Info("Dropping queued pod deletion", "pod", entry.Name, "namespace", entry.Namespace, "rule", entry.Rule, "reason", drop)
+			delete(q.pending, key)
+			q.dirty = true
+			continue
+		}
+
+		if _, ok := byRule[entry.Rule]; !ok {
+			order = append(order, entry.Rule)
+		}
+		byRule[entry.Rule] = append(byRule[entry.Rule], *pod)
+	}
+
+	attempted := map[types.NamespacedName]bool{}
+	for _, rule := range order {
+		ruleCtx := withRule(ctx, rule)
+		pods, deferred := budget.allocateUnits(byRule[rule], budgetUnits(rules[rule]))
+		if len(deferred) > 0 {
+			logger.Info("Deletion budget exhausted; keeping queued pods for a later run", "rule", rule, "deferred", len(deferred),
+				"reasonCode", ReasonCodeDeferredByBudget)
+		}
+
+		for _, pod := range pods {
+			key := types.NamespacedName{Namespace: pod.Namespace, Name: pod.Name}
+			attempted[key] = true
+			logger.Info("Retrying pod deletion", "pod", pod.Name, "namespace", pod.Namespace, "rule", rule, "attempts", q.pending[key].Attempts)
+		}
+
+		results := BatchApply(ruleCtx, c.Client, retryDeleteAction{}, pods, c.CleanupConfig.EffectiveBatchSize(), c.CleanupConfig.EffectiveBatchDelay(), false)
+		budget.refund(results)
+		for _, result := range results {
+			key := types.NamespacedName{Namespace: result.Namespace, Name: result.Name}
+			var deleteErr *PodDeleteError
+			switch {
+			case result.Skipped != nil:
+				// Left alone because the circuit breaker opened; the pod stays queued for a later run.
+				delete(attempted, key)
+			case result.Deleted:
+				delete(q.pending, key)
+				q.dirty = true
+				run.retried++
+			case !errors.As(result.Err, &deleteErr), apierrors.IsConflict(deleteErr.Err):
+				// The pod is gone or was recreated; there is nothing left to retry.
+				delete(q.pending, key)
+				q.dirty = true
+			default:
+				run.recordDeleteFailures(rule, result.Err)
+				q.fail(ctx, run, key, *q.pending[key], deleteErr.Err, retryCfg.Attempts())
+			}
+		}
+	}
+
+	retryQueueLength.Set(float64(len(q.pending)))
+	return attempted
+}
+
+// errOutsideLane is returned by recheckRetry for entries whose rule's lane does not run.
+var errOutsideLane = errors.New("the rule's lane does not run")
+
+// recheckRetry fetches the pod of a queued deletion and checks it against its rule in cfg, as the
+// rule's own matching would. It returns why the entry must be dropped, or the pod to retry. Pods
+// of rules whose lane does not run in run are kept for a later run.
+func (c *PodCleanController) recheckRetry(ctx context.Context, cfg *cleanupconfig.CleanupConfig, run *cleanupRun, rules map[string]cleanupconfig.PodCleanRule, entry RetryEntry) (*corev1.Pod, string, error) {
+	rule, ok := rules[entry.Rule]
+	if !ok || !c.overrides.isEnabled(RuleKindPod, rule.Name, rule.Enabled) || run.coolingDown(rule.Name) {
+		return nil, "the rule is gone, disabled or in cooldown", nil
+	}
+	if !run.inLane(rule.Lane) {
+		return nil, "", errOutsideLane
+	}
+	rule, err := cfg.Constraints.Enforce(withGlobalSettings(rule, cfg.PodCleanupConfig))
+	if err != nil {
+		return nil, "the rule is outside the constraints", nil
+	}
+
+	pod := &corev1.Pod{}
+	err = withThrottleRetry(ctx, "get", func() error {
+		return c.Client.Get(ctx, types.NamespacedName{Namespace: entry.Namespace, Name: entry.Name}, pod)
+	})
+	switch {
+	case apierrors.IsNotFound(err):
+		return nil, "the pod is gone", nil
+	case err != nil:
+		return nil, "", err
+	case entry.UID != "" && pod.UID != entry.UID:
+		return nil, "the pod was recreated", nil
+	case slices.Contains(rule.ForbiddenNamespaces, pod.Namespace):
+		return nil, string(SkipReasonNamespaceForbidden), nil
+	}
+
+	if reason := c.PodMatcher.EvaluatePod(pod, rule); reason != SkipReasonNone {
+		return nil, string(reason), nil
+	}
+	reason, err := c.PodMatcher.evaluateSurroundings(ctx, pod, rule)
+	if err != nil || reason != SkipReasonNone {
+		return nil, string(reason), err
+	}
+	return pod, "", nil
+}
+
+// queueFailedDeletions queues the pods whose deletion for the rule failed transiently in err.
+func (c *PodCleanController) queueFailedDeletions(ctx context.Context, run *cleanupRun, rule string, err error) {
+	cfg := c.CleanupConfig.RetryQueue
+	if !cfg.Enabled || err == nil {
+		return
+	}
+
+	q := c.retries
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	c.loadRetryQueue(ctx)
+
+	errs := []error{err}
+	if joined, ok := err.(interface{ Unwrap() []error }); ok {
+		errs = joined.Unwrap()
+	}
+	for _, e := range errs {
+		var deleteErr *PodDeleteError
+		if !errors.As(e, &deleteErr) {
+			continue
+		}
+		key := types.NamespacedName{Namespace: deleteErr.Namespace, Name: deleteErr.Name}
+		entry := RetryEntry{Rule: rule, Namespace: deleteErr.Namespace, Name: deleteErr.Name, UID: deleteErr.UID}
+		if existing, ok := q.pending[key]; ok {
+			entry = *existing
+		}
+		q.fail(ctx, run, key, entry, deleteErr.Err, cfg.Attempts())
+	}
+
+	retryQueueLength.Set(float64(len(q.pending)))
+}
+
+// fail records a failed deletion of the entry's pod, queueing it for another attempt if the
+// failure is transient and attempts remain, and dead-lettering it otherwise. Callers hold mu.
+func (q *retryQueue) fail(ctx context.Context, run *cleanupRun, key types.NamespacedName, entry RetryEntry, err error, maxAttempts int) {
+	now := time.Now()
+	if entry.FirstFailed.IsZero() {
+		entry.FirstFailed = now
+	}
+	entry.LastFailed = now
+	entry.Attempts++
+	entry.Reason = ClassifyError(err)
+	entry.Error = err.Error()
+
+	switch transient := isTransient(entry.Reason); {
+	case transient && entry.Attempts < maxAttempts:
+		q.pending[key] = &entry
+		q.dirty = true
+		return
+	case !transient && entry.Attempts == 1:
+		// Permanent failures of new candidates are only reported through the delete failure metrics.
+		return
+	}
+
+	delete(q.pending, key)
+	q.dirty = true
+	q.dead.record(entry)
+	run.deadLettered++
+	deadLetteredDeletionsTotal.WithLabelValues(entry.Rule, string(entry.Reason)).Inc()
+	log.FromContext(ctx).Error(err, "Giving up on pod deletion", "pod", entry.Name, "namespace", entry.Namespace,
+		"rule", entry.Rule, "attempts", entry.Attempts, "reason", entry.Reason, "reasonCode", entry.Reason.Code())
+}
+
+// loadRetryQueue reads the persisted queue on first use. Callers hold the queue's mutex.
+func (c *PodCleanController) loadRetryQueue(ctx context.Context) {
+	q := c.retries
+	if q.loaded {
+		return
+	}
+	q.loaded = true
+
+	namespace, name, ok := c.CleanupConfig.RetryQueue.ConfigMapKey()
+	if !ok {
+		return
+	}
+
+	configMap := &corev1.ConfigMap{}
+	err := withThrottleRetry(ctx, "get", func() error {
+		return c.Client.Get(ctx, types.NamespacedName{Namespace: namespace, Name: name}, configMap)
+	})
+	if apierrors.IsNotFound(err) {
+		return
+	}
+	if err != nil {
+		log.FromContext(ctx).Error(err, "Failed to load the retry queue", "configMap", c.CleanupConfig.RetryQueue.ConfigMap)
+		return
+	}
+
+	var pending, dead []RetryEntry
+	if err := errors.Join(
+		unmarshalIfSet(configMap.Data[retryQueuePendingKey], &pending),
+		unmarshalIfSet(configMap.Data[retryQueueDeadLettersKey], &dead),
+	); err != nil {
+		log.FromContext(ctx).Error(err, "Failed to decode the retry queue", "configMap", c.CleanupConfig.RetryQueue.ConfigMap)
+	}
+	for i := range pending {
+		q.pending[types.NamespacedName{Namespace: pending[i].Namespace, Name: pending[i].Name}] = &pending[i]
+	}
+	for _, entry := range dead {
+		q.dead.record(entry)
+	}
+}
+
+func unmarshalIfSet(data string, v any) error {
+	if data == "" {
+		return nil
+	}
+	return json.Unmarshal([]byte(data), v)
+}
+
+// saveRetryQueue persists the queue to the configured ConfigMap, if any, when it changed. Failures
+// are logged; the in-memory queue stays authoritative until the next restart.
+func (c *PodCleanController) saveRetryQueue(ctx context.Context) {
+	cfg := c.CleanupConfig.RetryQueue
+	namespace, name, ok := cfg.ConfigMapKey()
+	if !cfg.Enabled || !ok {
+		return
+	}
+
+	q := c.retries
+	q.mu.Lock()
+	if !q.dirty {
+		q.mu.Unlock()
+		return
+	}
+	q.dirty = false
+	pending, err := json.Marshal(q.sortedPending())
+	q.mu.Unlock()
+	if err != nil {
+		log.FromContext(ctx).Error(err, "Failed to encode the retry queue")
+		return
+	}
+	dead := q.dead.latest(0)
+	slices.Reverse(dead)
+	deadLetters, err := json.Marshal(dead)
+	if err != nil {
+		log.FromContext(ctx).Error(err, "Failed to encode the retry queue")
+		return
+	}
+	data := map[string]string{retryQueuePendingKey: string(pending), retryQueueDeadLettersKey: string(deadLetters)}
+
+	key := types.NamespacedName{Namespace: namespace, Name: name}
+	if err := retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		configMap := &corev1.ConfigMap{}
+		err := withThrottleRetry(ctx, "get", func() error { return c.Client.Get(ctx, key, configMap) })
+		if apierrors.IsNotFound(err) {
+			configMap = &corev1.ConfigMap{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      name,
+					Namespace: namespace,
+					Labels:    map[string]string{managedByLabel: managedByKubeclean},
+				},
+				Data: data,
+			}
+			return withThrottleRetry(ctx, "create", func() error { return c.Client.Create(ctx, configMap) })
+		}
+		if err != nil {
+			return err
+		}
+
+		configMap.Data = data
+		return withThrottleRetry(ctx, "update", func() error { return c.Client.Update(ctx, configMap) })
+	}); err != nil {
+		log.FromContext(ctx).Error(err, "Failed to persist the retry queue", "configMap", cfg.ConfigMap)
+		q.mu.Lock()
+		q.dirty = true
+		q.mu.Unlock()
+	}
+}
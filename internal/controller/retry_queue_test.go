@@ -0,0 +1,152 @@
+package controller
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	cleanupconfig "github.com/infrautils/kubeclean/internal/cleanup_config"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	ctrlclient "sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
+)
+
+func TestRunCleanUp_RetriesTransientDeleteFailures(t *testing.T) {
+	scheme := runtime.NewScheme()
+	_ = corev1.AddToScheme(scheme)
+
+	newPod := func(name string) *corev1.Pod {
+		return &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default", UID: types.UID(name), CreationTimestamp: metav1.NewTime(time.Now().Add(-2 * time.Hour))},
+			Status:     corev1.PodStatus{Phase: corev1.PodSucceeded},
+		}
+	}
+
+	podsResource := schema.GroupResource{Resource: "pods"}
+	attempts := map[string]int{}
+	client := fake.NewClientBuilder().WithScheme(scheme).
+		WithRuntimeObjects(newPod("flaky"), newPod("stuck"), newPod("forbidden"), newPod("internal")).
+		WithInterceptorFuncs(interceptor.Funcs{
+			Delete: func(ctx context.Context, c ctrlclient.WithWatch, obj ctrlclient.Object, opts ...ctrlclient.DeleteOption) error {
+				attempts[obj.GetName()]++
+				switch {
+				case obj.GetName() == "flaky" && attempts["flaky"] == 1:
+					return apierrors.NewServerTimeout(podsResource, "delete", 0)
+				case obj.GetName() == "stuck":
+					return apierrors.NewTimeoutError("deleting pod", 0)
+				case obj.GetName() == "internal":
+					return apierrors.NewInternalError(context.DeadlineExceeded)
+				case obj.GetName() == "forbidden":
+					return apierrors.NewForbidden(podsResource, "forbidden", nil)
+				}
+				return c.Delete(ctx, obj, opts...)
+			},
+		}).Build()
+
+	cfg := &cleanupconfig.CleanupConfig{
+		BatchSize: 10,
+		PodCleanupConfig: cleanupconfig.PodCleanupConfig{
+			Enabled: true,
+			Rules:   []cleanupconfig.PodCleanRule{{Name: "succeeded", Enabled: true, Phase: "Succeeded", TTL: cleanupconfig.Duration{Duration: time.Hour}}},
+		},
+		RetryQueue: cleanupconfig.RetryQueueConfig{Enabled: true, MaxAttempts: 2, ConfigMap: "kubeclean/retries"},
+	}
+	podCleanController := NewPodCleanController(client, scheme, cfg)
+
+	podCleanController.RunCleanUp(context.Background())
+
+	pending := podCleanController.PendingRetries()
+	if len(pending) != 2 || pending[0].Attempts != 1 || pending[1].Attempts != 1 {
+		t.Fatalf("Expected the flaky and stuck pods to be queued after one attempt, got %+v", pending)
+	}
+
+	// A restarted controller picks the queue up from its ConfigMap.
+	restarted := NewPodCleanController(client, scheme, cfg)
+	summary := restarted.RunCleanUp(context.Background())
+
+	if summary.Retried != 1 || summary.DeadLettered != 1 {
+		t.Errorf("Expected 1 retried and 1 dead-lettered pod, got %d and %d", summary.Retried, summary.DeadLettered)
+	}
+	if attempts["flaky"] != 2 || attempts["stuck"] != 2 {
+		t.Errorf("Expected queued pods to be attempted once per run, got %v", attempts)
+	}
+	if pending := restarted.PendingRetries(); len(pending) != 0 {
+		t.Errorf("Expected an empty queue, got %+v", pending)
+	}
+
+	dead := restarted.DeadLetters(0)
+	if len(dead) != 1 || dead[0].Name != "stuck" || dead[0].Attempts != 2 || dead[0].Reason != ErrorReasonTimeout {
+		t.Errorf("Expected the stuck pod to be dead-lettered, got %+v", dead)
+	}
+	for _, entry := range append(restarted.PendingRetries(), dead...) {
+		if entry.Name == "forbidden" || entry.Name == "internal" {
+			t.Errorf("Expected permanent and unclassified failures not to be queued, got %+v", entry)
+		}
+	}
+}
+
+func TestRunCleanUp_DropsQueuedPodsRulesNoLongerSelect(t *testing.T) {
+	scheme := runtime.NewScheme()
+	_ = corev1.AddToScheme(scheme)
+
+	newPod := func(name string) *corev1.Pod {
+		return &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default", UID: types.UID(name), CreationTimestamp: metav1.NewTime(time.Now().Add(-2 * time.Hour))},
+			Status:     corev1.PodStatus{Phase: corev1.PodSucceeded},
+		}
+	}
+
+	failing := true
+	deleted := map[string]int{}
+	client := fake.NewClientBuilder().WithScheme(scheme).
+		WithRuntimeObjects(newPod("opted-out"), newPod("removed-rule"), newPod("retried")).
+		WithInterceptorFuncs(interceptor.Funcs{
+			Delete: func(ctx context.Context, c ctrlclient.WithWatch, obj ctrlclient.Object, opts ...ctrlclient.DeleteOption) error {
+				if failing {
+					return apierrors.NewTimeoutError("deleting pod", 0)
+				}
+				deleted[obj.GetName()]++
+				return c.Delete(ctx, obj, opts...)
+			},
+		}).Build()
+
+	cfg := &cleanupconfig.CleanupConfig{
+		BatchSize: 10,
+		PodCleanupConfig: cleanupconfig.PodCleanupConfig{
+			Enabled: true,
+			Rules:   []cleanupconfig.PodCleanRule{{Name: "succeeded", Enabled: true, Phase: "Succeeded", TTL: cleanupconfig.Duration{Duration: time.Hour}}},
+		},
+		RetryQueue: cleanupconfig.RetryQueueConfig{Enabled: true, MaxAttempts: 3, ConfigMap: "kubeclean/retries"},
+	}
+	podCleanController := NewPodCleanController(client, scheme, cfg)
+	podCleanController.RunCleanUp(context.Background())
+	if pending := podCleanController.PendingRetries(); len(pending) != 3 {
+		t.Fatalf("Expected 3 queued pods, got %+v", pending)
+	}
+
+	// The pod opts out, and the queue entry of another pod names a rule the config no longer has.
+	pod := &corev1.Pod{}
+	_ = client.Get(context.Background(), types.NamespacedName{Namespace: "default", Name: "opted-out"}, pod)
+	pod.Annotations = map[string]string{"kubeclean/disabled": "true"}
+	_ = client.Update(context.Background(), pod)
+	podCleanController.retries.pending[types.NamespacedName{Namespace: "default", Name: "removed-rule"}].Rule = "removed"
+
+	failing = false
+	summary := podCleanController.RunCleanUp(context.Background())
+
+	if summary.Retried != 1 || deleted["retried"] != 1 {
+		t.Errorf("Expected only the pod its rule still selects to be retried, got %d retried and deletions %v", summary.Retried, deleted)
+	}
+	if deleted["opted-out"] != 0 {
+		t.Errorf("Expected the opted-out pod not to be deleted, got %v", deleted)
+	}
+	if pending := podCleanController.PendingRetries(); len(pending) != 0 {
+		t.Errorf("Expected the dropped entries to leave the queue, got %+v", pending)
+	}
+}
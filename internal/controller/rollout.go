@@ -0,0 +1,127 @@
+package controller
+
+import (
+	"context"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// isOwnerRollingOut reports whether the pod belongs to a Deployment, through its ReplicaSet, or to
+// a StatefulSet whose rollout is in flight. Deleting such pods skews the availability the rollout
+// controllers compute, so rules may leave them until the rollout settles.
+func (pm *PodMatcher) isOwnerRollingOut(ctx context.Context, pod *corev1.Pod) (bool, error) {
+	ref := metav1.GetControllerOf(pod)
+	if ref == nil {
+		return false, nil
+	}
+
+	switch ref.Kind {
+	case "ReplicaSet":
+		replicaSet := &appsv1.ReplicaSet{}
+		if found, err := pm.getOwner(ctx, pod.Namespace, ref.Name, replicaSet); !found || err != nil {
+			return false, err
+		}
+		ref = metav1.GetControllerOf(replicaSet)
+		if ref == nil || ref.Kind != "Deployment" {
+			return false, nil
+		}
+	case "StatefulSet":
+	default:
+		return false, nil
+	}
+
+	owner := Owner{Kind: ref.Kind, Namespace: pod.Namespace, Name: ref.Name}
+	if rolling, ok := pm.rollingOutOwners[owner]; ok {
+		return rolling, nil
+	}
+
+	rolling := false
+	switch ref.Kind {
+	case "Deployment":
+		deployment := &appsv1.Deployment{}
+		found, err := pm.getOwner(ctx, pod.Namespace, ref.Name, deployment)
+		if err != nil {
+			return false, err
+		}
+		rolling = found && isDeploymentRollingOut(deployment)
+	case "StatefulSet":
+		statefulSet := &appsv1.StatefulSet{}
+		found, err := pm.getOwner(ctx, pod.Namespace, ref.Name, statefulSet)
+		if err != nil {
+			return false, err
+		}
+		rolling = found && isStatefulSetRollingOut(statefulSet)
+	}
+
+	if pm.rollingOutOwners == nil {
+		pm.rollingOutOwners = map[Owner]bool{}
+	}
+	pm.rollingOutOwners[owner] = rolling
+	return rolling, nil
+}
+
+// getOwner fetches a controller into obj and reports whether it exists.
+func (pm *PodMatcher) getOwner(ctx context.Context, namespace, name string, obj client.Object) (bool, error) {
+	err := withThrottleRetry(ctx, "get", func() error {
+		return pm.client.Get(ctx, types.NamespacedName{Namespace: namespace, Name: name}, obj)
+	})
+	if apierrors.IsNotFound(err) {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+// isDeploymentRollingOut mirrors `kubectl rollout status`: a rollout is in flight until the
+// controller observed the latest spec and every replica is updated and available. Paused
+// rollouts and those past their progress deadline are not progressing and do not count.
+func isDeploymentRollingOut(deployment *appsv1.Deployment) bool {
+	if deployment.Spec.Paused {
+		return false
+	}
+	if deployment.Generation > deployment.Status.ObservedGeneration {
+		return true
+	}
+	for _, condition := range deployment.Status.Conditions {
+		if condition.Type == appsv1.DeploymentProgressing && condition.Reason == "ProgressDeadlineExceeded" {
+			return false
+		}
+	}
+
+	replicas := int32(1)
+	if deployment.Spec.Replicas != nil {
+		replicas = *deployment.Spec.Replicas
+	}
+	status := deployment.Status
+	return status.UpdatedReplicas < replicas || status.Replicas > status.UpdatedReplicas ||
+		status.AvailableReplicas < status.UpdatedReplicas
+}
+
+// isStatefulSetRollingOut mirrors `kubectl rollout status` for StatefulSets. With the OnDelete
+// strategy, pods are only replaced when deleted, so there is no rollout to interfere with.
+func isStatefulSetRollingOut(statefulSet *appsv1.StatefulSet) bool {
+	if statefulSet.Spec.UpdateStrategy.Type == appsv1.OnDeleteStatefulSetStrategyType {
+		return false
+	}
+	if statefulSet.Generation > statefulSet.Status.ObservedGeneration {
+		return true
+	}
+
+	replicas := int32(1)
+	if statefulSet.Spec.Replicas != nil {
+		replicas = *statefulSet.Spec.Replicas
+	}
+	status := statefulSet.Status
+	if status.ReadyReplicas < replicas {
+		return true
+	}
+
+	if update := statefulSet.Spec.UpdateStrategy.RollingUpdate; update != nil && update.Partition != nil && *update.Partition > 0 {
+		return status.UpdatedReplicas < replicas-*update.Partition
+	}
+	return status.UpdateRevision != status.CurrentRevision
+}
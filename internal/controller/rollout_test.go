@@ -0,0 +1,157 @@
+package controller
+
+import (
+	"context"
+	"slices"
+	"testing"
+	"time"
+
+	cleanupconfig "github.com/infrautils/kubeclean/internal/cleanup_config"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestFindPodsToCleanup_SkipDuringRollout(t *testing.T) {
+	scheme := runtime.NewScheme()
+	_ = corev1.AddToScheme(scheme)
+	_ = appsv1.AddToScheme(scheme)
+
+	three := int32(3)
+	deployment := func(name string, status appsv1.DeploymentStatus) *appsv1.Deployment {
+		return &appsv1.Deployment{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default", Generation: 2},
+			Spec:       appsv1.DeploymentSpec{Replicas: &three},
+			Status:     status,
+		}
+	}
+	replicaSet := func(name, deployment string) *appsv1.ReplicaSet {
+		return &appsv1.ReplicaSet{ObjectMeta: metav1.ObjectMeta{
+			Name: name, Namespace: "default", OwnerReferences: controllerRef("Deployment", deployment),
+		}}
+	}
+	pod := func(name, kind, owner string) *corev1.Pod {
+		return &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: name, Namespace: "default", OwnerReferences: controllerRef(kind, owner),
+				CreationTimestamp: metav1.NewTime(time.Now().Add(-2 * time.Hour)),
+			},
+			Status: corev1.PodStatus{Phase: corev1.PodFailed},
+		}
+	}
+
+	settled := appsv1.DeploymentStatus{ObservedGeneration: 2, Replicas: 3, UpdatedReplicas: 3, AvailableReplicas: 3}
+	rolling := appsv1.DeploymentStatus{ObservedGeneration: 2, Replicas: 4, UpdatedReplicas: 1, AvailableReplicas: 3}
+	statefulSet := &appsv1.StatefulSet{
+		ObjectMeta: metav1.ObjectMeta{Name: "db", Namespace: "default", Generation: 1},
+		Spec:       appsv1.StatefulSetSpec{Replicas: &three},
+		Status: appsv1.StatefulSetStatus{
+			ObservedGeneration: 1, ReadyReplicas: 3, CurrentRevision: "db-1", UpdateRevision: "db-2",
+		},
+	}
+
+	k8sClient := fake.NewClientBuilder().WithScheme(scheme).WithObjects(
+		deployment("api", settled), deployment("web", rolling), statefulSet,
+		replicaSet("api-1", "api"), replicaSet("web-2", "web"),
+		pod("api-1-a", "ReplicaSet", "api-1"),
+		pod("web-2-a", "ReplicaSet", "web-2"),
+		pod("db-0", "StatefulSet", "db"),
+		pod("orphan", "ReplicaSet", "gone"),
+	).Build()
+
+	rule := cleanupconfig.PodCleanRule{Name: "failed", Enabled: true, Phase: "Failed", TTL: cleanupconfig.Duration{Duration: time.Hour}}
+	names := func(rule cleanupconfig.PodCleanRule) []string {
+		t.Helper()
+		pods, err := NewPodMatcher(k8sClient).FindPodsToCleanup(context.Background(), rule)
+		if err != nil {
+			t.Fatalf("FindPodsToCleanup failed: %v", err)
+		}
+		var names []string
+		for _, pod := range pods {
+			names = append(names, pod.Name)
+		}
+		slices.Sort(names)
+		return names
+	}
+
+	if got := names(rule); len(got) != 4 {
+		t.Errorf("Expected rollouts to be ignored by default, got %v", got)
+	}
+
+	rule.SkipDuringRollout = true
+	if got, want := names(rule), []string{"api-1-a", "orphan"}; !slices.Equal(got, want) {
+		t.Errorf("Expected pods of workloads mid-rollout to be skipped, got %v, want %v", got, want)
+	}
+}
+
+func TestIsDeploymentRollingOut(t *testing.T) {
+	two := int32(2)
+	tests := []struct {
+		name       string
+		deployment appsv1.Deployment
+		want       bool
+	}{
+		{
+			name: "settled",
+			deployment: appsv1.Deployment{
+				Spec:   appsv1.DeploymentSpec{Replicas: &two},
+				Status: appsv1.DeploymentStatus{Replicas: 2, UpdatedReplicas: 2, AvailableReplicas: 2},
+			},
+			want: false,
+		},
+		{
+			name: "spec not yet observed",
+			deployment: appsv1.Deployment{
+				ObjectMeta: metav1.ObjectMeta{Generation: 3},
+				Spec:       appsv1.DeploymentSpec{Replicas: &two},
+				Status:     appsv1.DeploymentStatus{ObservedGeneration: 2, Replicas: 2, UpdatedReplicas: 2, AvailableReplicas: 2},
+			},
+			want: true,
+		},
+		{
+			name: "old replicas terminating",
+			deployment: appsv1.Deployment{
+				Spec:   appsv1.DeploymentSpec{Replicas: &two},
+				Status: appsv1.DeploymentStatus{Replicas: 3, UpdatedReplicas: 2, AvailableReplicas: 2},
+			},
+			want: true,
+		},
+		{
+			name: "updated replicas not yet available",
+			deployment: appsv1.Deployment{
+				Spec:   appsv1.DeploymentSpec{Replicas: &two},
+				Status: appsv1.DeploymentStatus{Replicas: 2, UpdatedReplicas: 2, AvailableReplicas: 1},
+			},
+			want: true,
+		},
+		{
+			name: "paused",
+			deployment: appsv1.Deployment{
+				Spec:   appsv1.DeploymentSpec{Replicas: &two, Paused: true},
+				Status: appsv1.DeploymentStatus{Replicas: 2, UpdatedReplicas: 1, AvailableReplicas: 2},
+			},
+			want: false,
+		},
+		{
+			name: "progress deadline exceeded",
+			deployment: appsv1.Deployment{
+				Spec: appsv1.DeploymentSpec{Replicas: &two},
+				Status: appsv1.DeploymentStatus{
+					Replicas: 3, UpdatedReplicas: 1, AvailableReplicas: 2,
+					Conditions: []appsv1.DeploymentCondition{{Type: appsv1.DeploymentProgressing, Reason: "ProgressDeadlineExceeded"}},
+				},
+			},
+			want: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := isDeploymentRollingOut(&tt.deployment); got != tt.want {
+				t.Errorf("isDeploymentRollingOut() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,80 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+
+	cleanupconfig "github.com/infrautils/kubeclean/internal/cleanup_config"
+	"github.com/infrautils/kubeclean/internal/notify"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+// markCooledDownRules records in run the pod rules of cfg in cooldown. Full passes use up one
+// run of the cooldown of every rule whose lane runs in them; targeted passes skip the rules
+// without counting.
+func (c *PodCleanController) markCooledDownRules(ctx context.Context, cfg *cleanupconfig.CleanupConfig, run *cleanupRun) {
+	if !c.CleanupConfig.RuleCooldown.Enabled {
+		return
+	}
+
+	logger := log.FromContext(ctx)
+	for _, rule := range cfg.PodCleanupConfig.EffectiveRules() {
+		if !run.inLane(rule.Lane) {
+			// Not run in this pass anyway, so the pass does not count as one the rule sat out.
+			continue
+		}
+		remaining := c.statuses.cooldown(rule.Name, !run.Scope.targeted())
+		if remaining == 0 {
+			continue
+		}
+		logger.Info("Skipping rule in cooldown", "rule", rule.Name, "remainingRuns", remaining)
+
+		if run.cooledDown == nil {
+			run.cooledDown = map[string]bool{}
+		}
+		run.cooledDown[rule.Name] = true
+	}
+}
+
+// coolingDown reports whether the named pod rule is in cooldown for run. Evaluations outside a
+// run, which have no run, ignore cooldowns.
+func (r *cleanupRun) coolingDown(name string) bool {
+	return r != nil && r.cooledDown[name]
+}
+
+// checkCooldown puts rule in cooldown if failed of its attempted actions and pod lists failed
+// in this run, for reasons, and alerts about it.
+func (c *PodCleanController) checkCooldown(ctx context.Context, run *cleanupRun, rule cleanupconfig.PodCleanRule, attempted, failed int, reasons map[ErrorReason]int) {
+	cfg := c.CleanupConfig.RuleCooldown
+	if !cfg.Trips(attempted, failed) {
+		return
+	}
+
+	logger := log.FromContext(ctx)
+	runs := cfg.CooldownRuns()
+	c.statuses.startCooldown(rule.Name, runs)
+	ruleCooldownsTotal.WithLabelValues(rule.Name).Inc()
+	logger.Info("Putting rule in cooldown after failures", "rule", rule.Name, "runs", runs, "attempted", attempted, "failed", failed,
+		"reasons", reasons, "reasonCodes", ErrorCodes(reasons))
+
+	if run.notifier == nil {
+		return
+	}
+
+	sinks := cfg.NotificationSinks
+	if len(sinks) == 0 {
+		sinks = rule.NotificationSinks
+	}
+
+	event := notify.Event{
+		RunID:   run.ID,
+		Rule:    rule.Name,
+		DryRun:  run.DryRun,
+		Summary: run.summarySoFar(),
+		Message: fmt.Sprintf("Rule %s put in cooldown for %d run(s): %d of %d attempt(s) failed (%s)",
+			rule.Name, runs, failed, attempted, TopErrorReasons(reasons, 3)),
+	}
+	if err := run.notifier.Notify(ctx, sinks, event); err != nil {
+		logger.Error(err, "Failed to send notification", "rule", rule.Name)
+	}
+}
@@ -0,0 +1,180 @@
+package controller
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	cleanupconfig "github.com/infrautils/kubeclean/internal/cleanup_config"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	ctrlclient "sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
+)
+
+func TestRunCleanUp_CoolsDownFailingRule(t *testing.T) {
+	scheme := runtime.NewScheme()
+	_ = corev1.AddToScheme(scheme)
+
+	var objects []runtime.Object
+	for _, name := range []string{"a", "b", "c"} {
+		objects = append(objects, &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default", CreationTimestamp: metav1.NewTime(time.Now().Add(-2 * time.Hour))},
+			Status:     corev1.PodStatus{Phase: corev1.PodSucceeded},
+		})
+	}
+
+	denied := &apierrors.StatusError{ErrStatus: metav1.Status{
+		Status:  metav1.StatusFailure,
+		Code:    403,
+		Reason:  metav1.StatusReasonForbidden,
+		Message: `admission webhook "guard.example.com" denied the request: pod is protected`,
+	}}
+	var deletes int
+	client := fake.NewClientBuilder().WithScheme(scheme).WithRuntimeObjects(objects...).
+		WithInterceptorFuncs(interceptor.Funcs{
+			Delete: func(context.Context, ctrlclient.WithWatch, ctrlclient.Object, ...ctrlclient.DeleteOption) error {
+				deletes++
+				return denied
+			},
+		}).Build()
+
+	controller := NewPodCleanController(client, scheme, &cleanupconfig.CleanupConfig{
+		PodCleanupConfig: cleanupconfig.PodCleanupConfig{
+			Enabled: true,
+			Rules: []cleanupconfig.PodCleanRule{
+				{Name: "succeeded", Enabled: true, Phase: "Succeeded", TTL: cleanupconfig.Duration{Duration: time.Hour}},
+			},
+		},
+		RuleCooldown: cleanupconfig.RuleCooldownConfig{Enabled: true, MinAttempts: 3, Runs: 2},
+	})
+
+	controller.RunCleanUp(context.Background())
+	if deletes != 3 {
+		t.Fatalf("Expected 3 deletion attempts, got %d", deletes)
+	}
+	if status, _ := controller.RuleStatus(RuleKindPod, "succeeded"); status.CooldownRuns != 2 {
+		t.Fatalf("Expected the rule to be in cooldown for 2 runs, got %+v", status)
+	}
+
+	// A targeted pass skips the rule without using up the cooldown.
+	controller.runCleanUp(context.Background(), "targeted", runScope{Namespace: "default"})
+	if status, _ := controller.RuleStatus(RuleKindPod, "succeeded"); deletes != 3 || status.CooldownRuns != 2 {
+		t.Errorf("Expected the targeted pass to skip the rule, got %d deletion attempts and %+v", deletes, status)
+	}
+
+	for run := 1; run <= 2; run++ {
+		summary := controller.RunCleanUp(context.Background())
+		if deletes != 3 || summary.Matched != 0 {
+			t.Errorf("Expected run %d to skip the rule, got %d deletion attempts and %d matched pod(s)", run, deletes, summary.Matched)
+		}
+	}
+	if status, _ := controller.RuleStatus(RuleKindPod, "succeeded"); status.CooldownRuns != 0 {
+		t.Errorf("Expected the cooldown to be over, got %+v", status)
+	}
+
+	controller.RunCleanUp(context.Background())
+	if deletes != 6 {
+		t.Errorf("Expected the rule to run again after its cooldown, got %d deletion attempts", deletes)
+	}
+}
+
+func TestRunCleanUp_CooldownCountsOnlyRunsOfTheRulesLane(t *testing.T) {
+	scheme := runtime.NewScheme()
+	_ = corev1.AddToScheme(scheme)
+
+	var objects []runtime.Object
+	for _, name := range []string{"a", "b", "c"} {
+		objects = append(objects, &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default", CreationTimestamp: metav1.NewTime(time.Now().Add(-2 * time.Hour))},
+			Status:     corev1.PodStatus{Phase: corev1.PodSucceeded},
+		})
+	}
+
+	var deletes int
+	client := fake.NewClientBuilder().WithScheme(scheme).WithRuntimeObjects(objects...).
+		WithInterceptorFuncs(interceptor.Funcs{
+			Delete: func(context.Context, ctrlclient.WithWatch, ctrlclient.Object, ...ctrlclient.DeleteOption) error {
+				deletes++
+				return apierrors.NewForbidden(corev1.Resource("pods"), "", nil)
+			},
+		}).Build()
+
+	controller := NewPodCleanController(client, scheme, &cleanupconfig.CleanupConfig{
+		PodCleanupConfig: cleanupconfig.PodCleanupConfig{
+			Enabled: true,
+			Rules: []cleanupconfig.PodCleanRule{
+				{Name: "succeeded", Enabled: true, Lane: cleanupconfig.LaneSlow, Phase: "Succeeded", TTL: cleanupconfig.Duration{Duration: time.Hour}},
+			},
+		},
+		Lanes:        cleanupconfig.LanesConfig{SlowEvery: 2},
+		RuleCooldown: cleanupconfig.RuleCooldownConfig{Enabled: true, MinAttempts: 3, Runs: 2},
+	})
+
+	controller.RunCleanUp(context.Background())
+	if status, _ := controller.RuleStatus(RuleKindPod, "succeeded"); deletes != 3 || status.CooldownRuns != 2 {
+		t.Fatalf("Expected the rule to be in cooldown for 2 runs after 3 deletion attempts, got %d and %+v", deletes, status)
+	}
+
+	// Only the runs of the slow lane, the third and fifth, count towards the cooldown.
+	for run, remaining := range []int{2, 1, 1, 0} {
+		controller.RunCleanUp(context.Background())
+		if status, _ := controller.RuleStatus(RuleKindPod, "succeeded"); deletes != 3 || status.CooldownRuns != remaining {
+			t.Errorf("Run %d: expected %d cooldown run(s) left and no new deletion attempts, got %+v and %d", run+2, remaining, status, deletes)
+		}
+	}
+
+	controller.RunCleanUp(context.Background())
+	controller.RunCleanUp(context.Background())
+	if deletes != 6 {
+		t.Errorf("Expected the rule to run again in the next slow lane, got %d deletion attempts", deletes)
+	}
+}
+
+func TestRunCleanUp_CooldownAppliesToOverriddenRules(t *testing.T) {
+	scheme := runtime.NewScheme()
+	_ = corev1.AddToScheme(scheme)
+
+	var objects []runtime.Object
+	for _, name := range []string{"a", "b", "c"} {
+		objects = append(objects, &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default", CreationTimestamp: metav1.NewTime(time.Now().Add(-2 * time.Hour))},
+			Status:     corev1.PodStatus{Phase: corev1.PodSucceeded},
+		})
+	}
+
+	var deletes int
+	client := fake.NewClientBuilder().WithScheme(scheme).WithRuntimeObjects(objects...).
+		WithInterceptorFuncs(interceptor.Funcs{
+			Delete: func(context.Context, ctrlclient.WithWatch, ctrlclient.Object, ...ctrlclient.DeleteOption) error {
+				deletes++
+				return apierrors.NewForbidden(corev1.Resource("pods"), "", nil)
+			},
+		}).Build()
+
+	controller := NewPodCleanController(client, scheme, &cleanupconfig.CleanupConfig{
+		PodCleanupConfig: cleanupconfig.PodCleanupConfig{
+			Enabled: true,
+			Rules: []cleanupconfig.PodCleanRule{
+				{Name: "succeeded", Enabled: true, Phase: "Succeeded", TTL: cleanupconfig.Duration{Duration: time.Hour}},
+			},
+		},
+		RuleCooldown: cleanupconfig.RuleCooldownConfig{Enabled: true, MinAttempts: 3, Runs: 1},
+	})
+	if _, _, err := controller.SetRuleEnabled(RuleKindPod, "succeeded", true); err != nil {
+		t.Fatalf("Failed to enable rule: %v", err)
+	}
+
+	controller.RunCleanUp(context.Background())
+	if status, _ := controller.RuleStatus(RuleKindPod, "succeeded"); deletes != 3 || status.CooldownRuns != 1 {
+		t.Fatalf("Expected the rule to be in cooldown after 3 deletion attempts, got %d and %+v", deletes, status)
+	}
+
+	// The runtime override enables the rule, but does not end its cooldown.
+	if summary := controller.RunCleanUp(context.Background()); deletes != 3 || summary.Matched != 0 {
+		t.Errorf("Expected the overridden rule to sit out its cooldown, got %d deletion attempts and %d matched pod(s)", deletes, summary.Matched)
+	}
+}
@@ -0,0 +1,134 @@
+package controller
+
+import (
+	"cmp"
+	"errors"
+	"maps"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+// RuleStatus describes a rule's health as of its most recent evaluation.
+type RuleStatus struct {
+	Kind                string    `json:"kind"` // Config section of the rule, such as pod or orphan; names are unique within it.
+	Name                string    `json:"name"`
+	LastRunTime         time.Time `json:"lastRunTime"`
+	LastMatched         int       `json:"lastMatched"`            // Resources the rule matched.
+	LastDeleted         int       `json:"lastDeleted"`            // Resources deleted; zero on dry-runs.
+	LastFailed          int       `json:"lastFailed"`             // Resources whose deletion failed.
+	LastError           string    `json:"lastError,omitempty"`    // Error of the last run, if it failed.
+	ConsecutiveFailures int       `json:"consecutiveFailures"`    // Runs in a row that ended with an error.
+	CooldownRuns        int       `json:"cooldownRuns,omitempty"` // Runs the rule is still skipped for after most of its actions failed.
+	Unavailable         string    `json:"unavailable,omitempty"`  // Why the rule was skipped, such as its kind not being served; empty when it ran.
+}
+
+// ruleStatuses holds the latest RuleStatus of every evaluated rule, keyed by kind and name.
+// Cooldowns are kept apart from the outcomes of the last run, which every evaluation replaces.
+// Statuses are lost when the controller restarts.
+type ruleStatuses struct {
+	mu        sync.RWMutex
+	statuses  map[ruleKey]RuleStatus // Without CooldownRuns, which cooldowns holds.
+	cooldowns map[string]int         // Runs each pod rule in cooldown is still skipped for, by name.
+}
+
+func newRuleStatuses() *ruleStatuses {
+	return &ruleStatuses{statuses: map[ruleKey]RuleStatus{}, cooldowns: map[string]int{}}
+}
+
+// record stores the outcome of evaluating the named rule of kind at now. err is the error failing
+// the rule, as returned by ruleError.
+func (s *ruleStatuses) record(kind, name string, now time.Time, matched, deleted, failed int, err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	key := ruleKey{kind: kind, name: name}
+	status := RuleStatus{Kind: kind, Name: name, LastRunTime: now, LastMatched: matched, LastDeleted: deleted, LastFailed: failed}
+	if err != nil {
+		status.LastError = err.Error()
+		status.ConsecutiveFailures = s.statuses[key].ConsecutiveFailures + 1
+	}
+	s.statuses[key] = status
+}
+
+// recordUnavailable stores that the named rule of kind was skipped at now because of err. It
+// neither fails the rule nor resets its consecutive failures.
+func (s *ruleStatuses) recordUnavailable(kind, name string, now time.Time, err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	key := ruleKey{kind: kind, name: name}
+	s.statuses[key] = RuleStatus{Kind: kind, Name: name, LastRunTime: now, Unavailable: err.Error(),
+		ConsecutiveFailures: s.statuses[key].ConsecutiveFailures}
+}
+
+// startCooldown skips the named pod rule for the next runs.
+func (s *ruleStatuses) startCooldown(name string, runs int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.cooldowns[name] = runs
+}
+
+// cooldown returns the number of runs the named pod rule is still skipped for. With use, one of them
+// is used up.
+func (s *ruleStatuses) cooldown(name string, use bool) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	remaining := s.cooldowns[name]
+	if use && remaining > 0 {
+		if remaining == 1 {
+			delete(s.cooldowns, name)
+		} else {
+			s.cooldowns[name] = remaining - 1
+		}
+	}
+	return remaining
+}
+
+// ruleError returns the error failing a rule: err from finding its resources, joined with its
+// deletion failures only if every one of its attempted deletions failed. Partial deletion
+// failures are reported as counts so they do not hide the deletions that succeeded.
+func ruleError(err, deleteErr error, attempted, failed int) error {
+	if failed > 0 && failed == attempted {
+		return errors.Join(err, deleteErr)
+	}
+	return err
+}
+
+// RuleStatuses returns the latest status of every rule evaluated since startup, sorted by kind
+// and name.
+func (c *PodCleanController) RuleStatuses() []RuleStatus {
+	c.statuses.mu.RLock()
+	defer c.statuses.mu.RUnlock()
+
+	statuses := maps.Clone(c.statuses.statuses)
+	for name, runs := range c.statuses.cooldowns {
+		key := ruleKey{kind: RuleKindPod, name: name}
+		status, ok := statuses[key]
+		if !ok {
+			status = RuleStatus{Kind: RuleKindPod, Name: name}
+		}
+		status.CooldownRuns = runs
+		statuses[key] = status
+	}
+
+	sorted := slices.Collect(maps.Values(statuses))
+	slices.SortFunc(sorted, func(a, b RuleStatus) int {
+		return cmp.Or(strings.Compare(a.Kind, b.Kind), strings.Compare(a.Name, b.Name))
+	})
+	return sorted
+}
+
+// RuleStatus returns the latest status of the named rule of kind, and whether it was evaluated
+// since startup.
+func (c *PodCleanController) RuleStatus(kind, name string) (RuleStatus, bool) {
+	for _, status := range c.RuleStatuses() {
+		if status.Kind == kind && status.Name == name {
+			return status, true
+		}
+	}
+	return RuleStatus{}, false
+}
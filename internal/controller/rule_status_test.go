@@ -0,0 +1,78 @@
+package controller
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestRuleStatuses_Record(t *testing.T) {
+	statuses := newRuleStatuses()
+	now := time.Now()
+
+	statuses.record(RuleKindPod, "rule", now, 3, 0, 0, errors.New("list pods: forbidden"))
+	statuses.record(RuleKindPod, "rule", now.Add(time.Minute), 2, 0, 0, errors.New("list pods: forbidden"))
+
+	got := statuses.statuses[ruleKey{kind: RuleKindPod, name: "rule"}]
+	if got.ConsecutiveFailures != 2 || got.LastError != "list pods: forbidden" || got.LastMatched != 2 {
+		t.Errorf("Unexpected status after failures: %+v", got)
+	}
+
+	statuses.record(RuleKindPod, "rule", now.Add(2*time.Minute), 2, 2, 0, nil)
+
+	got = statuses.statuses[ruleKey{kind: RuleKindPod, name: "rule"}]
+	if got.ConsecutiveFailures != 0 || got.LastError != "" || got.LastDeleted != 2 || !got.LastRunTime.Equal(now.Add(2*time.Minute)) {
+		t.Errorf("Unexpected status after success: %+v", got)
+	}
+}
+
+func TestRuleStatuses_RecordKeepsCooldown(t *testing.T) {
+	statuses := newRuleStatuses()
+	now := time.Now()
+
+	statuses.startCooldown("rule", 3)
+	statuses.record(RuleKindPod, "rule", now, 5, 0, 5, errors.New("delete pods: forbidden"))
+	statuses.record(RuleKindPod, "rule", now.Add(time.Minute), 0, 0, 0, nil)
+
+	if remaining := statuses.cooldown("rule", true); remaining != 3 {
+		t.Errorf("Expected later evaluations to keep the cooldown of 3 runs, got %d", remaining)
+	}
+	if remaining := statuses.cooldown("rule", false); remaining != 2 {
+		t.Errorf("Expected one run of the cooldown to be used up, got %d", remaining)
+	}
+}
+
+func TestRuleStatuses_SameNameOfDifferentKinds(t *testing.T) {
+	controller := &PodCleanController{statuses: newRuleStatuses()}
+	now := time.Now()
+
+	controller.statuses.record(RuleKindPod, "stale", now, 3, 3, 0, nil)
+	controller.statuses.record(RuleKindGeneric, "stale", now, 1, 0, 0, errors.New("list workflows: forbidden"))
+	controller.statuses.startCooldown("stale", 2)
+
+	statuses := controller.RuleStatuses()
+	if len(statuses) != 2 || statuses[0].Kind != RuleKindGeneric || statuses[1].Kind != RuleKindPod {
+		t.Fatalf("Expected a status for each kind sorted by kind, got %+v", statuses)
+	}
+	if generic := statuses[0]; generic.LastMatched != 1 || generic.LastError == "" || generic.CooldownRuns != 0 {
+		t.Errorf("Unexpected generic rule status: %+v", generic)
+	}
+	if pod, _ := controller.RuleStatus(RuleKindPod, "stale"); pod.LastDeleted != 3 || pod.LastError != "" || pod.CooldownRuns != 2 {
+		t.Errorf("Unexpected pod rule status: %+v", pod)
+	}
+}
+
+func TestRuleError(t *testing.T) {
+	listErr := errors.New("list pods: forbidden")
+	deleteErr := errors.New("delete pod default/a: timeout")
+
+	if err := ruleError(nil, deleteErr, 100, 1); err != nil {
+		t.Errorf("Expected a partial deletion failure not to fail the rule, got %v", err)
+	}
+	if err := ruleError(nil, deleteErr, 2, 2); !errors.Is(err, deleteErr) {
+		t.Errorf("Expected the rule to fail when every deletion failed, got %v", err)
+	}
+	if err := ruleError(listErr, deleteErr, 100, 1); !errors.Is(err, listErr) || errors.Is(err, deleteErr) {
+		t.Errorf("Expected only the list error to fail the rule, got %v", err)
+	}
+}
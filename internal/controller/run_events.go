@@ -0,0 +1,144 @@
+package controller
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+// lastRunAnnotation records the summary of the last run on kubeclean's own Deployment.
+const lastRunAnnotation = "kubeclean.io/last-run"
+
+// reasonCodesAnnotation carries the reason codes of a run's failures, counted, on its Event, e.g.
+// {"KC3002":2}, so automation need not parse the message.
+const reasonCodesAnnotation = "kubeclean.io/reason-codes"
+
+// Reasons of the Events reporting a run.
+const (
+	runEventReason         = "CleanupRun"
+	runEventFailuresReason = "CleanupRunFailures" // Some deletions of the run failed.
+)
+
+// runMessage summarizes run, which finished with summary, in a line.
+func runMessage(summary RunSummary, run *cleanupRun) string {
+	var deleted int
+	for _, rules := range run.deleted {
+		for _, count := range rules {
+			deleted += count
+		}
+	}
+
+	var message strings.Builder
+	fmt.Fprintf(&message, "Run %s", summary.RunID)
+	if summary.Namespace != "" {
+		fmt.Fprintf(&message, " in namespace %s", summary.Namespace)
+	}
+	if summary.Node != "" {
+		fmt.Fprintf(&message, " on node %s", summary.Node)
+	}
+	if run.DryRun {
+		message.WriteString(" (dry run)")
+	}
+	fmt.Fprintf(&message, ": matched %d pod(s), deleted %d, deferred %d", summary.Matched, deleted, summary.Deferred)
+	if summary.DeleteFailures > 0 {
+		fmt.Fprintf(&message, ", %d deletion(s) failed (%s)", summary.DeleteFailures, summary.TopDeleteErrors(3))
+	}
+	if summary.Aborted != "" {
+		fmt.Fprintf(&message, ", aborted: %s,", summary.Aborted)
+	}
+	fmt.Fprintf(&message, " in %s", summary.Finished.Sub(summary.Started).Round(time.Second))
+	return message.String()
+}
+
+// reportRun emits an Event summarizing the run on kubeclean's Deployment and, when configured,
+// records the summary in its last-run annotation. Failures are logged; a missing report never
+// fails the run.
+func (c *PodCleanController) reportRun(ctx context.Context, summary RunSummary, run *cleanupRun) {
+	cfg := c.CleanupConfig.RunEvents
+	namespace, name, ok := cfg.DeploymentKey()
+	if !cfg.Enabled || !ok {
+		return
+	}
+	logger := log.FromContext(ctx).WithValues("deployment", cfg.Deployment)
+
+	deployment := &appsv1.Deployment{}
+	key := types.NamespacedName{Namespace: namespace, Name: name}
+	if err := withThrottleRetry(ctx, "get", func() error { return c.Client.Get(ctx, key, deployment) }); err != nil {
+		logger.Error(err, "Failed to get the Deployment to report the run on")
+		return
+	}
+
+	message := runMessage(summary, run)
+	eventType, reason := corev1.EventTypeNormal, runEventReason
+	if summary.DeleteFailures > 0 {
+		eventType, reason = corev1.EventTypeWarning, runEventFailuresReason
+	}
+
+	finished := metav1.NewTime(summary.Finished)
+	event := &corev1.Event{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        fmt.Sprintf("%s.%x", name, summary.Finished.UnixNano()),
+			Namespace:   namespace,
+			Annotations: runEventAnnotations(summary),
+		},
+		InvolvedObject: corev1.ObjectReference{
+			APIVersion:      appsv1.SchemeGroupVersion.String(),
+			Kind:            "Deployment",
+			Namespace:       namespace,
+			Name:            name,
+			UID:             deployment.UID,
+			ResourceVersion: deployment.ResourceVersion,
+		},
+		Reason:         reason,
+		Message:        message,
+		Type:           eventType,
+		Source:         corev1.EventSource{Component: "kubeclean"},
+		FirstTimestamp: finished,
+		LastTimestamp:  finished,
+		Count:          1,
+	}
+	if err := withThrottleRetry(ctx, "create", func() error { return c.Client.Create(ctx, event) }); err != nil {
+		logger.Error(err, "Failed to emit the run Event")
+	}
+
+	if !cfg.Annotate {
+		return
+	}
+
+	patch := client.MergeFrom(deployment.DeepCopy())
+	if deployment.Annotations == nil {
+		deployment.Annotations = map[string]string{}
+	}
+	deployment.Annotations[lastRunAnnotation] = summary.Finished.UTC().Format(time.RFC3339) + " " + message
+	if err := withThrottleRetry(ctx, "patch", func() error { return c.Client.Patch(ctx, deployment, patch) }); err != nil {
+		logger.Error(err, "Failed to annotate the Deployment with the last run")
+	}
+}
+
+// runEventAnnotations returns the annotations of the Event reporting a run that finished with
+// summary; nil when nothing failed.
+func runEventAnnotations(summary RunSummary) map[string]string {
+	codes := ErrorCodes(summary.DeleteErrors)
+	for reason, count := range summary.ListErrors {
+		if count > 0 {
+			codes[reason.Code()] += count
+		}
+	}
+	if len(codes) == 0 {
+		return nil
+	}
+	data, err := json.Marshal(codes)
+	if err != nil {
+		return nil
+	}
+	return map[string]string{reasonCodesAnnotation: string(data)}
+}
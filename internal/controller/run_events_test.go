@@ -0,0 +1,89 @@
+package controller
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	cleanupconfig "github.com/infrautils/kubeclean/internal/cleanup_config"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestRunCleanUp_ReportsRunOnDeployment(t *testing.T) {
+	scheme := runtime.NewScheme()
+	_ = corev1.AddToScheme(scheme)
+	_ = appsv1.AddToScheme(scheme)
+
+	newPod := func(name string) *corev1.Pod {
+		return &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default", CreationTimestamp: metav1.NewTime(time.Now().Add(-2 * time.Hour))},
+			Status:     corev1.PodStatus{Phase: corev1.PodSucceeded},
+		}
+	}
+	client := fake.NewClientBuilder().WithScheme(scheme).WithRuntimeObjects(
+		&appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Name: "kubeclean", Namespace: "kubeclean", UID: "deployment-uid"}},
+		newPod("a"), newPod("b"),
+	).Build()
+
+	cfg := &cleanupconfig.CleanupConfig{
+		PodCleanupConfig: cleanupconfig.PodCleanupConfig{
+			Enabled: true,
+			Rules:   []cleanupconfig.PodCleanRule{{Name: "succeeded", Enabled: true, Phase: "Succeeded", TTL: cleanupconfig.Duration{Duration: time.Hour}}},
+		},
+		RunEvents: cleanupconfig.RunEventsConfig{Enabled: true, Deployment: "kubeclean/kubeclean", Annotate: true},
+	}
+	summary := NewPodCleanController(client, scheme, cfg).RunCleanUp(context.Background())
+
+	var events corev1.EventList
+	if err := client.List(context.Background(), &events); err != nil {
+		t.Fatalf("Failed to list events: %v", err)
+	}
+	if len(events.Items) != 1 {
+		t.Fatalf("Expected one Event, got %d", len(events.Items))
+	}
+	event := events.Items[0]
+	if event.Namespace != "kubeclean" || event.InvolvedObject.Kind != "Deployment" || event.InvolvedObject.UID != "deployment-uid" {
+		t.Errorf("Expected the Event to involve kubeclean's Deployment, got %+v", event.InvolvedObject)
+	}
+	if event.Type != corev1.EventTypeNormal || event.Reason != runEventReason {
+		t.Errorf("Expected a Normal %s Event, got %s %s", runEventReason, event.Type, event.Reason)
+	}
+	if want := "Run " + summary.RunID + ": matched 2 pod(s), deleted 2, deferred 0"; !strings.HasPrefix(event.Message, want) {
+		t.Errorf("Expected message starting with %q, got %q", want, event.Message)
+	}
+
+	deployment := &appsv1.Deployment{}
+	if err := client.Get(context.Background(), types.NamespacedName{Namespace: "kubeclean", Name: "kubeclean"}, deployment); err != nil {
+		t.Fatalf("Failed to get deployment: %v", err)
+	}
+	if annotation := deployment.Annotations[lastRunAnnotation]; !strings.HasSuffix(annotation, event.Message) {
+		t.Errorf("Expected the %s annotation to end with %q, got %q", lastRunAnnotation, event.Message, annotation)
+	}
+}
+
+func TestRunMessage(t *testing.T) {
+	started := time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)
+	summary := RunSummary{
+		RunID:          "7",
+		Node:           "node-a",
+		Started:        started,
+		Finished:       started.Add(3 * time.Second),
+		Matched:        5,
+		Deferred:       1,
+		DeleteFailures: 2,
+		DeleteErrors:   map[ErrorReason]int{ErrorReasonTimeout: 2},
+	}
+	run := &cleanupRun{DryRun: true, deleted: deletionTally{"default": {"succeeded": 2}}}
+
+	want := "Run 7 on node node-a (dry run): matched 5 pod(s), deleted 2, deferred 1, 2 deletion(s) failed (" +
+		summary.TopDeleteErrors(3) + ") in 3s"
+	if got := runMessage(summary, run); got != want {
+		t.Errorf("Expected %q, got %q", want, got)
+	}
+}
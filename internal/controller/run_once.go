@@ -0,0 +1,50 @@
+package controller
+
+import (
+	"fmt"
+
+	cleanupconfig "github.com/infrautils/kubeclean/internal/cleanup_config"
+)
+
+// ValidateRunOnce rejects settings that cannot work when every run is a new process, as with
+// `kubeclean run`: warm-up runs are counted in memory, so every invocation would be the first
+// and never delete anything.
+func ValidateRunOnce(cfg *cleanupconfig.CleanupConfig) error {
+	if cfg.WarmupRuns > 0 {
+		return fmt.Errorf("warmupRuns is not supported by kubeclean run: each invocation would be a warm-up run and dry-run")
+	}
+	return nil
+}
+
+// RunOnceWarnings describes the settings of cfg that rely on state kept in memory between runs,
+// which `kubeclean run` loses with every invocation.
+func RunOnceWarnings(cfg *cleanupconfig.CleanupConfig) []string {
+	var warnings []string
+
+	for _, rule := range cfg.OrphanCleanupConfig.Rules {
+		if !cfg.OrphanCleanupConfig.Enabled || !rule.Enabled {
+			continue
+		}
+		if _, persisted := persistedOrphanKinds[rule.Kind]; !persisted && rule.TTL.Duration > 0 {
+			warnings = append(warnings, fmt.Sprintf("orphan rule %s: orphans are first seen on every invocation, so its ttl never expires", rule.Name))
+		}
+		if rule.BurnInPeriod() > 0 && !rule.IsDryRun() {
+			warnings = append(warnings, fmt.Sprintf("orphan rule %s: its burn-in period starts on every invocation, so it only reports", rule.Name))
+		}
+	}
+
+	for _, rule := range cfg.IdleWorkloadConfig.Rules {
+		if cfg.IdleWorkloadConfig.Enabled && rule.Enabled && rule.TTL.Duration > 0 {
+			warnings = append(warnings, fmt.Sprintf("idle workload rule %s: workloads are first seen idle on every invocation, so its ttl never expires", rule.Name))
+		}
+	}
+
+	if cfg.AnomalyDetection.Enabled {
+		warnings = append(warnings, "anomalyDetection: baselines are not kept between invocations, so no spike is ever detected")
+	}
+	if cfg.NamespaceNotifications.Enabled {
+		warnings = append(warnings, "namespaceNotifications: minInterval is not enforced between invocations")
+	}
+
+	return warnings
+}
@@ -0,0 +1,40 @@
+package controller
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	cleanupconfig "github.com/infrautils/kubeclean/internal/cleanup_config"
+)
+
+func TestValidateRunOnce(t *testing.T) {
+	if err := ValidateRunOnce(&cleanupconfig.CleanupConfig{}); err != nil {
+		t.Errorf("Expected no error, got %v", err)
+	}
+	if err := ValidateRunOnce(&cleanupconfig.CleanupConfig{WarmupRuns: 2}); err == nil {
+		t.Error("Expected warmupRuns to be rejected")
+	}
+}
+
+func TestRunOnceWarnings(t *testing.T) {
+	dryRun := false
+	cfg := &cleanupconfig.CleanupConfig{
+		OrphanCleanupConfig: cleanupconfig.OrphanCleanupConfig{Enabled: true, Rules: []cleanupconfig.OrphanCleanRule{
+			{Name: "hpas", Enabled: true, Kind: cleanupconfig.OrphanKindHorizontalPodAutoscaler, TTL: cleanupconfig.Duration{Duration: time.Hour}},
+			{Name: "accounts", Enabled: true, Kind: cleanupconfig.OrphanKindServiceAccount, TTL: cleanupconfig.Duration{Duration: time.Hour}},
+			{Name: "secrets", Enabled: true, Kind: cleanupconfig.OrphanKindImagePullSecret, DryRun: &dryRun},
+		}},
+		AnomalyDetection: cleanupconfig.AnomalyDetectionConfig{Enabled: true},
+	}
+
+	warnings := strings.Join(RunOnceWarnings(cfg), "\n")
+	for _, want := range []string{"orphan rule hpas: orphans", "orphan rule secrets: its burn-in", "anomalyDetection"} {
+		if !strings.Contains(warnings, want) {
+			t.Errorf("Expected a warning containing %q, got:\n%s", want, warnings)
+		}
+	}
+	if strings.Contains(warnings, "accounts") {
+		t.Errorf("Expected no warning for a kind whose first observation is persisted, got:\n%s", warnings)
+	}
+}
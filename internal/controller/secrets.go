@@ -0,0 +1,105 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+
+	cleanupconfig "github.com/infrautils/kubeclean/internal/cleanup_config"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+// secretVersions remembers the resource version each referenced Secret was last read at, to
+// report credential rotations.
+type secretVersions struct {
+	mu   sync.Mutex
+	seen map[string]string
+}
+
+// changed records version as the current version of secret and reports whether it replaces an
+// earlier one.
+func (v *secretVersions) changed(secret, version string) bool {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	if v.seen == nil {
+		v.seen = map[string]string{}
+	}
+	previous, known := v.seen[secret]
+	v.seen[secret] = version
+	return known && previous != version
+}
+
+// resolveSecret reads the value ref points to. Secrets are read uncached, so that kubeclean
+// neither caches nor watches every Secret of the cluster.
+func (c *PodCleanController) resolveSecret(ctx context.Context, ref cleanupconfig.SecretKeyRef) (string, error) {
+	namespace, name, _ := ref.SecretKey()
+	secret := &corev1.Secret{}
+	key := types.NamespacedName{Namespace: namespace, Name: name}
+	if err := withThrottleRetry(ctx, "get", func() error { return c.stateReader().Get(ctx, key, secret) }); err != nil {
+		return "", fmt.Errorf("failed to read Secret %s: %w", ref.Secret, err)
+	}
+
+	value, ok := secret.Data[ref.Key]
+	if !ok {
+		return "", fmt.Errorf("secret %s has no key %q", ref.Secret, ref.Key)
+	}
+	if c.secrets.changed(ref.Secret, secret.ResourceVersion) {
+		log.FromContext(ctx).Info("Referenced Secret changed; using its current value", "secret", ref.Secret)
+	}
+	return strings.TrimSpace(string(value)), nil
+}
+
+// notificationConfig returns the notification config with the URLs and SMTP passwords of sinks
+// resolved from their Secrets. Sinks whose credentials cannot be resolved are left out and
+// logged, so the others still notify.
+func (c *PodCleanController) notificationConfig(ctx context.Context) cleanupconfig.NotificationConfig {
+	cfg := c.CleanupConfig.Notifications
+	sinks := make([]cleanupconfig.NotificationSink, 0, len(cfg.Sinks))
+
+	for _, sink := range cfg.Sinks {
+		if sink.URLFrom != nil {
+			url, err := c.resolveSecret(ctx, *sink.URLFrom)
+			if err == nil {
+				sink.URL, sink.URLFrom = url, nil
+				err = sink.Validate()
+			}
+			if err != nil {
+				log.FromContext(ctx).Error(err, "Skipping notification sink whose URL cannot be resolved", "sink", sink.Name)
+				continue
+			}
+		}
+		if sink.Email != nil && sink.Email.PasswordFrom != nil {
+			password, err := c.resolveSecret(ctx, *sink.Email.PasswordFrom)
+			if err != nil {
+				log.FromContext(ctx).Error(err, "Skipping notification sink whose SMTP password cannot be resolved", "sink", sink.Name)
+				continue
+			}
+			// Copy the settings, which the active config shares.
+			email := *sink.Email
+			email.Password, email.PasswordFrom = password, nil
+			sink.Email = &email
+		}
+		sinks = append(sinks, sink)
+	}
+
+	cfg.Sinks = sinks
+	return cfg
+}
+
+// logForwardingConfig returns the log forwarding config with its URL resolved from its Secret.
+func (c *PodCleanController) logForwardingConfig(ctx context.Context) (cleanupconfig.LogForwardingConfig, error) {
+	cfg := c.CleanupConfig.LogForwarding
+	if cfg.URLFrom == nil {
+		return cfg, nil
+	}
+
+	url, err := c.resolveSecret(ctx, *cfg.URLFrom)
+	if err != nil {
+		return cfg, err
+	}
+	cfg.URL, cfg.URLFrom = url, nil
+	return cfg, cfg.ValidateURL()
+}
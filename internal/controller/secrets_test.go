@@ -0,0 +1,77 @@
+package controller
+
+import (
+	"context"
+	"testing"
+
+	cleanupconfig "github.com/infrautils/kubeclean/internal/cleanup_config"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestPodCleanController_NotificationConfigResolvesSecrets(t *testing.T) {
+	scheme := runtime.NewScheme()
+	_ = corev1.AddToScheme(scheme)
+
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "slack", Namespace: "kubeclean"},
+		Data: map[string][]byte{
+			"url":      []byte("https://hooks.slack.com/services/T0/B0/first\n"),
+			"password": []byte("smtp-secret"),
+		},
+	}
+	client := fake.NewClientBuilder().WithScheme(scheme).WithObjects(secret).Build()
+
+	cfg := &cleanupconfig.CleanupConfig{Notifications: cleanupconfig.NotificationConfig{Sinks: []cleanupconfig.NotificationSink{
+		{Name: "inline", Type: cleanupconfig.SinkTypeWebhook, URL: "https://example.com/hook"},
+		{Name: "slack", Type: cleanupconfig.SinkTypeSlack, URLFrom: &cleanupconfig.SecretKeyRef{Secret: "kubeclean/slack", Key: "url"}},
+		{Name: "missing", Type: cleanupconfig.SinkTypeSlack, URLFrom: &cleanupconfig.SecretKeyRef{Secret: "kubeclean/slack", Key: "token"}},
+		{Name: "mail", Type: cleanupconfig.SinkTypeEmail, Email: &cleanupconfig.EmailSinkConfig{
+			Host: "smtp.internal", From: "kubeclean@example.com", To: []string{"ops@example.com"},
+			Username: "kubeclean", PasswordFrom: &cleanupconfig.SecretKeyRef{Secret: "kubeclean/slack", Key: "password"},
+		}},
+	}}}
+	controller := NewPodCleanController(client, scheme, cfg)
+
+	resolved := controller.notificationConfig(context.Background())
+	if len(resolved.Sinks) != 3 {
+		t.Fatalf("Expected the sink with a missing key to be left out, got %+v", resolved.Sinks)
+	}
+	if sink := resolved.Sinks[1]; sink.URL != "https://hooks.slack.com/services/T0/B0/first" || sink.URLFrom != nil {
+		t.Errorf("Expected the slack URL to be read from its Secret, got %+v", sink)
+	}
+	if email := resolved.Sinks[2].Email; email.Password != "smtp-secret" || email.PasswordFrom != nil {
+		t.Errorf("Expected the SMTP password to be read from its Secret, got %+v", email)
+	}
+	if cfg.Notifications.Sinks[1].URL != "" || cfg.Notifications.Sinks[3].Email.Password != "" {
+		t.Error("Expected the active config to keep referencing the Secrets")
+	}
+
+	// Rotated credentials apply on the next resolution.
+	secret.Data["url"] = []byte("https://hooks.slack.com/services/T0/B0/second")
+	if err := client.Update(context.Background(), secret); err != nil {
+		t.Fatalf("Failed to update secret: %v", err)
+	}
+	if url := controller.notificationConfig(context.Background()).Sinks[1].URL; url != "https://hooks.slack.com/services/T0/B0/second" {
+		t.Errorf("Expected the rotated URL, got %q", url)
+	}
+}
+
+func TestPodCleanController_LogForwardingConfigResolvesSecret(t *testing.T) {
+	scheme := runtime.NewScheme()
+	_ = corev1.AddToScheme(scheme)
+
+	client := fake.NewClientBuilder().WithScheme(scheme).WithObjects(&corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "loki", Namespace: "kubeclean"},
+		Data:       map[string][]byte{"url": []byte("not a url")},
+	}).Build()
+
+	cfg := &cleanupconfig.CleanupConfig{LogForwarding: cleanupconfig.LogForwardingConfig{
+		Enabled: true, Backend: cleanupconfig.LogBackendLoki, URLFrom: &cleanupconfig.SecretKeyRef{Secret: "kubeclean/loki", Key: "url"},
+	}}
+	if _, err := NewPodCleanController(client, scheme, cfg).logForwardingConfig(context.Background()); err == nil {
+		t.Error("Expected an invalid URL read from the Secret to be rejected")
+	}
+}
@@ -0,0 +1,67 @@
+package controller
+
+import (
+	"context"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+// Values of the config label of kubeclean_shadow_matched_pods.
+const (
+	activeConfigLabel = "active"
+	shadowConfigLabel = "shadow"
+)
+
+// compareShadow plans the pod rules of the shadow config against the same cluster state as the
+// active plans, without acting on any pod, and exports how their matches differ as metrics.
+func (c *PodCleanController) compareShadow(ctx context.Context, activePlans []rulePlan) {
+	if c.ShadowConfig == nil {
+		return
+	}
+
+	// A matcher of its own keeps the active run's caches intact. Runtime overrides target the
+	// active config, so the shadow config is evaluated as written.
+	shadowPlans := planRules(log.IntoContext(ctx, log.FromContext(ctx).WithValues("config", shadowConfigLabel)),
+		NewPodMatcher(c.Client), c.ShadowConfig, nil)
+
+	active, shadow := matchedKeys(activePlans), matchedKeys(shadowPlans)
+	var added, removed int
+	for key := range shadow {
+		if _, ok := active[key]; !ok {
+			added++
+		}
+	}
+	for key := range active {
+		if _, ok := shadow[key]; !ok {
+			removed++
+		}
+	}
+
+	shadowMatchedPods.Reset()
+	for rule, matched := range summarize(activePlans).MatchedByRule {
+		shadowMatchedPods.WithLabelValues(activeConfigLabel, rule).Set(float64(matched))
+	}
+	for rule, matched := range summarize(shadowPlans).MatchedByRule {
+		shadowMatchedPods.WithLabelValues(shadowConfigLabel, rule).Set(float64(matched))
+	}
+	shadowAddedPods.Set(float64(added))
+	shadowRemovedPods.Set(float64(removed))
+
+	log.FromContext(ctx).Info("Compared the shadow config", "active", len(active), "shadow", len(shadow),
+		"added", added, "removed", removed)
+}
+
+// matchedKeys returns the keys of every matched pod, selected or deferred.
+func matchedKeys(plans []rulePlan) map[types.NamespacedName]struct{} {
+	keys := map[types.NamespacedName]struct{}{}
+	for _, plan := range plans {
+		for _, pods := range [][]corev1.Pod{plan.Selected, plan.Deferred} {
+			for _, pod := range pods {
+				keys[types.NamespacedName{Namespace: pod.Namespace, Name: pod.Name}] = struct{}{}
+			}
+		}
+	}
+	return keys
+}
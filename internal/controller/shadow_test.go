@@ -0,0 +1,60 @@
+package controller
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	cleanupconfig "github.com/infrautils/kubeclean/internal/cleanup_config"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestRunCleanUp_ComparesShadowConfig(t *testing.T) {
+	scheme := runtime.NewScheme()
+	_ = corev1.AddToScheme(scheme)
+
+	newPod := func(name string, phase corev1.PodPhase) *corev1.Pod {
+		return &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default", CreationTimestamp: metav1.NewTime(time.Now().Add(-2 * time.Hour))},
+			Status:     corev1.PodStatus{Phase: phase},
+		}
+	}
+	client := fake.NewClientBuilder().WithScheme(scheme).WithRuntimeObjects(
+		newPod("succeeded", corev1.PodSucceeded),
+		newPod("failed-1", corev1.PodFailed),
+		newPod("failed-2", corev1.PodFailed),
+	).Build()
+
+	rule := func(name, phase string) cleanupconfig.PodCleanRule {
+		return cleanupconfig.PodCleanRule{Name: name, Enabled: true, Phase: phase, TTL: cleanupconfig.Duration{Duration: time.Hour}}
+	}
+	cfg := &cleanupconfig.CleanupConfig{
+		DryRun:           true,
+		PodCleanupConfig: cleanupconfig.PodCleanupConfig{Enabled: true, Rules: []cleanupconfig.PodCleanRule{rule("succeeded", "Succeeded")}},
+	}
+	controller := NewPodCleanController(client, scheme, cfg)
+	controller.ShadowConfig = &cleanupconfig.CleanupConfig{
+		PodCleanupConfig: cleanupconfig.PodCleanupConfig{Enabled: true, Rules: []cleanupconfig.PodCleanRule{rule("failed", "Failed")}},
+	}
+
+	controller.RunCleanUp(context.Background())
+
+	if got := testutil.ToFloat64(shadowMatchedPods.WithLabelValues(activeConfigLabel, "succeeded")); got != 1 {
+		t.Errorf("Expected the active rule to match 1 pod, got %v", got)
+	}
+	if got := testutil.ToFloat64(shadowMatchedPods.WithLabelValues(shadowConfigLabel, "failed")); got != 2 {
+		t.Errorf("Expected the shadow rule to match 2 pods, got %v", got)
+	}
+	if added, removed := testutil.ToFloat64(shadowAddedPods), testutil.ToFloat64(shadowRemovedPods); added != 2 || removed != 1 {
+		t.Errorf("Expected 2 added and 1 removed pod, got %v and %v", added, removed)
+	}
+
+	// The shadow config never acts, even though it is not a dry run.
+	if got := remainingPodNames(t, client); len(got) != 3 {
+		t.Errorf("Expected all pods to remain, got %v", got)
+	}
+}
@@ -0,0 +1,102 @@
+package controller
+
+import (
+	"context"
+	"sort"
+
+	cleanupconfig "github.com/infrautils/kubeclean/internal/cleanup_config"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// PodRef identifies a pod matched by a rule.
+type PodRef struct {
+	Rule      string `json:"rule"`
+	Namespace string `json:"namespace"`
+	Name      string `json:"name"`
+	Owner     string `json:"owner,omitempty"` // Top-level owner, e.g. "CronJob default/nightly".
+
+	// ReasonCode is why the rule matched the pod: selected by TTL or maxPerNode, or deferred by a budget.
+	ReasonCode ReasonCode `json:"reasonCode,omitempty"`
+
+	// Warnings name finalizers and validating webhooks that would make deleting the pod hang or fail.
+	Warnings []string `json:"warnings,omitempty"`
+
+	UID types.UID `json:"-"` // UID of the matched pod, guarding deletions against recreated pods.
+}
+
+// SimulationResult compares the pods a candidate config would act on with those of the active config.
+type SimulationResult struct {
+	Active    map[string]int `json:"active"`    // Matched pods per rule under the active config.
+	Candidate map[string]int `json:"candidate"` // Matched pods per rule under the candidate config.
+	Added     []PodRef       `json:"added"`     // Pods only the candidate config matches.
+	Removed   []PodRef       `json:"removed"`   // Pods only the active config matches.
+}
+
+// Simulate evaluates the active and candidate configs against the live cluster without acting
+// on any pod and returns the difference between their matches.
+func (c *PodCleanController) Simulate(ctx context.Context, candidate *cleanupconfig.CleanupConfig) SimulationResult {
+	matcher := c.newMatcher()
+	checker := newDeletionChecker(matcher)
+
+	resolver := newOwnerResolver(c.Client)
+
+	activePlans := planRules(ctx, matcher, c.CleanupConfig, c.overrides)
+	activeMatches := matchedPods(ctx, matcher, resolver, checker, activePlans)
+
+	// Rules of both configs may share names; trims of the active ones must not leak into the
+	// candidate's reason codes.
+	matcher.trimmed = nil
+	candidatePlans := planRules(ctx, matcher, candidate, c.overrides)
+	candidateMatches := matchedPods(ctx, matcher, resolver, checker, candidatePlans)
+
+	return SimulationResult{
+		Active:    summarize(activePlans).MatchedByRule,
+		Candidate: summarize(candidatePlans).MatchedByRule,
+		Added:     missingFrom(candidateMatches, activeMatches),
+		Removed:   missingFrom(activeMatches, candidateMatches),
+	}
+}
+
+// matchedPods indexes every matched pod, selected or deferred, by its key.
+func matchedPods(ctx context.Context, matcher *PodMatcher, resolver *ownerResolver, checker *deletionChecker, plans []rulePlan) map[types.NamespacedName]PodRef {
+	matched := map[types.NamespacedName]PodRef{}
+
+	for _, plan := range plans {
+		for deferred, pods := range [][]corev1.Pod{plan.Selected, plan.Deferred} {
+			for i := range pods {
+				pod := &pods[i]
+				key := types.NamespacedName{Namespace: pod.Namespace, Name: pod.Name}
+				if _, exists := matched[key]; !exists {
+					ref := PodRef{Rule: plan.Rule.Name, Namespace: pod.Namespace, Name: pod.Name, ReasonCode: matcher.matchCode(plan.Rule.Name, pod, deferred == 1)}
+					if owner := resolver.resolve(ctx, pod); owner.Kind != "" {
+						ref.Owner = owner.String()
+					}
+					ref.Warnings = checker.warnings(ctx, pod)
+					matched[key] = ref
+				}
+			}
+		}
+	}
+
+	return matched
+}
+
+// missingFrom returns the refs in from whose pods are absent in other, sorted by namespace and name.
+func missingFrom(from, other map[types.NamespacedName]PodRef) []PodRef {
+	refs := []PodRef{}
+	for key, ref := range from {
+		if _, exists := other[key]; !exists {
+			refs = append(refs, ref)
+		}
+	}
+
+	sort.Slice(refs, func(i, j int) bool {
+		if refs[i].Namespace != refs[j].Namespace {
+			return refs[i].Namespace < refs[j].Namespace
+		}
+		return refs[i].Name < refs[j].Name
+	})
+
+	return refs
+}
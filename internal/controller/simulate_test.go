@@ -0,0 +1,163 @@
+package controller
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	cleanupconfig "github.com/infrautils/kubeclean/internal/cleanup_config"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestSimulate_ReportsDeltaWithoutDeleting(t *testing.T) {
+	scheme := runtime.NewScheme()
+	_ = corev1.AddToScheme(scheme)
+
+	newPod := func(name string, phase corev1.PodPhase, age time.Duration) *corev1.Pod {
+		return &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:              name,
+				Namespace:         "default",
+				CreationTimestamp: metav1.NewTime(time.Now().Add(-age)),
+			},
+			Status: corev1.PodStatus{Phase: phase},
+		}
+	}
+
+	client := fake.NewClientBuilder().WithScheme(scheme).WithRuntimeObjects(
+		newPod("old-succeeded", corev1.PodSucceeded, 3*time.Hour),
+		newPod("young-succeeded", corev1.PodSucceeded, 90*time.Minute),
+		newPod("old-failed", corev1.PodFailed, 3*time.Hour),
+	).Build()
+
+	newConfig := func(ttl time.Duration, phase corev1.PodPhase) *cleanupconfig.CleanupConfig {
+		return &cleanupconfig.CleanupConfig{
+			BatchSize: 10,
+			PodCleanupConfig: cleanupconfig.PodCleanupConfig{
+				Enabled: true,
+				Rules: []cleanupconfig.PodCleanRule{{
+					Name:    "rule",
+					Enabled: true,
+					Phase:   string(phase),
+					TTL:     cleanupconfig.Duration{Duration: ttl},
+				}},
+			},
+		}
+	}
+
+	active := newConfig(2*time.Hour, corev1.PodSucceeded)
+	candidate := newConfig(time.Hour, corev1.PodSucceeded)
+	candidate.PodCleanupConfig.Rules = append(candidate.PodCleanupConfig.Rules, cleanupconfig.PodCleanRule{
+		Name:    "failed",
+		Enabled: true,
+		Phase:   string(corev1.PodFailed),
+		TTL:     cleanupconfig.Duration{Duration: 4 * time.Hour},
+	})
+
+	result := NewPodCleanController(client, scheme, active).Simulate(context.Background(), candidate)
+
+	if result.Active["rule"] != 1 || result.Candidate["rule"] != 2 {
+		t.Errorf("Unexpected per-rule counts: active=%v candidate=%v", result.Active, result.Candidate)
+	}
+
+	if len(result.Added) != 1 || result.Added[0].Name != "young-succeeded" || len(result.Removed) != 0 {
+		t.Errorf("Unexpected delta: added=%v removed=%v", result.Added, result.Removed)
+	}
+
+	if remaining := remainingPodNames(t, client); len(remaining) != 3 {
+		t.Errorf("Simulation must not delete pods, remaining: %v", remaining)
+	}
+}
+
+func TestPreviewAndDeletePods(t *testing.T) {
+	scheme := runtime.NewScheme()
+	_ = corev1.AddToScheme(scheme)
+
+	newPod := func(name string, uid types.UID) *corev1.Pod {
+		return &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:              name,
+				Namespace:         "default",
+				UID:               uid,
+				CreationTimestamp: metav1.NewTime(time.Now().Add(-2 * time.Hour)),
+			},
+			Status: corev1.PodStatus{Phase: corev1.PodFailed},
+		}
+	}
+
+	client := fake.NewClientBuilder().WithScheme(scheme).WithRuntimeObjects(
+		newPod("keep", "uid-keep"),
+		newPod("remove", "uid-remove"),
+	).Build()
+
+	cleanupCfg := &cleanupconfig.CleanupConfig{
+		BatchSize: 10,
+		PodCleanupConfig: cleanupconfig.PodCleanupConfig{
+			Enabled: true,
+			Rules: []cleanupconfig.PodCleanRule{
+				{Name: "failed", Enabled: true, Phase: "Failed", TTL: cleanupconfig.Duration{Duration: time.Hour}},
+			},
+		},
+	}
+
+	controller := NewPodCleanController(client, scheme, cleanupCfg)
+
+	refs := controller.Preview(context.Background())
+	if len(refs) != 2 || refs[0].UID == "" {
+		t.Fatalf("Expected 2 previewed pods, got %+v", refs)
+	}
+	if remaining := remainingPodNames(t, client); len(remaining) != 2 {
+		t.Fatalf("Preview must not delete pods, remaining: %v", remaining)
+	}
+
+	var selected []PodRef
+	for _, ref := range refs {
+		if ref.Name == "remove" {
+			selected = append(selected, ref)
+		}
+	}
+
+	deleted, err := controller.DeletePods(context.Background(), selected)
+	if deleted != 1 || err != nil {
+		t.Errorf("Expected 1 deletion, got %d, %v", deleted, err)
+	}
+
+	remaining := remainingPodNames(t, client)
+	if remaining["remove"] || !remaining["keep"] {
+		t.Errorf("Unexpected pods after deletion: %v", remaining)
+	}
+}
+
+func TestPreview_EvaluatesTTLsAgainstNow(t *testing.T) {
+	scheme := runtime.NewScheme()
+	_ = corev1.AddToScheme(scheme)
+
+	recorded := time.Date(2026, 1, 2, 15, 0, 0, 0, time.UTC)
+	newPod := func(name string, age time.Duration) *corev1.Pod {
+		return &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default", CreationTimestamp: metav1.NewTime(recorded.Add(-age))},
+			Status:     corev1.PodStatus{Phase: corev1.PodFailed},
+		}
+	}
+	client := fake.NewClientBuilder().WithScheme(scheme).WithRuntimeObjects(newPod("expired", 2*time.Hour), newPod("young", 30*time.Minute)).Build()
+
+	controller := NewPodCleanController(client, scheme, &cleanupconfig.CleanupConfig{
+		PodCleanupConfig: cleanupconfig.PodCleanupConfig{
+			Enabled: true,
+			Rules:   []cleanupconfig.PodCleanRule{{Name: "failed", Enabled: true, Phase: "Failed", TTL: cleanupconfig.Duration{Duration: time.Hour}}},
+		},
+	})
+
+	if refs := controller.Preview(context.Background()); len(refs) != 2 {
+		t.Errorf("Expected both pods to have expired by now, got %+v", refs)
+	}
+
+	controller.Now = func() time.Time { return recorded }
+	if refs := controller.Preview(context.Background()); len(refs) != 1 || refs[0].Name != "expired" {
+		t.Errorf("Expected only the pod older than the TTL at the given time, got %+v", refs)
+	}
+}
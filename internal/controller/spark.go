@@ -0,0 +1,86 @@
+package controller
+
+import (
+	"context"
+
+	cleanupconfig "github.com/infrautils/kubeclean/internal/cleanup_config"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/selection"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// SparkAppLabel is set by Spark on Kubernetes on the driver and every executor of an application.
+const SparkAppLabel = "spark-app-selector"
+
+// filterSparkApplications keeps Spark pods only when every pod of their application was matched
+// by the rule, so drivers and executors are cleaned up together once the whole application is done.
+// Pods without the Spark label are returned unchanged.
+func (pm *PodMatcher) filterSparkApplications(ctx context.Context, pods []corev1.Pod, rule cleanupconfig.PodCleanRule) ([]corev1.Pod, error) {
+	if !rule.GroupBySparkApplication {
+		return pods, nil
+	}
+
+	matched := map[types.NamespacedName]bool{}
+	namespaces := map[string]bool{}
+	for i := range pods {
+		if _, ok := pods[i].Labels[SparkAppLabel]; ok {
+			matched[types.NamespacedName{Namespace: pods[i].Namespace, Name: pods[i].Name}] = true
+			namespaces[pods[i].Namespace] = true
+		}
+	}
+
+	if len(matched) == 0 {
+		return pods, nil
+	}
+
+	hasSparkLabel, err := labels.NewRequirement(SparkAppLabel, selection.Exists, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	// An application is complete only if none of its pods fell outside the rule's match.
+	incomplete := map[string]bool{}
+	for namespace := range namespaces {
+		var podList corev1.PodList
+		if err := withThrottleRetry(ctx, "list", func() error {
+			return pm.client.List(ctx, &podList, &client.ListOptions{
+				Namespace:     namespace,
+				LabelSelector: labels.NewSelector().Add(*hasSparkLabel),
+			})
+		}); err != nil {
+			return nil, newListError("pods", namespace, err)
+		}
+
+		for i := range podList.Items {
+			member := &podList.Items[i]
+			if !matched[types.NamespacedName{Namespace: member.Namespace, Name: member.Name}] {
+				incomplete[sparkAppKey(member)] = true
+			}
+		}
+	}
+
+	filtered := make([]corev1.Pod, 0, len(pods))
+	for i := range pods {
+		if _, ok := pods[i].Labels[SparkAppLabel]; ok && incomplete[sparkAppKey(&pods[i])] {
+			continue
+		}
+		filtered = append(filtered, pods[i])
+	}
+
+	return filtered, nil
+}
+
+func sparkAppKey(pod *corev1.Pod) string {
+	return pod.Namespace + "/" + pod.Labels[SparkAppLabel]
+}
+
+// budgetUnits returns how deletion budgets group the pods of rule: by Spark application when the
+// rule groups them, so an application is never deleted in part, and pod by pod otherwise.
+func budgetUnits(rule cleanupconfig.PodCleanRule) func(*corev1.Pod) string {
+	if !rule.GroupBySparkApplication {
+		return nil
+	}
+	return func(pod *corev1.Pod) string { return pod.Labels[SparkAppLabel] }
+}
@@ -0,0 +1,79 @@
+package controller
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	cleanupconfig "github.com/infrautils/kubeclean/internal/cleanup_config"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestPodCleanupController_SparkApplicationGroups(t *testing.T) {
+	scheme := runtime.NewScheme()
+	_ = corev1.AddToScheme(scheme)
+
+	newSparkPod := func(name, app string, phase corev1.PodPhase, age time.Duration) *corev1.Pod {
+		podLabels := map[string]string{"workload": "spark"}
+		if app != "" {
+			podLabels[SparkAppLabel] = app
+		}
+		return &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:              name,
+				Namespace:         "spark",
+				Labels:            podLabels,
+				CreationTimestamp: metav1.NewTime(time.Now().Add(-age)),
+			},
+			Status: corev1.PodStatus{Phase: phase},
+		}
+	}
+
+	client := fake.NewClientBuilder().WithScheme(scheme).WithRuntimeObjects(
+		// Finished application: driver and executors all terminated past the TTL.
+		newSparkPod("done-driver", "app-done", corev1.PodSucceeded, 3*time.Hour),
+		newSparkPod("done-exec-1", "app-done", corev1.PodFailed, 3*time.Hour),
+		// Running application: the driver finished but an executor is still running.
+		newSparkPod("running-driver", "app-running", corev1.PodSucceeded, 3*time.Hour),
+		newSparkPod("running-exec-1", "app-running", corev1.PodRunning, 3*time.Hour),
+		// Recently finished application: one executor is still within the TTL.
+		newSparkPod("recent-driver", "app-recent", corev1.PodSucceeded, 3*time.Hour),
+		newSparkPod("recent-exec-1", "app-recent", corev1.PodSucceeded, 10*time.Minute),
+		// Pods outside any Spark application are cleaned individually.
+		newSparkPod("standalone", "", corev1.PodSucceeded, 3*time.Hour),
+	).Build()
+
+	cleanupCfg := &cleanupconfig.CleanupConfig{
+		BatchSize: 10,
+		PodCleanupConfig: cleanupconfig.PodCleanupConfig{
+			Enabled: true,
+			Rules: []cleanupconfig.PodCleanRule{{
+				Name:     "spark-apps",
+				Enabled:  true,
+				TTL:      cleanupconfig.Duration{Duration: time.Hour},
+				Selector: metav1.LabelSelector{MatchLabels: map[string]string{"workload": "spark"}},
+				Match: &cleanupconfig.MatchCriteria{
+					Any: []cleanupconfig.MatchCondition{{Phase: "Succeeded"}, {Phase: "Failed"}},
+				},
+				GroupBySparkApplication: true,
+			}},
+		},
+	}
+
+	NewPodCleanController(client, scheme, cleanupCfg).RunCleanUp(context.Background())
+
+	remaining := remainingPodNames(t, client)
+	for _, deleted := range []string{"done-driver", "done-exec-1", "standalone"} {
+		if remaining[deleted] {
+			t.Errorf("Expected %s to be deleted, remaining: %v", deleted, remaining)
+		}
+	}
+	for _, kept := range []string{"running-driver", "running-exec-1", "recent-driver", "recent-exec-1"} {
+		if !remaining[kept] {
+			t.Errorf("Expected %s to be kept, remaining: %v", kept, remaining)
+		}
+	}
+}
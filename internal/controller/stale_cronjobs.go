@@ -0,0 +1,334 @@
+package controller
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+	"time"
+
+	cleanupconfig "github.com/infrautils/kubeclean/internal/cleanup_config"
+	"github.com/infrautils/kubeclean/internal/notify"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+// suspendedReasonAnnotation records on a suspended CronJob why kubeclean suspended it.
+const suspendedReasonAnnotation = "kubeclean.io/suspended-reason"
+
+// cronJobTracker remembers the failed Jobs of each CronJob since its last success, so failures
+// whose Jobs were pruned by the CronJob's failedJobsHistoryLimit still count, and when CronJobs
+// kubeclean suspended were resumed. State is lost on restart.
+type cronJobTracker struct {
+	failed  map[types.NamespacedName]map[types.UID]time.Time // Creation times of failed Jobs, by CronJob.
+	resumed map[types.NamespacedName]time.Time               // When a CronJob kubeclean suspended was first seen resumed.
+}
+
+func newCronJobTracker() *cronJobTracker {
+	return &cronJobTracker{
+		failed:  map[types.NamespacedName]map[types.UID]time.Time{},
+		resumed: map[types.NamespacedName]time.Time{},
+	}
+}
+
+// resumedAt returns when cronJob, suspended by kubeclean and since resumed by someone else, was
+// first seen resumed, or the zero time if it was never suspended by kubeclean. Staleness is only
+// measured from then, so a resumed CronJob gets a full threshold to recover.
+func (t *cronJobTracker) resumedAt(cronJob *batchv1.CronJob, now time.Time) time.Time {
+	key := client.ObjectKeyFromObject(cronJob)
+	if _, ok := cronJob.Annotations[suspendedReasonAnnotation]; !ok {
+		delete(t.resumed, key)
+		return time.Time{}
+	}
+	resumed, ok := t.resumed[key]
+	if !ok {
+		resumed = now
+		t.resumed[key] = now
+	}
+	return resumed
+}
+
+// observe records the finished Jobs of cronJob and returns how many Jobs failed since its last
+// success, or since it was resumed. A success clears every failure of a Job created before it.
+func (t *cronJobTracker) observe(cronJob *batchv1.CronJob, jobs []*batchv1.Job, resumed time.Time) int {
+	key := client.ObjectKeyFromObject(cronJob)
+	failed := t.failed[key]
+	if failed == nil {
+		failed = map[types.UID]time.Time{}
+		t.failed[key] = failed
+	}
+
+	clearBefore := func(succeeded time.Time) {
+		for uid, created := range failed {
+			if created.Before(succeeded) {
+				delete(failed, uid)
+			}
+		}
+	}
+
+	for _, job := range jobs {
+		switch {
+		case jobConditionTrue(job, batchv1.JobComplete):
+			clearBefore(job.CreationTimestamp.Time)
+		case jobConditionTrue(job, batchv1.JobFailed):
+			failed[job.UID] = job.CreationTimestamp.Time
+		}
+	}
+	// The last success may belong to a Job that was already pruned.
+	if last := cronJob.Status.LastSuccessfulTime; last != nil {
+		clearBefore(last.Time)
+	}
+	clearBefore(resumed)
+
+	return len(failed)
+}
+
+// forget drops the state of a CronJob.
+func (t *cronJobTracker) forget(key types.NamespacedName) {
+	delete(t.failed, key)
+	delete(t.resumed, key)
+}
+
+// prune forgets the CronJobs of namespace that are not in keep, or of every namespace when
+// namespace is empty.
+func (t *cronJobTracker) prune(namespace string, keep map[types.NamespacedName]bool) {
+	for key := range t.failed {
+		if (namespace == "" || key.Namespace == namespace) && !keep[key] {
+			t.forget(key)
+		}
+	}
+	for key := range t.resumed {
+		if (namespace == "" || key.Namespace == namespace) && !keep[key] {
+			t.forget(key)
+		}
+	}
+}
+
+func jobConditionTrue(job *batchv1.Job, conditionType batchv1.JobConditionType) bool {
+	for _, condition := range job.Status.Conditions {
+		if condition.Type == conditionType && condition.Status == corev1.ConditionTrue {
+			return true
+		}
+	}
+	return false
+}
+
+// staleCronJob is a CronJob a rule decided to suspend, with the reason why.
+type staleCronJob struct {
+	cronJob *batchv1.CronJob
+	reason  string
+}
+
+// suspendStaleCronJobs executes every stale CronJob rule, suspending the CronJobs that have not
+// succeeded for too long or whose Jobs keep failing, unless the run is dry-run.
+func (c *PodCleanController) suspendStaleCronJobs(ctx context.Context, run *cleanupRun) {
+	logger := log.FromContext(ctx)
+	logger.Info("Starting stale CronJob cleanup")
+
+	if c.cronJobs == nil {
+		c.cronJobs = newCronJobTracker()
+	}
+
+	for _, rule := range c.CleanupConfig.StaleCronJobConfig.Rules {
+		if !c.overrides.isEnabled(RuleKindStaleCronJob, rule.Name, rule.Enabled) || !run.inLane(rule.Lane) {
+			continue
+		}
+		ctx := withRule(ctx, rule.Name)
+
+		namespaces := rule.Namespaces
+		if len(namespaces) == 0 {
+			namespaces = []string{""} // All namespaces
+		}
+
+		now := time.Now()
+		var stale []staleCronJob
+		var errs []error
+		for _, namespace := range namespaces {
+			found, err := c.findStaleCronJobs(ctx, rule, namespace, now)
+			stale = append(stale, found...)
+			if err != nil {
+				errs = append(errs, err)
+			}
+		}
+
+		if err := errors.Join(errs...); err != nil {
+			for reason, count := range ErrorReasons(err) {
+				listErrorsTotal.WithLabelValues(rule.Name, string(reason)).Add(float64(count))
+			}
+			logger.Error(err, "Failed to detect stale CronJobs", "rule", rule.Name)
+		}
+		staleCronJobs.WithLabelValues(rule.Name).Set(float64(len(stale)))
+
+		if len(stale) == 0 {
+			c.statuses.record(RuleKindStaleCronJob, rule.Name, now, 0, 0, 0, errors.Join(errs...))
+			continue
+		}
+
+		suspended, suspendErr := suspendCronJobs(ctx, c.Client, stale, run.DryRun)
+		failed := run.recordDeleteFailures(rule.Name, suspendErr)
+		c.statuses.record(RuleKindStaleCronJob, rule.Name, now, len(stale), deletedCount(len(stale), run.DryRun)-failed, failed,
+			ruleError(errors.Join(errs...), suspendErr, len(stale), failed))
+
+		c.notifyRule(ctx, run, rule.Name, rule.NotificationSinks,
+			notify.Event{Kind: "CronJob", Resources: len(stale), Action: cleanupconfig.ActionSuspend}, "CronJob(s)")
+		if rule.NotifyOwners {
+			c.notifyCronJobOwners(ctx, run, rule.Name, suspended)
+		}
+	}
+}
+
+// findStaleCronJobs returns the CronJobs of namespace that the rule considers stale at now.
+// CronJobs already suspended are never stale.
+func (c *PodCleanController) findStaleCronJobs(ctx context.Context, rule cleanupconfig.StaleCronJobRule, namespace string, now time.Time) ([]staleCronJob, error) {
+	opts := []client.ListOption{client.InNamespace(namespace)}
+	if rule.Selector != nil {
+		selector, err := metav1.LabelSelectorAsSelector(rule.Selector)
+		if err != nil {
+			return nil, err
+		}
+		opts = append(opts, client.MatchingLabelsSelector{Selector: selector})
+	}
+
+	var cronJobs batchv1.CronJobList
+	if err := withThrottleRetry(ctx, "list", func() error { return c.Client.List(ctx, &cronJobs, opts...) }); err != nil {
+		return nil, newListError("cronjobs", namespace, err)
+	}
+
+	var jobs batchv1.JobList
+	if rule.MaxConsecutiveFailures > 0 && len(cronJobs.Items) > 0 {
+		if err := withThrottleRetry(ctx, "list", func() error {
+			return c.Client.List(ctx, &jobs, client.InNamespace(namespace))
+		}); err != nil {
+			return nil, newListError("jobs", namespace, err)
+		}
+	}
+	jobsByOwner := map[types.UID][]*batchv1.Job{}
+	for i := range jobs.Items {
+		if ref := metav1.GetControllerOf(&jobs.Items[i]); ref != nil && ref.Kind == "CronJob" {
+			jobsByOwner[ref.UID] = append(jobsByOwner[ref.UID], &jobs.Items[i])
+		}
+	}
+
+	var stale []staleCronJob
+	seen := map[types.NamespacedName]bool{}
+	for i := range cronJobs.Items {
+		cronJob := &cronJobs.Items[i]
+		key := client.ObjectKeyFromObject(cronJob)
+		seen[key] = true
+		if cronJob.Spec.Suspend != nil && *cronJob.Spec.Suspend {
+			c.cronJobs.forget(key)
+			continue
+		}
+		resumed := c.cronJobs.resumedAt(cronJob, now)
+
+		if threshold := rule.MaxConsecutiveFailures; threshold > 0 {
+			if failures := c.cronJobs.observe(cronJob, jobsByOwner[cronJob.UID], resumed); failures >= threshold {
+				stale = append(stale, staleCronJob{cronJob, fmt.Sprintf("%d consecutive failed Jobs", failures)})
+				continue
+			}
+		}
+
+		if threshold := rule.MaxSinceSuccess.Duration; threshold > 0 {
+			// CronJobs that never succeeded are measured from their creation.
+			since := cronJob.CreationTimestamp.Time
+			if last := cronJob.Status.LastSuccessfulTime; last != nil && last.After(since) {
+				since = last.Time
+			}
+			if resumed.After(since) {
+				since = resumed
+			}
+			if now.Sub(since) > threshold {
+				stale = append(stale, staleCronJob{cronJob, fmt.Sprintf("no successful run for %s", now.Sub(since).Round(time.Minute))})
+			}
+		}
+	}
+
+	// A selector hides CronJobs other rules may track, so only unfiltered listings prune.
+	if rule.Selector == nil {
+		c.cronJobs.prune(namespace, seen)
+	}
+
+	return stale, nil
+}
+
+// suspendCronJobs suspends every stale CronJob, recording why in suspendedReasonAnnotation. It
+// returns the CronJobs suspended, which are none in a dry run.
+func suspendCronJobs(ctx context.Context, k8sClient client.Client, stale []staleCronJob, dryRun bool) ([]staleCronJob, error) {
+	logger := log.FromContext(ctx)
+	var suspended []staleCronJob
+	var errs []error
+
+	for _, s := range stale {
+		name, namespace := s.cronJob.Name, s.cronJob.Namespace
+		if dryRun {
+			logger.Info("DRY RUN: Would suspend CronJob", "name", name, "namespace", namespace, "reason", s.reason)
+			continue
+		}
+
+		logger.Info("Suspending CronJob", "name", name, "namespace", namespace, "reason", s.reason)
+		patch := map[string]any{
+			"metadata": map[string]any{"annotations": map[string]string{suspendedReasonAnnotation: s.reason}},
+			"spec":     map[string]any{"suspend": true},
+		}
+		err := withThrottleRetry(ctx, "patch", func() error { return mergePatch(ctx, k8sClient, s.cronJob, patch) })
+		if err == nil {
+			suspended = append(suspended, s)
+			continue
+		}
+		if client.IgnoreNotFound(err) != nil {
+			logger.Error(err, "Failed to suspend CronJob", "name", name, "namespace", namespace)
+			errs = append(errs, fmt.Errorf("suspend CronJob %s/%s: %w", namespace, name, err))
+		}
+	}
+
+	return suspended, errors.Join(errs...)
+}
+
+// notifyCronJobOwners notifies the owner of each namespace where the rule suspended CronJobs.
+func (c *PodCleanController) notifyCronJobOwners(ctx context.Context, run *cleanupRun, rule string, suspended []staleCronJob) {
+	if len(suspended) == 0 {
+		return
+	}
+	logger := log.FromContext(ctx)
+
+	byNamespace := map[string][]string{}
+	for _, s := range suspended {
+		byNamespace[s.cronJob.Namespace] = append(byNamespace[s.cronJob.Namespace], fmt.Sprintf("%s (%s)", s.cronJob.Name, s.reason))
+	}
+
+	ownership := c.namespaceOwnership(ctx)
+	for _, namespace := range slices.Sorted(maps.Keys(byNamespace)) {
+		sink, err := c.namespaceOwnerSink(ctx, namespace, ownership)
+		if err != nil {
+			logger.Error(err, "Failed to resolve the namespace owner", "namespace", namespace)
+			continue
+		}
+		if sink == nil {
+			continue // Nobody to notify.
+		}
+
+		cronJobs := byNamespace[namespace]
+		event := notify.Event{
+			RunID:     run.ID,
+			Rule:      rule,
+			Namespace: namespace,
+			Kind:      "CronJob",
+			Resources: len(cronJobs),
+			Action:    cleanupconfig.ActionSuspend,
+			Message:   fmt.Sprintf("Suspended %d CronJob(s) in namespace %s: %s", len(cronJobs), namespace, strings.Join(cronJobs, ", ")),
+		}
+		notifier, err := notify.NewNotifier(cleanupconfig.NotificationConfig{Sinks: []cleanupconfig.NotificationSink{*sink}}, nil)
+		if err == nil {
+			err = notifier.Notify(ctx, nil, event)
+		}
+		if err != nil {
+			logger.Error(err, "Failed to notify the namespace owner", "namespace", namespace, "sink", sink.Name)
+		}
+	}
+}
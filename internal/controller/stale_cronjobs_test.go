@@ -0,0 +1,172 @@
+package controller
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	cleanupconfig "github.com/infrautils/kubeclean/internal/cleanup_config"
+	"github.com/infrautils/kubeclean/internal/notify"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func newStaleTestCronJob(name string, created time.Duration, lastSuccess *time.Duration) *batchv1.CronJob {
+	cronJob := &batchv1.CronJob{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: name, Namespace: "team-a", UID: types.UID(name),
+			CreationTimestamp: metav1.NewTime(time.Now().Add(-created)),
+		},
+		Spec: batchv1.CronJobSpec{Schedule: "@daily"},
+	}
+	if lastSuccess != nil {
+		last := metav1.NewTime(time.Now().Add(-*lastSuccess))
+		cronJob.Status.LastSuccessfulTime = &last
+	}
+	return cronJob
+}
+
+func newStaleTestJob(cronJob, name string, age time.Duration, outcome batchv1.JobConditionType) *batchv1.Job {
+	controller := true
+	return &batchv1.Job{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: name, Namespace: "team-a", UID: types.UID(name),
+			CreationTimestamp: metav1.NewTime(time.Now().Add(-age)),
+			OwnerReferences: []metav1.OwnerReference{{
+				APIVersion: "batch/v1", Kind: "CronJob", Name: cronJob, UID: types.UID(cronJob), Controller: &controller,
+			}},
+		},
+		Status: batchv1.JobStatus{Conditions: []batchv1.JobCondition{{Type: outcome, Status: corev1.ConditionTrue}}},
+	}
+}
+
+func TestPodCleanController_SuspendsStaleCronJobs(t *testing.T) {
+	scheme := runtime.NewScheme()
+	_ = corev1.AddToScheme(scheme)
+	_ = batchv1.AddToScheme(scheme)
+
+	var mu sync.Mutex
+	var received []notify.Event
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var event notify.Event
+		_ = json.NewDecoder(r.Body).Decode(&event)
+		mu.Lock()
+		received = append(received, event)
+		mu.Unlock()
+	}))
+	defer server.Close()
+
+	threeDays, fourHours, oneHour := 72*time.Hour, 4*time.Hour-10*time.Minute, time.Hour
+	suspended := true
+	paused := newStaleTestCronJob("paused", 30*24*time.Hour, nil)
+	paused.Spec.Suspend = &suspended
+
+	k8sClient := fake.NewClientBuilder().WithScheme(scheme).WithObjects(
+		&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "team-a", Annotations: map[string]string{AnnotationOwnerSink: "team-a-hook"}}},
+		newStaleTestCronJob("abandoned", 30*24*time.Hour, &threeDays),
+		newStaleTestCronJob("fresh", time.Hour, nil),
+		newStaleTestCronJob("flaky", 30*24*time.Hour, &fourHours),
+		newStaleTestCronJob("recovered", 30*24*time.Hour, &oneHour),
+		paused,
+		newStaleTestJob("flaky", "flaky-1", 4*time.Hour, batchv1.JobComplete),
+		newStaleTestJob("flaky", "flaky-2", 3*time.Hour, batchv1.JobFailed),
+		newStaleTestJob("flaky", "flaky-3", 2*time.Hour, batchv1.JobFailed),
+		newStaleTestJob("flaky", "flaky-4", 1*time.Hour, batchv1.JobFailed),
+		newStaleTestJob("recovered", "recovered-1", 3*time.Hour, batchv1.JobFailed),
+		newStaleTestJob("recovered", "recovered-2", 2*time.Hour, batchv1.JobFailed),
+		newStaleTestJob("recovered", "recovered-3", 1*time.Hour, batchv1.JobComplete),
+	).Build()
+
+	cfg := &cleanupconfig.CleanupConfig{
+		StaleCronJobConfig: cleanupconfig.StaleCronJobConfig{Enabled: true, Rules: []cleanupconfig.StaleCronJobRule{{
+			Name: "zombies", Enabled: true, NotifyOwners: true,
+			MaxSinceSuccess: cleanupconfig.Duration{Duration: 48 * time.Hour}, MaxConsecutiveFailures: 3,
+			NotificationSinks: []string{"team-a-hook"},
+		}}},
+		Notifications: cleanupconfig.NotificationConfig{
+			Sinks: []cleanupconfig.NotificationSink{{Name: "team-a-hook", Type: cleanupconfig.SinkTypeWebhook, TenantSelectable: true, URL: server.URL}},
+		},
+	}
+	controller := NewPodCleanController(k8sClient, scheme, cfg)
+	controller.RunCleanUp(context.Background())
+
+	get := func(name string) *batchv1.CronJob {
+		t.Helper()
+		cronJob := &batchv1.CronJob{}
+		if err := k8sClient.Get(context.Background(), types.NamespacedName{Namespace: "team-a", Name: name}, cronJob); err != nil {
+			t.Fatalf("Failed to get CronJob %s: %v", name, err)
+		}
+		return cronJob
+	}
+	isSuspended := func(cronJob *batchv1.CronJob) bool {
+		return cronJob.Spec.Suspend != nil && *cronJob.Spec.Suspend
+	}
+
+	for name, want := range map[string]bool{"abandoned": true, "flaky": true, "fresh": false, "recovered": false} {
+		if got := isSuspended(get(name)); got != want {
+			t.Errorf("Expected CronJob %s to be suspended=%v, got %v", name, want, got)
+		}
+	}
+	if reason := get("flaky").Annotations[suspendedReasonAnnotation]; reason != "3 consecutive failed Jobs" {
+		t.Errorf("Expected the suspension reason to be recorded, got %q", reason)
+	}
+
+	mu.Lock()
+	if len(received) != 2 {
+		t.Fatalf("Expected a rule notification and an owner notification, got %+v", received)
+	}
+	owner := received[1]
+	if received[0].Namespace == "team-a" {
+		owner = received[0]
+	}
+	if owner.Namespace != "team-a" || owner.Resources != 2 || owner.Action != cleanupconfig.ActionSuspend {
+		t.Errorf("Expected the owner of team-a to be told about 2 suspended CronJobs, got %+v", owner)
+	}
+	mu.Unlock()
+
+	// A CronJob its owner resumes gets a full threshold to recover.
+	abandoned := get("abandoned")
+	abandoned.Spec.Suspend = nil
+	if err := k8sClient.Update(context.Background(), abandoned); err != nil {
+		t.Fatalf("Failed to resume CronJob: %v", err)
+	}
+	controller.RunCleanUp(context.Background())
+
+	if isSuspended(get("abandoned")) {
+		t.Errorf("Expected a resumed CronJob not to be suspended again right away")
+	}
+}
+
+func TestCronJobTracker_CountsPrunedFailures(t *testing.T) {
+	tracker := newCronJobTracker()
+	cronJob := newStaleTestCronJob("nightly", 30*24*time.Hour, nil)
+
+	first := []*batchv1.Job{
+		newStaleTestJob("nightly", "nightly-1", 3*time.Hour, batchv1.JobFailed),
+		newStaleTestJob("nightly", "nightly-2", 2*time.Hour, batchv1.JobFailed),
+	}
+	if failures := tracker.observe(cronJob, first, time.Time{}); failures != 2 {
+		t.Errorf("Expected 2 failures, got %d", failures)
+	}
+
+	// failedJobsHistoryLimit pruned the earlier failures.
+	second := []*batchv1.Job{newStaleTestJob("nightly", "nightly-3", time.Hour, batchv1.JobFailed)}
+	if failures := tracker.observe(cronJob, second, time.Time{}); failures != 3 {
+		t.Errorf("Expected pruned failures to still count, got %d", failures)
+	}
+
+	// A success reported only through the CronJob's status clears the earlier failures.
+	last := metav1.NewTime(time.Now().Add(-30 * time.Minute))
+	cronJob.Status.LastSuccessfulTime = &last
+	if failures := tracker.observe(cronJob, second, time.Time{}); failures != 0 {
+		t.Errorf("Expected a later success to clear the failures, got %d", failures)
+	}
+}
@@ -0,0 +1,133 @@
+package controller
+
+import (
+	"context"
+	"slices"
+
+	kubecleanv1alpha1 "github.com/infrautils/kubeclean/api/v1alpha1"
+	cleanupconfig "github.com/infrautils/kubeclean/internal/cleanup_config"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+// Condition type and reasons reported on CleanupRule resources.
+const (
+	ConditionAccepted = "Accepted"
+
+	ReasonAccepted    = "Accepted"    // The rule is planned with the file rules.
+	ReasonInvalid     = "Invalid"     // The rule is malformed, e.g. it has no TTL.
+	ReasonOutOfBounds = "OutOfBounds" // The rule violates the tenantRules bounds or the constraints.
+)
+
+// tenantRule is a CleanupRule resource along with the pod rule it translates to.
+type tenantRule struct {
+	Object *kubecleanv1alpha1.CleanupRule
+	Rule   cleanupconfig.PodCleanRule
+	Reason string // Why the rule was accepted or rejected; one of the Reason constants.
+	Err    error  // Set when the rule is rejected.
+}
+
+// listTenantRules lists every CleanupRule and checks it against the tenant bounds and the
+// constraints of cfg. Rules are clamped when the constraints are in clamp mode.
+func listTenantRules(ctx context.Context, k8sClient client.Client, cfg *cleanupconfig.CleanupConfig) ([]tenantRule, error) {
+	var list kubecleanv1alpha1.CleanupRuleList
+	if err := withThrottleRetry(ctx, "list", func() error {
+		return k8sClient.List(ctx, &list)
+	}); err != nil {
+		return nil, newListError("cleanuprules", "", err)
+	}
+
+	rules := make([]tenantRule, 0, len(list.Items))
+	for i := range list.Items {
+		obj := &list.Items[i]
+		tenant := tenantRule{Object: obj, Rule: toPodCleanRule(obj), Reason: ReasonAccepted}
+
+		if err := tenant.Rule.Validate(); err != nil {
+			tenant.Reason, tenant.Err = ReasonInvalid, err
+		} else if err := cfg.TenantRules.CheckBounds(tenant.Rule); err != nil {
+			tenant.Reason, tenant.Err = ReasonOutOfBounds, err
+		} else if tenant.Rule, err = cfg.Constraints.Enforce(tenant.Rule); err != nil {
+			tenant.Reason, tenant.Err = ReasonOutOfBounds, err
+		}
+
+		rules = append(rules, tenant)
+	}
+
+	return rules, nil
+}
+
+// toPodCleanRule translates a CleanupRule into a pod rule confined to the CleanupRule's namespace
+// and named after it, e.g. "team-a/old-jobs".
+func toPodCleanRule(obj *kubecleanv1alpha1.CleanupRule) cleanupconfig.PodCleanRule {
+	rule := cleanupconfig.PodCleanRule{
+		Name:                 obj.Namespace + "/" + obj.Name,
+		Enabled:              true,
+		Phase:                obj.Spec.Phase,
+		TTL:                  cleanupconfig.Duration{Duration: obj.Spec.TTL.Duration},
+		Namespaces:           []string{obj.Namespace},
+		ExcludeSelector:      obj.Spec.ExcludeSelector,
+		DeleteOwnerWhenEmpty: obj.Spec.DeleteOwnerWhenEmpty,
+	}
+	if obj.Spec.Selector != nil {
+		rule.Selector = *obj.Spec.Selector
+	}
+	return rule
+}
+
+// withTenantRules returns the controller's config extended with the accepted tenant rules, along
+// with every tenant rule found. Without tenant rules enabled, the config is returned as is.
+func (c *PodCleanController) withTenantRules(ctx context.Context) (*cleanupconfig.CleanupConfig, []tenantRule) {
+	if !c.CleanupConfig.TenantRules.Enabled {
+		return c.CleanupConfig, nil
+	}
+
+	tenants, err := listTenantRules(ctx, c.Client, c.CleanupConfig)
+	if err != nil {
+		log.FromContext(ctx).Error(err, "Failed to list tenant rules; planning file rules only")
+		return c.CleanupConfig, nil
+	}
+
+	cfg := *c.CleanupConfig
+	cfg.PodCleanupConfig.Rules = slices.Clone(cfg.PodCleanupConfig.Rules)
+	for _, tenant := range tenants {
+		if tenant.Err == nil {
+			cfg.PodCleanupConfig.Rules = append(cfg.PodCleanupConfig.Rules, tenant.Rule)
+		}
+	}
+
+	return &cfg, tenants
+}
+
+// reportTenantRules records on each CleanupRule whether it was accepted. Resources whose
+// condition is unchanged are not updated.
+func (c *PodCleanController) reportTenantRules(ctx context.Context, tenants []tenantRule) {
+	logger := log.FromContext(ctx)
+
+	for _, tenant := range tenants {
+		condition := metav1.Condition{
+			Type:               ConditionAccepted,
+			Status:             metav1.ConditionTrue,
+			Reason:             tenant.Reason,
+			Message:            "Rule is applied to namespace " + tenant.Object.Namespace,
+			ObservedGeneration: tenant.Object.Generation,
+		}
+		if tenant.Err != nil {
+			condition.Status = metav1.ConditionFalse
+			condition.Message = tenant.Err.Error()
+			logger.Info("Rejected tenant rule", "rule", tenant.Rule.Name, "reason", tenant.Reason, "error", tenant.Err)
+		}
+
+		obj := tenant.Object
+		changed := meta.SetStatusCondition(&obj.Status.Conditions, condition)
+		if !changed && obj.Status.ObservedGeneration == obj.Generation {
+			continue
+		}
+		obj.Status.ObservedGeneration = obj.Generation
+
+		if err := c.Client.Status().Update(ctx, obj); err != nil {
+			logger.Error(err, "Failed to update tenant rule status", "rule", tenant.Rule.Name)
+		}
+	}
+}
@@ -0,0 +1,89 @@
+package controller
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	kubecleanv1alpha1 "github.com/infrautils/kubeclean/api/v1alpha1"
+	cleanupconfig "github.com/infrautils/kubeclean/internal/cleanup_config"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestPodCleanupController_TenantRules(t *testing.T) {
+	scheme := runtime.NewScheme()
+	_ = corev1.AddToScheme(scheme)
+	_ = kubecleanv1alpha1.AddToScheme(scheme)
+
+	newPod := func(name, namespace string) *corev1.Pod {
+		return &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:              name,
+				Namespace:         namespace,
+				CreationTimestamp: metav1.NewTime(time.Now().Add(-2 * time.Hour)),
+			},
+			Status: corev1.PodStatus{Phase: corev1.PodSucceeded},
+		}
+	}
+
+	newRule := func(name, namespace string, spec kubecleanv1alpha1.CleanupRuleSpec) *kubecleanv1alpha1.CleanupRule {
+		return &kubecleanv1alpha1.CleanupRule{ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace}, Spec: spec}
+	}
+
+	hour := metav1.Duration{Duration: time.Hour}
+	accepted := newRule("done", "team-a", kubecleanv1alpha1.CleanupRuleSpec{Phase: "Succeeded", TTL: hour})
+	tooShort := newRule("eager", "team-b", kubecleanv1alpha1.CleanupRuleSpec{Phase: "Succeeded", TTL: metav1.Duration{Duration: time.Minute}})
+	ownerAction := newRule("owners", "team-c", kubecleanv1alpha1.CleanupRuleSpec{Phase: "Succeeded", TTL: hour, DeleteOwnerWhenEmpty: true})
+	invalid := newRule("empty", "team-d", kubecleanv1alpha1.CleanupRuleSpec{TTL: hour})
+
+	client := fake.NewClientBuilder().WithScheme(scheme).
+		WithStatusSubresource(&kubecleanv1alpha1.CleanupRule{}).
+		WithRuntimeObjects(
+			newPod("a", "team-a"), newPod("b", "team-b"), newPod("c", "team-c"), newPod("other", "default"),
+			accepted, tooShort, ownerAction, invalid,
+		).Build()
+
+	controller := NewPodCleanController(client, scheme, &cleanupconfig.CleanupConfig{
+		BatchSize:        10,
+		PodCleanupConfig: cleanupconfig.PodCleanupConfig{Enabled: true},
+		TenantRules:      cleanupconfig.TenantRulesConfig{Enabled: true, MinTTL: cleanupconfig.Duration{Duration: 30 * time.Minute}},
+	})
+
+	summary := controller.RunCleanUp(context.Background())
+
+	if summary.Matched != 1 || summary.MatchedByRule["team-a/done"] != 1 {
+		t.Errorf("Expected only team-a/done to match one pod, got %v", summary.MatchedByRule)
+	}
+
+	var pods corev1.PodList
+	if err := client.List(context.Background(), &pods); err != nil {
+		t.Fatalf("Failed to list pods: %v", err)
+	}
+	if len(pods.Items) != 3 {
+		t.Errorf("Expected 3 pods to remain, got %d", len(pods.Items))
+	}
+
+	expected := map[string]string{
+		"team-a/done":   ReasonAccepted,
+		"team-b/eager":  ReasonOutOfBounds,
+		"team-c/owners": ReasonOutOfBounds,
+		"team-d/empty":  ReasonInvalid,
+	}
+	for _, obj := range []*kubecleanv1alpha1.CleanupRule{accepted, tooShort, ownerAction, invalid} {
+		var got kubecleanv1alpha1.CleanupRule
+		if err := client.Get(context.Background(), types.NamespacedName{Namespace: obj.Namespace, Name: obj.Name}, &got); err != nil {
+			t.Fatalf("Failed to get %s/%s: %v", obj.Namespace, obj.Name, err)
+		}
+
+		condition := meta.FindStatusCondition(got.Status.Conditions, ConditionAccepted)
+		key := obj.Namespace + "/" + obj.Name
+		if condition == nil || condition.Reason != expected[key] {
+			t.Errorf("Expected %s to have reason %q, got %+v", key, expected[key], condition)
+		}
+	}
+}
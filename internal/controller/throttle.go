@@ -0,0 +1,63 @@
+package controller
+
+import (
+	"context"
+	"time"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+const (
+	// maxThrottleRetries bounds how often a single call is retried after a 429 response.
+	maxThrottleRetries = 3
+	// defaultThrottleDelay is used when a 429 response carries no Retry-After hint.
+	defaultThrottleDelay = time.Second
+)
+
+// throttleDelay reports whether err is a 429 response and how long the server asked us to wait.
+func throttleDelay(err error) (time.Duration, bool) {
+	if err == nil || !apierrors.IsTooManyRequests(err) {
+		return 0, false
+	}
+
+	if seconds, ok := apierrors.SuggestsClientDelay(err); ok && seconds > 0 {
+		return time.Duration(seconds) * time.Second, true
+	}
+
+	return defaultThrottleDelay, true
+}
+
+// withThrottleRetry calls fn and, when the API server responds with 429, pauses for the
+// Retry-After duration before retrying instead of moving on to the next request.
+func withThrottleRetry(ctx context.Context, operation string, fn func() error) error {
+	for attempt := 0; ; attempt++ {
+		err := fn()
+
+		delay, throttled := throttleDelay(err)
+		if !throttled || attempt >= maxThrottleRetries {
+			return err
+		}
+
+		throttledTotal.WithLabelValues(operation).Inc()
+		throttleWaitSecondsTotal.WithLabelValues(operation).Add(delay.Seconds())
+		log.FromContext(ctx).Info("API server throttled request; pausing", "operation", operation, "retryAfter", delay)
+
+		if err := waitFor(ctx, delay); err != nil {
+			return err
+		}
+	}
+}
+
+// waitFor blocks for d or until ctx is done, whichever comes first.
+func waitFor(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,102 @@
+package controller
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	cleanupconfig "github.com/infrautils/kubeclean/internal/cleanup_config"
+	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// unusedSinceAnnotation records on a resource when kubeclean first observed it unused, so the TTL
+// of reference-based orphan rules measures continuous unuse across controller restarts.
+const unusedSinceAnnotation = "kubeclean.io/unused-since"
+
+// persistedOrphanKinds maps the orphan kinds whose first observation is persisted on the resource
+// to the kind of their resources. They are the kinds found unused by scanning for references or
+// pods, where a TTL is typically days and a restart would otherwise start it over.
+var persistedOrphanKinds = map[string]schema.GroupVersionKind{
+	cleanupconfig.OrphanKindServiceAccount:  corev1.SchemeGroupVersion.WithKind("ServiceAccount"),
+	cleanupconfig.OrphanKindImagePullSecret: corev1.SchemeGroupVersion.WithKind("Secret"),
+	cleanupconfig.OrphanKindNetworkPolicy:   networkingv1.SchemeGroupVersion.WithKind("NetworkPolicy"),
+}
+
+// unusedSince returns the time recorded in obj's unusedSinceAnnotation, if it holds one.
+func unusedSince(obj client.Object) (time.Time, bool) {
+	value, ok := obj.GetAnnotations()[unusedSinceAnnotation]
+	if !ok {
+		return time.Time{}, false
+	}
+	since, err := time.Parse(time.RFC3339, value)
+	return since, err == nil
+}
+
+// persistUnusedSince records in unusedSinceAnnotation when each orphan was first seen, unless it
+// already carries that time. After a complete listing, it also removes the annotation from the
+// resources of gvk in namespaces that are in use again, so a later unuse starts a new TTL.
+func persistUnusedSince(ctx context.Context, k8sClient client.Client, gvk schema.GroupVersionKind, namespaces []string,
+	orphans []client.Object, firstSeen map[types.NamespacedName]time.Time, complete bool) error {
+	var errs []error
+	orphaned := map[types.NamespacedName]bool{}
+
+	for _, obj := range orphans {
+		key := client.ObjectKeyFromObject(obj)
+		orphaned[key] = true
+
+		value := firstSeen[key].UTC().Format(time.RFC3339)
+		if obj.GetAnnotations()[unusedSinceAnnotation] == value {
+			continue
+		}
+		if err := setUnusedSince(ctx, k8sClient, obj, &value); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	if !complete {
+		return errors.Join(errs...)
+	}
+
+	for _, namespace := range namespaces {
+		list := &metav1.PartialObjectMetadataList{}
+		list.SetGroupVersionKind(gvk.GroupVersion().WithKind(gvk.Kind + "List"))
+		if err := withThrottleRetry(ctx, "list", func() error {
+			return k8sClient.List(ctx, list, client.InNamespace(namespace))
+		}); err != nil {
+			errs = append(errs, newListError(gvk.Kind, namespace, err))
+			continue
+		}
+
+		for i := range list.Items {
+			obj := &list.Items[i]
+			if _, ok := obj.Annotations[unusedSinceAnnotation]; !ok || orphaned[client.ObjectKeyFromObject(obj)] {
+				continue
+			}
+			obj.SetGroupVersionKind(gvk)
+			if err := setUnusedSince(ctx, k8sClient, obj, nil); err != nil {
+				errs = append(errs, err)
+			}
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// setUnusedSince sets obj's unusedSinceAnnotation to value, or removes it when value is nil.
+func setUnusedSince(ctx context.Context, k8sClient client.Client, obj client.Object, value *string) error {
+	err := withThrottleRetry(ctx, "patch", func() error {
+		return mergePatch(ctx, k8sClient, obj, map[string]any{
+			"metadata": map[string]any{"annotations": map[string]any{unusedSinceAnnotation: value}},
+		})
+	})
+	if apierrors.IsNotFound(err) {
+		return nil
+	}
+	return err
+}
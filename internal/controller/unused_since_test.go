@@ -0,0 +1,131 @@
+package controller
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	cleanupconfig "github.com/infrautils/kubeclean/internal/cleanup_config"
+	appsv1 "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestPodCleanupController_UnusedSinceSurvivesRestart(t *testing.T) {
+	scheme := runtime.NewScheme()
+	_ = corev1.AddToScheme(scheme)
+	_ = appsv1.AddToScheme(scheme)
+	_ = batchv1.AddToScheme(scheme)
+
+	stale := time.Now().Add(-48 * time.Hour).UTC().Format(time.RFC3339)
+	k8sClient := fake.NewClientBuilder().WithScheme(scheme).WithObjects(
+		&corev1.ServiceAccount{ObjectMeta: metav1.ObjectMeta{Name: "unused", Namespace: "default"}},
+		&corev1.ServiceAccount{ObjectMeta: metav1.ObjectMeta{
+			Name: "api", Namespace: "default", Annotations: map[string]string{unusedSinceAnnotation: stale},
+		}},
+		&corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: "api-0", Namespace: "default"},
+			Spec:       corev1.PodSpec{ServiceAccountName: "api"},
+		},
+	).Build()
+
+	disabled := false
+	cfg := &cleanupconfig.CleanupConfig{
+		OrphanCleanupConfig: cleanupconfig.OrphanCleanupConfig{Enabled: true, Rules: []cleanupconfig.OrphanCleanRule{{
+			Name: "unused-accounts", Enabled: true, Kind: cleanupconfig.OrphanKindServiceAccount,
+			TTL: cleanupconfig.Duration{Duration: 24 * time.Hour}, DryRun: &disabled,
+		}}},
+	}
+	get := func(name string) (*corev1.ServiceAccount, error) {
+		sa := &corev1.ServiceAccount{}
+		return sa, k8sClient.Get(context.Background(), types.NamespacedName{Namespace: "default", Name: name}, sa)
+	}
+
+	NewPodCleanController(k8sClient, scheme, cfg).RunCleanUp(context.Background())
+
+	unused, err := get("unused")
+	if err != nil {
+		t.Fatalf("Expected the unused account to survive its TTL, got %v", err)
+	}
+	if _, ok := unusedSince(unused); !ok {
+		t.Errorf("Expected the first observation to be recorded, got annotations %v", unused.Annotations)
+	}
+	if api, _ := get("api"); api.Annotations[unusedSinceAnnotation] != "" {
+		t.Errorf("Expected the annotation of an account in use to be removed, got %v", api.Annotations)
+	}
+
+	// A restarted controller picks up the recorded time instead of starting the TTL over.
+	unused.Annotations[unusedSinceAnnotation] = stale
+	if err := k8sClient.Update(context.Background(), unused); err != nil {
+		t.Fatalf("Failed to update account: %v", err)
+	}
+	NewPodCleanController(k8sClient, scheme, cfg).RunCleanUp(context.Background())
+
+	if _, err := get("unused"); !apierrors.IsNotFound(err) {
+		t.Errorf("Expected the account unused for 48h to be deleted after a restart, got %v", err)
+	}
+}
+
+func TestPodCleanupController_IdleNetworkPolicySurvivesRestart(t *testing.T) {
+	scheme := runtime.NewScheme()
+	_ = corev1.AddToScheme(scheme)
+	_ = networkingv1.AddToScheme(scheme)
+
+	stale := time.Now().Add(-48 * time.Hour).UTC().Format(time.RFC3339)
+	policy := func(name, app string, annotations map[string]string) *networkingv1.NetworkPolicy {
+		return &networkingv1.NetworkPolicy{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default", Annotations: annotations},
+			Spec:       networkingv1.NetworkPolicySpec{PodSelector: metav1.LabelSelector{MatchLabels: map[string]string{"app": app}}},
+		}
+	}
+	k8sClient := fake.NewClientBuilder().WithScheme(scheme).WithObjects(
+		policy("retired", "retired", nil),
+		policy("api", "api", map[string]string{unusedSinceAnnotation: stale}),
+		&corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: "api-0", Namespace: "default", Labels: map[string]string{"app": "api"}},
+			Status:     corev1.PodStatus{Phase: corev1.PodRunning},
+		},
+	).Build()
+
+	disabled := false
+	cfg := &cleanupconfig.CleanupConfig{
+		OrphanCleanupConfig: cleanupconfig.OrphanCleanupConfig{Enabled: true, Rules: []cleanupconfig.OrphanCleanRule{{
+			Name: "idle-policies", Enabled: true, Kind: cleanupconfig.OrphanKindNetworkPolicy,
+			TTL: cleanupconfig.Duration{Duration: 24 * time.Hour}, DryRun: &disabled,
+		}}},
+	}
+	get := func(name string) (*networkingv1.NetworkPolicy, error) {
+		policy := &networkingv1.NetworkPolicy{}
+		return policy, k8sClient.Get(context.Background(), types.NamespacedName{Namespace: "default", Name: name}, policy)
+	}
+
+	NewPodCleanController(k8sClient, scheme, cfg).RunCleanUp(context.Background())
+
+	retired, err := get("retired")
+	if err != nil {
+		t.Fatalf("Expected the idle policy to survive its TTL, got %v", err)
+	}
+	if _, ok := unusedSince(retired); !ok {
+		t.Errorf("Expected the first observation to be recorded, got annotations %v", retired.Annotations)
+	}
+	if api, _ := get("api"); api.Annotations[unusedSinceAnnotation] != "" {
+		t.Errorf("Expected the annotation of a policy matching pods again to be removed, got %v", api.Annotations)
+	}
+
+	// A restarted controller picks up the recorded time instead of starting the TTL over.
+	retired.Annotations[unusedSinceAnnotation] = stale
+	if err := k8sClient.Update(context.Background(), retired); err != nil {
+		t.Fatalf("Failed to update policy: %v", err)
+	}
+	NewPodCleanController(k8sClient, scheme, cfg).RunCleanUp(context.Background())
+
+	if _, err := get("retired"); !apierrors.IsNotFound(err) {
+		t.Errorf("Expected the policy idle for 48h to be deleted after a restart, got %v", err)
+	}
+}
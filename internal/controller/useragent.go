@@ -0,0 +1,54 @@
+package controller
+
+import (
+	"context"
+	"net/http"
+
+	utilnet "k8s.io/apimachinery/pkg/util/net"
+	"k8s.io/client-go/transport"
+)
+
+// DefaultFieldManager is the field manager recorded on objects kubeclean writes.
+const DefaultFieldManager = "kubeclean"
+
+type ruleContextKey struct{}
+
+// withRule marks API calls made with ctx as made on behalf of the named rule.
+func withRule(ctx context.Context, name string) context.Context {
+	return context.WithValue(ctx, ruleContextKey{}, name)
+}
+
+// ruleFromContext returns the rule ctx was marked with by withRule.
+func ruleFromContext(ctx context.Context) (string, bool) {
+	name, ok := ctx.Value(ruleContextKey{}).(string)
+	return name, ok && name != ""
+}
+
+// RuleUserAgent returns a transport wrapper that sends "<userAgent> rule=<name>" as the
+// User-Agent of requests made on behalf of a rule, so audit logs and API priority-and-fairness
+// rules can tell rules apart. Other requests are sent with userAgent as is.
+func RuleUserAgent(userAgent string) transport.WrapperFunc {
+	return func(rt http.RoundTripper) http.RoundTripper {
+		return &ruleUserAgentRoundTripper{userAgent: userAgent, rt: rt}
+	}
+}
+
+type ruleUserAgentRoundTripper struct {
+	userAgent string
+	rt        http.RoundTripper
+}
+
+func (r *ruleUserAgentRoundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	name, ok := ruleFromContext(req.Context())
+	if !ok {
+		return r.rt.RoundTrip(req)
+	}
+
+	req = utilnet.CloneRequest(req)
+	req.Header.Set("User-Agent", r.userAgent+" rule="+name)
+	return r.rt.RoundTrip(req)
+}
+
+func (r *ruleUserAgentRoundTripper) WrappedRoundTripper() http.RoundTripper {
+	return r.rt
+}
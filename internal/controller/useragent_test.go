@@ -0,0 +1,45 @@
+package controller
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+type recordingRoundTripper struct {
+	userAgent string
+}
+
+func (r *recordingRoundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	r.userAgent = req.Header.Get("User-Agent")
+	return &http.Response{StatusCode: http.StatusOK, Body: http.NoBody}, nil
+}
+
+func TestRuleUserAgent(t *testing.T) {
+	recorder := &recordingRoundTripper{}
+	rt := RuleUserAgent("kubeclean/v1.2.3")(recorder)
+
+	tests := []struct {
+		name     string
+		ctx      context.Context
+		expected string
+	}{
+		{name: "outside a rule", ctx: context.Background(), expected: "client-default"},
+		{name: "within a rule", ctx: withRule(context.Background(), "failed-pods"), expected: "kubeclean/v1.2.3 rule=failed-pods"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/api/v1/pods", nil).WithContext(tt.ctx)
+			req.Header.Set("User-Agent", "client-default")
+
+			if _, err := rt.RoundTrip(req); err != nil {
+				t.Fatalf("RoundTrip failed: %v", err)
+			}
+			if recorder.userAgent != tt.expected {
+				t.Errorf("Expected User-Agent %q, got %q", tt.expected, recorder.userAgent)
+			}
+		})
+	}
+}
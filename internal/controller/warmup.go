@@ -0,0 +1,47 @@
+package controller
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+
+	cleanupconfig "github.com/infrautils/kubeclean/internal/cleanup_config"
+)
+
+// warmupCounter counts the runs since startup or the last config change, so the first
+// warmupRuns of them can be forced to dry-run. Runtime rule overrides are not config changes.
+type warmupCounter struct {
+	fingerprint string
+	runs        int
+}
+
+// next records a run under cfg and returns its number since the last config change and
+// whether it falls within cfg's warm-up period.
+func (w *warmupCounter) next(cfg *cleanupconfig.CleanupConfig) (int, bool) {
+	if fingerprint := configFingerprint(cfg); fingerprint != w.fingerprint {
+		w.fingerprint = fingerprint
+		w.runs = 0
+	}
+
+	w.runs++
+	return w.runs, w.runs <= cfg.WarmupRuns
+}
+
+// active reports whether the next run under cfg falls within its warm-up period, without
+// recording a run.
+func (w *warmupCounter) active(cfg *cleanupconfig.CleanupConfig) bool {
+	if configFingerprint(cfg) != w.fingerprint {
+		return cfg.WarmupRuns > 0
+	}
+	return w.runs < cfg.WarmupRuns
+}
+
+// configFingerprint returns a digest identifying the content of cfg.
+func configFingerprint(cfg *cleanupconfig.CleanupConfig) string {
+	data, err := json.Marshal(cfg)
+	if err != nil {
+		return ""
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
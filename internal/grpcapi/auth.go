@@ -0,0 +1,98 @@
+package grpcapi
+
+import (
+	"context"
+	"errors"
+
+	"github.com/infrautils/kubeclean/internal/auth"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// methodVerbs maps methods to the verbs checked by delegated authorization. Methods not listed
+// only read state and are checked as "get".
+var methodVerbs = map[string]string{
+	TriggerRunMethod: "create",
+}
+
+// authorize checks the bearer token in the "authorization" metadata of ctx against authorizer
+// and returns ctx carrying the authenticated user.
+func authorize(ctx context.Context, authorizer *auth.Authorizer, method string) (context.Context, error) {
+	var token string
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		if values := md.Get("authorization"); len(values) > 0 {
+			token = auth.BearerToken(values[0])
+		}
+	}
+
+	verb, ok := methodVerbs[method]
+	if !ok {
+		verb = "get"
+	}
+
+	user, err := authorizer.Authorize(ctx, token, auth.Attributes{Path: method, Verb: verb})
+	switch {
+	case errors.Is(err, auth.ErrUnauthenticated):
+		return nil, status.Error(codes.Unauthenticated, err.Error())
+	case errors.Is(err, auth.ErrForbidden):
+		return nil, status.Error(codes.PermissionDenied, err.Error())
+	case err != nil:
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+	return auth.WithUser(ctx, user), nil
+}
+
+// unaryAuthInterceptor rejects unary calls the authorizer does not allow.
+func unaryAuthInterceptor(authorizer *auth.Authorizer) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		ctx, err := authorize(ctx, authorizer, info.FullMethod)
+		if err != nil {
+			return nil, err
+		}
+		return handler(ctx, req)
+	}
+}
+
+// streamAuthInterceptor rejects streams the authorizer does not allow.
+func streamAuthInterceptor(authorizer *auth.Authorizer) grpc.StreamServerInterceptor {
+	return func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		ctx, err := authorize(ss.Context(), authorizer, info.FullMethod)
+		if err != nil {
+			return err
+		}
+		return handler(srv, &authenticatedStream{ServerStream: ss, ctx: ctx})
+	}
+}
+
+// authenticatedStream overrides the context of a stream with one carrying the authenticated user.
+type authenticatedStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *authenticatedStream) Context() context.Context {
+	return s.ctx
+}
+
+// bearerToken sends a static token in the "authorization" metadata of every call.
+type bearerToken struct {
+	token    string
+	insecure bool
+}
+
+// BearerToken returns call credentials presenting token to a server with authentication enabled.
+// Unless allowInsecure is set, the token is only sent over TLS connections.
+func BearerToken(token string, allowInsecure bool) credentials.PerRPCCredentials {
+	return bearerToken{token: token, insecure: allowInsecure}
+}
+
+func (t bearerToken) GetRequestMetadata(context.Context, ...string) (map[string]string, error) {
+	return map[string]string{"authorization": "Bearer " + t.token}, nil
+}
+
+func (t bearerToken) RequireTransportSecurity() bool {
+	return !t.insecure
+}
@@ -0,0 +1,62 @@
+package grpcapi
+
+import (
+	"context"
+	"io"
+
+	"github.com/infrautils/kubeclean/internal/controller"
+	"google.golang.org/grpc"
+)
+
+// Client calls kubeclean's gRPC API over conn.
+type Client struct {
+	conn grpc.ClientConnInterface
+}
+
+// NewClient returns a client using conn.
+func NewClient(conn grpc.ClientConnInterface) *Client {
+	return &Client{conn: conn}
+}
+
+// TriggerRun starts a cleanup pass and returns its run ID.
+func (c *Client) TriggerRun(ctx context.Context) (string, error) {
+	resp := &TriggerRunResponse{}
+	if err := c.conn.Invoke(ctx, TriggerRunMethod, &TriggerRunRequest{}, resp, grpc.CallContentSubtype(CodecName)); err != nil {
+		return "", err
+	}
+	return resp.RunID, nil
+}
+
+// GetHistory returns up to limit recent pass summaries, newest first.
+func (c *Client) GetHistory(ctx context.Context, limit int) ([]controller.RunSummary, error) {
+	resp := &GetHistoryResponse{}
+	if err := c.conn.Invoke(ctx, GetHistoryMethod, &GetHistoryRequest{Limit: limit}, resp, grpc.CallContentSubtype(CodecName)); err != nil {
+		return nil, err
+	}
+	return resp.Runs, nil
+}
+
+// StreamProgress calls fn for every progress event until ctx is cancelled or, when runID is set,
+// until that pass completes.
+func (c *Client) StreamProgress(ctx context.Context, runID string, fn func(controller.ProgressEvent)) error {
+	stream, err := c.conn.NewStream(ctx, &serviceDesc.Streams[0], StreamProgressMethod, grpc.CallContentSubtype(CodecName))
+	if err != nil {
+		return err
+	}
+	if err := stream.SendMsg(&StreamProgressRequest{RunID: runID}); err != nil {
+		return err
+	}
+	if err := stream.CloseSend(); err != nil {
+		return err
+	}
+
+	for {
+		var event controller.ProgressEvent
+		if err := stream.RecvMsg(&event); err == io.EOF {
+			return nil
+		} else if err != nil {
+			return err
+		}
+		fn(event)
+	}
+}
@@ -0,0 +1,30 @@
+package grpcapi
+
+import (
+	"encoding/json"
+
+	"google.golang.org/grpc/encoding"
+)
+
+// CodecName is the content subtype of the API: messages are JSON-encoded, so clients send
+// "application/grpc+json" requests, e.g. with grpc.CallContentSubtype(CodecName).
+const CodecName = "json"
+
+// jsonCodec encodes messages as JSON using the same field names as the admin API.
+type jsonCodec struct{}
+
+func (jsonCodec) Marshal(v interface{}) ([]byte, error) {
+	return json.Marshal(v)
+}
+
+func (jsonCodec) Unmarshal(data []byte, v interface{}) error {
+	return json.Unmarshal(data, v)
+}
+
+func (jsonCodec) Name() string {
+	return CodecName
+}
+
+func init() {
+	encoding.RegisterCodec(jsonCodec{})
+}
@@ -0,0 +1,72 @@
+package grpcapi
+
+import (
+	"context"
+	"crypto/tls"
+	"net"
+
+	"github.com/infrautils/kubeclean/internal/auth"
+	"github.com/infrautils/kubeclean/internal/controller"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+// Server serves kubeclean's gRPC API.
+type Server struct {
+	addr       string
+	controller *controller.PodCleanController
+
+	TLSConfig  *tls.Config      // Serves TLS, and mTLS when it requires client certificates, if set.
+	Authorizer *auth.Authorizer // Authenticates and authorizes every call, if set.
+}
+
+// NewServer returns a gRPC API server bound to addr that operates on the given controller.
+func NewServer(addr string, podCleanController *controller.PodCleanController) *Server {
+	return &Server{addr: addr, controller: podCleanController}
+}
+
+// Register registers the service on srv.
+func Register(srv *grpc.Server, podCleanController *controller.PodCleanController) {
+	srv.RegisterService(&serviceDesc, &service{controller: podCleanController})
+}
+
+// Start serves the API until ctx is cancelled. It implements manager.Runnable.
+func (s *Server) Start(ctx context.Context) error {
+	listener, err := net.Listen("tcp", s.addr)
+	if err != nil {
+		return err
+	}
+
+	var opts []grpc.ServerOption
+	if s.TLSConfig != nil {
+		opts = append(opts, grpc.Creds(credentials.NewTLS(s.TLSConfig)))
+	}
+	if s.Authorizer.Enabled() {
+		opts = append(opts,
+			grpc.ChainUnaryInterceptor(unaryAuthInterceptor(s.Authorizer)),
+			grpc.ChainStreamInterceptor(streamAuthInterceptor(s.Authorizer)))
+	}
+
+	srv := grpc.NewServer(opts...)
+	Register(srv, s.controller)
+
+	errCh := make(chan error, 1)
+	go func() {
+		log.FromContext(ctx).Info("Starting gRPC API server", "address", s.addr)
+		errCh <- srv.Serve(listener)
+	}()
+
+	select {
+	case <-ctx.Done():
+		srv.GracefulStop()
+		return nil
+	case err := <-errCh:
+		return err
+	}
+}
+
+// NeedLeaderElection reports that the API is served by every replica, not just the leader.
+func (s *Server) NeedLeaderElection() bool {
+	return false
+}
@@ -0,0 +1,135 @@
+package grpcapi
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/infrautils/kubeclean/internal/auth"
+	cleanupconfig "github.com/infrautils/kubeclean/internal/cleanup_config"
+	"github.com/infrautils/kubeclean/internal/controller"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func newTestClient(t *testing.T, podCleanController *controller.PodCleanController) *Client {
+	t.Helper()
+	return newTestClientWithServer(t, grpc.NewServer(), podCleanController)
+}
+
+func newTestClientWithServer(t *testing.T, srv *grpc.Server, podCleanController *controller.PodCleanController, dialOpts ...grpc.DialOption) *Client {
+	t.Helper()
+
+	listener := bufconn.Listen(1 << 20)
+	Register(srv, podCleanController)
+	go func() { _ = srv.Serve(listener) }()
+	t.Cleanup(srv.Stop)
+
+	dialOpts = append(dialOpts,
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return listener.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()))
+	conn, err := grpc.NewClient("passthrough:///bufnet", dialOpts...)
+	if err != nil {
+		t.Fatalf("Failed to dial: %v", err)
+	}
+	t.Cleanup(func() { _ = conn.Close() })
+
+	return NewClient(conn)
+}
+
+func TestServer_TriggerRunStreamsProgress(t *testing.T) {
+	scheme := runtime.NewScheme()
+	_ = corev1.AddToScheme(scheme)
+
+	var objects []runtime.Object
+	for _, name := range []string{"a", "b", "c"} {
+		objects = append(objects, &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default", CreationTimestamp: metav1.NewTime(time.Now().Add(-2 * time.Hour))},
+			Status:     corev1.PodStatus{Phase: corev1.PodSucceeded},
+		})
+	}
+	k8sClient := fake.NewClientBuilder().WithScheme(scheme).WithRuntimeObjects(objects...).Build()
+
+	podCleanController := controller.NewPodCleanController(k8sClient, scheme, &cleanupconfig.CleanupConfig{
+		BatchSize: 2,
+		PodCleanupConfig: cleanupconfig.PodCleanupConfig{
+			Enabled: true,
+			Rules:   []cleanupconfig.PodCleanRule{{Name: "succeeded", Enabled: true, Phase: "Succeeded", TTL: cleanupconfig.Duration{Duration: time.Hour}}},
+		},
+	})
+	client := newTestClient(t, podCleanController)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	runID, err := client.TriggerRun(ctx)
+	if err != nil {
+		t.Fatalf("TriggerRun failed: %v", err)
+	}
+
+	var events []controller.ProgressEvent
+	if err := client.StreamProgress(ctx, runID, func(event controller.ProgressEvent) {
+		events = append(events, event)
+	}); err != nil {
+		t.Fatalf("StreamProgress failed: %v", err)
+	}
+
+	if len(events) == 0 || !events[len(events)-1].Done {
+		t.Fatalf("Expected the stream to end with the completion event, got %+v", events)
+	}
+	if summary := events[len(events)-1].Summary; summary == nil || summary.Matched != 3 {
+		t.Errorf("Expected the completion event to carry the summary, got %+v", summary)
+	}
+	for _, event := range events[:len(events)-1] {
+		if event.Rule != "succeeded" || event.Total != 3 || event.RunID != runID {
+			t.Errorf("Unexpected batch event %+v", event)
+		}
+	}
+
+	runs, err := client.GetHistory(ctx, 1)
+	if err != nil {
+		t.Fatalf("GetHistory failed: %v", err)
+	}
+	if len(runs) != 1 || runs[0].RunID != runID {
+		t.Errorf("Expected the triggered run in the history, got %+v", runs)
+	}
+}
+
+func TestServer_Authorization(t *testing.T) {
+	scheme := runtime.NewScheme()
+	_ = corev1.AddToScheme(scheme)
+	podCleanController := controller.NewPodCleanController(fake.NewClientBuilder().WithScheme(scheme).Build(), scheme,
+		&cleanupconfig.CleanupConfig{})
+
+	authorizer := &auth.Authorizer{Tokens: []string{"secret"}}
+	newServer := func() *grpc.Server {
+		return grpc.NewServer(
+			grpc.ChainUnaryInterceptor(unaryAuthInterceptor(authorizer)),
+			grpc.ChainStreamInterceptor(streamAuthInterceptor(authorizer)))
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	anonymous := newTestClientWithServer(t, newServer(), podCleanController)
+	if _, err := anonymous.GetHistory(ctx, 1); status.Code(err) != codes.Unauthenticated {
+		t.Errorf("Expected GetHistory without a token to fail with Unauthenticated, got %v", err)
+	}
+	if err := anonymous.StreamProgress(ctx, "", func(controller.ProgressEvent) {}); status.Code(err) != codes.Unauthenticated {
+		t.Errorf("Expected StreamProgress without a token to fail with Unauthenticated, got %v", err)
+	}
+
+	authenticated := newTestClientWithServer(t, newServer(), podCleanController,
+		grpc.WithPerRPCCredentials(BearerToken("secret", true)))
+	if _, err := authenticated.GetHistory(ctx, 1); err != nil {
+		t.Errorf("Expected GetHistory with a valid token to succeed, got %v", err)
+	}
+}
@@ -0,0 +1,149 @@
+// Package grpcapi serves kubeclean's gRPC API, which triggers cleanup passes and streams their
+// progress to UIs and chatbots. Messages are JSON-encoded; see CodecName.
+package grpcapi
+
+import (
+	"context"
+	"errors"
+
+	"github.com/infrautils/kubeclean/internal/controller"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// ServiceName is the fully qualified name of the gRPC service.
+const ServiceName = "kubeclean.v1.Kubeclean"
+
+// Full method names of the service.
+const (
+	TriggerRunMethod     = "/" + ServiceName + "/TriggerRun"
+	StreamProgressMethod = "/" + ServiceName + "/StreamProgress"
+	GetHistoryMethod     = "/" + ServiceName + "/GetHistory"
+)
+
+// TriggerRunRequest starts a cleanup pass.
+type TriggerRunRequest struct{}
+
+// TriggerRunResponse identifies the started pass.
+type TriggerRunResponse struct {
+	RunID string `json:"runID"`
+}
+
+// StreamProgressRequest subscribes to progress events.
+type StreamProgressRequest struct {
+	RunID string `json:"runID,omitempty"` // Only stream events of this pass, ending with its completion.
+}
+
+// GetHistoryRequest asks for recent pass summaries.
+type GetHistoryRequest struct {
+	Limit int `json:"limit,omitempty"` // Maximum number of passes to return; 0 returns all kept passes.
+}
+
+// GetHistoryResponse lists recent pass summaries, newest first.
+type GetHistoryResponse struct {
+	Runs []controller.RunSummary `json:"runs"`
+}
+
+// service implements the gRPC methods on top of a PodCleanController.
+type service struct {
+	controller *controller.PodCleanController
+}
+
+func (s *service) triggerRun(ctx context.Context, _ *TriggerRunRequest) (*TriggerRunResponse, error) {
+	runID, err := s.controller.TriggerRun(ctx)
+	if errors.Is(err, controller.ErrRunInProgress) {
+		return nil, status.Error(codes.Unavailable, err.Error())
+	} else if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+	return &TriggerRunResponse{RunID: runID}, nil
+}
+
+func (s *service) streamProgress(req *StreamProgressRequest, stream grpc.ServerStream) error {
+	events, unsubscribe := s.controller.SubscribeProgress()
+	defer unsubscribe()
+
+	// A pass that completed before the subscription started would otherwise never end the stream.
+	if req.RunID != "" {
+		for _, run := range s.controller.History(0) {
+			if run.RunID == req.RunID {
+				return stream.SendMsg(&controller.ProgressEvent{RunID: run.RunID, Done: true, Time: run.Finished, Summary: &run})
+			}
+		}
+	}
+
+	for {
+		select {
+		case <-stream.Context().Done():
+			return nil
+		case event := <-events:
+			if req.RunID != "" && event.RunID != req.RunID {
+				continue
+			}
+			if err := stream.SendMsg(&event); err != nil {
+				return err
+			}
+			if req.RunID != "" && event.Done {
+				return nil
+			}
+		}
+	}
+}
+
+func (s *service) getHistory(_ context.Context, req *GetHistoryRequest) (*GetHistoryResponse, error) {
+	if req.Limit < 0 {
+		return nil, status.Error(codes.InvalidArgument, "limit cannot be negative")
+	}
+	return &GetHistoryResponse{Runs: s.controller.History(req.Limit)}, nil
+}
+
+// serviceDesc describes the service for grpc.Server.RegisterService.
+var serviceDesc = grpc.ServiceDesc{
+	ServiceName: ServiceName,
+	HandlerType: (*any)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "TriggerRun",
+			Handler: func(srv any, ctx context.Context, dec func(any) error, interceptor grpc.UnaryServerInterceptor) (any, error) {
+				return unary(srv.(*service).triggerRun, ctx, dec, interceptor, TriggerRunMethod)
+			},
+		},
+		{
+			MethodName: "GetHistory",
+			Handler: func(srv any, ctx context.Context, dec func(any) error, interceptor grpc.UnaryServerInterceptor) (any, error) {
+				return unary(srv.(*service).getHistory, ctx, dec, interceptor, GetHistoryMethod)
+			},
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "StreamProgress",
+			ServerStreams: true,
+			Handler: func(srv any, stream grpc.ServerStream) error {
+				req := &StreamProgressRequest{}
+				if err := stream.RecvMsg(req); err != nil {
+					return err
+				}
+				return srv.(*service).streamProgress(req, stream)
+			},
+		},
+	},
+}
+
+// unary decodes the request of a unary method and invokes handler through the interceptor, if any.
+func unary[Req, Resp any](handler func(context.Context, *Req) (*Resp, error), ctx context.Context,
+	dec func(any) error, interceptor grpc.UnaryServerInterceptor, method string) (any, error) {
+	req := new(Req)
+	if err := dec(req); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return handler(ctx, req)
+	}
+
+	info := &grpc.UnaryServerInfo{FullMethod: method}
+	return interceptor(ctx, req, info, func(ctx context.Context, req any) (any, error) {
+		return handler(ctx, req.(*Req))
+	})
+}
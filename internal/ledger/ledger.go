@@ -0,0 +1,335 @@
+// Package ledger keeps an append-only record of cleanup runs. Every record carries the hash of
+// the record before it, so that editing, inserting or removing a record breaks the chain and is
+// detected by Verify. Ledger files can be rotated; a rotated file keeps its records, and the next
+// file continues the chain where it ended.
+package ledger
+
+import (
+	"bufio"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+)
+
+// Record describes one cleanup run.
+type Record struct {
+	Seq            int            `json:"seq"` // Position in the ledger, starting at 1.
+	Time           time.Time      `json:"time"`
+	RunID          string         `json:"runID"`
+	Namespace      string         `json:"namespace,omitempty"` // Namespace a targeted run was restricted to.
+	Node           string         `json:"node,omitempty"`      // Node a targeted run was restricted to.
+	DryRun         bool           `json:"dryRun"`
+	ConfigHash     string         `json:"configHash"` // SHA-256 of the config the run used.
+	Matched        int            `json:"matched"`
+	Deleted        map[string]int `json:"deleted,omitempty"` // Pods deleted per rule.
+	DeleteFailures int            `json:"deleteFailures"`
+	PrevHash       string         `json:"prevHash"` // Hash of the previous record; empty for the first.
+	Hash           string         `json:"hash"`     // SHA-256 of this record with Hash unset.
+}
+
+// computeHash returns the hash of r with its Hash field unset.
+func (r Record) computeHash() (string, error) {
+	r.Hash = ""
+	data, err := json.Marshal(r)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// Ledger appends records to ledger files. It remembers the last record of the file it appended
+// to, so that the file is only read when the ledger first appends to it.
+type Ledger struct {
+	mu    sync.Mutex
+	path  string
+	last  Record
+	count int // Records in the file at path, for rotation.
+}
+
+// load reads the ledger file at path unless it was the last one appended to. When the file is
+// missing or empty, the chain continues from the last rotated file.
+func (l *Ledger) load(path string) error {
+	if path == l.path {
+		return nil
+	}
+
+	records, err := readFile(path)
+	if err != nil {
+		return err
+	}
+	last := Record{}
+	if len(records) > 0 {
+		last = records[len(records)-1]
+	} else if rotated, err := RotatedFiles(path); err != nil {
+		return err
+	} else if len(rotated) > 0 {
+		previous, err := readFile(rotated[len(rotated)-1])
+		if err != nil {
+			return err
+		}
+		if len(previous) > 0 {
+			last = previous[len(previous)-1]
+		}
+	}
+
+	l.path, l.last, l.count = path, last, len(records)
+	return nil
+}
+
+// Append chains record to the last record of the ledger file at path, which is created if
+// missing, and appends it. It returns record with its Seq and hashes set. A ledger file that
+// fails verification is not appended to.
+func (l *Ledger) Append(path string, record Record) (Record, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if err := l.load(path); err != nil {
+		return record, err
+	}
+
+	record.Seq = l.last.Seq + 1
+	record.PrevHash = l.last.Hash
+	record.Time = record.Time.UTC()
+	hash, err := record.computeHash()
+	if err != nil {
+		return record, err
+	}
+	record.Hash = hash
+
+	data, err := json.Marshal(record)
+	if err != nil {
+		return record, err
+	}
+
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0600)
+	if err != nil {
+		return record, fmt.Errorf("unable to open ledger %q: %w", path, err)
+	}
+	defer file.Close()
+
+	info, err := file.Stat()
+	if err != nil {
+		return record, fmt.Errorf("unable to stat ledger %q: %w", path, err)
+	}
+	if err := appendLine(file, info.Size(), data); err != nil {
+		if !errors.Is(err, errTruncate) {
+			return record, fmt.Errorf("unable to append to ledger %q: %w", path, err)
+		}
+		// The file may now end in a partial record; read it again before the next append.
+		l.path = ""
+		return record, fmt.Errorf("ledger %q: %w", path, err)
+	}
+
+	l.last = record
+	l.count++
+	return record, nil
+}
+
+// rotatedSuffix matches the suffix Rotate adds to a ledger file's name: the sequence number of
+// its last record, padded so that rotated files sort in ledger order.
+var rotatedSuffix = regexp.MustCompile(`^\.[0-9]{10}$`)
+
+// Rotate renames the ledger file at path once it holds maxRecords records, so that the next
+// append starts a new file continuing the chain. Rotated files beyond the newest keep are
+// removed. A maxRecords of zero never rotates.
+func (l *Ledger) Rotate(path string, maxRecords, keep int) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if maxRecords <= 0 {
+		return nil
+	}
+	if err := l.load(path); err != nil {
+		return err
+	}
+	if l.count < maxRecords {
+		return nil
+	}
+
+	if err := os.Rename(path, fmt.Sprintf("%s.%010d", path, l.last.Seq)); err != nil {
+		return fmt.Errorf("unable to rotate ledger %q: %w", path, err)
+	}
+	l.count = 0
+
+	rotated, err := RotatedFiles(path)
+	if err != nil {
+		return err
+	}
+	var errs []error
+	for _, old := range rotated[:max(0, len(rotated)-keep)] {
+		if err := os.Remove(old); err != nil {
+			errs = append(errs, fmt.Errorf("unable to remove rotated ledger %q: %w", old, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// RotatedFiles returns the files the ledger at path was rotated to, oldest first.
+func RotatedFiles(path string) ([]string, error) {
+	entries, err := os.ReadDir(filepath.Dir(path))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("unable to list rotated ledgers of %q: %w", path, err)
+	}
+
+	var rotated []string
+	prefix := filepath.Base(path)
+	for _, entry := range entries {
+		if suffix, ok := strings.CutPrefix(entry.Name(), prefix); ok && rotatedSuffix.MatchString(suffix) && entry.Type().IsRegular() {
+			rotated = append(rotated, filepath.Join(filepath.Dir(path), entry.Name()))
+		}
+	}
+	slices.Sort(rotated)
+	return rotated, nil
+}
+
+// ReadFiles reads the records of the ledger at path, including the files it was rotated to, and
+// verifies their chain.
+func ReadFiles(path string) ([]Record, error) {
+	files, err := RotatedFiles(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var records []Record
+	for _, file := range append(files, path) {
+		read, err := readFile(file)
+		if err != nil {
+			return nil, err
+		}
+		records = append(records, read...)
+	}
+	return records, Verify(records)
+}
+
+// errTruncate is returned by appendLine when a failed append could not be undone.
+var errTruncate = errors.New("unable to truncate a failed append")
+
+// appendFile is the part of *os.File appendLine uses.
+type appendFile interface {
+	io.Writer
+	Sync() error
+	Truncate(size int64) error
+}
+
+// appendLine appends line and a newline to file, which is offset bytes long, and syncs it. When
+// the write or sync fails, file is truncated back to offset so that it does not end in a partial
+// record.
+func appendLine(file appendFile, offset int64, line []byte) error {
+	_, err := file.Write(append(line, '\n'))
+	if err == nil {
+		err = file.Sync()
+	}
+	if err == nil {
+		return nil
+	}
+	if truncateErr := file.Truncate(offset); truncateErr != nil {
+		return fmt.Errorf("%w: %w (after %w)", errTruncate, truncateErr, err)
+	}
+	return err
+}
+
+// readFile reads and verifies the records of the ledger file at path; none if it is missing.
+func readFile(path string) ([]Record, error) {
+	file, err := os.Open(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("unable to open ledger %q: %w", path, err)
+	}
+	defer file.Close()
+
+	records, err := Read(file)
+	if err != nil {
+		return nil, fmt.Errorf("ledger %q: %w", path, err)
+	}
+	return records, nil
+}
+
+// Read reads the records of a ledger, one JSON object per line or a JSON array of them as written
+// by `kubeclean ledger export`, and verifies their chain.
+func Read(r io.Reader) ([]Record, error) {
+	var records []Record
+
+	buffered := bufio.NewReader(r)
+	if first, err := firstByte(buffered); err == nil && first == '[' {
+		if err := json.NewDecoder(buffered).Decode(&records); err != nil {
+			return nil, fmt.Errorf("invalid records: %w", err)
+		}
+		return records, Verify(records)
+	}
+
+	scanner := bufio.NewScanner(buffered)
+	scanner.Buffer(nil, 1<<20)
+	for line := 1; scanner.Scan(); line++ {
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+		var record Record
+		if err := json.Unmarshal(scanner.Bytes(), &record); err != nil {
+			return nil, fmt.Errorf("line %d: invalid record: %w", line, err)
+		}
+		records = append(records, record)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	return records, Verify(records)
+}
+
+// firstByte returns the first non-whitespace byte of r without consuming it.
+func firstByte(r *bufio.Reader) (byte, error) {
+	for {
+		b, err := r.ReadByte()
+		if err != nil {
+			return 0, err
+		}
+		if !unicode.IsSpace(rune(b)) {
+			return b, r.UnreadByte()
+		}
+	}
+}
+
+// Verify checks that records form an unbroken chain. It reports the first record whose sequence
+// number, link or hash does not match. The chain may start after the first record of a ledger,
+// as in a rotated ledger whose oldest files were removed; records removed from its start are
+// only detected by checking for an anchor hash.
+func Verify(records []Record) error {
+	var prev Record
+	if len(records) > 0 && records[0].Seq > 1 {
+		prev = Record{Seq: records[0].Seq - 1, Hash: records[0].PrevHash}
+	}
+	for _, record := range records {
+		if record.Seq != prev.Seq+1 {
+			return fmt.Errorf("record %d: expected sequence number %d", record.Seq, prev.Seq+1)
+		}
+		if record.PrevHash != prev.Hash {
+			return fmt.Errorf("record %d: previous hash does not match record %d", record.Seq, prev.Seq)
+		}
+		hash, err := record.computeHash()
+		if err != nil {
+			return fmt.Errorf("record %d: %w", record.Seq, err)
+		}
+		if record.Hash != hash {
+			return fmt.Errorf("record %d: hash does not match its content", record.Seq)
+		}
+		prev = record
+	}
+	return nil
+}
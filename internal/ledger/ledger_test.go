@@ -0,0 +1,154 @@
+package ledger
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestLedger_AppendChainsRecords(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "ledger.jsonl")
+	started := time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)
+
+	var ledger Ledger
+	first, err := ledger.Append(path, Record{Time: started, RunID: "1", Matched: 2, Deleted: map[string]int{"succeeded": 2}})
+	require.NoError(t, err)
+	require.Equal(t, 1, first.Seq)
+	require.Empty(t, first.PrevHash)
+	require.NotEmpty(t, first.Hash)
+
+	// A new ledger picks the chain up from the file.
+	second, err := (&Ledger{}).Append(path, Record{Time: started.Add(time.Minute), RunID: "2", DryRun: true})
+	require.NoError(t, err)
+	require.Equal(t, 2, second.Seq)
+	require.Equal(t, first.Hash, second.PrevHash)
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	records, err := Read(bytes.NewReader(data))
+	require.NoError(t, err)
+	require.Equal(t, []Record{first, second}, records)
+
+	exported, err := json.Marshal(records)
+	require.NoError(t, err)
+	records, err = Read(bytes.NewReader(exported))
+	require.NoError(t, err)
+	require.Equal(t, []Record{first, second}, records)
+}
+
+func TestVerify_DetectsTampering(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "ledger.jsonl")
+	var ledger Ledger
+	var records []Record
+	for _, runID := range []string{"1", "2", "3"} {
+		record, err := ledger.Append(path, Record{Time: time.Now(), RunID: runID, Deleted: map[string]int{"failed": 1}})
+		require.NoError(t, err)
+		records = append(records, record)
+	}
+	require.NoError(t, Verify(records))
+
+	edited := append([]Record(nil), records...)
+	edited[1].Deleted = map[string]int{"failed": 0}
+	require.ErrorContains(t, Verify(edited), "record 2: hash does not match its content")
+
+	rehashed := append([]Record(nil), edited...)
+	hash, err := rehashed[1].computeHash()
+	require.NoError(t, err)
+	rehashed[1].Hash = hash
+	require.ErrorContains(t, Verify(rehashed), "record 3: previous hash does not match record 2")
+
+	removed := []Record{records[0], records[2]}
+	require.ErrorContains(t, Verify(removed), "record 3: expected sequence number 2")
+
+	// A ledger may start after its first record once rotated files were removed, but a first
+	// record claiming to start the ledger cannot link to a predecessor.
+	require.NoError(t, Verify(records[1:]))
+	forged := records[1]
+	forged.Seq = 1
+	hash, err = forged.computeHash()
+	require.NoError(t, err)
+	forged.Hash = hash
+	require.ErrorContains(t, Verify([]Record{forged}), "record 1: previous hash does not match record 0")
+}
+
+func TestLedger_RotateContinuesChain(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "ledger.jsonl")
+	var ledger Ledger
+	var records []Record
+	for _, runID := range []string{"1", "2", "3", "4", "5", "6", "7"} {
+		require.NoError(t, ledger.Rotate(path, 2, 2))
+		record, err := ledger.Append(path, Record{Time: time.Now(), RunID: runID})
+		require.NoError(t, err)
+		records = append(records, record)
+	}
+
+	// Records 1-2 were rotated out and removed; 3-4 and 5-6 are kept, and 7 is in the file.
+	rotated, err := RotatedFiles(path)
+	require.NoError(t, err)
+	require.Equal(t, []string{path + ".0000000004", path + ".0000000006"}, rotated)
+
+	read, err := ReadFiles(path)
+	require.NoError(t, err)
+	require.Equal(t, records[2:], read)
+
+	// After a restart right after a rotation, the chain continues from the rotated file.
+	require.NoError(t, (&Ledger{}).Rotate(path, 1, 2))
+	record, err := (&Ledger{}).Append(path, Record{Time: time.Now(), RunID: "8"})
+	require.NoError(t, err)
+	require.Equal(t, 8, record.Seq)
+	require.Equal(t, records[6].Hash, record.PrevHash)
+}
+
+func TestLedger_RefusesBrokenLedger(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "ledger.jsonl")
+	require.NoError(t, os.WriteFile(path, []byte(`{"seq":1,"runID":"1","hash":"forged"}`+"\n"), 0600))
+
+	_, err := (&Ledger{}).Append(path, Record{Time: time.Now(), RunID: "2"})
+	require.ErrorContains(t, err, "record 1: hash does not match its content")
+}
+
+// failingFile writes up to limit bytes, then fails, like a full disk.
+type failingFile struct {
+	bytes.Buffer
+	limit       int
+	truncateErr error
+}
+
+func (f *failingFile) Write(p []byte) (int, error) {
+	if f.Len()+len(p) <= f.limit {
+		return f.Buffer.Write(p)
+	}
+	n, _ := f.Buffer.Write(p[:f.limit-f.Len()])
+	return n, errors.New("no space left on device")
+}
+
+func (*failingFile) Sync() error { return nil }
+
+func (f *failingFile) Truncate(size int64) error {
+	if f.truncateErr != nil {
+		return f.truncateErr
+	}
+	f.Buffer.Truncate(int(size))
+	return nil
+}
+
+func TestAppendLine_TruncatesFailedAppend(t *testing.T) {
+	file := &failingFile{limit: 20}
+	require.NoError(t, appendLine(file, 0, []byte(`{"seq":1}`)))
+
+	err := appendLine(file, int64(file.Len()), []byte(`{"seq":2,"runID":"2"}`))
+	require.ErrorContains(t, err, "no space left on device")
+	require.NotErrorIs(t, err, errTruncate)
+	require.Equal(t, "{\"seq\":1}\n", file.String())
+
+	file.truncateErr = errors.New("read-only file system")
+	err = appendLine(file, int64(file.Len()), []byte(`{"seq":2,"runID":"2"}`))
+	require.ErrorIs(t, err, errTruncate)
+	require.ErrorContains(t, err, "no space left on device")
+}
@@ -0,0 +1,243 @@
+// Package logship captures the logs of pods about to be deleted and pushes them to Loki or
+// Elasticsearch, so they stay searchable once the pods are gone.
+package logship
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	cleanupconfig "github.com/infrautils/kubeclean/internal/cleanup_config"
+	corev1 "k8s.io/api/core/v1"
+	corev1client "k8s.io/client-go/kubernetes/typed/core/v1"
+)
+
+// Entry holds the captured log of one container of a pod.
+type Entry struct {
+	Rule      string
+	Namespace string
+	Pod       string
+	Container string
+	Lines     []Line
+}
+
+// Line is a single log line with the time the container wrote it.
+type Line struct {
+	Time time.Time
+	Text string
+}
+
+// Capture returns the last tailLines lines of every container of pod, init containers included.
+// Containers whose logs cannot be read, e.g. because they never started, are skipped and their
+// errors joined.
+func Capture(ctx context.Context, pods corev1client.PodsGetter, rule string, pod *corev1.Pod, tailLines int64) ([]Entry, error) {
+	var entries []Entry
+	var errs []error
+
+	for _, container := range slices.Concat(pod.Spec.InitContainers, pod.Spec.Containers) {
+		stream, err := pods.Pods(pod.Namespace).GetLogs(pod.Name, &corev1.PodLogOptions{
+			Container:  container.Name,
+			TailLines:  &tailLines,
+			Timestamps: true,
+		}).Stream(ctx)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("container %q: %w", container.Name, err))
+			continue
+		}
+
+		lines, err := readLines(stream)
+		_ = stream.Close()
+		if err != nil {
+			errs = append(errs, fmt.Errorf("container %q: %w", container.Name, err))
+		}
+		if len(lines) > 0 {
+			entries = append(entries, Entry{Rule: rule, Namespace: pod.Namespace, Pod: pod.Name, Container: container.Name, Lines: lines})
+		}
+	}
+
+	return entries, errors.Join(errs...)
+}
+
+// readLines parses log lines prefixed with their RFC 3339 timestamp. Lines without a valid
+// timestamp are kept with the time they were read.
+func readLines(r io.Reader) ([]Line, error) {
+	var lines []Line
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		text := scanner.Text()
+		line := Line{Time: time.Now(), Text: text}
+		if stamp, rest, ok := strings.Cut(text, " "); ok {
+			if t, err := time.Parse(time.RFC3339Nano, stamp); err == nil {
+				line = Line{Time: t, Text: rest}
+			}
+		}
+		lines = append(lines, line)
+	}
+
+	return lines, scanner.Err()
+}
+
+// Backend stores captured logs.
+type Backend interface {
+	Push(ctx context.Context, entries []Entry) error
+}
+
+// NewBackend builds the backend selected by cfg.
+func NewBackend(cfg cleanupconfig.LogForwardingConfig, httpClient *http.Client) (Backend, error) {
+	if httpClient == nil {
+		httpClient = &http.Client{Timeout: 30 * time.Second}
+	}
+
+	switch cfg.Backend {
+	case cleanupconfig.LogBackendLoki:
+		endpoint, err := url.JoinPath(cfg.URL, "loki/api/v1/push")
+		if err != nil {
+			return nil, err
+		}
+		return &lokiBackend{url: endpoint, tenantID: cfg.TenantID, client: httpClient}, nil
+	case cleanupconfig.LogBackendElasticsearch:
+		endpoint, err := url.JoinPath(cfg.URL, "_bulk")
+		if err != nil {
+			return nil, err
+		}
+		return &elasticsearchBackend{url: endpoint, index: cfg.EffectiveIndex(), client: httpClient}, nil
+	default:
+		return nil, fmt.Errorf("unknown log forwarding backend %q", cfg.Backend)
+	}
+}
+
+// lokiBackend pushes every entry as a stream labelled with its rule, namespace, pod and container.
+type lokiBackend struct {
+	url      string
+	tenantID string
+	client   *http.Client
+}
+
+type lokiStream struct {
+	Stream map[string]string `json:"stream"`
+	Values [][2]string       `json:"values"`
+}
+
+func (b *lokiBackend) Push(ctx context.Context, entries []Entry) error {
+	streams := make([]lokiStream, 0, len(entries))
+	for _, entry := range entries {
+		stream := lokiStream{Stream: map[string]string{
+			"source":    "kubeclean",
+			"rule":      entry.Rule,
+			"namespace": entry.Namespace,
+			"pod":       entry.Pod,
+			"container": entry.Container,
+		}}
+		for _, line := range entry.Lines {
+			stream.Values = append(stream.Values, [2]string{strconv.FormatInt(line.Time.UnixNano(), 10), line.Text})
+		}
+		streams = append(streams, stream)
+	}
+
+	body, err := json.Marshal(map[string]interface{}{"streams": streams})
+	if err != nil {
+		return fmt.Errorf("failed to marshal payload: %w", err)
+	}
+
+	header := http.Header{"Content-Type": {"application/json"}}
+	if b.tenantID != "" {
+		header.Set("X-Scope-OrgID", b.tenantID)
+	}
+	_, err = post(ctx, b.client, b.url, header, body)
+	return err
+}
+
+// elasticsearchBackend indexes every line as a document carrying its rule, namespace, pod and container.
+type elasticsearchBackend struct {
+	url    string
+	index  string
+	client *http.Client
+}
+
+type elasticsearchDocument struct {
+	Timestamp time.Time `json:"@timestamp"`
+	Message   string    `json:"message"`
+	Rule      string    `json:"rule"`
+	Namespace string    `json:"namespace"`
+	Pod       string    `json:"pod"`
+	Container string    `json:"container"`
+	Source    string    `json:"source"`
+}
+
+func (b *elasticsearchBackend) Push(ctx context.Context, entries []Entry) error {
+	var body bytes.Buffer
+	encoder := json.NewEncoder(&body)
+	action := map[string]map[string]string{"index": {"_index": b.index}}
+
+	for _, entry := range entries {
+		for _, line := range entry.Lines {
+			if err := encoder.Encode(action); err != nil {
+				return fmt.Errorf("failed to marshal payload: %w", err)
+			}
+			if err := encoder.Encode(elasticsearchDocument{
+				Timestamp: line.Time,
+				Message:   line.Text,
+				Rule:      entry.Rule,
+				Namespace: entry.Namespace,
+				Pod:       entry.Pod,
+				Container: entry.Container,
+				Source:    "kubeclean",
+			}); err != nil {
+				return fmt.Errorf("failed to marshal payload: %w", err)
+			}
+		}
+	}
+	if body.Len() == 0 {
+		return nil
+	}
+
+	resp, err := post(ctx, b.client, b.url, http.Header{"Content-Type": {"application/x-ndjson"}}, body.Bytes())
+	if err != nil {
+		return err
+	}
+
+	// The bulk API answers 200 even when individual documents are rejected.
+	var result struct {
+		Errors bool `json:"errors"`
+	}
+	if err := json.Unmarshal(resp, &result); err == nil && result.Errors {
+		return fmt.Errorf("some log lines were rejected by Elasticsearch")
+	}
+	return nil
+}
+
+// post sends body to endpoint and returns the response body, failing on non-2xx statuses.
+func post(ctx context.Context, client *http.Client, endpoint string, header http.Header, body []byte) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("failed to build request: %w", err)
+	}
+	req.Header = header
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to send request: %w", err)
+	}
+	defer resp.Body.Close() //nolint:errcheck
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response: %w", err)
+	}
+	if resp.StatusCode >= 300 {
+		return nil, fmt.Errorf("unexpected response status %s", resp.Status)
+	}
+	return data, nil
+}
@@ -0,0 +1,118 @@
+package logship
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	cleanupconfig "github.com/infrautils/kubeclean/internal/cleanup_config"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+var testEntries = []Entry{{
+	Rule: "failed-jobs", Namespace: "batch", Pod: "job-abc", Container: "main",
+	Lines: []Line{
+		{Time: time.Unix(1700000000, 5).UTC(), Text: "starting"},
+		{Time: time.Unix(1700000001, 0).UTC(), Text: "exit code 1"},
+	},
+}}
+
+// recordingServer records the requests it receives.
+type recordingServer struct {
+	mu       sync.Mutex
+	requests []*http.Request
+	bodies   []string
+	response string
+}
+
+func (s *recordingServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	body, _ := io.ReadAll(r.Body)
+	s.mu.Lock()
+	s.requests = append(s.requests, r)
+	s.bodies = append(s.bodies, string(body))
+	s.mu.Unlock()
+	_, _ = w.Write([]byte(s.response))
+}
+
+func TestCapture(t *testing.T) {
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "job-abc", Namespace: "batch"},
+		Spec: corev1.PodSpec{
+			InitContainers: []corev1.Container{{Name: "init"}},
+			Containers:     []corev1.Container{{Name: "main"}},
+		},
+	}
+	clientset := fake.NewClientset(pod)
+
+	entries, err := Capture(context.Background(), clientset.CoreV1(), "failed-jobs", pod, 100)
+	require.NoError(t, err)
+	require.Len(t, entries, 2)
+	require.Equal(t, "init", entries[0].Container)
+	require.Equal(t, "main", entries[1].Container)
+	require.Equal(t, "failed-jobs", entries[1].Rule)
+	require.Equal(t, "fake logs", entries[1].Lines[0].Text) // The fake clientset always returns "fake logs".
+}
+
+func TestReadLines(t *testing.T) {
+	lines, err := readLines(strings.NewReader("2024-05-01T10:00:00.123456789Z hello world\nno timestamp\n"))
+	require.NoError(t, err)
+	require.Len(t, lines, 2)
+	require.Equal(t, "hello world", lines[0].Text)
+	require.Equal(t, time.Date(2024, 5, 1, 10, 0, 0, 123456789, time.UTC), lines[0].Time)
+	require.Equal(t, "no timestamp", lines[1].Text)
+}
+
+func TestLokiBackend(t *testing.T) {
+	recorder := &recordingServer{}
+	server := httptest.NewServer(recorder)
+	defer server.Close()
+
+	backend, err := NewBackend(cleanupconfig.LogForwardingConfig{Backend: cleanupconfig.LogBackendLoki, URL: server.URL, TenantID: "ops"}, nil)
+	require.NoError(t, err)
+	require.NoError(t, backend.Push(context.Background(), testEntries))
+
+	require.Len(t, recorder.requests, 1)
+	require.Equal(t, "/loki/api/v1/push", recorder.requests[0].URL.Path)
+	require.Equal(t, "ops", recorder.requests[0].Header.Get("X-Scope-OrgID"))
+
+	var payload struct {
+		Streams []lokiStream `json:"streams"`
+	}
+	require.NoError(t, json.Unmarshal([]byte(recorder.bodies[0]), &payload))
+	require.Len(t, payload.Streams, 1)
+	require.Equal(t, map[string]string{
+		"source": "kubeclean", "rule": "failed-jobs", "namespace": "batch", "pod": "job-abc", "container": "main",
+	}, payload.Streams[0].Stream)
+	require.Equal(t, [][2]string{{"1700000000000000005", "starting"}, {"1700000001000000000", "exit code 1"}}, payload.Streams[0].Values)
+}
+
+func TestElasticsearchBackend(t *testing.T) {
+	recorder := &recordingServer{response: `{"errors": false}`}
+	server := httptest.NewServer(recorder)
+	defer server.Close()
+
+	backend, err := NewBackend(cleanupconfig.LogForwardingConfig{Backend: cleanupconfig.LogBackendElasticsearch, URL: server.URL}, nil)
+	require.NoError(t, err)
+	require.NoError(t, backend.Push(context.Background(), testEntries))
+
+	require.Equal(t, "/_bulk", recorder.requests[0].URL.Path)
+	require.Equal(t, "application/x-ndjson", recorder.requests[0].Header.Get("Content-Type"))
+
+	lines := strings.Split(strings.TrimSpace(recorder.bodies[0]), "\n")
+	require.Len(t, lines, 4)
+	require.JSONEq(t, `{"index": {"_index": "kubeclean-logs"}}`, lines[0])
+	require.JSONEq(t, `{"@timestamp": "2023-11-14T22:13:21Z", "message": "exit code 1", "rule": "failed-jobs",
+		"namespace": "batch", "pod": "job-abc", "container": "main", "source": "kubeclean"}`, lines[3])
+
+	recorder.response = `{"errors": true}`
+	require.Error(t, backend.Push(context.Background(), testEntries))
+}
@@ -0,0 +1,157 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"mime"
+	"net"
+	"net/smtp"
+	"os"
+	"strings"
+	"text/template"
+	"time"
+
+	cleanupconfig "github.com/infrautils/kubeclean/internal/cleanup_config"
+)
+
+// smtpTimeout bounds the delivery of an email when the context has no earlier deadline.
+const smtpTimeout = 30 * time.Second
+
+// emailSink sends the event as a plain-text email through an SMTP relay.
+type emailSink struct {
+	name    string
+	cfg     cleanupconfig.EmailSinkConfig
+	tls     *tls.Config // nil with tls mode none.
+	subject *template.Template
+	body    *template.Template
+}
+
+func newEmailSink(sinkCfg cleanupconfig.NotificationSink) (*emailSink, error) {
+	cfg := *sinkCfg.Email
+	sink := &emailSink{name: sinkCfg.Name, cfg: cfg}
+
+	subject := cfg.Subject
+	if subject == "" {
+		subject = "kubeclean: {{if .Namespace}}{{.Namespace}}{{else}}{{.Rule}}{{end}}"
+	}
+	var err error
+	if sink.subject, err = cleanupconfig.ParseTemplate("subject", subject); err != nil {
+		return nil, fmt.Errorf("invalid subject template: %w", err)
+	}
+	if sink.body, err = parseTemplate(sinkCfg.Template, "{{.Message}}\n"); err != nil {
+		return nil, err
+	}
+
+	if cfg.TLS.TLSMode() != cleanupconfig.SMTPTLSNone {
+		sink.tls = &tls.Config{MinVersion: tls.VersionTLS12, ServerName: cfg.TLS.ServerName}
+		if sink.tls.ServerName == "" {
+			sink.tls.ServerName = cfg.Host
+		}
+		if cfg.TLS.CAFile != "" {
+			caBundle, err := os.ReadFile(cfg.TLS.CAFile)
+			if err != nil {
+				return nil, fmt.Errorf("unable to read CA file: %w", err)
+			}
+			sink.tls.RootCAs = x509.NewCertPool()
+			if !sink.tls.RootCAs.AppendCertsFromPEM(caBundle) {
+				return nil, fmt.Errorf("no certificates found in CA file %q", cfg.TLS.CAFile)
+			}
+		}
+	}
+
+	return sink, nil
+}
+
+func (s *emailSink) Name() string {
+	return s.name
+}
+
+func (s *emailSink) Send(ctx context.Context, event Event) error {
+	message, err := s.message(event, time.Now())
+	if err != nil {
+		return err
+	}
+
+	deadline, ok := ctx.Deadline()
+	if !ok || time.Until(deadline) > smtpTimeout {
+		deadline = time.Now().Add(smtpTimeout)
+	}
+	dialer := &net.Dialer{Deadline: deadline}
+
+	var conn net.Conn
+	if s.cfg.TLS.TLSMode() == cleanupconfig.SMTPTLSImplicit {
+		conn, err = (&tls.Dialer{NetDialer: dialer, Config: s.tls}).DialContext(ctx, "tcp", s.cfg.Address())
+	} else {
+		conn, err = dialer.DialContext(ctx, "tcp", s.cfg.Address())
+	}
+	if err != nil {
+		return fmt.Errorf("failed to connect to SMTP relay: %w", err)
+	}
+	defer conn.Close() //nolint:errcheck
+	if err := conn.SetDeadline(deadline); err != nil {
+		return err
+	}
+
+	client, err := smtp.NewClient(conn, s.cfg.Host)
+	if err != nil {
+		return fmt.Errorf("failed to greet SMTP relay: %w", err)
+	}
+	defer client.Close() //nolint:errcheck
+
+	if s.cfg.TLS.TLSMode() == cleanupconfig.SMTPTLSStartTLS {
+		if err := client.StartTLS(s.tls); err != nil {
+			return fmt.Errorf("failed to start TLS: %w", err)
+		}
+	}
+	if s.cfg.Username != "" {
+		if err := client.Auth(smtp.PlainAuth("", s.cfg.Username, s.cfg.Password, s.cfg.Host)); err != nil {
+			return fmt.Errorf("failed to authenticate: %w", err)
+		}
+	}
+
+	if err := client.Mail(s.cfg.From); err != nil {
+		return fmt.Errorf("sender rejected: %w", err)
+	}
+	for _, to := range s.cfg.To {
+		if err := client.Rcpt(to); err != nil {
+			return fmt.Errorf("recipient %q rejected: %w", to, err)
+		}
+	}
+	w, err := client.Data()
+	if err != nil {
+		return fmt.Errorf("failed to start message: %w", err)
+	}
+	if _, err := w.Write(message); err != nil {
+		return fmt.Errorf("failed to write message: %w", err)
+	}
+	if err := w.Close(); err != nil {
+		return fmt.Errorf("message rejected: %w", err)
+	}
+	return client.Quit()
+}
+
+// message renders the event as an RFC 5322 message sent at now.
+func (s *emailSink) message(event Event, now time.Time) ([]byte, error) {
+	var subject, body bytes.Buffer
+	if err := s.subject.Execute(&subject, event); err != nil {
+		return nil, fmt.Errorf("failed to render subject: %w", err)
+	}
+	if err := s.body.Execute(&body, event); err != nil {
+		return nil, fmt.Errorf("failed to render body: %w", err)
+	}
+
+	var message bytes.Buffer
+	fmt.Fprintf(&message, "From: %s\r\n", s.cfg.From)
+	fmt.Fprintf(&message, "To: %s\r\n", strings.Join(s.cfg.To, ", "))
+	// Line breaks in the subject would inject headers.
+	fmt.Fprintf(&message, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", strings.Join(strings.Fields(subject.String()), " ")))
+	fmt.Fprintf(&message, "Date: %s\r\n", now.Format(time.RFC1123Z))
+	message.WriteString("MIME-Version: 1.0\r\n")
+	message.WriteString("Content-Type: text/plain; charset=utf-8\r\n")
+	message.WriteString("Content-Transfer-Encoding: 8bit\r\n\r\n")
+	message.WriteString(strings.ReplaceAll(strings.ReplaceAll(body.String(), "\r\n", "\n"), "\n", "\r\n"))
+	return message.Bytes(), nil
+}
@@ -0,0 +1,64 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/template"
+	"time"
+)
+
+// fileSink writes every event as a file into a directory, from which another process ships it,
+// e.g. across an air gap. Files appear atomically, so a reader never sees a partial event.
+type fileSink struct {
+	name      string
+	directory string
+	template  *template.Template // nil writes the event as JSON.
+}
+
+func (s *fileSink) Name() string {
+	return s.name
+}
+
+func (s *fileSink) Send(_ context.Context, event Event) error {
+	content, ext := []byte(nil), ".json"
+	if s.template != nil {
+		var rendered bytes.Buffer
+		if err := s.template.Execute(&rendered, event); err != nil {
+			return fmt.Errorf("failed to render template: %w", err)
+		}
+		content, ext = rendered.Bytes(), ".txt"
+	} else {
+		var err error
+		if content, err = json.Marshal(event); err != nil {
+			return fmt.Errorf("failed to marshal event: %w", err)
+		}
+		content = append(content, '\n')
+	}
+
+	prefix := time.Now().UTC().Format("20060102T150405.000000000Z") + "-"
+	file, err := os.CreateTemp(s.directory, "."+prefix+"*")
+	if err != nil {
+		return fmt.Errorf("failed to create event file: %w", err)
+	}
+	tmpPath := file.Name()
+	defer os.Remove(tmpPath) //nolint:errcheck // Fails once renamed.
+
+	if _, err := file.Write(content); err != nil {
+		file.Close() //nolint:errcheck
+		return fmt.Errorf("failed to write event file: %w", err)
+	}
+	if err := file.Close(); err != nil {
+		return fmt.Errorf("failed to write event file: %w", err)
+	}
+
+	path := filepath.Join(s.directory, strings.TrimPrefix(filepath.Base(tmpPath), ".")+ext)
+	if err := os.Rename(tmpPath, path); err != nil {
+		return fmt.Errorf("failed to publish event file: %w", err)
+	}
+	return nil
+}
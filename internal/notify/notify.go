@@ -0,0 +1,196 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"text/template"
+	"time"
+
+	cleanupconfig "github.com/infrautils/kubeclean/internal/cleanup_config"
+)
+
+// Event describes the outcome of a cleanup rule that is reported to notification sinks.
+type Event struct {
+	RunID   string `json:"runID"`
+	Rule    string `json:"rule"`
+	Pods    int    `json:"pods"`
+	DryRun  bool   `json:"dryRun"`
+	Message string `json:"message"`
+
+	Kind      string `json:"kind,omitempty"`      // Resource kind for rules that clean up something other than pods.
+	Resources int    `json:"resources,omitempty"` // Number of Kind resources processed.
+	Action    string `json:"action,omitempty"`    // Action taken, e.g. "scaleToZero"; empty for deletions.
+
+	Failed int            `json:"failed,omitempty"` // Pods or resources the action failed on; not counted in Pods or Resources.
+	Owners map[string]int `json:"owners,omitempty"` // Processed pods per top-level owner, e.g. "CronJob default/nightly".
+
+	Namespace string         `json:"namespace,omitempty"` // Namespace of an aggregated notification to its owner.
+	Rules     map[string]int `json:"rules,omitempty"`     // Deleted pods per rule of an aggregated notification.
+
+	// Summary is the summary of the run so far, a controller.RunSummary, for templates to read
+	// fields such as .Summary.Matched from. It is not part of the default payload.
+	Summary any `json:"-"`
+}
+
+// Sink delivers events to a single destination.
+type Sink interface {
+	Name() string
+	Send(ctx context.Context, event Event) error
+}
+
+// Notifier routes events to the configured sinks.
+type Notifier struct {
+	sinks []Sink
+}
+
+// NewNotifier builds a Notifier from the notification config.
+func NewNotifier(cfg cleanupconfig.NotificationConfig, httpClient *http.Client) (*Notifier, error) {
+	if httpClient == nil {
+		httpClient = &http.Client{Timeout: 10 * time.Second}
+	}
+
+	notifier := &Notifier{}
+	for _, sinkCfg := range cfg.Sinks {
+		var tmpl *template.Template
+		if sinkCfg.Type != cleanupconfig.SinkTypeEmail {
+			var err error
+			if tmpl, err = parseTemplate(sinkCfg.Template, ""); err != nil {
+				return nil, fmt.Errorf("sink %q: %w", sinkCfg.Name, err)
+			}
+		}
+
+		switch sinkCfg.Type {
+		case cleanupconfig.SinkTypeWebhook:
+			notifier.sinks = append(notifier.sinks, &webhookSink{name: sinkCfg.Name, url: sinkCfg.URL, client: httpClient, template: tmpl})
+		case cleanupconfig.SinkTypeSlack:
+			notifier.sinks = append(notifier.sinks, &slackSink{webhookSink{name: sinkCfg.Name, url: sinkCfg.URL, client: httpClient, template: tmpl}})
+		case cleanupconfig.SinkTypeEmail:
+			sink, err := newEmailSink(sinkCfg)
+			if err != nil {
+				return nil, fmt.Errorf("sink %q: %w", sinkCfg.Name, err)
+			}
+			notifier.sinks = append(notifier.sinks, sink)
+		case cleanupconfig.SinkTypeFile:
+			notifier.sinks = append(notifier.sinks, &fileSink{name: sinkCfg.Name, directory: sinkCfg.File.Directory, template: tmpl})
+		default:
+			return nil, fmt.Errorf("unknown sink type %q for sink %q", sinkCfg.Type, sinkCfg.Name)
+		}
+	}
+
+	return notifier, nil
+}
+
+// parseTemplate parses the template of a sink, or fallback when it has none. It returns nil
+// when both are empty.
+func parseTemplate(text, fallback string) (*template.Template, error) {
+	if text == "" {
+		text = fallback
+	}
+	if text == "" {
+		return nil, nil
+	}
+	tmpl, err := cleanupconfig.ParseTemplate("template", text)
+	if err != nil {
+		return nil, fmt.Errorf("invalid template: %w", err)
+	}
+	return tmpl, nil
+}
+
+// Notify sends the event to the named sinks, or to every sink when names is empty.
+// Delivery continues past failing sinks; their errors are joined.
+func (n *Notifier) Notify(ctx context.Context, names []string, event Event) error {
+	var errs []error
+
+	for _, sink := range n.sinks {
+		if len(names) > 0 && !slices.Contains(names, sink.Name()) {
+			continue
+		}
+
+		if err := sink.Send(ctx, event); err != nil {
+			errs = append(errs, fmt.Errorf("sink %q: %w", sink.Name(), err))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// webhookSink POSTs the event as JSON, or the JSON its template renders.
+type webhookSink struct {
+	name     string
+	url      string
+	client   *http.Client
+	template *template.Template
+}
+
+func (s *webhookSink) Name() string {
+	return s.name
+}
+
+func (s *webhookSink) Send(ctx context.Context, event Event) error {
+	if s.template != nil {
+		return s.postTemplate(ctx, event)
+	}
+	return s.post(ctx, event)
+}
+
+func (s *webhookSink) post(ctx context.Context, payload interface{}) error {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("failed to marshal payload: %w", err)
+	}
+	return s.postBody(ctx, body)
+}
+
+// postTemplate POSTs the payload the sink's template renders for event.
+func (s *webhookSink) postTemplate(ctx context.Context, event Event) error {
+	var body bytes.Buffer
+	if err := s.template.Execute(&body, event); err != nil {
+		return fmt.Errorf("failed to render template: %w", err)
+	}
+	if !json.Valid(body.Bytes()) {
+		return fmt.Errorf("template rendered invalid JSON: %s", body.String())
+	}
+	return s.postBody(ctx, body.Bytes())
+}
+
+func (s *webhookSink) postBody(ctx context.Context, body []byte) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.url, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to build request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send request: %w", err)
+	}
+	defer resp.Body.Close() //nolint:errcheck
+
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("unexpected response status %s", resp.Status)
+	}
+
+	return nil
+}
+
+// slackSink POSTs the event message, followed by the run ID, as a Slack incoming-webhook payload,
+// or the payload its template renders, e.g. with blocks.
+type slackSink struct {
+	webhookSink
+}
+
+func (s *slackSink) Send(ctx context.Context, event Event) error {
+	if s.template != nil {
+		return s.postTemplate(ctx, event)
+	}
+	text := event.Message
+	if event.RunID != "" {
+		text += fmt.Sprintf(" (run %s)", event.RunID)
+	}
+	return s.post(ctx, map[string]string{"text": text})
+}
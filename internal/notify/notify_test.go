@@ -0,0 +1,262 @@
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/textproto"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+
+	cleanupconfig "github.com/infrautils/kubeclean/internal/cleanup_config"
+	"github.com/stretchr/testify/require"
+)
+
+// recordingServer captures the JSON payloads posted to it.
+type recordingServer struct {
+	*httptest.Server
+	mu       sync.Mutex
+	payloads []map[string]interface{}
+}
+
+func newRecordingServer(t *testing.T, status int) *recordingServer {
+	t.Helper()
+
+	rs := &recordingServer{}
+	rs.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var payload map[string]interface{}
+		_ = json.NewDecoder(r.Body).Decode(&payload)
+
+		rs.mu.Lock()
+		rs.payloads = append(rs.payloads, payload)
+		rs.mu.Unlock()
+
+		w.WriteHeader(status)
+	}))
+	t.Cleanup(rs.Close)
+
+	return rs
+}
+
+func (rs *recordingServer) received() []map[string]interface{} {
+	rs.mu.Lock()
+	defer rs.mu.Unlock()
+	return rs.payloads
+}
+
+func TestNotifier_RoutesToNamedSinksOrAll(t *testing.T) {
+	storage := newRecordingServer(t, http.StatusOK)
+	chat := newRecordingServer(t, http.StatusOK)
+
+	notifier, err := NewNotifier(cleanupconfig.NotificationConfig{
+		Sinks: []cleanupconfig.NotificationSink{
+			{Name: "storage-oncall", Type: cleanupconfig.SinkTypeWebhook, URL: storage.URL},
+			{Name: "chat", Type: cleanupconfig.SinkTypeSlack, URL: chat.URL},
+		},
+	}, nil)
+	require.NoError(t, err)
+
+	event := Event{Rule: "pvc-rule", Pods: 3, Message: "Deleted 3 pod(s) for rule pvc-rule"}
+
+	require.NoError(t, notifier.Notify(context.Background(), []string{"storage-oncall"}, event))
+	require.Len(t, storage.received(), 1)
+	require.Empty(t, chat.received())
+	require.Equal(t, "pvc-rule", storage.received()[0]["rule"])
+
+	require.NoError(t, notifier.Notify(context.Background(), nil, event))
+	require.Len(t, storage.received(), 2)
+	require.Len(t, chat.received(), 1)
+	require.Equal(t, event.Message, chat.received()[0]["text"])
+
+	// The run ID lets readers trace the message to the run's logs and Events.
+	event.RunID = "7"
+	require.NoError(t, notifier.Notify(context.Background(), []string{"chat"}, event))
+	require.Equal(t, "Deleted 3 pod(s) for rule pvc-rule (run 7)", chat.received()[1]["text"])
+}
+
+func TestNotifier_ReportsFailingSinks(t *testing.T) {
+	failing := newRecordingServer(t, http.StatusInternalServerError)
+	healthy := newRecordingServer(t, http.StatusOK)
+
+	notifier, err := NewNotifier(cleanupconfig.NotificationConfig{
+		Sinks: []cleanupconfig.NotificationSink{
+			{Name: "failing", Type: cleanupconfig.SinkTypeWebhook, URL: failing.URL},
+			{Name: "healthy", Type: cleanupconfig.SinkTypeWebhook, URL: healthy.URL},
+		},
+	}, nil)
+	require.NoError(t, err)
+
+	err = notifier.Notify(context.Background(), nil, Event{Rule: "r"})
+	require.Error(t, err)
+	require.Contains(t, err.Error(), `sink "failing"`)
+	require.Len(t, healthy.received(), 1, "healthy sinks should still be notified")
+}
+
+func TestFileSink_WritesEventFiles(t *testing.T) {
+	jsonDir, textDir := t.TempDir(), t.TempDir()
+	notifier, err := NewNotifier(cleanupconfig.NotificationConfig{
+		Sinks: []cleanupconfig.NotificationSink{
+			{Name: "drop", Type: cleanupconfig.SinkTypeFile, File: &cleanupconfig.FileSinkConfig{Directory: jsonDir}},
+			{
+				Name: "text", Type: cleanupconfig.SinkTypeFile, File: &cleanupconfig.FileSinkConfig{Directory: textDir},
+				Template: "{{.Rule}}: {{.Pods}} pod(s)\n",
+			},
+		},
+	}, nil)
+	require.NoError(t, err)
+
+	event := Event{RunID: "7", Rule: "failed", Pods: 2, Message: "Deleted 2 pod(s) for rule failed"}
+	require.NoError(t, notifier.Notify(context.Background(), nil, event))
+	require.NoError(t, notifier.Notify(context.Background(), nil, event))
+
+	files, err := filepath.Glob(filepath.Join(jsonDir, "*"))
+	require.NoError(t, err)
+	require.Len(t, files, 2, "every event should get its own file and no temporary file should be left")
+	require.Equal(t, ".json", filepath.Ext(files[0]))
+	data, err := os.ReadFile(files[0])
+	require.NoError(t, err)
+	var written Event
+	require.NoError(t, json.Unmarshal(data, &written))
+	require.Equal(t, event, written)
+
+	files, err = filepath.Glob(filepath.Join(textDir, "*.txt"))
+	require.NoError(t, err)
+	require.Len(t, files, 2)
+	data, err = os.ReadFile(files[0])
+	require.NoError(t, err)
+	require.Equal(t, "failed: 2 pod(s)\n", string(data))
+}
+
+// smtpServer is a minimal plaintext SMTP relay recording the messages it accepts.
+type smtpServer struct {
+	net.Listener
+	mu       sync.Mutex
+	rcpts    []string
+	messages []string
+}
+
+func newSMTPServer(t *testing.T) *smtpServer {
+	t.Helper()
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	server := &smtpServer{Listener: listener}
+	t.Cleanup(func() { listener.Close() })
+
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go server.serve(conn)
+		}
+	}()
+	return server
+}
+
+func (s *smtpServer) serve(conn net.Conn) {
+	defer conn.Close()
+	text := textproto.NewConn(conn)
+	_ = text.PrintfLine("220 localhost ESMTP")
+	for {
+		line, err := text.ReadLine()
+		if err != nil {
+			return
+		}
+		command := strings.ToUpper(strings.Fields(line + " ")[0])
+		switch command {
+		case "EHLO", "HELO":
+			_ = text.PrintfLine("250 localhost")
+		case "RCPT":
+			s.mu.Lock()
+			s.rcpts = append(s.rcpts, line)
+			s.mu.Unlock()
+			_ = text.PrintfLine("250 OK")
+		case "DATA":
+			_ = text.PrintfLine("354 Go ahead")
+			data, err := text.ReadDotBytes()
+			if err != nil {
+				return
+			}
+			s.mu.Lock()
+			s.messages = append(s.messages, string(data))
+			s.mu.Unlock()
+			_ = text.PrintfLine("250 OK")
+		case "QUIT":
+			_ = text.PrintfLine("221 Bye")
+			return
+		default:
+			_ = text.PrintfLine("250 OK")
+		}
+	}
+}
+
+func TestEmailSink_SendsTemplatedMessage(t *testing.T) {
+	server := newSMTPServer(t)
+	_, port, err := net.SplitHostPort(server.Addr().String())
+	require.NoError(t, err)
+	portNumber, err := strconv.Atoi(port)
+	require.NoError(t, err)
+
+	notifier, err := NewNotifier(cleanupconfig.NotificationConfig{
+		Sinks: []cleanupconfig.NotificationSink{{
+			Name: "ops-mail", Type: cleanupconfig.SinkTypeEmail,
+			Email: &cleanupconfig.EmailSinkConfig{
+				Host: "127.0.0.1", Port: portNumber, From: "kubeclean@example.com",
+				To:      []string{"ops@example.com", "audit@example.com"},
+				Subject: "[kubeclean] {{.Rule}}\nBcc: injected@example.com",
+				TLS:     cleanupconfig.SMTPTLSConfig{Mode: cleanupconfig.SMTPTLSNone},
+			},
+			Template: "Run {{.RunID}} deleted {{.Pods}} pod(s).\n",
+		}},
+	}, nil)
+	require.NoError(t, err)
+
+	require.NoError(t, notifier.Notify(context.Background(), nil, Event{RunID: "7", Rule: "failed", Pods: 2}))
+
+	server.mu.Lock()
+	defer server.mu.Unlock()
+	require.Len(t, server.rcpts, 2)
+	require.Len(t, server.messages, 1)
+	message := server.messages[0]
+	require.Contains(t, message, "To: ops@example.com, audit@example.com\n")
+	require.Contains(t, message, "Subject: [kubeclean] failed Bcc: injected@example.com\n")
+	require.NotContains(t, message, "\nBcc:")
+	require.True(t, strings.HasSuffix(message, "\n\nRun 7 deleted 2 pod(s).\n"), "unexpected message %q", message)
+}
+
+func TestNotifier_RendersPayloadTemplates(t *testing.T) {
+	chat := newRecordingServer(t, http.StatusOK)
+	broken := newRecordingServer(t, http.StatusOK)
+
+	notifier, err := NewNotifier(cleanupconfig.NotificationConfig{
+		Sinks: []cleanupconfig.NotificationSink{
+			{
+				Name: "chat", Type: cleanupconfig.SinkTypeSlack, URL: chat.URL,
+				Template: `{"blocks": [{"type": "section", "text": {"type": "mrkdwn", "text": {{toJson (printf "*%s* %d/%d (%s)" (upper .Rule) .Pods .Summary.Matched (join ", " (keys .Owners)))}}}}]}`,
+			},
+			{Name: "broken", Type: cleanupconfig.SinkTypeWebhook, URL: broken.URL, Template: `{"text": {{.Message}}}`},
+		},
+	}, nil)
+	require.NoError(t, err)
+
+	event := Event{
+		Rule: "failed", Pods: 3, Message: "Deleted 3 pod(s)",
+		Owners:  map[string]int{"Job default/b": 1, "Job default/a": 2},
+		Summary: struct{ Matched int }{Matched: 5},
+	}
+	err = notifier.Notify(context.Background(), nil, event)
+	require.ErrorContains(t, err, `sink "broken": template rendered invalid JSON`)
+
+	require.Empty(t, broken.received())
+	require.Len(t, chat.received(), 1)
+	block := chat.received()[0]["blocks"].([]interface{})[0].(map[string]interface{})
+	require.Equal(t, "*FAILED* 3/5 (Job default/a, Job default/b)", block["text"].(map[string]interface{})["text"])
+}
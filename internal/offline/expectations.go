@@ -0,0 +1,83 @@
+package offline
+
+import (
+	"fmt"
+	"os"
+	"slices"
+	"sort"
+
+	"github.com/infrautils/kubeclean/internal/controller"
+	"sigs.k8s.io/yaml"
+)
+
+// Expectations lists the pods each rule is expected to match, as "namespace/name". Rules that
+// are not listed are expected to match no pod.
+type Expectations struct {
+	Matches map[string][]string `json:"matches"`
+}
+
+// LoadExpectations reads Expectations from a YAML file.
+func LoadExpectations(path string) (Expectations, error) {
+	var expectations Expectations
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return expectations, fmt.Errorf("unable to read expectations %q: %w", path, err)
+	}
+	if err := yaml.UnmarshalStrict(data, &expectations); err != nil {
+		return expectations, fmt.Errorf("invalid expectations %q: %w", path, err)
+	}
+	return expectations, nil
+}
+
+// RuleResult compares the pods a rule matched with those it was expected to match.
+type RuleResult struct {
+	Rule       string   `json:"rule"`
+	Passed     bool     `json:"passed"`
+	Unexpected []string `json:"unexpected,omitempty"` // Matched but not expected.
+	Missing    []string `json:"missing,omitempty"`    // Expected but not matched.
+}
+
+// Check compares the matched pods with the expectations and returns a result per rule that
+// matched a pod or has expectations, sorted by rule name.
+func Check(expectations Expectations, matched []controller.PodRef) []RuleResult {
+	actual := map[string][]string{}
+	for _, ref := range matched {
+		actual[ref.Rule] = append(actual[ref.Rule], ref.Namespace+"/"+ref.Name)
+	}
+
+	rules := map[string]struct{}{}
+	for rule := range actual {
+		rules[rule] = struct{}{}
+	}
+	for rule := range expectations.Matches {
+		rules[rule] = struct{}{}
+	}
+
+	results := make([]RuleResult, 0, len(rules))
+	for rule := range rules {
+		expected := expectations.Matches[rule]
+		result := RuleResult{
+			Rule:       rule,
+			Unexpected: difference(actual[rule], expected),
+			Missing:    difference(expected, actual[rule]),
+		}
+		result.Passed = len(result.Unexpected) == 0 && len(result.Missing) == 0
+		results = append(results, result)
+	}
+
+	sort.Slice(results, func(i, j int) bool { return results[i].Rule < results[j].Rule })
+	return results
+}
+
+// difference returns the sorted values of a that are not in b.
+func difference(a, b []string) []string {
+	var diff []string
+	for _, value := range a {
+		if !slices.Contains(b, value) {
+			diff = append(diff, value)
+		}
+	}
+	slices.Sort(diff)
+	return diff
+}
@@ -0,0 +1,132 @@
+// Package offline evaluates cleanup rules against Kubernetes objects read from files instead of
+// a live cluster, so rule changes can be tested in CI.
+package offline
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	utilyaml "k8s.io/apimachinery/pkg/util/yaml"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+// ExpectationsFile is the file in a fixture directory holding the expected matches; it is not
+// read as a fixture.
+const ExpectationsFile = "expectations.yaml"
+
+// LoadObjects reads every Kubernetes object in the YAML and JSON files under path, which may also
+// be a single file such as a snapshot. A file may hold several documents, and List documents are
+// expanded into their items. Objects must be of a kind registered in scheme.
+func LoadObjects(path string, scheme *runtime.Scheme) ([]client.Object, error) {
+	var objects []client.Object
+
+	if info, err := os.Stat(path); err == nil && !info.IsDir() {
+		return loadFile(path, scheme)
+	}
+
+	err := filepath.WalkDir(path, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if entry.IsDir() || entry.Name() == ExpectationsFile {
+			return nil
+		}
+		switch filepath.Ext(path) {
+		case ".yaml", ".yml", ".json":
+		default:
+			return nil
+		}
+
+		decoded, err := loadFile(path, scheme)
+		objects = append(objects, decoded...)
+		return err
+	})
+
+	return objects, err
+}
+
+// loadFile decodes the objects of a single file.
+func loadFile(path string, scheme *runtime.Scheme) ([]client.Object, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	objects, err := DecodeObjects(file, scheme)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return objects, nil
+}
+
+// DecodeObjects decodes the YAML or JSON documents of r into typed objects.
+func DecodeObjects(r io.Reader, scheme *runtime.Scheme) ([]client.Object, error) {
+	decoder := utilyaml.NewYAMLOrJSONDecoder(r, 4096)
+
+	var objects []client.Object
+	for {
+		var doc unstructured.Unstructured
+		if err := decoder.Decode(&doc.Object); err != nil {
+			if errors.Is(err, io.EOF) {
+				return objects, nil
+			}
+			return nil, err
+		}
+		if len(doc.Object) == 0 {
+			continue // Empty document, e.g. a trailing "---".
+		}
+
+		items := []unstructured.Unstructured{doc}
+		if doc.IsList() {
+			list, err := doc.ToList()
+			if err != nil {
+				return nil, err
+			}
+			items = list.Items
+		}
+
+		for i := range items {
+			obj, err := toTyped(&items[i], scheme)
+			if err != nil {
+				return nil, err
+			}
+			objects = append(objects, obj)
+		}
+	}
+}
+
+// toTyped converts u into the typed object registered for its kind.
+func toTyped(u *unstructured.Unstructured, scheme *runtime.Scheme) (client.Object, error) {
+	gvk := u.GroupVersionKind()
+	if gvk.Kind == "" {
+		return nil, fmt.Errorf("object %q has no kind", u.GetName())
+	}
+
+	typed, err := scheme.New(gvk)
+	if err != nil {
+		return nil, fmt.Errorf("unsupported kind %s: %w", strings.TrimPrefix(gvk.String(), "/, Kind="), err)
+	}
+	if err := runtime.DefaultUnstructuredConverter.FromUnstructured(u.Object, typed); err != nil {
+		return nil, fmt.Errorf("%s %s: %w", gvk.Kind, u.GetName(), err)
+	}
+
+	obj, ok := typed.(client.Object)
+	if !ok {
+		return nil, fmt.Errorf("kind %s is not an object", gvk.Kind)
+	}
+	return obj, nil
+}
+
+// NewClient returns a client serving objects from memory. Writes only change the in-memory copy.
+func NewClient(scheme *runtime.Scheme, objects []client.Object) client.Client {
+	return fake.NewClientBuilder().WithScheme(scheme).WithObjects(objects...).Build()
+}
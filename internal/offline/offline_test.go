@@ -0,0 +1,223 @@
+package offline
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	kubecleanv1alpha1 "github.com/infrautils/kubeclean/api/v1alpha1"
+	cleanupconfig "github.com/infrautils/kubeclean/internal/cleanup_config"
+	"github.com/infrautils/kubeclean/internal/controller"
+	appsv1 "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const podFixtures = `
+apiVersion: v1
+kind: Pod
+metadata:
+  name: done
+  namespace: default
+  creationTimestamp: "2024-01-01T00:00:00Z"
+status:
+  phase: Succeeded
+---
+apiVersion: v1
+kind: List
+items:
+- apiVersion: v1
+  kind: Pod
+  metadata:
+    name: running
+    namespace: default
+    creationTimestamp: "2024-01-01T00:00:00Z"
+  status:
+    phase: Running
+- apiVersion: v1
+  kind: Namespace
+  metadata:
+    name: default
+---
+`
+
+func TestLoadObjectsAndCheck(t *testing.T) {
+	scheme := runtime.NewScheme()
+	_ = clientgoscheme.AddToScheme(scheme)
+
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "pods.yaml"), podFixtures)
+	writeFile(t, filepath.Join(dir, "README.md"), "not a fixture")
+	writeFile(t, filepath.Join(dir, ExpectationsFile), "matches:\n  succeeded:\n  - default/done\n  failed:\n  - default/crashed\n")
+
+	objects, err := LoadObjects(dir, scheme)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(objects) != 3 {
+		t.Fatalf("Expected 3 objects, got %d", len(objects))
+	}
+
+	expectations, err := LoadExpectations(filepath.Join(dir, ExpectationsFile))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	cfg := &cleanupconfig.CleanupConfig{PodCleanupConfig: cleanupconfig.PodCleanupConfig{
+		Enabled: true,
+		Rules: []cleanupconfig.PodCleanRule{
+			{Name: "succeeded", Enabled: true, Phase: "Succeeded", TTL: cleanupconfig.Duration{Duration: 1}},
+			{Name: "failed", Enabled: true, Phase: "Failed", TTL: cleanupconfig.Duration{Duration: 1}},
+			{Name: "running", Enabled: true, Phase: "Running", TTL: cleanupconfig.Duration{Duration: 1}},
+		},
+	}}
+
+	matched := controller.NewPodCleanController(NewClient(scheme, objects), scheme, cfg).Preview(context.Background())
+	results := Check(expectations, matched)
+
+	expected := []RuleResult{
+		{Rule: "failed", Missing: []string{"default/crashed"}},
+		{Rule: "running", Unexpected: []string{"default/running"}},
+		{Rule: "succeeded", Passed: true},
+	}
+	if len(results) != len(expected) {
+		t.Fatalf("Expected %d results, got %+v", len(expected), results)
+	}
+	for i, result := range results {
+		want := expected[i]
+		if result.Rule != want.Rule || result.Passed != want.Passed ||
+			strings.Join(result.Missing, ",") != strings.Join(want.Missing, ",") ||
+			strings.Join(result.Unexpected, ",") != strings.Join(want.Unexpected, ",") {
+			t.Errorf("Expected result %+v, got %+v", want, result)
+		}
+	}
+}
+
+func TestDecodeObjects_UnsupportedKind(t *testing.T) {
+	scheme := runtime.NewScheme()
+	_ = clientgoscheme.AddToScheme(scheme)
+
+	_, err := DecodeObjects(strings.NewReader("apiVersion: example.com/v1\nkind: Widget\nmetadata:\n  name: w\n"), scheme)
+	if err == nil {
+		t.Fatal("Expected an error for a kind missing from the scheme")
+	}
+}
+
+func writeFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatalf("Failed to write %s: %v", path, err)
+	}
+}
+
+func TestExport_RoundTrip(t *testing.T) {
+	scheme := runtime.NewScheme()
+	_ = clientgoscheme.AddToScheme(scheme)
+	_ = kubecleanv1alpha1.AddToScheme(scheme)
+
+	isController := true
+	source := NewClient(scheme, []client.Object{
+		&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "default"}},
+		&batchv1.Job{
+			ObjectMeta: metav1.ObjectMeta{Name: "nightly", Namespace: "default", Annotations: map[string]string{"kubeclean/disabled": "true"}},
+			Spec:       batchv1.JobSpec{Template: corev1.PodTemplateSpec{Spec: corev1.PodSpec{Containers: []corev1.Container{{Name: "job", Image: "busybox"}}}}},
+		},
+		&corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:            "nightly-abc",
+				Namespace:       "default",
+				OwnerReferences: []metav1.OwnerReference{{Kind: "Job", Name: "nightly", Controller: &isController}},
+				Annotations:     map[string]string{"kubectl.kubernetes.io/last-applied-configuration": "{}"},
+			},
+			Spec: corev1.PodSpec{
+				NodeName:   "node-a",
+				Containers: []corev1.Container{{Name: "job", Image: "busybox", Env: []corev1.EnvVar{{Name: "TOKEN", Value: "secret"}}}},
+			},
+			Status: corev1.PodStatus{Phase: corev1.PodSucceeded, Conditions: []corev1.PodCondition{{Type: corev1.PodReady, Status: corev1.ConditionFalse}}},
+		},
+		&corev1.Node{
+			ObjectMeta: metav1.ObjectMeta{Name: "node-a"},
+			Status: corev1.NodeStatus{
+				Conditions: []corev1.NodeCondition{{Type: corev1.NodeDiskPressure, Status: corev1.ConditionTrue}},
+				Images:     []corev1.ContainerImage{{Names: []string{"busybox"}}},
+			},
+		},
+		&appsv1.Deployment{
+			ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "default"},
+			Spec:       appsv1.DeploymentSpec{Template: corev1.PodTemplateSpec{Spec: corev1.PodSpec{Containers: []corev1.Container{{Name: "web", Env: []corev1.EnvVar{{Name: "TOKEN", Value: "secret"}}}}}}},
+			Status:     appsv1.DeploymentStatus{ReadyReplicas: 3},
+		},
+	})
+
+	var snapshot bytes.Buffer
+	before := time.Now().Truncate(time.Second)
+	if err := Export(context.Background(), source, scheme, &snapshot); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	dir := t.TempDir()
+	path := filepath.Join(dir, "snapshot.yaml")
+	if err := os.WriteFile(path, snapshot.Bytes(), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if exportedAt, err := ExportedAt(path); err != nil || exportedAt.Before(before) || exportedAt.After(time.Now()) {
+		t.Errorf("Expected the snapshot to record when it was exported, got %v, %v", exportedAt, err)
+	}
+	if exportedAt, err := ExportedAt(dir); err != nil || !exportedAt.IsZero() {
+		t.Errorf("Expected a fixture directory to have no export time, got %v, %v", exportedAt, err)
+	}
+	if strings.Contains(snapshot.String(), "secret") || strings.Contains(snapshot.String(), "last-applied") {
+		t.Errorf("Expected container specs and last-applied annotations to be dropped, got:\n%s", snapshot.String())
+	}
+
+	objects, err := DecodeObjects(&snapshot, scheme)
+	if err != nil {
+		t.Fatalf("Failed to read snapshot back: %v", err)
+	}
+	if len(objects) != 5 {
+		t.Fatalf("Expected 5 objects, got %d", len(objects))
+	}
+
+	restored := NewClient(scheme, objects)
+
+	var pod corev1.Pod
+	if err := restored.Get(context.Background(), types.NamespacedName{Namespace: "default", Name: "nightly-abc"}, &pod); err != nil {
+		t.Fatalf("Expected the pod in the snapshot: %v", err)
+	}
+	if pod.Spec.NodeName != "node-a" || pod.Status.Phase != corev1.PodSucceeded || len(pod.OwnerReferences) != 1 || len(pod.Status.Conditions) != 1 {
+		t.Errorf("Expected the pod's node, phase, conditions and owner to be kept, got %+v", pod)
+	}
+
+	var node corev1.Node
+	if err := restored.Get(context.Background(), types.NamespacedName{Name: "node-a"}, &node); err != nil {
+		t.Fatalf("Expected the node in the snapshot: %v", err)
+	}
+	if len(node.Status.Conditions) != 1 || len(node.Status.Images) != 0 {
+		t.Errorf("Expected the node's conditions only to be kept, got %+v", node.Status)
+	}
+
+	var deployment appsv1.Deployment
+	if err := restored.Get(context.Background(), types.NamespacedName{Namespace: "default", Name: "web"}, &deployment); err != nil {
+		t.Fatalf("Expected the deployment in the snapshot: %v", err)
+	}
+	if deployment.Status.ReadyReplicas != 3 {
+		t.Errorf("Expected the deployment's ready replicas to be kept, got %+v", deployment.Status)
+	}
+
+	var job batchv1.Job
+	if err := restored.Get(context.Background(), types.NamespacedName{Namespace: "default", Name: "nightly"}, &job); err != nil {
+		t.Fatalf("Expected the job in the snapshot: %v", err)
+	}
+	if job.Annotations["kubeclean/disabled"] != "true" {
+		t.Errorf("Expected the job's annotations to be kept, got %v", job.Annotations)
+	}
+}
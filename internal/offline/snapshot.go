@@ -0,0 +1,158 @@
+package offline
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	kubecleanv1alpha1 "github.com/infrautils/kubeclean/api/v1alpha1"
+	appsv1 "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	utilyaml "k8s.io/apimachinery/pkg/util/yaml"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/apiutil"
+	"sigs.k8s.io/yaml"
+)
+
+// exportedAtField is the field of a snapshot's List recording when it was exported, in RFC 3339.
+// Rules are evaluated against it by default, so pods are as old as they were at export.
+const exportedAtField = "exportedAt"
+
+// lastAppliedAnnotation is dropped from snapshots; it duplicates the object and may hold secrets.
+const lastAppliedAnnotation = "kubectl.kubernetes.io/last-applied-configuration"
+
+// snapshotLists returns empty lists of the kinds pod rules are evaluated against.
+func snapshotLists() []client.ObjectList {
+	return []client.ObjectList{
+		&corev1.NamespaceList{},
+		&corev1.NodeList{},
+		&corev1.PodList{},
+		&batchv1.JobList{},
+		&batchv1.CronJobList{},
+		&appsv1.ReplicaSetList{},
+		&appsv1.DeploymentList{},
+		&appsv1.StatefulSetList{},
+		&appsv1.DaemonSetList{},
+		&kubecleanv1alpha1.CleanupRuleList{},
+	}
+}
+
+// Export lists the objects pod rules are evaluated against and writes them to w as a YAML List
+// that LoadObjects reads back, along with the export time that ExportedAt reads back. Pods and nodes are reduced to the fields rules match on, and
+// workloads lose their pod templates, which keeps snapshots small and free of container specs.
+// Kinds the cluster does not serve, such as CleanupRule without its CRD, are left out.
+func Export(ctx context.Context, k8sClient client.Client, scheme *runtime.Scheme, w io.Writer) error {
+	var items []runtime.Object
+	exportedAt := time.Now().UTC()
+
+	for _, list := range snapshotLists() {
+		if err := k8sClient.List(ctx, list); err != nil {
+			if meta.IsNoMatchError(err) {
+				continue
+			}
+			return fmt.Errorf("list %T: %w", list, err)
+		}
+
+		objects, err := meta.ExtractList(list)
+		if err != nil {
+			return err
+		}
+
+		for _, object := range objects {
+			obj, ok := object.(client.Object)
+			if !ok {
+				continue
+			}
+			gvk, err := apiutil.GVKForObject(obj, scheme)
+			if err != nil {
+				return err
+			}
+			items = append(items, trimForSnapshot(obj, gvk))
+		}
+	}
+
+	data, err := yaml.Marshal(map[string]interface{}{"apiVersion": "v1", "kind": "List", exportedAtField: exportedAt.Format(time.RFC3339), "items": items})
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(data)
+	return err
+}
+
+// ExportedAt returns when the snapshot at path was exported, or the zero time when path is a
+// fixture directory or a file not written by Export.
+func ExportedAt(path string) (time.Time, error) {
+	if info, err := os.Stat(path); err != nil || info.IsDir() {
+		return time.Time{}, err
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		return time.Time{}, err
+	}
+	defer file.Close()
+
+	var doc map[string]interface{}
+	if err := utilyaml.NewYAMLOrJSONDecoder(file, 4096).Decode(&doc); err != nil {
+		if errors.Is(err, io.EOF) {
+			return time.Time{}, nil
+		}
+		return time.Time{}, fmt.Errorf("%s: %w", path, err)
+	}
+
+	value, ok := doc[exportedAtField].(string)
+	if !ok || doc["kind"] != "List" {
+		return time.Time{}, nil
+	}
+	exportedAt, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("%s: invalid %s: %w", path, exportedAtField, err)
+	}
+	return exportedAt, nil
+}
+
+// trimForSnapshot returns obj, with its kind set, without the fields rules never read.
+func trimForSnapshot(obj client.Object, gvk schema.GroupVersionKind) runtime.Object {
+	obj.SetManagedFields(nil)
+	if annotations := obj.GetAnnotations(); annotations != nil {
+		delete(annotations, lastAppliedAnnotation)
+		obj.SetAnnotations(annotations)
+	}
+
+	switch o := obj.(type) {
+	case *corev1.Pod:
+		o.Spec = corev1.PodSpec{NodeName: o.Spec.NodeName, PriorityClassName: o.Spec.PriorityClassName}
+		o.Status = corev1.PodStatus{Phase: o.Status.Phase, Reason: o.Status.Reason, Conditions: o.Status.Conditions}
+	case *corev1.Node:
+		// Rules read node labels and schedulability; node pressure reads the conditions.
+		o.Status = corev1.NodeStatus{Conditions: o.Status.Conditions}
+	case *corev1.Namespace, *kubecleanv1alpha1.CleanupRule:
+		// Kept whole: rules read namespace state, and tenant rules are the rules themselves.
+
+	// Workloads keep their status, which the rollout and minAvailable guards read, but not the
+	// pod templates holding container specs.
+	case *batchv1.Job:
+		o.Spec.Template = corev1.PodTemplateSpec{}
+	case *batchv1.CronJob:
+		o.Spec.JobTemplate.Spec.Template = corev1.PodTemplateSpec{}
+	case *appsv1.ReplicaSet:
+		o.Spec.Template = corev1.PodTemplateSpec{}
+	case *appsv1.Deployment:
+		o.Spec.Template = corev1.PodTemplateSpec{}
+	case *appsv1.StatefulSet:
+		o.Spec.Template = corev1.PodTemplateSpec{}
+		o.Spec.VolumeClaimTemplates = nil
+	case *appsv1.DaemonSet:
+		o.Spec.Template = corev1.PodTemplateSpec{}
+	}
+
+	obj.GetObjectKind().SetGroupVersionKind(gvk)
+	return obj
+}
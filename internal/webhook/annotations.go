@@ -0,0 +1,62 @@
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"maps"
+	"net/http"
+	"strings"
+
+	"github.com/infrautils/kubeclean/internal/controller"
+	admissionv1 "k8s.io/api/admission/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+)
+
+// AnnotationValidatorPath is the path the annotation validator is served on.
+const AnnotationValidatorPath = "/validate-kubeclean-annotations"
+
+// AnnotationValidator rejects objects carrying malformed kubeclean annotations, so typos are
+// caught at admission time instead of the matcher silently falling back to the rule TTL.
+type AnnotationValidator struct{}
+
+// Handle implements admission.Handler. It only reads object metadata, so it serves pods and
+// namespaces alike. Updates are only checked when they change a kubeclean annotation, so objects
+// admitted before the webhook was enabled can still be updated otherwise.
+func (v *AnnotationValidator) Handle(_ context.Context, req admission.Request) admission.Response {
+	if len(req.Object.Raw) == 0 {
+		return admission.Allowed("")
+	}
+
+	var obj metav1.PartialObjectMetadata
+	if err := json.Unmarshal(req.Object.Raw, &obj); err != nil {
+		return admission.Errored(http.StatusBadRequest, err)
+	}
+
+	if req.Operation == admissionv1.Update && len(req.OldObject.Raw) > 0 {
+		var old metav1.PartialObjectMetadata
+		if err := json.Unmarshal(req.OldObject.Raw, &old); err != nil {
+			return admission.Errored(http.StatusBadRequest, err)
+		}
+		if maps.Equal(kubecleanAnnotations(old.Annotations), kubecleanAnnotations(obj.Annotations)) {
+			return admission.Allowed("")
+		}
+	}
+
+	if problems := controller.ValidateAnnotations(obj.Annotations); len(problems) > 0 {
+		return admission.Denied("invalid kubeclean annotations: " + strings.Join(problems, "; "))
+	}
+
+	return admission.Allowed("")
+}
+
+// kubecleanAnnotations returns the annotations ValidateAnnotations checks.
+func kubecleanAnnotations(annotations map[string]string) map[string]string {
+	filtered := map[string]string{}
+	for _, key := range []string{controller.AnnotationTTL, controller.AnnotationDisabled} {
+		if value, ok := annotations[key]; ok {
+			filtered[key] = value
+		}
+	}
+	return filtered
+}
@@ -0,0 +1,79 @@
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	admissionv1 "k8s.io/api/admission/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+)
+
+func TestAnnotationValidator_Handle(t *testing.T) {
+	tests := []struct {
+		name        string
+		annotations map[string]string
+		allowed     bool
+	}{
+		{name: "no annotations", allowed: true},
+		{name: "valid ttl", annotations: map[string]string{"kubeclean/ttl": "90m"}, allowed: true},
+		{name: "malformed ttl", annotations: map[string]string{"kubeclean/ttl": "2 hours"}, allowed: false},
+		{name: "negative ttl", annotations: map[string]string{"kubeclean/ttl": "-1h"}, allowed: false},
+		{name: "valid disabled", annotations: map[string]string{"kubeclean/disabled": "false"}, allowed: true},
+		{name: "malformed disabled", annotations: map[string]string{"kubeclean/disabled": "yes"}, allowed: false},
+		{name: "unrelated annotations", annotations: map[string]string{"example.com/ttl": "soon"}, allowed: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pod := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "pod", Namespace: "default", Annotations: tt.annotations}}
+			raw, err := json.Marshal(pod)
+			require.NoError(t, err)
+
+			resp := (&AnnotationValidator{}).Handle(context.Background(), admission.Request{
+				AdmissionRequest: admissionv1.AdmissionRequest{
+					Operation: admissionv1.Create,
+					Object:    runtime.RawExtension{Raw: raw},
+				},
+			})
+
+			require.Equal(t, tt.allowed, resp.Allowed, resp.Result)
+		})
+	}
+}
+
+func TestAnnotationValidator_Handle_Updates(t *testing.T) {
+	tests := []struct {
+		name    string
+		old     map[string]string
+		new     map[string]string
+		allowed bool
+	}{
+		{name: "malformed annotation unchanged", old: map[string]string{"kubeclean/ttl": "2 hours"}, new: map[string]string{"kubeclean/ttl": "2 hours", "team": "a"}, allowed: true},
+		{name: "malformed annotation added", old: map[string]string{"kubeclean/ttl": "1h"}, new: map[string]string{"kubeclean/ttl": "2 hours"}, allowed: false},
+		{name: "valid annotation changed", old: map[string]string{"kubeclean/ttl": "1h"}, new: map[string]string{"kubeclean/ttl": "2h"}, allowed: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			oldRaw, err := json.Marshal(&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "team-a", Annotations: tt.old}})
+			require.NoError(t, err)
+			newRaw, err := json.Marshal(&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "team-a", Annotations: tt.new}})
+			require.NoError(t, err)
+
+			resp := (&AnnotationValidator{}).Handle(context.Background(), admission.Request{
+				AdmissionRequest: admissionv1.AdmissionRequest{
+					Operation: admissionv1.Update,
+					Object:    runtime.RawExtension{Raw: newRaw},
+					OldObject: runtime.RawExtension{Raw: oldRaw},
+				},
+			})
+
+			require.Equal(t, tt.allowed, resp.Allowed, resp.Result)
+		})
+	}
+}
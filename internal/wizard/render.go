@@ -0,0 +1,165 @@
+package wizard
+
+import (
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+	"text/template"
+	"time"
+
+	cleanupconfig "github.com/infrautils/kubeclean/internal/cleanup_config"
+)
+
+// Terminated pod counts from which the starter config tightens TTLs and budgets a backlog.
+const (
+	largeBacklog     = 1000
+	backlogPerRun    = 500
+	busySucceededTTL = 6 * time.Hour
+	busyFailedTTL    = 24 * time.Hour
+)
+
+// starterRule is a rule of the starter config and the observations behind it.
+type starterRule struct {
+	Name    string
+	Phase   string
+	Evicted bool
+	TTL     time.Duration
+	Stats   PodStats
+	Jobs    bool // Whether to suggest deleteOwnerWhenEmpty.
+}
+
+// labelRule is a commented-out rule suggested for a common workload label.
+type labelRule struct {
+	Name  string
+	Label LabelCount
+}
+
+var starterTemplate = template.Must(template.New("starter").Funcs(template.FuncMap{
+	"age":      formatAge,
+	"duration": func(d time.Duration) string { return strings.TrimSuffix(d.String(), "0m0s") },
+	"quote":    func(s string) string { return fmt.Sprintf("%q", s) },
+	"join":     strings.Join,
+}).Parse(`# Starter kubeclean config generated by "kubeclean init" on {{ .Obs.Time.Format "2006-01-02" }}.
+#
+# Observed {{ .Obs.Namespaces }} namespace(s) besides {{ join .SystemNamespaces ", " }}, holding
+# {{ .Obs.Succeeded.Count }} Succeeded, {{ .Obs.Failed.Count }} Failed and {{ .Obs.Evicted.Count }} evicted pod(s).
+{{- range .Obs.TopNamespaces }}
+#   {{ .Name }}: {{ .Count }} terminated pod(s)
+{{- end }}
+#
+# The config starts as a dry-run. Check what it would delete with
+#   kubeclean preview --config <this file>
+# then set dryRun to false.
+apiVersion: {{ .APIVersion }}
+dryRun: true
+batchSize: 10
+{{- if .Budget }}
+# A backlog of {{ .Obs.Terminated }} terminated pods; the budget spreads its cleanup over several runs.
+maxDeletionsPerRun: {{ .Budget }}
+{{- end }}
+podCleanupConfig:
+  enabled: true
+  rules:
+{{- range .Rules }}
+{{- if .Stats.Count }}
+    # {{ .Stats.Count }} pod(s); the oldest is {{ age .Stats.Oldest }} old.
+{{- else }}
+    # No such pods observed; enable the rule if they appear.
+{{- end }}
+    - name: {{ .Name }}
+      enabled: {{ if .Stats.Count }}true{{ else }}false{{ end }}
+{{- if .Evicted }}
+      match:
+        all:
+          - phase: Failed
+            reason: Evicted
+{{- else }}
+      phase: {{ .Phase }}
+{{- end }}
+      ttl: {{ duration .TTL }}
+      namespaceSelector:
+        matchExpressions:
+          - key: kubernetes.io/metadata.name
+            operator: NotIn
+            values: [{{ join $.SystemNamespaces ", " }}]
+{{- if .Jobs }}
+      # {{ $.Obs.JobPods }} terminated pod(s) belong to Jobs. Set to true to delete a finished Job
+      # along with its pods instead of leaving it empty.
+      deleteOwnerWhenEmpty: false
+{{- end }}
+{{- end }}
+{{- if .LabelRules }}
+    # Most terminated pods carry these workload labels. A rule per workload can give it its own
+    # TTL; rules are evaluated by priority, so give such rules a higher one.
+{{- range .LabelRules }}
+    #
+    # {{ .Label.Count }} pod(s) labelled {{ .Label.Key }}={{ .Label.Value }}:
+    # - name: {{ .Name }}
+    #   enabled: true
+    #   priority: 10
+    #   phase: Succeeded
+    #   ttl: 1h
+    #   selector:
+    #     matchLabels:
+    #       {{ .Label.Key }}: {{ quote .Label.Value }}
+{{- end }}
+{{- end }}
+`))
+
+// Render writes a starter config tuned to obs to w. Rules for kinds of pods that were observed are
+// enabled; TTLs are shorter and a deletion budget is set when there is a large backlog.
+func Render(w io.Writer, obs Observations) error {
+	succeededTTL, failedTTL := 24*time.Hour, 72*time.Hour
+	if obs.Succeeded.Count >= largeBacklog {
+		succeededTTL = busySucceededTTL
+	}
+	if obs.Failed.Count >= largeBacklog {
+		failedTTL = busyFailedTTL
+	}
+
+	data := struct {
+		Obs              Observations
+		APIVersion       string
+		SystemNamespaces []string
+		Budget           int
+		Rules            []starterRule
+		LabelRules       []labelRule
+	}{
+		Obs:              obs,
+		APIVersion:       cleanupconfig.CurrentAPIVersion,
+		SystemNamespaces: cleanupconfig.SystemNamespaces,
+		Rules: []starterRule{
+			{Name: "succeeded-pods", Phase: "Succeeded", TTL: succeededTTL, Stats: obs.Succeeded, Jobs: obs.JobPods > 0},
+			{Name: "failed-pods", Phase: "Failed", TTL: failedTTL, Stats: obs.Failed},
+			{Name: "evicted-pods", Evicted: true, TTL: time.Hour, Stats: obs.Evicted},
+		},
+	}
+	if obs.Terminated() >= largeBacklog {
+		data.Budget = backlogPerRun
+	}
+	for _, label := range obs.TopLabels {
+		data.LabelRules = append(data.LabelRules, labelRule{Name: ruleName(label.Value) + "-pods", Label: label})
+	}
+
+	return starterTemplate.Execute(w, data)
+}
+
+var invalidNameChars = regexp.MustCompile(`[^a-z0-9-]+`)
+
+// ruleName turns a label value into a rule name.
+func ruleName(value string) string {
+	return strings.Trim(invalidNameChars.ReplaceAllString(strings.ToLower(value), "-"), "-")
+}
+
+// formatAge formats d in its largest whole unit of days, hours or minutes.
+func formatAge(d time.Duration) string {
+	switch {
+	case d >= 24*time.Hour:
+		return fmt.Sprintf("%dd", int(d/(24*time.Hour)))
+	case d >= time.Hour:
+		return fmt.Sprintf("%dh", int(d/time.Hour))
+	default:
+		return fmt.Sprintf("%dm", int(d/time.Minute))
+	}
+}
@@ -0,0 +1,146 @@
+// Package wizard generates a starter config from what it observes in a cluster.
+package wizard
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"maps"
+	"slices"
+	"time"
+
+	cleanupconfig "github.com/infrautils/kubeclean/internal/cleanup_config"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// workloadLabelKeys are the labels commonly identifying the workload a pod belongs to, in order of
+// preference.
+var workloadLabelKeys = []string{
+	"app.kubernetes.io/name",
+	"app.kubernetes.io/component",
+	"app",
+	"k8s-app",
+	"component",
+	"tekton.dev/pipeline",
+	"spark-role",
+}
+
+// topCount is the number of namespaces and workload labels reported.
+const topCount = 3
+
+// PodStats counts the terminated pods of one kind.
+type PodStats struct {
+	Count  int
+	Oldest time.Duration // Age of the oldest pod.
+}
+
+// Count is a name and how often it was observed.
+type Count struct {
+	Name  string
+	Count int
+}
+
+// LabelCount is a workload label and the number of terminated pods carrying it.
+type LabelCount struct {
+	Key   string
+	Value string
+	Count int
+}
+
+// Observations summarizes the terminated pods Survey found outside the system namespaces.
+type Observations struct {
+	Time       time.Time
+	Namespaces int // Namespaces other than the system namespaces.
+
+	Succeeded PodStats
+	Failed    PodStats // Failed pods other than evicted ones.
+	Evicted   PodStats
+
+	JobPods       int          // Terminated pods owned by a Job.
+	TopNamespaces []Count      // Namespaces with the most terminated pods, most first.
+	TopLabels     []LabelCount // Workload labels most common among terminated pods, most first.
+}
+
+// Terminated returns the number of terminated pods observed.
+func (o Observations) Terminated() int {
+	return o.Succeeded.Count + o.Failed.Count + o.Evicted.Count
+}
+
+// Survey lists the cluster's namespaces and pods and summarizes its terminated pods as of now.
+// Pods in the system namespaces are left out, as the generated rules never touch them.
+func Survey(ctx context.Context, k8sClient client.Client, now time.Time) (Observations, error) {
+	obs := Observations{Time: now}
+
+	var namespaces corev1.NamespaceList
+	if err := k8sClient.List(ctx, &namespaces); err != nil {
+		return obs, fmt.Errorf("failed to list namespaces: %w", err)
+	}
+	for _, namespace := range namespaces.Items {
+		if !slices.Contains(cleanupconfig.SystemNamespaces, namespace.Name) {
+			obs.Namespaces++
+		}
+	}
+
+	var pods corev1.PodList
+	if err := k8sClient.List(ctx, &pods); err != nil {
+		return obs, fmt.Errorf("failed to list pods: %w", err)
+	}
+
+	byNamespace := map[string]int{}
+	byLabel := map[LabelCount]int{}
+
+	for i := range pods.Items {
+		pod := &pods.Items[i]
+		if slices.Contains(cleanupconfig.SystemNamespaces, pod.Namespace) {
+			continue
+		}
+
+		var stats *PodStats
+		switch {
+		case pod.Status.Phase == corev1.PodSucceeded:
+			stats = &obs.Succeeded
+		case pod.Status.Phase == corev1.PodFailed && pod.Status.Reason == "Evicted":
+			stats = &obs.Evicted
+		case pod.Status.Phase == corev1.PodFailed:
+			stats = &obs.Failed
+		default:
+			continue
+		}
+
+		stats.Count++
+		stats.Oldest = max(stats.Oldest, now.Sub(pod.CreationTimestamp.Time))
+		byNamespace[pod.Namespace]++
+
+		if owner := metav1.GetControllerOf(pod); owner != nil && owner.Kind == "Job" {
+			obs.JobPods++
+		}
+
+		for _, key := range workloadLabelKeys {
+			if value, ok := pod.Labels[key]; ok {
+				byLabel[LabelCount{Key: key, Value: value}]++
+				break
+			}
+		}
+	}
+
+	for _, name := range slices.Collect(maps.Keys(byNamespace)) {
+		obs.TopNamespaces = append(obs.TopNamespaces, Count{Name: name, Count: byNamespace[name]})
+	}
+	slices.SortFunc(obs.TopNamespaces, func(a, b Count) int {
+		return cmp.Or(cmp.Compare(b.Count, a.Count), cmp.Compare(a.Name, b.Name))
+	})
+	obs.TopNamespaces = obs.TopNamespaces[:min(topCount, len(obs.TopNamespaces))]
+
+	for label, count := range byLabel {
+		label.Count = count
+		obs.TopLabels = append(obs.TopLabels, label)
+	}
+	slices.SortFunc(obs.TopLabels, func(a, b LabelCount) int {
+		return cmp.Or(cmp.Compare(b.Count, a.Count), cmp.Compare(a.Key, b.Key), cmp.Compare(a.Value, b.Value))
+	})
+	obs.TopLabels = obs.TopLabels[:min(topCount, len(obs.TopLabels))]
+
+	return obs, nil
+}
@@ -0,0 +1,116 @@
+package wizard
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	cleanupconfig "github.com/infrautils/kubeclean/internal/cleanup_config"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func newPod(name, namespace string, phase corev1.PodPhase, reason string, age time.Duration, labels map[string]string) *corev1.Pod {
+	return &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:              name,
+			Namespace:         namespace,
+			Labels:            labels,
+			CreationTimestamp: metav1.NewTime(time.Now().Add(-age)),
+		},
+		Status: corev1.PodStatus{Phase: phase, Reason: reason},
+	}
+}
+
+func TestSurvey(t *testing.T) {
+	scheme := runtime.NewScheme()
+	_ = corev1.AddToScheme(scheme)
+
+	isController := true
+	jobPod := newPod("job-1", "batch", corev1.PodSucceeded, "", 2*time.Hour, map[string]string{"app": "report"})
+	jobPod.OwnerReferences = []metav1.OwnerReference{{APIVersion: "batch/v1", Kind: "Job", Name: "report", UID: "1", Controller: &isController}}
+
+	client := fake.NewClientBuilder().WithScheme(scheme).WithRuntimeObjects(
+		&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "kube-system"}},
+		&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "batch"}},
+		&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "web"}},
+		jobPod,
+		newPod("job-2", "batch", corev1.PodSucceeded, "", 48*time.Hour, map[string]string{"app": "report"}),
+		newPod("job-3", "batch", corev1.PodFailed, "", time.Hour, map[string]string{"app.kubernetes.io/name": "etl", "app": "ignored"}),
+		newPod("web-1", "web", corev1.PodFailed, "Evicted", 3*time.Hour, nil),
+		newPod("web-2", "web", corev1.PodRunning, "", 3*time.Hour, map[string]string{"app": "web"}),
+		newPod("system", "kube-system", corev1.PodSucceeded, "", time.Hour, map[string]string{"app": "dns"}),
+	).Build()
+
+	obs, err := Survey(context.Background(), client, time.Now())
+	require.NoError(t, err)
+
+	require.Equal(t, 2, obs.Namespaces)
+	require.Equal(t, 2, obs.Succeeded.Count)
+	require.GreaterOrEqual(t, obs.Succeeded.Oldest, 48*time.Hour)
+	require.Equal(t, 1, obs.Failed.Count)
+	require.Equal(t, 1, obs.Evicted.Count)
+	require.Equal(t, 4, obs.Terminated())
+	require.Equal(t, 1, obs.JobPods)
+	require.Equal(t, []Count{{Name: "batch", Count: 3}, {Name: "web", Count: 1}}, obs.TopNamespaces)
+	require.Equal(t, []LabelCount{
+		{Key: "app", Value: "report", Count: 2},
+		{Key: "app.kubernetes.io/name", Value: "etl", Count: 1},
+	}, obs.TopLabels)
+}
+
+func TestRender(t *testing.T) {
+	obs := Observations{
+		Time:          time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC),
+		Namespaces:    4,
+		Succeeded:     PodStats{Count: 1500, Oldest: 30 * 24 * time.Hour},
+		Failed:        PodStats{Count: 12, Oldest: 5 * time.Hour},
+		JobPods:       900,
+		TopNamespaces: []Count{{Name: "batch", Count: 1400}},
+		TopLabels:     []LabelCount{{Key: "app", Value: "Nightly Report", Count: 1000}},
+	}
+
+	var out bytes.Buffer
+	require.NoError(t, Render(&out, obs))
+
+	cfg, err := cleanupconfig.LoadConfig(out.Bytes())
+	require.NoError(t, err, out.String())
+	require.NoError(t, cfg.Validate())
+
+	require.True(t, cfg.DryRun)
+	require.Equal(t, backlogPerRun, cfg.MaxDeletionsPerRun)
+
+	rules := cfg.PodCleanupConfig.Rules
+	require.Len(t, rules, 3)
+	require.Equal(t, "succeeded-pods", rules[0].Name)
+	require.True(t, rules[0].Enabled)
+	require.Equal(t, busySucceededTTL, rules[0].TTL.Duration)
+	require.Equal(t, "failed-pods", rules[1].Name)
+	require.Equal(t, 72*time.Hour, rules[1].TTL.Duration)
+	require.Equal(t, "evicted-pods", rules[2].Name)
+	require.False(t, rules[2].Enabled, "no evicted pods were observed")
+	require.Equal(t, cleanupconfig.SystemNamespaces, rules[2].NamespaceSelector.MatchExpressions[0].Values)
+
+	require.Contains(t, out.String(), "deleteOwnerWhenEmpty")
+	require.Contains(t, out.String(), "# - name: nightly-report-pods")
+	require.Contains(t, out.String(), fmt.Sprintf("#       app: %q", "Nightly Report"))
+}
+
+func TestRender_EmptyCluster(t *testing.T) {
+	var out bytes.Buffer
+	require.NoError(t, Render(&out, Observations{Time: time.Now()}))
+
+	cfg, err := cleanupconfig.LoadConfig(out.Bytes())
+	require.NoError(t, err, out.String())
+	require.NoError(t, cfg.Validate())
+	require.Zero(t, cfg.MaxDeletionsPerRun)
+	for _, rule := range cfg.PodCleanupConfig.Rules {
+		require.False(t, rule.Enabled, rule.Name)
+	}
+	require.NotContains(t, out.String(), "deleteOwnerWhenEmpty")
+}
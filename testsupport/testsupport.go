@@ -0,0 +1,274 @@
+// Package testsupport runs kubeclean's rule engine against a real API server started with envtest,
+// so platform teams can write integration tests for their own rule files in CI.
+//
+// envtest needs the kube-apiserver and etcd binaries. Install them with setup-envtest and point
+// KUBEBUILDER_ASSETS at the directory it prints:
+//
+//	export KUBEBUILDER_ASSETS=$(setup-envtest use -p path)
+//
+// A test starts an environment, creates the objects its rules should or should not match, and
+// checks the matches:
+//
+//	func TestRules(t *testing.T) {
+//		env := testsupport.Start(t)
+//		env.CreateFromFiles(t, "testdata/fixtures")
+//		env.CheckExpectations(t, "config.yaml", "testdata/fixtures/expectations.yaml")
+//	}
+//
+// The API server sets creationTimestamp itself, so pods are as old as the time since they were
+// created. Rules under test should use short TTLs, or the test should wait for them to elapse.
+package testsupport
+
+import (
+	"context"
+	"embed"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"slices"
+	"testing"
+
+	kubecleanv1alpha1 "github.com/infrautils/kubeclean/api/v1alpha1"
+	cleanupconfig "github.com/infrautils/kubeclean/internal/cleanup_config"
+	"github.com/infrautils/kubeclean/internal/controller"
+	"github.com/infrautils/kubeclean/internal/offline"
+	corev1 "k8s.io/api/core/v1"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/rest"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/envtest"
+	"sigs.k8s.io/controller-runtime/pkg/log/zap"
+	"sigs.k8s.io/yaml"
+)
+
+// crdFiles holds kubeclean's CustomResourceDefinitions, kept identical to those of the Helm chart.
+//
+//go:embed crds/*.yaml
+var crdFiles embed.FS
+
+// Environment is a running API server with kubeclean's CRDs installed.
+type Environment struct {
+	Config *rest.Config
+	Client client.Client
+	Scheme *runtime.Scheme
+}
+
+// Match is a pod matched by a rule.
+type Match struct {
+	Rule      string
+	Namespace string
+	Name      string
+}
+
+// RunResult summarizes a cleanup pass.
+type RunResult struct {
+	Matched        int // Pods matched by any rule.
+	Deferred       int // Matched pods held back by deletion budgets.
+	DeleteFailures int // Deletions that failed.
+}
+
+// Start starts an API server and stops it when the test ends. It fails the test when the
+// envtest binaries cannot be found.
+func Start(t testing.TB) *Environment {
+	t.Helper()
+	logs := io.Discard
+	if testing.Verbose() {
+		logs = os.Stderr
+	}
+	ctrl.SetLogger(zap.New(zap.WriteTo(logs)))
+
+	scheme := runtime.NewScheme()
+	utilruntime.Must(clientgoscheme.AddToScheme(scheme))
+	utilruntime.Must(kubecleanv1alpha1.AddToScheme(scheme))
+
+	crds, err := loadCRDs()
+	if err != nil {
+		t.Fatalf("testsupport: %v", err)
+	}
+
+	testEnv := &envtest.Environment{
+		CRDInstallOptions: envtest.CRDInstallOptions{CRDs: crds},
+	}
+	restConfig, err := testEnv.Start()
+	if err != nil {
+		t.Fatalf("testsupport: unable to start the API server; is KUBEBUILDER_ASSETS set? %v", err)
+	}
+	t.Cleanup(func() {
+		if err := testEnv.Stop(); err != nil {
+			t.Logf("testsupport: unable to stop the API server: %v", err)
+		}
+	})
+
+	k8sClient, err := client.New(restConfig, client.Options{Scheme: scheme})
+	if err != nil {
+		t.Fatalf("testsupport: unable to create client: %v", err)
+	}
+
+	return &Environment{Config: restConfig, Client: k8sClient, Scheme: scheme}
+}
+
+// Create creates objects, along with the namespaces they are in when missing. The API server
+// ignores the status of new objects, so the status of pods, such as their phase, is written after
+// they are created.
+func (e *Environment) Create(t testing.TB, objects ...client.Object) {
+	t.Helper()
+	ctx := context.Background()
+
+	// Namespaces go first, so those created for other objects do not shadow their labels.
+	objects = slices.Clone(objects)
+	slices.SortStableFunc(objects, func(a, b client.Object) int {
+		_, aIsNamespace := a.(*corev1.Namespace)
+		_, bIsNamespace := b.(*corev1.Namespace)
+		switch {
+		case aIsNamespace && !bIsNamespace:
+			return -1
+		case bIsNamespace && !aIsNamespace:
+			return 1
+		}
+		return 0
+	})
+
+	for _, obj := range objects {
+		if namespace := obj.GetNamespace(); namespace != "" {
+			e.createNamespace(ctx, t, &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: namespace}})
+		}
+		if namespace, ok := obj.(*corev1.Namespace); ok {
+			e.createNamespace(ctx, t, namespace)
+			continue
+		}
+
+		var status *corev1.PodStatus
+		if pod, ok := obj.(*corev1.Pod); ok {
+			status = pod.Status.DeepCopy()
+		}
+
+		if err := e.Client.Create(ctx, obj); err != nil {
+			t.Fatalf("testsupport: unable to create %s/%s: %v", obj.GetNamespace(), obj.GetName(), err)
+		}
+
+		if pod, ok := obj.(*corev1.Pod); ok && status != nil {
+			pod.Status = *status
+			if err := e.Client.Status().Update(ctx, pod); err != nil {
+				t.Fatalf("testsupport: unable to set the status of pod %s/%s: %v", pod.Namespace, pod.Name, err)
+			}
+		}
+	}
+}
+
+// createNamespace creates namespace unless it exists, along with its default service account.
+// Without a controller manager, nothing else creates the account the API server requires of pods.
+func (e *Environment) createNamespace(ctx context.Context, t testing.TB, namespace *corev1.Namespace) {
+	t.Helper()
+
+	if err := e.Client.Create(ctx, namespace); err != nil && !apierrors.IsAlreadyExists(err) {
+		t.Fatalf("testsupport: unable to create namespace %s: %v", namespace.Name, err)
+	}
+	account := &corev1.ServiceAccount{ObjectMeta: metav1.ObjectMeta{Namespace: namespace.Name, Name: "default"}}
+	if err := e.Client.Create(ctx, account); err != nil && !apierrors.IsAlreadyExists(err) {
+		t.Fatalf("testsupport: unable to create the default service account of %s: %v", namespace.Name, err)
+	}
+}
+
+// CreateFromFiles creates the objects of a YAML file, or of every YAML file in a directory, in the
+// format accepted by `kubeclean test --fixtures`.
+func (e *Environment) CreateFromFiles(t testing.TB, path string) {
+	t.Helper()
+
+	objects, err := offline.LoadObjects(path, e.Scheme)
+	if err != nil {
+		t.Fatalf("testsupport: %v", err)
+	}
+	e.Create(t, objects...)
+}
+
+// Preview returns the pods the rules of the config file match, as a dry run would report them.
+func (e *Environment) Preview(t testing.TB, configPath string) []Match {
+	t.Helper()
+
+	refs := controller.NewPodCleanController(e.Client, e.Scheme, loadConfig(t, configPath)).Preview(context.Background())
+	matches := make([]Match, 0, len(refs))
+	for _, ref := range refs {
+		matches = append(matches, Match{Rule: ref.Rule, Namespace: ref.Namespace, Name: ref.Name})
+	}
+	return matches
+}
+
+// CheckExpectations fails the test when the pods the rules of the config file match differ from
+// those of the expectations file, in the format read by `kubeclean test`.
+func (e *Environment) CheckExpectations(t testing.TB, configPath, expectationsPath string) {
+	t.Helper()
+
+	expectations, err := offline.LoadExpectations(expectationsPath)
+	if err != nil {
+		t.Fatalf("testsupport: %v", err)
+	}
+
+	refs := controller.NewPodCleanController(e.Client, e.Scheme, loadConfig(t, configPath)).Preview(context.Background())
+	for _, result := range offline.Check(expectations, refs) {
+		if !result.Passed {
+			t.Errorf("rule %s: unexpected matches %v, missing matches %v", result.Rule, result.Unexpected, result.Missing)
+		}
+	}
+}
+
+// RunCleanup runs a single cleanup pass with the config file, deleting the matched pods unless
+// the config is a dry run.
+func (e *Environment) RunCleanup(t testing.TB, configPath string) RunResult {
+	t.Helper()
+
+	podCleanController := controller.NewPodCleanController(e.Client, e.Scheme, loadConfig(t, configPath))
+	summary := podCleanController.RunCleanUp(context.Background())
+	return RunResult{Matched: summary.Matched, Deferred: summary.Deferred, DeleteFailures: summary.DeleteFailures}
+}
+
+// Exists reports whether the object with obj's kind, namespace and name exists.
+func (e *Environment) Exists(t testing.TB, obj client.Object) bool {
+	t.Helper()
+
+	err := e.Client.Get(context.Background(), client.ObjectKeyFromObject(obj), obj)
+	if apierrors.IsNotFound(err) {
+		return false
+	}
+	if err != nil {
+		t.Fatalf("testsupport: unable to get %s/%s: %v", obj.GetNamespace(), obj.GetName(), err)
+	}
+	return true
+}
+
+func loadConfig(t testing.TB, path string) *cleanupconfig.CleanupConfig {
+	t.Helper()
+
+	cfg, err := cleanupconfig.LoadConfigFromFile(path)
+	if err != nil {
+		t.Fatalf("testsupport: %v", err)
+	}
+	cfg.SetDefaults()
+	return cfg
+}
+
+func loadCRDs() ([]*apiextensionsv1.CustomResourceDefinition, error) {
+	var crds []*apiextensionsv1.CustomResourceDefinition
+	err := fs.WalkDir(crdFiles, "crds", func(path string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		data, err := crdFiles.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		crd := &apiextensionsv1.CustomResourceDefinition{}
+		if err := yaml.UnmarshalStrict(data, crd); err != nil {
+			return fmt.Errorf("invalid CRD %s: %w", path, err)
+		}
+		crds = append(crds, crd)
+		return nil
+	})
+	return crds, err
+}
@@ -0,0 +1,54 @@
+package testsupport
+
+import (
+	"os"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestCRDsMatchChart(t *testing.T) {
+	entries, err := crdFiles.ReadDir("crds")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	for _, entry := range entries {
+		embedded, err := crdFiles.ReadFile("crds/" + entry.Name())
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		chart, err := os.ReadFile("../chart/crds/" + entry.Name())
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if string(embedded) != string(chart) {
+			t.Errorf("Expected crds/%s to match the chart's copy; copy it from chart/crds", entry.Name())
+		}
+	}
+
+	if _, err := loadCRDs(); err != nil {
+		t.Errorf("Expected the CRDs to decode, got %v", err)
+	}
+}
+
+func TestEnvironment(t *testing.T) {
+	if os.Getenv("KUBEBUILDER_ASSETS") == "" {
+		t.Skip("KUBEBUILDER_ASSETS is not set; skipping envtest")
+	}
+
+	env := Start(t)
+	env.CreateFromFiles(t, "testdata/fixtures.yaml")
+	env.CheckExpectations(t, "testdata/config.yaml", "testdata/expectations.yaml")
+
+	if result := env.RunCleanup(t, "testdata/config.yaml"); result.Matched != 1 || result.DeleteFailures != 0 {
+		t.Errorf("Expected 1 matched pod and no failed deletions, got %+v", result)
+	}
+	if env.Exists(t, &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Namespace: "team-a", Name: "crashed"}}) {
+		t.Errorf("Expected the failed pod to be deleted")
+	}
+	if !env.Exists(t, &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Namespace: "team-a", Name: "healthy"}}) {
+		t.Errorf("Expected the running pod to be kept")
+	}
+}